@@ -22,6 +22,6 @@ func main() {
 
 	if err := app.Run(os.Args); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }
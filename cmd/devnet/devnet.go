@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/devnet"
+	"github.com/skycoin/skycoin/src/util/apputil"
+	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin/src/util/logging"
+)
+
+// Note: devnet runs a local network of skycoin nodes on a fresh blockchain.
+// The genesis keys and distribution addresses are generated from -seed, and each node's
+// data directory in -dir has a run.sh script that runs the node with the skycoin binary.
+// -skycoin runs the nodes as separate skycoin processes instead of in this process.
+
+var logger = logging.MustGetLogger("devnet")
+
+func main() {
+	c := devnet.NewConfig()
+
+	flag.IntVar(&c.Nodes, "nodes", c.Nodes, "Number of nodes, the first node is the master")
+	flag.StringVar(&c.Seed, "seed", c.Seed, "Seed that the genesis keys and distribution addresses are generated from")
+	flag.StringVar(&c.Dir, "dir", "$HOME/.skycoin-devnet", "Directory that the nodes' data directories are created in")
+	flag.IntVar(&c.Port, "port", c.Port, "Port of the first node, the other nodes use the following ports")
+	flag.IntVar(&c.WebInterfacePort, "web-interface-port", c.WebInterfacePort, "Web interface port of the first node, the other nodes use the following ports")
+	flag.Uint64Var(&c.GenesisCoinVolume, "genesis-coin-volume", c.GenesisCoinVolume, "Number of coins in the genesis block, in droplets")
+	flag.Uint64Var(&c.GenesisTimestamp, "genesis-timestamp", c.GenesisTimestamp, "Genesis block timestamp")
+	flag.IntVar(&c.DistributionAddresses, "distribution-addresses", c.DistributionAddresses, "Number of distribution addresses that the genesis coins are distributed to")
+	skycoinBin := flag.String("skycoin", "", "Path of a skycoin binary to run the nodes as separate processes. The genesis coins are not distributed in this mode")
+	flag.Parse()
+
+	c.Dir = strings.Replace(c.Dir, "$HOME", file.UserHome(), 1)
+
+	dn, err := devnet.New(c)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	quit := make(chan struct{})
+	go apputil.CatchInterrupt(quit)
+
+	if *skycoinBin != "" {
+		if err := runProcesses(dn, *skycoinBin, quit); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := dn.Start(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	printNodes(dn)
+
+	<-quit
+
+	logger.Info("Shutting down devnet")
+	dn.Shutdown()
+}
+
+// runProcesses runs each node in a skycoin process until quit is closed.
+// The output of a node is written to skycoin.log in its data directory.
+func runProcesses(dn *devnet.Devnet, skycoinBin string, quit <-chan struct{}) error {
+	var cmds []*exec.Cmd
+	defer func() {
+		for _, cmd := range cmds {
+			if err := cmd.Process.Signal(os.Interrupt); err != nil {
+				logger.WithError(err).Error("Failed to stop skycoin process")
+				continue
+			}
+			if err := cmd.Wait(); err != nil {
+				logger.WithError(err).Error("skycoin process failed")
+			}
+		}
+	}()
+
+	for _, n := range dn.Nodes {
+		f, err := os.OpenFile(filepath.Join(n.Config.DataDirectory, "skycoin.log"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		cmd := exec.Command(skycoinBin, n.Args()...)
+		cmd.Stdout = f
+		cmd.Stderr = f
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("Start node %d failed: %v", n.Config.Index, err)
+		}
+
+		cmds = append(cmds, cmd)
+	}
+
+	printNodes(dn)
+
+	<-quit
+
+	logger.Info("Shutting down devnet")
+	return nil
+}
+
+func printNodes(dn *devnet.Devnet) {
+	fmt.Printf("Genesis address: %s\n", dn.Genesis.Address)
+	fmt.Printf("Blockchain pubkey: %s\n", dn.Genesis.BlockchainPubkey.Hex())
+	for _, n := range dn.Nodes {
+		role := "node"
+		if n.Config.Master {
+			role = "master"
+		}
+		fmt.Printf("Node %d (%s): %s, web interface http://%s, data directory %s\n",
+			n.Config.Index, role, n.Addr(), n.WebInterfaceAddr(), n.Config.DataDirectory)
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/go-bip39"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/devnet"
+	"github.com/skycoin/skycoin/src/skycoin"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/util/utc"
+)
+
+// GenesisKeys are the secrets of a new coin's genesis, written to the keys file.
+// The genesis address and the distribution addresses are the addresses of a deterministic wallet
+// with the seed, so the coins can be spent from a wallet created with the seed.
+type GenesisKeys struct {
+	Seed             string `json:"seed"`
+	BlockchainPubkey string `json:"blockchain_pubkey"`
+	BlockchainSeckey string `json:"blockchain_seckey"`
+	GenesisAddress   string `json:"genesis_address"`
+}
+
+func createGenesisCommand() cli.Command {
+	name := "creategenesis"
+	return cli.Command{
+		Name:  name,
+		Usage: "Generate the genesis keys, genesis block and distribution addresses of a new coin, and create the coin",
+		Description: `Generates the blockchain keypair, the genesis address and the distribution addresses from a seed,
+    and signs the genesis block. The coin supply and number of distribution addresses are read from the
+    config file, which is then rewritten with the genesis parameters. The coin and visor parameters
+    files are created like createcoin does, and the coin is verified like verifycoin does.
+
+    The seed and the blockchain secret key are written to the keys file, and not to the config file.
+    Keep the keys file safe, the seed can spend all of the coins.`,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "coin",
+				Usage: "name of the coin to create",
+				Value: "skycoin",
+			},
+			cli.StringFlag{
+				Name:  "template-dir, td",
+				Usage: "template directory path",
+				Value: "./template",
+			},
+			cli.StringFlag{
+				Name:  "coin-template-file, ct",
+				Usage: "coin template file",
+				Value: "coin.template",
+			},
+			cli.StringFlag{
+				Name:  "visor-template-file, vt",
+				Usage: "visor template file",
+				Value: "visor.template",
+			},
+			cli.StringFlag{
+				Name:  "fiber-template-file, ft",
+				Usage: "fiber config template file",
+				Value: "fiber.template",
+			},
+			cli.StringFlag{
+				Name:  "config-dir, cd",
+				Usage: "config directory path",
+				Value: "./",
+			},
+			cli.StringFlag{
+				Name:  "config-file, cf",
+				Usage: "config file path",
+				Value: "fiber.toml",
+			},
+			cli.StringFlag{
+				Name:  "keys-file, kf",
+				Usage: "file in the config directory that the seed and blockchain keys are written to",
+				Value: "fiber-keys.json",
+			},
+			cli.StringFlag{
+				Name:  "seed",
+				Usage: "seed that the genesis keys and distribution addresses are generated from. A random bip39 seed is used if not set",
+			},
+			cli.Uint64Flag{
+				Name:  "genesis-timestamp",
+				Usage: "genesis block timestamp. The current time is used if not set",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			templateDir := c.String("template-dir")
+			coinTemplateFile := c.String("coin-template-file")
+			visorTemplateFile := c.String("visor-template-file")
+			fiberTemplateFile := c.String("fiber-template-file")
+
+			// check that the template files exist
+			for _, f := range []string{coinTemplateFile, visorTemplateFile, fiberTemplateFile} {
+				if _, err := os.Stat(filepath.Join(templateDir, f)); os.IsNotExist(err) {
+					return err
+				}
+			}
+
+			configFile := c.String("config-file")
+			configDir := c.String("config-dir")
+
+			config, err := skycoin.NewParameters(configFile, configDir)
+			if err != nil {
+				log.Errorf("failed to load fiber coin config")
+				return err
+			}
+
+			// never overwrite the keys of an existing coin
+			keysFilepath := filepath.Join(configDir, c.String("keys-file"))
+			if _, err := os.Stat(keysFilepath); err == nil {
+				return fmt.Errorf("keys file %s already exists", keysFilepath)
+			}
+
+			seed := c.String("seed")
+			if seed == "" {
+				seed, err = bip39.NewDefaultMnemonic()
+				if err != nil {
+					return err
+				}
+			}
+
+			timestamp := c.Uint64("genesis-timestamp")
+			if timestamp == 0 {
+				timestamp = uint64(utc.UnixNow())
+			}
+
+			keys, err := createGenesis(&config, seed, timestamp)
+			if err != nil {
+				return err
+			}
+
+			if err := writeGenesisKeys(keysFilepath, keys); err != nil {
+				log.Errorf("failed to write keys file %s", keysFilepath)
+				return err
+			}
+
+			configFilepath := filepath.Join(configDir, configFile)
+			if err := writeFiberConfig(configFilepath, filepath.Join(templateDir, fiberTemplateFile), config); err != nil {
+				log.Errorf("failed to write config file %s", configFilepath)
+				return err
+			}
+
+			coinName := c.String("coin")
+			if err := createCoin(coinName, templateDir, coinTemplateFile, visorTemplateFile, config); err != nil {
+				return err
+			}
+
+			dc := devnet.NewConfig()
+			if err := verifyCoin(config, seed, dc.Port, dc.WebInterfacePort); err != nil {
+				log.Errorf("failed to verify the new coin, run verifycoin to retry")
+				return err
+			}
+
+			fmt.Printf("Created coin %s\n", coinName)
+			fmt.Printf("Genesis address: %s\n", keys.GenesisAddress)
+			fmt.Printf("Blockchain pubkey: %s\n", keys.BlockchainPubkey)
+			fmt.Printf("The seed and blockchain secret key are in %s, keep this file safe\n", keysFilepath)
+
+			return nil
+		},
+	}
+}
+
+func verifyCoinCommand() cli.Command {
+	name := "verifycoin"
+	return cli.Command{
+		Name:  name,
+		Usage: "Verify a coin's config file and boot the coin in-process",
+		Description: `Checks that the genesis signature in the config file is valid, that the distribution addresses
+    match the visor parameters and that the genesis keys are generated from the seed in the keys file.
+    Then runs a node of the coin with a temporary data directory, distributes the genesis coins and
+    checks the balances of the distribution addresses.`,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "config-dir, cd",
+				Usage: "config directory path",
+				Value: "./",
+			},
+			cli.StringFlag{
+				Name:  "config-file, cf",
+				Usage: "config file path",
+				Value: "fiber.toml",
+			},
+			cli.StringFlag{
+				Name:  "keys-file, kf",
+				Usage: "file in the config directory with the seed and blockchain keys",
+				Value: "fiber-keys.json",
+			},
+			cli.IntFlag{
+				Name:  "port",
+				Usage: "port of the node",
+				Value: devnet.NewConfig().Port,
+			},
+			cli.IntFlag{
+				Name:  "web-interface-port",
+				Usage: "web interface port of the node",
+				Value: devnet.NewConfig().WebInterfacePort,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			configDir := c.String("config-dir")
+
+			config, err := skycoin.NewParameters(c.String("config-file"), configDir)
+			if err != nil {
+				log.Errorf("failed to load fiber coin config")
+				return err
+			}
+
+			keys, err := readGenesisKeys(filepath.Join(configDir, c.String("keys-file")))
+			if err != nil {
+				return err
+			}
+
+			if err := verifyCoin(config, keys.Seed, c.Int("port"), c.Int("web-interface-port")); err != nil {
+				return err
+			}
+
+			fmt.Println("Coin verified")
+			return nil
+		},
+	}
+}
+
+// createGenesis generates the genesis keys and distribution addresses from the seed, signs the
+// genesis block and sets them in the config. The coin volume and number of distribution
+// addresses come from the visor parameters of the config.
+func createGenesis(config *skycoin.Parameters, seed string, timestamp uint64) (*GenesisKeys, error) {
+	if config.Visor.DistributionAddressesTotal == 0 {
+		return nil, errors.New("distribution_addresses_total must be greater than 0")
+	}
+	if config.Visor.MaxCoinSupply%config.Visor.DistributionAddressesTotal != 0 {
+		return nil, errors.New("max_coin_supply must be a multiple of distribution_addresses_total")
+	}
+
+	coinVolume := config.Visor.MaxCoinSupply * droplet.Multiplier
+
+	g, err := devnet.NewGenesis(seed, coinVolume, timestamp, int(config.Visor.DistributionAddressesTotal))
+	if err != nil {
+		return nil, err
+	}
+
+	config.Node.GenesisSignatureStr = g.Signature.Hex()
+	config.Node.GenesisAddressStr = g.Address.String()
+	config.Node.BlockchainPubkeyStr = g.BlockchainPubkey.Hex()
+	config.Node.BlockchainSeckeyStr = ""
+	config.Node.GenesisTimestamp = g.Timestamp
+	config.Node.GenesisCoinVolume = g.CoinVolume
+
+	config.Visor.DistributionAddresses = make([]string, len(g.DistributionAddresses))
+	for i, a := range g.DistributionAddresses {
+		config.Visor.DistributionAddresses[i] = a.String()
+	}
+
+	return &GenesisKeys{
+		Seed:             seed,
+		BlockchainPubkey: g.BlockchainPubkey.Hex(),
+		BlockchainSeckey: g.BlockchainSeckey.Hex(),
+		GenesisAddress:   g.Address.String(),
+	}, nil
+}
+
+// verifyGenesis checks that the genesis parameters of the config are consistent
+func verifyGenesis(config skycoin.Parameters) error {
+	pubkey, err := cipher.PubKeyFromHex(config.Node.BlockchainPubkeyStr)
+	if err != nil {
+		return fmt.Errorf("invalid blockchain_pubkey_str: %v", err)
+	}
+
+	addr, err := cipher.DecodeBase58Address(config.Node.GenesisAddressStr)
+	if err != nil {
+		return fmt.Errorf("invalid genesis_address_str: %v", err)
+	}
+
+	sig, err := cipher.SigFromHex(config.Node.GenesisSignatureStr)
+	if err != nil {
+		return fmt.Errorf("invalid genesis_signature_str: %v", err)
+	}
+
+	b, err := coin.NewGenesisBlock(addr, config.Node.GenesisCoinVolume, config.Node.GenesisTimestamp)
+	if err != nil {
+		return err
+	}
+
+	if err := cipher.VerifySignature(pubkey, sig, b.HashHeader()); err != nil {
+		return fmt.Errorf("genesis_signature_str is not a signature of the genesis block by blockchain_pubkey_str: %v", err)
+	}
+
+	if config.Node.GenesisCoinVolume != config.Visor.MaxCoinSupply*droplet.Multiplier {
+		return errors.New("genesis_coin_volume does not match max_coin_supply")
+	}
+
+	if uint64(len(config.Visor.DistributionAddresses)) != config.Visor.DistributionAddressesTotal {
+		return fmt.Errorf("there are %d distribution_addresses, expected distribution_addresses_total %d",
+			len(config.Visor.DistributionAddresses), config.Visor.DistributionAddressesTotal)
+	}
+
+	seen := make(map[string]struct{}, len(config.Visor.DistributionAddresses))
+	for _, a := range config.Visor.DistributionAddresses {
+		if _, err := cipher.DecodeBase58Address(a); err != nil {
+			return fmt.Errorf("invalid distribution address %s: %v", a, err)
+		}
+		if _, ok := seen[a]; ok {
+			return fmt.Errorf("duplicate distribution address %s", a)
+		}
+		seen[a] = struct{}{}
+	}
+
+	return nil
+}
+
+// verifyCoin verifies the genesis parameters of the config and that they are generated from the seed.
+// Then it boots the coin in a single node devnet, distributes the genesis coins and checks that
+// every distribution address has its initial balance.
+func verifyCoin(config skycoin.Parameters, seed string, port, webInterfacePort int) error {
+	if err := verifyGenesis(config); err != nil {
+		return err
+	}
+
+	dir, err := ioutil.TempDir("", "newcoin")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	dn, err := devnet.New(devnet.Config{
+		Nodes:                 1,
+		Seed:                  seed,
+		Dir:                   dir,
+		Port:                  port,
+		WebInterfacePort:      webInterfacePort,
+		GenesisCoinVolume:     config.Node.GenesisCoinVolume,
+		GenesisTimestamp:      config.Node.GenesisTimestamp,
+		DistributionAddresses: len(config.Visor.DistributionAddresses),
+	})
+	if err != nil {
+		return err
+	}
+
+	if dn.Genesis.BlockchainPubkey.Hex() != config.Node.BlockchainPubkeyStr ||
+		dn.Genesis.Address.String() != config.Node.GenesisAddressStr {
+		return errors.New("the genesis keys are not generated from the seed")
+	}
+
+	addrs := make([]cipher.Address, len(config.Visor.DistributionAddresses))
+	for i, a := range config.Visor.DistributionAddresses {
+		if a != dn.Genesis.DistributionAddresses[i].String() {
+			return fmt.Errorf("distribution address %s is not generated from the seed", a)
+		}
+		addrs[i] = dn.Genesis.DistributionAddresses[i]
+	}
+
+	log.Infof("Booting the coin in %s", dir)
+
+	if err := dn.Start(); err != nil {
+		return err
+	}
+	defer dn.Shutdown()
+
+	gw := dn.Master().Gateway()
+
+	genesis, err := gw.GetSignedBlockBySeq(0)
+	if err != nil {
+		return err
+	}
+	if genesis == nil || genesis.Body.Transactions[0].Out[0].Address != dn.Genesis.Address {
+		return errors.New("the node did not create the genesis block")
+	}
+
+	balances, err := gw.GetBalanceOfAddrs(addrs)
+	if err != nil {
+		return err
+	}
+
+	initialBalance := config.Visor.MaxCoinSupply / config.Visor.DistributionAddressesTotal * droplet.Multiplier
+	for i, b := range balances {
+		if b.Confirmed.Coins != initialBalance {
+			return fmt.Errorf("distribution address %s has %d droplets, expected %d", addrs[i], b.Confirmed.Coins, initialBalance)
+		}
+	}
+
+	return nil
+}
+
+func writeFiberConfig(path, templatePath string, config skycoin.Parameters) error {
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return t.Execute(f, config)
+}
+
+func writeGenesisKeys(path string, keys *GenesisKeys) error {
+	b, err := json.MarshalIndent(keys, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, b, 0600)
+}
+
+func readGenesisKeys(path string) (*GenesisKeys, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys GenesisKeys
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return nil, fmt.Errorf("invalid keys file %s: %v", path, err)
+	}
+
+	if keys.Seed == "" {
+		return nil, fmt.Errorf("keys file %s has no seed", path)
+	}
+
+	return &keys, nil
+}
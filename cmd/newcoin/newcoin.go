@@ -46,6 +46,8 @@ func init() {
 	app.Version = Version
 	commands := cli.Commands{
 		createCoinCommand(),
+		createGenesisCommand(),
+		verifyCoinCommand(),
 	}
 
 	app.Commands = commands
@@ -127,80 +129,82 @@ func createCoinCommand() cli.Command {
 
 			coinName := c.String("coin")
 
-			// -- parse template and create new coin.go and visor parameters.go -- //
-
 			config, err := skycoin.NewParameters(configFile, configDir)
 			if err != nil {
 				log.Errorf("failed to create new fiber coin config")
 				return err
 			}
 
-			coinDir := fmt.Sprintf("./cmd/%s", coinName)
-			// create new coin directory
-			// MkdirAll does not error out if the directory already exists
-			err = os.MkdirAll(coinDir, 0755)
-			if err != nil {
-				log.Errorf("failed to create new coin directory %s", coinDir)
-				return err
-			}
-
-			// we have to always create a new file otherwise the templating gives an error
-			coinFilePath := fmt.Sprintf("./cmd/%[1]s/%[1]s.go", coinName)
-			coinFile, err := os.Create(coinFilePath)
-			if err != nil {
-				log.Errorf("failed to create new coin file %s", coinFilePath)
-				return err
-			}
-
-			visorParamsFile, err := os.Create("./src/visor/parameters.go")
-			if err != nil {
-				log.Errorf("failed to create new visor parameters.go")
-				return err
-			}
+			return createCoin(coinName, templateDir, coinTemplateFile, visorTemplateFile, config)
+		},
+	}
+}
 
-			// change dir so that text/template can parse the file
-			err = os.Chdir(templateDir)
-			if err != nil {
-				log.Errorf("failed to change directory to %s", templateDir)
-				return err
-			}
+// createCoin creates the new coin's cmd/<coin>/<coin>.go and src/visor/parameters.go
+// from the coin and visor templates, with the parameters of a fiber config
+func createCoin(coinName, templateDir, coinTemplateFile, visorTemplateFile string, config skycoin.Parameters) error {
+	// -- parse template and create new coin.go and visor parameters.go -- //
+
+	coinDir := fmt.Sprintf("./cmd/%s", coinName)
+	// create new coin directory
+	// MkdirAll does not error out if the directory already exists
+	err := os.MkdirAll(coinDir, 0755)
+	if err != nil {
+		log.Errorf("failed to create new coin directory %s", coinDir)
+		return err
+	}
 
-			t := template.New(coinTemplateFile)
-			t, err = t.ParseFiles(coinTemplateFile, visorTemplateFile)
-			if err != nil {
-				log.Errorf("failed to parse template file: %s", coinTemplateFile)
-				return err
-			}
+	// we have to always create a new file otherwise the templating gives an error
+	coinFilePath := fmt.Sprintf("./cmd/%[1]s/%[1]s.go", coinName)
+	coinFile, err := os.Create(coinFilePath)
+	if err != nil {
+		log.Errorf("failed to create new coin file %s", coinFilePath)
+		return err
+	}
+	defer coinFile.Close()
 
-			err = t.ExecuteTemplate(coinFile, coinTemplateFile, CoinTemplateParameters{
-				Version:             config.Build.Version,
-				PeerListURL:         config.Node.PeerListURL,
-				Port:                config.Node.Port,
-				WebInterfacePort:    config.Node.WebInterfacePort,
-				DataDirectory:       "$HOME/." + coinName,
-				ProfileCPUFile:      coinName + ".prof",
-				GenesisSignatureStr: config.Node.GenesisSignatureStr,
-				GenesisAddressStr:   config.Node.GenesisAddressStr,
-				BlockchainPubkeyStr: config.Node.BlockchainPubkeyStr,
-				BlockchainSeckeyStr: config.Node.BlockchainSeckeyStr,
-				GenesisTimestamp:    config.Node.GenesisTimestamp,
-				GenesisCoinVolume:   config.Node.GenesisCoinVolume,
-				DefaultConnections:  config.Node.DefaultConnections,
-			})
-			if err != nil {
-				log.Errorf("failed to parse coin template variables")
-				return err
-			}
+	visorParamsFile, err := os.Create("./src/visor/parameters.go")
+	if err != nil {
+		log.Errorf("failed to create new visor parameters.go")
+		return err
+	}
+	defer visorParamsFile.Close()
+
+	// the templates are named after their file names
+	t := template.New(coinTemplateFile)
+	t, err = t.ParseFiles(filepath.Join(templateDir, coinTemplateFile), filepath.Join(templateDir, visorTemplateFile))
+	if err != nil {
+		log.Errorf("failed to parse template file: %s", coinTemplateFile)
+		return err
+	}
 
-			err = t.ExecuteTemplate(visorParamsFile, visorTemplateFile, config.Visor)
-			if err != nil {
-				log.Errorf("failed to parse visor params template variables")
-				return err
-			}
+	err = t.ExecuteTemplate(coinFile, coinTemplateFile, CoinTemplateParameters{
+		Version:             config.Build.Version,
+		PeerListURL:         config.Node.PeerListURL,
+		Port:                config.Node.Port,
+		WebInterfacePort:    config.Node.WebInterfacePort,
+		DataDirectory:       "$HOME/." + coinName,
+		ProfileCPUFile:      coinName + ".prof",
+		GenesisSignatureStr: config.Node.GenesisSignatureStr,
+		GenesisAddressStr:   config.Node.GenesisAddressStr,
+		BlockchainPubkeyStr: config.Node.BlockchainPubkeyStr,
+		BlockchainSeckeyStr: config.Node.BlockchainSeckeyStr,
+		GenesisTimestamp:    config.Node.GenesisTimestamp,
+		GenesisCoinVolume:   config.Node.GenesisCoinVolume,
+		DefaultConnections:  config.Node.DefaultConnections,
+	})
+	if err != nil {
+		log.Errorf("failed to parse coin template variables")
+		return err
+	}
 
-			return nil
-		},
+	err = t.ExecuteTemplate(visorParamsFile, visorTemplateFile, config.Visor)
+	if err != nil {
+		log.Errorf("failed to parse visor params template variables")
+		return err
 	}
+
+	return nil
 }
 
 func main() {
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/file"
+	wh "github.com/skycoin/skycoin/src/util/http" //http,json helpers
+)
+
+const (
+	// APITokensFilename is the default name of the file that API tokens are stored in, in the data directory
+	APITokensFilename = "api_tokens.json"
+
+	// authScheme is the scheme of the Authorization header
+	authScheme = "Bearer"
+
+	apiTokenLength   = 32
+	apiTokenIDLength = 8
+)
+
+// Scope is a permission granted to an API token
+type Scope string
+
+const (
+	// ScopeRead allows reading the blockchain, unspent outputs and unconfirmed transactions
+	ScopeRead Scope = "read"
+	// ScopeWalletRead allows reading the wallets, their balances and transactions
+	ScopeWalletRead Scope = "wallet.read"
+	// ScopeWalletSpend allows creating, modifying and spending from wallets
+	ScopeWalletSpend Scope = "wallet.spend"
+	// ScopeAdmin allows managing the network connections and broadcasting transactions
+	ScopeAdmin Scope = "admin"
+)
+
+var (
+	// ErrAPITokenNotFound is returned if an API token does not exist
+	ErrAPITokenNotFound = errors.New("API token not found")
+	// ErrInvalidScope is returned if a scope is not recognized
+	ErrInvalidScope = errors.New("invalid scope, must be one of read, wallet.read, wallet.spend, admin")
+
+	// impliedScopes lists the scopes that each scope grants, including itself
+	impliedScopes = map[Scope][]Scope{
+		ScopeRead:        {ScopeRead},
+		ScopeWalletRead:  {ScopeWalletRead, ScopeRead},
+		ScopeWalletSpend: {ScopeWalletSpend, ScopeWalletRead, ScopeRead},
+		ScopeAdmin:       {ScopeAdmin, ScopeRead},
+	}
+)
+
+// ParseScope parses a scope name
+func ParseScope(s string) (Scope, error) {
+	scope := Scope(s)
+	if _, ok := impliedScopes[scope]; !ok {
+		return "", ErrInvalidScope
+	}
+	return scope, nil
+}
+
+// APIToken is an API token as stored in the tokens file. The token itself is not stored, only its hash.
+type APIToken struct {
+	ID        string  `json:"id"`
+	Label     string  `json:"label"`
+	Hash      string  `json:"hash"`
+	Scopes    []Scope `json:"scopes"`
+	CreatedAt int64   `json:"created_at"`
+}
+
+// Allows returns true if the token grants scope
+func (t APIToken) Allows(scope Scope) bool {
+	for _, s := range t.Scopes {
+		for _, implied := range impliedScopes[s] {
+			if implied == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type apiTokensFile struct {
+	Tokens []APIToken `json:"tokens"`
+}
+
+// hashAPIToken returns the hex encoded SHA256 hash of a token
+func hashAPIToken(token string) string {
+	return cipher.SumSHA256([]byte(token)).Hex()
+}
+
+// TokenStore manages the API tokens saved in a file.
+// The file is reloaded when it changes, so that tokens created or revoked
+// by another process (e.g. the CLI) take effect without restarting the node.
+type TokenStore struct {
+	filename string
+	modTime  time.Time
+	size     int64
+	tokens   map[string]APIToken // keyed by hash
+	sync.Mutex
+}
+
+// LoadTokenStore loads the API tokens from filename. If the file does not exist, the store is empty.
+func LoadTokenStore(filename string) (*TokenStore, error) {
+	s := &TokenStore{
+		filename: filename,
+		tokens:   make(map[string]APIToken),
+	}
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// reload reloads the tokens file if it was modified since it was last loaded
+func (s *TokenStore) reload() error {
+	fi, err := os.Stat(s.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.tokens = make(map[string]APIToken)
+			s.modTime = time.Time{}
+			s.size = 0
+			return nil
+		}
+		return err
+	}
+
+	if fi.ModTime().Equal(s.modTime) && fi.Size() == s.size {
+		return nil
+	}
+
+	var f apiTokensFile
+	if err := file.LoadJSON(s.filename, &f); err != nil {
+		return fmt.Errorf("load API tokens file %s failed: %v", s.filename, err)
+	}
+
+	tokens := make(map[string]APIToken, len(f.Tokens))
+	for _, t := range f.Tokens {
+		tokens[t.Hash] = t
+	}
+
+	s.tokens = tokens
+	s.modTime = fi.ModTime()
+	s.size = fi.Size()
+
+	return nil
+}
+
+// save writes the tokens to the tokens file
+func (s *TokenStore) save() error {
+	f := apiTokensFile{
+		Tokens: s.sortedTokens(),
+	}
+
+	if err := file.SaveJSON(s.filename, f, 0600); err != nil {
+		return err
+	}
+
+	// Force a reload on the next access, to pick up the new modification time
+	s.modTime = time.Time{}
+	return nil
+}
+
+func (s *TokenStore) sortedTokens() []APIToken {
+	tokens := make([]APIToken, 0, len(s.tokens))
+	for _, t := range s.tokens {
+		tokens = append(tokens, t)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].CreatedAt == tokens[j].CreatedAt {
+			return tokens[i].ID < tokens[j].ID
+		}
+		return tokens[i].CreatedAt < tokens[j].CreatedAt
+	})
+
+	return tokens
+}
+
+// Tokens returns the API tokens, ordered by creation time
+func (s *TokenStore) Tokens() ([]APIToken, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	return s.sortedTokens(), nil
+}
+
+// CreateToken creates a new API token with the given scopes and saves its hash.
+// Returns the token, which can not be recovered later.
+func (s *TokenStore) CreateToken(label string, scopes []Scope) (string, *APIToken, error) {
+	if len(scopes) == 0 {
+		return "", nil, errors.New("at least one scope is required")
+	}
+
+	for _, scope := range scopes {
+		if _, err := ParseScope(string(scope)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.reload(); err != nil {
+		return "", nil, err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(cipher.RandByte(apiTokenLength))
+	hash := hashAPIToken(token)
+
+	t := APIToken{
+		ID:        hash[:apiTokenIDLength*2],
+		Label:     label,
+		Hash:      hash,
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC().Unix(),
+	}
+
+	s.tokens[hash] = t
+
+	if err := s.save(); err != nil {
+		delete(s.tokens, hash)
+		return "", nil, err
+	}
+
+	return token, &t, nil
+}
+
+// RevokeToken deletes the API token with the given ID
+func (s *TokenStore) RevokeToken(id string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	for hash, t := range s.tokens {
+		if t.ID == id {
+			delete(s.tokens, hash)
+			return s.save()
+		}
+	}
+
+	return ErrAPITokenNotFound
+}
+
+// Lookup returns the APIToken for a token, or ErrAPITokenNotFound if it does not exist
+func (s *TokenStore) Lookup(token string) (*APIToken, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	t, ok := s.tokens[hashAPIToken(token)]
+	if !ok {
+		return nil, ErrAPITokenNotFound
+	}
+
+	return &t, nil
+}
+
+// AuthCheck verifies that the Authorization header has an API token that grants scope.
+// If store is nil, API authentication is disabled and all requests are allowed.
+func AuthCheck(store *TokenStore, scope Scope, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if store != nil {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				wh.Error401(w, authScheme, "missing API token")
+				return
+			}
+
+			prefix := authScheme + " "
+			if !strings.HasPrefix(header, prefix) {
+				wh.Error401(w, authScheme, "invalid Authorization header, must use the Bearer scheme")
+				return
+			}
+
+			t, err := store.Lookup(strings.TrimSpace(strings.TrimPrefix(header, prefix)))
+			switch err {
+			case nil:
+			case ErrAPITokenNotFound:
+				wh.Error401(w, authScheme, "invalid API token")
+				return
+			default:
+				logger.WithError(err).Error("API token lookup failed")
+				wh.Error500(w, "")
+				return
+			}
+
+			if !t.Allows(scope) {
+				scopeError(w, scope)
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), apiTokenKey{}, t))
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+type apiTokenKey struct{}
+
+// apiTokenFromContext returns the API token of a request verified by AuthCheck,
+// or nil if API authentication is disabled
+func apiTokenFromContext(ctx context.Context) *APIToken {
+	t, _ := ctx.Value(apiTokenKey{}).(*APIToken)
+	return t
+}
+
+// scopeError responds with 403 Forbidden to a request whose API token does not have scope
+func scopeError(w http.ResponseWriter, scope Scope) {
+	wh.Error403(w, fmt.Sprintf("API token does not have the %s scope", scope))
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func setupTokenStore(t *testing.T) (*TokenStore, func()) {
+	dir, err := ioutil.TempDir("", "apitokens")
+	require.NoError(t, err)
+
+	store, err := LoadTokenStore(filepath.Join(dir, APITokensFilename))
+	require.NoError(t, err)
+
+	return store, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	for _, s := range []string{"read", "wallet.read", "wallet.spend", "admin"} {
+		scope, err := ParseScope(s)
+		require.NoError(t, err)
+		require.Equal(t, Scope(s), scope)
+	}
+
+	_, err := ParseScope("wallet")
+	require.Equal(t, ErrInvalidScope, err)
+}
+
+func TestAPITokenAllows(t *testing.T) {
+	cases := []struct {
+		scopes  []Scope
+		allowed []Scope
+	}{
+		{
+			scopes:  []Scope{ScopeRead},
+			allowed: []Scope{ScopeRead},
+		},
+		{
+			scopes:  []Scope{ScopeWalletRead},
+			allowed: []Scope{ScopeRead, ScopeWalletRead},
+		},
+		{
+			scopes:  []Scope{ScopeWalletSpend},
+			allowed: []Scope{ScopeRead, ScopeWalletRead, ScopeWalletSpend},
+		},
+		{
+			scopes:  []Scope{ScopeAdmin},
+			allowed: []Scope{ScopeRead, ScopeAdmin},
+		},
+		{
+			scopes:  []Scope{ScopeAdmin, ScopeWalletRead},
+			allowed: []Scope{ScopeRead, ScopeWalletRead, ScopeAdmin},
+		},
+	}
+
+	all := []Scope{ScopeRead, ScopeWalletRead, ScopeWalletSpend, ScopeAdmin}
+
+	for _, tc := range cases {
+		tok := APIToken{Scopes: tc.scopes}
+		for _, s := range all {
+			var expected bool
+			for _, a := range tc.allowed {
+				if a == s {
+					expected = true
+				}
+			}
+			require.Equal(t, expected, tok.Allows(s), "scopes=%v scope=%s", tc.scopes, s)
+		}
+	}
+}
+
+func TestTokenStore(t *testing.T) {
+	store, teardown := setupTokenStore(t)
+	defer teardown()
+
+	tokens, err := store.Tokens()
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	_, _, err = store.CreateToken("foo", nil)
+	require.Error(t, err)
+
+	_, _, err = store.CreateToken("foo", []Scope{"bar"})
+	require.Equal(t, ErrInvalidScope, err)
+
+	token, tok, err := store.CreateToken("foo", []Scope{ScopeWalletRead})
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.Equal(t, "foo", tok.Label)
+	require.Equal(t, hashAPIToken(token), tok.Hash)
+	require.Len(t, tok.ID, apiTokenIDLength*2)
+
+	// Only the hash is saved
+	data, err := ioutil.ReadFile(store.filename)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), token)
+
+	found, err := store.Lookup(token)
+	require.NoError(t, err)
+	require.Equal(t, tok, found)
+
+	_, err = store.Lookup("xyz")
+	require.Equal(t, ErrAPITokenNotFound, err)
+
+	// Tokens created in another store with the same file are visible
+	other, err := LoadTokenStore(store.filename)
+	require.NoError(t, err)
+	token2, tok2, err := other.CreateToken("bar", []Scope{ScopeAdmin})
+	require.NoError(t, err)
+
+	found, err = store.Lookup(token2)
+	require.NoError(t, err)
+	require.Equal(t, tok2, found)
+
+	tokens, err = store.Tokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+
+	// Revoked tokens are removed from the other store
+	require.Equal(t, ErrAPITokenNotFound, other.RevokeToken("xyz"))
+	require.NoError(t, other.RevokeToken(tok.ID))
+
+	_, err = store.Lookup(token)
+	require.Equal(t, ErrAPITokenNotFound, err)
+
+	tokens, err = store.Tokens()
+	require.NoError(t, err)
+	require.Equal(t, []APIToken{*tok2}, tokens)
+}
+
+func TestAuthCheck(t *testing.T) {
+	store, teardown := setupTokenStore(t)
+	defer teardown()
+
+	readToken, _, err := store.CreateToken("read", []Scope{ScopeRead})
+	require.NoError(t, err)
+	spendToken, _, err := store.CreateToken("spend", []Scope{ScopeWalletSpend})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name          string
+		endpoint      string
+		authorization string
+		status        int
+		err           string
+	}{
+		{
+			name:     "missing token",
+			endpoint: "/api/v1/version",
+			status:   http.StatusUnauthorized,
+			err:      "401 Unauthorized - missing API token",
+		},
+		{
+			name:          "not bearer",
+			endpoint:      "/api/v1/version",
+			authorization: "Basic " + readToken,
+			status:        http.StatusUnauthorized,
+			err:           "401 Unauthorized - invalid Authorization header, must use the Bearer scheme",
+		},
+		{
+			name:          "invalid token",
+			endpoint:      "/api/v1/version",
+			authorization: "Bearer foo",
+			status:        http.StatusUnauthorized,
+			err:           "401 Unauthorized - invalid API token",
+		},
+		{
+			name:          "read token, read endpoint",
+			endpoint:      "/api/v1/version",
+			authorization: "Bearer " + readToken,
+			status:        http.StatusOK,
+		},
+		{
+			name:          "read token, wallet endpoint",
+			endpoint:      "/api/v1/wallets",
+			authorization: "Bearer " + readToken,
+			status:        http.StatusForbidden,
+			err:           "403 Forbidden - API token does not have the wallet.read scope",
+		},
+		{
+			name:          "spend token, admin endpoint",
+			endpoint:      "/api/v1/network/connections",
+			authorization: "Bearer " + spendToken,
+			status:        http.StatusForbidden,
+			err:           "403 Forbidden - API token does not have the admin scope",
+		},
+		{
+			name:          "spend token, read endpoint",
+			endpoint:      "/api/v1/version",
+			authorization: "Bearer " + spendToken,
+			status:        http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &GatewayerMock{}
+			gateway.On("GetBuildInfo").Return(visor.BuildInfo{Version: "0.24.0"})
+
+			req, err := http.NewRequest(http.MethodGet, tc.endpoint, nil)
+			require.NoError(t, err)
+
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(muxConfig{
+				host:   configuredHost,
+				appLoc: ".",
+				tokens: store,
+			}, gateway, &CSRFStore{}, nil)
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "wrong status code: got `%v` want `%v`", rr.Code, tc.status)
+			if tc.err != "" {
+				require.Equal(t, tc.err+"\n", rr.Body.String())
+			}
+		})
+	}
+}
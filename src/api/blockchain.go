@@ -3,6 +3,7 @@ package api
 // APIs for blockchain related information
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -15,6 +16,12 @@ import (
 
 func blockchainHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
 		metadata, err := gateway.GetBlockchainMetadata()
 		if err != nil {
 			err = fmt.Errorf("gateway.GetBlockchainMetadata failed: %v", err)
@@ -28,6 +35,12 @@ func blockchainHandler(gateway Gatewayer) http.HandlerFunc {
 
 func blockchainProgressHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
 		progress, err := gateway.GetBlockchainProgress()
 		if err != nil {
 			err = fmt.Errorf("gateway.GetBlockchainProgress failed: %v", err)
@@ -39,12 +52,74 @@ func blockchainProgressHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// Returns the key rotations of the blockchain signing keys, ordered by seq
+// method: GET
+// url: /blockchain/keyRotations
+func keyRotationsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		rotations, err := gateway.GetKeyRotations()
+		if err != nil {
+			err = fmt.Errorf("gateway.GetKeyRotations failed: %v", err)
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, visor.NewReadableKeyRotations(rotations))
+	}
+}
+
+// Adds a signed key rotation of the blockchain signing keys and broadcasts it
+// method: POST
+// url: /blockchain/injectKeyRotation
+// body: visor.ReadableKeyRotation
+func injectKeyRotationHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		var rr visor.ReadableKeyRotation
+		if err := json.NewDecoder(r.Body).Decode(&rr); err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		kr, err := rr.ToKeyRotation()
+		if err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		if err := kr.Verify(); err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		if err := gateway.InjectBroadcastKeyRotation(*kr); err != nil {
+			err = fmt.Errorf("inject key rotation failed: %v", err)
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, visor.NewReadableKeyRotation(*kr))
+	}
+}
+
 // get block by hash or seq
 // method: GET
 // url: /block?hash=[:hash]  or /block?seq[:seq]
 // params: hash or seq, should only specify one filter.
 func getBlock(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -101,8 +176,9 @@ func getBlock(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
-func getBlocks(gateway Gatewayer) http.HandlerFunc {
+func getBlocks(gateway Gatewayer, maxBlockRange uint64) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -120,6 +196,12 @@ func getBlocks(gateway Gatewayer) http.HandlerFunc {
 			wh.Error400(w, fmt.Sprintf("Invalid end value \"%s\"", send))
 			return
 		}
+
+		if maxBlockRange > 0 && end >= start && end-start >= maxBlockRange {
+			wh.Error400(w, fmt.Sprintf("Block range is too large, at most %d blocks can be requested", maxBlockRange))
+			return
+		}
+
 		rb, err := gateway.GetBlocks(start, end)
 		if err != nil {
 			wh.Error400(w, fmt.Sprintf("Get blocks failed: %v", err))
@@ -130,8 +212,9 @@ func getBlocks(gateway Gatewayer) http.HandlerFunc {
 }
 
 // get last N blocks
-func getLastBlocks(gateway Gatewayer) http.HandlerFunc {
+func getLastBlocks(gateway Gatewayer, maxBlockRange uint64) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -149,6 +232,11 @@ func getLastBlocks(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		if maxBlockRange > 0 && n > maxBlockRange {
+			wh.Error400(w, fmt.Sprintf("num is too large, at most %d blocks can be requested", maxBlockRange))
+			return
+		}
+
 		rb, err := gateway.GetLastBlocks(n)
 		if err != nil {
 			wh.Error400(w, fmt.Sprintf("Get last %v blocks failed: %v", n, err))
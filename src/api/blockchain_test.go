@@ -487,3 +487,177 @@ func TestGetLastBlocks(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyRotations(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	kr, err := coin.NewKeyRotation(10, []cipher.PubKey{pk}, sk)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name         string
+		method       string
+		status       int
+		err          string
+		gatewayRet   []coin.KeyRotation
+		gatewayError error
+		response     []visor.ReadableKeyRotation
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:         "500 - gateway error",
+			method:       http.MethodGet,
+			status:       http.StatusInternalServerError,
+			err:          "500 Internal Server Error - gateway.GetKeyRotations failed: gatewayError",
+			gatewayError: errors.New("gatewayError"),
+		},
+		{
+			name:     "200 - no key rotations",
+			method:   http.MethodGet,
+			status:   http.StatusOK,
+			response: []visor.ReadableKeyRotation{},
+		},
+		{
+			name:       "200",
+			method:     http.MethodGet,
+			status:     http.StatusOK,
+			gatewayRet: []coin.KeyRotation{*kr},
+			response: []visor.ReadableKeyRotation{{
+				Seq:     10,
+				Pubkeys: []string{pk.Hex()},
+				Sig:     kr.Sig.Hex(),
+			}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := NewGatewayerMock()
+			gateway.On("GetKeyRotations").Return(tc.gatewayRet, tc.gatewayError)
+
+			req, err := http.NewRequest(tc.method, "/api/v1/blockchain/keyRotations", nil)
+			require.NoError(t, err)
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, csrfStore, nil)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+			} else {
+				var msg []visor.ReadableKeyRotation
+				err = json.Unmarshal(rr.Body.Bytes(), &msg)
+				require.NoError(t, err)
+				require.Equal(t, tc.response, msg)
+			}
+		})
+	}
+}
+
+func TestInjectKeyRotation(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	kr, err := coin.NewKeyRotation(10, []cipher.PubKey{pk}, sk)
+	require.NoError(t, err)
+	rkr := visor.NewReadableKeyRotation(*kr)
+
+	rkrJSON, err := json.Marshal(rkr)
+	require.NoError(t, err)
+
+	unsigned := rkr
+	unsigned.Sig = cipher.Sig{}.Hex()
+	unsignedJSON, err := json.Marshal(unsigned)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name         string
+		method       string
+		body         string
+		status       int
+		err          string
+		gatewayError error
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "400 - invalid json",
+			method: http.MethodPost,
+			body:   "{",
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - unexpected EOF",
+		},
+		{
+			name:   "400 - invalid pubkey",
+			method: http.MethodPost,
+			body:   `{"seq":10,"pubkeys":["foo"],"sig":"` + rkr.Sig + `"}`,
+			status: http.StatusBadRequest,
+			err:    `400 Bad Request - Invalid pubkey "foo": Invalid public key`,
+		},
+		{
+			name:   "400 - invalid signature",
+			method: http.MethodPost,
+			body:   string(unsignedJSON),
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid key rotation sig: PubKey recovery failed",
+		},
+		{
+			name:         "400 - gateway error",
+			method:       http.MethodPost,
+			body:         string(rkrJSON),
+			status:       http.StatusBadRequest,
+			err:          "400 Bad Request - inject key rotation failed: Key rotation is not signed by a blockchain pubkey",
+			gatewayError: errors.New("Key rotation is not signed by a blockchain pubkey"),
+		},
+		{
+			name:   "200",
+			method: http.MethodPost,
+			body:   string(rkrJSON),
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := NewGatewayerMock()
+			gateway.On("InjectBroadcastKeyRotation", *kr).Return(tc.gatewayError)
+
+			req, err := http.NewRequest(tc.method, "/api/v1/blockchain/injectKeyRotation", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, csrfStore, nil)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+			} else {
+				var msg visor.ReadableKeyRotation
+				err = json.Unmarshal(rr.Body.Bytes(), &msg)
+				require.NoError(t, err)
+				require.Equal(t, rkr, msg)
+			}
+		})
+	}
+}
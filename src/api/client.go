@@ -10,12 +10,12 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/visor"
-	"github.com/skycoin/skycoin/src/visor/historydb"
 	"github.com/skycoin/skycoin/src/wallet"
 )
 
@@ -30,6 +30,8 @@ type ClientError struct {
 	Status     string
 	StatusCode int
 	Message    string
+	// ErrorCode is the machine readable error code of a v2 API error
+	ErrorCode ErrorCode
 }
 
 func (e ClientError) Error() string {
@@ -46,6 +48,8 @@ type ReceivedHTTPResponse struct {
 type Client struct {
 	HTTPClient *http.Client
 	Addr       string
+	// AuthToken is sent as a Bearer token, if the node requires API authentication
+	AuthToken string
 }
 
 // NewClient creates a Client
@@ -108,9 +112,18 @@ func (c *Client) get(endpoint string) (*http.Response, error) {
 		return nil, err
 	}
 
+	c.setAuthHeader(req)
+
 	return c.HTTPClient.Do(req)
 }
 
+// setAuthHeader sets the Authorization header, if an API token is configured
+func (c *Client) setAuthHeader(req *http.Request) {
+	if c.AuthToken != "" {
+		req.Header.Set("Authorization", authScheme+" "+c.AuthToken)
+	}
+}
+
 // PostForm makes a POST request to an endpoint with body of "application/x-www-form-urlencoded" formated data.
 func (c *Client) PostForm(endpoint string, body io.Reader, obj interface{}) error {
 	return c.post(endpoint, "application/x-www-form-urlencoded", body, obj)
@@ -145,6 +158,8 @@ func (c *Client) post(endpoint string, contentType string, body io.Reader, obj i
 		req.Header.Set(CSRFHeaderName, csrf)
 	}
 
+	c.setAuthHeader(req)
+
 	req.Header.Set("Content-Type", contentType)
 
 	resp, err := c.HTTPClient.Do(req)
@@ -201,6 +216,8 @@ func (c *Client) PostJSONV2(endpoint string, reqObj, respObj interface{}) (bool,
 		req.Header.Set(CSRFHeaderName, csrf)
 	}
 
+	c.setAuthHeader(req)
+
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
@@ -242,6 +259,7 @@ func (c *Client) PostJSONV2(endpoint string, reqObj, respObj interface{}) (bool,
 			Status:     resp.Status,
 			StatusCode: resp.StatusCode,
 			Message:    wrapObj.Error.Message,
+			ErrorCode:  wrapObj.Error.ErrorCode,
 		}
 	}
 
@@ -299,193 +317,6 @@ func (c *Client) CSRF() (string, error) {
 	return token, nil
 }
 
-// Version makes a request to GET /api/v1/version
-func (c *Client) Version() (*visor.BuildInfo, error) {
-	var bi visor.BuildInfo
-	if err := c.Get("/api/v1/version", &bi); err != nil {
-		return nil, err
-	}
-	return &bi, nil
-}
-
-// Outputs makes a request to GET /api/v1/outputs
-func (c *Client) Outputs() (*visor.ReadableOutputSet, error) {
-	var o visor.ReadableOutputSet
-	if err := c.Get("/api/v1/outputs", &o); err != nil {
-		return nil, err
-	}
-	return &o, nil
-}
-
-// OutputsForAddresses makes a request to GET /api/v1/outputs?addrs=xxx
-func (c *Client) OutputsForAddresses(addrs []string) (*visor.ReadableOutputSet, error) {
-	v := url.Values{}
-	v.Add("addrs", strings.Join(addrs, ","))
-	endpoint := "/api/v1/outputs?" + v.Encode()
-
-	var o visor.ReadableOutputSet
-	if err := c.Get(endpoint, &o); err != nil {
-		return nil, err
-	}
-	return &o, nil
-}
-
-// OutputsForHashes makes a request to GET /api/v1/outputs?hashes=zzz
-func (c *Client) OutputsForHashes(hashes []string) (*visor.ReadableOutputSet, error) {
-	v := url.Values{}
-	v.Add("hashes", strings.Join(hashes, ","))
-	endpoint := "/api/v1/outputs?" + v.Encode()
-
-	var o visor.ReadableOutputSet
-	if err := c.Get(endpoint, &o); err != nil {
-		return nil, err
-	}
-	return &o, nil
-}
-
-// CoinSupply makes a request to GET /api/v1/coinSupply
-func (c *Client) CoinSupply() (*CoinSupply, error) {
-	var cs CoinSupply
-	if err := c.Get("/api/v1/coinSupply", &cs); err != nil {
-		return nil, err
-	}
-	return &cs, nil
-}
-
-// BlockByHash makes a request to GET /api/v1/block?hash=xxx
-func (c *Client) BlockByHash(hash string) (*visor.ReadableBlock, error) {
-	v := url.Values{}
-	v.Add("hash", hash)
-	endpoint := "/api/v1/block?" + v.Encode()
-
-	var b visor.ReadableBlock
-	if err := c.Get(endpoint, &b); err != nil {
-		return nil, err
-	}
-	return &b, nil
-}
-
-// BlockBySeq makes a request to GET /api/v1/block?seq=xxx
-func (c *Client) BlockBySeq(seq uint64) (*visor.ReadableBlock, error) {
-	v := url.Values{}
-	v.Add("seq", fmt.Sprint(seq))
-	endpoint := "/api/v1/block?" + v.Encode()
-
-	var b visor.ReadableBlock
-	if err := c.Get(endpoint, &b); err != nil {
-		return nil, err
-	}
-	return &b, nil
-}
-
-// Blocks makes a request to GET /api/v1/blocks
-func (c *Client) Blocks(start, end int) (*visor.ReadableBlocks, error) {
-	v := url.Values{}
-	v.Add("start", fmt.Sprint(start))
-	v.Add("end", fmt.Sprint(end))
-	endpoint := "/api/v1/blocks?" + v.Encode()
-
-	var b visor.ReadableBlocks
-	if err := c.Get(endpoint, &b); err != nil {
-		return nil, err
-	}
-	return &b, nil
-}
-
-// LastBlocks makes a request to GET /api/v1/last_blocks
-func (c *Client) LastBlocks(n int) (*visor.ReadableBlocks, error) {
-	v := url.Values{}
-	v.Add("num", fmt.Sprint(n))
-	endpoint := "/api/v1/last_blocks?" + v.Encode()
-
-	var b visor.ReadableBlocks
-	if err := c.Get(endpoint, &b); err != nil {
-		return nil, err
-	}
-	return &b, nil
-}
-
-// BlockchainMetadata makes a request to GET /api/v1/blockchain/metadata
-func (c *Client) BlockchainMetadata() (*visor.BlockchainMetadata, error) {
-	var b visor.BlockchainMetadata
-	if err := c.Get("/api/v1/blockchain/metadata", &b); err != nil {
-		return nil, err
-	}
-	return &b, nil
-}
-
-// BlockchainProgress makes a request to GET /api/v1/blockchain/progress
-func (c *Client) BlockchainProgress() (*daemon.BlockchainProgress, error) {
-	var b daemon.BlockchainProgress
-	if err := c.Get("/api/v1/blockchain/progress", &b); err != nil {
-		return nil, err
-	}
-	return &b, nil
-}
-
-// Balance makes a request to GET /api/v1/balance?addrs=xxx
-func (c *Client) Balance(addrs []string) (*wallet.BalancePair, error) {
-	v := url.Values{}
-	v.Add("addrs", strings.Join(addrs, ","))
-	endpoint := "/api/v1/balance?" + v.Encode()
-
-	var b wallet.BalancePair
-	if err := c.Get(endpoint, &b); err != nil {
-		return nil, err
-	}
-	return &b, nil
-}
-
-// UxOut makes a request to GET /api/v1/uxout?uxid=xxx
-func (c *Client) UxOut(uxID string) (*historydb.UxOutJSON, error) {
-	v := url.Values{}
-	v.Add("uxid", uxID)
-	endpoint := "/api/v1/uxout?" + v.Encode()
-
-	var b historydb.UxOutJSON
-	if err := c.Get(endpoint, &b); err != nil {
-		return nil, err
-	}
-	return &b, nil
-}
-
-// AddressUxOuts makes a request to GET /api/v1/address_uxouts
-func (c *Client) AddressUxOuts(addr string) ([]*historydb.UxOutJSON, error) {
-	v := url.Values{}
-	v.Add("address", addr)
-	endpoint := "/api/v1/address_uxouts?" + v.Encode()
-
-	var b []*historydb.UxOutJSON
-	if err := c.Get(endpoint, &b); err != nil {
-		return nil, err
-	}
-	return b, nil
-}
-
-// Wallet makes a request to GET /api/v1/wallet
-func (c *Client) Wallet(id string) (*WalletResponse, error) {
-	v := url.Values{}
-	v.Add("id", id)
-	endpoint := "/api/v1/wallet?" + v.Encode()
-
-	var wr WalletResponse
-	if err := c.Get(endpoint, &wr); err != nil {
-		return nil, err
-	}
-
-	return &wr, nil
-}
-
-// Wallets makes a request to GET /api/v1/wallets
-func (c *Client) Wallets() ([]*WalletResponse, error) {
-	var wrs []*WalletResponse
-	if err := c.Get("/api/v1/wallets", &wrs); err != nil {
-		return nil, err
-	}
-
-	return wrs, nil
-}
-
 // CreateUnencryptedWallet makes a request to POST /api/v1/wallet/create and creates
 // a wallet without encryption.
 // If scanN is <= 0, the scan number defaults to 1
@@ -547,39 +378,10 @@ func (c *Client) NewWalletAddress(id string, n int, password string) ([]string,
 	return obj.Addresses, nil
 }
 
-// WalletBalance makes a request to GET /api/v1/wallet/balance
-func (c *Client) WalletBalance(id string) (*BalanceResponse, error) {
-	v := url.Values{}
-	v.Add("id", id)
-	endpoint := "/api/v1/wallet/balance?" + v.Encode()
-
-	var b BalanceResponse
-	if err := c.Get(endpoint, &b); err != nil {
-		return nil, err
-	}
-	return &b, nil
-}
-
-// Spend makes a request to POST /api/v1/wallet/spend
-func (c *Client) Spend(id, dst string, coins uint64, password string) (*SpendResult, error) {
-	v := url.Values{}
-	v.Add("id", id)
-	v.Add("dst", dst)
-	v.Add("coins", fmt.Sprint(coins))
-	v.Add("password", password)
-
-	var r SpendResult
-	endpoint := "/api/v1/wallet/spend"
-	if err := c.PostForm(endpoint, strings.NewReader(v.Encode()), &r); err != nil {
-		return nil, err
-	}
-
-	return &r, nil
-}
-
 // CreateTransactionRequest is sent to /wallet/transaction
 type CreateTransactionRequest struct {
 	IgnoreUnconfirmed bool                           `json:"ignore_unconfirmed"`
+	SpendUnconfirmed  bool                           `json:"spend_unconfirmed"`
 	HoursSelection    HoursSelection                 `json:"hours_selection"`
 	Wallet            CreateTransactionRequestWallet `json:"wallet"`
 	ChangeAddress     *string                        `json:"change_address,omitempty"`
@@ -608,48 +410,6 @@ type Receiver struct {
 	Hours   string `json:"hours,omitempty"`
 }
 
-// CreateTransaction makes a request to POST /api/v1/wallet/transaction
-func (c *Client) CreateTransaction(req CreateTransactionRequest) (*CreateTransactionResponse, error) {
-	var r CreateTransactionResponse
-	endpoint := "/api/v1/wallet/transaction"
-	if err := c.PostJSON(endpoint, req, &r); err != nil {
-		return nil, err
-	}
-
-	return &r, nil
-}
-
-// WalletTransactions makes a request to GET /api/v1/wallet/transactions
-func (c *Client) WalletTransactions(id string) (*UnconfirmedTxnsResponse, error) {
-	v := url.Values{}
-	v.Add("id", id)
-	endpoint := "/api/v1/wallet/transactions?" + v.Encode()
-
-	var utx *UnconfirmedTxnsResponse
-	if err := c.Get(endpoint, &utx); err != nil {
-		return nil, err
-	}
-	return utx, nil
-}
-
-// UpdateWallet makes a request to POST /api/v1/wallet/update
-func (c *Client) UpdateWallet(id, label string) error {
-	v := url.Values{}
-	v.Add("id", id)
-	v.Add("label", label)
-
-	return c.PostForm("/api/v1/wallet/update", strings.NewReader(v.Encode()), nil)
-}
-
-// WalletFolderName makes a request to GET /api/v1/wallets/folderName
-func (c *Client) WalletFolderName() (*WalletFolder, error) {
-	var w WalletFolder
-	if err := c.Get("/api/v1/wallets/folderName", &w); err != nil {
-		return nil, err
-	}
-	return &w, nil
-}
-
 // NewSeed makes a request to GET /api/v1/wallet/newSeed
 // entropy must be 128 or 256
 func (c *Client) NewSeed(entropy int) (string, error) {
@@ -682,81 +442,87 @@ func (c *Client) GetWalletSeed(id string, password string) (string, error) {
 	return r.Seed, nil
 }
 
-// NetworkConnection makes a request to GET /api/v1/network/connection
-func (c *Client) NetworkConnection(addr string) (*daemon.Connection, error) {
-	v := url.Values{}
-	v.Add("addr", addr)
-	endpoint := "/api/v1/network/connection?" + v.Encode()
-
-	var dc daemon.Connection
-	if err := c.Get(endpoint, &dc); err != nil {
+// ImportWallet makes a request to POST /api/v1/wallet/import to restore a wallet
+// from a bundle made by ExportWallet
+func (c *Client) ImportWallet(bundle wallet.EncryptedBundle, password string) (*WalletResponse, error) {
+	b, err := json.Marshal(bundle)
+	if err != nil {
 		return nil, err
 	}
-	return &dc, nil
-}
 
-// NetworkConnections makes a request to GET /api/v1/network/connections
-func (c *Client) NetworkConnections() (*Connections, error) {
-	var dc Connections
-	if err := c.Get("/api/v1/network/connections", &dc); err != nil {
+	v := url.Values{}
+	v.Add("bundle", string(b))
+	v.Add("password", password)
+
+	var w WalletResponse
+	if err := c.PostForm("/api/v1/wallet/import", strings.NewReader(v.Encode()), &w); err != nil {
 		return nil, err
 	}
-	return &dc, nil
+	return &w, nil
 }
 
-// NetworkDefaultConnections makes a request to GET /api/v1/network/defaultConnections
-func (c *Client) NetworkDefaultConnections() ([]string, error) {
-	var dc []string
-	if err := c.Get("/api/v1/network/defaultConnections", &dc); err != nil {
-		return nil, err
+// ChangeWalletPassword makes a request to POST /api/v1/wallet/changePassword to re-encrypt
+// a wallet with a new password and/or crypto type
+func (c *Client) ChangeWalletPassword(id, oldPassword string, opts wallet.ChangePasswordOptions) (*WalletResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("old_password", oldPassword)
+	if len(opts.NewPassword) != 0 {
+		v.Add("new_password", string(opts.NewPassword))
+	}
+	if opts.CryptoType != "" {
+		v.Add("crypto_type", string(opts.CryptoType))
+	}
+	if opts.ScryptParams != nil {
+		v.Add("scrypt_n", strconv.Itoa(opts.ScryptParams.N))
+		v.Add("scrypt_r", strconv.Itoa(opts.ScryptParams.R))
+		v.Add("scrypt_p", strconv.Itoa(opts.ScryptParams.P))
 	}
-	return dc, nil
-}
 
-// NetworkTrustedConnections makes a request to GET /api/v1/network/connections/trust
-func (c *Client) NetworkTrustedConnections() ([]string, error) {
-	var dc []string
-	if err := c.Get("/api/v1/network/connections/trust", &dc); err != nil {
+	var w WalletResponse
+	if err := c.PostForm("/api/v1/wallet/changePassword", strings.NewReader(v.Encode()), &w); err != nil {
 		return nil, err
 	}
-	return dc, nil
+	return &w, nil
 }
 
-// NetworkExchangeableConnections makes a request to GET /api/v1/network/connections/exchange
-func (c *Client) NetworkExchangeableConnections() ([]string, error) {
-	var dc []string
-	if err := c.Get("/api/v1/network/connections/exchange", &dc); err != nil {
-		return nil, err
+// ExpiredPendingTransactions makes a request to GET /api/v1/pendingTxs/expired.
+// If maxAge is 0, the node's configured max age is used.
+func (c *Client) ExpiredPendingTransactions(maxAge time.Duration) ([]*visor.ReadableUnconfirmedTxn, error) {
+	endpoint := "/api/v1/pendingTxs/expired"
+	if maxAge > 0 {
+		v := url.Values{}
+		v.Add("max_age", maxAge.String())
+		endpoint += "?" + v.Encode()
 	}
-	return dc, nil
-}
 
-// PendingTransactions makes a request to GET /api/v1/pendingTxs
-func (c *Client) PendingTransactions() ([]*visor.ReadableUnconfirmedTxn, error) {
-	var v []*visor.ReadableUnconfirmedTxn
-	if err := c.Get("/api/v1/pendingTxs", &v); err != nil {
+	var txns []*visor.ReadableUnconfirmedTxn
+	if err := c.Get(endpoint, &txns); err != nil {
 		return nil, err
 	}
-	return v, nil
+	return txns, nil
 }
 
-// Transaction makes a request to GET /api/v1/transaction
-func (c *Client) Transaction(txid string) (*daemon.TransactionResult, error) {
+// PurgeExpiredPendingTransactions makes a request to POST /api/v1/pendingTxs/purge.
+// If maxAge is 0, the node's configured max age is used.
+func (c *Client) PurgeExpiredPendingTransactions(maxAge time.Duration) ([]string, error) {
 	v := url.Values{}
-	v.Add("txid", txid)
-	endpoint := "/api/v1/transaction?" + v.Encode()
+	if maxAge > 0 {
+		v.Add("max_age", maxAge.String())
+	}
 
-	var r daemon.TransactionResult
-	if err := c.Get(endpoint, &r); err != nil {
+	var txids []string
+	if err := c.PostForm("/api/v1/pendingTxs/purge", strings.NewReader(v.Encode()), &txids); err != nil {
 		return nil, err
 	}
-	return &r, nil
+	return txids, nil
 }
 
-// Transactions makes a request to GET /api/v1/transactions
-func (c *Client) Transactions(addrs []string) (*[]daemon.TransactionResult, error) {
+// ConfirmedTransactions makes a request to GET /api/v1/transactions?confirmed=true
+func (c *Client) ConfirmedTransactions(addrs []string) (*[]daemon.TransactionResult, error) {
 	v := url.Values{}
 	v.Add("addrs", strings.Join(addrs, ","))
+	v.Add("confirmed", "true")
 	endpoint := "/api/v1/transactions?" + v.Encode()
 
 	var r []daemon.TransactionResult
@@ -766,11 +532,11 @@ func (c *Client) Transactions(addrs []string) (*[]daemon.TransactionResult, erro
 	return &r, nil
 }
 
-// ConfirmedTransactions makes a request to GET /api/v1/transactions?confirmed=true
-func (c *Client) ConfirmedTransactions(addrs []string) (*[]daemon.TransactionResult, error) {
+// UnconfirmedTransactions makes a request to GET /api/v1/transactions?confirmed=false
+func (c *Client) UnconfirmedTransactions(addrs []string) (*[]daemon.TransactionResult, error) {
 	v := url.Values{}
 	v.Add("addrs", strings.Join(addrs, ","))
-	v.Add("confirmed", "true")
+	v.Add("confirmed", "false")
 	endpoint := "/api/v1/transactions?" + v.Encode()
 
 	var r []daemon.TransactionResult
@@ -780,11 +546,42 @@ func (c *Client) ConfirmedTransactions(addrs []string) (*[]daemon.TransactionRes
 	return &r, nil
 }
 
-// UnconfirmedTransactions makes a request to GET /api/v1/transactions?confirmed=false
-func (c *Client) UnconfirmedTransactions(addrs []string) (*[]daemon.TransactionResult, error) {
+// TransactionSearch are the parameters for SearchTransactions. Empty values are not sent.
+type TransactionSearch struct {
+	Addrs     []string
+	InAddrs   []string
+	OutAddrs  []string
+	StartTime uint64
+	EndTime   uint64
+	// MinCoins and MaxCoins are decimal coin amounts
+	MinCoins string
+	MaxCoins string
+}
+
+// SearchTransactions makes a request to GET /api/v1/transactions with search filters
+func (c *Client) SearchTransactions(q TransactionSearch) (*[]daemon.TransactionResult, error) {
 	v := url.Values{}
-	v.Add("addrs", strings.Join(addrs, ","))
-	v.Add("confirmed", "false")
+	if len(q.Addrs) != 0 {
+		v.Add("addrs", strings.Join(q.Addrs, ","))
+	}
+	if len(q.InAddrs) != 0 {
+		v.Add("in_addrs", strings.Join(q.InAddrs, ","))
+	}
+	if len(q.OutAddrs) != 0 {
+		v.Add("out_addrs", strings.Join(q.OutAddrs, ","))
+	}
+	if q.StartTime != 0 {
+		v.Add("start_time", fmt.Sprint(q.StartTime))
+	}
+	if q.EndTime != 0 {
+		v.Add("end_time", fmt.Sprint(q.EndTime))
+	}
+	if q.MinCoins != "" {
+		v.Add("min_coins", q.MinCoins)
+	}
+	if q.MaxCoins != "" {
+		v.Add("max_coins", q.MaxCoins)
+	}
 	endpoint := "/api/v1/transactions?" + v.Encode()
 
 	var r []daemon.TransactionResult
@@ -809,26 +606,13 @@ func (c *Client) InjectTransaction(rawTx string) (string, error) {
 	return txid, nil
 }
 
-// ResendUnconfirmedTransactions makes a request to GET /api/v1/resendUnconfirmedTxns
-func (c *Client) ResendUnconfirmedTransactions() (*daemon.ResendResult, error) {
-	var r daemon.ResendResult
-	if err := c.Get("/api/v1/resendUnconfirmedTxns", &r); err != nil {
+// InjectKeyRotation makes a request to POST /api/v1/blockchain/injectKeyRotation
+func (c *Client) InjectKeyRotation(r visor.ReadableKeyRotation) (*visor.ReadableKeyRotation, error) {
+	var rsp visor.ReadableKeyRotation
+	if err := c.PostJSON("/api/v1/blockchain/injectKeyRotation", r, &rsp); err != nil {
 		return nil, err
 	}
-	return &r, nil
-}
-
-// RawTransaction makes a request to GET /api/v1/rawtx
-func (c *Client) RawTransaction(txid string) (string, error) {
-	v := url.Values{}
-	v.Add("txid", txid)
-	endpoint := "/api/v1/rawtx?" + v.Encode()
-
-	var rawTx string
-	if err := c.Get(endpoint, &rawTx); err != nil {
-		return "", err
-	}
-	return rawTx, nil
+	return &rsp, nil
 }
 
 // VerifyTransaction makes a request to POST /api/v2/transaction/verify.
@@ -863,19 +647,6 @@ func (c *Client) VerifyAddress(addr string) (*VerifyAddressResponse, error) {
 	return nil, err
 }
 
-// AddressTransactions makes a request to GET /api/v1/explorer/address
-func (c *Client) AddressTransactions(addr string) ([]daemon.ReadableTransaction, error) {
-	v := url.Values{}
-	v.Add("address", addr)
-	endpoint := "/api/v1/explorer/address?" + v.Encode()
-
-	var b []daemon.ReadableTransaction
-	if err := c.Get(endpoint, &b); err != nil {
-		return nil, err
-	}
-	return b, nil
-}
-
 // RichlistParams are arguments to the /richlist endpoint
 type RichlistParams struct {
 	N                   int
@@ -900,57 +671,56 @@ func (c *Client) Richlist(params *RichlistParams) (*Richlist, error) {
 	return &r, nil
 }
 
-// AddressCount makes a request to GET /api/v1/addresscount
-func (c *Client) AddressCount() (uint64, error) {
-	var r struct {
-		Count uint64 `json:"count"`
+// SupplyHistory makes a request to GET /api/v1/explorer/supplyHistory.
+// If end is 0, all snapshots after start are returned.
+func (c *Client) SupplyHistory(start, end uint64) (*SupplyHistory, error) {
+	v := url.Values{}
+	if start != 0 {
+		v.Add("start", fmt.Sprint(start))
 	}
-	if err := c.Get("/api/v1/addresscount", &r); err != nil {
-		return 0, err
+	if end != 0 {
+		v.Add("end", fmt.Sprint(end))
 	}
-	return r.Count, nil
-
-}
 
-// UnloadWallet makes a request to POST /api/v1/wallet/unload
-func (c *Client) UnloadWallet(id string) error {
-	v := url.Values{}
-	v.Add("id", id)
-	return c.PostForm("/api/v1/wallet/unload", strings.NewReader(v.Encode()), nil)
-}
+	endpoint := "/api/v1/explorer/supplyHistory"
+	if len(v) > 0 {
+		endpoint += "?" + v.Encode()
+	}
 
-// Health makes a request to GET /api/v1/health
-func (c *Client) Health() (*HealthResponse, error) {
-	var r HealthResponse
-	if err := c.Get("/api/v1/health", &r); err != nil {
+	var r SupplyHistory
+	if err := c.Get(endpoint, &r); err != nil {
 		return nil, err
 	}
-
 	return &r, nil
 }
 
-// EncryptWallet makes a request to POST /api/v1/wallet/encrypt to encrypt a specific wallet with the given password
-func (c *Client) EncryptWallet(id string, password string) (*WalletResponse, error) {
+// Distribution makes a request to GET /api/v1/explorer/distribution
+func (c *Client) Distribution(tops []int, includeDistribution bool) (*Distribution, error) {
 	v := url.Values{}
-	v.Add("id", id)
-	v.Add("password", password)
-	var wlt WalletResponse
-	if err := c.PostForm("/api/v1/wallet/encrypt", strings.NewReader(v.Encode()), &wlt); err != nil {
-		return nil, err
+	if len(tops) > 0 {
+		n := make([]string, len(tops))
+		for i, t := range tops {
+			n[i] = fmt.Sprint(t)
+		}
+		v.Add("n", strings.Join(n, ","))
 	}
+	v.Add("include-distribution", fmt.Sprint(includeDistribution))
 
-	return &wlt, nil
+	var d Distribution
+	if err := c.Get("/api/v1/explorer/distribution?"+v.Encode(), &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
 }
 
-// DecryptWallet makes a request to POST /api/v1/wallet/decrypt to decrypt a wallet
-func (c *Client) DecryptWallet(id string, password string) (*WalletResponse, error) {
-	v := url.Values{}
-	v.Add("id", id)
-	v.Add("password", password)
-	var wlt WalletResponse
-	if err := c.PostForm("/api/v1/wallet/decrypt", strings.NewReader(v.Encode()), &wlt); err != nil {
-		return nil, err
+// AddressCount makes a request to GET /api/v1/addresscount
+func (c *Client) AddressCount() (uint64, error) {
+	var r struct {
+		Count uint64 `json:"count"`
 	}
+	if err := c.Get("/api/v1/addresscount", &r); err != nil {
+		return 0, err
+	}
+	return r.Count, nil
 
-	return &wlt, nil
 }
@@ -0,0 +1,602 @@
+// Code generated by TestGenerateClient from apiOperations. DO NOT EDIT.
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// Version makes a request to GET /api/v1/version
+func (c *Client) Version() (*visor.BuildInfo, error) {
+	var resp visor.BuildInfo
+	if err := c.Get("/api/v1/version", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Health makes a request to GET /api/v1/health
+func (c *Client) Health() (*HealthResponse, error) {
+	var resp HealthResponse
+	if err := c.Get("/api/v1/health", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// OpenAPISpec makes a request to GET /api/v1/openapi.json
+func (c *Client) OpenAPISpec() (*OpenAPISpec, error) {
+	var resp OpenAPISpec
+	if err := c.Get("/api/v1/openapi.json", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RateLimit makes a request to GET /api/v1/ratelimit
+func (c *Client) RateLimit() (*RateLimitResponse, error) {
+	var resp RateLimitResponse
+	if err := c.Get("/api/v1/ratelimit", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ReloadConfig makes a request to POST /api/v1/reloadConfig
+func (c *Client) ReloadConfig() (*ConfigReloadResult, error) {
+	var resp ConfigReloadResult
+	if err := c.PostForm("/api/v1/reloadConfig", strings.NewReader(""), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Outputs makes a request to GET /api/v1/outputs
+func (c *Client) Outputs() (*visor.ReadableOutputSet, error) {
+	var resp visor.ReadableOutputSet
+	if err := c.Get("/api/v1/outputs", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// OutputsForAddresses makes a request to GET /api/v1/outputs
+func (c *Client) OutputsForAddresses(addrs []string) (*visor.ReadableOutputSet, error) {
+	v := url.Values{}
+	v.Add("addrs", strings.Join(addrs, ","))
+	endpoint := "/api/v1/outputs?" + v.Encode()
+
+	var resp visor.ReadableOutputSet
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// OutputsForHashes makes a request to GET /api/v1/outputs
+func (c *Client) OutputsForHashes(hashes []string) (*visor.ReadableOutputSet, error) {
+	v := url.Values{}
+	v.Add("hashes", strings.Join(hashes, ","))
+	endpoint := "/api/v1/outputs?" + v.Encode()
+
+	var resp visor.ReadableOutputSet
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Balance makes a request to GET /api/v1/balance
+func (c *Client) Balance(addrs []string) (*wallet.BalancePair, error) {
+	v := url.Values{}
+	v.Add("addrs", strings.Join(addrs, ","))
+	endpoint := "/api/v1/balance?" + v.Encode()
+
+	var resp wallet.BalancePair
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Wallet makes a request to GET /api/v1/wallet
+func (c *Client) Wallet(id string) (*WalletResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	endpoint := "/api/v1/wallet?" + v.Encode()
+
+	var resp WalletResponse
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WalletBalance makes a request to GET /api/v1/wallet/balance
+func (c *Client) WalletBalance(id string) (*BalanceResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	endpoint := "/api/v1/wallet/balance?" + v.Encode()
+
+	var resp BalanceResponse
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Spend makes a request to POST /api/v1/wallet/spend
+func (c *Client) Spend(id string, dst string, coins uint64, password string) (*SpendResult, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("dst", dst)
+	v.Add("coins", fmt.Sprint(coins))
+	v.Add("password", password)
+
+	var resp SpendResult
+	if err := c.PostForm("/api/v1/wallet/spend", strings.NewReader(v.Encode()), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateTransaction makes a request to POST /api/v1/wallet/transaction
+func (c *Client) CreateTransaction(req CreateTransactionRequest) (*CreateTransactionResponse, error) {
+	var resp CreateTransactionResponse
+	if err := c.PostJSON("/api/v1/wallet/transaction", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WalletTransactions makes a request to GET /api/v1/wallet/transactions
+func (c *Client) WalletTransactions(id string) (*UnconfirmedTxnsResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	endpoint := "/api/v1/wallet/transactions?" + v.Encode()
+
+	var resp UnconfirmedTxnsResponse
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WalletLedger makes a request to GET /api/v1/wallet/ledger to get the ledger of a wallet as JSON
+func (c *Client) WalletLedger(id string, startTime uint64, endTime uint64) (*LedgerResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("start_time", fmt.Sprint(startTime))
+	v.Add("end_time", fmt.Sprint(endTime))
+	endpoint := "/api/v1/wallet/ledger?" + v.Encode()
+
+	var resp LedgerResponse
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateWallet makes a request to POST /api/v1/wallet/update
+func (c *Client) UpdateWallet(id string, label string) error {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("label", label)
+
+	return c.PostForm("/api/v1/wallet/update", strings.NewReader(v.Encode()), nil)
+}
+
+// Wallets makes a request to GET /api/v1/wallets
+func (c *Client) Wallets() ([]*WalletResponse, error) {
+	var resp []*WalletResponse
+	if err := c.Get("/api/v1/wallets", &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WalletFolderName makes a request to GET /api/v1/wallets/folderName
+func (c *Client) WalletFolderName() (*WalletFolder, error) {
+	var resp WalletFolder
+	if err := c.Get("/api/v1/wallets/folderName", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ExportWallet makes a request to POST /api/v1/wallet/export to export a wallet to a bundle encrypted with password
+func (c *Client) ExportWallet(id string, password string, watchOnly bool, walletPassword string) (*wallet.EncryptedBundle, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("password", password)
+	v.Add("watch_only", fmt.Sprint(watchOnly))
+	v.Add("wallet_password", walletPassword)
+
+	var resp wallet.EncryptedBundle
+	if err := c.PostForm("/api/v1/wallet/export", strings.NewReader(v.Encode()), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UnloadWallet makes a request to POST /api/v1/wallet/unload
+func (c *Client) UnloadWallet(id string) error {
+	v := url.Values{}
+	v.Add("id", id)
+
+	return c.PostForm("/api/v1/wallet/unload", strings.NewReader(v.Encode()), nil)
+}
+
+// EncryptWallet makes a request to POST /api/v1/wallet/encrypt to encrypt a specific wallet with the given password
+func (c *Client) EncryptWallet(id string, password string) (*WalletResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("password", password)
+
+	var resp WalletResponse
+	if err := c.PostForm("/api/v1/wallet/encrypt", strings.NewReader(v.Encode()), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DecryptWallet makes a request to POST /api/v1/wallet/decrypt to decrypt a wallet
+func (c *Client) DecryptWallet(id string, password string) (*WalletResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("password", password)
+
+	var resp WalletResponse
+	if err := c.PostForm("/api/v1/wallet/decrypt", strings.NewReader(v.Encode()), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WalletMetadata makes a request to POST /api/v1/wallet/metadata to get the notes, address labels and contacts of a wallet
+func (c *Client) WalletMetadata(id string, password string) (*wallet.Metadata, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("password", password)
+
+	var resp wallet.Metadata
+	if err := c.PostForm("/api/v1/wallet/metadata", strings.NewReader(v.Encode()), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetWalletNote makes a request to POST /api/v1/wallet/setNote to set the note of a transaction in a wallet
+func (c *Client) SetWalletNote(id string, txid string, note string, password string) (*wallet.Metadata, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("txid", txid)
+	v.Add("note", note)
+	v.Add("password", password)
+
+	var resp wallet.Metadata
+	if err := c.PostForm("/api/v1/wallet/setNote", strings.NewReader(v.Encode()), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetWalletAddressLabel makes a request to POST /api/v1/wallet/setLabel to set the label of an address in a wallet
+func (c *Client) SetWalletAddressLabel(id string, address string, label string, password string) (*wallet.Metadata, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("address", address)
+	v.Add("label", label)
+	v.Add("password", password)
+
+	var resp wallet.Metadata
+	if err := c.PostForm("/api/v1/wallet/setLabel", strings.NewReader(v.Encode()), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetWalletContact makes a request to POST /api/v1/wallet/setContact to add or change a contact in the address book of a wallet
+func (c *Client) SetWalletContact(id string, name string, address string, password string) (*wallet.Metadata, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("name", name)
+	v.Add("address", address)
+	v.Add("password", password)
+
+	var resp wallet.Metadata
+	if err := c.PostForm("/api/v1/wallet/setContact", strings.NewReader(v.Encode()), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteWalletContact makes a request to POST /api/v1/wallet/deleteContact to remove a contact from the address book of a wallet
+func (c *Client) DeleteWalletContact(id string, name string, password string) (*wallet.Metadata, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("name", name)
+	v.Add("password", password)
+
+	var resp wallet.Metadata
+	if err := c.PostForm("/api/v1/wallet/deleteContact", strings.NewReader(v.Encode()), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockchainMetadata makes a request to GET /api/v1/blockchain/metadata
+func (c *Client) BlockchainMetadata() (*visor.BlockchainMetadata, error) {
+	var resp visor.BlockchainMetadata
+	if err := c.Get("/api/v1/blockchain/metadata", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockchainProgress makes a request to GET /api/v1/blockchain/progress
+func (c *Client) BlockchainProgress() (*daemon.BlockchainProgress, error) {
+	var resp daemon.BlockchainProgress
+	if err := c.Get("/api/v1/blockchain/progress", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// KeyRotations makes a request to GET /api/v1/blockchain/keyRotations
+func (c *Client) KeyRotations() ([]visor.ReadableKeyRotation, error) {
+	var resp []visor.ReadableKeyRotation
+	if err := c.Get("/api/v1/blockchain/keyRotations", &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// BlockByHash makes a request to GET /api/v1/block
+func (c *Client) BlockByHash(hash string) (*visor.ReadableBlock, error) {
+	v := url.Values{}
+	v.Add("hash", hash)
+	endpoint := "/api/v1/block?" + v.Encode()
+
+	var resp visor.ReadableBlock
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockBySeq makes a request to GET /api/v1/block
+func (c *Client) BlockBySeq(seq uint64) (*visor.ReadableBlock, error) {
+	v := url.Values{}
+	v.Add("seq", fmt.Sprint(seq))
+	endpoint := "/api/v1/block?" + v.Encode()
+
+	var resp visor.ReadableBlock
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Blocks makes a request to GET /api/v1/blocks
+func (c *Client) Blocks(start int, end int) (*visor.ReadableBlocks, error) {
+	v := url.Values{}
+	v.Add("start", fmt.Sprint(start))
+	v.Add("end", fmt.Sprint(end))
+	endpoint := "/api/v1/blocks?" + v.Encode()
+
+	var resp visor.ReadableBlocks
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// LastBlocks makes a request to GET /api/v1/last_blocks
+func (c *Client) LastBlocks(n int) (*visor.ReadableBlocks, error) {
+	v := url.Values{}
+	v.Add("num", fmt.Sprint(n))
+	endpoint := "/api/v1/last_blocks?" + v.Encode()
+
+	var resp visor.ReadableBlocks
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// NetworkConnection makes a request to GET /api/v1/network/connection
+func (c *Client) NetworkConnection(addr string) (*daemon.Connection, error) {
+	v := url.Values{}
+	v.Add("addr", addr)
+	endpoint := "/api/v1/network/connection?" + v.Encode()
+
+	var resp daemon.Connection
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// NetworkConnections makes a request to GET /api/v1/network/connections
+func (c *Client) NetworkConnections() (*Connections, error) {
+	var resp Connections
+	if err := c.Get("/api/v1/network/connections", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// NetworkDefaultConnections makes a request to GET /api/v1/network/defaultConnections
+func (c *Client) NetworkDefaultConnections() ([]string, error) {
+	var resp []string
+	if err := c.Get("/api/v1/network/defaultConnections", &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// NetworkTrustedConnections makes a request to GET /api/v1/network/connections/trust
+func (c *Client) NetworkTrustedConnections() ([]string, error) {
+	var resp []string
+	if err := c.Get("/api/v1/network/connections/trust", &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// NetworkExchangeableConnections makes a request to GET /api/v1/network/connections/exchange
+func (c *Client) NetworkExchangeableConnections() ([]string, error) {
+	var resp []string
+	if err := c.Get("/api/v1/network/connections/exchange", &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PendingTransactions makes a request to GET /api/v1/pendingTxs
+func (c *Client) PendingTransactions() ([]*visor.ReadableUnconfirmedTxn, error) {
+	var resp []*visor.ReadableUnconfirmedTxn
+	if err := c.Get("/api/v1/pendingTxs", &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PendingTransactionsStats makes a request to GET /api/v1/pendingTxs/stats
+func (c *Client) PendingTransactionsStats() (*PendingTxnsStats, error) {
+	var resp PendingTxnsStats
+	if err := c.Get("/api/v1/pendingTxs/stats", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Transaction makes a request to GET /api/v1/transaction
+func (c *Client) Transaction(txid string) (*daemon.TransactionResult, error) {
+	v := url.Values{}
+	v.Add("txid", txid)
+	endpoint := "/api/v1/transaction?" + v.Encode()
+
+	var resp daemon.TransactionResult
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Transactions makes a request to GET /api/v1/transactions
+func (c *Client) Transactions(addrs []string) (*[]daemon.TransactionResult, error) {
+	v := url.Values{}
+	v.Add("addrs", strings.Join(addrs, ","))
+	endpoint := "/api/v1/transactions?" + v.Encode()
+
+	var resp []daemon.TransactionResult
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Ledger makes a request to GET /api/v1/ledger to get the ledger of addresses as JSON
+func (c *Client) Ledger(addrs []string, startTime uint64, endTime uint64) (*LedgerResponse, error) {
+	v := url.Values{}
+	v.Add("addrs", strings.Join(addrs, ","))
+	v.Add("start_time", fmt.Sprint(startTime))
+	v.Add("end_time", fmt.Sprint(endTime))
+	endpoint := "/api/v1/ledger?" + v.Encode()
+
+	var resp LedgerResponse
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ResendUnconfirmedTransactions makes a request to GET /api/v1/resendUnconfirmedTxns
+func (c *Client) ResendUnconfirmedTransactions() (*daemon.ResendResult, error) {
+	var resp daemon.ResendResult
+	if err := c.Get("/api/v1/resendUnconfirmedTxns", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RawTransaction makes a request to GET /api/v1/rawtx
+func (c *Client) RawTransaction(txid string) (string, error) {
+	v := url.Values{}
+	v.Add("txid", txid)
+	endpoint := "/api/v1/rawtx?" + v.Encode()
+
+	var resp string
+	if err := c.Get(endpoint, &resp); err != nil {
+		return "", err
+	}
+	return resp, nil
+}
+
+// EstimateTransaction makes a request to POST /api/v2/transaction/estimate.
+// Wallet.ID can be omitted if Wallet.Addresses is set, and Wallet.Password is not required.
+func (c *Client) EstimateTransaction(req CreateTransactionRequest) (*EstimateTransactionResponse, error) {
+	var resp EstimateTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/transaction/estimate", req, &resp)
+	if ok {
+		return &resp, err
+	}
+	return nil, err
+}
+
+// UxOut makes a request to GET /api/v1/uxout
+func (c *Client) UxOut(uxID string) (*historydb.UxOutJSON, error) {
+	v := url.Values{}
+	v.Add("uxid", uxID)
+	endpoint := "/api/v1/uxout?" + v.Encode()
+
+	var resp historydb.UxOutJSON
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressUxOuts makes a request to GET /api/v1/address_uxouts
+func (c *Client) AddressUxOuts(addr string) ([]*historydb.UxOutJSON, error) {
+	v := url.Values{}
+	v.Add("address", addr)
+	endpoint := "/api/v1/address_uxouts?" + v.Encode()
+
+	var resp []*historydb.UxOutJSON
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AddressTransactions makes a request to GET /api/v1/explorer/address
+func (c *Client) AddressTransactions(addr string) ([]daemon.ReadableTransaction, error) {
+	v := url.Values{}
+	v.Add("address", addr)
+	endpoint := "/api/v1/explorer/address?" + v.Encode()
+
+	var resp []daemon.ReadableTransaction
+	if err := c.Get(endpoint, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CoinSupply makes a request to GET /api/v1/coinSupply
+func (c *Client) CoinSupply() (*CoinSupply, error) {
+	var resp CoinSupply
+	if err := c.Get("/api/v1/coinSupply", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
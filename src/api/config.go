@@ -0,0 +1,115 @@
+package api
+
+// APIs for the node's config
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	wh "github.com/skycoin/skycoin/src/util/http" //http,json helpers
+)
+
+// ConfigReloader reloads the config of the node
+type ConfigReloader interface {
+	// ReloadConfig reloads the config and applies the options that can be changed while the node runs
+	ReloadConfig() (*ConfigReloadResult, error)
+}
+
+// ConfigReloadResult is the result of a config reload
+type ConfigReloadResult struct {
+	// Options that changed and were applied
+	Applied []string `json:"applied"`
+	// Options that changed and are applied when the node is restarted
+	RestartRequired []string `json:"restart_required"`
+}
+
+// RuntimeConfig are the options of a Server that can be changed while it runs
+type RuntimeConfig struct {
+	DisableCSRF          bool
+	EnableUnversionedAPI bool
+	// The rate limits can be changed but not enabled or disabled, because the rate limiters
+	// of the disabled limits are not created. A rate of 0 leaves the limit unchanged.
+	RateLimit RateLimitConfig
+}
+
+// SetRuntimeConfig applies the runtime options to the running Server
+func (s *Server) SetRuntimeConfig(c RuntimeConfig) {
+	if s.csrfStore.isEnabled() == c.DisableCSRF {
+		if c.DisableCSRF {
+			logger.Warning("CSRF check disabled")
+		} else {
+			logger.Info("CSRF check enabled")
+		}
+		s.csrfStore.setEnabled(!c.DisableCSRF)
+	}
+
+	s.unversionedAPI.set(c.EnableUnversionedAPI)
+
+	if s.rateLimiter != nil && c.RateLimit.Rate > 0 {
+		s.rateLimiter.SetLimit(c.RateLimit.Rate, c.RateLimit.Burst)
+	}
+	if s.expensiveRateLimiter != nil && c.RateLimit.ExpensiveRate > 0 {
+		s.expensiveRateLimiter.SetLimit(c.RateLimit.ExpensiveRate, c.RateLimit.ExpensiveBurst)
+	}
+}
+
+// toggle is an option of a Server that can be switched on and off while it runs
+type toggle struct {
+	on int32
+}
+
+func newToggle(on bool) *toggle {
+	t := &toggle{}
+	t.set(on)
+	return t
+}
+
+func (t *toggle) set(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&t.on, v)
+}
+
+func (t *toggle) isOn() bool {
+	return atomic.LoadInt32(&t.on) == 1
+}
+
+// toggleCheck responds with 404 Not Found if t is off
+func toggleCheck(t *toggle, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !t.isOn() {
+			wh.Error404(w, "")
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Reloads the node's config file, environment variables and command line options,
+// and applies the options that can be changed while the node runs
+// URI: /api/v1/reloadConfig
+// Method: POST
+func reloadConfigHandler(reloader ConfigReloader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		if reloader == nil {
+			wh.Error403(w, "Config reload is not available")
+			return
+		}
+
+		result, err := reloader.ReloadConfig()
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, result)
+	}
+}
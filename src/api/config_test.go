@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+type fakeConfigReloader struct {
+	result *ConfigReloadResult
+	err    error
+}
+
+func (r *fakeConfigReloader) ReloadConfig() (*ConfigReloadResult, error) {
+	return r.result, r.err
+}
+
+func TestReloadConfigHandler(t *testing.T) {
+	result := &ConfigReloadResult{
+		Applied:         []string{"log-level"},
+		RestartRequired: []string{"port"},
+	}
+
+	cases := []struct {
+		name     string
+		method   string
+		reloader ConfigReloader
+		status   int
+		err      string
+	}{
+		{
+			name:     "405",
+			method:   http.MethodGet,
+			reloader: &fakeConfigReloader{result: result},
+			status:   http.StatusMethodNotAllowed,
+			err:      "405 Method Not Allowed",
+		},
+		{
+			name:   "403 no reloader",
+			method: http.MethodPost,
+			status: http.StatusForbidden,
+			err:    "403 Forbidden - Config reload is not available",
+		},
+		{
+			name:     "500 invalid config",
+			method:   http.MethodPost,
+			reloader: &fakeConfigReloader{err: errors.New("invalid log-level: loud")},
+			status:   http.StatusInternalServerError,
+			err:      "500 Internal Server Error - invalid log-level: loud",
+		},
+		{
+			name:     "200",
+			method:   http.MethodPost,
+			reloader: &fakeConfigReloader{result: result},
+			status:   http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, "/api/v1/reloadConfig", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(muxConfig{
+				host:           configuredHost,
+				appLoc:         ".",
+				configReloader: tc.reloader,
+			}, &GatewayerMock{}, &CSRFStore{}, nil)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			if tc.status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var resp ConfigReloadResult
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+			require.Equal(t, *result, resp)
+		})
+	}
+}
+
+func TestServerSetRuntimeConfig(t *testing.T) {
+	gateway := &GatewayerMock{}
+	gateway.On("GetBuildInfo").Return(visor.BuildInfo{Version: "0.24.0"})
+
+	s := &Server{
+		csrfStore:      &CSRFStore{Enabled: true},
+		unversionedAPI: newToggle(false),
+		rateLimiter:    NewRateLimiter(1, 1),
+	}
+	handler := newServerMux(muxConfig{
+		host:           configuredHost,
+		appLoc:         ".",
+		unversionedAPI: s.unversionedAPI,
+		rateLimiter:    s.rateLimiter,
+	}, gateway, s.csrfStore, nil)
+
+	get := func(endpoint string) int {
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	require.Equal(t, http.StatusNotFound, get("/version"))
+	require.Equal(t, http.StatusOK, get("/api/v1/csrf"))
+
+	s.SetRuntimeConfig(RuntimeConfig{
+		DisableCSRF:          true,
+		EnableUnversionedAPI: true,
+		RateLimit: RateLimitConfig{
+			Rate:  5,
+			Burst: 3,
+		},
+	})
+
+	require.Equal(t, http.StatusOK, get("/version"))
+	require.Equal(t, http.StatusNotFound, get("/api/v1/csrf"))
+	require.False(t, s.csrfStore.isEnabled())
+	require.Equal(t, RateLimitStats{
+		Rate:    5,
+		Burst:   3,
+		Clients: 1,
+	}, s.rateLimiter.Stats())
+
+	// A rate of 0 doesn't disable the limit
+	s.SetRuntimeConfig(RuntimeConfig{})
+	require.Equal(t, http.StatusNotFound, get("/version"))
+	require.Equal(t, http.StatusOK, get("/api/v1/csrf"))
+	require.Equal(t, 5.0, s.rateLimiter.Stats().Rate)
+}
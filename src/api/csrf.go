@@ -49,6 +49,20 @@ type CSRFStore struct {
 	sync.RWMutex
 }
 
+// isEnabled returns whether the CSRF check is enabled
+func (c *CSRFStore) isEnabled() bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.Enabled
+}
+
+// setEnabled enables or disables the CSRF check
+func (c *CSRFStore) setEnabled(enabled bool) {
+	c.Lock()
+	defer c.Unlock()
+	c.Enabled = enabled
+}
+
 // getTokenValue returns a url safe base64 encoded token
 func (c *CSRFStore) getTokenValue() string {
 	c.RLock()
@@ -110,7 +124,7 @@ func getCSRFToken(store *CSRFStore) http.HandlerFunc {
 			return
 		}
 
-		if !store.Enabled {
+		if !store.isEnabled() {
 			logger.Warning("CSRF check disabled")
 			wh.Error404(w, "")
 			return
@@ -126,7 +140,7 @@ func getCSRFToken(store *CSRFStore) http.HandlerFunc {
 // CSRFCheck verifies X-CSRF-Token header value
 func CSRFCheck(store *CSRFStore, handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if store.Enabled {
+		if store.isEnabled() {
 			switch r.Method {
 			case http.MethodPost, http.MethodPut, http.MethodDelete:
 				token := r.Header.Get(CSRFHeaderName)
@@ -61,6 +61,7 @@ var endpoints = []string{
 	"/health",
 	"/injectTransaction",
 	"/last_blocks",
+	"/ledger",
 	"/version",
 	"/network/connection",
 	"/network/connections",
@@ -77,10 +78,19 @@ var endpoints = []string{
 	"/uxout",
 	"/wallet",
 	"/wallet/balance",
+	"/wallet/changePassword",
 	"/wallet/create",
+	"/wallet/deleteContact",
+	"/wallet/export",
+	"/wallet/import",
+	"/wallet/ledger",
+	"/wallet/metadata",
 	"/wallet/newAddress",
 	"/wallet/newSeed",
 	"/wallet/seed",
+	"/wallet/setContact",
+	"/wallet/setLabel",
+	"/wallet/setNote",
 	"/wallet/spend",
 	"/wallet/transaction",
 	"/wallet/transactions",
@@ -102,6 +112,7 @@ var endpoints = []string{
 	"/api/v1/health",
 	"/api/v1/injectTransaction",
 	"/api/v1/last_blocks",
+	"/api/v1/ledger",
 	"/api/v1/version",
 	"/api/v1/network/connection",
 	"/api/v1/network/connections",
@@ -118,10 +129,19 @@ var endpoints = []string{
 	"/api/v1/uxout",
 	"/api/v1/wallet",
 	"/api/v1/wallet/balance",
+	"/api/v1/wallet/changePassword",
 	"/api/v1/wallet/create",
+	"/api/v1/wallet/deleteContact",
+	"/api/v1/wallet/export",
+	"/api/v1/wallet/import",
+	"/api/v1/wallet/ledger",
+	"/api/v1/wallet/metadata",
 	"/api/v1/wallet/newAddress",
 	"/api/v1/wallet/newSeed",
 	"/api/v1/wallet/seed",
+	"/api/v1/wallet/setContact",
+	"/api/v1/wallet/setLabel",
+	"/api/v1/wallet/setNote",
 	"/api/v1/wallet/spend",
 	"/api/v1/wallet/transaction",
 	"/api/v1/wallet/transactions",
@@ -133,6 +153,9 @@ var endpoints = []string{
 
 	"/api/v2/transaction/verify",
 	"/api/v2/address/verify",
+	"/api/v2/balance",
+	"/api/v2/wallet/spend",
+	"/api/v2/injectTransaction",
 }
 
 func TestCSRFWrapper(t *testing.T) {
@@ -156,17 +179,17 @@ func TestCSRFWrapper(t *testing.T) {
 
 					rr := httptest.NewRecorder()
 					handler := newServerMux(muxConfig{
-						host:                 configuredHost,
-						appLoc:               ".",
-						enableJSON20RPC:      true,
-						enableUnversionedAPI: true,
+						host:            configuredHost,
+						appLoc:          ".",
+						enableJSON20RPC: true,
+						unversionedAPI:  newToggle(true),
 					}, gateway, csrfStore, nil)
 
 					handler.ServeHTTP(rr, req)
 
 					status := rr.Code
 					require.Equal(t, http.StatusForbidden, status, "wrong status code: got `%v` want `%v`", status, http.StatusForbidden)
-					require.Equal(t, "403 Forbidden - invalid CSRF token\n", rr.Body.String())
+					require.Equal(t, errorResponseBody(t, endpoint, http.StatusForbidden, "invalid CSRF token"), rr.Body.String())
 				})
 			}
 		}
@@ -221,7 +244,7 @@ func TestOriginRefererCheck(t *testing.T) {
 
 				status := rr.Code
 				require.Equal(t, http.StatusForbidden, status, "wrong status code: got `%v` want `%v`", status, http.StatusForbidden)
-				require.Equal(t, "403 Forbidden\n", rr.Body.String())
+				require.Equal(t, errorResponseBody(t, endpoint, http.StatusForbidden, ""), rr.Body.String())
 			})
 		}
 	}
@@ -253,7 +276,7 @@ func TestHostCheck(t *testing.T) {
 
 			status := rr.Code
 			require.Equal(t, http.StatusForbidden, status, "wrong status code: got `%v` want `%v`", status, http.StatusForbidden)
-			require.Equal(t, "403 Forbidden\n", rr.Body.String())
+			require.Equal(t, errorResponseBody(t, endpoint, http.StatusForbidden, ""), rr.Body.String())
 		})
 	}
 }
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
-	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/util/droplet"
 	wh "github.com/skycoin/skycoin/src/util/http" //http,json helpers
 	"github.com/skycoin/skycoin/src/visor"
@@ -32,6 +32,7 @@ type CoinSupply struct {
 
 func getCoinSupply(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		supply := coinSupply(gateway, w, r)
 		if supply != nil {
 			wh.SendJSONOr500(logger, w, supply)
@@ -45,109 +46,156 @@ func coinSupply(gateway Gatewayer, w http.ResponseWriter, r *http.Request) *Coin
 		return nil
 	}
 
-	allUnspents, err := gateway.GetUnspentOutputs()
+	supply, err := gateway.GetCoinSupply()
 	if err != nil {
-		err = fmt.Errorf("gateway.GetUnspentOutputs failed: %v", err)
+		err = fmt.Errorf("gateway.GetCoinSupply failed: %v", err)
 		wh.Error500(w, err.Error())
 		return nil
 	}
 
-	unlockedAddrs := visor.GetUnlockedDistributionAddresses()
-	// Search map of unlocked addresses
-	// used to filter unspents
-	unlockedAddrMap := daemon.MakeSearchMap(unlockedAddrs)
-
-	var unlockedSupply uint64
-	// check confirmed unspents only
-	for _, u := range allUnspents.HeadOutputs {
-		// check if address is an unlocked distribution address
-		if _, ok := unlockedAddrMap[u.Address]; ok {
-			coins, err := droplet.FromString(u.Coins)
-			if err != nil {
-				err = fmt.Errorf("Invalid unlocked output balance string %s: %v", u.Coins, err)
-				wh.Error500(w, err.Error())
-				return nil
-			}
-			unlockedSupply += coins
-		}
-	}
-
-	// "total supply" is the number of coins unlocked.
-	// Each distribution address was allocated visor.DistributionAddressInitialBalance coins.
-	totalSupply := uint64(len(unlockedAddrs)) * visor.DistributionAddressInitialBalance
-	totalSupply *= droplet.Multiplier
-
-	// "current supply" is the number of coins distributed from the unlocked pool
-	currentSupply := totalSupply - unlockedSupply
-
-	currentSupplyStr, err := droplet.ToString(currentSupply)
+	currentSupplyStr, err := droplet.ToString(supply.CurrentSupply)
 	if err != nil {
 		err = fmt.Errorf("Failed to convert coins to string: %v", err)
 		wh.Error500(w, err.Error())
 		return nil
 	}
 
-	totalSupplyStr, err := droplet.ToString(totalSupply)
+	totalSupplyStr, err := droplet.ToString(supply.TotalSupply)
 	if err != nil {
 		err = fmt.Errorf("Failed to convert coins to string: %v", err)
 		wh.Error500(w, err.Error())
 		return nil
 	}
 
-	maxSupplyStr, err := droplet.ToString(visor.MaxCoinSupply * droplet.Multiplier)
+	maxSupplyStr, err := droplet.ToString(supply.MaxSupply)
 	if err != nil {
 		err = fmt.Errorf("Failed to convert coins to string: %v", err)
 		wh.Error500(w, err.Error())
 		return nil
 	}
 
-	// locked distribution addresses
-	lockedAddrs := visor.GetLockedDistributionAddresses()
-	lockedAddrMap := daemon.MakeSearchMap(lockedAddrs)
-
-	// get total coins hours which excludes locked distribution addresses
-	var totalCoinHours uint64
-	for _, out := range allUnspents.HeadOutputs {
-		if _, ok := lockedAddrMap[out.Address]; !ok {
-			totalCoinHours += out.CalculatedHours
-		}
+	cs := CoinSupply{
+		CurrentSupply:         currentSupplyStr,
+		TotalSupply:           totalSupplyStr,
+		MaxSupply:             maxSupplyStr,
+		CurrentCoinHourSupply: strconv.FormatUint(supply.CurrentCoinHourSupply, 10),
+		TotalCoinHourSupply:   strconv.FormatUint(supply.TotalCoinHourSupply, 10),
+		UnlockedAddresses:     supply.UnlockedAddresses,
+		LockedAddresses:       supply.LockedAddresses,
 	}
 
-	// get current coin hours which excludes all distribution addresses
-	var currentCoinHours uint64
-	for _, out := range allUnspents.HeadOutputs {
-		// check if address not in locked distribution addresses
-		if _, ok := lockedAddrMap[out.Address]; !ok {
-			// check if address not in unlocked distribution addresses
-			if _, ok := unlockedAddrMap[out.Address]; !ok {
-				currentCoinHours += out.CalculatedHours
-			}
-		}
-	}
+	return &cs
+}
+
+// SupplySnapshot is a daily record of the coin supply, returned by /explorer/supplyHistory
+type SupplySnapshot struct {
+	// Date of the snapshot, YYYY-MM-DD (UTC)
+	Date string `json:"date"`
+	// Unix time of the start of the day
+	Time uint64 `json:"time"`
+	// Block the snapshot was taken at, the first block of the day
+	BlockSeq              uint64 `json:"block_seq"`
+	BlockTime             uint64 `json:"block_time"`
+	CurrentSupply         string `json:"current_supply"`
+	TotalSupply           string `json:"total_supply"`
+	CurrentCoinHourSupply string `json:"current_coinhour_supply"`
+	TotalCoinHourSupply   string `json:"total_coinhour_supply"`
+	// Number of addresses with unspent outputs
+	AddressCount uint64 `json:"address_count"`
+}
 
+// NewSupplySnapshot creates a SupplySnapshot from a visor.SupplySnapshot
+func NewSupplySnapshot(ss visor.SupplySnapshot) (*SupplySnapshot, error) {
+	currentSupply, err := droplet.ToString(ss.CurrentSupply)
 	if err != nil {
-		err = fmt.Errorf("Failed to get total coinhours: %v", err)
-		wh.Error500(w, err.Error())
-		return nil
+		return nil, err
 	}
 
-	cs := CoinSupply{
-		CurrentSupply:         currentSupplyStr,
-		TotalSupply:           totalSupplyStr,
-		MaxSupply:             maxSupplyStr,
-		CurrentCoinHourSupply: strconv.FormatUint(currentCoinHours, 10),
-		TotalCoinHourSupply:   strconv.FormatUint(totalCoinHours, 10),
-		UnlockedAddresses:     unlockedAddrs,
-		LockedAddresses:       visor.GetLockedDistributionAddresses(),
+	totalSupply, err := droplet.ToString(ss.TotalSupply)
+	if err != nil {
+		return nil, err
 	}
 
-	return &cs
+	return &SupplySnapshot{
+		Date:                  time.Unix(int64(ss.Time), 0).UTC().Format("2006-01-02"),
+		Time:                  ss.Time,
+		BlockSeq:              ss.BkSeq,
+		BlockTime:             ss.BlockTime,
+		CurrentSupply:         currentSupply,
+		TotalSupply:           totalSupply,
+		CurrentCoinHourSupply: strconv.FormatUint(ss.CurrentCoinHourSupply, 10),
+		TotalCoinHourSupply:   strconv.FormatUint(ss.TotalCoinHourSupply, 10),
+		AddressCount:          ss.AddressCount,
+	}, nil
+}
+
+// SupplyHistory is the API response for /explorer/supplyHistory
+type SupplyHistory struct {
+	Snapshots []SupplySnapshot `json:"snapshots"`
+}
+
+// method: GET
+// url: /explorer/supplyHistory?start=${unixtime}&end=${unixtime}
+func getSupplyHistory(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		var start, end uint64
+		if startStr := r.FormValue("start"); startStr != "" {
+			var err error
+			start, err = strconv.ParseUint(startStr, 10, 64)
+			if err != nil {
+				wh.Error400(w, "invalid start")
+				return
+			}
+		}
+
+		if endStr := r.FormValue("end"); endStr != "" {
+			var err error
+			end, err = strconv.ParseUint(endStr, 10, 64)
+			if err != nil {
+				wh.Error400(w, "invalid end")
+				return
+			}
+
+			if end < start {
+				wh.Error400(w, "end must be >= start")
+				return
+			}
+		}
+
+		snapshots, err := gateway.GetSupplySnapshots(start, end)
+		if err != nil {
+			err = fmt.Errorf("gateway.GetSupplySnapshots failed: %v", err)
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		history := SupplyHistory{
+			Snapshots: make([]SupplySnapshot, len(snapshots)),
+		}
+		for i, ss := range snapshots {
+			s, err := NewSupplySnapshot(ss)
+			if err != nil {
+				wh.Error500(w, err.Error())
+				return
+			}
+			history.Snapshots[i] = *s
+		}
+
+		wh.SendJSONOr500(logger, w, history)
+	}
 }
 
 // method: GET
 // url: /explorer/address?address=${address}
 func getTransactionsForAddress(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -185,6 +233,7 @@ type Richlist struct {
 // url: /richlist?n=${number}&include-distribution=${bool}
 func getRichlist(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -216,7 +265,11 @@ func getRichlist(gateway Gatewayer) http.HandlerFunc {
 			}
 		}
 
-		richlist, err := gateway.GetRichlist(includeDistribution)
+		if topn < 0 {
+			topn = 0
+		}
+
+		richlist, err := gateway.GetRichlist(includeDistribution, topn)
 		if err != nil {
 			wh.Error500(w, err.Error())
 			return
@@ -232,10 +285,125 @@ func getRichlist(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// DistributionTier is the share of coins held by the top N addresses
+type DistributionTier struct {
+	Top     int    `json:"top"`
+	Coins   string `json:"coins"`
+	Percent string `json:"percent"`
+}
+
+// Distribution is the API response for /explorer/distribution
+type Distribution struct {
+	// Total coins held by the addresses considered
+	TotalCoins   string             `json:"total_coins"`
+	AddressCount int                `json:"address_count"`
+	Distribution []DistributionTier `json:"distribution"`
+}
+
+// method: GET
+// url: /explorer/distribution?n=${comma separated list of top N}&include-distribution=${bool}
+func getDistribution(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		tops := []int{10, 100, 1000}
+		if topsStr := r.FormValue("n"); topsStr != "" {
+			tops = nil
+			for _, s := range splitCommaString(topsStr) {
+				n, err := strconv.Atoi(s)
+				if err != nil || n <= 0 {
+					wh.Error400(w, "invalid n")
+					return
+				}
+				tops = append(tops, n)
+			}
+		}
+
+		var includeDistribution bool
+		if includeDistributionStr := r.FormValue("include-distribution"); includeDistributionStr != "" {
+			var err error
+			includeDistribution, err = strconv.ParseBool(includeDistributionStr)
+			if err != nil {
+				wh.Error400(w, "invalid include-distribution")
+				return
+			}
+		}
+
+		// Only the largest top N of the richlist is needed
+		maxTop := 0
+		for _, n := range tops {
+			if n > maxTop {
+				maxTop = n
+			}
+		}
+
+		summary, err := gateway.GetRichlistSummary(includeDistribution, maxTop)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+		richlist := summary.Richlist
+
+		// cumulative[i] is the number of coins held by the top i addresses
+		cumulative := make([]uint64, len(richlist)+1)
+		for i, b := range richlist {
+			coins, err := droplet.FromString(b.Coins)
+			if err != nil {
+				wh.Error500(w, err.Error())
+				return
+			}
+			cumulative[i+1] = cumulative[i] + coins
+		}
+
+		total := summary.TotalCoins
+		totalStr, err := droplet.ToString(total)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		d := Distribution{
+			TotalCoins:   totalStr,
+			AddressCount: summary.AddressCount,
+			Distribution: make([]DistributionTier, len(tops)),
+		}
+
+		for i, n := range tops {
+			if n > len(richlist) {
+				n = len(richlist)
+			}
+
+			coins, err := droplet.ToString(cumulative[n])
+			if err != nil {
+				wh.Error500(w, err.Error())
+				return
+			}
+
+			var percent float64
+			if total > 0 {
+				percent = float64(cumulative[n]) * 100 / float64(total)
+			}
+
+			d.Distribution[i] = DistributionTier{
+				Top:     tops[i],
+				Coins:   coins,
+				Percent: strconv.FormatFloat(percent, 'f', 2, 64),
+			}
+		}
+
+		wh.SendJSONOr500(logger, w, d)
+	}
+}
+
 // method: GET
 // url: /addresscount
 func getAddressCount(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
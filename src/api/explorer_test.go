@@ -12,84 +12,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 
-	"github.com/stretchr/testify/mock"
-
-	"strconv"
-
 	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/testutil"
-	"github.com/skycoin/skycoin/src/util/droplet"
 	"github.com/skycoin/skycoin/src/visor"
 )
 
-func makeSuccessCoinSupplyResult(t *testing.T, allUnspents visor.ReadableOutputSet) *CoinSupply {
-	unlockedAddrs := visor.GetUnlockedDistributionAddresses()
-	var unlockedSupply uint64
-	// check confirmed unspents only
-	// Search map of unlocked addresses
-	// used to filter unspents
-	unlockedAddrMap := daemon.MakeSearchMap(unlockedAddrs)
-	for _, u := range allUnspents.HeadOutputs {
-		// check if address is an unlocked distribution address
-		if _, ok := unlockedAddrMap[u.Address]; ok {
-			coins, err := droplet.FromString(u.Coins)
-			require.NoError(t, err)
-			unlockedSupply += coins
-		}
-	}
-	// "total supply" is the number of coins unlocked.
-	// Each distribution address was allocated visor.DistributionAddressInitialBalance coins.
-	totalSupply := uint64(len(unlockedAddrs)) * visor.DistributionAddressInitialBalance
-	totalSupply *= droplet.Multiplier
-
-	// "current supply" is the number of coins distribution from the unlocked pool
-	currentSupply := totalSupply - unlockedSupply
-
-	currentSupplyStr, err := droplet.ToString(currentSupply)
-	require.NoError(t, err)
-
-	totalSupplyStr, err := droplet.ToString(totalSupply)
-	require.NoError(t, err)
-
-	maxSupplyStr, err := droplet.ToString(visor.MaxCoinSupply * droplet.Multiplier)
-	require.NoError(t, err)
-
-	// locked distribution addresses
-	lockedAddrs := visor.GetLockedDistributionAddresses()
-	lockedAddrMap := daemon.MakeSearchMap(lockedAddrs)
-
-	// get total coins hours which excludes locked distribution addresses
-	var totalCoinHours uint64
-	for _, out := range allUnspents.HeadOutputs {
-		if _, ok := lockedAddrMap[out.Address]; !ok {
-			totalCoinHours += out.Hours
-		}
-	}
-
-	// get current coin hours which excludes all distribution addresses
-	var currentCoinHours uint64
-	for _, out := range allUnspents.HeadOutputs {
-		// check if address not in locked distribution addresses
-		if _, ok := lockedAddrMap[out.Address]; !ok {
-			// check if address not in unlocked distribution addresses
-			if _, ok := unlockedAddrMap[out.Address]; !ok {
-				currentCoinHours += out.Hours
-			}
-		}
-	}
-
-	cs := CoinSupply{
-		CurrentSupply:         currentSupplyStr,
-		TotalSupply:           totalSupplyStr,
-		MaxSupply:             maxSupplyStr,
-		CurrentCoinHourSupply: strconv.FormatUint(currentCoinHours, 10),
-		TotalCoinHourSupply:   strconv.FormatUint(totalCoinHours, 10),
-		UnlockedAddresses:     unlockedAddrs,
-		LockedAddresses:       visor.GetLockedDistributionAddresses(),
-	}
-	return &cs
-}
-
 func TestGetTransactionsForAddress(t *testing.T) {
 	address := testutil.MakeAddress()
 	successAddress := "111111111111111111111691FSP"
@@ -200,28 +127,119 @@ func TestGetTransactionsForAddress(t *testing.T) {
 
 func TestCoinSupply(t *testing.T) {
 	unlockedAddrs := visor.GetUnlockedDistributionAddresses()
-	successGatewayGetUnspentOutputsResult := visor.ReadableOutputSet{
-		HeadOutputs: visor.ReadableOutputs{
-			visor.ReadableOutput{
-				Coins: "0",
+	lockedAddrs := visor.GetLockedDistributionAddresses()
+
+	tt := []struct {
+		name                       string
+		method                     string
+		status                     int
+		err                        string
+		gatewayGetCoinSupplyResult *visor.CoinSupply
+		gatewayGetCoinSupplyErr    error
+		result                     *CoinSupply
+		csrfDisabled               bool
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:                    "500 - gatewayGetCoinSupplyErr",
+			method:                  http.MethodGet,
+			status:                  http.StatusInternalServerError,
+			err:                     "500 Internal Server Error - gateway.GetCoinSupply failed: gatewayGetCoinSupplyErr",
+			gatewayGetCoinSupplyErr: errors.New("gatewayGetCoinSupplyErr"),
+		},
+		{
+			name:   "500 - too large supply",
+			method: http.MethodGet,
+			status: http.StatusInternalServerError,
+			err:    "500 Internal Server Error - Failed to convert coins to string: Droplet string conversion failed: Value is too large",
+			gatewayGetCoinSupplyResult: &visor.CoinSupply{
+				CurrentSupply: 9223372036854775808,
+			},
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			gatewayGetCoinSupplyResult: &visor.CoinSupply{
+				CurrentSupply:         7187500000000,
+				TotalSupply:           25000000000000,
+				MaxSupply:             100000000000000,
+				CurrentCoinHourSupply: 23499025077,
+				TotalCoinHourSupply:   93679828577,
+				UnlockedAddresses:     unlockedAddrs,
+				LockedAddresses:       lockedAddrs,
 			},
-			visor.ReadableOutput{
-				Coins: "0",
+			result: &CoinSupply{
+				CurrentSupply:         "7187500.000000",
+				TotalSupply:           "25000000.000000",
+				MaxSupply:             "100000000.000000",
+				CurrentCoinHourSupply: "23499025077",
+				TotalCoinHourSupply:   "93679828577",
+				UnlockedAddresses:     unlockedAddrs,
+				LockedAddresses:       lockedAddrs,
 			},
 		},
 	}
-	var filterInUnlocked []daemon.OutputsFilter
-	filterInUnlocked = append(filterInUnlocked, daemon.FbyAddresses(unlockedAddrs))
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := "/api/v1/coinSupply"
+			gateway := NewGatewayerMock()
+			gateway.On("GetCoinSupply").Return(tc.gatewayGetCoinSupplyResult, tc.gatewayGetCoinSupplyErr)
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			csrfStore := &CSRFStore{
+				Enabled: !tc.csrfDisabled,
+			}
+			if csrfStore.Enabled {
+				setCSRFParameters(csrfStore, tokenValid, req)
+			} else {
+				setCSRFParameters(csrfStore, tokenInvalid, req)
+			}
+			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, csrfStore, nil)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "case: %s, handler returned wrong status code: got `%v` want `%v`", tc.name, status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()), "case: %s, handler returned wrong error message: got `%v`| %s, want `%v`",
+					tc.name, strings.TrimSpace(rr.Body.String()), status, tc.err)
+			} else {
+				var msg *CoinSupply
+				err = json.Unmarshal(rr.Body.Bytes(), &msg)
+				require.NoError(t, err)
+				require.Equal(t, tc.result, msg)
+			}
+		})
+	}
+}
+
+func TestGetSupplyHistory(t *testing.T) {
+	type httpParams struct {
+		start string
+		end   string
+	}
+
 	tt := []struct {
-		name                           string
-		method                         string
-		status                         int
-		err                            string
-		gatewayGetUnspentOutputsArg    []daemon.OutputsFilter
-		gatewayGetUnspentOutputsResult *visor.ReadableOutputSet
-		gatewayGetUnspentOutputsErr    error
-		result                         *CoinSupply
-		csrfDisabled                   bool
+		name                            string
+		method                          string
+		status                          int
+		err                             string
+		httpParams                      *httpParams
+		start                           uint64
+		end                             uint64
+		gatewayGetSupplySnapshotsResult []visor.SupplySnapshot
+		gatewayGetSupplySnapshotsErr    error
+		result                          SupplyHistory
 	}{
 		{
 			name:   "405",
@@ -230,77 +248,311 @@ func TestCoinSupply(t *testing.T) {
 			err:    "405 Method Not Allowed",
 		},
 		{
-			name:   "500 - gatewayGetUnspentOutputsErr",
+			name:   "400 - invalid start",
 			method: http.MethodGet,
-			status: http.StatusInternalServerError,
-			err:    "500 Internal Server Error - gateway.GetUnspentOutputs failed: gatewayGetUnspentOutputsErr",
-			gatewayGetUnspentOutputsArg: filterInUnlocked,
-			gatewayGetUnspentOutputsErr: errors.New("gatewayGetUnspentOutputsErr"),
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid start",
+			httpParams: &httpParams{
+				start: "foo",
+			},
 		},
 		{
-			name:   "500 - gatewayGetUnspentOutputsErr",
+			name:   "400 - invalid end",
 			method: http.MethodGet,
-			status: http.StatusInternalServerError,
-			err:    "500 Internal Server Error - gateway.GetUnspentOutputs failed: gatewayGetUnspentOutputsErr",
-			gatewayGetUnspentOutputsArg: filterInUnlocked,
-			gatewayGetUnspentOutputsErr: errors.New("gatewayGetUnspentOutputsErr"),
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid end",
+			httpParams: &httpParams{
+				end: "-1",
+			},
+		},
+		{
+			name:   "400 - end before start",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - end must be >= start",
+			httpParams: &httpParams{
+				start: "86400",
+				end:   "100",
+			},
 		},
 		{
-			name:   "500 - too large HeadOutputs item",
+			name:   "500 - gateway error",
 			method: http.MethodGet,
 			status: http.StatusInternalServerError,
-			err:    "500 Internal Server Error - Invalid unlocked output balance string 9223372036854775807: Droplet string conversion failed: Value is too large",
-			gatewayGetUnspentOutputsArg: filterInUnlocked,
-			gatewayGetUnspentOutputsResult: &visor.ReadableOutputSet{
-				HeadOutputs: visor.ReadableOutputs{
-					visor.ReadableOutput{
-						Coins:   "9223372036854775807",
-						Address: unlockedAddrs[0],
-					},
-					visor.ReadableOutput{
-						Coins: "1",
+			err:    "500 Internal Server Error - gateway.GetSupplySnapshots failed: gatewayGetSupplySnapshotsErr",
+			gatewayGetSupplySnapshotsErr: errors.New("gatewayGetSupplySnapshotsErr"),
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			httpParams: &httpParams{
+				start: "1526342400",
+				end:   "1526428800",
+			},
+			start: 1526342400,
+			end:   1526428800,
+			gatewayGetSupplySnapshotsResult: []visor.SupplySnapshot{
+				{
+					Time:                  1526342400,
+					BkSeq:                 24000,
+					BlockTime:             1526342413,
+					CurrentSupply:         7187500000000,
+					TotalSupply:           25000000000000,
+					CurrentCoinHourSupply: 23499025077,
+					TotalCoinHourSupply:   93679828577,
+					AddressCount:          10103,
+				},
+			},
+			result: SupplyHistory{
+				Snapshots: []SupplySnapshot{
+					{
+						Date:                  "2018-05-15",
+						Time:                  1526342400,
+						BlockSeq:              24000,
+						BlockTime:             1526342413,
+						CurrentSupply:         "7187500.000000",
+						TotalSupply:           "25000000.000000",
+						CurrentCoinHourSupply: "23499025077",
+						TotalCoinHourSupply:   "93679828577",
+						AddressCount:          10103,
 					},
 				},
 			},
 		},
 		{
-			name:   "200",
+			name:   "200 no snapshots",
 			method: http.MethodGet,
 			status: http.StatusOK,
+			result: SupplyHistory{
+				Snapshots: []SupplySnapshot{},
+			},
+		},
+	}
 
-			gatewayGetUnspentOutputsArg: filterInUnlocked,
-			gatewayGetUnspentOutputsResult: &visor.ReadableOutputSet{
-				HeadOutputs: visor.ReadableOutputs{
-					visor.ReadableOutput{
-						Coins: "0",
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := "/api/v1/explorer/supplyHistory"
+			gateway := NewGatewayerMock()
+			gateway.On("GetSupplySnapshots", tc.start, tc.end).Return(tc.gatewayGetSupplySnapshotsResult, tc.gatewayGetSupplySnapshotsErr)
+
+			v := url.Values{}
+			if tc.httpParams != nil {
+				if tc.httpParams.start != "" {
+					v.Add("start", tc.httpParams.start)
+				}
+				if tc.httpParams.end != "" {
+					v.Add("end", tc.httpParams.end)
+				}
+			}
+			if len(v) > 0 {
+				endpoint += "?" + v.Encode()
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, csrfStore, nil)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "case: %s, handler returned wrong status code: got `%v` want `%v`", tc.name, status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()), "case: %s, handler returned wrong error message: got `%v`| %s, want `%v`",
+					tc.name, strings.TrimSpace(rr.Body.String()), status, tc.err)
+			} else {
+				var msg SupplyHistory
+				err = json.Unmarshal(rr.Body.Bytes(), &msg)
+				require.NoError(t, err)
+				require.Equal(t, tc.result, msg)
+			}
+		})
+	}
+}
+
+func TestGetDistribution(t *testing.T) {
+	richlist := visor.Richlist{
+		{
+			Address: "2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF",
+			Coins:   "1000000.000000",
+		},
+		{
+			Address: "27jg25DZX21MXMypVbKJMmgCJ5SPuEunMF1",
+			Coins:   "500000.000000",
+		},
+		{
+			Address: "2fGi2jhvp6ppHg3DecguZgzqvpJj2Gd4KHW",
+			Coins:   "300000.000000",
+		},
+		{
+			Address: "2TmvdBWJgxMwGs84R4drS9p5fYkva4dGdfs",
+			Coins:   "200000.000000",
+		},
+	}
+
+	tt := []struct {
+		name                            string
+		method                          string
+		status                          int
+		err                             string
+		query                           string
+		includeDistribution             bool
+		gatewayGetRichlistSummaryN      int
+		gatewayGetRichlistSummaryResult *visor.RichlistSummary
+		gatewayGetRichlistSummaryErr    error
+		result                          Distribution
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "400 - invalid n",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid n",
+			query:  "n=1,foo",
+		},
+		{
+			name:   "400 - zero n",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid n",
+			query:  "n=0",
+		},
+		{
+			name:   "400 - invalid include-distribution",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid include-distribution",
+			query:  "include-distribution=foo",
+		},
+		{
+			name:                         "500 - gw GetRichlistSummary error",
+			method:                       http.MethodGet,
+			status:                       http.StatusInternalServerError,
+			err:                          "500 Internal Server Error - gatewayGetRichlistSummaryErr",
+			gatewayGetRichlistSummaryN:   1000,
+			gatewayGetRichlistSummaryErr: errors.New("gatewayGetRichlistSummaryErr"),
+		},
+		{
+			name:                       "200",
+			method:                     http.MethodGet,
+			status:                     http.StatusOK,
+			query:                      "n=1,2,10&include-distribution=true",
+			includeDistribution:        true,
+			gatewayGetRichlistSummaryN: 10,
+			gatewayGetRichlistSummaryResult: &visor.RichlistSummary{
+				Richlist:     richlist,
+				TotalCoins:   2000000e6,
+				AddressCount: 4,
+			},
+			result: Distribution{
+				TotalCoins:   "2000000.000000",
+				AddressCount: 4,
+				Distribution: []DistributionTier{
+					{
+						Top:     1,
+						Coins:   "1000000.000000",
+						Percent: "50.00",
+					},
+					{
+						Top:     2,
+						Coins:   "1500000.000000",
+						Percent: "75.00",
+					},
+					{
+						Top:     10,
+						Coins:   "2000000.000000",
+						Percent: "100.00",
+					},
+				},
+			},
+		},
+		{
+			name:                       "200 top of the richlist",
+			method:                     http.MethodGet,
+			status:                     http.StatusOK,
+			query:                      "n=2,1",
+			gatewayGetRichlistSummaryN: 2,
+			gatewayGetRichlistSummaryResult: &visor.RichlistSummary{
+				Richlist:     richlist[:2],
+				TotalCoins:   2000000e6,
+				AddressCount: 4,
+			},
+			result: Distribution{
+				TotalCoins:   "2000000.000000",
+				AddressCount: 4,
+				Distribution: []DistributionTier{
+					{
+						Top:     2,
+						Coins:   "1500000.000000",
+						Percent: "75.00",
 					},
-					visor.ReadableOutput{
-						Coins: "0",
+					{
+						Top:     1,
+						Coins:   "1000000.000000",
+						Percent: "50.00",
+					},
+				},
+			},
+		},
+		{
+			name:                            "200 empty richlist",
+			method:                          http.MethodGet,
+			status:                          http.StatusOK,
+			gatewayGetRichlistSummaryN:      1000,
+			gatewayGetRichlistSummaryResult: &visor.RichlistSummary{},
+			result: Distribution{
+				TotalCoins:   "0.000000",
+				AddressCount: 0,
+				Distribution: []DistributionTier{
+					{
+						Top:     10,
+						Coins:   "0.000000",
+						Percent: "0.00",
+					},
+					{
+						Top:     100,
+						Coins:   "0.000000",
+						Percent: "0.00",
+					},
+					{
+						Top:     1000,
+						Coins:   "0.000000",
+						Percent: "0.00",
 					},
 				},
 			},
-			result: makeSuccessCoinSupplyResult(t, successGatewayGetUnspentOutputsResult),
 		},
 	}
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
-			endpoint := "/api/v1/coinSupply"
+			endpoint := "/api/v1/explorer/distribution"
+			if tc.query != "" {
+				endpoint += "?" + tc.query
+			}
+
 			gateway := NewGatewayerMock()
-			gateway.On("GetUnspentOutputs", mock.Anything).Return(tc.gatewayGetUnspentOutputsResult, tc.gatewayGetUnspentOutputsErr)
+			gateway.On("GetRichlistSummary", tc.includeDistribution, tc.gatewayGetRichlistSummaryN).Return(tc.gatewayGetRichlistSummaryResult, tc.gatewayGetRichlistSummaryErr)
 
 			req, err := http.NewRequest(tc.method, endpoint, nil)
 			require.NoError(t, err)
 
 			rr := httptest.NewRecorder()
 			csrfStore := &CSRFStore{
-				Enabled: !tc.csrfDisabled,
-			}
-			if csrfStore.Enabled {
-				setCSRFParameters(csrfStore, tokenValid, req)
-			} else {
-				setCSRFParameters(csrfStore, tokenInvalid, req)
+				Enabled: true,
 			}
+			setCSRFParameters(csrfStore, tokenValid, req)
 			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, csrfStore, nil)
 			handler.ServeHTTP(rr, req)
 
@@ -311,7 +563,7 @@ func TestCoinSupply(t *testing.T) {
 				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()), "case: %s, handler returned wrong error message: got `%v`| %s, want `%v`",
 					tc.name, strings.TrimSpace(rr.Body.String()), status, tc.err)
 			} else {
-				var msg *CoinSupply
+				var msg Distribution
 				err = json.Unmarshal(rr.Body.Bytes(), &msg)
 				require.NoError(t, err)
 				require.Equal(t, tc.result, msg)
@@ -332,6 +584,7 @@ func TestGetRichlist(t *testing.T) {
 		err                      string
 		httpParams               *httpParams
 		includeDistribution      bool
+		gatewayGetRichlistN      int
 		gatewayGetRichlistResult visor.Richlist
 		gatewayGetRichlistErr    error
 		result                   Richlist
@@ -371,6 +624,7 @@ func TestGetRichlist(t *testing.T) {
 				topn:                "1",
 				includeDistribution: "false",
 			},
+			gatewayGetRichlistN:   1,
 			gatewayGetRichlistErr: errors.New("gatewayGetRichlistErr"),
 		},
 		{
@@ -381,6 +635,7 @@ func TestGetRichlist(t *testing.T) {
 				topn:                "3",
 				includeDistribution: "false",
 			},
+			gatewayGetRichlistN: 3,
 			gatewayGetRichlistResult: visor.Richlist{
 				{
 					Address: "2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF",
@@ -499,7 +754,7 @@ func TestGetRichlist(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			endpoint := "/api/v1/richlist"
 			gateway := NewGatewayerMock()
-			gateway.On("GetRichlist", tc.includeDistribution).Return(tc.gatewayGetRichlistResult, tc.gatewayGetRichlistErr)
+			gateway.On("GetRichlist", tc.includeDistribution, tc.gatewayGetRichlistN).Return(tc.gatewayGetRichlistResult, tc.gatewayGetRichlistErr)
 
 			v := url.Values{}
 			if tc.httpParams != nil {
@@ -1,9 +1,13 @@
 package api
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon"
+	wh "github.com/skycoin/skycoin/src/util/http" //http,json helpers
 	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/visor/historydb"
 	"github.com/skycoin/skycoin/src/wallet"
@@ -16,18 +20,28 @@ import (
 type Gatewayer interface {
 	Spend(wltID string, password []byte, coins uint64, dest cipher.Address) (*coin.Transaction, error)
 	CreateTransaction(w wallet.CreateTransactionParams) (*coin.Transaction, []wallet.UxBalance, error)
+	EstimateTransaction(params wallet.CreateTransactionParams) (*visor.TransactionEstimate, error)
 	GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalance, error)
 	GetWallet(wltID string) (*wallet.Wallet, error)
 	GetWallets() (wallet.Wallets, error)
 	UpdateWalletLabel(wltID, label string) error
 	GetWalletUnconfirmedTxns(wltID string) ([]visor.UnconfirmedTxn, error)
+	GetWalletLedger(wltID string, start, end uint64) ([]visor.LedgerEntry, error)
 	CreateWallet(wltName string, options wallet.Options) (*wallet.Wallet, error)
 	NewAddresses(wltID string, password []byte, n uint64) ([]cipher.Address, error)
 	GetWalletDir() (string, error)
 	IsWalletAPIEnabled() bool
 	EncryptWallet(wltID string, password []byte) (*wallet.Wallet, error)
 	DecryptWallet(wltID string, password []byte) (*wallet.Wallet, error)
+	ChangeWalletPassword(wltID string, password []byte, opts wallet.ChangePasswordOptions) (*wallet.Wallet, error)
+	GetWalletMetadata(wltID string, password []byte) (*wallet.Metadata, error)
+	SetWalletNote(wltID string, password []byte, txid cipher.SHA256, note string) (*wallet.Metadata, error)
+	SetWalletAddressLabel(wltID string, password []byte, addr cipher.Address, label string) (*wallet.Metadata, error)
+	SetWalletContact(wltID string, password []byte, name string, addr cipher.Address) (*wallet.Metadata, error)
+	DeleteWalletContact(wltID string, password []byte, name string) (*wallet.Metadata, error)
 	GetWalletSeed(wltID string, password []byte) (string, error)
+	ExportWallet(wltID string, password, walletPassword []byte, watchOnly bool) (*wallet.EncryptedBundle, error)
+	ImportWallet(eb wallet.EncryptedBundle, password []byte) (*wallet.Wallet, error)
 	GetSignedBlockByHash(hash cipher.SHA256) (*coin.SignedBlock, error)
 	GetSignedBlockBySeq(seq uint64) (*coin.SignedBlock, error)
 	GetBlocks(start, end uint64) (*visor.ReadableBlocks, error)
@@ -37,22 +51,51 @@ type Gatewayer interface {
 	GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error)
 	GetBlockchainMetadata() (*visor.BlockchainMetadata, error)
 	GetBlockchainProgress() (*daemon.BlockchainProgress, error)
+	GetKeyRotations() ([]coin.KeyRotation, error)
+	InjectBroadcastKeyRotation(r coin.KeyRotation) error
 	GetConnection(addr string) *daemon.Connection
 	GetConnections() *daemon.Connections
 	GetDefaultConnections() []string
 	GetTrustConnections() []string
 	GetExchgConnection() []string
 	GetAllUnconfirmedTxns() ([]visor.UnconfirmedTxn, error)
+	GetUnconfirmedTxnPoolStats() (*visor.UnconfirmedTxnPoolStats, error)
+	GetExpiredUnconfirmedTxns(maxAge time.Duration) ([]visor.UnconfirmedTxn, error)
+	RemoveExpiredUnconfirmedTxns(maxAge time.Duration) ([]cipher.SHA256, error)
 	GetTransaction(txid cipher.SHA256) (*visor.Transaction, error)
 	GetTransactions(flts ...visor.TxFilter) ([]visor.Transaction, error)
+	GetLedger(addrs []cipher.Address, start, end uint64) ([]visor.LedgerEntry, error)
 	InjectBroadcastTransaction(txn coin.Transaction) error
 	ResendUnconfirmedTxns() (*daemon.ResendResult, error)
 	GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, error)
 	GetAddrUxOuts(addr []cipher.Address) ([]*historydb.UxOut, error)
 	GetTransactionsForAddress(a cipher.Address) ([]daemon.ReadableTransaction, error)
-	GetRichlist(includeDistribution bool) (visor.Richlist, error)
+	GetRichlist(includeDistribution bool, n int) (visor.Richlist, error)
+	GetRichlistSummary(includeDistribution bool, n int) (*visor.RichlistSummary, error)
+	GetCoinSupply() (*visor.CoinSupply, error)
+	GetSupplySnapshots(start, end uint64) ([]visor.SupplySnapshot, error)
 	GetAddressCount() (uint64, error)
 	GetHealth() (*daemon.Health, error)
 	UnloadWallet(id string) error
 	VerifyTxnVerbose(txn *coin.Transaction) ([]wallet.UxBalance, bool, error)
 }
+
+// requestIDGatewayer is a Gatewayer that can add the ID of an API request to its log lines
+type requestIDGatewayer interface {
+	WithRequestID(id string) *daemon.Gateway
+}
+
+// requestGateway returns a Gatewayer that adds the ID of the request to its log lines,
+// if gateway supports it
+func requestGateway(gateway Gatewayer, r *http.Request) Gatewayer {
+	id := wh.RequestID(r.Context())
+	if id == "" {
+		return gateway
+	}
+
+	if g, ok := gateway.(requestIDGatewayer); ok {
+		return g.WithRequestID(id)
+	}
+
+	return gateway
+}
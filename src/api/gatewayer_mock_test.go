@@ -7,6 +7,7 @@ package api
 
 import (
 	"fmt"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -27,6 +28,33 @@ func NewGatewayerMock() *GatewayerMock {
 	return &GatewayerMock{}
 }
 
+// ChangeWalletPassword mocked method
+func (m *GatewayerMock) ChangeWalletPassword(p0 string, p1 []byte, p2 wallet.ChangePasswordOptions) (*wallet.Wallet, error) {
+
+	ret := m.Called(p0, p1, p2)
+
+	var r0 *wallet.Wallet
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *wallet.Wallet:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // CreateTransaction mocked method
 func (m *GatewayerMock) CreateTransaction(p0 wallet.CreateTransactionParams) (*coin.Transaction, []wallet.UxBalance, error) {
 
@@ -117,6 +145,33 @@ func (m *GatewayerMock) DecryptWallet(p0 string, p1 []byte) (*wallet.Wallet, err
 
 }
 
+// DeleteWalletContact mocked method
+func (m *GatewayerMock) DeleteWalletContact(p0 string, p1 []byte, p2 string) (*wallet.Metadata, error) {
+
+	ret := m.Called(p0, p1, p2)
+
+	var r0 *wallet.Metadata
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *wallet.Metadata:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // EncryptWallet mocked method
 func (m *GatewayerMock) EncryptWallet(p0 string, p1 []byte) (*wallet.Wallet, error) {
 
@@ -144,6 +199,60 @@ func (m *GatewayerMock) EncryptWallet(p0 string, p1 []byte) (*wallet.Wallet, err
 
 }
 
+// EstimateTransaction mocked method
+func (m *GatewayerMock) EstimateTransaction(p0 wallet.CreateTransactionParams) (*visor.TransactionEstimate, error) {
+
+	ret := m.Called(p0)
+
+	var r0 *visor.TransactionEstimate
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *visor.TransactionEstimate:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// ExportWallet mocked method
+func (m *GatewayerMock) ExportWallet(p0 string, p1 []byte, p2 []byte, p3 bool) (*wallet.EncryptedBundle, error) {
+
+	ret := m.Called(p0, p1, p2, p3)
+
+	var r0 *wallet.EncryptedBundle
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *wallet.EncryptedBundle:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetAddrUxOuts mocked method
 func (m *GatewayerMock) GetAddrUxOuts(p0 []cipher.Address) ([]*historydb.UxOut, error) {
 
@@ -351,6 +460,33 @@ func (m *GatewayerMock) GetBuildInfo() visor.BuildInfo {
 
 }
 
+// GetCoinSupply mocked method
+func (m *GatewayerMock) GetCoinSupply() (*visor.CoinSupply, error) {
+
+	ret := m.Called()
+
+	var r0 *visor.CoinSupply
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *visor.CoinSupply:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetConnection mocked method
 func (m *GatewayerMock) GetConnection(p0 string) *daemon.Connection {
 
@@ -423,6 +559,33 @@ func (m *GatewayerMock) GetExchgConnection() []string {
 
 }
 
+// GetExpiredUnconfirmedTxns mocked method
+func (m *GatewayerMock) GetExpiredUnconfirmedTxns(p0 time.Duration) ([]visor.UnconfirmedTxn, error) {
+
+	ret := m.Called(p0)
+
+	var r0 []visor.UnconfirmedTxn
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []visor.UnconfirmedTxn:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetHealth mocked method
 func (m *GatewayerMock) GetHealth() (*daemon.Health, error) {
 
@@ -450,6 +613,33 @@ func (m *GatewayerMock) GetHealth() (*daemon.Health, error) {
 
 }
 
+// GetKeyRotations mocked method
+func (m *GatewayerMock) GetKeyRotations() ([]coin.KeyRotation, error) {
+
+	ret := m.Called()
+
+	var r0 []coin.KeyRotation
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []coin.KeyRotation:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetLastBlocks mocked method
 func (m *GatewayerMock) GetLastBlocks(p0 uint64) (*visor.ReadableBlocks, error) {
 
@@ -477,10 +667,37 @@ func (m *GatewayerMock) GetLastBlocks(p0 uint64) (*visor.ReadableBlocks, error)
 
 }
 
+// GetLedger mocked method
+func (m *GatewayerMock) GetLedger(p0 []cipher.Address, p1 uint64, p2 uint64) ([]visor.LedgerEntry, error) {
+
+	ret := m.Called(p0, p1, p2)
+
+	var r0 []visor.LedgerEntry
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []visor.LedgerEntry:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetRichlist mocked method
-func (m *GatewayerMock) GetRichlist(p0 bool) (visor.Richlist, error) {
+func (m *GatewayerMock) GetRichlist(p0 bool, p1 int) (visor.Richlist, error) {
 
-	ret := m.Called(p0)
+	ret := m.Called(p0, p1)
 
 	var r0 visor.Richlist
 	switch res := ret.Get(0).(type) {
@@ -504,6 +721,33 @@ func (m *GatewayerMock) GetRichlist(p0 bool) (visor.Richlist, error) {
 
 }
 
+// GetRichlistSummary mocked method
+func (m *GatewayerMock) GetRichlistSummary(p0 bool, p1 int) (*visor.RichlistSummary, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 *visor.RichlistSummary
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *visor.RichlistSummary:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetSignedBlockByHash mocked method
 func (m *GatewayerMock) GetSignedBlockByHash(p0 cipher.SHA256) (*coin.SignedBlock, error) {
 
@@ -558,6 +802,33 @@ func (m *GatewayerMock) GetSignedBlockBySeq(p0 uint64) (*coin.SignedBlock, error
 
 }
 
+// GetSupplySnapshots mocked method
+func (m *GatewayerMock) GetSupplySnapshots(p0 uint64, p1 uint64) ([]visor.SupplySnapshot, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 []visor.SupplySnapshot
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []visor.SupplySnapshot:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetTransaction mocked method
 func (m *GatewayerMock) GetTransaction(p0 cipher.SHA256) (*visor.Transaction, error) {
 
@@ -657,6 +928,33 @@ func (m *GatewayerMock) GetTrustConnections() []string {
 
 }
 
+// GetUnconfirmedTxnPoolStats mocked method
+func (m *GatewayerMock) GetUnconfirmedTxnPoolStats() (*visor.UnconfirmedTxnPoolStats, error) {
+
+	ret := m.Called()
+
+	var r0 *visor.UnconfirmedTxnPoolStats
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *visor.UnconfirmedTxnPoolStats:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetUnspentOutputs mocked method
 func (m *GatewayerMock) GetUnspentOutputs(p0 ...daemon.OutputsFilter) (*visor.ReadableOutputSet, error) {
 
@@ -801,6 +1099,60 @@ func (m *GatewayerMock) GetWalletDir() (string, error) {
 
 }
 
+// GetWalletLedger mocked method
+func (m *GatewayerMock) GetWalletLedger(p0 string, p1 uint64, p2 uint64) ([]visor.LedgerEntry, error) {
+
+	ret := m.Called(p0, p1, p2)
+
+	var r0 []visor.LedgerEntry
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []visor.LedgerEntry:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetWalletMetadata mocked method
+func (m *GatewayerMock) GetWalletMetadata(p0 string, p1 []byte) (*wallet.Metadata, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 *wallet.Metadata
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *wallet.Metadata:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetWalletSeed mocked method
 func (m *GatewayerMock) GetWalletSeed(p0 string, p1 []byte) (string, error) {
 
@@ -882,6 +1234,51 @@ func (m *GatewayerMock) GetWallets() (wallet.Wallets, error) {
 
 }
 
+// ImportWallet mocked method
+func (m *GatewayerMock) ImportWallet(p0 wallet.EncryptedBundle, p1 []byte) (*wallet.Wallet, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 *wallet.Wallet
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *wallet.Wallet:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// InjectBroadcastKeyRotation mocked method
+func (m *GatewayerMock) InjectBroadcastKeyRotation(p0 coin.KeyRotation) error {
+
+	ret := m.Called(p0)
+
+	var r0 error
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case error:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0
+
+}
+
 // InjectBroadcastTransaction mocked method
 func (m *GatewayerMock) InjectBroadcastTransaction(p0 coin.Transaction) error {
 
@@ -945,6 +1342,33 @@ func (m *GatewayerMock) NewAddresses(p0 string, p1 []byte, p2 uint64) ([]cipher.
 
 }
 
+// RemoveExpiredUnconfirmedTxns mocked method
+func (m *GatewayerMock) RemoveExpiredUnconfirmedTxns(p0 time.Duration) ([]cipher.SHA256, error) {
+
+	ret := m.Called(p0)
+
+	var r0 []cipher.SHA256
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []cipher.SHA256:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // ResendUnconfirmedTxns mocked method
 func (m *GatewayerMock) ResendUnconfirmedTxns() (*daemon.ResendResult, error) {
 
@@ -972,6 +1396,87 @@ func (m *GatewayerMock) ResendUnconfirmedTxns() (*daemon.ResendResult, error) {
 
 }
 
+// SetWalletAddressLabel mocked method
+func (m *GatewayerMock) SetWalletAddressLabel(p0 string, p1 []byte, p2 cipher.Address, p3 string) (*wallet.Metadata, error) {
+
+	ret := m.Called(p0, p1, p2, p3)
+
+	var r0 *wallet.Metadata
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *wallet.Metadata:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// SetWalletContact mocked method
+func (m *GatewayerMock) SetWalletContact(p0 string, p1 []byte, p2 string, p3 cipher.Address) (*wallet.Metadata, error) {
+
+	ret := m.Called(p0, p1, p2, p3)
+
+	var r0 *wallet.Metadata
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *wallet.Metadata:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// SetWalletNote mocked method
+func (m *GatewayerMock) SetWalletNote(p0 string, p1 []byte, p2 cipher.SHA256, p3 string) (*wallet.Metadata, error) {
+
+	ret := m.Called(p0, p1, p2, p3)
+
+	var r0 *wallet.Metadata
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *wallet.Metadata:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // Spend mocked method
 func (m *GatewayerMock) Spend(p0 string, p1 []byte, p2 uint64, p3 cipher.Address) (*coin.Transaction, error) {
 
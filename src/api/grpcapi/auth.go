@@ -0,0 +1,169 @@
+package grpcapi
+
+import (
+	"context"
+	"math"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+// authorizationKey is the metadata key of the API token, sent as "Bearer <token>" like the Authorization header of the REST API
+const authorizationKey = "authorization"
+
+// methodScopes are the API token scopes required by the methods of the Node service.
+// Methods which are not listed require api.ScopeAdmin.
+var methodScopes = map[string]api.Scope{
+	Node_GetBlockchainMetadata_FullMethodName:            api.ScopeRead,
+	Node_GetBlock_FullMethodName:                         api.ScopeRead,
+	Node_GetBlocks_FullMethodName:                        api.ScopeRead,
+	Node_GetLastBlocks_FullMethodName:                    api.ScopeRead,
+	Node_GetTransaction_FullMethodName:                   api.ScopeRead,
+	Node_GetTransactions_FullMethodName:                  api.ScopeRead,
+	Node_GetUnconfirmedTransactions_FullMethodName:       api.ScopeRead,
+	Node_GetOutputs_FullMethodName:                       api.ScopeRead,
+	Node_GetBalance_FullMethodName:                       api.ScopeRead,
+	Node_InjectTransaction_FullMethodName:                api.ScopeAdmin,
+	Node_SubscribeBlocks_FullMethodName:                  api.ScopeRead,
+	Node_SubscribeUnconfirmedTransactions_FullMethodName: api.ScopeRead,
+}
+
+// expensiveMethods are limited by the expensive rate limiter, like the expensive endpoints of the REST API
+var expensiveMethods = map[string]struct{}{
+	Node_GetBlocks_FullMethodName:       {},
+	Node_GetLastBlocks_FullMethodName:   {},
+	Node_GetTransactions_FullMethodName: {},
+	Node_GetOutputs_FullMethodName:      {},
+}
+
+// checker verifies the API token and the rate limits of the calls, like api.AuthCheck and api.RateLimitCheck
+type checker struct {
+	// tokens is nil if API authentication is disabled
+	tokens *api.TokenStore
+	// The rate limiters are nil if the limit is disabled
+	rateLimiter          *api.RateLimiter
+	expensiveRateLimiter *api.RateLimiter
+}
+
+// check verifies the API token of a call to method, then takes a request from the client's rate limits.
+// A subscription counts as one request when it is opened.
+func (c *checker) check(ctx context.Context, method string) error {
+	client, err := c.authorize(ctx, method)
+	if err != nil {
+		return err
+	}
+
+	if err := allow(c.rateLimiter, client); err != nil {
+		return err
+	}
+
+	if _, ok := expensiveMethods[method]; ok {
+		return allow(c.expensiveRateLimiter, client)
+	}
+
+	return nil
+}
+
+// authorize verifies that the call's API token grants the scope of method, if API authentication is enabled.
+// Returns the rate limit client of the call, its API token ID if API authentication is enabled, otherwise its IP address.
+func (c *checker) authorize(ctx context.Context, method string) (string, error) {
+	if c.tokens == nil {
+		return "ip:" + peerHost(ctx), nil
+	}
+
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(authorizationKey); len(v) != 0 {
+			header = v[0]
+		}
+	}
+
+	if header == "" {
+		return "", status.Error(codes.Unauthenticated, "missing API token")
+	}
+
+	prefix := "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return "", status.Error(codes.Unauthenticated, "invalid authorization metadata, must use the Bearer scheme")
+	}
+
+	t, err := c.tokens.Lookup(strings.TrimSpace(strings.TrimPrefix(header, prefix)))
+	switch err {
+	case nil:
+	case api.ErrAPITokenNotFound:
+		return "", status.Error(codes.Unauthenticated, "invalid API token")
+	default:
+		logger.WithError(err).Error("API token lookup failed")
+		return "", status.Error(codes.Internal, "API token lookup failed")
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = api.ScopeAdmin
+	}
+
+	if !t.Allows(scope) {
+		return "", status.Errorf(codes.PermissionDenied, "API token does not have the %s scope", scope)
+	}
+
+	return "token:" + t.ID, nil
+}
+
+// allow takes a request from the client's bucket of limiter. If limiter is nil, all requests are allowed.
+func allow(limiter *api.RateLimiter, client string) error {
+	if limiter == nil {
+		return nil
+	}
+
+	ok, wait := limiter.Allow(client)
+	if ok {
+		return nil
+	}
+
+	retryAfter := int64(math.Ceil(wait.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	logger.Warningf("gRPC rate limit exceeded by %s", client)
+	return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %d seconds", retryAfter)
+}
+
+// peerHost returns the IP address of the client of a call
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	addr := p.Addr.String()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
+
+func (c *checker) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := c.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (c *checker) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := c.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
@@ -0,0 +1,141 @@
+package grpcapi
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func newTestTokenStore(t *testing.T) (*api.TokenStore, func()) {
+	dir, err := ioutil.TempDir("", "grpcapitokens")
+	require.NoError(t, err)
+
+	store, err := api.LoadTokenStore(filepath.Join(dir, api.APITokensFilename))
+	require.NoError(t, err)
+
+	return store, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer "+token)
+}
+
+func TestAuth(t *testing.T) {
+	store, cleanup := newTestTokenStore(t)
+	defer cleanup()
+
+	readToken, _, err := store.CreateToken("read", []api.Scope{api.ScopeRead})
+	require.NoError(t, err)
+	adminToken, _, err := store.CreateToken("admin", []api.Scope{api.ScopeAdmin})
+	require.NoError(t, err)
+
+	txn := makeTransaction(t)
+
+	gateway := NewGatewayerMock()
+	gateway.On("GetBlockchainMetadata").Return(nil, visor.ErrTxnPoolFull)
+	gateway.On("InjectBroadcastTransaction", txn).Return(nil)
+
+	client, s, closeConn := newTestClient(t, Config{Tokens: store}, gateway)
+	defer closeConn()
+	defer s.Shutdown()
+
+	// Missing and invalid tokens
+	_, err = client.GetBlockchainMetadata(context.Background(), &GetBlockchainMetadataRequest{})
+	requireCode(t, codes.Unauthenticated, err)
+
+	_, err = client.GetBlockchainMetadata(withToken("foo"), &GetBlockchainMetadataRequest{})
+	requireCode(t, codes.Unauthenticated, err)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), authorizationKey, readToken)
+	_, err = client.GetBlockchainMetadata(ctx, &GetBlockchainMetadataRequest{})
+	requireCode(t, codes.Unauthenticated, err)
+
+	// The read scope allows reading but not injecting transactions.
+	// GetBlockchainMetadata fails in the gateway, after the token is verified
+	_, err = client.GetBlockchainMetadata(withToken(readToken), &GetBlockchainMetadataRequest{})
+	requireCode(t, codes.Internal, err)
+
+	_, err = client.InjectTransaction(withToken(readToken), &InjectTransactionRequest{
+		RawTx: txn.Serialize(),
+	})
+	requireCode(t, codes.PermissionDenied, err)
+
+	resp, err := client.InjectTransaction(withToken(adminToken), &InjectTransactionRequest{
+		RawTx: txn.Serialize(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, txn.Hash().Hex(), resp.Txid)
+
+	// Subscriptions are checked when they are opened
+	stream, err := client.SubscribeBlocks(context.Background(), &SubscribeBlocksRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	requireCode(t, codes.Unauthenticated, err)
+
+	gateway.AssertNumberOfCalls(t, "InjectBroadcastTransaction", 1)
+}
+
+func TestRateLimit(t *testing.T) {
+	store, cleanup := newTestTokenStore(t)
+	defer cleanup()
+
+	token, _, err := store.CreateToken("admin", []api.Scope{api.ScopeAdmin})
+	require.NoError(t, err)
+	token2, _, err := store.CreateToken("admin2", []api.Scope{api.ScopeAdmin})
+	require.NoError(t, err)
+
+	txn := makeTransaction(t)
+
+	gateway := NewGatewayerMock()
+	gateway.On("InjectBroadcastTransaction", txn).Return(nil)
+	gateway.On("GetSignedBlockBySeq", uint64(1)).Return(makeBlock(t, 1), nil)
+
+	client, s, closeConn := newTestClient(t, Config{
+		Tokens: store,
+		RateLimit: api.RateLimitConfig{
+			Rate:           0.001,
+			Burst:          2,
+			ExpensiveRate:  0.001,
+			ExpensiveBurst: 1,
+		},
+	}, gateway)
+	defer closeConn()
+	defer s.Shutdown()
+
+	inject := func(token string) error {
+		_, err := client.InjectTransaction(withToken(token), &InjectTransactionRequest{
+			RawTx: txn.Serialize(),
+		})
+		return err
+	}
+
+	require.NoError(t, inject(token))
+
+	// GetBlocks is also limited by the expensive rate limit
+	_, err = client.GetBlocks(withToken(token), &GetBlocksRequest{Start: 1, End: 1})
+	require.NoError(t, err)
+
+	err = inject(token)
+	requireCode(t, codes.ResourceExhausted, err)
+
+	// Clients are limited separately
+	require.NoError(t, inject(token2))
+
+	_, err = client.GetBlocks(withToken(token2), &GetBlocksRequest{Start: 1, End: 1})
+	require.NoError(t, err)
+	_, err = client.GetBlocks(withToken(token2), &GetBlocksRequest{Start: 1, End: 1})
+	requireCode(t, codes.ResourceExhausted, err)
+
+	gateway.AssertNumberOfCalls(t, "InjectBroadcastTransaction", 2)
+}
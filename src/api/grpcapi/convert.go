@@ -0,0 +1,126 @@
+package grpcapi
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func newBlockHeader(bh coin.BlockHeader) *BlockHeader {
+	return &BlockHeader{
+		Seq:          bh.BkSeq,
+		Hash:         bh.Hash().Hex(),
+		PreviousHash: bh.PrevHash.Hex(),
+		Time:         bh.Time,
+		Fee:          bh.Fee,
+		Version:      bh.Version,
+		BodyHash:     bh.BodyHash.Hex(),
+		UxHash:       bh.UxHash.Hex(),
+	}
+}
+
+func newBlock(b *coin.SignedBlock) *Block {
+	txns := make([]*Transaction, len(b.Body.Transactions))
+	for i := range b.Body.Transactions {
+		txns[i] = newTransaction(&b.Body.Transactions[i], b.Head.BkSeq == 0)
+	}
+
+	return &Block{
+		Header:       newBlockHeader(b.Head),
+		Transactions: txns,
+		Sig:          b.Sig.Hex(),
+	}
+}
+
+// newTransaction converts a coin.Transaction. The outputs of the genesis
+// transaction are derived from an empty txid, as in visor.NewReadableTransaction.
+func newTransaction(txn *coin.Transaction, isGenesis bool) *Transaction {
+	txid := txn.Hash()
+	uxTxid := txid
+	if isGenesis {
+		uxTxid = cipher.SHA256{}
+	}
+
+	sigs := make([]string, len(txn.Sigs))
+	for i := range txn.Sigs {
+		sigs[i] = txn.Sigs[i].Hex()
+	}
+
+	in := make([]string, len(txn.In))
+	for i := range txn.In {
+		in[i] = txn.In[i].Hex()
+	}
+
+	out := make([]*TransactionOutput, len(txn.Out))
+	for i := range txn.Out {
+		out[i] = &TransactionOutput{
+			Uxid:    txn.Out[i].UxID(uxTxid).Hex(),
+			Address: txn.Out[i].Address.String(),
+			Coins:   txn.Out[i].Coins,
+			Hours:   txn.Out[i].Hours,
+		}
+	}
+
+	return &Transaction{
+		Txid:      txid.Hex(),
+		Length:    txn.Length,
+		Type:      uint32(txn.Type),
+		InnerHash: txn.InnerHash.Hex(),
+		Sigs:      sigs,
+		Inputs:    in,
+		Outputs:   out,
+	}
+}
+
+func newTransactionWithStatus(txn *visor.Transaction) *TransactionWithStatus {
+	return &TransactionWithStatus{
+		Transaction: newTransaction(&txn.Txn, txn.Status.Confirmed && txn.Status.BlockSeq == 0),
+		Status: &TransactionStatus{
+			Confirmed:   txn.Status.Confirmed,
+			Unconfirmed: txn.Status.Unconfirmed,
+			Height:      txn.Status.Height,
+			BlockSeq:    txn.Status.BlockSeq,
+		},
+		Time: txn.Time,
+	}
+}
+
+func newUnconfirmedTransaction(txn *visor.UnconfirmedTxn) *UnconfirmedTransaction {
+	return &UnconfirmedTransaction{
+		Transaction: newTransaction(&txn.Txn, false),
+		Received:    txn.Received,
+		IsValid:     txn.IsValid == 1,
+	}
+}
+
+func newOutputs(outs visor.ReadableOutputs) ([]*Output, error) {
+	os := make([]*Output, len(outs))
+	for i, o := range outs {
+		coins, err := droplet.FromString(o.Coins)
+		if err != nil {
+			return nil, err
+		}
+
+		os[i] = &Output{
+			Hash:            o.Hash,
+			Time:            o.Time,
+			BlockSeq:        o.BkSeq,
+			SrcTx:           o.SourceTransaction,
+			Address:         o.Address,
+			Coins:           coins,
+			Hours:           o.Hours,
+			CalculatedHours: o.CalculatedHours,
+		}
+	}
+
+	return os, nil
+}
+
+func newBalance(b wallet.Balance) *Balance {
+	return &Balance{
+		Coins: b.Coins,
+		Hours: b.Hours,
+	}
+}
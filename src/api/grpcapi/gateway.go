@@ -0,0 +1,24 @@
+package grpcapi
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+//go:generate goautomock -template=testify Gatewayer
+
+// Gatewayer provides the daemon gateway methods used by the gRPC service
+type Gatewayer interface {
+	GetBlockchainMetadata() (*visor.BlockchainMetadata, error)
+	GetSignedBlockByHash(hash cipher.SHA256) (*coin.SignedBlock, error)
+	GetSignedBlockBySeq(seq uint64) (*coin.SignedBlock, error)
+	GetTransaction(txid cipher.SHA256) (*visor.Transaction, error)
+	GetTransactions(flts ...visor.TxFilter) ([]visor.Transaction, error)
+	GetAllUnconfirmedTxns() ([]visor.UnconfirmedTxn, error)
+	GetUnspentOutputs(filters ...daemon.OutputsFilter) (*visor.ReadableOutputSet, error)
+	GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error)
+	InjectBroadcastTransaction(txn coin.Transaction) error
+}
@@ -0,0 +1,261 @@
+/*
+* CODE GENERATED AUTOMATICALLY WITH github.com/ernesto-jimenez/goautomock
+* THIS FILE MUST NEVER BE EDITED MANUALLY
+ */
+
+package grpcapi
+
+import (
+	"fmt"
+
+	mock "github.com/stretchr/testify/mock"
+
+	cipher "github.com/skycoin/skycoin/src/cipher"
+	coin "github.com/skycoin/skycoin/src/coin"
+	daemon "github.com/skycoin/skycoin/src/daemon"
+	visor "github.com/skycoin/skycoin/src/visor"
+	wallet "github.com/skycoin/skycoin/src/wallet"
+)
+
+// GatewayerMock mock
+type GatewayerMock struct {
+	mock.Mock
+}
+
+func NewGatewayerMock() *GatewayerMock {
+	return &GatewayerMock{}
+}
+
+// GetAllUnconfirmedTxns mocked method
+func (m *GatewayerMock) GetAllUnconfirmedTxns() ([]visor.UnconfirmedTxn, error) {
+
+	ret := m.Called()
+
+	var r0 []visor.UnconfirmedTxn
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []visor.UnconfirmedTxn:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetBalanceOfAddrs mocked method
+func (m *GatewayerMock) GetBalanceOfAddrs(p0 []cipher.Address) ([]wallet.BalancePair, error) {
+
+	ret := m.Called(p0)
+
+	var r0 []wallet.BalancePair
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []wallet.BalancePair:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetBlockchainMetadata mocked method
+func (m *GatewayerMock) GetBlockchainMetadata() (*visor.BlockchainMetadata, error) {
+
+	ret := m.Called()
+
+	var r0 *visor.BlockchainMetadata
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *visor.BlockchainMetadata:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetSignedBlockByHash mocked method
+func (m *GatewayerMock) GetSignedBlockByHash(p0 cipher.SHA256) (*coin.SignedBlock, error) {
+
+	ret := m.Called(p0)
+
+	var r0 *coin.SignedBlock
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *coin.SignedBlock:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetSignedBlockBySeq mocked method
+func (m *GatewayerMock) GetSignedBlockBySeq(p0 uint64) (*coin.SignedBlock, error) {
+
+	ret := m.Called(p0)
+
+	var r0 *coin.SignedBlock
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *coin.SignedBlock:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetTransaction mocked method
+func (m *GatewayerMock) GetTransaction(p0 cipher.SHA256) (*visor.Transaction, error) {
+
+	ret := m.Called(p0)
+
+	var r0 *visor.Transaction
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *visor.Transaction:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetTransactions mocked method
+func (m *GatewayerMock) GetTransactions(p0 ...visor.TxFilter) ([]visor.Transaction, error) {
+
+	ret := m.Called(p0)
+
+	var r0 []visor.Transaction
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []visor.Transaction:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetUnspentOutputs mocked method
+func (m *GatewayerMock) GetUnspentOutputs(p0 ...daemon.OutputsFilter) (*visor.ReadableOutputSet, error) {
+
+	ret := m.Called(p0)
+
+	var r0 *visor.ReadableOutputSet
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *visor.ReadableOutputSet:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// InjectBroadcastTransaction mocked method
+func (m *GatewayerMock) InjectBroadcastTransaction(p0 coin.Transaction) error {
+
+	ret := m.Called(p0)
+
+	var r0 error
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case error:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0
+
+}
@@ -0,0 +1,449 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// nodeServer implements NodeServer on top of a Gatewayer
+type nodeServer struct {
+	UnimplementedNodeServer
+
+	gateway Gatewayer
+	cfg     Config
+	// Closed when the Server shuts down, to end the subscriptions
+	quit chan struct{}
+}
+
+func errInternal(err error) error {
+	return status.Error(codes.Internal, err.Error())
+}
+
+func errInvalidArgument(format string, a ...interface{}) error {
+	return status.Errorf(codes.InvalidArgument, format, a...)
+}
+
+func (n *nodeServer) parseAddresses(addrs []string) ([]cipher.Address, error) {
+	if n.cfg.MaxRequestAddresses > 0 && len(addrs) > n.cfg.MaxRequestAddresses {
+		return nil, errInvalidArgument("too many addresses, at most %d addresses can be requested", n.cfg.MaxRequestAddresses)
+	}
+
+	as := make([]cipher.Address, len(addrs))
+	for i, addr := range addrs {
+		a, err := cipher.DecodeBase58Address(addr)
+		if err != nil {
+			return nil, errInvalidArgument("invalid address %q: %v", addr, err)
+		}
+		as[i] = a
+	}
+
+	return as, nil
+}
+
+// GetBlockchainMetadata returns the head block header and the sizes of the unspent and unconfirmed pools
+func (n *nodeServer) GetBlockchainMetadata(ctx context.Context, req *GetBlockchainMetadataRequest) (*BlockchainMetadata, error) {
+	bcm, err := n.gateway.GetBlockchainMetadata()
+	if err != nil {
+		return nil, errInternal(err)
+	}
+
+	// visor.ReadableBlockHeader has no UxHash, read the full header of the head block
+	head, err := n.gateway.GetSignedBlockBySeq(bcm.Head.BkSeq)
+	if err != nil {
+		return nil, errInternal(err)
+	}
+	if head == nil {
+		return nil, status.Error(codes.Internal, "head block not found")
+	}
+
+	return &BlockchainMetadata{
+		Head:        newBlockHeader(head.Head),
+		Unspents:    bcm.Unspents,
+		Unconfirmed: bcm.Unconfirmed,
+	}, nil
+}
+
+// GetBlock returns a block by hash or by sequence number
+func (n *nodeServer) GetBlock(ctx context.Context, req *GetBlockRequest) (*Block, error) {
+	var b *coin.SignedBlock
+	var err error
+	switch blk := req.Block.(type) {
+	case *GetBlockRequest_Hash:
+		h, err := cipher.SHA256FromHex(blk.Hash)
+		if err != nil {
+			return nil, errInvalidArgument("invalid hash: %v", err)
+		}
+
+		b, err = n.gateway.GetSignedBlockByHash(h)
+		if err != nil {
+			return nil, errInternal(err)
+		}
+	case *GetBlockRequest_Seq:
+		b, err = n.gateway.GetSignedBlockBySeq(blk.Seq)
+		if err != nil {
+			return nil, errInternal(err)
+		}
+	default:
+		return nil, errInvalidArgument("hash or seq is required")
+	}
+
+	if b == nil {
+		return nil, status.Error(codes.NotFound, "block not found")
+	}
+
+	return newBlock(b), nil
+}
+
+func (n *nodeServer) getBlocks(start, end uint64) (*Blocks, error) {
+	var blocks []*Block
+	for seq := start; seq <= end; seq++ {
+		b, err := n.gateway.GetSignedBlockBySeq(seq)
+		if err != nil {
+			return nil, errInternal(err)
+		}
+		if b == nil {
+			break
+		}
+
+		blocks = append(blocks, newBlock(b))
+	}
+
+	return &Blocks{
+		Blocks: blocks,
+	}, nil
+}
+
+// GetBlocks returns the blocks with start <= seq <= end
+func (n *nodeServer) GetBlocks(ctx context.Context, req *GetBlocksRequest) (*Blocks, error) {
+	if req.End < req.Start {
+		return nil, errInvalidArgument("end must be greater than or equal to start")
+	}
+
+	if n.cfg.MaxBlockRange > 0 && req.End-req.Start >= n.cfg.MaxBlockRange {
+		return nil, errInvalidArgument("Block range is too large, at most %d blocks can be requested", n.cfg.MaxBlockRange)
+	}
+
+	return n.getBlocks(req.Start, req.End)
+}
+
+// GetLastBlocks returns the last n blocks
+func (n *nodeServer) GetLastBlocks(ctx context.Context, req *GetLastBlocksRequest) (*Blocks, error) {
+	if n.cfg.MaxBlockRange > 0 && req.N > n.cfg.MaxBlockRange {
+		return nil, errInvalidArgument("n is too large, at most %d blocks can be requested", n.cfg.MaxBlockRange)
+	}
+
+	if req.N == 0 {
+		return &Blocks{}, nil
+	}
+
+	bcm, err := n.gateway.GetBlockchainMetadata()
+	if err != nil {
+		return nil, errInternal(err)
+	}
+
+	end := bcm.Head.BkSeq
+	var start uint64
+	if end+1 > req.N {
+		start = end + 1 - req.N
+	}
+
+	return n.getBlocks(start, end)
+}
+
+// GetTransaction returns a confirmed or unconfirmed transaction by ID
+func (n *nodeServer) GetTransaction(ctx context.Context, req *GetTransactionRequest) (*TransactionWithStatus, error) {
+	txid, err := cipher.SHA256FromHex(req.Txid)
+	if err != nil {
+		return nil, errInvalidArgument("invalid txid: %v", err)
+	}
+
+	txn, err := n.gateway.GetTransaction(txid)
+	if err != nil {
+		return nil, errInternal(err)
+	}
+	if txn == nil {
+		return nil, status.Error(codes.NotFound, "transaction not found")
+	}
+
+	return newTransactionWithStatus(txn), nil
+}
+
+// GetTransactions returns the transactions of a set of addresses
+func (n *nodeServer) GetTransactions(ctx context.Context, req *GetTransactionsRequest) (*Transactions, error) {
+	addrs, err := n.parseAddresses(req.Addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	flts := []visor.TxFilter{visor.AddrsFilter(addrs)}
+	if req.Confirmed != nil {
+		flts = append(flts, visor.ConfirmedTxFilter(*req.Confirmed))
+	}
+
+	txns, err := n.gateway.GetTransactions(flts...)
+	if err != nil {
+		return nil, errInternal(err)
+	}
+
+	ts := make([]*TransactionWithStatus, len(txns))
+	for i := range txns {
+		ts[i] = newTransactionWithStatus(&txns[i])
+	}
+
+	return &Transactions{
+		Transactions: ts,
+	}, nil
+}
+
+// GetUnconfirmedTransactions returns all transactions of the unconfirmed pool
+func (n *nodeServer) GetUnconfirmedTransactions(ctx context.Context, req *GetUnconfirmedTransactionsRequest) (*UnconfirmedTransactions, error) {
+	txns, err := n.gateway.GetAllUnconfirmedTxns()
+	if err != nil {
+		return nil, errInternal(err)
+	}
+
+	ts := make([]*UnconfirmedTransaction, len(txns))
+	for i := range txns {
+		ts[i] = newUnconfirmedTransaction(&txns[i])
+	}
+
+	return &UnconfirmedTransactions{
+		Transactions: ts,
+	}, nil
+}
+
+// GetOutputs returns the unspent outputs of a set of addresses or by hash
+func (n *nodeServer) GetOutputs(ctx context.Context, req *GetOutputsRequest) (*Outputs, error) {
+	if len(req.Addresses) > 0 && len(req.Hashes) > 0 {
+		return nil, errInvalidArgument("addresses and hashes cannot be specified together")
+	}
+
+	var filters []daemon.OutputsFilter
+	switch {
+	case len(req.Addresses) > 0:
+		if _, err := n.parseAddresses(req.Addresses); err != nil {
+			return nil, err
+		}
+		filters = append(filters, daemon.FbyAddresses(req.Addresses))
+	case len(req.Hashes) > 0:
+		for _, h := range req.Hashes {
+			if _, err := cipher.SHA256FromHex(h); err != nil {
+				return nil, errInvalidArgument("invalid hash %q: %v", h, err)
+			}
+		}
+		filters = append(filters, daemon.FbyHashes(req.Hashes))
+	}
+
+	outs, err := n.gateway.GetUnspentOutputs(filters...)
+	if err != nil {
+		return nil, errInternal(err)
+	}
+
+	var o Outputs
+	if o.HeadOutputs, err = newOutputs(outs.HeadOutputs); err != nil {
+		return nil, errInternal(err)
+	}
+	if o.OutgoingOutputs, err = newOutputs(outs.OutgoingOutputs); err != nil {
+		return nil, errInternal(err)
+	}
+	if o.IncomingOutputs, err = newOutputs(outs.IncomingOutputs); err != nil {
+		return nil, errInternal(err)
+	}
+
+	return &o, nil
+}
+
+// GetBalance returns the confirmed and predicted balance of a set of addresses
+func (n *nodeServer) GetBalance(ctx context.Context, req *GetBalanceRequest) (*Balances, error) {
+	if len(req.Addresses) == 0 {
+		return nil, errInvalidArgument("addresses is required")
+	}
+
+	addrs, err := n.parseAddresses(req.Addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	bals, err := n.gateway.GetBalanceOfAddrs(addrs)
+	if err != nil {
+		return nil, errInternal(err)
+	}
+
+	var total wallet.BalancePair
+	addressBalances := make([]*AddressBalance, len(addrs))
+	for i, bal := range bals {
+		total.Confirmed, err = total.Confirmed.Add(bal.Confirmed)
+		if err != nil {
+			return nil, errInternal(err)
+		}
+
+		total.Predicted, err = total.Predicted.Add(bal.Predicted)
+		if err != nil {
+			return nil, errInternal(err)
+		}
+
+		addressBalances[i] = &AddressBalance{
+			Address:   addrs[i].String(),
+			Confirmed: newBalance(bal.Confirmed),
+			Predicted: newBalance(bal.Predicted),
+		}
+	}
+
+	return &Balances{
+		Confirmed: newBalance(total.Confirmed),
+		Predicted: newBalance(total.Predicted),
+		Addresses: addressBalances,
+	}, nil
+}
+
+// InjectTransaction verifies a transaction, adds it to the unconfirmed pool and broadcasts it
+func (n *nodeServer) InjectTransaction(ctx context.Context, req *InjectTransactionRequest) (*InjectTransactionResponse, error) {
+	if len(req.RawTx) == 0 {
+		return nil, errInvalidArgument("raw_tx is required")
+	}
+
+	txn, err := coin.TransactionDeserialize(req.RawTx)
+	if err != nil {
+		return nil, errInvalidArgument("%v", err)
+	}
+
+	if err := n.gateway.InjectBroadcastTransaction(txn); err != nil {
+		switch err.(type) {
+		case visor.ErrTxnViolatesHardConstraint, visor.ErrTxnViolatesSoftConstraint, visor.ErrTxnViolatesUserConstraint:
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		switch err {
+		case visor.ErrTxnPoolFull, visor.ErrTxnReplacementFeeTooLow:
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		return nil, status.Error(codes.Unavailable, fmt.Sprintf("inject tx failed: %v", err))
+	}
+
+	return &InjectTransactionResponse{
+		Txid: txn.Hash().Hex(),
+	}, nil
+}
+
+// wait blocks until the next poll, returning false if the subscription should end
+func (n *nodeServer) wait(ctx context.Context, ticker *time.Ticker) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-n.quit:
+		return false, nil
+	case <-ticker.C:
+		return true, nil
+	}
+}
+
+// SubscribeBlocks streams the blocks added to the blockchain
+func (n *nodeServer) SubscribeBlocks(req *SubscribeBlocksRequest, stream Node_SubscribeBlocksServer) error {
+	var next uint64
+	if req.Start != nil {
+		next = *req.Start
+	} else {
+		bcm, err := n.gateway.GetBlockchainMetadata()
+		if err != nil {
+			return errInternal(err)
+		}
+		next = bcm.Head.BkSeq + 1
+	}
+
+	ticker := time.NewTicker(n.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			b, err := n.gateway.GetSignedBlockBySeq(next)
+			if err != nil {
+				return errInternal(err)
+			}
+			if b == nil {
+				break
+			}
+
+			if err := stream.Send(newBlock(b)); err != nil {
+				return err
+			}
+			next++
+
+			select {
+			case <-stream.Context().Done():
+				return stream.Context().Err()
+			case <-n.quit:
+				return nil
+			default:
+			}
+		}
+
+		if ok, err := n.wait(stream.Context(), ticker); !ok {
+			return err
+		}
+	}
+}
+
+// SubscribeUnconfirmedTransactions streams the transactions added to and removed from the unconfirmed pool
+func (n *nodeServer) SubscribeUnconfirmedTransactions(req *SubscribeUnconfirmedTransactionsRequest, stream Node_SubscribeUnconfirmedTransactionsServer) error {
+	ticker := time.NewTicker(n.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var known map[cipher.SHA256]visor.UnconfirmedTxn
+	for {
+		txns, err := n.gateway.GetAllUnconfirmedTxns()
+		if err != nil {
+			return errInternal(err)
+		}
+
+		current := make(map[cipher.SHA256]visor.UnconfirmedTxn, len(txns))
+		for _, txn := range txns {
+			current[txn.Hash()] = txn
+		}
+
+		// The first poll only records the pool, unless the existing transactions were requested
+		if known != nil || req.IncludeExisting {
+			for i := range txns {
+				if _, ok := known[txns[i].Hash()]; ok {
+					continue
+				}
+
+				if err := stream.Send(&UnconfirmedTransactionEvent{
+					Type:        UnconfirmedTransactionEvent_ADDED,
+					Transaction: newUnconfirmedTransaction(&txns[i]),
+				}); err != nil {
+					return err
+				}
+			}
+
+			for h, txn := range known {
+				if _, ok := current[h]; ok {
+					continue
+				}
+
+				if err := stream.Send(&UnconfirmedTransactionEvent{
+					Type:        UnconfirmedTransactionEvent_REMOVED,
+					Transaction: newUnconfirmedTransaction(&txn),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		known = current
+
+		if ok, err := n.wait(stream.Context(), ticker); !ok {
+			return err
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// newTestClient serves gateway on an in-memory listener and returns a client connected to it.
+// The returned function closes the client connection.
+func newTestClient(t *testing.T, c Config, gateway Gatewayer) (NodeClient, *Server, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+	s := create(listener, c, gateway)
+	go s.Serve() // nolint: errcheck
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	return NewNodeClient(conn), s, func() {
+		conn.Close()
+	}
+}
+
+func makeTransaction(t *testing.T) coin.Transaction {
+	txn := coin.Transaction{}
+	txn.PushInput(testutil.RandSHA256(t))
+	txn.PushOutput(testutil.MakeAddress(), 1e6, 10)
+	txn.Sigs = append(txn.Sigs, testutil.RandSig(t))
+	txn.UpdateHeader()
+	return txn
+}
+
+func makeBlock(t *testing.T, seq uint64) *coin.SignedBlock {
+	return &coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq:    seq,
+				Time:     1000 + seq,
+				PrevHash: testutil.RandSHA256(t),
+			},
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{makeTransaction(t)},
+			},
+		},
+		Sig: testutil.RandSig(t),
+	}
+}
+
+func requireCode(t *testing.T, code codes.Code, err error) {
+	require.Error(t, err)
+	require.Equal(t, code, status.Code(err), err.Error())
+}
+
+func TestGetBlock(t *testing.T) {
+	b := makeBlock(t, 3)
+	hash := b.HashHeader()
+
+	tt := []struct {
+		name    string
+		req     *GetBlockRequest
+		bySeq   *coin.SignedBlock
+		byHash  *coin.SignedBlock
+		gwErr   error
+		code    codes.Code
+		resp    *Block
+		noCalls bool
+	}{
+		{
+			name:    "no selector",
+			req:     &GetBlockRequest{},
+			code:    codes.InvalidArgument,
+			noCalls: true,
+		},
+		{
+			name:    "invalid hash",
+			req:     &GetBlockRequest{Block: &GetBlockRequest_Hash{Hash: "foo"}},
+			code:    codes.InvalidArgument,
+			noCalls: true,
+		},
+		{
+			name: "by seq not found",
+			req:  &GetBlockRequest{Block: &GetBlockRequest_Seq{Seq: 3}},
+			code: codes.NotFound,
+		},
+		{
+			name:  "by seq error",
+			req:   &GetBlockRequest{Block: &GetBlockRequest_Seq{Seq: 3}},
+			gwErr: errors.New("db failure"),
+			code:  codes.Internal,
+		},
+		{
+			name:  "by seq",
+			req:   &GetBlockRequest{Block: &GetBlockRequest_Seq{Seq: 3}},
+			bySeq: b,
+			resp:  newBlock(b),
+		},
+		{
+			name:   "by hash",
+			req:    &GetBlockRequest{Block: &GetBlockRequest_Hash{Hash: hash.Hex()}},
+			byHash: b,
+			resp:   newBlock(b),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := NewGatewayerMock()
+			gateway.On("GetSignedBlockBySeq", uint64(3)).Return(tc.bySeq, tc.gwErr)
+			gateway.On("GetSignedBlockByHash", hash).Return(tc.byHash, tc.gwErr)
+
+			client, s, closeConn := newTestClient(t, Config{}, gateway)
+			defer closeConn()
+			defer s.Shutdown()
+			resp, err := client.GetBlock(context.Background(), tc.req)
+			if tc.code != codes.OK {
+				requireCode(t, tc.code, err)
+				if tc.noCalls {
+					gateway.AssertNotCalled(t, "GetSignedBlockBySeq", mock.Anything)
+					gateway.AssertNotCalled(t, "GetSignedBlockByHash", mock.Anything)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.resp.Header, resp.Header)
+			require.Equal(t, tc.resp.Sig, resp.Sig)
+			require.Len(t, resp.Transactions, 1)
+			require.Equal(t, b.Body.Transactions[0].Hash().Hex(), resp.Transactions[0].Txid)
+			require.Equal(t, b.Body.Transactions[0].Out[0].UxID(b.Body.Transactions[0].Hash()).Hex(), resp.Transactions[0].Outputs[0].Uxid)
+		})
+	}
+}
+
+func TestGetBlocks(t *testing.T) {
+	gateway := NewGatewayerMock()
+	for i := uint64(0); i <= 4; i++ {
+		gateway.On("GetSignedBlockBySeq", i).Return(makeBlock(t, i), nil)
+	}
+	gateway.On("GetSignedBlockBySeq", uint64(5)).Return(nil, nil)
+	gateway.On("GetBlockchainMetadata").Return(&visor.BlockchainMetadata{
+		Head: visor.ReadableBlockHeader{BkSeq: 4},
+	}, nil)
+
+	client, s, closeConn := newTestClient(t, Config{MaxBlockRange: 3}, gateway)
+	defer closeConn()
+	defer s.Shutdown()
+	ctx := context.Background()
+
+	_, err := client.GetBlocks(ctx, &GetBlocksRequest{Start: 2, End: 1})
+	requireCode(t, codes.InvalidArgument, err)
+
+	_, err = client.GetBlocks(ctx, &GetBlocksRequest{Start: 0, End: 3})
+	requireCode(t, codes.InvalidArgument, err)
+
+	blocks, err := client.GetBlocks(ctx, &GetBlocksRequest{Start: 3, End: 5})
+	require.NoError(t, err)
+	require.Len(t, blocks.Blocks, 2)
+	require.Equal(t, uint64(3), blocks.Blocks[0].Header.Seq)
+	require.Equal(t, uint64(4), blocks.Blocks[1].Header.Seq)
+
+	// The genesis outputs are derived from an empty txid
+	blocks, err = client.GetBlocks(ctx, &GetBlocksRequest{Start: 0, End: 0})
+	require.NoError(t, err)
+	require.Len(t, blocks.Blocks, 1)
+
+	_, err = client.GetLastBlocks(ctx, &GetLastBlocksRequest{N: 4})
+	requireCode(t, codes.InvalidArgument, err)
+
+	blocks, err = client.GetLastBlocks(ctx, &GetLastBlocksRequest{N: 2})
+	require.NoError(t, err)
+	require.Len(t, blocks.Blocks, 2)
+	require.Equal(t, uint64(3), blocks.Blocks[0].Header.Seq)
+	require.Equal(t, uint64(4), blocks.Blocks[1].Header.Seq)
+}
+
+func TestGetBalance(t *testing.T) {
+	addrs := []cipher.Address{testutil.MakeAddress(), testutil.MakeAddress()}
+
+	gateway := NewGatewayerMock()
+	gateway.On("GetBalanceOfAddrs", addrs).Return([]wallet.BalancePair{
+		{
+			Confirmed: wallet.Balance{Coins: 1e6, Hours: 2},
+			Predicted: wallet.Balance{Coins: 2e6, Hours: 3},
+		},
+		{
+			Confirmed: wallet.Balance{Coins: 3e6, Hours: 4},
+			Predicted: wallet.Balance{Coins: 3e6, Hours: 4},
+		},
+	}, nil)
+
+	client, s, closeConn := newTestClient(t, Config{MaxRequestAddresses: 2}, gateway)
+	defer closeConn()
+	defer s.Shutdown()
+	ctx := context.Background()
+
+	_, err := client.GetBalance(ctx, &GetBalanceRequest{})
+	requireCode(t, codes.InvalidArgument, err)
+
+	_, err = client.GetBalance(ctx, &GetBalanceRequest{Addresses: []string{"foo"}})
+	requireCode(t, codes.InvalidArgument, err)
+
+	_, err = client.GetBalance(ctx, &GetBalanceRequest{
+		Addresses: []string{addrs[0].String(), addrs[1].String(), testutil.MakeAddress().String()},
+	})
+	requireCode(t, codes.InvalidArgument, err)
+
+	bals, err := client.GetBalance(ctx, &GetBalanceRequest{
+		Addresses: []string{addrs[0].String(), addrs[1].String()},
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(4e6), bals.Confirmed.Coins)
+	require.Equal(t, uint64(6), bals.Confirmed.Hours)
+	require.Equal(t, uint64(5e6), bals.Predicted.Coins)
+	require.Equal(t, uint64(7), bals.Predicted.Hours)
+	require.Len(t, bals.Addresses, 2)
+	require.Equal(t, addrs[1].String(), bals.Addresses[1].Address)
+	require.Equal(t, uint64(3e6), bals.Addresses[1].Confirmed.Coins)
+}
+
+func TestInjectTransaction(t *testing.T) {
+	txn := makeTransaction(t)
+
+	tt := []struct {
+		name  string
+		rawTx []byte
+		err   error
+		code  codes.Code
+	}{
+		{
+			name: "empty",
+			code: codes.InvalidArgument,
+		},
+		{
+			name:  "invalid transaction",
+			rawTx: []byte{1, 2, 3},
+			code:  codes.InvalidArgument,
+		},
+		{
+			name:  "violates hard constraint",
+			rawTx: txn.Serialize(),
+			err:   visor.NewErrTxnViolatesHardConstraint(errors.New("Signature not valid for output being spent")),
+			code:  codes.InvalidArgument,
+		},
+		{
+			name:  "pool full",
+			rawTx: txn.Serialize(),
+			err:   visor.ErrTxnPoolFull,
+			code:  codes.FailedPrecondition,
+		},
+		{
+			name:  "broadcast failed",
+			rawTx: txn.Serialize(),
+			err:   errors.New("no connections"),
+			code:  codes.Unavailable,
+		},
+		{
+			name:  "ok",
+			rawTx: txn.Serialize(),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := NewGatewayerMock()
+			gateway.On("InjectBroadcastTransaction", txn).Return(tc.err)
+
+			client, s, closeConn := newTestClient(t, Config{}, gateway)
+			defer closeConn()
+			defer s.Shutdown()
+			resp, err := client.InjectTransaction(context.Background(), &InjectTransactionRequest{
+				RawTx: tc.rawTx,
+			})
+			if tc.code != codes.OK {
+				requireCode(t, tc.code, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, txn.Hash().Hex(), resp.Txid)
+		})
+	}
+}
+
+func TestSubscribeBlocks(t *testing.T) {
+	gateway := NewGatewayerMock()
+	gateway.On("GetSignedBlockBySeq", uint64(1)).Return(makeBlock(t, 1), nil)
+	gateway.On("GetSignedBlockBySeq", uint64(2)).Return(nil, nil).Once()
+	gateway.On("GetSignedBlockBySeq", uint64(2)).Return(makeBlock(t, 2), nil)
+	gateway.On("GetSignedBlockBySeq", uint64(3)).Return(nil, nil)
+
+	client, s, closeConn := newTestClient(t, Config{PollInterval: 10 * time.Millisecond}, gateway)
+	defer closeConn()
+
+	start := uint64(1)
+	stream, err := client.SubscribeBlocks(context.Background(), &SubscribeBlocksRequest{
+		Start: &start,
+	})
+	require.NoError(t, err)
+
+	b, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), b.Header.Seq)
+
+	// Block 2 is only found by the next poll
+	b, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), b.Header.Seq)
+
+	// Shutting down the server ends the subscription
+	go s.Shutdown()
+	_, err = stream.Recv()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestSubscribeUnconfirmedTransactions(t *testing.T) {
+	a := visor.UnconfirmedTxn{Txn: makeTransaction(t), Received: 1, IsValid: 1}
+	b := visor.UnconfirmedTxn{Txn: makeTransaction(t), Received: 2}
+
+	gateway := NewGatewayerMock()
+	gateway.On("GetAllUnconfirmedTxns").Return([]visor.UnconfirmedTxn{a}, nil).Once()
+	gateway.On("GetAllUnconfirmedTxns").Return([]visor.UnconfirmedTxn{a, b}, nil).Once()
+	gateway.On("GetAllUnconfirmedTxns").Return([]visor.UnconfirmedTxn{b}, nil)
+
+	client, s, closeConn := newTestClient(t, Config{PollInterval: 10 * time.Millisecond}, gateway)
+	defer closeConn()
+	defer s.Shutdown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.SubscribeUnconfirmedTransactions(ctx, &SubscribeUnconfirmedTransactionsRequest{
+		IncludeExisting: true,
+	})
+	require.NoError(t, err)
+
+	expected := []struct {
+		typ     UnconfirmedTransactionEvent_Type
+		txn     visor.UnconfirmedTxn
+		isValid bool
+	}{
+		{UnconfirmedTransactionEvent_ADDED, a, true},
+		{UnconfirmedTransactionEvent_ADDED, b, false},
+		{UnconfirmedTransactionEvent_REMOVED, a, true},
+	}
+
+	for _, e := range expected {
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, e.typ, ev.Type)
+		require.Equal(t, e.txn.Hash().Hex(), ev.Transaction.Transaction.Txid)
+		require.Equal(t, e.txn.Received, ev.Transaction.Received)
+		require.Equal(t, e.isValid, ev.Transaction.IsValid)
+	}
+
+	cancel()
+	_, err = stream.Recv()
+	requireCode(t, codes.Canceled, err)
+}
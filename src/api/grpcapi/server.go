@@ -0,0 +1,166 @@
+/*
+Package grpcapi implements the gRPC interface of the node.
+
+The service is defined in skycoin.proto. skycoin.pb.go and skycoin_grpc.pb.go
+are generated from it with protoc-gen-go and protoc-gen-go-grpc.
+*/
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative skycoin.proto
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/util/logging"
+)
+
+const (
+	// DefaultPollInterval is how often the subscriptions check for new blocks and unconfirmed transactions
+	DefaultPollInterval = time.Second
+)
+
+var (
+	logger = logging.MustGetLogger("grpcapi")
+)
+
+// Config configures Server
+type Config struct {
+	// Maximum number of blocks that GetBlocks and GetLastBlocks can return. 0 is unlimited
+	MaxBlockRange uint64
+	// Maximum number of addresses that a request can contain. 0 is unlimited
+	MaxRequestAddresses int
+	// How often the subscriptions check for new blocks and unconfirmed transactions
+	PollInterval time.Duration
+	// API tokens which calls must send in their authorization metadata. nil disables API authentication
+	Tokens *api.TokenStore
+	// Per-client rate limits of the calls. Clients are identified by their API token if API authentication
+	// is enabled, otherwise by their IP address
+	RateLimit api.RateLimitConfig
+}
+
+// Server exposes the Node service over gRPC
+type Server struct {
+	server   *grpc.Server
+	listener net.Listener
+	checker  *checker
+	quit     chan struct{}
+	done     chan struct{}
+}
+
+func create(listener net.Listener, c Config, gateway Gatewayer, opts ...grpc.ServerOption) *Server {
+	if c.PollInterval == 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+
+	quit := make(chan struct{})
+
+	checker := &checker{
+		tokens:               c.Tokens,
+		rateLimiter:          api.NewRateLimiter(c.RateLimit.Rate, c.RateLimit.Burst),
+		expensiveRateLimiter: api.NewRateLimiter(c.RateLimit.ExpensiveRate, c.RateLimit.ExpensiveBurst),
+	}
+
+	opts = append(opts,
+		grpc.UnaryInterceptor(checker.unaryInterceptor),
+		grpc.StreamInterceptor(checker.streamInterceptor),
+	)
+
+	server := grpc.NewServer(opts...)
+	RegisterNodeServer(server, &nodeServer{
+		gateway: gateway,
+		cfg:     c,
+		quit:    quit,
+	})
+
+	return &Server{
+		server:   server,
+		listener: listener,
+		checker:  checker,
+		quit:     quit,
+		done:     make(chan struct{}),
+	}
+}
+
+// Create creates a new Server instance that listens without TLS
+func Create(host string, c Config, gateway Gatewayer) (*Server, error) {
+	logger.Warning("gRPC TLS not in use!")
+
+	listener, err := net.Listen("tcp", host)
+	if err != nil {
+		return nil, err
+	}
+
+	return create(listener, c, gateway), nil
+}
+
+// CreateTLS creates a new Server instance that listens with TLS
+func CreateTLS(host string, c Config, gateway Gatewayer, certFile, keyFile string) (*Server, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Using %s for the gRPC certificate", certFile)
+	logger.Infof("Using %s for the gRPC key", keyFile)
+
+	listener, err := net.Listen("tcp", host)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+	})
+
+	return create(listener, c, gateway, grpc.Creds(creds)), nil
+}
+
+// Addr returns the listening address of the Server
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Serve serves the gRPC interface on the configured host
+func (s *Server) Serve() error {
+	logger.Infof("Starting gRPC interface on %s", s.listener.Addr())
+	defer logger.Info("gRPC interface closed")
+	defer close(s.done)
+
+	if err := s.server.Serve(s.listener); err != nil {
+		if err != grpc.ErrServerStopped {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRateLimit changes the rate limits of the calls, like api.Server.SetRuntimeConfig.
+// The rate limits can be changed but not enabled or disabled. A rate of 0 leaves the limit unchanged.
+func (s *Server) SetRateLimit(c api.RateLimitConfig) {
+	if s.checker.rateLimiter != nil && c.Rate > 0 {
+		s.checker.rateLimiter.SetLimit(c.Rate, c.Burst)
+	}
+	if s.checker.expensiveRateLimiter != nil && c.ExpensiveRate > 0 {
+		s.checker.expensiveRateLimiter.SetLimit(c.ExpensiveRate, c.ExpensiveBurst)
+	}
+}
+
+// Shutdown closes the gRPC service. Open subscriptions are ended and
+// pending calls are allowed to finish. This can only be called after Serve has been called.
+func (s *Server) Shutdown() {
+	if s == nil {
+		return
+	}
+
+	logger.Info("Shutting down gRPC interface")
+	defer logger.Info("gRPC interface shut down")
+	close(s.quit)
+	s.server.GracefulStop()
+	<-s.done
+}
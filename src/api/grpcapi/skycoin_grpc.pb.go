@@ -0,0 +1,595 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: skycoin.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Node_GetBlockchainMetadata_FullMethodName            = "/skycoin.api.v1.Node/GetBlockchainMetadata"
+	Node_GetBlock_FullMethodName                         = "/skycoin.api.v1.Node/GetBlock"
+	Node_GetBlocks_FullMethodName                        = "/skycoin.api.v1.Node/GetBlocks"
+	Node_GetLastBlocks_FullMethodName                    = "/skycoin.api.v1.Node/GetLastBlocks"
+	Node_GetTransaction_FullMethodName                   = "/skycoin.api.v1.Node/GetTransaction"
+	Node_GetTransactions_FullMethodName                  = "/skycoin.api.v1.Node/GetTransactions"
+	Node_GetUnconfirmedTransactions_FullMethodName       = "/skycoin.api.v1.Node/GetUnconfirmedTransactions"
+	Node_GetOutputs_FullMethodName                       = "/skycoin.api.v1.Node/GetOutputs"
+	Node_GetBalance_FullMethodName                       = "/skycoin.api.v1.Node/GetBalance"
+	Node_InjectTransaction_FullMethodName                = "/skycoin.api.v1.Node/InjectTransaction"
+	Node_SubscribeBlocks_FullMethodName                  = "/skycoin.api.v1.Node/SubscribeBlocks"
+	Node_SubscribeUnconfirmedTransactions_FullMethodName = "/skycoin.api.v1.Node/SubscribeUnconfirmedTransactions"
+)
+
+// NodeClient is the client API for Node service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type NodeClient interface {
+	// GetBlockchainMetadata returns the head block header and the sizes of the unspent and unconfirmed pools
+	GetBlockchainMetadata(ctx context.Context, in *GetBlockchainMetadataRequest, opts ...grpc.CallOption) (*BlockchainMetadata, error)
+	// GetBlock returns a block by hash or by sequence number
+	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*Block, error)
+	// GetBlocks returns the blocks with start <= seq <= end
+	GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (*Blocks, error)
+	// GetLastBlocks returns the last n blocks
+	GetLastBlocks(ctx context.Context, in *GetLastBlocksRequest, opts ...grpc.CallOption) (*Blocks, error)
+	// GetTransaction returns a confirmed or unconfirmed transaction by ID
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*TransactionWithStatus, error)
+	// GetTransactions returns the transactions of a set of addresses
+	GetTransactions(ctx context.Context, in *GetTransactionsRequest, opts ...grpc.CallOption) (*Transactions, error)
+	// GetUnconfirmedTransactions returns all transactions of the unconfirmed pool
+	GetUnconfirmedTransactions(ctx context.Context, in *GetUnconfirmedTransactionsRequest, opts ...grpc.CallOption) (*UnconfirmedTransactions, error)
+	// GetOutputs returns the unspent outputs of a set of addresses or by hash
+	GetOutputs(ctx context.Context, in *GetOutputsRequest, opts ...grpc.CallOption) (*Outputs, error)
+	// GetBalance returns the confirmed and predicted balance of a set of addresses
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balances, error)
+	// InjectTransaction verifies a transaction, adds it to the unconfirmed pool and broadcasts it
+	InjectTransaction(ctx context.Context, in *InjectTransactionRequest, opts ...grpc.CallOption) (*InjectTransactionResponse, error)
+	// SubscribeBlocks streams the blocks added to the blockchain
+	SubscribeBlocks(ctx context.Context, in *SubscribeBlocksRequest, opts ...grpc.CallOption) (Node_SubscribeBlocksClient, error)
+	// SubscribeUnconfirmedTransactions streams the transactions added to and removed from the unconfirmed pool
+	SubscribeUnconfirmedTransactions(ctx context.Context, in *SubscribeUnconfirmedTransactionsRequest, opts ...grpc.CallOption) (Node_SubscribeUnconfirmedTransactionsClient, error)
+}
+
+type nodeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNodeClient(cc grpc.ClientConnInterface) NodeClient {
+	return &nodeClient{cc}
+}
+
+func (c *nodeClient) GetBlockchainMetadata(ctx context.Context, in *GetBlockchainMetadataRequest, opts ...grpc.CallOption) (*BlockchainMetadata, error) {
+	out := new(BlockchainMetadata)
+	err := c.cc.Invoke(ctx, Node_GetBlockchainMetadata_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, Node_GetBlock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (*Blocks, error) {
+	out := new(Blocks)
+	err := c.cc.Invoke(ctx, Node_GetBlocks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetLastBlocks(ctx context.Context, in *GetLastBlocksRequest, opts ...grpc.CallOption) (*Blocks, error) {
+	out := new(Blocks)
+	err := c.cc.Invoke(ctx, Node_GetLastBlocks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*TransactionWithStatus, error) {
+	out := new(TransactionWithStatus)
+	err := c.cc.Invoke(ctx, Node_GetTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetTransactions(ctx context.Context, in *GetTransactionsRequest, opts ...grpc.CallOption) (*Transactions, error) {
+	out := new(Transactions)
+	err := c.cc.Invoke(ctx, Node_GetTransactions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetUnconfirmedTransactions(ctx context.Context, in *GetUnconfirmedTransactionsRequest, opts ...grpc.CallOption) (*UnconfirmedTransactions, error) {
+	out := new(UnconfirmedTransactions)
+	err := c.cc.Invoke(ctx, Node_GetUnconfirmedTransactions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetOutputs(ctx context.Context, in *GetOutputsRequest, opts ...grpc.CallOption) (*Outputs, error) {
+	out := new(Outputs)
+	err := c.cc.Invoke(ctx, Node_GetOutputs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balances, error) {
+	out := new(Balances)
+	err := c.cc.Invoke(ctx, Node_GetBalance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) InjectTransaction(ctx context.Context, in *InjectTransactionRequest, opts ...grpc.CallOption) (*InjectTransactionResponse, error) {
+	out := new(InjectTransactionResponse)
+	err := c.cc.Invoke(ctx, Node_InjectTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) SubscribeBlocks(ctx context.Context, in *SubscribeBlocksRequest, opts ...grpc.CallOption) (Node_SubscribeBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Node_ServiceDesc.Streams[0], Node_SubscribeBlocks_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeSubscribeBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Node_SubscribeBlocksClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type nodeSubscribeBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeSubscribeBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *nodeClient) SubscribeUnconfirmedTransactions(ctx context.Context, in *SubscribeUnconfirmedTransactionsRequest, opts ...grpc.CallOption) (Node_SubscribeUnconfirmedTransactionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Node_ServiceDesc.Streams[1], Node_SubscribeUnconfirmedTransactions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeSubscribeUnconfirmedTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Node_SubscribeUnconfirmedTransactionsClient interface {
+	Recv() (*UnconfirmedTransactionEvent, error)
+	grpc.ClientStream
+}
+
+type nodeSubscribeUnconfirmedTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeSubscribeUnconfirmedTransactionsClient) Recv() (*UnconfirmedTransactionEvent, error) {
+	m := new(UnconfirmedTransactionEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NodeServer is the server API for Node service.
+// All implementations must embed UnimplementedNodeServer
+// for forward compatibility
+type NodeServer interface {
+	// GetBlockchainMetadata returns the head block header and the sizes of the unspent and unconfirmed pools
+	GetBlockchainMetadata(context.Context, *GetBlockchainMetadataRequest) (*BlockchainMetadata, error)
+	// GetBlock returns a block by hash or by sequence number
+	GetBlock(context.Context, *GetBlockRequest) (*Block, error)
+	// GetBlocks returns the blocks with start <= seq <= end
+	GetBlocks(context.Context, *GetBlocksRequest) (*Blocks, error)
+	// GetLastBlocks returns the last n blocks
+	GetLastBlocks(context.Context, *GetLastBlocksRequest) (*Blocks, error)
+	// GetTransaction returns a confirmed or unconfirmed transaction by ID
+	GetTransaction(context.Context, *GetTransactionRequest) (*TransactionWithStatus, error)
+	// GetTransactions returns the transactions of a set of addresses
+	GetTransactions(context.Context, *GetTransactionsRequest) (*Transactions, error)
+	// GetUnconfirmedTransactions returns all transactions of the unconfirmed pool
+	GetUnconfirmedTransactions(context.Context, *GetUnconfirmedTransactionsRequest) (*UnconfirmedTransactions, error)
+	// GetOutputs returns the unspent outputs of a set of addresses or by hash
+	GetOutputs(context.Context, *GetOutputsRequest) (*Outputs, error)
+	// GetBalance returns the confirmed and predicted balance of a set of addresses
+	GetBalance(context.Context, *GetBalanceRequest) (*Balances, error)
+	// InjectTransaction verifies a transaction, adds it to the unconfirmed pool and broadcasts it
+	InjectTransaction(context.Context, *InjectTransactionRequest) (*InjectTransactionResponse, error)
+	// SubscribeBlocks streams the blocks added to the blockchain
+	SubscribeBlocks(*SubscribeBlocksRequest, Node_SubscribeBlocksServer) error
+	// SubscribeUnconfirmedTransactions streams the transactions added to and removed from the unconfirmed pool
+	SubscribeUnconfirmedTransactions(*SubscribeUnconfirmedTransactionsRequest, Node_SubscribeUnconfirmedTransactionsServer) error
+	mustEmbedUnimplementedNodeServer()
+}
+
+// UnimplementedNodeServer must be embedded to have forward compatible implementations.
+type UnimplementedNodeServer struct {
+}
+
+func (UnimplementedNodeServer) GetBlockchainMetadata(context.Context, *GetBlockchainMetadataRequest) (*BlockchainMetadata, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockchainMetadata not implemented")
+}
+func (UnimplementedNodeServer) GetBlock(context.Context, *GetBlockRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedNodeServer) GetBlocks(context.Context, *GetBlocksRequest) (*Blocks, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlocks not implemented")
+}
+func (UnimplementedNodeServer) GetLastBlocks(context.Context, *GetLastBlocksRequest) (*Blocks, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLastBlocks not implemented")
+}
+func (UnimplementedNodeServer) GetTransaction(context.Context, *GetTransactionRequest) (*TransactionWithStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedNodeServer) GetTransactions(context.Context, *GetTransactionsRequest) (*Transactions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactions not implemented")
+}
+func (UnimplementedNodeServer) GetUnconfirmedTransactions(context.Context, *GetUnconfirmedTransactionsRequest) (*UnconfirmedTransactions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUnconfirmedTransactions not implemented")
+}
+func (UnimplementedNodeServer) GetOutputs(context.Context, *GetOutputsRequest) (*Outputs, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOutputs not implemented")
+}
+func (UnimplementedNodeServer) GetBalance(context.Context, *GetBalanceRequest) (*Balances, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedNodeServer) InjectTransaction(context.Context, *InjectTransactionRequest) (*InjectTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InjectTransaction not implemented")
+}
+func (UnimplementedNodeServer) SubscribeBlocks(*SubscribeBlocksRequest, Node_SubscribeBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeBlocks not implemented")
+}
+func (UnimplementedNodeServer) SubscribeUnconfirmedTransactions(*SubscribeUnconfirmedTransactionsRequest, Node_SubscribeUnconfirmedTransactionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeUnconfirmedTransactions not implemented")
+}
+func (UnimplementedNodeServer) mustEmbedUnimplementedNodeServer() {}
+
+// UnsafeNodeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NodeServer will
+// result in compilation errors.
+type UnsafeNodeServer interface {
+	mustEmbedUnimplementedNodeServer()
+}
+
+func RegisterNodeServer(s grpc.ServiceRegistrar, srv NodeServer) {
+	s.RegisterService(&Node_ServiceDesc, srv)
+}
+
+func _Node_GetBlockchainMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockchainMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetBlockchainMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_GetBlockchainMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetBlockchainMetadata(ctx, req.(*GetBlockchainMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_GetBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetBlock(ctx, req.(*GetBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_GetBlocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetBlocks(ctx, req.(*GetBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetLastBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLastBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetLastBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_GetLastBlocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetLastBlocks(ctx, req.(*GetLastBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_GetTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_GetTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetTransactions(ctx, req.(*GetTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetUnconfirmedTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUnconfirmedTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetUnconfirmedTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_GetUnconfirmedTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetUnconfirmedTransactions(ctx, req.(*GetUnconfirmedTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetOutputs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOutputsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetOutputs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_GetOutputs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetOutputs(ctx, req.(*GetOutputsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_InjectTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InjectTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).InjectTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Node_InjectTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).InjectTransaction(ctx, req.(*InjectTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_SubscribeBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeServer).SubscribeBlocks(m, &nodeSubscribeBlocksServer{stream})
+}
+
+type Node_SubscribeBlocksServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type nodeSubscribeBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeSubscribeBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Node_SubscribeUnconfirmedTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeUnconfirmedTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeServer).SubscribeUnconfirmedTransactions(m, &nodeSubscribeUnconfirmedTransactionsServer{stream})
+}
+
+type Node_SubscribeUnconfirmedTransactionsServer interface {
+	Send(*UnconfirmedTransactionEvent) error
+	grpc.ServerStream
+}
+
+type nodeSubscribeUnconfirmedTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeSubscribeUnconfirmedTransactionsServer) Send(m *UnconfirmedTransactionEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Node_ServiceDesc is the grpc.ServiceDesc for Node service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Node_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "skycoin.api.v1.Node",
+	HandlerType: (*NodeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBlockchainMetadata",
+			Handler:    _Node_GetBlockchainMetadata_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _Node_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetBlocks",
+			Handler:    _Node_GetBlocks_Handler,
+		},
+		{
+			MethodName: "GetLastBlocks",
+			Handler:    _Node_GetLastBlocks_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _Node_GetTransaction_Handler,
+		},
+		{
+			MethodName: "GetTransactions",
+			Handler:    _Node_GetTransactions_Handler,
+		},
+		{
+			MethodName: "GetUnconfirmedTransactions",
+			Handler:    _Node_GetUnconfirmedTransactions_Handler,
+		},
+		{
+			MethodName: "GetOutputs",
+			Handler:    _Node_GetOutputs_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _Node_GetBalance_Handler,
+		},
+		{
+			MethodName: "InjectTransaction",
+			Handler:    _Node_InjectTransaction_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeBlocks",
+			Handler:       _Node_SubscribeBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeUnconfirmedTransactions",
+			Handler:       _Node_SubscribeUnconfirmedTransactions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "skycoin.proto",
+}
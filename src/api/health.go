@@ -28,6 +28,7 @@ type HealthResponse struct {
 // Method: GET
 func healthCheck(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
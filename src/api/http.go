@@ -40,6 +40,11 @@ type Server struct {
 	server   *http.Server
 	listener net.Listener
 	done     chan struct{}
+
+	csrfStore            *CSRFStore
+	unversionedAPI       *toggle
+	rateLimiter          *RateLimiter
+	expensiveRateLimiter *RateLimiter
 }
 
 // Config configures Server
@@ -50,17 +55,31 @@ type Config struct {
 	EnableJSON20RPC      bool
 	EnableGUI            bool
 	EnableUnversionedAPI bool
+	EnableAuth           bool
+	TokensFile           string
+	RateLimit            RateLimitConfig
+	MaxBlockRange        uint64
+	MaxRequestAddresses  int
 	ReadTimeout          time.Duration
 	WriteTimeout         time.Duration
 	IdleTimeout          time.Duration
+	// Reloads the node's config for POST /api/v1/reloadConfig
+	ConfigReloader ConfigReloader
 }
 
 type muxConfig struct {
-	host                 string
-	appLoc               string
-	enableGUI            bool
-	enableJSON20RPC      bool
-	enableUnversionedAPI bool
+	host            string
+	appLoc          string
+	enableGUI       bool
+	enableJSON20RPC bool
+	// The unversioned API endpoints are only registered if unversionedAPI is not nil
+	unversionedAPI       *toggle
+	configReloader       ConfigReloader
+	tokens               *TokenStore
+	rateLimiter          *RateLimiter
+	expensiveRateLimiter *RateLimiter
+	maxBlockRange        uint64
+	maxRequestAddresses  int
 }
 
 // HTTPResponse represents the http response struct
@@ -71,20 +90,38 @@ type HTTPResponse struct {
 
 // HTTPError is included in an HTTPResponse
 type HTTPError struct {
-	Message string `json:"message"`
-	Code    int    `json:"code"`
+	Message   string    `json:"message"`
+	Code      int       `json:"code"`
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
 }
 
 // NewHTTPErrorResponse returns an HTTPResponse with the Error field populated
 func NewHTTPErrorResponse(code int, msg string) HTTPResponse {
+	return newHTTPErrorResponse(code, msg, "")
+}
+
+// newHTTPErrorResponseFromError returns an HTTPResponse with the Error field populated with err,
+// and the ErrorCode of err if it is one of knownErrorCodes
+func newHTTPErrorResponseFromError(code int, err error) HTTPResponse {
+	return newHTTPErrorResponse(code, err.Error(), knownErrorCode(err))
+}
+
+// newHTTPErrorResponse returns an HTTPResponse with the Error field populated.
+// If errCode is empty, the ErrorCode of the status code is used.
+func newHTTPErrorResponse(code int, msg string, errCode ErrorCode) HTTPResponse {
 	if msg == "" {
 		msg = http.StatusText(code)
 	}
 
+	if errCode == "" {
+		errCode = statusErrorCode(code)
+	}
+
 	return HTTPResponse{
 		Error: &HTTPError{
-			Code:    code,
-			Message: msg,
+			Code:      code,
+			Message:   msg,
+			ErrorCode: errCode,
 		},
 	}
 }
@@ -112,10 +149,27 @@ func create(host string, c Config, gateway Gatewayer) (*Server, error) {
 		logger.Info("JSON 2.0 RPC enabled")
 		var err error
 		// TODO: change webprc to use http.Gatewayer
-		rpc, err = webrpc.New(gateway.(*daemon.Gateway))
+		rpc, err = webrpc.New(webrpc.Config{
+			MaxBlockRange: c.MaxBlockRange,
+		}, gateway.(*daemon.Gateway))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tokens *TokenStore
+	if c.EnableAuth {
+		var err error
+		tokens, err = LoadTokenStore(c.TokensFile)
 		if err != nil {
 			return nil, err
 		}
+
+		logger.Infof("API authentication enabled, using API tokens from %s", c.TokensFile)
+
+		if ts, err := tokens.Tokens(); err == nil && len(ts) == 0 {
+			logger.Warning("API authentication enabled but no API tokens exist, create one with the CLI")
+		}
 	}
 
 	if c.ReadTimeout == 0 {
@@ -133,7 +187,17 @@ func create(host string, c Config, gateway Gatewayer) (*Server, error) {
 		appLoc:               appLoc,
 		enableGUI:            c.EnableGUI,
 		enableJSON20RPC:      c.EnableJSON20RPC,
-		enableUnversionedAPI: c.EnableUnversionedAPI,
+		unversionedAPI:       newToggle(c.EnableUnversionedAPI),
+		configReloader:       c.ConfigReloader,
+		tokens:               tokens,
+		rateLimiter:          NewRateLimiter(c.RateLimit.Rate, c.RateLimit.Burst),
+		expensiveRateLimiter: NewRateLimiter(c.RateLimit.ExpensiveRate, c.RateLimit.ExpensiveBurst),
+		maxBlockRange:        c.MaxBlockRange,
+		maxRequestAddresses:  c.MaxRequestAddresses,
+	}
+
+	if mc.rateLimiter != nil || mc.expensiveRateLimiter != nil {
+		logger.Infof("API rate limit enabled: %+v", c.RateLimit)
 	}
 
 	srvMux := newServerMux(mc, gateway, csrfStore, rpc)
@@ -145,8 +209,12 @@ func create(host string, c Config, gateway Gatewayer) (*Server, error) {
 	}
 
 	return &Server{
-		server: srv,
-		done:   make(chan struct{}),
+		server:               srv,
+		done:                 make(chan struct{}),
+		csrfStore:            csrfStore,
+		unversionedAPI:       mc.unversionedAPI,
+		rateLimiter:          mc.rateLimiter,
+		expensiveRateLimiter: mc.expensiveRateLimiter,
 	}, nil
 }
 
@@ -165,7 +233,7 @@ func Create(host string, c Config, gateway Gatewayer) (*Server, error) {
 
 	s, err := create(host, c, gateway)
 	if err != nil {
-		s.listener.Close()
+		listener.Close()
 		return nil, err
 	}
 
@@ -197,7 +265,7 @@ func CreateHTTPS(host string, c Config, gateway Gatewayer, certFile, keyFile str
 
 	s, err := create(host, c, gateway)
 	if err != nil {
-		s.listener.Close()
+		listener.Close()
 		return nil, err
 	}
 
@@ -247,23 +315,56 @@ func newServerMux(c muxConfig, gateway Gatewayer, csrfStore *CSRFStore, rpc *web
 		return handler
 	}
 
-	webHandler := func(endpoint string, handler http.Handler) {
+	wrapHandler := func(endpoint string, handler http.Handler) http.Handler {
 		handler = wh.ElapsedHandler(logger, handler)
 		handler = CSRFCheck(csrfStore, handler)
 		handler = headerCheck(c.host, handler)
+		if strings.HasPrefix(endpoint, "/api/v2/") {
+			handler = JSONErrors(handler)
+		}
 		handler = gziphandler.GzipHandler(handler)
-		mux.Handle(endpoint, handler)
+		handler = wh.RequestIDHandler(handler)
+		return RouteMetrics(endpoint, handler)
+	}
+
+	webHandler := func(endpoint string, handler http.Handler) {
+		mux.Handle(endpoint, wrapHandler(endpoint, handler))
+	}
+
+	// API endpoints are rate limited per client and require an API token with the given scope,
+	// if API authentication is enabled
+	apiHandler := func(scope Scope, handler http.Handler) http.Handler {
+		handler = RateLimitCheck(c.rateLimiter, c.tokens != nil, handler)
+		return AuthCheck(c.tokens, scope, handler)
+	}
+
+	// expensive applies the separate rate limit for expensive endpoints
+	expensive := func(handler http.Handler) http.Handler {
+		return RateLimitCheck(c.expensiveRateLimiter, c.tokens != nil, handler)
 	}
 
-	webHandlerV1 := func(endpoint string, handler http.Handler) {
-		if c.enableUnversionedAPI {
-			webHandler(endpoint, handler)
+	// routes are the versioned API endpoints, which are documented in the OpenAPI spec
+	var routes []apiRoute
+
+	// v1 endpoints are also served under /api/v2 with JSON request bodies and responses, see mirrorV1
+	webHandlerV1 := func(scope Scope, endpoint string, handler http.Handler) {
+		if op, ok := findV1Operation("/api/v1" + endpoint); ok {
+			webHandler("/api/v2"+endpoint, apiHandler(scope, mirrorV1(op, handler)))
+			routes = append(routes, apiRoute{path: "/api/v2" + endpoint, scope: scope})
+		}
+
+		handler = apiHandler(scope, handler)
+		if c.unversionedAPI != nil {
+			// The unversioned endpoints can be disabled while the server runs
+			mux.Handle(endpoint, toggleCheck(c.unversionedAPI, wrapHandler(endpoint, handler)))
 		}
 		webHandler("/api/v1"+endpoint, handler)
+		routes = append(routes, apiRoute{path: "/api/v1" + endpoint, scope: scope})
 	}
 
-	webHandlerV2 := func(endpoint string, handler http.Handler) {
-		webHandler("/api/v2"+endpoint, handler)
+	webHandlerV2 := func(scope Scope, endpoint string, handler http.Handler) {
+		webHandler("/api/v2"+endpoint, apiHandler(scope, handler))
+		routes = append(routes, apiRoute{path: "/api/v2" + endpoint, scope: scope})
 	}
 
 	webHandler("/", newIndexHandler(c.appLoc, c.enableGUI))
@@ -280,20 +381,22 @@ func newServerMux(c muxConfig, gateway Gatewayer, csrfStore *CSRFStore, rpc *web
 	}
 
 	if c.enableJSON20RPC {
-		webHandlerV1("/webrpc", http.HandlerFunc(rpc.Handler))
+		// The scope required by each JSON-RPC method, and the rate limits of the requests of a batch,
+		// are checked by webrpcCheck
+		webHandlerV1(ScopeRead, "/webrpc", expensive(rpc.CheckedHandler(webrpcCheck(c.tokens, c.rateLimiter, c.expensiveRateLimiter))))
 	}
 
 	// get the current CSRF token
 	mux.Handle("/csrf", headerCheck(c.host, getCSRFToken(csrfStore)))
 	mux.Handle("/api/v1/csrf", headerCheck(c.host, getCSRFToken(csrfStore)))
 
-	webHandlerV1("/version", versionHandler(gateway))
+	webHandlerV1(ScopeRead, "/version", versionHandler(gateway))
 
 	// get set of unspent outputs
-	webHandlerV1("/outputs", getOutputsHandler(gateway))
+	webHandlerV1(ScopeRead, "/outputs", expensive(getOutputsHandler(gateway, c.maxRequestAddresses)))
 
 	// get balance of addresses
-	webHandlerV1("/balance", getBalanceHandler(gateway))
+	webHandlerV1(ScopeRead, "/balance", getBalanceHandler(gateway, c.maxRequestAddresses))
 
 	// Wallet interface
 
@@ -301,7 +404,7 @@ func newServerMux(c muxConfig, gateway Gatewayer, csrfStore *CSRFStore, rpc *web
 	// Method: GET
 	// Args:
 	//      id - Wallet ID [required]
-	webHandlerV1("/wallet", walletGet(gateway))
+	webHandlerV1(ScopeWalletRead, "/wallet", walletGet(gateway))
 
 	// Loads wallet from seed, will scan ahead N address and
 	// load addresses till the last one that have coins.
@@ -310,16 +413,16 @@ func newServerMux(c muxConfig, gateway Gatewayer, csrfStore *CSRFStore, rpc *web
 	//     seed: wallet seed [required]
 	//     label: wallet label [required]
 	//     scan: the number of addresses to scan ahead for balances [optional, must be > 0]
-	webHandlerV1("/wallet/create", walletCreate(gateway))
+	webHandlerV1(ScopeWalletSpend, "/wallet/create", walletCreate(gateway))
 
-	webHandlerV1("/wallet/newAddress", walletNewAddresses(gateway))
+	webHandlerV1(ScopeWalletSpend, "/wallet/newAddress", walletNewAddresses(gateway))
 
 	// Returns the confirmed and predicted balance for a specific wallet.
 	// The predicted balance is the confirmed balance minus any pending
 	// spent amount.
 	// GET arguments:
 	//      id: Wallet ID
-	webHandlerV1("/wallet/balance", walletBalanceHandler(gateway))
+	webHandlerV1(ScopeWalletRead, "/wallet/balance", walletBalanceHandler(gateway))
 
 	// Sends coins&hours to another address.
 	// POST arguments:
@@ -328,121 +431,228 @@ func newServerMux(c muxConfig, gateway Gatewayer, csrfStore *CSRFStore, rpc *web
 	//  dst: Destination address
 	//  Returns total amount spent if successful, otherwise error describing
 	//  failure status.
-	webHandlerV1("/wallet/spend", walletSpendHandler(gateway))
+	webHandlerV1(ScopeWalletSpend, "/wallet/spend", walletSpendHandler(gateway))
 
 	// Creates a transaction from a wallet
-	webHandlerV1("/wallet/transaction", createTransactionHandler(gateway))
+	webHandlerV1(ScopeWalletSpend, "/wallet/transaction", createTransactionHandler(gateway))
 
 	// GET Arguments:
 	//      id: Wallet ID
 	// Returns all pending transanction for all addresses by selected Wallet
-	webHandlerV1("/wallet/transactions", walletTransactionsHandler(gateway))
+	webHandlerV1(ScopeWalletRead, "/wallet/transactions", walletTransactionsHandler(gateway))
+
+	// GET Arguments:
+	//      id: Wallet ID
+	//      start_time: unix timestamp, the earliest time of the transactions [optional]
+	//      end_time: unix timestamp, the latest time of the transactions [optional]
+	//      format: "json" or "csv" [optional, default "json"]
+	// Returns the incoming, outgoing and self transfer transactions of a wallet, with their notes
+	webHandlerV1(ScopeWalletRead, "/wallet/ledger", expensive(walletLedgerHandler(gateway)))
 
 	// Update wallet label
 	// POST Arguments:
 	//     id: wallet id
 	//     label: wallet label
-	webHandlerV1("/wallet/update", walletUpdateHandler(gateway))
+	webHandlerV1(ScopeWalletSpend, "/wallet/update", walletUpdateHandler(gateway))
 
 	// Returns all loaded wallets
 	// returns sensitive information
-	webHandlerV1("/wallets", walletsHandler(gateway))
+	webHandlerV1(ScopeWalletRead, "/wallets", walletsHandler(gateway))
 
 	// Returns wallets directory path
-	webHandlerV1("/wallets/folderName", getWalletFolder(gateway))
+	webHandlerV1(ScopeWalletRead, "/wallets/folderName", getWalletFolder(gateway))
 
 	// Generate wallet seed
 	// GET Arguments:
 	//     entropy: entropy bitsize.
-	webHandlerV1("/wallet/newSeed", newWalletSeed(gateway))
+	webHandlerV1(ScopeWalletRead, "/wallet/newSeed", newWalletSeed(gateway))
 
 	// Gets seed of wallet of given id
 	// GET Arguments:
 	//     id: wallet id
 	//     password: wallet password
-	webHandlerV1("/wallet/seed", walletSeedHandler(gateway))
+	webHandlerV1(ScopeWalletSpend, "/wallet/seed", walletSeedHandler(gateway))
+
+	// Exports wallet to a password encrypted bundle
+	// Method: POST
+	// Args:
+	//     id: wallet id
+	//     password: bundle password
+	//     watch_only: leave out the seeds and secret keys [optional]
+	webHandlerV1(ScopeWalletSpend, "/wallet/export", walletExportHandler(gateway))
+
+	// Imports wallet from a password encrypted bundle
+	// Method: POST
+	// Args:
+	//     bundle: JSON of the bundle
+	//     password: bundle password
+	webHandlerV1(ScopeWalletSpend, "/wallet/import", walletImportHandler(gateway))
 
 	// unload wallet
 	// POST Argument:
 	//         id: wallet id
-	webHandlerV1("/wallet/unload", walletUnloadHandler(gateway))
+	webHandlerV1(ScopeWalletSpend, "/wallet/unload", walletUnloadHandler(gateway))
 
 	// Encrypts wallet
 	// POST arguments:
 	//     id: wallet id
 	//     password: wallet password
 	// Returns an encrypted wallet json without sensitive data
-	webHandlerV1("/wallet/encrypt", walletEncryptHandler(gateway))
+	webHandlerV1(ScopeWalletSpend, "/wallet/encrypt", walletEncryptHandler(gateway))
 
 	// Decrypts wallet
 	// POST arguments:
 	//     id: wallet id
 	//     password: wallet password
-	webHandlerV1("/wallet/decrypt", walletDecryptHandler(gateway))
+	webHandlerV1(ScopeWalletSpend, "/wallet/decrypt", walletDecryptHandler(gateway))
+
+	// Returns the transaction notes, address labels and contacts of a wallet
+	// POST arguments:
+	//     id: wallet id
+	//     password: wallet password, required if the wallet is encrypted
+	webHandlerV1(ScopeWalletRead, "/wallet/metadata", walletMetadataHandler(gateway))
+
+	// Sets the note of a transaction in a wallet
+	// POST arguments:
+	//     id: wallet id
+	//     txid: transaction id
+	//     note: the note, empty to delete
+	//     password: wallet password [optional]
+	webHandlerV1(ScopeWalletSpend, "/wallet/setNote", walletSetNoteHandler(gateway))
+
+	// Sets the label of an address in a wallet
+	// POST arguments:
+	//     id: wallet id
+	//     address: address of the wallet
+	//     label: the label, empty to delete
+	//     password: wallet password [optional]
+	webHandlerV1(ScopeWalletSpend, "/wallet/setLabel", walletSetLabelHandler(gateway))
+
+	// Adds or changes a contact in the address book of a wallet
+	// POST arguments:
+	//     id: wallet id
+	//     name: contact name
+	//     address: contact address
+	//     password: wallet password [optional]
+	webHandlerV1(ScopeWalletSpend, "/wallet/setContact", walletSetContactHandler(gateway))
+
+	// Removes a contact from the address book of a wallet
+	// POST arguments:
+	//     id: wallet id
+	//     name: contact name
+	//     password: wallet password [optional]
+	webHandlerV1(ScopeWalletSpend, "/wallet/deleteContact", walletDeleteContactHandler(gateway))
+
+	// Changes the password and/or crypto type of an encrypted wallet
+	// POST arguments:
+	//     id: wallet id
+	//     old_password: current wallet password
+	//     new_password: new wallet password [optional]
+	//     crypto_type: new crypto type [optional]
+	//     scrypt_n, scrypt_r, scrypt_p: scrypt parameters [optional]
+	webHandlerV1(ScopeWalletSpend, "/wallet/changePassword", walletChangePasswordHandler(gateway))
 
 	// Blockchain interface
 
-	webHandlerV1("/blockchain/metadata", blockchainHandler(gateway))
-	webHandlerV1("/blockchain/progress", blockchainProgressHandler(gateway))
+	webHandlerV1(ScopeRead, "/blockchain/metadata", blockchainHandler(gateway))
+	webHandlerV1(ScopeRead, "/blockchain/progress", blockchainProgressHandler(gateway))
+
+	// get the key rotations of the blockchain signing keys
+	webHandlerV1(ScopeRead, "/blockchain/keyRotations", keyRotationsHandler(gateway))
+	// add a key rotation of the blockchain signing keys and broadcast it
+	webHandlerV1(ScopeAdmin, "/blockchain/injectKeyRotation", injectKeyRotationHandler(gateway))
 
 	// get block by hash or seq
-	webHandlerV1("/block", getBlock(gateway))
+	webHandlerV1(ScopeRead, "/block", getBlock(gateway))
 	// get blocks in specific range
-	webHandlerV1("/blocks", getBlocks(gateway))
+	webHandlerV1(ScopeRead, "/blocks", expensive(getBlocks(gateway, c.maxBlockRange)))
 	// get last N blocks
-	webHandlerV1("/last_blocks", getLastBlocks(gateway))
+	webHandlerV1(ScopeRead, "/last_blocks", expensive(getLastBlocks(gateway, c.maxBlockRange)))
 
 	// Network stats interface
-	webHandlerV1("/network/connection", connectionHandler(gateway))
-	webHandlerV1("/network/connections", connectionsHandler(gateway))
-	webHandlerV1("/network/defaultConnections", defaultConnectionsHandler(gateway))
-	webHandlerV1("/network/connections/trust", trustConnectionsHandler(gateway))
-	webHandlerV1("/network/connections/exchange", exchgConnectionsHandler(gateway))
+	webHandlerV1(ScopeAdmin, "/network/connection", connectionHandler(gateway))
+	webHandlerV1(ScopeAdmin, "/network/connections", connectionsHandler(gateway))
+	webHandlerV1(ScopeAdmin, "/network/defaultConnections", defaultConnectionsHandler(gateway))
+	webHandlerV1(ScopeAdmin, "/network/connections/trust", trustConnectionsHandler(gateway))
+	webHandlerV1(ScopeAdmin, "/network/connections/exchange", exchgConnectionsHandler(gateway))
 
 	// Transaction handler
 
 	// get set of pending transactions
-	webHandlerV1("/pendingTxs", getPendingTxns(gateway))
+	webHandlerV1(ScopeRead, "/pendingTxs", getPendingTxns(gateway))
+	webHandlerV1(ScopeRead, "/pendingTxs/stats", getPendingTxnsStats(gateway))
+	webHandlerV1(ScopeRead, "/pendingTxs/expired", getExpiredPendingTxns(gateway))
+	webHandlerV1(ScopeAdmin, "/pendingTxs/purge", purgeExpiredPendingTxns(gateway))
 	// get txn by txid
-	webHandlerV1("/transaction", getTransactionByID(gateway))
+	webHandlerV1(ScopeRead, "/transaction", getTransactionByID(gateway))
 
 	// parse and verify transaction
-	webHandlerV2("/transaction/verify", verifyTxnHandler(gateway))
+	webHandlerV2(ScopeRead, "/transaction/verify", verifyTxnHandler(gateway))
+	webHandlerV2(ScopeWalletRead, "/transaction/estimate", estimateTransactionHandler(gateway))
 
 	// Health check handler
-	webHandlerV1("/health", healthCheck(gateway))
+	webHandlerV1(ScopeRead, "/health", healthCheck(gateway))
 
 	// Returns transactions that match the filters.
 	// Method: GET
 	// Args:
 	//     addrs: Comma seperated addresses [optional, returns all transactions if no address is provided]
 	//     confirmed: Whether the transactions should be confirmed [optional, must be 0 or 1; if not provided, returns all]
-	webHandlerV1("/transactions", getTransactions(gateway))
+	webHandlerV1(ScopeRead, "/transactions", expensive(getTransactions(gateway, c.maxRequestAddresses)))
+	// Returns the incoming, outgoing and self transfer transactions of a set of addresses.
+	// Method: GET
+	// Args:
+	//     addrs: Comma seperated addresses [required]
+	//     start_time: unix timestamp, the earliest time of the transactions [optional]
+	//     end_time: unix timestamp, the latest time of the transactions [optional]
+	//     format: "json" or "csv" [optional, default "json"]
+	webHandlerV1(ScopeRead, "/ledger", expensive(getLedgerHandler(gateway, c.maxRequestAddresses)))
 	// inject a transaction into network
-	webHandlerV1("/injectTransaction", injectTransaction(gateway))
-	webHandlerV1("/resendUnconfirmedTxns", resendUnconfirmedTxns(gateway))
+	webHandlerV1(ScopeAdmin, "/injectTransaction", injectTransaction(gateway))
+	webHandlerV1(ScopeAdmin, "/resendUnconfirmedTxns", resendUnconfirmedTxns(gateway))
 	// get raw tx by txid.
-	webHandlerV1("/rawtx", getRawTxn(gateway))
+	webHandlerV1(ScopeRead, "/rawtx", getRawTxn(gateway))
 
 	// UxOut api handler
 
 	// get uxout by id.
-	webHandlerV1("/uxout", getUxOutByID(gateway))
+	webHandlerV1(ScopeRead, "/uxout", getUxOutByID(gateway))
 	// get all the address affected uxouts.
-	webHandlerV1("/address_uxouts", getAddrUxOuts(gateway))
+	webHandlerV1(ScopeRead, "/address_uxouts", expensive(getAddrUxOuts(gateway)))
 
-	webHandlerV2("/address/verify", http.HandlerFunc(addressVerify))
+	webHandlerV2(ScopeRead, "/address/verify", http.HandlerFunc(addressVerify))
 
 	// Explorer handler
 
 	// get set of pending transactions
-	webHandlerV1("/explorer/address", getTransactionsForAddress(gateway))
+	webHandlerV1(ScopeRead, "/explorer/address", expensive(getTransactionsForAddress(gateway)))
+
+	webHandlerV1(ScopeRead, "/coinSupply", expensive(getCoinSupply(gateway)))
+
+	// get daily coin supply snapshots
+	webHandlerV1(ScopeRead, "/explorer/supplyHistory", expensive(getSupplyHistory(gateway)))
 
-	webHandlerV1("/coinSupply", getCoinSupply(gateway))
+	// get the share of coins held by the top N addresses
+	webHandlerV1(ScopeRead, "/explorer/distribution", expensive(getDistribution(gateway)))
 
-	webHandlerV1("/richlist", getRichlist(gateway))
+	webHandlerV1(ScopeRead, "/richlist", expensive(getRichlist(gateway)))
 
-	webHandlerV1("/addresscount", getAddressCount(gateway))
+	webHandlerV1(ScopeRead, "/addresscount", getAddressCount(gateway))
+
+	// Returns the rate limits and the number of throttled requests
+	webHandlerV1(ScopeAdmin, "/ratelimit", rateLimitHandler(c.rateLimiter, c.expensiveRateLimiter))
+
+	// Reloads the node's config and applies the options that can be changed while the node runs
+	webHandlerV1(ScopeAdmin, "/reloadConfig", reloadConfigHandler(c.configReloader))
+
+	// Node metrics in the Prometheus text exposition format
+	webHandler("/metrics", apiHandler(ScopeAdmin, metricsHandler(gateway, c.rateLimiter, c.expensiveRateLimiter)))
+
+	// OpenAPI specification of the API. It is registered last and built after its own route is registered,
+	// so that it documents all of the routes
+	spec := &OpenAPISpec{}
+	webHandlerV1(ScopeRead, "/openapi.json", openAPIHandler(gateway, spec))
+	*spec = *newOpenAPISpec(routes)
 
 	return mux
 }
@@ -487,6 +697,16 @@ func splitCommaString(s string) []string {
 	return dedupWords
 }
 
+// tooManyAddresses writes a 400 error and returns true if n is greater than maxAddresses.
+// If maxAddresses is 0, the number of addresses is not limited
+func tooManyAddresses(w http.ResponseWriter, n, maxAddresses int) bool {
+	if maxAddresses > 0 && n > maxAddresses {
+		wh.Error400(w, fmt.Sprintf("too many addresses, at most %d addresses can be requested", maxAddresses))
+		return true
+	}
+	return false
+}
+
 // getOutputsHandler returns UxOuts filtered by a set of addresses or a set of hashes
 // URI: /api/v1/outputs
 // Method: GET
@@ -496,8 +716,9 @@ func splitCommaString(s string) []string {
 // If neither addrs nor hashes are specificed, return all unspent outputs.
 // If only one filter is specified, then return outputs match the filter.
 // Both filters cannot be specified.
-func getOutputsHandler(gateway Gatewayer) http.HandlerFunc {
+func getOutputsHandler(gateway Gatewayer, maxAddresses int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -519,6 +740,10 @@ func getOutputsHandler(gateway Gatewayer) http.HandlerFunc {
 		if addrStr != "" {
 			addrs = splitCommaString(addrStr)
 
+			if tooManyAddresses(w, len(addrs), maxAddresses) {
+				return
+			}
+
 			for _, a := range addrs {
 				if _, err := cipher.DecodeBase58Address(a); err != nil {
 					wh.Error400(w, "addrs contains invalid address")
@@ -551,6 +776,7 @@ func getOutputsHandler(gateway Gatewayer) http.HandlerFunc {
 
 func versionHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -18,6 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	wh "github.com/skycoin/skycoin/src/util/http"
 	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/wallet"
 )
@@ -379,3 +383,57 @@ func TestEnableGUI(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateInvalidTokensFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apitokens")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tokensFile := filepath.Join(dir, APITokensFilename)
+	require.NoError(t, ioutil.WriteFile(tokensFile, []byte("{"), 0600))
+
+	c := Config{
+		DisableCSRF: true,
+		EnableAuth:  true,
+		TokensFile:  tokensFile,
+	}
+
+	host := "127.0.0.1:6424"
+	s, err := Create(host, c, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "load API tokens file")
+	require.Nil(t, s)
+
+	// The listener is closed
+	listener, err := net.Listen("tcp", host)
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+}
+
+func TestRequestID(t *testing.T) {
+	gateway := &GatewayerMock{}
+	gateway.On("GetBuildInfo").Return(visor.BuildInfo{Version: "0.24.0"})
+
+	handler := newServerMux(muxConfig{
+		host:   configuredHost,
+		appLoc: ".",
+	}, gateway, &CSRFStore{}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/version", nil)
+	require.NoError(t, err)
+	req.Header.Set(wh.RequestIDHeader, "abc")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "abc", rr.Header().Get(wh.RequestIDHeader))
+
+	// A request ID is generated if the request has none
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/version", nil)
+	require.NoError(t, err)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NotEmpty(t, rr.Header().Get(wh.RequestIDHeader))
+}
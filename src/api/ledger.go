@@ -0,0 +1,259 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	wh "github.com/skycoin/skycoin/src/util/http"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// Formats of a ledger
+const (
+	ledgerFormatJSON = "json"
+	ledgerFormatCSV  = "csv"
+)
+
+// LedgerColumns are the columns of a ledger in CSV format
+var LedgerColumns = []string{
+	"txid",
+	"type",
+	"timestamp",
+	"confirmed",
+	"confirmations",
+	"block_seq",
+	"net_coins",
+	"net_hours",
+	"hours_burned",
+	"counterparties",
+	"note",
+}
+
+// LedgerResponse is returned by /api/v1/wallet/ledger and /api/v1/ledger
+type LedgerResponse struct {
+	Entries []visor.ReadableLedgerEntry `json:"entries"`
+}
+
+// LedgerRow returns the CSV row of a ledger entry. The timestamp is formatted as RFC3339 in UTC,
+// and the counterparties are separated by a semicolon.
+func LedgerRow(e visor.ReadableLedgerEntry) []string {
+	return []string{
+		e.Txid,
+		string(e.Type),
+		time.Unix(int64(e.Timestamp), 0).UTC().Format(time.RFC3339),
+		strconv.FormatBool(e.Confirmed),
+		strconv.FormatUint(e.Confirmations, 10),
+		strconv.FormatUint(e.BlockSeq, 10),
+		e.NetCoins,
+		strconv.FormatInt(e.NetHours, 10),
+		strconv.FormatUint(e.HoursBurned, 10),
+		strings.Join(e.Counterparties, ";"),
+		e.Note,
+	}
+}
+
+// Returns the ledger of a wallet: its incoming, outgoing and self transfer transactions,
+// confirmed and unconfirmed, with the notes of the wallet if it is not encrypted
+// URI: /api/v1/wallet/ledger
+// Method: GET
+// Args:
+//     id: wallet id [required]
+//     start_time: unix timestamp, the earliest time of the transactions [optional]
+//     end_time: unix timestamp, the latest time of the transactions [optional]
+//     format: "json" or "csv" [optional, default "json"]
+func walletLedgerHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		wltID := r.FormValue("id")
+		if wltID == "" {
+			wh.Error400(w, "missing wallet id")
+			return
+		}
+
+		format, err := parseLedgerFormat(r)
+		if err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		start, end, err := parseTimeRange(r)
+		if err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		entries, err := gateway.GetWalletLedger(wltID, start, end)
+		if err != nil {
+			logger.Errorf("get wallet ledger failed: %v", err)
+			setErrorCode(r, err)
+			switch err {
+			case wallet.ErrWalletNotExist:
+				wh.Error404(w, "")
+			case wallet.ErrWalletAPIDisabled:
+				wh.Error403(w, "")
+			default:
+				wh.Error500(w, err.Error())
+			}
+			return
+		}
+
+		rEntries, err := visor.NewReadableLedger(entries)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		metadata, err := gateway.GetWalletMetadata(wltID, nil)
+		switch err {
+		case nil:
+			for i, e := range rEntries {
+				rEntries[i].Note = metadata.Notes[e.Txid]
+			}
+		case wallet.ErrMissingPassword:
+			// The wallet is encrypted
+		default:
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		writeLedger(w, format, strings.TrimSuffix(wltID, "."+wallet.WalletExt)+"_ledger", rEntries)
+	}
+}
+
+// Returns the ledger of a set of addresses: their incoming, outgoing and self transfer transactions,
+// confirmed and unconfirmed
+// URI: /api/v1/ledger
+// Method: GET
+// Args:
+//     addrs: comma separated addresses [required]
+//     start_time: unix timestamp, the earliest time of the transactions [optional]
+//     end_time: unix timestamp, the latest time of the transactions [optional]
+//     format: "json" or "csv" [optional, default "json"]
+func getLedgerHandler(gateway Gatewayer, maxAddresses int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		addrs, err := parseAddressesFromStr(r.FormValue("addrs"))
+		if err != nil {
+			wh.Error400(w, fmt.Sprintf("parse parameter: 'addrs' failed: %v", err))
+			return
+		}
+
+		if len(addrs) == 0 {
+			wh.Error400(w, "missing addrs")
+			return
+		}
+
+		if tooManyAddresses(w, len(addrs), maxAddresses) {
+			return
+		}
+
+		format, err := parseLedgerFormat(r)
+		if err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		start, end, err := parseTimeRange(r)
+		if err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		entries, err := gateway.GetLedger(addrs, start, end)
+		if err != nil {
+			err = fmt.Errorf("gateway.GetLedger failed: %v", err)
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		rEntries, err := visor.NewReadableLedger(entries)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		writeLedger(w, format, "ledger", rEntries)
+	}
+}
+
+// parseLedgerFormat parses the format parameter of a ledger request
+func parseLedgerFormat(r *http.Request) (string, error) {
+	switch f := r.FormValue("format"); f {
+	case "", ledgerFormatJSON:
+		return ledgerFormatJSON, nil
+	case ledgerFormatCSV:
+		return ledgerFormatCSV, nil
+	default:
+		return "", fmt.Errorf("invalid 'format' value %q, must be %q or %q", f, ledgerFormatJSON, ledgerFormatCSV)
+	}
+}
+
+// parseTimeRange parses the start_time and end_time parameters. An end_time of 0 means there is no upper bound.
+func parseTimeRange(r *http.Request) (uint64, uint64, error) {
+	var start, end uint64
+	var err error
+	if s := r.FormValue("start_time"); s != "" {
+		start, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid 'start_time' value: %v", err)
+		}
+	}
+
+	if s := r.FormValue("end_time"); s != "" {
+		end, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid 'end_time' value: %v", err)
+		}
+
+		if end < start {
+			return 0, 0, errors.New("'end_time' must be >= 'start_time'")
+		}
+	}
+
+	return start, end, nil
+}
+
+// writeLedger writes the ledger entries as a LedgerResponse, or as a CSV file attachment named after name
+func writeLedger(w http.ResponseWriter, format, name string, entries []visor.ReadableLedgerEntry) {
+	if format != ledgerFormatCSV {
+		wh.SendJSONOr500(logger, w, LedgerResponse{
+			Entries: entries,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".csv"))
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(LedgerColumns); err != nil {
+		logger.Errorf("write ledger CSV failed: %v", err)
+		return
+	}
+	for _, e := range entries {
+		if err := cw.Write(LedgerRow(e)); err != nil {
+			logger.Errorf("write ledger CSV failed: %v", err)
+			return
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		logger.Errorf("write ledger CSV failed: %v", err)
+	}
+}
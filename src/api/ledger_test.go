@@ -0,0 +1,308 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestLedgerHandlers(t *testing.T) {
+	addr := testutil.MakeAddress()
+	counterparty := testutil.MakeAddress()
+	txid := testutil.RandSHA256(t)
+
+	entries := []visor.LedgerEntry{
+		{
+			Txid:           txid,
+			Type:           visor.LedgerOutgoing,
+			Status:         visor.NewConfirmedTransactionStatus(2, 7),
+			Time:           1514764800,
+			CoinsSpent:     3e6,
+			HoursSpent:     20,
+			CoinsReceived:  5e5,
+			HoursReceived:  5,
+			HoursBurned:    10,
+			Counterparties: []cipher.Address{counterparty},
+		},
+	}
+
+	metadata := wallet.NewMetadata()
+	metadata.SetNote(txid, "rent, january")
+
+	tt := []struct {
+		name           string
+		endpoint       string
+		method         string
+		query          map[string]string
+		gatewayMethod  string
+		gatewayArgs    []interface{}
+		gatewayErr     error
+		metadataErr    error
+		maxAddresses   int
+		status         int
+		err            string
+		note           string
+		csv            string
+		csvDisposition string
+	}{
+		{
+			name:     "wallet ledger 405",
+			endpoint: "/api/v1/wallet/ledger",
+			method:   http.MethodPost,
+			status:   http.StatusMethodNotAllowed,
+			err:      "405 Method Not Allowed",
+		},
+		{
+			name:     "wallet ledger 400 - missing wallet id",
+			endpoint: "/api/v1/wallet/ledger",
+			method:   http.MethodGet,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing wallet id",
+		},
+		{
+			name:     "wallet ledger 400 - invalid format",
+			endpoint: "/api/v1/wallet/ledger",
+			method:   http.MethodGet,
+			query:    map[string]string{"id": "foo.wlt", "format": "xml"},
+			status:   http.StatusBadRequest,
+			err:      `400 Bad Request - invalid 'format' value "xml", must be "json" or "csv"`,
+		},
+		{
+			name:     "wallet ledger 400 - invalid start_time",
+			endpoint: "/api/v1/wallet/ledger",
+			method:   http.MethodGet,
+			query:    map[string]string{"id": "foo.wlt", "start_time": "yesterday"},
+			status:   http.StatusBadRequest,
+			err:      `400 Bad Request - invalid 'start_time' value: strconv.ParseUint: parsing "yesterday": invalid syntax`,
+		},
+		{
+			name:     "wallet ledger 400 - end_time before start_time",
+			endpoint: "/api/v1/wallet/ledger",
+			method:   http.MethodGet,
+			query:    map[string]string{"id": "foo.wlt", "start_time": "20", "end_time": "10"},
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - 'end_time' must be >= 'start_time'",
+		},
+		{
+			name:          "wallet ledger 403 - wallet API disabled",
+			endpoint:      "/api/v1/wallet/ledger",
+			method:        http.MethodGet,
+			query:         map[string]string{"id": "foo.wlt"},
+			gatewayMethod: "GetWalletLedger",
+			gatewayArgs:   []interface{}{"foo.wlt", uint64(0), uint64(0)},
+			gatewayErr:    wallet.ErrWalletAPIDisabled,
+			status:        http.StatusForbidden,
+			err:           "403 Forbidden",
+		},
+		{
+			name:          "wallet ledger 404 - wallet doesn't exist",
+			endpoint:      "/api/v1/wallet/ledger",
+			method:        http.MethodGet,
+			query:         map[string]string{"id": "foo.wlt"},
+			gatewayMethod: "GetWalletLedger",
+			gatewayArgs:   []interface{}{"foo.wlt", uint64(0), uint64(0)},
+			gatewayErr:    wallet.ErrWalletNotExist,
+			status:        http.StatusNotFound,
+			err:           "404 Not Found",
+		},
+		{
+			name:          "wallet ledger 500 - gateway error",
+			endpoint:      "/api/v1/wallet/ledger",
+			method:        http.MethodGet,
+			query:         map[string]string{"id": "foo.wlt"},
+			gatewayMethod: "GetWalletLedger",
+			gatewayArgs:   []interface{}{"foo.wlt", uint64(0), uint64(0)},
+			gatewayErr:    errors.New("gateway error"),
+			status:        http.StatusInternalServerError,
+			err:           "500 Internal Server Error - gateway error",
+		},
+		{
+			name:          "wallet ledger 500 - metadata error",
+			endpoint:      "/api/v1/wallet/ledger",
+			method:        http.MethodGet,
+			query:         map[string]string{"id": "foo.wlt"},
+			gatewayMethod: "GetWalletLedger",
+			gatewayArgs:   []interface{}{"foo.wlt", uint64(0), uint64(0)},
+			metadataErr:   errors.New("metadata error"),
+			status:        http.StatusInternalServerError,
+			err:           "500 Internal Server Error - metadata error",
+		},
+		{
+			name:          "wallet ledger 200",
+			endpoint:      "/api/v1/wallet/ledger",
+			method:        http.MethodGet,
+			query:         map[string]string{"id": "foo.wlt", "start_time": "10", "end_time": "20"},
+			gatewayMethod: "GetWalletLedger",
+			gatewayArgs:   []interface{}{"foo.wlt", uint64(10), uint64(20)},
+			status:        http.StatusOK,
+			note:          "rent, january",
+		},
+		{
+			name:          "wallet ledger 200 - encrypted wallet",
+			endpoint:      "/api/v1/wallet/ledger",
+			method:        http.MethodGet,
+			query:         map[string]string{"id": "foo.wlt"},
+			gatewayMethod: "GetWalletLedger",
+			gatewayArgs:   []interface{}{"foo.wlt", uint64(0), uint64(0)},
+			metadataErr:   wallet.ErrMissingPassword,
+			status:        http.StatusOK,
+		},
+		{
+			name:          "wallet ledger 200 - csv",
+			endpoint:      "/api/v1/wallet/ledger",
+			method:        http.MethodGet,
+			query:         map[string]string{"id": "foo.wlt", "format": "csv"},
+			gatewayMethod: "GetWalletLedger",
+			gatewayArgs:   []interface{}{"foo.wlt", uint64(0), uint64(0)},
+			status:        http.StatusOK,
+			csv: "txid,type,timestamp,confirmed,confirmations,block_seq,net_coins,net_hours,hours_burned,counterparties,note\n" +
+				txid.Hex() + ",outgoing,2018-01-01T00:00:00Z,true,2,7,-2.500000,-15,10," + counterparty.String() + ",\"rent, january\"\n",
+			csvDisposition: `attachment; filename="foo_ledger.csv"`,
+		},
+		{
+			name:     "ledger 405",
+			endpoint: "/api/v1/ledger",
+			method:   http.MethodPost,
+			status:   http.StatusMethodNotAllowed,
+			err:      "405 Method Not Allowed",
+		},
+		{
+			name:     "ledger 400 - missing addrs",
+			endpoint: "/api/v1/ledger",
+			method:   http.MethodGet,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing addrs",
+		},
+		{
+			name:     "ledger 400 - invalid addrs",
+			endpoint: "/api/v1/ledger",
+			method:   http.MethodGet,
+			query:    map[string]string{"addrs": "foo"},
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - parse parameter: 'addrs' failed: Invalid address length",
+		},
+		{
+			name:         "ledger 400 - too many addresses",
+			endpoint:     "/api/v1/ledger",
+			method:       http.MethodGet,
+			query:        map[string]string{"addrs": addr.String() + "," + counterparty.String()},
+			maxAddresses: 1,
+			status:       http.StatusBadRequest,
+			err:          "400 Bad Request - too many addresses, at most 1 addresses can be requested",
+		},
+		{
+			name:          "ledger 500 - gateway error",
+			endpoint:      "/api/v1/ledger",
+			method:        http.MethodGet,
+			query:         map[string]string{"addrs": addr.String()},
+			gatewayMethod: "GetLedger",
+			gatewayArgs:   []interface{}{[]cipher.Address{addr}, uint64(0), uint64(0)},
+			gatewayErr:    errors.New("gateway error"),
+			status:        http.StatusInternalServerError,
+			err:           "500 Internal Server Error - gateway.GetLedger failed: gateway error",
+		},
+		{
+			name:          "ledger 200",
+			endpoint:      "/api/v1/ledger",
+			method:        http.MethodGet,
+			query:         map[string]string{"addrs": addr.String(), "start_time": "10"},
+			gatewayMethod: "GetLedger",
+			gatewayArgs:   []interface{}{[]cipher.Address{addr}, uint64(10), uint64(0)},
+			status:        http.StatusOK,
+		},
+		{
+			name:          "ledger 200 - csv",
+			endpoint:      "/api/v1/ledger",
+			method:        http.MethodGet,
+			query:         map[string]string{"addrs": addr.String(), "format": "csv"},
+			gatewayMethod: "GetLedger",
+			gatewayArgs:   []interface{}{[]cipher.Address{addr}, uint64(0), uint64(0)},
+			status:        http.StatusOK,
+			csv: "txid,type,timestamp,confirmed,confirmations,block_seq,net_coins,net_hours,hours_burned,counterparties,note\n" +
+				txid.Hex() + ",outgoing,2018-01-01T00:00:00Z,true,2,7,-2.500000,-15,10," + counterparty.String() + ",\n",
+			csvDisposition: `attachment; filename="ledger.csv"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &GatewayerMock{}
+			if tc.gatewayMethod != "" {
+				var result []visor.LedgerEntry
+				if tc.gatewayErr == nil {
+					result = entries
+				}
+				gateway.On(tc.gatewayMethod, tc.gatewayArgs...).Return(result, tc.gatewayErr)
+			}
+
+			var m *wallet.Metadata
+			if tc.metadataErr == nil {
+				m = metadata
+			}
+			gateway.On("GetWalletMetadata", "foo.wlt", []byte(nil)).Return(m, tc.metadataErr)
+
+			v := url.Values{}
+			for k, x := range tc.query {
+				v.Add(k, x)
+			}
+
+			endpoint := tc.endpoint
+			if len(v) > 0 {
+				endpoint += "?" + v.Encode()
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+
+			cfg := mxConfig
+			cfg.maxRequestAddresses = tc.maxAddresses
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(cfg, gateway, csrfStore, nil)
+
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "wrong status code: got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			if tc.csv != "" {
+				require.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+				require.Equal(t, tc.csvDisposition, rr.Header().Get("Content-Disposition"))
+				require.Equal(t, tc.csv, rr.Body.String())
+				return
+			}
+
+			var resp LedgerResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &resp)
+			require.NoError(t, err)
+
+			expect, err := visor.NewReadableLedger(entries)
+			require.NoError(t, err)
+			expect[0].Note = tc.note
+			require.Equal(t, LedgerResponse{
+				Entries: expect,
+			}, resp)
+		})
+	}
+}
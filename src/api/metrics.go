@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	wh "github.com/skycoin/skycoin/src/util/http" //http,json helpers
+	"github.com/skycoin/skycoin/src/util/metrics"
+)
+
+var (
+	httpRequests = metrics.NewCounterVec("skycoin_http_requests_total",
+		"Number of HTTP requests, by route and status code", "route", "code")
+	httpRequestDuration = metrics.NewHistogramVec("skycoin_http_request_duration_seconds",
+		"Time spent handling HTTP requests, by route", metrics.DefaultDurationBuckets, "route")
+
+	unconfirmedTxns = metrics.NewGauge("skycoin_unconfirmed_txns",
+		"Number of transactions in the unconfirmed pool")
+	unconfirmedSize = metrics.NewGauge("skycoin_unconfirmed_size_bytes",
+		"Total size of the transactions in the unconfirmed pool")
+	unconfirmedEvicted = metrics.NewGauge("skycoin_unconfirmed_evicted",
+		"Number of transactions evicted from the unconfirmed pool because it was full, since the node started")
+	unconfirmedReplaced = metrics.NewGauge("skycoin_unconfirmed_replaced",
+		"Number of unconfirmed transactions replaced by a transaction with a higher fee, since the node started")
+	peers = metrics.NewGaugeVec("skycoin_peers",
+		"Number of connected peers, by direction (incoming or outgoing)", "direction")
+	rateLimitThrottled = metrics.NewGaugeVec("skycoin_api_rate_limit_throttled",
+		"Number of API requests rejected by the rate limit, by limiter (default or expensive)", "limiter")
+)
+
+// statusRecorder records the status code written to a http.ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.statusCode = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// RouteMetrics records the number of requests and the time spent handling them for a route
+func RouteMetrics(route string, handler http.Handler) http.Handler {
+	duration := httpRequestDuration.WithLabelValues(route)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		handler.ServeHTTP(rec, r)
+
+		duration.ObserveSince(start)
+		httpRequests.WithLabelValues(route, strconv.Itoa(rec.statusCode)).Inc()
+	})
+}
+
+// Returns the node's metrics in the Prometheus text exposition format
+// URI: /metrics
+// Method: GET
+func metricsHandler(gateway Gatewayer, limiter, expensiveLimiter *RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		stats, err := gateway.GetUnconfirmedTxnPoolStats()
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		unconfirmedTxns.Set(float64(stats.Count))
+		unconfirmedSize.Set(float64(stats.Size))
+		unconfirmedEvicted.Set(float64(stats.Evicted))
+		unconfirmedReplaced.Set(float64(stats.Replaced))
+
+		var incoming, outgoing int
+		if conns := gateway.GetConnections(); conns != nil {
+			for _, c := range conns.Connections {
+				if c.Outgoing {
+					outgoing++
+				} else {
+					incoming++
+				}
+			}
+		}
+
+		peers.WithLabelValues("incoming").Set(float64(incoming))
+		peers.WithLabelValues("outgoing").Set(float64(outgoing))
+
+		if limiter != nil {
+			rateLimitThrottled.WithLabelValues("default").Set(float64(limiter.Stats().Throttled))
+		}
+		if expensiveLimiter != nil {
+			rateLimitThrottled.WithLabelValues("expensive").Set(float64(expensiveLimiter.Stats().Throttled))
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := metrics.DefaultRegistry.WriteText(w); err != nil {
+			logger.WithError(err).Error("Write metrics failed")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   string
+		stats    *visor.UnconfirmedTxnPoolStats
+		statsErr error
+		status   int
+		err      string
+		contains []string
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:     "500 - GetUnconfirmedTxnPoolStats failed",
+			method:   http.MethodGet,
+			statsErr: errors.New("failed"),
+			status:   http.StatusInternalServerError,
+			err:      "500 Internal Server Error - failed",
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			stats: &visor.UnconfirmedTxnPoolStats{
+				Count:    3,
+				Size:     1200,
+				Evicted:  1,
+				Replaced: 2,
+			},
+			status: http.StatusOK,
+			contains: []string{
+				"# TYPE skycoin_unconfirmed_txns gauge\nskycoin_unconfirmed_txns 3\n",
+				"skycoin_unconfirmed_size_bytes 1200\n",
+				"skycoin_unconfirmed_evicted 1\n",
+				"skycoin_unconfirmed_replaced 2\n",
+				`skycoin_peers{direction="incoming"} 1` + "\n",
+				`skycoin_peers{direction="outgoing"} 2` + "\n",
+				"# TYPE skycoin_http_request_duration_seconds histogram\n",
+				"# TYPE skycoin_db_tx_duration_seconds histogram\n",
+				"# TYPE skycoin_strand_call_duration_seconds histogram\n",
+				"# TYPE skycoin_gnet_messages_sent_total counter\n",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &GatewayerMock{}
+			gateway.On("GetUnconfirmedTxnPoolStats").Return(tc.stats, tc.statsErr)
+			gateway.On("GetConnections").Return(&daemon.Connections{
+				Connections: []*daemon.Connection{
+					{Addr: "1.2.3.4:6000", Outgoing: true},
+					{Addr: "1.2.3.5:6000", Outgoing: true},
+					{Addr: "1.2.3.6:6000"},
+				},
+			})
+
+			req, err := http.NewRequest(tc.method, "/metrics", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, &CSRFStore{}, nil)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "wrong status code: got `%v` want `%v`", rr.Code, tc.status)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err+"\n", rr.Body.String())
+				return
+			}
+
+			require.Equal(t, "text/plain; version=0.0.4", rr.Header().Get("Content-Type"))
+
+			body := rr.Body.String()
+			for _, s := range tc.contains {
+				require.Contains(t, body, s)
+			}
+		})
+	}
+}
+
+func TestRouteMetrics(t *testing.T) {
+	handler := RouteMetrics("/test/route", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/test/route", nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	require.Equal(t, float64(2), httpRequests.WithLabelValues("/test/route", "418").Value())
+	require.Equal(t, uint64(2), httpRequestDuration.WithLabelValues("/test/route").Count())
+}
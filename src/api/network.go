@@ -24,6 +24,7 @@ type Connections struct {
 
 func connectionHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -62,6 +63,7 @@ func connectionHandler(gateway Gatewayer) http.HandlerFunc {
 
 func connectionsHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -95,6 +97,7 @@ func connectionsHandler(gateway Gatewayer) http.HandlerFunc {
 
 func defaultConnectionsHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -109,6 +112,7 @@ func defaultConnectionsHandler(gateway Gatewayer) http.HandlerFunc {
 
 func trustConnectionsHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -123,6 +127,7 @@ func trustConnectionsHandler(gateway Gatewayer) http.HandlerFunc {
 
 func exchgConnectionsHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
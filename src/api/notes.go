@@ -1,65 +1,262 @@
 package api
 
-// NotesRPC note rpc
-// type NotesRPC struct {
-// 	Notes           wallet.Notes
-// 	WalletDirectory string
-// }
-
-// // Ng global note
-// var Ng *NotesRPC
-
-// // InitWalletRPC init wallet rpc
-// func InitWalletRPC(walletDir string, options ...wallet.Option) {
-// 	Ng = NewNotesRPC(walletDir)
-// }
-
-// // NewNotesRPC new notes rpc
-// func NewNotesRPC(walletDir string) *NotesRPC {
-// 	rpc := &NotesRPC{}
-// 	if err := os.MkdirAll(walletDir, os.FileMode(0700)); err != nil {
-// 		logger.Panicf("Failed to create notes directory %s: %v", walletDir, err)
-// 	}
-// 	rpc.WalletDirectory = walletDir
-// 	w, err := wallet.LoadNotes(rpc.WalletDirectory)
-// 	if err != nil {
-// 		logger.Panicf("Failed to load all notes: %v", err)
-// 	}
-// 	wallet.CreateNoteFileIfNotExist(walletDir)
-// 	rpc.Notes = w
-// 	return rpc
-// }
-
-// GetNotesReadable returns readable notes
-// func (nt *NotesRPC) GetNotesReadable() wallet.ReadableNotes {
-// 	return nt.Notes.ToReadable()
-// }
-
-// // Create a wallet Name is set by creation date
-// func notesCreate(gateway *daemon.Gateway) http.HandlerFunc {
-// 	return func(w http.ResponseWriter, r *http.Request) {
-// 		logger.Info("API request made to create a note")
-// 		note := r.FormValue("note")
-// 		transactionID := r.FormValue("transaction_id")
-// 		newNote := wallet.Note{
-// 			TxID:  transactionID,
-// 			Value: note,
-// 		}
-// 		Ng.Notes.SaveNote(Ng.WalletDirectory, newNote)
-// 		rlt := Ng.GetNotesReadable()
-// 		wh.SendOr500(w, rlt)
-// 	}
-// }
-
-// // Returns a wallet by ID if GET.  Creates or updates a wallet if POST.
-// func notesHandler(gateway *daemon.Gateway) http.HandlerFunc {
-// 	return func(w http.ResponseWriter, r *http.Request) {
-// 		//ret := wallet.Wallets.ToPublicReadable()
-// 		ret := Ng.GetNotesReadable()
-// 		wh.SendOr404(w, ret)
-// 	}
-// }
-
-// mux.Handle("/notes", notesHandler(gateway))
-
-// mux.Handle("/notes/create", notesCreate(gateway))
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	wh "github.com/skycoin/skycoin/src/util/http"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// Returns the transaction notes, address labels and contacts of a wallet
+// URI: /api/v1/wallet/metadata
+// Method: POST
+// Args:
+//     id: wallet id [required]
+//     password: wallet password, required if the wallet is encrypted
+func walletMetadataHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		id := r.FormValue("id")
+		if id == "" {
+			wh.Error400(w, "missing wallet id")
+			return
+		}
+
+		password := r.FormValue("password")
+		defer func() {
+			password = ""
+		}()
+
+		m, err := gateway.GetWalletMetadata(id, []byte(password))
+		if err != nil {
+			writeWalletMetadataError(w, r, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, m)
+	}
+}
+
+// Sets the note of a transaction in a wallet
+// URI: /api/v1/wallet/setNote
+// Method: POST
+// Args:
+//     id: wallet id [required]
+//     txid: transaction id [required]
+//     note: the note, an empty note deletes the transaction's note
+//     password: wallet password, required if the wallet is encrypted
+func walletSetNoteHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		id := r.FormValue("id")
+		if id == "" {
+			wh.Error400(w, "missing wallet id")
+			return
+		}
+
+		txidStr := r.FormValue("txid")
+		if txidStr == "" {
+			wh.Error400(w, "missing txid")
+			return
+		}
+
+		txid, err := cipher.SHA256FromHex(txidStr)
+		if err != nil {
+			wh.Error400(w, fmt.Sprintf("invalid txid: %v", err))
+			return
+		}
+
+		password := r.FormValue("password")
+		defer func() {
+			password = ""
+		}()
+
+		m, err := gateway.SetWalletNote(id, []byte(password), txid, r.FormValue("note"))
+		if err != nil {
+			writeWalletMetadataError(w, r, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, m)
+	}
+}
+
+// Sets the label of an address in a wallet
+// URI: /api/v1/wallet/setLabel
+// Method: POST
+// Args:
+//     id: wallet id [required]
+//     address: an address of the wallet [required]
+//     label: the label, an empty label deletes the address's label
+//     password: wallet password, required if the wallet is encrypted
+func walletSetLabelHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		id := r.FormValue("id")
+		if id == "" {
+			wh.Error400(w, "missing wallet id")
+			return
+		}
+
+		addrStr := r.FormValue("address")
+		if addrStr == "" {
+			wh.Error400(w, "missing address")
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(addrStr)
+		if err != nil {
+			wh.Error400(w, fmt.Sprintf("invalid address: %v", err))
+			return
+		}
+
+		password := r.FormValue("password")
+		defer func() {
+			password = ""
+		}()
+
+		m, err := gateway.SetWalletAddressLabel(id, []byte(password), addr, r.FormValue("label"))
+		if err != nil {
+			writeWalletMetadataError(w, r, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, m)
+	}
+}
+
+// Adds a contact to the address book of a wallet, or changes the address of a contact
+// URI: /api/v1/wallet/setContact
+// Method: POST
+// Args:
+//     id: wallet id [required]
+//     name: contact name [required]
+//     address: contact address [required]
+//     password: wallet password, required if the wallet is encrypted
+func walletSetContactHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		id := r.FormValue("id")
+		if id == "" {
+			wh.Error400(w, "missing wallet id")
+			return
+		}
+
+		name := r.FormValue("name")
+		if name == "" {
+			wh.Error400(w, "missing name")
+			return
+		}
+
+		addrStr := r.FormValue("address")
+		if addrStr == "" {
+			wh.Error400(w, "missing address")
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(addrStr)
+		if err != nil {
+			wh.Error400(w, fmt.Sprintf("invalid address: %v", err))
+			return
+		}
+
+		password := r.FormValue("password")
+		defer func() {
+			password = ""
+		}()
+
+		m, err := gateway.SetWalletContact(id, []byte(password), name, addr)
+		if err != nil {
+			writeWalletMetadataError(w, r, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, m)
+	}
+}
+
+// Removes a contact from the address book of a wallet
+// URI: /api/v1/wallet/deleteContact
+// Method: POST
+// Args:
+//     id: wallet id [required]
+//     name: contact name [required]
+//     password: wallet password, required if the wallet is encrypted
+func walletDeleteContactHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		id := r.FormValue("id")
+		if id == "" {
+			wh.Error400(w, "missing wallet id")
+			return
+		}
+
+		name := r.FormValue("name")
+		if name == "" {
+			wh.Error400(w, "missing name")
+			return
+		}
+
+		password := r.FormValue("password")
+		defer func() {
+			password = ""
+		}()
+
+		m, err := gateway.DeleteWalletContact(id, []byte(password), name)
+		if err != nil {
+			writeWalletMetadataError(w, r, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, m)
+	}
+}
+
+// writeWalletMetadataError writes the error response of the wallet metadata endpoints
+func writeWalletMetadataError(w http.ResponseWriter, r *http.Request, err error) {
+	setErrorCode(r, err)
+	switch err {
+	case wallet.ErrInvalidPassword:
+		wh.Error401(w, HTTP401AuthHeader, err.Error())
+	case wallet.ErrWalletAPIDisabled:
+		wh.Error403(w, "")
+	case wallet.ErrWalletNotExist:
+		wh.Error404(w, "")
+	case wallet.ErrContactNotExist:
+		wh.Error404(w, err.Error())
+	default:
+		switch err.(type) {
+		case wallet.Error:
+			wh.Error400(w, err.Error())
+		default:
+			wh.Error500(w, err.Error())
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestWalletMetadataHandlers(t *testing.T) {
+	txid := testutil.RandSHA256(t)
+	addr := testutil.MakeAddress()
+
+	metadata := wallet.NewMetadata()
+	metadata.SetNote(txid, "note")
+	metadata.SetLabel(addr, "label")
+	require.NoError(t, metadata.SetContact("alice", addr))
+
+	tt := []struct {
+		name          string
+		endpoint      string
+		method        string
+		form          map[string]string
+		gatewayMethod string
+		gatewayArgs   []interface{}
+		gatewayErr    error
+		status        int
+		err           string
+	}{
+		{
+			name:     "metadata 405",
+			endpoint: "/api/v1/wallet/metadata",
+			method:   http.MethodGet,
+			status:   http.StatusMethodNotAllowed,
+			err:      "405 Method Not Allowed",
+		},
+		{
+			name:     "metadata 400 - missing wallet id",
+			endpoint: "/api/v1/wallet/metadata",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing wallet id",
+		},
+		{
+			name:          "metadata 400 - missing password",
+			endpoint:      "/api/v1/wallet/metadata",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt"},
+			gatewayMethod: "GetWalletMetadata",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte("")},
+			gatewayErr:    wallet.ErrMissingPassword,
+			status:        http.StatusBadRequest,
+			err:           "400 Bad Request - missing password",
+		},
+		{
+			name:          "metadata 401 - invalid password",
+			endpoint:      "/api/v1/wallet/metadata",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt", "password": "pwd"},
+			gatewayMethod: "GetWalletMetadata",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte("pwd")},
+			gatewayErr:    wallet.ErrInvalidPassword,
+			status:        http.StatusUnauthorized,
+			err:           "401 Unauthorized - invalid password",
+		},
+		{
+			name:          "metadata 403 - wallet API disabled",
+			endpoint:      "/api/v1/wallet/metadata",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt"},
+			gatewayMethod: "GetWalletMetadata",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte("")},
+			gatewayErr:    wallet.ErrWalletAPIDisabled,
+			status:        http.StatusForbidden,
+			err:           "403 Forbidden",
+		},
+		{
+			name:          "metadata 404 - wallet doesn't exist",
+			endpoint:      "/api/v1/wallet/metadata",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt"},
+			gatewayMethod: "GetWalletMetadata",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte("")},
+			gatewayErr:    wallet.ErrWalletNotExist,
+			status:        http.StatusNotFound,
+			err:           "404 Not Found",
+		},
+		{
+			name:          "metadata 500 - gateway error",
+			endpoint:      "/api/v1/wallet/metadata",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt"},
+			gatewayMethod: "GetWalletMetadata",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte("")},
+			gatewayErr:    errors.New("gateway error"),
+			status:        http.StatusInternalServerError,
+			err:           "500 Internal Server Error - gateway error",
+		},
+		{
+			name:          "metadata 200",
+			endpoint:      "/api/v1/wallet/metadata",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt", "password": "pwd"},
+			gatewayMethod: "GetWalletMetadata",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte("pwd")},
+			status:        http.StatusOK,
+		},
+		{
+			name:     "setNote 405",
+			endpoint: "/api/v1/wallet/setNote",
+			method:   http.MethodGet,
+			status:   http.StatusMethodNotAllowed,
+			err:      "405 Method Not Allowed",
+		},
+		{
+			name:     "setNote 400 - missing txid",
+			endpoint: "/api/v1/wallet/setNote",
+			method:   http.MethodPost,
+			form:     map[string]string{"id": "foo.wlt"},
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing txid",
+		},
+		{
+			name:     "setNote 400 - invalid txid",
+			endpoint: "/api/v1/wallet/setNote",
+			method:   http.MethodPost,
+			form:     map[string]string{"id": "foo.wlt", "txid": "abc"},
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - invalid txid: encoding/hex: odd length hex string",
+		},
+		{
+			name:          "setNote 200",
+			endpoint:      "/api/v1/wallet/setNote",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt", "txid": txid.Hex(), "note": "note"},
+			gatewayMethod: "SetWalletNote",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte(""), txid, "note"},
+			status:        http.StatusOK,
+		},
+		{
+			name:     "setLabel 400 - missing address",
+			endpoint: "/api/v1/wallet/setLabel",
+			method:   http.MethodPost,
+			form:     map[string]string{"id": "foo.wlt"},
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing address",
+		},
+		{
+			name:     "setLabel 400 - invalid address",
+			endpoint: "/api/v1/wallet/setLabel",
+			method:   http.MethodPost,
+			form:     map[string]string{"id": "foo.wlt", "address": "xyz"},
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - invalid address: Invalid address length",
+		},
+		{
+			name:          "setLabel 400 - address not in wallet",
+			endpoint:      "/api/v1/wallet/setLabel",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt", "address": addr.String(), "label": "label"},
+			gatewayMethod: "SetWalletAddressLabel",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte(""), addr, "label"},
+			gatewayErr:    wallet.ErrUnknownAddress,
+			status:        http.StatusBadRequest,
+			err:           "400 Bad Request - address not found in wallet",
+		},
+		{
+			name:          "setLabel 200",
+			endpoint:      "/api/v1/wallet/setLabel",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt", "address": addr.String(), "label": "label", "password": "pwd"},
+			gatewayMethod: "SetWalletAddressLabel",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte("pwd"), addr, "label"},
+			status:        http.StatusOK,
+		},
+		{
+			name:     "setContact 400 - missing name",
+			endpoint: "/api/v1/wallet/setContact",
+			method:   http.MethodPost,
+			form:     map[string]string{"id": "foo.wlt", "address": addr.String()},
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing name",
+		},
+		{
+			name:     "setContact 400 - missing address",
+			endpoint: "/api/v1/wallet/setContact",
+			method:   http.MethodPost,
+			form:     map[string]string{"id": "foo.wlt", "name": "alice"},
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing address",
+		},
+		{
+			name:          "setContact 200",
+			endpoint:      "/api/v1/wallet/setContact",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt", "name": "alice", "address": addr.String()},
+			gatewayMethod: "SetWalletContact",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte(""), "alice", addr},
+			status:        http.StatusOK,
+		},
+		{
+			name:     "deleteContact 400 - missing name",
+			endpoint: "/api/v1/wallet/deleteContact",
+			method:   http.MethodPost,
+			form:     map[string]string{"id": "foo.wlt"},
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing name",
+		},
+		{
+			name:          "deleteContact 404 - contact doesn't exist",
+			endpoint:      "/api/v1/wallet/deleteContact",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt", "name": "bob"},
+			gatewayMethod: "DeleteWalletContact",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte(""), "bob"},
+			gatewayErr:    wallet.ErrContactNotExist,
+			status:        http.StatusNotFound,
+			err:           "404 Not Found - contact doesn't exist",
+		},
+		{
+			name:          "deleteContact 200",
+			endpoint:      "/api/v1/wallet/deleteContact",
+			method:        http.MethodPost,
+			form:          map[string]string{"id": "foo.wlt", "name": "alice"},
+			gatewayMethod: "DeleteWalletContact",
+			gatewayArgs:   []interface{}{"foo.wlt", []byte(""), "alice"},
+			status:        http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &GatewayerMock{}
+			if tc.gatewayMethod != "" {
+				var result *wallet.Metadata
+				if tc.gatewayErr == nil {
+					result = metadata
+				}
+				gateway.On(tc.gatewayMethod, tc.gatewayArgs...).Return(result, tc.gatewayErr)
+			}
+
+			v := url.Values{}
+			for k, x := range tc.form {
+				v.Add(k, x)
+			}
+
+			req, err := http.NewRequest(tc.method, tc.endpoint, strings.NewReader(v.Encode()))
+			require.NoError(t, err)
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(mxConfig, gateway, csrfStore, nil)
+
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "wrong status code: got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var m wallet.Metadata
+			err = json.Unmarshal(rr.Body.Bytes(), &m)
+			require.NoError(t, err)
+			require.Equal(t, *metadata, m)
+		})
+	}
+}
@@ -0,0 +1,1436 @@
+package api
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/skycoin/skycoin/src/api/webrpc"
+	"github.com/skycoin/skycoin/src/daemon"
+	wh "github.com/skycoin/skycoin/src/util/http" //http,json helpers
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+const (
+	openAPIVersion = "3.0.0"
+
+	// openAPISecurityScheme is the name of the bearer token security scheme in the spec
+	openAPISecurityScheme = "bearerAuth"
+)
+
+// OpenAPISpec is an OpenAPI 3 document describing the REST API
+type OpenAPISpec struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents          `json:"components"`
+}
+
+// OpenAPIInfo is the info object of an OpenAPISpec
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIPathItem maps lowercase HTTP methods to the operations of a path
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+// OpenAPIOperation is an operation on a path
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId,omitempty"`
+	Summary     string                     `json:"summary,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+	// Scope is the API token scope required by the operation
+	Scope Scope `json:"x-skycoin-scope,omitempty"`
+}
+
+// OpenAPIParameter is a query parameter of an operation
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIRequestBody is the request body of an operation
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse is a response of an operation
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType is the schema of a request or response body
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema is a subset of the OpenAPI schema object
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+// OpenAPIComponents are the reusable schemas and the security schemes of an OpenAPISpec
+type OpenAPIComponents struct {
+	Schemas         map[string]*OpenAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+// OpenAPISecurityScheme is a security scheme of an OpenAPISpec
+type OpenAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description,omitempty"`
+}
+
+// apiRoute is an endpoint registered in newServerMux
+type apiRoute struct {
+	path  string
+	scope Scope
+}
+
+// apiOperation documents an operation of a registered endpoint.
+// The spec served by the node and the generated client methods in client_gen.go are built from apiOperations.
+type apiOperation struct {
+	path    string
+	method  string
+	tag     string
+	summary string
+	// Query parameters of GET operations, form values of POST operations
+	params []apiParam
+	// Request JSON body
+	body interface{}
+	// Response JSON body. v2 responses are wrapped in HTTPResponse
+	response interface{}
+	v2       bool
+	// v1 operations are also served under /api/v2 by mirrorV1, unless v1Only is set
+	v1Only bool
+	// Client methods generated for the operation
+	clients []apiClientMethod
+}
+
+// apiParam is a query parameter or form value
+type apiParam struct {
+	name        string
+	typ         string
+	required    bool
+	description string
+}
+
+// apiClientMethod describes a generated Client method
+type apiClientMethod struct {
+	name string
+	// Appended to the doc comment "<name> makes a request to <method> <path>"
+	doc  string
+	args []apiClientArg
+	// A zero value of the method's result type, nil if the method only returns an error
+	result interface{}
+}
+
+// apiClientArg is an argument of a generated Client method, sent as the parameter param
+// or as the JSON body if param is empty
+type apiClientArg struct {
+	name  string
+	param string
+	typ   string
+}
+
+var (
+	addrsParam = apiParam{
+		name:        "addrs",
+		typ:         "string",
+		description: "Comma separated list of addresses",
+	}
+	walletIDParam = apiParam{
+		name:        "id",
+		typ:         "string",
+		required:    true,
+		description: "Wallet id",
+	}
+	walletPasswordParam = apiParam{
+		name:        "password",
+		typ:         "string",
+		description: "Wallet password, required if the wallet is encrypted",
+	}
+	txidParam = apiParam{
+		name:        "txid",
+		typ:         "string",
+		required:    true,
+		description: "Transaction id",
+	}
+	maxAgeParam = apiParam{
+		name:        "max_age",
+		typ:         "string",
+		description: "Age after which transactions are expired, as a duration such as 72h. Defaults to the node's configured max age",
+	}
+	startTimeParam = apiParam{
+		name:        "start_time",
+		typ:         "integer",
+		description: "Earliest transaction time, as a unix timestamp",
+	}
+	endTimeParam = apiParam{
+		name:        "end_time",
+		typ:         "integer",
+		description: "Latest transaction time, as a unix timestamp",
+	}
+	ledgerFormatParam = apiParam{
+		name:        "format",
+		typ:         "string",
+		description: "json or csv. Defaults to json",
+	}
+	includeDistributionParam = apiParam{
+		name:        "include-distribution",
+		typ:         "boolean",
+		description: "Include the distribution addresses",
+	}
+
+	walletIDArg = apiClientArg{name: "id", param: "id", typ: "string"}
+	passwordArg = apiClientArg{name: "password", param: "password", typ: "string"}
+)
+
+var apiOperations = []apiOperation{
+	{
+		path:     "/api/v1/version",
+		method:   http.MethodGet,
+		tag:      "system",
+		summary:  "Returns the node's version",
+		response: visor.BuildInfo{},
+		clients: []apiClientMethod{{
+			name:   "Version",
+			result: (*visor.BuildInfo)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/health",
+		method:   http.MethodGet,
+		tag:      "system",
+		summary:  "Returns the node's health",
+		response: HealthResponse{},
+		clients: []apiClientMethod{{
+			name:   "Health",
+			result: (*HealthResponse)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/openapi.json",
+		method:   http.MethodGet,
+		tag:      "system",
+		summary:  "Returns the OpenAPI specification of the API",
+		response: OpenAPISpec{},
+		v1Only:   true,
+		clients: []apiClientMethod{{
+			name:   "OpenAPISpec",
+			result: (*OpenAPISpec)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/ratelimit",
+		method:   http.MethodGet,
+		tag:      "system",
+		summary:  "Returns the rate limits and the number of throttled requests",
+		response: RateLimitResponse{},
+		clients: []apiClientMethod{{
+			name:   "RateLimit",
+			result: (*RateLimitResponse)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/reloadConfig",
+		method:   http.MethodPost,
+		tag:      "system",
+		summary:  "Reloads the node's config and applies the options that can be changed while the node runs",
+		response: ConfigReloadResult{},
+		clients: []apiClientMethod{{
+			name:   "ReloadConfig",
+			result: (*ConfigReloadResult)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/webrpc",
+		method:   http.MethodPost,
+		tag:      "system",
+		summary:  "JSON-RPC 2.0 API, deprecated",
+		body:     webrpc.Request{},
+		response: webrpc.Response{},
+		v1Only:   true,
+	},
+	{
+		path:    "/api/v1/outputs",
+		method:  http.MethodGet,
+		tag:     "simple query",
+		summary: "Returns the unspent outputs, filtered by addresses or hashes",
+		params: []apiParam{
+			addrsParam,
+			{
+				name:        "hashes",
+				typ:         "string",
+				description: "Comma separated list of output hashes",
+			},
+		},
+		response: visor.ReadableOutputSet{},
+		clients: []apiClientMethod{
+			{
+				name:   "Outputs",
+				result: (*visor.ReadableOutputSet)(nil),
+			},
+			{
+				name:   "OutputsForAddresses",
+				args:   []apiClientArg{{name: "addrs", param: "addrs", typ: "[]string"}},
+				result: (*visor.ReadableOutputSet)(nil),
+			},
+			{
+				name:   "OutputsForHashes",
+				args:   []apiClientArg{{name: "hashes", param: "hashes", typ: "[]string"}},
+				result: (*visor.ReadableOutputSet)(nil),
+			},
+		},
+	},
+	{
+		path:     "/api/v1/balance",
+		method:   http.MethodGet,
+		tag:      "simple query",
+		summary:  "Returns the balance of addresses",
+		params:   []apiParam{addrsParam},
+		response: BalanceResponse{},
+		clients: []apiClientMethod{{
+			name:   "Balance",
+			args:   []apiClientArg{{name: "addrs", param: "addrs", typ: "[]string"}},
+			result: (*wallet.BalancePair)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/wallet",
+		method:   http.MethodGet,
+		tag:      "wallet",
+		summary:  "Returns a wallet",
+		params:   []apiParam{walletIDParam},
+		response: WalletResponse{},
+		clients: []apiClientMethod{{
+			name:   "Wallet",
+			args:   []apiClientArg{walletIDArg},
+			result: (*WalletResponse)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/create",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Creates a wallet",
+		params: []apiParam{
+			{name: "seed", typ: "string", required: true, description: "Wallet seed"},
+			{name: "label", typ: "string", required: true, description: "Wallet label"},
+			{name: "encrypt", typ: "boolean", description: "Encrypt the wallet"},
+			{name: "password", typ: "string", description: "Wallet password, required if encrypt is true"},
+			{name: "scan", typ: "integer", description: "Number of addresses to scan for a balance, defaults to 1"},
+		},
+		response: WalletResponse{},
+	},
+	{
+		path:    "/api/v1/wallet/newAddress",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Generates new addresses in a wallet",
+		params: []apiParam{
+			walletIDParam,
+			{name: "num", typ: "integer", description: "Number of addresses to generate, defaults to 1"},
+			walletPasswordParam,
+		},
+		response: struct {
+			Addresses []string `json:"addresses"`
+		}{},
+	},
+	{
+		path:     "/api/v1/wallet/balance",
+		method:   http.MethodGet,
+		tag:      "wallet",
+		summary:  "Returns the balance of a wallet",
+		params:   []apiParam{walletIDParam},
+		response: BalanceResponse{},
+		clients: []apiClientMethod{{
+			name:   "WalletBalance",
+			args:   []apiClientArg{walletIDArg},
+			result: (*BalanceResponse)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/spend",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Sends coins from a wallet",
+		params: []apiParam{
+			walletIDParam,
+			{name: "dst", typ: "string", required: true, description: "Destination address"},
+			{name: "coins", typ: "integer", required: true, description: "Number of droplets to send"},
+			walletPasswordParam,
+		},
+		response: SpendResult{},
+		clients: []apiClientMethod{{
+			name: "Spend",
+			args: []apiClientArg{
+				walletIDArg,
+				{name: "dst", param: "dst", typ: "string"},
+				{name: "coins", param: "coins", typ: "uint64"},
+				passwordArg,
+			},
+			result: (*SpendResult)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/wallet/transaction",
+		method:   http.MethodPost,
+		tag:      "wallet",
+		summary:  "Creates a signed transaction, without injecting it",
+		body:     createTransactionRequest{},
+		response: CreateTransactionResponse{},
+		clients: []apiClientMethod{{
+			name:   "CreateTransaction",
+			args:   []apiClientArg{{name: "req", typ: "CreateTransactionRequest"}},
+			result: (*CreateTransactionResponse)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/wallet/transactions",
+		method:   http.MethodGet,
+		tag:      "wallet",
+		summary:  "Returns the unconfirmed transactions of a wallet",
+		params:   []apiParam{walletIDParam},
+		response: UnconfirmedTxnsResponse{},
+		clients: []apiClientMethod{{
+			name:   "WalletTransactions",
+			args:   []apiClientArg{walletIDArg},
+			result: (*UnconfirmedTxnsResponse)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/ledger",
+		method:  http.MethodGet,
+		tag:     "wallet",
+		summary: "Returns the incoming, outgoing and self transfer transactions of a wallet, with their notes",
+		params: []apiParam{
+			walletIDParam,
+			startTimeParam,
+			endTimeParam,
+			ledgerFormatParam,
+		},
+		response: LedgerResponse{},
+		clients: []apiClientMethod{{
+			name: "WalletLedger",
+			doc:  " to get the ledger of a wallet as JSON",
+			args: []apiClientArg{
+				walletIDArg,
+				{name: "startTime", param: "start_time", typ: "uint64"},
+				{name: "endTime", param: "end_time", typ: "uint64"},
+			},
+			result: (*LedgerResponse)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/update",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Updates the label of a wallet",
+		params: []apiParam{
+			walletIDParam,
+			{name: "label", typ: "string", required: true, description: "Wallet label"},
+		},
+		response: "",
+		clients: []apiClientMethod{{
+			name: "UpdateWallet",
+			args: []apiClientArg{walletIDArg, {name: "label", param: "label", typ: "string"}},
+		}},
+	},
+	{
+		path:     "/api/v1/wallets",
+		method:   http.MethodGet,
+		tag:      "wallet",
+		summary:  "Returns all loaded wallets",
+		response: []WalletResponse{},
+		clients: []apiClientMethod{{
+			name:   "Wallets",
+			result: []*WalletResponse(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/wallets/folderName",
+		method:   http.MethodGet,
+		tag:      "wallet",
+		summary:  "Returns the wallet directory",
+		response: WalletFolder{},
+		clients: []apiClientMethod{{
+			name:   "WalletFolderName",
+			result: (*WalletFolder)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/newSeed",
+		method:  http.MethodGet,
+		tag:     "wallet",
+		summary: "Generates a new seed",
+		params: []apiParam{
+			{name: "entropy", typ: "integer", description: "Entropy bits of the seed, 128 or 256. Defaults to 128"},
+		},
+		response: struct {
+			Seed string `json:"seed"`
+		}{},
+	},
+	{
+		path:    "/api/v1/wallet/seed",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Returns the seed of an encrypted wallet",
+		params:  []apiParam{walletIDParam, walletPasswordParam},
+		response: struct {
+			Seed string `json:"seed"`
+		}{},
+	},
+	{
+		path:    "/api/v1/wallet/export",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Exports a wallet, with its notes, labels and contacts, to a password encrypted bundle",
+		params: []apiParam{
+			walletIDParam,
+			{name: "password", typ: "string", required: true, description: "Bundle password"},
+			{name: "watch_only", typ: "boolean", description: "Leave out the seeds and secret keys, for a watch-only wallet"},
+			{name: "wallet_password", typ: "string", description: "Wallet password, required to export an encrypted wallet with watch_only"},
+		},
+		response: wallet.EncryptedBundle{},
+		clients: []apiClientMethod{{
+			name: "ExportWallet",
+			doc:  " to export a wallet to a bundle encrypted with password",
+			args: []apiClientArg{
+				walletIDArg,
+				passwordArg,
+				{name: "watchOnly", param: "watch_only", typ: "bool"},
+				{name: "walletPassword", param: "wallet_password", typ: "string"},
+			},
+			result: (*wallet.EncryptedBundle)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/import",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Imports a wallet from a bundle made by /api/v1/wallet/export",
+		params: []apiParam{
+			{name: "bundle", typ: "string", required: true, description: "JSON of the bundle"},
+			{name: "password", typ: "string", required: true, description: "Bundle password"},
+		},
+		response: WalletResponse{},
+	},
+	{
+		path:    "/api/v1/wallet/unload",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Unloads a wallet",
+		params:  []apiParam{walletIDParam},
+		clients: []apiClientMethod{{
+			name: "UnloadWallet",
+			args: []apiClientArg{walletIDArg},
+		}},
+	},
+	{
+		path:     "/api/v1/wallet/encrypt",
+		method:   http.MethodPost,
+		tag:      "wallet",
+		summary:  "Encrypts a wallet",
+		params:   []apiParam{walletIDParam, walletPasswordParam},
+		response: WalletResponse{},
+		clients: []apiClientMethod{{
+			name:   "EncryptWallet",
+			doc:    " to encrypt a specific wallet with the given password",
+			args:   []apiClientArg{walletIDArg, passwordArg},
+			result: (*WalletResponse)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/wallet/decrypt",
+		method:   http.MethodPost,
+		tag:      "wallet",
+		summary:  "Decrypts a wallet",
+		params:   []apiParam{walletIDParam, walletPasswordParam},
+		response: WalletResponse{},
+		clients: []apiClientMethod{{
+			name:   "DecryptWallet",
+			doc:    " to decrypt a wallet",
+			args:   []apiClientArg{walletIDArg, passwordArg},
+			result: (*WalletResponse)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/wallet/metadata",
+		method:   http.MethodPost,
+		tag:      "wallet",
+		summary:  "Returns the transaction notes, address labels and contacts of a wallet",
+		params:   []apiParam{walletIDParam, walletPasswordParam},
+		response: wallet.Metadata{},
+		clients: []apiClientMethod{{
+			name:   "WalletMetadata",
+			doc:    " to get the notes, address labels and contacts of a wallet",
+			args:   []apiClientArg{walletIDArg, passwordArg},
+			result: (*wallet.Metadata)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/setNote",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Sets the note of a transaction in a wallet",
+		params: []apiParam{
+			walletIDParam,
+			txidParam,
+			{name: "note", typ: "string", description: "The note, an empty note deletes the transaction's note"},
+			walletPasswordParam,
+		},
+		response: wallet.Metadata{},
+		clients: []apiClientMethod{{
+			name: "SetWalletNote",
+			doc:  " to set the note of a transaction in a wallet",
+			args: []apiClientArg{
+				walletIDArg,
+				{name: "txid", param: "txid", typ: "string"},
+				{name: "note", param: "note", typ: "string"},
+				passwordArg,
+			},
+			result: (*wallet.Metadata)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/setLabel",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Sets the label of an address in a wallet",
+		params: []apiParam{
+			walletIDParam,
+			{name: "address", typ: "string", required: true, description: "An address of the wallet"},
+			{name: "label", typ: "string", description: "The label, an empty label deletes the address's label"},
+			walletPasswordParam,
+		},
+		response: wallet.Metadata{},
+		clients: []apiClientMethod{{
+			name: "SetWalletAddressLabel",
+			doc:  " to set the label of an address in a wallet",
+			args: []apiClientArg{
+				walletIDArg,
+				{name: "address", param: "address", typ: "string"},
+				{name: "label", param: "label", typ: "string"},
+				passwordArg,
+			},
+			result: (*wallet.Metadata)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/setContact",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Adds a contact to the address book of a wallet, or changes the address of a contact",
+		params: []apiParam{
+			walletIDParam,
+			{name: "name", typ: "string", required: true, description: "Contact name"},
+			{name: "address", typ: "string", required: true, description: "Contact address"},
+			walletPasswordParam,
+		},
+		response: wallet.Metadata{},
+		clients: []apiClientMethod{{
+			name: "SetWalletContact",
+			doc:  " to add or change a contact in the address book of a wallet",
+			args: []apiClientArg{
+				walletIDArg,
+				{name: "name", param: "name", typ: "string"},
+				{name: "address", param: "address", typ: "string"},
+				passwordArg,
+			},
+			result: (*wallet.Metadata)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/deleteContact",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Removes a contact from the address book of a wallet",
+		params: []apiParam{
+			walletIDParam,
+			{name: "name", typ: "string", required: true, description: "Contact name"},
+			walletPasswordParam,
+		},
+		response: wallet.Metadata{},
+		clients: []apiClientMethod{{
+			name: "DeleteWalletContact",
+			doc:  " to remove a contact from the address book of a wallet",
+			args: []apiClientArg{
+				walletIDArg,
+				{name: "name", param: "name", typ: "string"},
+				passwordArg,
+			},
+			result: (*wallet.Metadata)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/wallet/changePassword",
+		method:  http.MethodPost,
+		tag:     "wallet",
+		summary: "Changes the password and/or crypto type of an encrypted wallet, without decrypting it on disk",
+		params: []apiParam{
+			walletIDParam,
+			{name: "old_password", typ: "string", required: true, description: "Current wallet password"},
+			{name: "new_password", typ: "string", description: "New wallet password, the password is not changed if empty"},
+			{name: "crypto_type", typ: "string", description: "New crypto type, sha256-xor or scrypt-chacha20poly1305"},
+			{name: "scrypt_n", typ: "integer", description: "scrypt N parameter, a power of 2 of at least 16384. 128*N*r must not exceed 1 GiB. Defaults to 1048576"},
+			{name: "scrypt_r", typ: "integer", description: "scrypt r parameter. Defaults to 8"},
+			{name: "scrypt_p", typ: "integer", description: "scrypt p parameter, between 1 and 4. Defaults to 1"},
+		},
+		response: WalletResponse{},
+	},
+	{
+		path:     "/api/v1/blockchain/metadata",
+		method:   http.MethodGet,
+		tag:      "block",
+		summary:  "Returns the blockchain metadata",
+		response: visor.BlockchainMetadata{},
+		clients: []apiClientMethod{{
+			name:   "BlockchainMetadata",
+			result: (*visor.BlockchainMetadata)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/blockchain/progress",
+		method:   http.MethodGet,
+		tag:      "block",
+		summary:  "Returns the blockchain sync progress",
+		response: daemon.BlockchainProgress{},
+		clients: []apiClientMethod{{
+			name:   "BlockchainProgress",
+			result: (*daemon.BlockchainProgress)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/blockchain/keyRotations",
+		method:   http.MethodGet,
+		tag:      "block",
+		summary:  "Returns the key rotations of the blockchain signing keys",
+		response: []visor.ReadableKeyRotation{},
+		clients: []apiClientMethod{{
+			name:   "KeyRotations",
+			result: []visor.ReadableKeyRotation(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/blockchain/injectKeyRotation",
+		method:   http.MethodPost,
+		tag:      "block",
+		summary:  "Adds a signed key rotation of the blockchain signing keys and broadcasts it",
+		body:     visor.ReadableKeyRotation{},
+		response: visor.ReadableKeyRotation{},
+	},
+	{
+		path:    "/api/v1/block",
+		method:  http.MethodGet,
+		tag:     "block",
+		summary: "Returns a block by hash or sequence number",
+		params: []apiParam{
+			{name: "hash", typ: "string", description: "Block hash"},
+			{name: "seq", typ: "integer", description: "Block sequence number"},
+		},
+		response: visor.ReadableBlock{},
+		clients: []apiClientMethod{
+			{
+				name:   "BlockByHash",
+				args:   []apiClientArg{{name: "hash", param: "hash", typ: "string"}},
+				result: (*visor.ReadableBlock)(nil),
+			},
+			{
+				name:   "BlockBySeq",
+				args:   []apiClientArg{{name: "seq", param: "seq", typ: "uint64"}},
+				result: (*visor.ReadableBlock)(nil),
+			},
+		},
+	},
+	{
+		path:    "/api/v1/blocks",
+		method:  http.MethodGet,
+		tag:     "block",
+		summary: "Returns the blocks in a range of sequence numbers",
+		params: []apiParam{
+			{name: "start", typ: "integer", required: true, description: "First block sequence number"},
+			{name: "end", typ: "integer", required: true, description: "Last block sequence number"},
+		},
+		response: visor.ReadableBlocks{},
+		clients: []apiClientMethod{{
+			name: "Blocks",
+			args: []apiClientArg{
+				{name: "start", param: "start", typ: "int"},
+				{name: "end", param: "end", typ: "int"},
+			},
+			result: (*visor.ReadableBlocks)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/last_blocks",
+		method:  http.MethodGet,
+		tag:     "block",
+		summary: "Returns the most recent blocks",
+		params: []apiParam{
+			{name: "num", typ: "integer", required: true, description: "Number of blocks"},
+		},
+		response: visor.ReadableBlocks{},
+		clients: []apiClientMethod{{
+			name:   "LastBlocks",
+			args:   []apiClientArg{{name: "n", param: "num", typ: "int"}},
+			result: (*visor.ReadableBlocks)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/network/connection",
+		method:  http.MethodGet,
+		tag:     "network",
+		summary: "Returns a connection",
+		params: []apiParam{
+			{name: "addr", typ: "string", required: true, description: "Connection address, ip:port"},
+		},
+		response: daemon.Connection{},
+		clients: []apiClientMethod{{
+			name:   "NetworkConnection",
+			args:   []apiClientArg{{name: "addr", param: "addr", typ: "string"}},
+			result: (*daemon.Connection)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/network/connections",
+		method:   http.MethodGet,
+		tag:      "network",
+		summary:  "Returns all connections",
+		response: Connections{},
+		clients: []apiClientMethod{{
+			name:   "NetworkConnections",
+			result: (*Connections)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/network/defaultConnections",
+		method:   http.MethodGet,
+		tag:      "network",
+		summary:  "Returns the default connections",
+		response: []string{},
+		clients: []apiClientMethod{{
+			name:   "NetworkDefaultConnections",
+			result: []string(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/network/connections/trust",
+		method:   http.MethodGet,
+		tag:      "network",
+		summary:  "Returns the trusted connections",
+		response: []string{},
+		clients: []apiClientMethod{{
+			name:   "NetworkTrustedConnections",
+			result: []string(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/network/connections/exchange",
+		method:   http.MethodGet,
+		tag:      "network",
+		summary:  "Returns the connections learned from peers",
+		response: []string{},
+		clients: []apiClientMethod{{
+			name:   "NetworkExchangeableConnections",
+			result: []string(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/pendingTxs",
+		method:   http.MethodGet,
+		tag:      "transaction",
+		summary:  "Returns the unconfirmed transactions",
+		response: []visor.ReadableUnconfirmedTxn{},
+		clients: []apiClientMethod{{
+			name:   "PendingTransactions",
+			result: []*visor.ReadableUnconfirmedTxn(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/pendingTxs/stats",
+		method:   http.MethodGet,
+		tag:      "transaction",
+		summary:  "Returns the statistics of the unconfirmed transaction pool",
+		response: PendingTxnsStats{},
+		clients: []apiClientMethod{{
+			name:   "PendingTransactionsStats",
+			result: (*PendingTxnsStats)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/pendingTxs/expired",
+		method:   http.MethodGet,
+		tag:      "transaction",
+		summary:  "Returns the expired unconfirmed transactions",
+		params:   []apiParam{maxAgeParam},
+		response: []visor.ReadableUnconfirmedTxn{},
+	},
+	{
+		path:     "/api/v1/pendingTxs/purge",
+		method:   http.MethodPost,
+		tag:      "transaction",
+		summary:  "Removes the expired unconfirmed transactions",
+		params:   []apiParam{maxAgeParam},
+		response: []string{},
+	},
+	{
+		path:     "/api/v1/transaction",
+		method:   http.MethodGet,
+		tag:      "transaction",
+		summary:  "Returns a transaction",
+		params:   []apiParam{txidParam},
+		response: daemon.TransactionResult{},
+		clients: []apiClientMethod{{
+			name:   "Transaction",
+			args:   []apiClientArg{{name: "txid", param: "txid", typ: "string"}},
+			result: (*daemon.TransactionResult)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/transactions",
+		method:  http.MethodGet,
+		tag:     "transaction",
+		summary: "Returns the transactions of addresses",
+		params: []apiParam{
+			addrsParam,
+			{name: "confirmed", typ: "boolean", description: "Only return confirmed or unconfirmed transactions"},
+			{name: "in_addrs", typ: "string", description: "Comma separated list of input addresses"},
+			{name: "out_addrs", typ: "string", description: "Comma separated list of output addresses"},
+			startTimeParam,
+			endTimeParam,
+			{name: "min_coins", typ: "string", description: "Minimum total output coins"},
+			{name: "max_coins", typ: "string", description: "Maximum total output coins"},
+		},
+		response: []daemon.TransactionResult{},
+		clients: []apiClientMethod{{
+			name:   "Transactions",
+			args:   []apiClientArg{{name: "addrs", param: "addrs", typ: "[]string"}},
+			result: (*[]daemon.TransactionResult)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/ledger",
+		method:  http.MethodGet,
+		tag:     "transaction",
+		summary: "Returns the incoming, outgoing and self transfer transactions of addresses",
+		params: []apiParam{
+			{name: "addrs", typ: "string", required: true, description: "Comma separated list of addresses"},
+			startTimeParam,
+			endTimeParam,
+			ledgerFormatParam,
+		},
+		response: LedgerResponse{},
+		clients: []apiClientMethod{{
+			name: "Ledger",
+			doc:  " to get the ledger of addresses as JSON",
+			args: []apiClientArg{
+				{name: "addrs", param: "addrs", typ: "[]string"},
+				{name: "startTime", param: "start_time", typ: "uint64"},
+				{name: "endTime", param: "end_time", typ: "uint64"},
+			},
+			result: (*LedgerResponse)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/injectTransaction",
+		method:  http.MethodPost,
+		tag:     "transaction",
+		summary: "Broadcasts a raw transaction",
+		body: struct {
+			Rawtx string `json:"rawtx"`
+		}{},
+		response: "",
+	},
+	{
+		path:     "/api/v1/resendUnconfirmedTxns",
+		method:   http.MethodGet,
+		tag:      "transaction",
+		summary:  "Rebroadcasts the unconfirmed transactions",
+		response: daemon.ResendResult{},
+		clients: []apiClientMethod{{
+			name:   "ResendUnconfirmedTransactions",
+			result: (*daemon.ResendResult)(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/rawtx",
+		method:   http.MethodGet,
+		tag:      "transaction",
+		summary:  "Returns a transaction encoded as hex",
+		params:   []apiParam{txidParam},
+		response: "",
+		clients: []apiClientMethod{{
+			name:   "RawTransaction",
+			args:   []apiClientArg{{name: "txid", param: "txid", typ: "string"}},
+			result: "",
+		}},
+	},
+	{
+		path:     "/api/v2/transaction/verify",
+		method:   http.MethodPost,
+		tag:      "transaction",
+		summary:  "Verifies an encoded transaction",
+		body:     VerifyTxnRequest{},
+		response: VerifyTxnResponse{},
+		v2:       true,
+	},
+	{
+		path:     "/api/v2/transaction/estimate",
+		method:   http.MethodPost,
+		tag:      "transaction",
+		summary:  "Estimates the fee of a transaction and how long it will take to be confirmed",
+		body:     createTransactionRequest{},
+		response: EstimateTransactionResponse{},
+		v2:       true,
+		clients: []apiClientMethod{{
+			name:   "EstimateTransaction",
+			doc:    ".\nWallet.ID can be omitted if Wallet.Addresses is set, and Wallet.Password is not required.",
+			args:   []apiClientArg{{name: "req", typ: "CreateTransactionRequest"}},
+			result: (*EstimateTransactionResponse)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/uxout",
+		method:  http.MethodGet,
+		tag:     "uxout",
+		summary: "Returns an unspent output by id",
+		params: []apiParam{
+			{name: "uxid", typ: "string", required: true, description: "Output id"},
+		},
+		response: historydb.UxOutJSON{},
+		clients: []apiClientMethod{{
+			name:   "UxOut",
+			args:   []apiClientArg{{name: "uxID", param: "uxid", typ: "string"}},
+			result: (*historydb.UxOutJSON)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/address_uxouts",
+		method:  http.MethodGet,
+		tag:     "uxout",
+		summary: "Returns the historical outputs of an address",
+		params: []apiParam{
+			{name: "address", typ: "string", required: true, description: "Address"},
+		},
+		response: []historydb.UxOutJSON{},
+		clients: []apiClientMethod{{
+			name:   "AddressUxOuts",
+			args:   []apiClientArg{{name: "addr", param: "address", typ: "string"}},
+			result: []*historydb.UxOutJSON(nil),
+		}},
+	},
+	{
+		path:     "/api/v2/address/verify",
+		method:   http.MethodPost,
+		tag:      "simple query",
+		summary:  "Verifies an address",
+		body:     VerifyAddressRequest{},
+		response: VerifyAddressResponse{},
+		v2:       true,
+	},
+	{
+		path:    "/api/v1/explorer/address",
+		method:  http.MethodGet,
+		tag:     "explorer",
+		summary: "Returns the transactions of an address with their inputs",
+		params: []apiParam{
+			{name: "address", typ: "string", required: true, description: "Address"},
+		},
+		response: []daemon.ReadableTransaction{},
+		clients: []apiClientMethod{{
+			name:   "AddressTransactions",
+			args:   []apiClientArg{{name: "addr", param: "address", typ: "string"}},
+			result: []daemon.ReadableTransaction(nil),
+		}},
+	},
+	{
+		path:     "/api/v1/coinSupply",
+		method:   http.MethodGet,
+		tag:      "coin supply",
+		summary:  "Returns the coin supply",
+		response: CoinSupply{},
+		clients: []apiClientMethod{{
+			name:   "CoinSupply",
+			result: (*CoinSupply)(nil),
+		}},
+	},
+	{
+		path:    "/api/v1/explorer/supplyHistory",
+		method:  http.MethodGet,
+		tag:     "coin supply",
+		summary: "Returns daily coin supply snapshots",
+		params: []apiParam{
+			{name: "start", typ: "integer", description: "Earliest snapshot time, as a unix timestamp"},
+			{name: "end", typ: "integer", description: "Latest snapshot time, as a unix timestamp"},
+		},
+		response: SupplyHistory{},
+	},
+	{
+		path:    "/api/v1/explorer/distribution",
+		method:  http.MethodGet,
+		tag:     "coin supply",
+		summary: "Returns the share of coins held by the top addresses",
+		params: []apiParam{
+			{name: "n", typ: "string", description: "Comma separated list of top N address counts"},
+			includeDistributionParam,
+		},
+		response: Distribution{},
+	},
+	{
+		path:    "/api/v1/richlist",
+		method:  http.MethodGet,
+		tag:     "coin supply",
+		summary: "Returns the addresses with the most coins",
+		params: []apiParam{
+			{name: "n", typ: "integer", description: "Number of addresses, defaults to 20"},
+			includeDistributionParam,
+		},
+		response: Richlist{},
+	},
+	{
+		path:    "/api/v1/addresscount",
+		method:  http.MethodGet,
+		tag:     "coin supply",
+		summary: "Returns the number of addresses with unspent outputs",
+		response: struct {
+			Count uint64 `json:"count"`
+		}{},
+	},
+}
+
+// newOpenAPISpec builds the OpenAPISpec of the registered routes.
+// Routes that are not documented by apiOperations are included without any operations.
+func newOpenAPISpec(routes []apiRoute) *OpenAPISpec {
+	schemas := openAPISchemas{}
+
+	spec := &OpenAPISpec{
+		OpenAPI: openAPIVersion,
+		Info: OpenAPIInfo{
+			Title:       "Skycoin REST API",
+			Description: "API tokens are only required if the node is run with -enable-api-auth",
+		},
+		Paths: make(map[string]OpenAPIPathItem, len(routes)),
+		Components: OpenAPIComponents{
+			Schemas: schemas,
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				openAPISecurityScheme: {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "API token created with the CLI command createAPIToken",
+				},
+			},
+		},
+	}
+
+	for _, r := range routes {
+		spec.Paths[r.path] = OpenAPIPathItem{}
+	}
+
+	for _, op := range documentedOperations() {
+		item, ok := spec.Paths[op.path]
+		if !ok {
+			continue
+		}
+
+		var scope Scope
+		for _, r := range routes {
+			if r.path == op.path {
+				scope = r.scope
+			}
+		}
+
+		item[strings.ToLower(op.method)] = op.build(schemas, scope)
+	}
+
+	return spec
+}
+
+// documentedOperations returns apiOperations and the v1 operations mirrored under /api/v2
+func documentedOperations() []apiOperation {
+	return append(append([]apiOperation{}, apiOperations...), v2Operations()...)
+}
+
+// build creates the OpenAPIOperation of an apiOperation
+func (op apiOperation) build(schemas openAPISchemas, scope Scope) *OpenAPIOperation {
+	o := &OpenAPIOperation{
+		OperationID: op.operationID(),
+		Summary:     op.summary,
+		Tags:        []string{op.tag},
+		Responses:   make(map[string]OpenAPIResponse),
+		Security: []map[string][]string{
+			{openAPISecurityScheme: {}},
+		},
+		Scope: scope,
+	}
+
+	if len(op.params) != 0 {
+		if op.method == http.MethodGet {
+			for _, p := range op.params {
+				o.Parameters = append(o.Parameters, OpenAPIParameter{
+					Name:        p.name,
+					In:          "query",
+					Description: p.description,
+					Required:    p.required,
+					Schema:      &OpenAPISchema{Type: p.typ},
+				})
+			}
+		} else {
+			form := &OpenAPISchema{
+				Type:       "object",
+				Properties: make(map[string]*OpenAPISchema, len(op.params)),
+			}
+			for _, p := range op.params {
+				form.Properties[p.name] = &OpenAPISchema{
+					Type:        p.typ,
+					Description: p.description,
+				}
+				if p.required {
+					form.Required = append(form.Required, p.name)
+				}
+			}
+
+			// v2 requests send the form values as a JSON object
+			contentType := "application/x-www-form-urlencoded"
+			if op.v2 {
+				contentType = "application/json"
+			}
+
+			o.RequestBody = &OpenAPIRequestBody{
+				Required: len(form.Required) != 0,
+				Content: map[string]OpenAPIMediaType{
+					contentType: {Schema: form},
+				},
+			}
+		}
+	}
+
+	if op.body != nil {
+		o.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content: map[string]OpenAPIMediaType{
+				"application/json": {Schema: schemas.schemaFor(reflect.TypeOf(op.body))},
+			},
+		}
+	}
+
+	ok := OpenAPIResponse{
+		Description: "OK",
+	}
+
+	if op.response != nil {
+		schema := schemas.schemaFor(reflect.TypeOf(op.response))
+		if op.v2 {
+			schema = &OpenAPISchema{
+				Type: "object",
+				Properties: map[string]*OpenAPISchema{
+					"data":  schema,
+					"error": schemas.schemaFor(reflect.TypeOf(HTTPError{})),
+				},
+			}
+		}
+
+		ok.Content = map[string]OpenAPIMediaType{
+			"application/json": {Schema: schema},
+		}
+	}
+
+	o.Responses["200"] = ok
+
+	if op.v2 {
+		o.Responses["default"] = OpenAPIResponse{
+			Description: "Error",
+			Content: map[string]OpenAPIMediaType{
+				"application/json": {Schema: schemas.schemaFor(reflect.TypeOf(HTTPResponse{}))},
+			},
+		}
+	} else {
+		o.Responses["default"] = OpenAPIResponse{
+			Description: "Error, with the status text and message in the body",
+			Content: map[string]OpenAPIMediaType{
+				"text/plain": {Schema: &OpenAPISchema{Type: "string"}},
+			},
+		}
+	}
+
+	return o
+}
+
+// operationID returns the operation's id, derived from its method and path, e.g. getWalletBalance
+func (op apiOperation) operationID() string {
+	p := strings.TrimPrefix(op.path, "/api/")
+	parts := strings.FieldsFunc(p, func(r rune) bool {
+		return r == '/' || r == '_' || r == '.'
+	})
+
+	id := strings.ToLower(op.method)
+	for _, s := range parts[1:] {
+		id += strings.ToUpper(s[:1]) + s[1:]
+	}
+
+	if parts[0] != "v1" {
+		id += strings.ToUpper(parts[0])
+	}
+
+	return id
+}
+
+// openAPISchemas are the named schemas of an OpenAPISpec, keyed by <package>.<type>
+type openAPISchemas map[string]*OpenAPISchema
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaFor returns the schema of the JSON encoding of t. Named structs are added to the schemas and referenced
+func (s openAPISchemas) schemaFor(t reflect.Type) *OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &OpenAPISchema{}
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return &OpenAPISchema{}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return &OpenAPISchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: t.Kind().String()}
+	case reflect.Float32, reflect.Float64:
+		return &OpenAPISchema{Type: "number"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: s.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: s.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := s[name]; !ok {
+			// Add a placeholder before building the schema, for recursive types
+			s[name] = &OpenAPISchema{}
+			*s[name] = *s.structSchema(t)
+		}
+
+		return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+	default:
+		return &OpenAPISchema{}
+	}
+}
+
+// structSchema returns the object schema of a struct, following the encoding/json rules for field names
+func (s openAPISchemas) structSchema(t reflect.Type) *OpenAPISchema {
+	schema := &OpenAPISchema{
+		Type:       "object",
+		Properties: make(map[string]*OpenAPISchema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+		name := opts[0]
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		// Fields of embedded structs are promoted
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded := s.structSchema(ft)
+			for k, v := range embedded.Properties {
+				if _, ok := schema.Properties[k]; !ok {
+					schema.Properties[k] = v
+				}
+			}
+			continue
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := s.schemaFor(f.Type)
+		for _, o := range opts[1:] {
+			if o == "string" {
+				fs = &OpenAPISchema{Type: "string"}
+			}
+		}
+
+		schema.Properties[name] = fs
+	}
+
+	return schema
+}
+
+// Returns the OpenAPI specification of the API
+// URI: /api/v1/openapi.json
+// Method: GET
+func openAPIHandler(gateway Gatewayer, spec *OpenAPISpec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		s := *spec
+		s.Info.Version = gateway.GetBuildInfo().Version
+
+		wh.SendJSONOr500(logger, w, s)
+	}
+}
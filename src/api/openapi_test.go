@@ -0,0 +1,379 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+const clientGenFilename = "client_gen.go"
+
+var updateClient = flag.Bool("update-client", false, "regenerate "+clientGenFilename+" from apiOperations")
+
+// newOpenAPITestMux creates a server mux with all of the optional endpoints enabled
+func newOpenAPITestMux(gateway Gatewayer) *http.ServeMux {
+	return newServerMux(muxConfig{
+		host:            configuredHost,
+		appLoc:          ".",
+		enableJSON20RPC: true,
+	}, gateway, &CSRFStore{}, nil)
+}
+
+func getOpenAPISpec(t *testing.T) *OpenAPISpec {
+	gateway := &GatewayerMock{}
+	gateway.On("GetBuildInfo").Return(visor.BuildInfo{Version: "0.24.0"})
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	newOpenAPITestMux(gateway).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var spec OpenAPISpec
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&spec))
+	return &spec
+}
+
+func TestOpenAPISpecRoutes(t *testing.T) {
+	spec := getOpenAPISpec(t)
+
+	require.Equal(t, "0.24.0", spec.Info.Version)
+
+	// Every registered route must be documented
+	for p, item := range spec.Paths {
+		require.NotEmpty(t, item, "route %s is registered in newServerMux but is not documented in apiOperations", p)
+
+		for _, op := range item {
+			require.NotEmpty(t, op.Scope, "route %s has no scope", p)
+			require.NotEmpty(t, op.OperationID)
+		}
+	}
+
+	// Every documented operation must be registered
+	for _, op := range documentedOperations() {
+		item, ok := spec.Paths[op.path]
+		require.True(t, ok, "%s is documented in apiOperations but is not registered in newServerMux", op.path)
+		require.NotNil(t, item[strings.ToLower(op.method)], "%s %s is not in the spec", op.method, op.path)
+	}
+
+	// Operation ids are unique
+	ids := make(map[string]struct{})
+	for _, op := range documentedOperations() {
+		id := op.operationID()
+		_, ok := ids[id]
+		require.False(t, ok, "duplicate operation id %s", id)
+		ids[id] = struct{}{}
+	}
+
+	require.Equal(t, ScopeWalletSpend, spec.Paths["/api/v1/wallet/spend"]["post"].Scope)
+	require.Equal(t, ScopeAdmin, spec.Paths["/api/v1/network/connections"]["get"].Scope)
+	require.Equal(t, "getWalletBalance", spec.Paths["/api/v1/wallet/balance"]["get"].OperationID)
+	require.Equal(t, "postTransactionVerifyV2", spec.Paths["/api/v2/transaction/verify"]["post"].OperationID)
+}
+
+func TestOpenAPISpecMethods(t *testing.T) {
+	methods := []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}
+
+	documented := make(map[string]map[string]bool)
+	for _, op := range documentedOperations() {
+		if documented[op.path] == nil {
+			documented[op.path] = make(map[string]bool)
+		}
+		documented[op.path][op.method] = true
+	}
+
+	for p, ms := range documented {
+		// The JSON-RPC handler reports errors in the JSON-RPC response instead of the status code
+		if p == "/api/v1/webrpc" {
+			continue
+		}
+
+		for _, method := range methods {
+			t.Run(method+" "+p, func(t *testing.T) {
+				// The gateway has no expectations, so a handler that accepts the method will panic
+				// when it calls the gateway. This is not a 405, and is treated as accepted
+				var status int
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							status = http.StatusOK
+						}
+					}()
+
+					req, err := http.NewRequest(method, p, nil)
+					require.NoError(t, err)
+
+					rr := httptest.NewRecorder()
+					newOpenAPITestMux(&GatewayerMock{}).ServeHTTP(rr, req)
+					status = rr.Code
+				}()
+
+				if ms[method] {
+					require.NotEqual(t, http.StatusMethodNotAllowed, status, "the handler does not accept the documented method")
+				} else {
+					require.Equal(t, http.StatusMethodNotAllowed, status, "the handler accepts an undocumented method")
+				}
+			})
+		}
+	}
+}
+
+func TestOpenAPISpecSchemas(t *testing.T) {
+	spec := getOpenAPISpec(t)
+
+	block := spec.Components.Schemas["visor.ReadableBlock"]
+	require.NotNil(t, block)
+	require.Equal(t, "object", block.Type)
+	for _, k := range []string{"header", "body", "size"} {
+		require.Contains(t, block.Properties, k)
+	}
+	require.Equal(t, "#/components/schemas/visor.ReadableBlockHeader", block.Properties["header"].Ref)
+
+	// Fields of embedded structs are promoted
+	balance := spec.Components.Schemas["api.BalanceResponse"]
+	require.NotNil(t, balance)
+	for _, k := range []string{"confirmed", "predicted", "addresses"} {
+		require.Contains(t, balance.Properties, k)
+	}
+
+	// v2 responses are wrapped
+	verify := spec.Paths["/api/v2/address/verify"]["post"].Responses["200"].Content["application/json"].Schema
+	require.Equal(t, "#/components/schemas/api.VerifyAddressResponse", verify.Properties["data"].Ref)
+	require.Equal(t, "#/components/schemas/api.HTTPError", verify.Properties["error"].Ref)
+
+	// GET parameters are in the query, POST parameters in a form
+	block2 := spec.Paths["/api/v1/block"]["get"]
+	require.Len(t, block2.Parameters, 2)
+	require.Equal(t, "query", block2.Parameters[0].In)
+
+	spend := spec.Paths["/api/v1/wallet/spend"]["post"]
+	form := spend.RequestBody.Content["application/x-www-form-urlencoded"].Schema
+	require.Equal(t, []string{"id", "dst", "coins"}, form.Required)
+
+	// All references resolve
+	var checkRefs func(s *OpenAPISchema)
+	checkRefs = func(s *OpenAPISchema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+			require.Contains(t, spec.Components.Schemas, name)
+		}
+		checkRefs(s.Items)
+		checkRefs(s.AdditionalProperties)
+		for _, p := range s.Properties {
+			checkRefs(p)
+		}
+	}
+
+	for _, s := range spec.Components.Schemas {
+		checkRefs(s)
+	}
+	for _, item := range spec.Paths {
+		for _, op := range item {
+			if op.RequestBody != nil {
+				for _, c := range op.RequestBody.Content {
+					checkRefs(c.Schema)
+				}
+			}
+			for _, r := range op.Responses {
+				for _, c := range r.Content {
+					checkRefs(c.Schema)
+				}
+			}
+		}
+	}
+}
+
+// TestGenerateClient fails if client_gen.go is out of date. Run with -update-client to regenerate it
+func TestGenerateClient(t *testing.T) {
+	src, err := generateClient()
+	require.NoError(t, err)
+
+	if *updateClient {
+		require.NoError(t, ioutil.WriteFile(clientGenFilename, src, 0644))
+		return
+	}
+
+	current, err := ioutil.ReadFile(clientGenFilename)
+	require.NoError(t, err)
+	require.Equal(t, string(src), string(current), "%s is out of date, run go test ./src/api -run TestGenerateClient -update-client", clientGenFilename)
+}
+
+// clientTypeName returns the name of t in the api package, and adds the packages it uses to imports
+func clientTypeName(t reflect.Type, imports map[string]struct{}) string {
+	for e := t; ; e = e.Elem() {
+		if e.Kind() != reflect.Ptr && e.Kind() != reflect.Slice {
+			if e.PkgPath() != "" && !strings.HasSuffix(e.PkgPath(), "/src/api") {
+				imports[e.PkgPath()] = struct{}{}
+			}
+			break
+		}
+	}
+
+	return strings.Replace(t.String(), "api.", "", -1)
+}
+
+// generateClient generates the Client methods of apiOperations
+func generateClient() ([]byte, error) {
+	var buf bytes.Buffer
+	imports := make(map[string]struct{})
+
+	var ops []apiOperation
+	for _, op := range apiOperations {
+		if len(op.clients) != 0 {
+			ops = append(ops, op)
+		}
+	}
+
+	for _, op := range ops {
+		for _, m := range op.clients {
+			generateClientMethod(&buf, op, m, imports)
+		}
+	}
+
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var out bytes.Buffer
+	fmt.Fprintln(&out, "// Code generated by TestGenerateClient from apiOperations. DO NOT EDIT.")
+	fmt.Fprintln(&out)
+	fmt.Fprintln(&out, "package api")
+	fmt.Fprintln(&out)
+	// Standard library imports are grouped before the other imports
+	fmt.Fprintln(&out, "import (")
+	for _, p := range paths {
+		if !strings.Contains(p, ".") {
+			fmt.Fprintf(&out, "\t%q\n", p)
+		}
+	}
+	fmt.Fprintln(&out)
+	for _, p := range paths {
+		if strings.Contains(p, ".") {
+			fmt.Fprintf(&out, "\t%q\n", p)
+		}
+	}
+	fmt.Fprintln(&out, ")")
+	out.Write(buf.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+func generateClientMethod(w *bytes.Buffer, op apiOperation, m apiClientMethod, imports map[string]struct{}) {
+	var args []string
+	var bodyArg string
+	var values []apiClientArg
+	for _, a := range m.args {
+		args = append(args, a.name+" "+a.typ)
+		if a.param == "" {
+			bodyArg = a.name
+		} else {
+			values = append(values, a)
+		}
+	}
+
+	var resultType, zero string
+	var resultPtr bool
+	if m.result != nil {
+		t := reflect.TypeOf(m.result)
+		resultType = clientTypeName(t, imports)
+		switch t.Kind() {
+		case reflect.Ptr:
+			resultPtr = true
+			zero = "nil"
+		case reflect.Slice:
+			zero = "nil"
+		default:
+			zero = fmt.Sprintf("%#v", reflect.Zero(t).Interface())
+		}
+	}
+
+	fmt.Fprintf(w, "\n// %s makes a request to %s %s%s\n", m.name, op.method, op.path, strings.Replace(m.doc, "\n", "\n// ", -1))
+	if resultType == "" {
+		fmt.Fprintf(w, "func (c *Client) %s(%s) error {\n", m.name, strings.Join(args, ", "))
+	} else {
+		fmt.Fprintf(w, "func (c *Client) %s(%s) (%s, error) {\n", m.name, strings.Join(args, ", "), resultType)
+	}
+
+	endpoint := fmt.Sprintf("%q", op.path)
+	if len(values) != 0 {
+		imports["net/url"] = struct{}{}
+		fmt.Fprintln(w, "v := url.Values{}")
+		for _, a := range values {
+			switch a.typ {
+			case "string":
+				fmt.Fprintf(w, "v.Add(%q, %s)\n", a.param, a.name)
+			case "[]string":
+				imports["strings"] = struct{}{}
+				fmt.Fprintf(w, "v.Add(%q, strings.Join(%s, \",\"))\n", a.param, a.name)
+			default:
+				imports["fmt"] = struct{}{}
+				fmt.Fprintf(w, "v.Add(%q, fmt.Sprint(%s))\n", a.param, a.name)
+			}
+		}
+
+		if op.method == http.MethodGet {
+			fmt.Fprintf(w, "endpoint := %q + v.Encode()\n", op.path+"?")
+			endpoint = "endpoint"
+		}
+		fmt.Fprintln(w)
+	}
+
+	respArg := "nil"
+	if resultType != "" {
+		fmt.Fprintf(w, "var resp %s\n", strings.TrimPrefix(resultType, "*"))
+		respArg = "&resp"
+	}
+
+	ret := "resp"
+	if resultPtr {
+		ret = "&resp"
+	}
+
+	var call string
+	switch {
+	case op.method == http.MethodGet:
+		call = fmt.Sprintf("c.Get(%s, %s)", endpoint, respArg)
+	case op.v2:
+		call = fmt.Sprintf("c.PostJSONV2(%s, %s, %s)", endpoint, bodyArg, respArg)
+	case bodyArg != "":
+		call = fmt.Sprintf("c.PostJSON(%s, %s, %s)", endpoint, bodyArg, respArg)
+	default:
+		imports["strings"] = struct{}{}
+		form := "v.Encode()"
+		if len(values) == 0 {
+			form = `""`
+		}
+		call = fmt.Sprintf("c.PostForm(%s, strings.NewReader(%s), %s)", endpoint, form, respArg)
+	}
+
+	switch {
+	case resultType == "":
+		fmt.Fprintf(w, "return %s\n", call)
+	case op.v2:
+		// The v2 API may respond with an error and data
+		fmt.Fprintf(w, "ok, err := %s\nif ok {\nreturn %s, err\n}\nreturn %s, err\n", call, ret, zero)
+	default:
+		fmt.Fprintf(w, "if err := %s; err != nil {\nreturn %s, err\n}\nreturn %s, nil\n", call, zero, ret)
+	}
+
+	fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	wh "github.com/skycoin/skycoin/src/util/http" //http,json helpers
+)
+
+// rateLimitPruneInterval is how often clients with a full bucket are forgotten
+const rateLimitPruneInterval = time.Minute
+
+// RateLimitConfig configures the per-client rate limits of the API.
+// Clients are identified by their API token if API authentication is enabled, otherwise by their IP address.
+type RateLimitConfig struct {
+	// Requests per second allowed per client, for all endpoints. 0 disables the limit
+	Rate float64
+	// Number of requests a client can make at once, before being limited to Rate
+	Burst int
+	// Requests per second allowed per client, for the expensive endpoints. 0 disables the limit
+	ExpensiveRate float64
+	// Number of requests a client can make at once to the expensive endpoints, before being limited to ExpensiveRate
+	ExpensiveBurst int
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter is a token bucket rate limiter with one bucket per client
+type RateLimiter struct {
+	rate      float64
+	burst     int
+	buckets   map[string]*rateBucket
+	throttled uint64
+	lastPrune time.Time
+	now       func() time.Time
+	sync.Mutex
+}
+
+// RateLimitStats are the statistics of a RateLimiter
+type RateLimitStats struct {
+	Rate      float64 `json:"requests_per_second"`
+	Burst     int     `json:"burst"`
+	Clients   int     `json:"clients"`
+	Throttled uint64  `json:"throttled"`
+}
+
+// NewRateLimiter creates a RateLimiter that allows rate requests per second per client, with bursts of burst requests.
+// Returns nil if rate is 0, which disables rate limiting.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*rateBucket),
+		now:     time.Now,
+	}
+}
+
+// SetLimit changes the rate and burst of the RateLimiter. rate must be greater than 0.
+// The buckets of the clients are kept, and refill at the new rate.
+func (l *RateLimiter) SetLimit(rate float64, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	if rate != l.rate || burst != l.burst {
+		logger.Infof("API rate limit changed from %v/s burst %d to %v/s burst %d", l.rate, l.burst, rate, burst)
+	}
+
+	l.rate = rate
+	l.burst = burst
+}
+
+// Allow takes a token from the client's bucket. If the bucket is empty,
+// returns false and the time until a token is available
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	return l.allowN(client, 1)
+}
+
+// allowN takes n tokens from the client's bucket. If the bucket has fewer than n tokens,
+// none are taken, and returns false and the time until n tokens are available.
+// n must not be greater than the burst
+func (l *RateLimiter) allowN(client string, n int) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &rateBucket{
+			tokens: float64(l.burst),
+			last:   now,
+		}
+		l.buckets[client] = b
+	}
+
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < float64(n) {
+		l.throttled++
+		wait := time.Duration((float64(n) - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens -= float64(n)
+	return true, 0
+}
+
+// prune forgets the clients whose buckets have refilled, to bound memory use
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+
+	l.lastPrune = now
+
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= float64(l.burst) {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// Stats returns the statistics of the RateLimiter
+func (l *RateLimiter) Stats() RateLimitStats {
+	l.Lock()
+	defer l.Unlock()
+
+	return RateLimitStats{
+		Rate:      l.rate,
+		Burst:     l.burst,
+		Clients:   len(l.buckets),
+		Throttled: l.throttled,
+	}
+}
+
+// rateLimitClient identifies the client of a request, by its API token if useToken is true, otherwise by its IP address
+func rateLimitClient(r *http.Request, useToken bool) string {
+	if useToken {
+		header := r.Header.Get("Authorization")
+		if strings.HasPrefix(header, authScheme+" ") {
+			token := strings.TrimSpace(strings.TrimPrefix(header, authScheme+" "))
+			return "token:" + hashAPIToken(token)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// RateLimitCheck responds with 429 Too Many Requests if the client has exceeded the limiter's rate.
+// The Retry-After header is set to the number of seconds until the client can make a request.
+// If limiter is nil, rate limiting is disabled and all requests are allowed.
+// If useToken is true, clients are identified by their API token, which must have been verified already.
+func RateLimitCheck(limiter *RateLimiter, useToken bool, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limiter != nil && !checkRateLimit(w, r, limiter, rateLimitClient(r, useToken), 1) {
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// checkRateLimit takes n tokens from the client's bucket. If the bucket does not have them,
+// responds with 429 Too Many Requests and returns false
+func checkRateLimit(w http.ResponseWriter, r *http.Request, limiter *RateLimiter, client string, n int) bool {
+	ok, wait := limiter.allowN(client, n)
+	if ok {
+		return true
+	}
+
+	retryAfter := int64(math.Ceil(wait.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	logger.Warningf("Rate limit exceeded by %s for %s", client, r.URL.Path)
+	w.Header().Set("Retry-After", fmt.Sprint(retryAfter))
+	wh.Error429(w, "")
+	return false
+}
+
+// RateLimitResponse is returned by GET /api/v1/ratelimit
+type RateLimitResponse struct {
+	Enabled   bool            `json:"enabled"`
+	Default   *RateLimitStats `json:"default"`
+	Expensive *RateLimitStats `json:"expensive"`
+}
+
+// Returns the rate limits and the number of throttled requests
+// URI: /api/v1/ratelimit
+// Method: GET
+func rateLimitHandler(limiter, expensiveLimiter *RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		var resp RateLimitResponse
+
+		if limiter != nil {
+			s := limiter.Stats()
+			resp.Default = &s
+		}
+
+		if expensiveLimiter != nil {
+			s := expensiveLimiter.Stats()
+			resp.Expensive = &s
+		}
+
+		resp.Enabled = resp.Default != nil || resp.Expensive != nil
+
+		wh.SendJSONOr500(logger, w, resp)
+	}
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	require.Nil(t, NewRateLimiter(0, 10))
+
+	l := NewRateLimiter(1, 0)
+	require.NotNil(t, l)
+	require.Equal(t, 1, l.burst)
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Unix(1539939600, 0)
+	l := NewRateLimiter(2, 3)
+	l.now = func() time.Time {
+		return now
+	}
+
+	// The burst is allowed at once
+	for i := 0; i < 3; i++ {
+		ok, wait := l.Allow("a")
+		require.True(t, ok)
+		require.Equal(t, time.Duration(0), wait)
+	}
+
+	ok, wait := l.Allow("a")
+	require.False(t, ok)
+	require.Equal(t, time.Millisecond*500, wait)
+
+	// Other clients have their own bucket
+	ok, _ = l.Allow("b")
+	require.True(t, ok)
+
+	// Tokens are refilled at the rate
+	now = now.Add(time.Millisecond * 500)
+	ok, _ = l.Allow("a")
+	require.True(t, ok)
+	ok, _ = l.Allow("a")
+	require.False(t, ok)
+
+	stats := l.Stats()
+	require.Equal(t, RateLimitStats{
+		Rate:      2,
+		Burst:     3,
+		Clients:   2,
+		Throttled: 2,
+	}, stats)
+
+	// Clients with a full bucket are pruned
+	now = now.Add(rateLimitPruneInterval)
+	ok, _ = l.Allow("c")
+	require.True(t, ok)
+	require.Equal(t, 1, l.Stats().Clients)
+}
+
+func TestRateLimiterAllowN(t *testing.T) {
+	now := time.Unix(1539939600, 0)
+	l := NewRateLimiter(2, 3)
+	l.now = func() time.Time {
+		return now
+	}
+
+	ok, wait := l.allowN("a", 2)
+	require.True(t, ok)
+	require.Equal(t, time.Duration(0), wait)
+
+	// No tokens are taken if the bucket does not have enough
+	ok, wait = l.allowN("a", 2)
+	require.False(t, ok)
+	require.Equal(t, time.Millisecond*500, wait)
+
+	ok, _ = l.Allow("a")
+	require.True(t, ok)
+
+	now = now.Add(time.Second)
+	ok, _ = l.allowN("a", 2)
+	require.True(t, ok)
+	require.Equal(t, uint64(1), l.Stats().Throttled)
+}
+
+func TestRateLimitCheck(t *testing.T) {
+	store, teardown := setupTokenStore(t)
+	defer teardown()
+
+	token, _, err := store.CreateToken("read", []Scope{ScopeRead})
+	require.NoError(t, err)
+	token2, _, err := store.CreateToken("read2", []Scope{ScopeRead})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		tokens    *TokenStore
+		endpoint  string
+		requests  []string
+		remoteIPs []string
+		status    []int
+	}{
+		{
+			name:      "by ip",
+			endpoint:  "/api/v1/version",
+			remoteIPs: []string{"1.2.3.4:1000", "1.2.3.4:1001", "1.2.3.4:1002", "5.6.7.8:1000"},
+			status:    []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:      "by token",
+			tokens:    store,
+			endpoint:  "/api/v1/version",
+			requests:  []string{token, token, token, token2},
+			remoteIPs: []string{"1.2.3.4:1000", "1.2.3.4:1000", "1.2.3.4:1000", "1.2.3.4:1000"},
+			status:    []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:      "expensive endpoint",
+			endpoint:  "/api/v1/last_blocks?num=1",
+			remoteIPs: []string{"1.2.3.4:1000", "1.2.3.4:1000", "1.2.3.4:1000"},
+			// The second request is throttled by the expensive limiter and the third by the default limiter
+			status: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &GatewayerMock{}
+			gateway.On("GetBuildInfo").Return(visor.BuildInfo{Version: "0.24.0"})
+			gateway.On("GetLastBlocks", uint64(1)).Return(&visor.ReadableBlocks{}, nil)
+
+			handler := newServerMux(muxConfig{
+				host:                 configuredHost,
+				appLoc:               ".",
+				tokens:               tc.tokens,
+				rateLimiter:          NewRateLimiter(0.001, 2),
+				expensiveRateLimiter: NewRateLimiter(0.001, 1),
+			}, gateway, &CSRFStore{}, nil)
+
+			for i, ip := range tc.remoteIPs {
+				req, err := http.NewRequest(http.MethodGet, tc.endpoint, nil)
+				require.NoError(t, err)
+				req.RemoteAddr = ip
+
+				if tc.requests != nil {
+					req.Header.Set("Authorization", "Bearer "+tc.requests[i])
+				}
+
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+
+				require.Equal(t, tc.status[i], rr.Code, "request %d", i)
+				if tc.status[i] == http.StatusTooManyRequests {
+					require.Equal(t, "429 Too Many Requests\n", rr.Body.String())
+					require.NotEmpty(t, rr.Header().Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitHandler(t *testing.T) {
+	gateway := &GatewayerMock{}
+	gateway.On("GetBuildInfo").Return(visor.BuildInfo{Version: "0.24.0"})
+
+	limiter := NewRateLimiter(0.001, 1)
+	handler := newServerMux(muxConfig{
+		host:        configuredHost,
+		appLoc:      ".",
+		rateLimiter: limiter,
+	}, gateway, &CSRFStore{}, nil)
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodGet, "/api/v1/version", nil)
+		require.NoError(t, err)
+		req.RemoteAddr = "1.2.3.4:1000"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// The stats request is made by a different client, so that it is not throttled
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/ratelimit", nil)
+	require.NoError(t, err)
+	req.RemoteAddr = "5.6.7.8:1000"
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp RateLimitResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.True(t, resp.Enabled)
+	require.Nil(t, resp.Expensive)
+	require.Equal(t, &RateLimitStats{
+		Rate:      0.001,
+		Burst:     1,
+		Clients:   2,
+		Throttled: 1,
+	}, resp.Default)
+}
+
+func TestRequestLimits(t *testing.T) {
+	addr := testutil.MakeAddress().String()
+	addrs := addr + "," + testutil.MakeAddress().String()
+
+	cases := []struct {
+		name     string
+		endpoint string
+		status   int
+		err      string
+	}{
+		{
+			name:     "blocks range too large",
+			endpoint: "/api/v1/blocks?start=1&end=10",
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - Block range is too large, at most 5 blocks can be requested",
+		},
+		{
+			name:     "last_blocks num too large",
+			endpoint: "/api/v1/last_blocks?num=6",
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - num is too large, at most 5 blocks can be requested",
+		},
+		{
+			name:     "balance too many addresses",
+			endpoint: "/api/v1/balance?addrs=" + addrs,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - too many addresses, at most 1 addresses can be requested",
+		},
+		{
+			name:     "outputs too many addresses",
+			endpoint: "/api/v1/outputs?addrs=" + addrs,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - too many addresses, at most 1 addresses can be requested",
+		},
+		{
+			name:     "transactions too many addresses",
+			endpoint: "/api/v1/transactions?addrs=" + addrs,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - too many addresses, at most 1 addresses can be requested",
+		},
+		{
+			name:     "transactions too many addresses in filters",
+			endpoint: "/api/v1/transactions?addrs=" + addr + "&in_addrs=" + addr + "&out_addrs=" + addr,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - too many addresses, at most 1 addresses can be requested",
+		},
+		{
+			name:     "transactions too many in_addrs",
+			endpoint: "/api/v1/transactions?in_addrs=" + addrs,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - too many addresses, at most 1 addresses can be requested",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &GatewayerMock{}
+
+			req, err := http.NewRequest(http.MethodGet, tc.endpoint, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(muxConfig{
+				host:                configuredHost,
+				appLoc:              ".",
+				maxBlockRange:       5,
+				maxRequestAddresses: 1,
+			}, gateway, &CSRFStore{}, nil)
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			require.Equal(t, tc.err+"\n", rr.Body.String())
+		})
+	}
+}
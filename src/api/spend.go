@@ -255,6 +255,7 @@ func NewCreatedTransactionInput(out wallet.UxBalance) (*CreatedTransactionInput,
 // createTransactionRequest is sent to /wallet/transaction
 type createTransactionRequest struct {
 	IgnoreUnconfirmed bool                           `json:"ignore_unconfirmed"`
+	SpendUnconfirmed  bool                           `json:"spend_unconfirmed"`
 	HoursSelection    hoursSelection                 `json:"hours_selection"`
 	Wallet            createTransactionRequestWallet `json:"wallet"`
 	ChangeAddress     *wh.Address                    `json:"change_address,omitempty"`
@@ -285,6 +286,12 @@ type receiver struct {
 
 // Validate validates createTransactionRequest data
 func (r createTransactionRequest) Validate() error {
+	return r.validate(true)
+}
+
+// validate validates createTransactionRequest data. wallet.id is only required if walletRequired,
+// otherwise either wallet.id or wallet.addresses is required
+func (r createTransactionRequest) validate(walletRequired bool) error {
 	switch r.HoursSelection.Type {
 	case wallet.HoursSelectionTypeAuto:
 		for i, to := range r.To {
@@ -340,7 +347,13 @@ func (r createTransactionRequest) Validate() error {
 	}
 
 	if r.Wallet.ID == "" {
-		return errors.New("missing wallet.id")
+		if walletRequired {
+			return errors.New("missing wallet.id")
+		}
+
+		if len(r.Wallet.Addresses) == 0 {
+			return errors.New("missing wallet.id or wallet.addresses")
+		}
 	}
 
 	addressMap := make(map[cipher.Address]struct{}, len(r.Wallet.Addresses))
@@ -454,6 +467,7 @@ func (r createTransactionRequest) ToWalletParams() wallet.CreateTransactionParam
 
 	return wallet.CreateTransactionParams{
 		IgnoreUnconfirmed: r.IgnoreUnconfirmed,
+		SpendUnconfirmed:  r.SpendUnconfirmed,
 		HoursSelection: wallet.HoursSelection{
 			Type:        r.HoursSelection.Type,
 			Mode:        r.HoursSelection.Mode,
@@ -467,6 +481,7 @@ func (r createTransactionRequest) ToWalletParams() wallet.CreateTransactionParam
 
 func createTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			wh.Error405(w)
 			return
@@ -493,6 +508,7 @@ func createTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 
 		txn, inputs, err := gateway.CreateTransaction(params.ToWalletParams())
 		if err != nil {
+			setErrorCode(r, err)
 			switch err.(type) {
 			case wallet.Error:
 				switch err {
@@ -528,3 +544,139 @@ func createTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 		wh.SendJSONOr500(logger, w, txnResp)
 	}
 }
+
+// FeeRateDistribution summarizes the fee rates of the unconfirmed transactions, in coin hours burned per byte
+type FeeRateDistribution struct {
+	Count     int             `json:"count"`
+	Size      int             `json:"size"`
+	Min       decimal.Decimal `json:"min"`
+	Quartile1 decimal.Decimal `json:"quartile1"`
+	Median    decimal.Decimal `json:"median"`
+	Quartile3 decimal.Decimal `json:"quartile3"`
+	Max       decimal.Decimal `json:"max"`
+}
+
+// NewFeeRateDistribution creates a FeeRateDistribution
+func NewFeeRateDistribution(d visor.FeeRateDistribution) FeeRateDistribution {
+	return FeeRateDistribution{
+		Count:     d.Count,
+		Size:      d.Size,
+		Min:       d.Min,
+		Quartile1: d.Quartile1,
+		Median:    d.Median,
+		Quartile3: d.Quartile3,
+		Max:       d.Max,
+	}
+}
+
+// EstimateTransactionResponse is returned by /api/v2/transaction/estimate
+type EstimateTransactionResponse struct {
+	// The transaction is not signed, its txid and output uxids change when it is signed
+	Transaction     CreatedTransaction  `json:"transaction"`
+	Size            int                 `json:"size"`
+	FeeRate         decimal.Decimal     `json:"fee_per_byte"`
+	SizeAhead       int                 `json:"pending_size_ahead"`
+	BlocksToConfirm int                 `json:"blocks_to_confirm"`
+	Pending         FeeRateDistribution `json:"pending_fee_per_byte"`
+}
+
+// NewEstimateTransactionResponse creates an EstimateTransactionResponse
+func NewEstimateTransactionResponse(e *visor.TransactionEstimate) (*EstimateTransactionResponse, error) {
+	cTxn, err := NewCreatedTransaction(&e.Transaction, e.Inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signatures are empty placeholders
+	cTxn.Sigs = []string{}
+
+	return &EstimateTransactionResponse{
+		Transaction:     *cTxn,
+		Size:            e.Transaction.Size(),
+		FeeRate:         e.FeeRate,
+		SizeAhead:       e.SizeAhead,
+		BlocksToConfirm: e.BlocksToConfirm,
+		Pending:         NewFeeRateDistribution(e.Unconfirmed),
+	}, nil
+}
+
+// Estimates a transaction without signing it.
+// Chooses the inputs and output hours the same way as /wallet/transaction, and reports
+// the fee rates of the unconfirmed transactions to gauge how soon it would be confirmed.
+// URI: /api/v2/transaction/estimate
+// Method: POST
+// Content-Type: application/json
+// Body: the same as /api/v1/wallet/transaction, except that wallet.id can be omitted if wallet.addresses
+// is provided, and wallet.password is not required
+func estimateTransactionHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != "application/json" {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var params createTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if err := params.validate(false); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		estimate, err := gateway.EstimateTransaction(params.ToWalletParams())
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case wallet.Error:
+				switch err {
+				case wallet.ErrWalletAPIDisabled:
+					resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+				case wallet.ErrWalletNotExist:
+					resp = newHTTPErrorResponseFromError(http.StatusNotFound, err)
+				default:
+					resp = newHTTPErrorResponseFromError(http.StatusBadRequest, err)
+				}
+			case blockdb.ErrUnspentNotExist:
+				resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			case visor.ErrTxnViolatesSoftConstraint,
+				visor.ErrTxnViolatesUserConstraint:
+				resp = NewHTTPErrorResponse(http.StatusUnprocessableEntity, err.Error())
+			default:
+				switch err {
+				case fee.ErrTxnNoFee,
+					fee.ErrTxnInsufficientCoinHours,
+					wallet.ErrSpendingUnconfirmed:
+					resp = newHTTPErrorResponseFromError(http.StatusBadRequest, err)
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		estimateResp, err := NewEstimateTransactionResponse(estimate)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, fmt.Sprintf("NewEstimateTransactionResponse failed: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: estimateResp,
+		})
+	}
+}
@@ -5,18 +5,21 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/testutil" //http,json helpers
 	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/visor/blockdb"
 	"github.com/skycoin/skycoin/src/wallet"
 )
@@ -901,3 +904,244 @@ func TestCreateTransaction(t *testing.T) {
 func newStrPtr(s string) *string {
 	return &s
 }
+
+func TestEstimateTransaction(t *testing.T) {
+	fromAddress := testutil.MakeAddress()
+	destinationAddress := testutil.MakeAddress()
+
+	txn := coin.Transaction{
+		Length:    183,
+		Type:      0,
+		InnerHash: testutil.RandSHA256(t),
+		Sigs:      make([]cipher.Sig, 1),
+		In:        []cipher.SHA256{testutil.RandSHA256(t)},
+		Out: []coin.TransactionOutput{
+			{
+				Address: destinationAddress,
+				Coins:   1e6,
+				Hours:   100,
+			},
+		},
+	}
+
+	inputs := []wallet.UxBalance{
+		{
+			Hash:           txn.In[0],
+			Time:           uint64(time.Now().UTC().Unix()),
+			BkSeq:          9999,
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        fromAddress,
+			Coins:          1e6,
+			Hours:          200,
+			InitialHours:   100,
+		},
+	}
+
+	rate := decimal.New(546, -3)
+	estimate := &visor.TransactionEstimate{
+		Transaction:     txn,
+		Inputs:          inputs,
+		Fee:             100,
+		FeeRate:         rate,
+		SizeAhead:       1024,
+		BlocksToConfirm: 1,
+		Unconfirmed: visor.FeeRateDistribution{
+			Count:     2,
+			Size:      1024,
+			Min:       decimal.New(1, 0),
+			Quartile1: decimal.New(1, 0),
+			Median:    decimal.New(1, 0),
+			Quartile3: decimal.New(2, 0),
+			Max:       decimal.New(2, 0),
+		},
+	}
+
+	createdTxn, err := NewCreatedTransaction(&txn, inputs)
+	require.NoError(t, err)
+	createdTxn.Sigs = []string{}
+
+	estimateResponse := EstimateTransactionResponse{
+		Transaction:     *createdTxn,
+		Size:            txn.Size(),
+		FeeRate:         rate,
+		SizeAhead:       1024,
+		BlocksToConfirm: 1,
+		Pending: FeeRateDistribution{
+			Count:     2,
+			Size:      1024,
+			Min:       decimal.New(1, 0),
+			Quartile1: decimal.New(1, 0),
+			Median:    decimal.New(1, 0),
+			Quartile3: decimal.New(2, 0),
+			Max:       decimal.New(2, 0),
+		},
+	}
+
+	addressesBody := fmt.Sprintf(`{
+		"hours_selection": {"type": "auto", "mode": "share", "share_factor": "0.5"},
+		"wallet": {"addresses": ["%s"]},
+		"to": [{"address": "%s", "coins": "1"}]
+	}`, fromAddress, destinationAddress)
+
+	walletBody := fmt.Sprintf(`{
+		"hours_selection": {"type": "manual"},
+		"wallet": {"id": "foo.wlt"},
+		"to": [{"address": "%s", "coins": "1", "hours": "100"}]
+	}`, destinationAddress)
+
+	missingWalletBody := fmt.Sprintf(`{
+		"hours_selection": {"type": "manual"},
+		"to": [{"address": "%s", "coins": "1", "hours": "100"}]
+	}`, destinationAddress)
+
+	tt := []struct {
+		name                     string
+		method                   string
+		body                     string
+		contentType              string
+		status                   int
+		gatewayEstimateResult    *visor.TransactionEstimate
+		gatewayEstimateErr       error
+		httpResponse             HTTPResponse
+		expectedEstimateResponse *EstimateTransactionResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "415",
+			method:       http.MethodPost,
+			body:         walletBody,
+			contentType:  "text/plain",
+			status:       http.StatusUnsupportedMediaType,
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, ""),
+		},
+		{
+			name:         "400 - invalid json",
+			method:       http.MethodPost,
+			body:         "{",
+			contentType:  "application/json",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "unexpected EOF"),
+		},
+		{
+			name:         "400 - missing wallet and addresses",
+			method:       http.MethodPost,
+			body:         missingWalletBody,
+			contentType:  "application/json",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "missing wallet.id or wallet.addresses"),
+		},
+		{
+			name:               "403 - wallet API disabled",
+			method:             http.MethodPost,
+			body:               walletBody,
+			contentType:        "application/json",
+			status:             http.StatusForbidden,
+			gatewayEstimateErr: wallet.ErrWalletAPIDisabled,
+			httpResponse:       NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:               "404 - wallet not found",
+			method:             http.MethodPost,
+			body:               walletBody,
+			contentType:        "application/json",
+			status:             http.StatusNotFound,
+			gatewayEstimateErr: wallet.ErrWalletNotExist,
+			httpResponse:       newHTTPErrorResponseFromError(http.StatusNotFound, wallet.ErrWalletNotExist),
+		},
+		{
+			name:               "400 - insufficient coin hours",
+			method:             http.MethodPost,
+			body:               walletBody,
+			contentType:        "application/json",
+			status:             http.StatusBadRequest,
+			gatewayEstimateErr: fee.ErrTxnInsufficientCoinHours,
+			httpResponse:       newHTTPErrorResponseFromError(http.StatusBadRequest, fee.ErrTxnInsufficientCoinHours),
+		},
+		{
+			name:               "422 - soft constraint violation",
+			method:             http.MethodPost,
+			body:               addressesBody,
+			contentType:        "application/json",
+			status:             http.StatusUnprocessableEntity,
+			gatewayEstimateErr: visor.NewErrTxnViolatesSoftConstraint(fee.ErrTxnInsufficientFee),
+			httpResponse:       NewHTTPErrorResponse(http.StatusUnprocessableEntity, visor.NewErrTxnViolatesSoftConstraint(fee.ErrTxnInsufficientFee).Error()),
+		},
+		{
+			name:               "500 - gateway error",
+			method:             http.MethodPost,
+			body:               addressesBody,
+			contentType:        "application/json",
+			status:             http.StatusInternalServerError,
+			gatewayEstimateErr: errors.New("EstimateTransaction failed"),
+			httpResponse:       NewHTTPErrorResponse(http.StatusInternalServerError, "EstimateTransaction failed"),
+		},
+		{
+			name:                     "200 - addresses",
+			method:                   http.MethodPost,
+			body:                     addressesBody,
+			contentType:              "application/json",
+			status:                   http.StatusOK,
+			gatewayEstimateResult:    estimate,
+			expectedEstimateResponse: &estimateResponse,
+		},
+		{
+			name:                     "200 - wallet",
+			method:                   http.MethodPost,
+			body:                     walletBody,
+			contentType:              "application/json",
+			status:                   http.StatusOK,
+			gatewayEstimateResult:    estimate,
+			expectedEstimateResponse: &estimateResponse,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := "/api/v2/transaction/estimate"
+			gateway := NewGatewayerMock()
+
+			var req createTransactionRequest
+			if err := json.Unmarshal([]byte(tc.body), &req); err == nil {
+				gateway.On("EstimateTransaction", req.ToWalletParams()).Return(tc.gatewayEstimateResult, tc.gatewayEstimateErr)
+			}
+
+			r, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			r.Header.Set("Content-Type", tc.contentType)
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, r)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, csrfStore, nil)
+			handler.ServeHTTP(rr, r)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "case: %s, handler returned wrong status code: got `%v` want `%v`",
+				tc.name, status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.NewDecoder(rr.Body).Decode(&rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if tc.expectedEstimateResponse == nil {
+				require.Nil(t, rsp.Data)
+			} else {
+				var estimateRsp EstimateTransactionResponse
+				err := json.Unmarshal(rsp.Data, &estimateRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, *tc.expectedEstimateResponse, estimateRsp)
+			}
+		})
+	}
+}
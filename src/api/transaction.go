@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/util/droplet"
 	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/wallet"
 
@@ -20,6 +22,7 @@ import (
 // Returns pending transactions
 func getPendingTxns(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -45,6 +48,146 @@ func getPendingTxns(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// PendingTxnsStats is returned by /pendingTxs/stats
+type PendingTxnsStats struct {
+	Count    uint64 `json:"count"`
+	Size     uint64 `json:"size"`
+	MaxSize  uint64 `json:"max_size"`
+	Evicted  uint64 `json:"evicted"`
+	Replaced uint64 `json:"replaced"`
+}
+
+// Returns the unconfirmed pool size, and the number of transactions evicted
+// from the full pool or replaced by a higher fee double spend since startup
+func getPendingTxnsStats(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		stats, err := gateway.GetUnconfirmedTxnPoolStats()
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, PendingTxnsStats{
+			Count:    stats.Count,
+			Size:     stats.Size,
+			MaxSize:  stats.MaxSize,
+			Evicted:  stats.Evicted,
+			Replaced: stats.Replaced,
+		})
+	}
+}
+
+// parseMaxAge parses the optional max_age duration parameter, 0 if not provided
+func parseMaxAge(r *http.Request) (time.Duration, error) {
+	v := r.FormValue("max_age")
+	if v == "" {
+		return 0, nil
+	}
+
+	maxAge, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid max_age value: %v", err)
+	}
+
+	if maxAge <= 0 {
+		return 0, errors.New("max_age must be positive")
+	}
+
+	return maxAge, nil
+}
+
+// Returns pending transactions received more than max_age ago.
+// If max_age is not provided, the node's configured max age is used.
+// URI: /api/v1/pendingTxs/expired
+// Method: GET
+// Args:
+//     max_age: duration, e.g. "72h" [optional]
+func getExpiredPendingTxns(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		maxAge, err := parseMaxAge(r)
+		if err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		txns, err := gateway.GetExpiredUnconfirmedTxns(maxAge)
+		if err != nil {
+			switch err {
+			case visor.ErrUnconfirmedExpiryDisabled:
+				wh.Error400(w, err.Error())
+			default:
+				wh.Error500(w, err.Error())
+			}
+			return
+		}
+
+		ret := make([]*visor.ReadableUnconfirmedTxn, 0, len(txns))
+		for _, unconfirmedTxn := range txns {
+			readable, err := visor.NewReadableUnconfirmedTxn(&unconfirmedTxn)
+			if err != nil {
+				wh.Error500(w, err.Error())
+				return
+			}
+			ret = append(ret, readable)
+		}
+
+		wh.SendJSONOr500(logger, w, &ret)
+	}
+}
+
+// Removes pending transactions received more than max_age ago, along with
+// the pending transactions that spend their outputs. Returns the removed txids.
+// If max_age is not provided, the node's configured max age is used.
+// URI: /api/v1/pendingTxs/purge
+// Method: POST
+// Args:
+//     max_age: duration, e.g. "72h" [optional]
+func purgeExpiredPendingTxns(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		maxAge, err := parseMaxAge(r)
+		if err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		hashes, err := gateway.RemoveExpiredUnconfirmedTxns(maxAge)
+		if err != nil {
+			switch err {
+			case visor.ErrUnconfirmedExpiryDisabled:
+				wh.Error400(w, err.Error())
+			default:
+				wh.Error500(w, err.Error())
+			}
+			return
+		}
+
+		txids := make([]string, len(hashes))
+		for i, h := range hashes {
+			txids[i] = h.Hex()
+		}
+
+		wh.SendJSONOr500(logger, w, txids)
+	}
+}
+
 func getTransactionByID(gate Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -94,8 +237,15 @@ func getTransactionByID(gate Gatewayer) http.HandlerFunc {
 // Args:
 //     addrs: Comma seperated addresses [optional, returns all transactions if no address provided]
 //     confirmed: Whether the transactions should be confirmed [optional, must be 0 or 1; if not provided, returns all]
-func getTransactions(gateway Gatewayer) http.HandlerFunc {
+//     in_addrs: Comma seperated addresses, returns transactions spending outputs of any of them [optional]
+//     out_addrs: Comma seperated addresses, returns transactions creating outputs for any of them [optional]
+//     start_time: Unix time, returns transactions with a block time or received time >= start_time [optional]
+//     end_time: Unix time, returns transactions with a block time or received time <= end_time [optional]
+//     min_coins: Returns transactions sending at least min_coins in their outputs, in decimal coins [optional]
+//     max_coins: Returns transactions sending at most max_coins in their outputs, in decimal coins [optional]
+func getTransactions(gateway Gatewayer, maxAddresses int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -108,6 +258,12 @@ func getTransactions(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		// The in_addrs and out_addrs filters count towards the address limit too
+		nAddrs := len(addrs) + len(splitCommaString(r.FormValue("in_addrs"))) + len(splitCommaString(r.FormValue("out_addrs")))
+		if tooManyAddresses(w, nAddrs, maxAddresses) {
+			return
+		}
+
 		// Initialize transaction filters
 		flts := []visor.TxFilter{visor.AddrsFilter(addrs)}
 
@@ -123,6 +279,13 @@ func getTransactions(gateway Gatewayer) http.HandlerFunc {
 			flts = append(flts, visor.ConfirmedTxFilter(confirmed))
 		}
 
+		searchFlts, err := parseTxnSearchFilters(r)
+		if err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+		flts = append(flts, searchFlts...)
+
 		// Gets transactions
 		txns, err := gateway.GetTransactions(flts...)
 		if err != nil {
@@ -143,6 +306,62 @@ func getTransactions(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// parseTxnSearchFilters parses the in_addrs, out_addrs, start_time, end_time, min_coins and max_coins
+// parameters into transaction filters
+func parseTxnSearchFilters(r *http.Request) ([]visor.TxFilter, error) {
+	var flts []visor.TxFilter
+
+	inAddrs, err := parseAddressesFromStr(r.FormValue("in_addrs"))
+	if err != nil {
+		return nil, fmt.Errorf("parse parameter: 'in_addrs' failed: %v", err)
+	}
+	if len(inAddrs) != 0 {
+		flts = append(flts, visor.InputAddrsFilter(inAddrs))
+	}
+
+	outAddrs, err := parseAddressesFromStr(r.FormValue("out_addrs"))
+	if err != nil {
+		return nil, fmt.Errorf("parse parameter: 'out_addrs' failed: %v", err)
+	}
+	if len(outAddrs) != 0 {
+		flts = append(flts, visor.OutputAddrsFilter(outAddrs))
+	}
+
+	startTime, endTime, err := parseTimeRange(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if startTime != 0 || endTime != 0 {
+		flts = append(flts, visor.TimeRangeFilter(startTime, endTime))
+	}
+
+	var minCoins, maxCoins uint64
+	if s := r.FormValue("min_coins"); s != "" {
+		minCoins, err = droplet.FromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'min_coins' value: %v", err)
+		}
+	}
+
+	if s := r.FormValue("max_coins"); s != "" {
+		maxCoins, err = droplet.FromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 'max_coins' value: %v", err)
+		}
+
+		if maxCoins == 0 || maxCoins < minCoins {
+			return nil, errors.New("'max_coins' must be > 0 and >= 'min_coins'")
+		}
+	}
+
+	if minCoins != 0 || maxCoins != 0 {
+		flts = append(flts, visor.AmountRangeFilter(minCoins, maxCoins))
+	}
+
+	return flts, nil
+}
+
 // parseAddressesFromStr parses comma seperated addresses string into []cipher.Address
 func parseAddressesFromStr(s string) ([]cipher.Address, error) {
 	addrsStr := splitCommaString(s)
@@ -170,6 +389,7 @@ func parseAddressesFromStr(s string) ([]cipher.Address, error) {
 //      200 - ok, returns the transaction hash in hex as string
 func injectTransaction(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			wh.Error405(w)
 			return
@@ -208,6 +428,7 @@ func injectTransaction(gateway Gatewayer) http.HandlerFunc {
 
 func resendUnconfirmedTxns(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -226,6 +447,7 @@ func resendUnconfirmedTxns(gateway Gatewayer) http.HandlerFunc {
 
 func getRawTxn(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -301,6 +523,7 @@ func writeHTTPResponse(w http.ResponseWriter, resp HTTPResponse) {
 // URI: /api/v2/transaction/verify
 func verifyTxnHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
 			writeHTTPResponse(w, resp)
@@ -334,10 +557,7 @@ func verifyTxnHandler(gateway Gatewayer) http.HandlerFunc {
 			case visor.ErrTxnViolatesSoftConstraint,
 				visor.ErrTxnViolatesHardConstraint,
 				visor.ErrTxnViolatesUserConstraint:
-				resp.Error = &HTTPError{
-					Code:    http.StatusUnprocessableEntity,
-					Message: err.Error(),
-				}
+				resp.Error = NewHTTPErrorResponse(http.StatusUnprocessableEntity, err.Error()).Error
 			default:
 				resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
 				writeHTTPResponse(w, resp)
@@ -364,10 +584,7 @@ func verifyTxnHandler(gateway Gatewayer) http.HandlerFunc {
 		resp.Data = verifyTxnResp
 
 		if isTxnConfirmed && resp.Error == nil {
-			resp.Error = &HTTPError{
-				Code:    http.StatusUnprocessableEntity,
-				Message: "transaction has been spent",
-			}
+			resp.Error = NewHTTPErrorResponse(http.StatusUnprocessableEntity, "transaction has been spent").Error
 		}
 
 		writeHTTPResponse(w, resp)
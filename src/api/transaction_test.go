@@ -162,6 +162,306 @@ func TestGetPendingTxs(t *testing.T) {
 	}
 }
 
+func TestGetPendingTxsStats(t *testing.T) {
+	tt := []struct {
+		name                               string
+		method                             string
+		status                             int
+		err                                string
+		getUnconfirmedTxnPoolStatsResponse *visor.UnconfirmedTxnPoolStats
+		getUnconfirmedTxnPoolStatsErr      error
+		httpResponse                       PendingTxnsStats
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "500 - get stats error",
+			method: http.MethodGet,
+			status: http.StatusInternalServerError,
+			err:    "500 Internal Server Error - GetUnconfirmedTxnPoolStats failed",
+			getUnconfirmedTxnPoolStatsErr: errors.New("GetUnconfirmedTxnPoolStats failed"),
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			getUnconfirmedTxnPoolStatsResponse: &visor.UnconfirmedTxnPoolStats{
+				Count:    3,
+				Size:     1024,
+				MaxSize:  4096,
+				Evicted:  2,
+				Replaced: 1,
+			},
+			httpResponse: PendingTxnsStats{
+				Count:    3,
+				Size:     1024,
+				MaxSize:  4096,
+				Evicted:  2,
+				Replaced: 1,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := "/api/v1/pendingTxs/stats"
+			gateway := NewGatewayerMock()
+			gateway.On("GetUnconfirmedTxnPoolStats").Return(tc.getUnconfirmedTxnPoolStatsResponse, tc.getUnconfirmedTxnPoolStatsErr)
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+
+			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, csrfStore, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "case: %s, handler returned wrong status code: got `%v` want `%v`",
+				tc.name, status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()), "case: %s, handler returned wrong error message: got `%v`| %s, want `%v`",
+					tc.name, strings.TrimSpace(rr.Body.String()), status, tc.err)
+			} else {
+				var msg PendingTxnsStats
+				err = json.Unmarshal(rr.Body.Bytes(), &msg)
+				require.NoError(t, err)
+				require.Equal(t, tc.httpResponse, msg, tc.name)
+			}
+		})
+	}
+}
+
+func TestGetExpiredPendingTxs(t *testing.T) {
+	txn := createUnconfirmedTxn(t)
+	readable, err := visor.NewReadableUnconfirmedTxn(&txn)
+	require.NoError(t, err)
+
+	// Round trip through JSON, so that times compare equal to the decoded response
+	b, err := json.Marshal(readable)
+	require.NoError(t, err)
+	readable = &visor.ReadableUnconfirmedTxn{}
+	err = json.Unmarshal(b, readable)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name                              string
+		method                            string
+		maxAge                            string
+		status                            int
+		err                               string
+		gatewayMaxAge                     time.Duration
+		getExpiredUnconfirmedTxnsResponse []visor.UnconfirmedTxn
+		getExpiredUnconfirmedTxnsErr      error
+		httpResponse                      []*visor.ReadableUnconfirmedTxn
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "400 - invalid max_age",
+			method: http.MethodGet,
+			maxAge: "foo",
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid max_age value: time: invalid duration \"foo\"",
+		},
+		{
+			name:   "400 - negative max_age",
+			method: http.MethodGet,
+			maxAge: "-1h",
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - max_age must be positive",
+		},
+		{
+			name:                         "400 - expiry disabled",
+			method:                       http.MethodGet,
+			status:                       http.StatusBadRequest,
+			err:                          "400 Bad Request - unconfirmed transactions do not expire, a max age is required",
+			getExpiredUnconfirmedTxnsErr: visor.ErrUnconfirmedExpiryDisabled,
+		},
+		{
+			name:                         "500 - get expired error",
+			method:                       http.MethodGet,
+			maxAge:                       "1h",
+			status:                       http.StatusInternalServerError,
+			err:                          "500 Internal Server Error - GetExpiredUnconfirmedTxns failed",
+			gatewayMaxAge:                time.Hour,
+			getExpiredUnconfirmedTxnsErr: errors.New("GetExpiredUnconfirmedTxns failed"),
+		},
+		{
+			name:                              "200",
+			method:                            http.MethodGet,
+			maxAge:                            "30m",
+			status:                            http.StatusOK,
+			gatewayMaxAge:                     30 * time.Minute,
+			getExpiredUnconfirmedTxnsResponse: []visor.UnconfirmedTxn{txn},
+			httpResponse:                      []*visor.ReadableUnconfirmedTxn{readable},
+		},
+		{
+			name:                              "200 - configured max age",
+			method:                            http.MethodGet,
+			status:                            http.StatusOK,
+			getExpiredUnconfirmedTxnsResponse: []visor.UnconfirmedTxn{},
+			httpResponse:                      []*visor.ReadableUnconfirmedTxn{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := "/api/v1/pendingTxs/expired"
+			gateway := NewGatewayerMock()
+			gateway.On("GetExpiredUnconfirmedTxns", tc.gatewayMaxAge).Return(tc.getExpiredUnconfirmedTxnsResponse, tc.getExpiredUnconfirmedTxnsErr)
+
+			v := url.Values{}
+			if tc.maxAge != "" {
+				v.Add("max_age", tc.maxAge)
+			}
+			if len(v) > 0 {
+				endpoint += "?" + v.Encode()
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+
+			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, csrfStore, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "case: %s, handler returned wrong status code: got `%v` want `%v`",
+				tc.name, status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()), "case: %s, handler returned wrong error message: got `%v`| %s, want `%v`",
+					tc.name, strings.TrimSpace(rr.Body.String()), status, tc.err)
+			} else {
+				var msg []*visor.ReadableUnconfirmedTxn
+				err = json.Unmarshal(rr.Body.Bytes(), &msg)
+				require.NoError(t, err)
+				require.Equal(t, tc.httpResponse, msg, tc.name)
+			}
+		})
+	}
+}
+
+func TestPurgeExpiredPendingTxs(t *testing.T) {
+	hash := testutil.RandSHA256(t)
+
+	tt := []struct {
+		name                                 string
+		method                               string
+		maxAge                               string
+		status                               int
+		err                                  string
+		gatewayMaxAge                        time.Duration
+		removeExpiredUnconfirmedTxnsResponse []cipher.SHA256
+		removeExpiredUnconfirmedTxnsErr      error
+		httpResponse                         []string
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "400 - invalid max_age",
+			method: http.MethodPost,
+			maxAge: "foo",
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid max_age value: time: invalid duration \"foo\"",
+		},
+		{
+			name:                            "400 - expiry disabled",
+			method:                          http.MethodPost,
+			status:                          http.StatusBadRequest,
+			err:                             "400 Bad Request - unconfirmed transactions do not expire, a max age is required",
+			removeExpiredUnconfirmedTxnsErr: visor.ErrUnconfirmedExpiryDisabled,
+		},
+		{
+			name:                            "500 - remove expired error",
+			method:                          http.MethodPost,
+			maxAge:                          "1h",
+			status:                          http.StatusInternalServerError,
+			err:                             "500 Internal Server Error - RemoveExpiredUnconfirmedTxns failed",
+			gatewayMaxAge:                   time.Hour,
+			removeExpiredUnconfirmedTxnsErr: errors.New("RemoveExpiredUnconfirmedTxns failed"),
+		},
+		{
+			name:                                 "200",
+			method:                               http.MethodPost,
+			maxAge:                               "1h",
+			status:                               http.StatusOK,
+			gatewayMaxAge:                        time.Hour,
+			removeExpiredUnconfirmedTxnsResponse: []cipher.SHA256{hash},
+			httpResponse:                         []string{hash.Hex()},
+		},
+		{
+			name:         "200 - nothing removed",
+			method:       http.MethodPost,
+			status:       http.StatusOK,
+			httpResponse: []string{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := "/api/v1/pendingTxs/purge"
+			gateway := NewGatewayerMock()
+			gateway.On("RemoveExpiredUnconfirmedTxns", tc.gatewayMaxAge).Return(tc.removeExpiredUnconfirmedTxnsResponse, tc.removeExpiredUnconfirmedTxnsErr)
+
+			v := url.Values{}
+			if tc.maxAge != "" {
+				v.Add("max_age", tc.maxAge)
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(v.Encode()))
+			require.NoError(t, err)
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+
+			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, csrfStore, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "case: %s, handler returned wrong status code: got `%v` want `%v`",
+				tc.name, status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()), "case: %s, handler returned wrong error message: got `%v`| %s, want `%v`",
+					tc.name, strings.TrimSpace(rr.Body.String()), status, tc.err)
+			} else {
+				var msg []string
+				err = json.Unmarshal(rr.Body.Bytes(), &msg)
+				require.NoError(t, err)
+				require.Equal(t, tc.httpResponse, msg, tc.name)
+			}
+		})
+	}
+}
+
 func TestGetTransactionByID(t *testing.T) {
 	oddHash := "cafcb"
 	invalidHash := "cabrca"
@@ -644,6 +944,12 @@ func TestGetTransactions(t *testing.T) {
 	type httpBody struct {
 		addrs     string
 		confirmed string
+		inAddrs   string
+		outAddrs  string
+		startTime string
+		endTime   string
+		minCoins  string
+		maxCoins  string
 	}
 
 	tt := []struct {
@@ -688,6 +994,62 @@ func TestGetTransactions(t *testing.T) {
 				visor.AddrsFilter(addrs),
 			},
 		},
+		{
+			name:   "400 - invalid `in_addrs` param",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - parse parameter: 'in_addrs' failed: Invalid base58 character",
+			httpBody: &httpBody{
+				inAddrs: invalidAddrsStr,
+			},
+		},
+		{
+			name:   "400 - invalid `out_addrs` param",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - parse parameter: 'out_addrs' failed: Invalid base58 character",
+			httpBody: &httpBody{
+				outAddrs: invalidAddrsStr,
+			},
+		},
+		{
+			name:   "400 - invalid `start_time` param",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid 'start_time' value: strconv.ParseUint: parsing \"-1\": invalid syntax",
+			httpBody: &httpBody{
+				startTime: "-1",
+			},
+		},
+		{
+			name:   "400 - `end_time` before `start_time`",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - 'end_time' must be >= 'start_time'",
+			httpBody: &httpBody{
+				startTime: "1000",
+				endTime:   "999",
+			},
+		},
+		{
+			name:   "400 - invalid `min_coins` param",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid 'min_coins' value: can't convert foo to decimal",
+			httpBody: &httpBody{
+				minCoins: "foo",
+			},
+		},
+		{
+			name:   "400 - `max_coins` less than `min_coins`",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - 'max_coins' must be > 0 and >= 'min_coins'",
+			httpBody: &httpBody{
+				minCoins: "10",
+				maxCoins: "1.5",
+			},
+		},
 		{
 			name:   "500 - getTransactionsError",
 			method: http.MethodGet,
@@ -741,6 +1103,28 @@ func TestGetTransactions(t *testing.T) {
 			getTransactionsResponse: []visor.Transaction{},
 			httpResponse:            []visor.Transaction{},
 		},
+		{
+			name:   "200 - search filters",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			httpBody: &httpBody{
+				inAddrs:   addrsStr,
+				outAddrs:  addrsStr,
+				startTime: "1000",
+				endTime:   "2000",
+				minCoins:  "1.5",
+				maxCoins:  "10",
+			},
+			getTransactionsArg: []visor.TxFilter{
+				visor.AddrsFilter(nil),
+				visor.InputAddrsFilter(addrs),
+				visor.OutputAddrsFilter(addrs),
+				visor.TimeRangeFilter(1000, 2000),
+				visor.AmountRangeFilter(1500000, 10000000),
+			},
+			getTransactionsResponse: []visor.Transaction{},
+			httpResponse:            []visor.Transaction{},
+		},
 	}
 
 	for _, tc := range tt {
@@ -757,6 +1141,24 @@ func TestGetTransactions(t *testing.T) {
 				if tc.httpBody.confirmed != "" {
 					v.Add("confirmed", tc.httpBody.confirmed)
 				}
+				if tc.httpBody.inAddrs != "" {
+					v.Add("in_addrs", tc.httpBody.inAddrs)
+				}
+				if tc.httpBody.outAddrs != "" {
+					v.Add("out_addrs", tc.httpBody.outAddrs)
+				}
+				if tc.httpBody.startTime != "" {
+					v.Add("start_time", tc.httpBody.startTime)
+				}
+				if tc.httpBody.endTime != "" {
+					v.Add("end_time", tc.httpBody.endTime)
+				}
+				if tc.httpBody.minCoins != "" {
+					v.Add("min_coins", tc.httpBody.minCoins)
+				}
+				if tc.httpBody.maxCoins != "" {
+					v.Add("max_coins", tc.httpBody.maxCoins)
+				}
 			}
 			if len(v) > 0 {
 				endpoint += "?" + v.Encode()
@@ -786,6 +1188,11 @@ func TestGetTransactions(t *testing.T) {
 				err = json.Unmarshal(rr.Body.Bytes(), &msg)
 				require.NoError(t, err)
 				require.Equal(t, tc.httpResponse, msg, tc.name)
+
+				// ConfirmedTxFilter can't be compared, it wraps a func
+				if tc.httpBody.confirmed == "" {
+					gateway.AssertCalled(t, "GetTransactions", tc.getTransactionsArg)
+				}
 			}
 		})
 	}
@@ -935,8 +1342,9 @@ func TestVerifyTransaction(t *testing.T) {
 			httpResponse: HTTPResponse{
 				Data: newVerifyTxnResponseJSON(t, &invalidTxnEmptyAddress.txn, invalidTxnEmptyAddress.inputs, false),
 				Error: &HTTPError{
-					Code:    http.StatusUnprocessableEntity,
-					Message: "Transaction violates user constraint: Transaction.Out contains an output sending to an empty address",
+					Code:      http.StatusUnprocessableEntity,
+					Message:   "Transaction violates user constraint: Transaction.Out contains an output sending to an empty address",
+					ErrorCode: ErrorCodeUnprocessable,
 				},
 			},
 		},
@@ -965,8 +1373,9 @@ func TestVerifyTransaction(t *testing.T) {
 			},
 			httpResponse: HTTPResponse{
 				Error: &HTTPError{
-					Message: "transaction has been spent",
-					Code:    http.StatusUnprocessableEntity,
+					Message:   "transaction has been spent",
+					Code:      http.StatusUnprocessableEntity,
+					ErrorCode: ErrorCodeUnprocessable,
 				},
 				Data: newVerifyTxnResponseJSON(t, &txnAndInputs.txn, txnAndInputs.inputs, true),
 			},
@@ -0,0 +1,563 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/util/fee"
+	wh "github.com/skycoin/skycoin/src/util/http" //http,json helpers
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// ErrorCode is a machine readable error code, included in the errors of the v2 API
+type ErrorCode string
+
+const (
+	// ErrorCodeBadRequest is returned for a 400 error that is not described by a more specific code
+	ErrorCodeBadRequest ErrorCode = "bad_request"
+	// ErrorCodeUnauthorized is returned if the API token is missing or invalid
+	ErrorCodeUnauthorized ErrorCode = "unauthorized"
+	// ErrorCodeForbidden is returned for a 403 error, such as a disabled API or a failed CSRF check
+	ErrorCodeForbidden ErrorCode = "forbidden"
+	// ErrorCodeNotFound is returned for a 404 error that is not described by a more specific code
+	ErrorCodeNotFound ErrorCode = "not_found"
+	// ErrorCodeMethodNotAllowed is returned if the endpoint does not accept the request method
+	ErrorCodeMethodNotAllowed ErrorCode = "method_not_allowed"
+	// ErrorCodeRequestTooLarge is returned if the request body is too large
+	ErrorCodeRequestTooLarge ErrorCode = "request_too_large"
+	// ErrorCodeUnsupportedMediaType is returned if the request body is not JSON
+	ErrorCodeUnsupportedMediaType ErrorCode = "unsupported_media_type"
+	// ErrorCodeUnprocessable is returned for a 422 error, such as a transaction that violates a constraint
+	ErrorCodeUnprocessable ErrorCode = "unprocessable"
+	// ErrorCodeRateLimited is returned if the client exceeded the API rate limit
+	ErrorCodeRateLimited ErrorCode = "rate_limited"
+	// ErrorCodeInternal is returned for a 500 error
+	ErrorCodeInternal ErrorCode = "internal_error"
+	// ErrorCodeNotImplemented is returned for a 501 error
+	ErrorCodeNotImplemented ErrorCode = "not_implemented"
+	// ErrorCodeUnavailable is returned for a 503 error, such as a failed injection when the node has no connections
+	ErrorCodeUnavailable ErrorCode = "unavailable"
+	// ErrorCodeUnknown is returned for any other error status
+	ErrorCodeUnknown ErrorCode = "unknown"
+
+	// ErrorCodeWalletNotFound is returned if the wallet does not exist
+	ErrorCodeWalletNotFound ErrorCode = "wallet_not_found"
+	// ErrorCodeInvalidPassword is returned if the wallet password is wrong
+	ErrorCodeInvalidPassword ErrorCode = "invalid_password"
+	// ErrorCodeMissingPassword is returned if the wallet is encrypted and no password was given
+	ErrorCodeMissingPassword ErrorCode = "missing_password"
+	// ErrorCodeWalletEncrypted is returned if the operation requires an unencrypted wallet
+	ErrorCodeWalletEncrypted ErrorCode = "wallet_encrypted"
+	// ErrorCodeWalletNotEncrypted is returned if the operation requires an encrypted wallet
+	ErrorCodeWalletNotEncrypted ErrorCode = "wallet_not_encrypted"
+	// ErrorCodeInsufficientBalance is returned if the wallet does not have enough coins to spend
+	ErrorCodeInsufficientBalance ErrorCode = "insufficient_balance"
+	// ErrorCodeInsufficientCoinHours is returned if the wallet does not have enough coin hours to spend
+	ErrorCodeInsufficientCoinHours ErrorCode = "insufficient_coin_hours"
+	// ErrorCodeSpendingUnconfirmed is returned if the wallet has an unconfirmed transaction
+	ErrorCodeSpendingUnconfirmed ErrorCode = "spending_unconfirmed"
+)
+
+var (
+	statusErrorCodes = map[int]ErrorCode{
+		http.StatusBadRequest:            ErrorCodeBadRequest,
+		http.StatusUnauthorized:          ErrorCodeUnauthorized,
+		http.StatusForbidden:             ErrorCodeForbidden,
+		http.StatusNotFound:              ErrorCodeNotFound,
+		http.StatusMethodNotAllowed:      ErrorCodeMethodNotAllowed,
+		http.StatusRequestEntityTooLarge: ErrorCodeRequestTooLarge,
+		http.StatusUnsupportedMediaType:  ErrorCodeUnsupportedMediaType,
+		http.StatusUnprocessableEntity:   ErrorCodeUnprocessable,
+		http.StatusTooManyRequests:       ErrorCodeRateLimited,
+		http.StatusInternalServerError:   ErrorCodeInternal,
+		http.StatusNotImplemented:        ErrorCodeNotImplemented,
+		http.StatusServiceUnavailable:    ErrorCodeUnavailable,
+	}
+
+	// knownErrorCodes are the codes of the errors returned by the gateway which have a more specific code than
+	// the status of their error response
+	knownErrorCodes = []struct {
+		err  error
+		code ErrorCode
+	}{
+		{wallet.ErrWalletNotExist, ErrorCodeWalletNotFound},
+		{wallet.ErrInvalidPassword, ErrorCodeInvalidPassword},
+		{wallet.ErrMissingPassword, ErrorCodeMissingPassword},
+		{wallet.ErrWalletEncrypted, ErrorCodeWalletEncrypted},
+		{wallet.ErrWalletNotEncrypted, ErrorCodeWalletNotEncrypted},
+		{wallet.ErrInsufficientBalance, ErrorCodeInsufficientBalance},
+		{wallet.ErrSpendingUnconfirmed, ErrorCodeSpendingUnconfirmed},
+		{fee.ErrTxnInsufficientCoinHours, ErrorCodeInsufficientCoinHours},
+	}
+)
+
+// knownErrorCode returns the ErrorCode of err, or "" if it is not one of knownErrorCodes
+func knownErrorCode(err error) ErrorCode {
+	for _, e := range knownErrorCodes {
+		if err == e.err {
+			return e.code
+		}
+	}
+
+	return ""
+}
+
+// statusErrorCode returns the ErrorCode of an error response with the given status
+func statusErrorCode(status int) ErrorCode {
+	if code, ok := statusErrorCodes[status]; ok {
+		return code
+	}
+
+	return ErrorCodeUnknown
+}
+
+// errorCodeKey is the request context key of the ErrorCode set by setErrorCode
+type errorCodeKey struct{}
+
+// setErrorCode sets the ErrorCode of the plain text error response which a handler writes for err,
+// when it is converted by JSONErrors. If err is not one of knownErrorCodes, the code of the status is used.
+func setErrorCode(r *http.Request, err error) {
+	if code, ok := r.Context().Value(errorCodeKey{}).(*ErrorCode); ok {
+		*code = knownErrorCode(err)
+	}
+}
+
+// BalanceV2 is a balance with the coins encoded as a decimal string
+type BalanceV2 struct {
+	Coins wh.Coins `json:"coins"`
+	Hours uint64   `json:"hours"`
+}
+
+// BalancePairV2 records the confirmed and predicted balance
+type BalancePairV2 struct {
+	Confirmed BalanceV2 `json:"confirmed"`
+	Predicted BalanceV2 `json:"predicted"`
+}
+
+// BalanceResponseV2 is the v2 response of /balance and /wallet/balance
+type BalanceResponseV2 struct {
+	BalancePairV2
+	Addresses map[string]BalancePairV2 `json:"addresses"`
+}
+
+// SpendResultV2 is the v2 response of /wallet/spend
+type SpendResultV2 struct {
+	Balance     *BalancePairV2             `json:"balance,omitempty"`
+	Transaction *visor.ReadableTransaction `json:"txn,omitempty"`
+	Error       string                     `json:"error,omitempty"`
+}
+
+// UxOutV2 is the v2 response of /uxout and /address_uxouts
+type UxOutV2 struct {
+	Uxid          string   `json:"uxid"`
+	Time          uint64   `json:"time"`
+	SrcBkSeq      uint64   `json:"src_block_seq"`
+	SrcTx         string   `json:"src_tx"`
+	OwnerAddress  string   `json:"owner_address"`
+	Coins         wh.Coins `json:"coins"`
+	Hours         uint64   `json:"hours"`
+	SpentBlockSeq uint64   `json:"spent_block_seq"`
+	SpentTxID     string   `json:"spent_tx"`
+}
+
+func newBalancePairV2(b wallet.BalancePair) BalancePairV2 {
+	return BalancePairV2{
+		Confirmed: BalanceV2{
+			Coins: wh.Coins(b.Confirmed.Coins),
+			Hours: b.Confirmed.Hours,
+		},
+		Predicted: BalanceV2{
+			Coins: wh.Coins(b.Predicted.Coins),
+			Hours: b.Predicted.Hours,
+		},
+	}
+}
+
+func newUxOutV2(ux historydb.UxOutJSON) UxOutV2 {
+	return UxOutV2{
+		Uxid:          ux.Uxid,
+		Time:          ux.Time,
+		SrcBkSeq:      ux.SrcBkSeq,
+		SrcTx:         ux.SrcTx,
+		OwnerAddress:  ux.OwnerAddress,
+		Coins:         wh.Coins(ux.Coins),
+		Hours:         ux.Hours,
+		SpentBlockSeq: ux.SpentBlockSeq,
+		SpentTxID:     ux.SpentTxID,
+	}
+}
+
+// v2Conversion converts the requests and responses of a v1 endpoint which encodes coins as droplets
+type v2Conversion struct {
+	// coinParams are the params which are droplets in v1, and decimal strings in v2
+	coinParams []apiParam
+	// response is a zero value of the v2 response
+	response interface{}
+	// convert converts the v1 JSON response body to the v2 response
+	convert func([]byte) (interface{}, error)
+}
+
+func convertBalanceResponse(body []byte) (interface{}, error) {
+	var v1 BalanceResponse
+	if err := json.Unmarshal(body, &v1); err != nil {
+		return nil, err
+	}
+
+	v2 := BalanceResponseV2{
+		BalancePairV2: newBalancePairV2(v1.BalancePair),
+		Addresses:     make(map[string]BalancePairV2, len(v1.Addresses)),
+	}
+	for addr, b := range v1.Addresses {
+		v2.Addresses[addr] = newBalancePairV2(b)
+	}
+
+	return v2, nil
+}
+
+// v2Conversions are keyed by the v1 path
+var v2Conversions = map[string]v2Conversion{
+	"/api/v1/balance": {
+		response: BalanceResponseV2{},
+		convert:  convertBalanceResponse,
+	},
+	"/api/v1/wallet/balance": {
+		response: BalanceResponseV2{},
+		convert:  convertBalanceResponse,
+	},
+	"/api/v1/wallet/spend": {
+		coinParams: []apiParam{
+			{name: "coins", typ: "string", required: true, description: "Coins to send, as a decimal string"},
+		},
+		response: SpendResultV2{},
+		convert: func(body []byte) (interface{}, error) {
+			var v1 SpendResult
+			if err := json.Unmarshal(body, &v1); err != nil {
+				return nil, err
+			}
+
+			v2 := SpendResultV2{
+				Transaction: v1.Transaction,
+				Error:       v1.Error,
+			}
+			if v1.Balance != nil {
+				b := newBalancePairV2(*v1.Balance)
+				v2.Balance = &b
+			}
+
+			return v2, nil
+		},
+	},
+	"/api/v1/uxout": {
+		response: UxOutV2{},
+		convert: func(body []byte) (interface{}, error) {
+			var v1 historydb.UxOutJSON
+			if err := json.Unmarshal(body, &v1); err != nil {
+				return nil, err
+			}
+
+			return newUxOutV2(v1), nil
+		},
+	},
+	"/api/v1/address_uxouts": {
+		response: []UxOutV2{},
+		convert: func(body []byte) (interface{}, error) {
+			var v1 []historydb.UxOutJSON
+			if err := json.Unmarshal(body, &v1); err != nil {
+				return nil, err
+			}
+
+			v2 := make([]UxOutV2, len(v1))
+			for i, ux := range v1 {
+				v2[i] = newUxOutV2(ux)
+			}
+
+			return v2, nil
+		},
+	},
+}
+
+// findV1Operation returns the apiOperation of a v1 path, if it is mirrored under /api/v2
+func findV1Operation(path string) (apiOperation, bool) {
+	for _, op := range apiOperations {
+		if op.path == path && !op.v2 && !op.v1Only {
+			return op, true
+		}
+	}
+
+	return apiOperation{}, false
+}
+
+// v2Operations returns the operations of the v1 endpoints which are mirrored under /api/v2
+func v2Operations() []apiOperation {
+	var ops []apiOperation
+	for _, op := range apiOperations {
+		if op.v2 || op.v1Only {
+			continue
+		}
+
+		conv := v2Conversions[op.path]
+
+		v2 := op
+		v2.path = "/api/v2" + strings.TrimPrefix(op.path, "/api/v1")
+		v2.v2 = true
+		v2.clients = nil
+
+		if conv.response != nil {
+			v2.response = conv.response
+		}
+
+		if len(conv.coinParams) != 0 {
+			v2.params = make([]apiParam, len(op.params))
+			for i, p := range op.params {
+				v2.params[i] = p
+				for _, c := range conv.coinParams {
+					if c.name == p.name {
+						v2.params[i] = c
+					}
+				}
+			}
+		}
+
+		ops = append(ops, v2)
+	}
+
+	return ops
+}
+
+// mirrorV1 serves a v1 endpoint under /api/v2.
+// POST form values are sent as a JSON object instead, and the v1 response is wrapped in an HTTPResponse.
+// Coins are converted between droplets and decimal strings for the endpoints in v2Conversions.
+// Errors are written by the v1 handler as plain text, and are converted by JSONErrors.
+func mirrorV1(op apiOperation, handler http.Handler) http.Handler {
+	conv := v2Conversions[op.path]
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != op.method {
+			writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""))
+			return
+		}
+
+		if r.Method == http.MethodPost && op.body == nil {
+			if r.Header.Get("Content-Type") != "application/json" {
+				writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusUnsupportedMediaType, ""))
+				return
+			}
+
+			values, err := formValuesFromJSON(r.Body, op.params, conv.coinParams)
+			if err != nil {
+				writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusBadRequest, err.Error()))
+				return
+			}
+
+			form := values.Encode()
+
+			r2 := *r
+			r2.Header = make(http.Header, len(r.Header))
+			for k, v := range r.Header {
+				r2.Header[k] = v
+			}
+			r2.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r2.Body = ioutil.NopCloser(strings.NewReader(form))
+			r2.ContentLength = int64(len(form))
+			r = &r2
+		}
+
+		rec := &responseBuffer{
+			header: make(http.Header),
+			status: http.StatusOK,
+		}
+		handler.ServeHTTP(rec, r)
+
+		if rec.status != http.StatusOK {
+			for k, v := range rec.header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(rec.status)
+			if _, err := w.Write(rec.body.Bytes()); err != nil {
+				logger.WithError(err).Error("http Write failed")
+			}
+			return
+		}
+
+		body := rec.body.Bytes()
+
+		var data interface{}
+		switch {
+		case conv.convert != nil:
+			var err error
+			data, err = conv.convert(body)
+			if err != nil {
+				writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusInternalServerError, err.Error()))
+				return
+			}
+		case len(bytes.TrimSpace(body)) == 0:
+		case json.Valid(body):
+			data = json.RawMessage(body)
+		default:
+			data = string(body)
+		}
+
+		writeHTTPResponse(w, HTTPResponse{Data: data})
+	})
+}
+
+// formValuesFromJSON converts a JSON object to the form values of a v1 endpoint.
+// coinParams are decimal strings, converted to droplets.
+func formValuesFromJSON(body io.Reader, params, coinParams []apiParam) (url.Values, error) {
+	var obj map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&obj); err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]string, len(params))
+	for _, p := range params {
+		types[p.name] = p.typ
+	}
+
+	coins := make(map[string]struct{}, len(coinParams))
+	for _, p := range coinParams {
+		coins[p.name] = struct{}{}
+	}
+
+	values := make(url.Values, len(obj))
+	for k, v := range obj {
+		typ, ok := types[k]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", k)
+		}
+
+		if string(v) == "null" {
+			continue
+		}
+
+		if _, ok := coins[k]; ok {
+			typ = "coins"
+		}
+
+		var s string
+		switch typ {
+		case "string":
+			if err := json.Unmarshal(v, &s); err != nil {
+				return nil, fmt.Errorf("%s must be a string", k)
+			}
+		case "integer":
+			var n int64
+			if err := json.Unmarshal(v, &n); err != nil {
+				return nil, fmt.Errorf("%s must be an integer", k)
+			}
+			s = strconv.FormatInt(n, 10)
+		case "boolean":
+			var b bool
+			if err := json.Unmarshal(v, &b); err != nil {
+				return nil, fmt.Errorf("%s must be a boolean", k)
+			}
+			s = strconv.FormatBool(b)
+		case "coins":
+			if err := json.Unmarshal(v, &s); err != nil {
+				return nil, fmt.Errorf("%s must be a decimal string", k)
+			}
+			d, err := droplet.FromString(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value: %v", k, err)
+			}
+			s = strconv.FormatUint(d, 10)
+		default:
+			return nil, errors.New("formValuesFromJSON unhandled param type " + typ)
+		}
+
+		values.Set(k, s)
+	}
+
+	return values, nil
+}
+
+// responseBuffer is a http.ResponseWriter which buffers the response
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) WriteHeader(code int) {
+	b.status = code
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+// jsonErrorWriter buffers plain text error responses, for JSONErrors
+type jsonErrorWriter struct {
+	http.ResponseWriter
+	status int
+	buf    *bytes.Buffer
+}
+
+func (w *jsonErrorWriter) WriteHeader(code int) {
+	if code >= 400 && !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.status = code
+		w.buf = &bytes.Buffer{}
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *jsonErrorWriter) Write(p []byte) (int, error) {
+	if w.buf != nil {
+		return w.buf.Write(p)
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+// JSONErrors converts the plain text error responses of a handler, such as those written by the
+// wh.ErrorXXX helpers or the authentication and rate limit checks, to an HTTPResponse error
+func JSONErrors(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ew := &jsonErrorWriter{
+			ResponseWriter: w,
+		}
+
+		var code ErrorCode
+		r = r.WithContext(context.WithValue(r.Context(), errorCodeKey{}, &code))
+
+		handler.ServeHTTP(ew, r)
+
+		if ew.buf == nil {
+			return
+		}
+
+		w.Header().Del("Content-Type")
+		w.Header().Del("X-Content-Type-Options")
+		writeHTTPResponse(w, newHTTPErrorResponse(ew.status, plainTextErrorMessage(ew.status, ew.buf.String()), code))
+	})
+}
+
+// plainTextErrorMessage returns the message of an error written by wh.HTTPError, "<code> <status text> - <message>"
+func plainTextErrorMessage(status int, body string) string {
+	body = strings.TrimSpace(body)
+
+	prefix := fmt.Sprintf("%d %s", status, http.StatusText(status))
+	if body == prefix {
+		return ""
+	}
+
+	return strings.TrimPrefix(body, prefix+" - ")
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+	wh "github.com/skycoin/skycoin/src/util/http"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// errorResponseBody returns the body of an error response of an endpoint,
+// plain text for v1 endpoints and an HTTPResponse for v2 endpoints
+func errorResponseBody(t *testing.T, endpoint string, status int, msg string) string {
+	if !strings.HasPrefix(endpoint, "/api/v2/") {
+		if msg == "" {
+			return fmt.Sprintf("%d %s\n", status, http.StatusText(status))
+		}
+		return fmt.Sprintf("%d %s - %s\n", status, http.StatusText(status), msg)
+	}
+
+	b, err := json.MarshalIndent(NewHTTPErrorResponse(status, msg), "", "    ")
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestMirrorV1(t *testing.T) {
+	addr := testutil.MakeAddress()
+	dst := testutil.MakeAddress()
+
+	cases := []struct {
+		name         string
+		method       string
+		endpoint     string
+		contentType  string
+		body         string
+		gateway      func(*GatewayerMock)
+		status       int
+		httpResponse HTTPResponse
+		data         string
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			endpoint:     "/api/v2/wallet/spend",
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "415",
+			method:       http.MethodPost,
+			endpoint:     "/api/v2/wallet/spend",
+			contentType:  "application/x-www-form-urlencoded",
+			body:         "id=foo",
+			status:       http.StatusUnsupportedMediaType,
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, ""),
+		},
+		{
+			name:         "400 - unknown field",
+			method:       http.MethodPost,
+			endpoint:     "/api/v2/wallet/spend",
+			contentType:  "application/json",
+			body:         `{"id":"foo","foo":"bar"}`,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, `unknown field "foo"`),
+		},
+		{
+			name:         "400 - coins is not a decimal string",
+			method:       http.MethodPost,
+			endpoint:     "/api/v2/wallet/spend",
+			contentType:  "application/json",
+			body:         `{"id":"foo","dst":"` + dst.String() + `","coins":1500000}`,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "coins must be a decimal string"),
+		},
+		{
+			name:         "400 - v1 error",
+			method:       http.MethodPost,
+			endpoint:     "/api/v2/wallet/spend",
+			contentType:  "application/json",
+			body:         `{"id":"foo"}`,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "missing destination address \"dst\""),
+		},
+		{
+			name:        "400 - insufficient balance",
+			method:      http.MethodPost,
+			endpoint:    "/api/v2/wallet/spend",
+			contentType: "application/json",
+			body:        `{"id":"foo","dst":"` + dst.String() + `","coins":"1.5","password":"pwd"}`,
+			gateway: func(gateway *GatewayerMock) {
+				gateway.On("Spend", "foo", []byte("pwd"), uint64(1500000), dst).Return(nil, wallet.ErrInsufficientBalance)
+			},
+			status: http.StatusBadRequest,
+			httpResponse: HTTPResponse{
+				Error: &HTTPError{
+					Code:      http.StatusBadRequest,
+					Message:   wallet.ErrInsufficientBalance.Error(),
+					ErrorCode: ErrorCodeInsufficientBalance,
+				},
+			},
+		},
+		{
+			name:     "404 - wallet not found",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/wallet/balance?id=foo",
+			gateway: func(gateway *GatewayerMock) {
+				gateway.On("GetWalletBalance", "foo").Return(wallet.BalancePair{}, wallet.AddressBalance{}, wallet.ErrWalletNotExist)
+			},
+			status: http.StatusNotFound,
+			httpResponse: HTTPResponse{
+				Error: &HTTPError{
+					Code:      http.StatusNotFound,
+					Message:   http.StatusText(http.StatusNotFound),
+					ErrorCode: ErrorCodeWalletNotFound,
+				},
+			},
+		},
+		{
+			name:     "200 - coins are decimal strings",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance?addrs=" + addr.String(),
+			gateway: func(gateway *GatewayerMock) {
+				gateway.On("GetBalanceOfAddrs", []cipher.Address{addr}).Return([]wallet.BalancePair{
+					{
+						Confirmed: wallet.Balance{Coins: 1500000, Hours: 10},
+						Predicted: wallet.Balance{Coins: 2000000, Hours: 20},
+					},
+				}, nil)
+			},
+			status: http.StatusOK,
+			data: `{
+				"confirmed": {"coins": "1.500000", "hours": 10},
+				"predicted": {"coins": "2.000000", "hours": 20},
+				"addresses": {
+					"` + addr.String() + `": {
+						"confirmed": {"coins": "1.500000", "hours": 10},
+						"predicted": {"coins": "2.000000", "hours": 20}
+					}
+				}
+			}`,
+		},
+		{
+			name:     "200 - unconverted response",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/network/defaultConnections",
+			gateway: func(gateway *GatewayerMock) {
+				gateway.On("GetDefaultConnections").Return([]string{"1.2.3.4:6000"})
+			},
+			status: http.StatusOK,
+			data:   `["1.2.3.4:6000"]`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &GatewayerMock{}
+			if tc.gateway != nil {
+				tc.gateway(gateway)
+			}
+
+			req, err := http.NewRequest(tc.method, tc.endpoint, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(muxConfig{host: configuredHost, appLoc: "."}, gateway, &CSRFStore{}, nil)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, rr.Body.String())
+			require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+			var rsp ReceivedHTTPResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&rsp))
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if tc.data == "" {
+				require.Empty(t, rsp.Data)
+			} else {
+				require.JSONEq(t, tc.data, string(rsp.Data))
+			}
+		})
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	require.Equal(t, ErrorCodeMissingPassword, knownErrorCode(wallet.ErrMissingPassword))
+	require.Equal(t, ErrorCodeWalletNotFound, knownErrorCode(wallet.ErrWalletNotExist))
+	require.Equal(t, ErrorCode(""), knownErrorCode(errors.New(wallet.ErrWalletNotExist.Error())))
+	require.Equal(t, ErrorCode(""), knownErrorCode(nil))
+
+	require.Equal(t, ErrorCodeBadRequest, statusErrorCode(http.StatusBadRequest))
+	require.Equal(t, ErrorCodeRateLimited, statusErrorCode(http.StatusTooManyRequests))
+	require.Equal(t, ErrorCodeUnknown, statusErrorCode(http.StatusTeapot))
+}
+
+func TestJSONErrorsErrorCode(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		rsp     HTTPResponse
+	}{
+		{
+			name: "status code",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				wh.Error400(w, wallet.ErrMissingPassword.Error())
+			},
+			rsp: newHTTPErrorResponse(http.StatusBadRequest, wallet.ErrMissingPassword.Error(), ErrorCodeBadRequest),
+		},
+		{
+			name: "known error with another message",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				setErrorCode(r, wallet.ErrMissingPassword)
+				wh.Error400(w, "missing wallet_password")
+			},
+			rsp: newHTTPErrorResponse(http.StatusBadRequest, "missing wallet_password", ErrorCodeMissingPassword),
+		},
+		{
+			name: "known error without a message",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				setErrorCode(r, wallet.ErrWalletNotExist)
+				wh.Error404(w, "")
+			},
+			rsp: newHTTPErrorResponse(http.StatusNotFound, "", ErrorCodeWalletNotFound),
+		},
+		{
+			name: "unknown error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				setErrorCode(r, errors.New("foo"))
+				wh.Error500(w, "foo")
+			},
+			rsp: newHTTPErrorResponse(http.StatusInternalServerError, "foo", ErrorCodeInternal),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/api/v2/foo", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			JSONErrors(tc.handler).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.rsp.Error.Code, rr.Code)
+
+			var rsp ReceivedHTTPResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&rsp))
+			require.Equal(t, tc.rsp.Error, rsp.Error)
+		})
+	}
+
+	// setErrorCode does nothing outside of JSONErrors
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/foo", nil)
+	require.NoError(t, err)
+	setErrorCode(req, wallet.ErrWalletNotExist)
+}
+
+func TestPlainTextErrorMessage(t *testing.T) {
+	require.Equal(t, "", plainTextErrorMessage(http.StatusForbidden, "403 Forbidden\n"))
+	require.Equal(t, "invalid CSRF token", plainTextErrorMessage(http.StatusForbidden, "403 Forbidden - invalid CSRF token\n"))
+	require.Equal(t, "404 page not found", plainTextErrorMessage(http.StatusNotFound, "404 page not found\n"))
+}
@@ -3,6 +3,7 @@ package api
 // APIs for wallet-related information
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"sort"
@@ -31,6 +32,8 @@ type SpendResult struct {
 // UnconfirmedTxnsResponse contains unconfirmed transaction data
 type UnconfirmedTxnsResponse struct {
 	Transactions []visor.ReadableUnconfirmedTxn `json:"transactions"`
+	// Notes are the wallet's notes of the transactions, by transaction id
+	Notes map[string]string `json:"notes,omitempty"`
 }
 
 // WalletEntry the wallet entry struct
@@ -49,6 +52,7 @@ type WalletMeta struct {
 	CryptoType string `json:"crypto_type"`
 	Timestamp  int64  `json:"timestamp"`
 	Encrypted  bool   `json:"encrypted"`
+	WatchOnly  bool   `json:"watch_only,omitempty"`
 }
 
 // WalletResponse wallet response struct for http apis
@@ -73,6 +77,7 @@ func NewWalletResponse(w *wallet.Wallet) (*WalletResponse, error) {
 	wr.Meta.Type = w.Meta["type"]
 	wr.Meta.Version = w.Meta["version"]
 	wr.Meta.CryptoType = w.Meta["cryptoType"]
+	wr.Meta.WatchOnly = w.IsWatchOnly()
 
 	// Converts "encrypted" string to boolean if any
 	if encryptedStr, ok := w.Meta["encrypted"]; ok {
@@ -110,6 +115,7 @@ func NewWalletResponse(w *wallet.Wallet) (*WalletResponse, error) {
 //     id: wallet id [required]
 func walletBalanceHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -123,6 +129,7 @@ func walletBalanceHandler(gateway Gatewayer) http.HandlerFunc {
 		walletBalance, addressBalances, err := gateway.GetWalletBalance(wltID)
 		if err != nil {
 			logger.Errorf("Get wallet balance failed: %v", err)
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrWalletNotExist:
 				wh.Error404(w, "")
@@ -149,8 +156,9 @@ func walletBalanceHandler(gateway Gatewayer) http.HandlerFunc {
 // Method: GET
 // Args:
 //     addrs: command separated list of addresses [required]
-func getBalanceHandler(gateway Gatewayer) http.HandlerFunc {
+func getBalanceHandler(gateway Gatewayer, maxAddresses int) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -159,6 +167,10 @@ func getBalanceHandler(gateway Gatewayer) http.HandlerFunc {
 		addrsParam := r.FormValue("addrs")
 		addrsStr := splitCommaString(addrsParam)
 
+		if tooManyAddresses(w, len(addrsStr), maxAddresses) {
+			return
+		}
+
 		addrs := make([]cipher.Address, 0, len(addrsStr))
 		for _, addr := range addrsStr {
 			a, err := cipher.DecodeBase58Address(addr)
@@ -226,6 +238,7 @@ func getBalanceHandler(gateway Gatewayer) http.HandlerFunc {
 //         if this field is not empty, the spend succeeded, but the response data could not be prepared
 func walletSpendHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			wh.Error405(w)
 			return
@@ -261,6 +274,7 @@ func walletSpendHandler(gateway Gatewayer) http.HandlerFunc {
 		}
 
 		tx, err := gateway.Spend(wltID, []byte(r.FormValue("password")), coins, dst)
+		setErrorCode(r, err)
 		switch err {
 		case nil:
 		case fee.ErrTxnNoFee,
@@ -333,6 +347,7 @@ func walletSpendHandler(gateway Gatewayer) http.HandlerFunc {
 //     password: password for encrypting wallet [optional, must be provided if "encrypt" is set]
 func walletCreate(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			wh.Error405(w)
 			return
@@ -400,6 +415,7 @@ func walletCreate(gateway Gatewayer) http.HandlerFunc {
 			ScanN:    scanN,
 		})
 		if err != nil {
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrWalletAPIDisabled:
 				wh.Error403(w, "")
@@ -428,6 +444,7 @@ func walletCreate(gateway Gatewayer) http.HandlerFunc {
 //     password: wallet password [optional, must be provided if the wallet is encrypted]
 func walletNewAddresses(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			wh.Error405(w)
 			return
@@ -458,6 +475,7 @@ func walletNewAddresses(gateway Gatewayer) http.HandlerFunc {
 
 		addrs, err := gateway.NewAddresses(wltID, []byte(password), n)
 		if err != nil {
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrInvalidPassword:
 				wh.Error401(w, HTTP401AuthHeader, err.Error())
@@ -490,6 +508,7 @@ func walletNewAddresses(gateway Gatewayer) http.HandlerFunc {
 //     label: the label the wallet will be updated to [required]
 func walletUpdateHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			wh.Error405(w)
 			return
@@ -511,6 +530,7 @@ func walletUpdateHandler(gateway Gatewayer) http.HandlerFunc {
 		if err := gateway.UpdateWalletLabel(wltID, label); err != nil {
 			logger.Errorf("update wallet label failed: %v", err)
 
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrWalletNotExist:
 				wh.Error404(w, "")
@@ -533,6 +553,7 @@ func walletUpdateHandler(gateway Gatewayer) http.HandlerFunc {
 //     id: wallet id [required]
 func walletGet(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -546,6 +567,7 @@ func walletGet(gateway Gatewayer) http.HandlerFunc {
 
 		wlt, err := gateway.GetWallet(wltID)
 		if err != nil {
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrWalletAPIDisabled:
 				wh.Error403(w, "")
@@ -563,13 +585,15 @@ func walletGet(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
-// Returns JSON of unconfirmed transactions for user's wallet
+// Returns JSON of unconfirmed transactions for user's wallet, with the wallet's notes of the transactions.
+// The notes of an encrypted wallet are encrypted, and are not included.
 // URI: /api/v1/wallet/transactions
 // Method: GET
 // Args:
 //     id: wallet id [required]
 func walletTransactionsHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -584,6 +608,7 @@ func walletTransactionsHandler(gateway Gatewayer) http.HandlerFunc {
 		txns, err := gateway.GetWalletUnconfirmedTxns(wltID)
 		if err != nil {
 			logger.Errorf("get wallet unconfirmed transactions failed: %v", err)
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrWalletNotExist:
 				wh.Error404(w, "")
@@ -604,6 +629,25 @@ func walletTransactionsHandler(gateway Gatewayer) http.HandlerFunc {
 		unconfirmedTxnResp := UnconfirmedTxnsResponse{
 			Transactions: unconfirmedTxns,
 		}
+
+		metadata, err := gateway.GetWalletMetadata(wltID, nil)
+		switch err {
+		case nil:
+			for _, txn := range unconfirmedTxns {
+				if note, ok := metadata.Notes[txn.Txn.Hash]; ok {
+					if unconfirmedTxnResp.Notes == nil {
+						unconfirmedTxnResp.Notes = make(map[string]string)
+					}
+					unconfirmedTxnResp.Notes[txn.Txn.Hash] = note
+				}
+			}
+		case wallet.ErrMissingPassword:
+			// The wallet is encrypted
+		default:
+			wh.Error500(w, err.Error())
+			return
+		}
+
 		wh.SendJSONOr500(logger, w, unconfirmedTxnResp)
 	}
 }
@@ -613,6 +657,7 @@ func walletTransactionsHandler(gateway Gatewayer) http.HandlerFunc {
 // Method: GET
 func walletsHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -620,6 +665,7 @@ func walletsHandler(gateway Gatewayer) http.HandlerFunc {
 
 		wlts, err := gateway.GetWallets()
 		if err != nil {
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrWalletAPIDisabled:
 				wh.Error403(w, "")
@@ -658,6 +704,7 @@ type WalletFolder struct {
 // Method: GET
 func getWalletFolder(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -665,6 +712,7 @@ func getWalletFolder(gateway Gatewayer) http.HandlerFunc {
 
 		addr, err := gateway.GetWalletDir()
 		if err != nil {
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrWalletAPIDisabled:
 				wh.Error403(w, "")
@@ -687,6 +735,7 @@ func getWalletFolder(gateway Gatewayer) http.HandlerFunc {
 //     entropy: entropy bitsize [optional, default value of 128 will be used if not set]
 func newWalletSeed(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodGet {
 			wh.Error405(w)
 			return
@@ -745,6 +794,7 @@ func newWalletSeed(gateway Gatewayer) http.HandlerFunc {
 //     password: wallet password
 func walletSeedHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			wh.Error405(w)
 			return
@@ -763,6 +813,7 @@ func walletSeedHandler(gateway Gatewayer) http.HandlerFunc {
 
 		seed, err := gateway.GetWalletSeed(id, []byte(password))
 		if err != nil {
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrMissingPassword, wallet.ErrWalletNotEncrypted:
 				wh.Error400(w, err.Error())
@@ -795,6 +846,7 @@ func walletSeedHandler(gateway Gatewayer) http.HandlerFunc {
 //     id: wallet id
 func walletUnloadHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			wh.Error405(w)
 			return
@@ -807,6 +859,7 @@ func walletUnloadHandler(gateway Gatewayer) http.HandlerFunc {
 		}
 
 		if err := gateway.UnloadWallet(id); err != nil {
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrWalletAPIDisabled:
 				wh.Error403(w, "")
@@ -825,6 +878,7 @@ func walletUnloadHandler(gateway Gatewayer) http.HandlerFunc {
 //     password: wallet password
 func walletEncryptHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			wh.Error405(w)
 			return
@@ -843,6 +897,7 @@ func walletEncryptHandler(gateway Gatewayer) http.HandlerFunc {
 
 		wlt, err := gateway.EncryptWallet(id, []byte(password))
 		if err != nil {
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrWalletEncrypted, wallet.ErrMissingPassword:
 				wh.Error400(w, err.Error())
@@ -876,6 +931,7 @@ func walletEncryptHandler(gateway Gatewayer) http.HandlerFunc {
 //     password: wallet password
 func walletDecryptHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
 		if r.Method != http.MethodPost {
 			wh.Error405(w)
 			return
@@ -894,6 +950,7 @@ func walletDecryptHandler(gateway Gatewayer) http.HandlerFunc {
 
 		wlt, err := gateway.DecryptWallet(id, []byte(password))
 		if err != nil {
+			setErrorCode(r, err)
 			switch err {
 			case wallet.ErrMissingPassword, wallet.ErrWalletNotEncrypted:
 				wh.Error400(w, err.Error())
@@ -917,3 +974,249 @@ func walletDecryptHandler(gateway Gatewayer) http.HandlerFunc {
 		wh.SendJSONOr500(logger, w, rlt)
 	}
 }
+
+// Changes the password and/or crypto type of an encrypted wallet, without decrypting it on disk
+// URI: /api/v1/wallet/changePassword
+// Method: POST
+// Args:
+//     id: wallet id
+//     old_password: current wallet password
+//     new_password: new wallet password, the password is not changed if empty [optional]
+//     crypto_type: new crypto type, sha256-xor or scrypt-chacha20poly1305 [optional]
+//     scrypt_n: scrypt N parameter, for scrypt-chacha20poly1305 [optional]
+//     scrypt_r: scrypt r parameter, for scrypt-chacha20poly1305 [optional]
+//     scrypt_p: scrypt p parameter, for scrypt-chacha20poly1305 [optional]
+func walletChangePasswordHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		id := r.FormValue("id")
+		if id == "" {
+			wh.Error400(w, "missing wallet id")
+			return
+		}
+
+		oldPassword := r.FormValue("old_password")
+		newPassword := r.FormValue("new_password")
+		defer func() {
+			oldPassword = ""
+			newPassword = ""
+		}()
+
+		if oldPassword == "" {
+			wh.Error400(w, "missing old_password")
+			return
+		}
+
+		opts := wallet.ChangePasswordOptions{
+			NewPassword: []byte(newPassword),
+		}
+
+		if s := r.FormValue("crypto_type"); s != "" {
+			ct, err := wallet.CryptoTypeFromString(s)
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("invalid crypto_type value: %v", err))
+				return
+			}
+			opts.CryptoType = ct
+		}
+
+		sp := wallet.DefaultScryptParams
+		var hasScryptParams bool
+		for _, f := range []struct {
+			name string
+			v    *int
+		}{
+			{"scrypt_n", &sp.N},
+			{"scrypt_r", &sp.R},
+			{"scrypt_p", &sp.P},
+		} {
+			s := r.FormValue(f.name)
+			if s == "" {
+				continue
+			}
+
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("invalid %s value", f.name))
+				return
+			}
+			*f.v = v
+			hasScryptParams = true
+		}
+
+		if hasScryptParams {
+			// Rejects the parameters before the wallet is locked, scrypt could exhaust the memory
+			if err := sp.Validate(); err != nil {
+				wh.Error400(w, err.Error())
+				return
+			}
+			opts.ScryptParams = &sp
+		}
+
+		if len(opts.NewPassword) == 0 && opts.CryptoType == "" && opts.ScryptParams == nil {
+			wh.Error400(w, "new_password, crypto_type or scrypt parameters must be provided")
+			return
+		}
+
+		wlt, err := gateway.ChangeWalletPassword(id, []byte(oldPassword), opts)
+		if err != nil {
+			setErrorCode(r, err)
+			switch err {
+			case wallet.ErrInvalidPassword:
+				wh.Error401(w, HTTP401AuthHeader, err.Error())
+			case wallet.ErrWalletAPIDisabled:
+				wh.Error403(w, "")
+			case wallet.ErrWalletNotExist:
+				wh.Error404(w, "")
+			default:
+				switch err.(type) {
+				case wallet.Error:
+					wh.Error400(w, err.Error())
+				default:
+					wh.Error500(w, err.Error())
+				}
+			}
+			return
+		}
+
+		rlt, err := NewWalletResponse(wlt)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+		wh.SendJSONOr500(logger, w, rlt)
+	}
+}
+
+// Exports wallet to a password encrypted bundle, with its notes, labels and contacts
+// URI: /api/v1/wallet/export
+// Method: POST
+// Args:
+//     id: wallet id
+//     password: bundle password
+//     watch_only: leave out the seeds and secret keys [optional]
+//     wallet_password: wallet password, required to export an encrypted wallet with watch_only [optional]
+func walletExportHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		id := r.FormValue("id")
+		if id == "" {
+			wh.Error400(w, "missing wallet id")
+			return
+		}
+
+		password := r.FormValue("password")
+		defer func() {
+			password = ""
+		}()
+
+		if password == "" {
+			wh.Error400(w, "missing password")
+			return
+		}
+
+		var watchOnly bool
+		if s := r.FormValue("watch_only"); s != "" {
+			var err error
+			watchOnly, err = strconv.ParseBool(s)
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("invalid watch_only value: %v", err))
+				return
+			}
+		}
+
+		walletPassword := r.FormValue("wallet_password")
+		defer func() {
+			walletPassword = ""
+		}()
+
+		bundle, err := gateway.ExportWallet(id, []byte(password), []byte(walletPassword), watchOnly)
+		if err != nil {
+			setErrorCode(r, err)
+			switch err {
+			case wallet.ErrMissingPassword:
+				wh.Error400(w, "missing wallet_password, required to export an encrypted wallet with watch_only")
+			case wallet.ErrInvalidPassword:
+				wh.Error401(w, HTTP401AuthHeader, err.Error())
+			case wallet.ErrWalletAPIDisabled:
+				wh.Error403(w, "")
+			case wallet.ErrWalletNotExist:
+				wh.Error404(w, "")
+			default:
+				wh.Error500(w, err.Error())
+			}
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, bundle)
+	}
+}
+
+// Imports wallet from a password encrypted bundle, made by /api/v1/wallet/export
+// URI: /api/v1/wallet/import
+// Method: POST
+// Args:
+//     bundle: JSON of the bundle
+//     password: bundle password
+func walletImportHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gateway := requestGateway(gateway, r)
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		bundleStr := r.FormValue("bundle")
+		if bundleStr == "" {
+			wh.Error400(w, "missing bundle")
+			return
+		}
+
+		var bundle wallet.EncryptedBundle
+		if err := json.Unmarshal([]byte(bundleStr), &bundle); err != nil {
+			wh.Error400(w, fmt.Sprintf("invalid bundle: %v", err))
+			return
+		}
+
+		password := r.FormValue("password")
+		defer func() {
+			password = ""
+		}()
+
+		wlt, err := gateway.ImportWallet(bundle, []byte(password))
+		if err != nil {
+			setErrorCode(r, err)
+			switch err {
+			case wallet.ErrInvalidPassword:
+				wh.Error401(w, HTTP401AuthHeader, err.Error())
+			case wallet.ErrWalletAPIDisabled:
+				wh.Error403(w, "")
+			default:
+				switch err.(type) {
+				case wallet.Error:
+					wh.Error400(w, err.Error())
+				default:
+					wh.Error500(w, err.Error())
+				}
+			}
+			return
+		}
+
+		rlt, err := NewWalletResponse(wlt)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+		wh.SendJSONOr500(logger, w, rlt)
+	}
+}
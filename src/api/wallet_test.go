@@ -16,6 +16,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/util/fee"
 	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/wallet"
@@ -886,6 +887,8 @@ func TestWalletTransactionsHandler(t *testing.T) {
 		walletID                              string
 		gatewayGetWalletUnconfirmedTxnsResult []visor.UnconfirmedTxn
 		gatewayGetWalletUnconfirmedTxnsErr    error
+		gatewayGetWalletMetadataResult        *wallet.Metadata
+		gatewayGetWalletMetadataErr           error
 		responseBody                          UnconfirmedTxnsResponse
 	}{
 		{
@@ -943,13 +946,63 @@ func TestWalletTransactionsHandler(t *testing.T) {
 			err:      "",
 			walletID: "foo",
 			gatewayGetWalletUnconfirmedTxnsResult: make([]visor.UnconfirmedTxn, 1),
+			gatewayGetWalletMetadataResult:        wallet.NewMetadata(),
 			responseBody:                          UnconfirmedTxnsResponse{Transactions: []visor.ReadableUnconfirmedTxn{*unconfirmedTxn}},
 		},
+		{
+			name:   "200 - OK, with notes",
+			method: http.MethodGet,
+			body: &httpBody{
+				WalletID: "foo",
+			},
+			status:   http.StatusOK,
+			err:      "",
+			walletID: "foo",
+			gatewayGetWalletUnconfirmedTxnsResult: make([]visor.UnconfirmedTxn, 1),
+			gatewayGetWalletMetadataResult: &wallet.Metadata{
+				Notes: map[string]string{
+					unconfirmedTxn.Txn.Hash:      "note",
+					testutil.RandSHA256(t).Hex(): "other transaction",
+				},
+			},
+			responseBody: UnconfirmedTxnsResponse{
+				Transactions: []visor.ReadableUnconfirmedTxn{*unconfirmedTxn},
+				Notes: map[string]string{
+					unconfirmedTxn.Txn.Hash: "note",
+				},
+			},
+		},
+		{
+			name:   "200 - OK, encrypted wallet",
+			method: http.MethodGet,
+			body: &httpBody{
+				WalletID: "foo",
+			},
+			status:   http.StatusOK,
+			err:      "",
+			walletID: "foo",
+			gatewayGetWalletUnconfirmedTxnsResult: make([]visor.UnconfirmedTxn, 1),
+			gatewayGetWalletMetadataErr:           wallet.ErrMissingPassword,
+			responseBody:                          UnconfirmedTxnsResponse{Transactions: []visor.ReadableUnconfirmedTxn{*unconfirmedTxn}},
+		},
+		{
+			name:   "500 - gateway.GetWalletMetadata error",
+			method: http.MethodGet,
+			body: &httpBody{
+				WalletID: "foo",
+			},
+			status:   http.StatusInternalServerError,
+			err:      "500 Internal Server Error - gateway.GetWalletMetadata error",
+			walletID: "foo",
+			gatewayGetWalletUnconfirmedTxnsResult: make([]visor.UnconfirmedTxn, 1),
+			gatewayGetWalletMetadataErr:           errors.New("gateway.GetWalletMetadata error"),
+		},
 	}
 
 	for _, tc := range tt {
 		gateway := &GatewayerMock{}
 		gateway.On("GetWalletUnconfirmedTxns", tc.walletID).Return(tc.gatewayGetWalletUnconfirmedTxnsResult, tc.gatewayGetWalletUnconfirmedTxnsErr)
+		gateway.On("GetWalletMetadata", tc.walletID, []byte(nil)).Return(tc.gatewayGetWalletMetadataResult, tc.gatewayGetWalletMetadataErr)
 
 		endpoint := "/api/v1/wallet/transactions"
 
@@ -990,6 +1043,7 @@ func TestWalletTransactionsHandler(t *testing.T) {
 			require.IsType(t, msg, tc.responseBody)
 			require.Len(t, msg.Transactions, 1)
 			require.Equal(t, msg.Transactions[0].Txn, tc.responseBody.Transactions[0].Txn)
+			require.Equal(t, tc.responseBody.Notes, msg.Notes)
 		}
 	}
 }
@@ -2551,3 +2605,546 @@ func cloneEntries(es []wallet.Entry) []wallet.Entry {
 	}
 	return entries
 }
+
+func TestWalletExportHandler(t *testing.T) {
+	bundle := &wallet.EncryptedBundle{
+		Version:    wallet.BundleVersion,
+		CryptoType: wallet.CryptoTypeScryptChacha20poly1305,
+		Data:       "data",
+	}
+
+	tt := []struct {
+		name            string
+		method          string
+		status          int
+		err             string
+		walletID        string
+		password        string
+		watchOnly       string
+		walletPassword  string
+		expectWatchOnly bool
+		gatewayReturn   *wallet.EncryptedBundle
+		gatewayErr      error
+	}{
+		{
+			name:     "405",
+			method:   http.MethodGet,
+			status:   http.StatusMethodNotAllowed,
+			err:      "405 Method Not Allowed",
+			walletID: "wallet.wlt",
+		},
+		{
+			name:     "400 - missing wallet id",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing wallet id",
+			password: "pwd",
+		},
+		{
+			name:     "400 - missing password",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing password",
+			walletID: "wallet.wlt",
+		},
+		{
+			name:      "400 - invalid watch_only",
+			method:    http.MethodPost,
+			status:    http.StatusBadRequest,
+			err:       "400 Bad Request - invalid watch_only value: strconv.ParseBool: parsing \"foo\": invalid syntax",
+			walletID:  "wallet.wlt",
+			password:  "pwd",
+			watchOnly: "foo",
+		},
+		{
+			name:            "400 - missing wallet_password",
+			method:          http.MethodPost,
+			status:          http.StatusBadRequest,
+			err:             "400 Bad Request - missing wallet_password, required to export an encrypted wallet with watch_only",
+			walletID:        "wallet.wlt",
+			password:        "pwd",
+			watchOnly:       "true",
+			expectWatchOnly: true,
+			gatewayErr:      wallet.ErrMissingPassword,
+		},
+		{
+			name:            "401 - invalid wallet_password",
+			method:          http.MethodPost,
+			status:          http.StatusUnauthorized,
+			err:             "401 Unauthorized - invalid password",
+			walletID:        "wallet.wlt",
+			password:        "pwd",
+			watchOnly:       "true",
+			walletPassword:  "wrong",
+			expectWatchOnly: true,
+			gatewayErr:      wallet.ErrInvalidPassword,
+		},
+		{
+			name:       "403 - Forbidden - wallet API disabled",
+			method:     http.MethodPost,
+			status:     http.StatusForbidden,
+			err:        "403 Forbidden",
+			walletID:   "wallet.wlt",
+			password:   "pwd",
+			gatewayErr: wallet.ErrWalletAPIDisabled,
+		},
+		{
+			name:       "404 - wallet does not exist",
+			method:     http.MethodPost,
+			status:     http.StatusNotFound,
+			err:        "404 Not Found",
+			walletID:   "wallet.wlt",
+			password:   "pwd",
+			gatewayErr: wallet.ErrWalletNotExist,
+		},
+		{
+			name:          "200 - ok",
+			method:        http.MethodPost,
+			status:        http.StatusOK,
+			walletID:      "wallet.wlt",
+			password:      "pwd",
+			gatewayReturn: bundle,
+		},
+		{
+			name:            "200 - ok, watch-only",
+			method:          http.MethodPost,
+			status:          http.StatusOK,
+			walletID:        "wallet.wlt",
+			password:        "pwd",
+			watchOnly:       "true",
+			expectWatchOnly: true,
+			gatewayReturn:   bundle,
+		},
+		{
+			name:            "200 - ok, watch-only encrypted wallet",
+			method:          http.MethodPost,
+			status:          http.StatusOK,
+			walletID:        "wallet.wlt",
+			password:        "pwd",
+			watchOnly:       "true",
+			walletPassword:  "wltpwd",
+			expectWatchOnly: true,
+			gatewayReturn:   bundle,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &GatewayerMock{}
+			gateway.On("ExportWallet", tc.walletID, []byte(tc.password), []byte(tc.walletPassword), tc.expectWatchOnly).Return(tc.gatewayReturn, tc.gatewayErr)
+
+			endpoint := "/api/v1/wallet/export"
+			v := url.Values{}
+			v.Add("id", tc.walletID)
+			v.Add("password", tc.password)
+			if tc.watchOnly != "" {
+				v.Add("watch_only", tc.watchOnly)
+			}
+			if tc.walletPassword != "" {
+				v.Add("wallet_password", tc.walletPassword)
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(v.Encode()))
+			require.NoError(t, err)
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(mxConfig, gateway, csrfStore, nil)
+
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "wrong status code: got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var r wallet.EncryptedBundle
+			err = json.Unmarshal(rr.Body.Bytes(), &r)
+			require.NoError(t, err)
+			require.Equal(t, *tc.gatewayReturn, r)
+		})
+	}
+}
+
+func TestWalletImportHandler(t *testing.T) {
+	bundle := wallet.EncryptedBundle{
+		Version:    wallet.BundleVersion,
+		CryptoType: wallet.CryptoTypeScryptChacha20poly1305,
+		Data:       "data",
+	}
+	bundleJSON, err := json.Marshal(bundle)
+	require.NoError(t, err)
+
+	w, err := wallet.NewWallet("wallet.wlt", wallet.Options{
+		Seed:  "seed",
+		Label: "label",
+	})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		method        string
+		status        int
+		err           string
+		bundle        string
+		password      string
+		gatewayReturn *wallet.Wallet
+		gatewayErr    error
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:     "400 - missing bundle",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing bundle",
+			password: "pwd",
+		},
+		{
+			name:     "400 - invalid bundle",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - invalid bundle: invalid character 'x' looking for beginning of value",
+			bundle:   "xyz",
+			password: "pwd",
+		},
+		{
+			name:       "400 - seed used",
+			method:     http.MethodPost,
+			status:     http.StatusBadRequest,
+			err:        "400 Bad Request - a wallet already exists with this seed",
+			bundle:     string(bundleJSON),
+			password:   "pwd",
+			gatewayErr: wallet.ErrSeedUsed,
+		},
+		{
+			name:       "400 - invalid scrypt parameters",
+			method:     http.MethodPost,
+			status:     http.StatusBadRequest,
+			err:        "400 Bad Request - invalid scrypt parameters",
+			bundle:     string(bundleJSON),
+			password:   "pwd",
+			gatewayErr: wallet.ErrInvalidScryptParams,
+		},
+		{
+			name:       "401 - invalid password",
+			method:     http.MethodPost,
+			status:     http.StatusUnauthorized,
+			err:        "401 Unauthorized - invalid password",
+			bundle:     string(bundleJSON),
+			password:   "pwd",
+			gatewayErr: wallet.ErrInvalidPassword,
+		},
+		{
+			name:       "403 - Forbidden - wallet API disabled",
+			method:     http.MethodPost,
+			status:     http.StatusForbidden,
+			err:        "403 Forbidden",
+			bundle:     string(bundleJSON),
+			password:   "pwd",
+			gatewayErr: wallet.ErrWalletAPIDisabled,
+		},
+		{
+			name:       "500 - gateway error",
+			method:     http.MethodPost,
+			status:     http.StatusInternalServerError,
+			err:        "500 Internal Server Error - gateway error",
+			bundle:     string(bundleJSON),
+			password:   "pwd",
+			gatewayErr: errors.New("gateway error"),
+		},
+		{
+			name:          "200 - ok",
+			method:        http.MethodPost,
+			status:        http.StatusOK,
+			bundle:        string(bundleJSON),
+			password:      "pwd",
+			gatewayReturn: w,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &GatewayerMock{}
+			gateway.On("ImportWallet", bundle, []byte(tc.password)).Return(tc.gatewayReturn, tc.gatewayErr)
+
+			endpoint := "/api/v1/wallet/import"
+			v := url.Values{}
+			v.Add("bundle", tc.bundle)
+			v.Add("password", tc.password)
+
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(v.Encode()))
+			require.NoError(t, err)
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(mxConfig, gateway, csrfStore, nil)
+
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "wrong status code: got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			expect, err := NewWalletResponse(tc.gatewayReturn)
+			require.NoError(t, err)
+
+			var r WalletResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &r)
+			require.NoError(t, err)
+			require.Equal(t, *expect, r)
+		})
+	}
+}
+
+func TestWalletChangePasswordHandler(t *testing.T) {
+	w, err := wallet.NewWallet("wallet.wlt", wallet.Options{
+		Seed:       "seed",
+		Label:      "label",
+		Encrypt:    true,
+		Password:   []byte("pwd"),
+		CryptoType: wallet.CryptoTypeSha256Xor,
+	})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		method        string
+		status        int
+		err           string
+		walletID      string
+		oldPassword   string
+		form          map[string]string
+		opts          wallet.ChangePasswordOptions
+		gatewayReturn *wallet.Wallet
+		gatewayErr    error
+	}{
+		{
+			name:     "405",
+			method:   http.MethodGet,
+			status:   http.StatusMethodNotAllowed,
+			err:      "405 Method Not Allowed",
+			walletID: "wallet.wlt",
+		},
+		{
+			name:        "400 - missing wallet id",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			err:         "400 Bad Request - missing wallet id",
+			oldPassword: "pwd",
+		},
+		{
+			name:     "400 - missing old password",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - missing old_password",
+			walletID: "wallet.wlt",
+			form:     map[string]string{"new_password": "newpwd"},
+		},
+		{
+			name:        "400 - nothing to change",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			err:         "400 Bad Request - new_password, crypto_type or scrypt parameters must be provided",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+		},
+		{
+			name:        "400 - invalid crypto type",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			err:         "400 Bad Request - invalid crypto_type value: unknown crypto type",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form:        map[string]string{"crypto_type": "foo"},
+		},
+		{
+			name:        "400 - invalid scrypt_n",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			err:         "400 Bad Request - invalid scrypt_n value",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form:        map[string]string{"scrypt_n": "foo"},
+		},
+		{
+			name:        "400 - invalid scrypt parameters",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			err:         "400 Bad Request - invalid scrypt parameters",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form:        map[string]string{"scrypt_n": "1000"},
+		},
+		{
+			name:        "400 - scrypt_n below the minimum",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			err:         "400 Bad Request - invalid scrypt parameters",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form:        map[string]string{"scrypt_n": "8192"},
+		},
+		{
+			name:        "400 - scrypt memory too large",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			err:         "400 Bad Request - invalid scrypt parameters",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form:        map[string]string{"scrypt_n": "1073741824", "scrypt_r": "8"},
+		},
+		{
+			name:        "400 - scrypt_p too large",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			err:         "400 Bad Request - invalid scrypt parameters",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form:        map[string]string{"scrypt_n": "16384", "scrypt_p": "5"},
+		},
+		{
+			name:        "400 - gateway invalid scrypt parameters",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			err:         "400 Bad Request - invalid scrypt parameters",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form:        map[string]string{"scrypt_n": "16384"},
+			opts: wallet.ChangePasswordOptions{
+				NewPassword:  []byte{},
+				ScryptParams: &wallet.ScryptParams{N: 16384, R: wallet.DefaultScryptParams.R, P: wallet.DefaultScryptParams.P},
+			},
+			gatewayErr: wallet.ErrInvalidScryptParams,
+		},
+		{
+			name:        "401 - invalid password",
+			method:      http.MethodPost,
+			status:      http.StatusUnauthorized,
+			err:         "401 Unauthorized - invalid password",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form:        map[string]string{"new_password": "newpwd"},
+			opts: wallet.ChangePasswordOptions{
+				NewPassword: []byte("newpwd"),
+			},
+			gatewayErr: wallet.ErrInvalidPassword,
+		},
+		{
+			name:        "403 - Forbidden - wallet API disabled",
+			method:      http.MethodPost,
+			status:      http.StatusForbidden,
+			err:         "403 Forbidden",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form:        map[string]string{"new_password": "newpwd"},
+			opts: wallet.ChangePasswordOptions{
+				NewPassword: []byte("newpwd"),
+			},
+			gatewayErr: wallet.ErrWalletAPIDisabled,
+		},
+		{
+			name:        "404 - wallet does not exist",
+			method:      http.MethodPost,
+			status:      http.StatusNotFound,
+			err:         "404 Not Found",
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form:        map[string]string{"new_password": "newpwd"},
+			opts: wallet.ChangePasswordOptions{
+				NewPassword: []byte("newpwd"),
+			},
+			gatewayErr: wallet.ErrWalletNotExist,
+		},
+		{
+			name:        "200 - ok",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			walletID:    "wallet.wlt",
+			oldPassword: "pwd",
+			form: map[string]string{
+				"new_password": "newpwd",
+				"crypto_type":  "scrypt-chacha20poly1305",
+				"scrypt_n":     "16384",
+				"scrypt_r":     "4",
+				"scrypt_p":     "2",
+			},
+			opts: wallet.ChangePasswordOptions{
+				NewPassword:  []byte("newpwd"),
+				CryptoType:   wallet.CryptoTypeScryptChacha20poly1305,
+				ScryptParams: &wallet.ScryptParams{N: 16384, R: 4, P: 2},
+			},
+			gatewayReturn: w,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.opts.NewPassword == nil {
+				tc.opts.NewPassword = []byte{}
+			}
+
+			gateway := &GatewayerMock{}
+			gateway.On("ChangeWalletPassword", tc.walletID, []byte(tc.oldPassword), tc.opts).Return(tc.gatewayReturn, tc.gatewayErr)
+
+			endpoint := "/api/v1/wallet/changePassword"
+			v := url.Values{}
+			v.Add("id", tc.walletID)
+			v.Add("old_password", tc.oldPassword)
+			for k, x := range tc.form {
+				v.Add(k, x)
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(v.Encode()))
+			require.NoError(t, err)
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			csrfStore := &CSRFStore{
+				Enabled: true,
+			}
+			setCSRFParameters(csrfStore, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(mxConfig, gateway, csrfStore, nil)
+
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "wrong status code: got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			expect, err := NewWalletResponse(tc.gatewayReturn)
+			require.NoError(t, err)
+
+			var r WalletResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &r)
+			require.NoError(t, err)
+			require.Equal(t, *expect, r)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/api/webrpc"
+	wh "github.com/skycoin/skycoin/src/util/http" //http,json helpers
+)
+
+// webrpcMethodScopes are the scopes required to call the methods of the JSON-RPC 2.0 API,
+// which are the scopes of the equivalent REST endpoints
+var webrpcMethodScopes = map[string]Scope{
+	"get_status":               ScopeRead,
+	"get_blocks_by_seq":        ScopeRead,
+	"get_lastblocks":           ScopeRead,
+	"get_blocks":               ScopeRead,
+	"get_outputs":              ScopeRead,
+	"get_transaction":          ScopeRead,
+	"get_address_uxouts":       ScopeRead,
+	"get_balance":              ScopeRead,
+	"get_transactions":         ScopeRead,
+	"get_pending_transactions": ScopeRead,
+	"get_coin_supply":          ScopeRead,
+	"get_network_info":         ScopeAdmin,
+	"inject_transaction":       ScopeAdmin,
+	"get_wallets":              ScopeWalletRead,
+	"get_wallet_balance":       ScopeWalletRead,
+	"create_wallet":            ScopeWalletSpend,
+	"new_addresses":            ScopeWalletSpend,
+	"spend":                    ScopeWalletSpend,
+}
+
+// webrpcCheck returns a webrpc.CheckFunc that responds without handling any of the requests
+// if the API token of the request does not have the scope of one of the methods, with 403 Forbidden,
+// or if the client exceeds a rate limit with the requests of a batch, with 429 Too Many Requests.
+// A batch larger than the burst of a rate limit can never be allowed, so it is rejected with
+// 413 Request Entity Too Large and a JSON-RPC error, without a Retry-After header.
+// If tokens is nil, API authentication is disabled and all methods are allowed.
+func webrpcCheck(tokens *TokenStore, limiter, expensiveLimiter *RateLimiter) webrpc.CheckFunc {
+	return func(w http.ResponseWriter, r *http.Request, methods []string) bool {
+		if tokens != nil {
+			t := apiTokenFromContext(r.Context())
+			for _, m := range methods {
+				// Unknown methods are not handled, and only need the read scope of the endpoint
+				scope, ok := webrpcMethodScopes[m]
+				if !ok {
+					continue
+				}
+
+				if t == nil || !t.Allows(scope) {
+					logger.Warningf("API token does not have the %s scope of JSON-RPC method %s", scope, m)
+					scopeError(w, scope)
+					return false
+				}
+			}
+		}
+
+		// The HTTP request took one token from each limiter. Each other request of a batch takes one more
+		if len(methods) > 1 {
+			client := rateLimitClient(r, tokens != nil)
+			for _, l := range []*RateLimiter{limiter, expensiveLimiter} {
+				if l == nil {
+					continue
+				}
+
+				// A batch larger than the burst would never be allowed
+				if burst := l.Stats().Burst; len(methods) > burst {
+					logger.Warningf("JSON-RPC batch of %d requests by %s exceeds the rate limit burst of %d", len(methods), client, burst)
+					res := webrpc.MakeErrorResponse(webrpc.ErrCodeInvalidRequest, fmt.Sprintf("batch of %d requests exceeds the rate limit burst of %d", len(methods), burst))
+					wh.Error413JSONOr500(logger, w, &res)
+					return false
+				}
+
+				if !checkRateLimit(w, r, l, client, len(methods)-1) {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+}
@@ -0,0 +1,76 @@
+package webrpc
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// BalanceResult the balance json format, of get_balance and get_wallet_balance
+type BalanceResult struct {
+	wallet.BalancePair
+	Addresses wallet.AddressBalance `json:"addresses"`
+}
+
+// request params: [addr1, addr2, ...]
+func getBalanceHandler(req Request, gateway Gatewayer) Response {
+	var addrs []string
+	if err := req.DecodeParams(&addrs); err != nil {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	if len(addrs) == 0 {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	as := make([]cipher.Address, len(addrs))
+	for i, a := range addrs {
+		addr, err := cipher.DecodeBase58Address(a)
+		if err != nil {
+			return MakeErrorResponse(ErrCodeInvalidParams, fmt.Sprintf("invalid address: %v", a))
+		}
+		as[i] = addr
+	}
+
+	bals, err := gateway.GetBalanceOfAddrs(as)
+	if err != nil {
+		logger.Errorf("get balance failed: %v", err)
+		return MakeErrorResponse(ErrCodeInternalError, fmt.Sprintf("gateway.GetBalanceOfAddrs failed: %v", err))
+	}
+
+	addressBalances := make(wallet.AddressBalance, len(as))
+	for i, addr := range as {
+		addressBalances[addr.String()] = bals[i]
+	}
+
+	res, err := newBalanceResult(addressBalances)
+	if err != nil {
+		logger.Error(err)
+		return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+	}
+
+	return makeSuccessResponse(req.ID, res)
+}
+
+// newBalanceResult sums the balances of addresses
+func newBalanceResult(addressBalances wallet.AddressBalance) (*BalanceResult, error) {
+	res := BalanceResult{
+		Addresses: addressBalances,
+	}
+
+	for _, bal := range addressBalances {
+		var err error
+		res.Confirmed, err = res.Confirmed.Add(bal.Confirmed)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Predicted, err = res.Predicted.Add(bal.Predicted)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,91 @@
+package webrpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func Test_getBalanceHandler(t *testing.T) {
+	addr1 := testutil.MakeAddress()
+	addr2 := testutil.MakeAddress()
+
+	bal1 := wallet.BalancePair{
+		Confirmed: wallet.Balance{Coins: 1000000, Hours: 10},
+		Predicted: wallet.Balance{Coins: 1000000, Hours: 10},
+	}
+	bal2 := wallet.BalancePair{
+		Confirmed: wallet.Balance{Coins: 2000000, Hours: 5},
+		Predicted: wallet.Balance{Coins: 1500000, Hours: 4},
+	}
+
+	tests := []struct {
+		name   string
+		params string
+		addrs  []cipher.Address
+		bals   []wallet.BalancePair
+		err    error
+		want   Response
+	}{
+		{
+			name:   "normal",
+			params: `["` + addr1.String() + `","` + addr2.String() + `"]`,
+			addrs:  []cipher.Address{addr1, addr2},
+			bals:   []wallet.BalancePair{bal1, bal2},
+			want: makeSuccessResponse("1", BalanceResult{
+				BalancePair: wallet.BalancePair{
+					Confirmed: wallet.Balance{Coins: 3000000, Hours: 15},
+					Predicted: wallet.Balance{Coins: 2500000, Hours: 14},
+				},
+				Addresses: wallet.AddressBalance{
+					addr1.String(): bal1,
+					addr2.String(): bal2,
+				},
+			}),
+		},
+		{
+			name:   "invalid params type",
+			params: `"foo"`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams),
+		},
+		{
+			name:   "no addresses",
+			params: `[]`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams),
+		},
+		{
+			name:   "invalid address",
+			params: `["foo"]`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, "invalid address: foo"),
+		},
+		{
+			name:   "gateway error",
+			params: `["` + addr1.String() + `"]`,
+			addrs:  []cipher.Address{addr1},
+			err:    errors.New("failed"),
+			want:   MakeErrorResponse(ErrCodeInternalError, "gateway.GetBalanceOfAddrs failed: failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGatewayerMock()
+			m.On("GetBalanceOfAddrs", tt.addrs).Return(tt.bals, tt.err)
+
+			req := Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Method:  "get_balance",
+				Params:  []byte(tt.params),
+			}
+
+			got := getBalanceHandler(req, m)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
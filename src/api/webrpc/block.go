@@ -1,56 +1,84 @@
 package webrpc
 
+import "fmt"
+
+// errBlockRangeTooLarge is the response to a request for more than maxBlockRange blocks
+func errBlockRangeTooLarge(maxBlockRange uint64) Response {
+	return MakeErrorResponse(ErrCodeInvalidParams, fmt.Sprintf("Block range is too large, at most %d blocks can be requested", maxBlockRange))
+}
+
 // request params: [seq1, seq2, seq3...]
-func getBlocksBySeqHandler(req Request, gateway Gatewayer) Response {
-	var seqs []uint64
-	if err := req.DecodeParams(&seqs); err != nil {
-		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
-	}
+func getBlocksBySeqHandler(maxBlockRange uint64) HandlerFunc {
+	return func(req Request, gateway Gatewayer) Response {
+		var seqs []uint64
+		if err := req.DecodeParams(&seqs); err != nil {
+			return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+		}
 
-	if len(seqs) == 0 {
-		return MakeErrorResponse(ErrCodeInvalidParams, "empty params")
-	}
-	blocks, err := gateway.GetBlocksInDepth(seqs)
-	if err != nil {
-		logger.Error(err)
-		return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+		if len(seqs) == 0 {
+			return MakeErrorResponse(ErrCodeInvalidParams, "empty params")
+		}
+
+		if maxBlockRange > 0 && uint64(len(seqs)) > maxBlockRange {
+			return errBlockRangeTooLarge(maxBlockRange)
+		}
+
+		blocks, err := gateway.GetBlocksInDepth(seqs)
+		if err != nil {
+			logger.Error(err)
+			return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+		}
+		return makeSuccessResponse(req.ID, blocks)
 	}
-	return makeSuccessResponse(req.ID, blocks)
 }
 
 // request params: [number]
-func getLastBlocksHandler(req Request, gateway Gatewayer) Response {
-	// validate the req params
-	var num []uint64
-	if err := req.DecodeParams(&num); err != nil {
-		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
-	}
+func getLastBlocksHandler(maxBlockRange uint64) HandlerFunc {
+	return func(req Request, gateway Gatewayer) Response {
+		// validate the req params
+		var num []uint64
+		if err := req.DecodeParams(&num); err != nil {
+			return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+		}
 
-	if len(num) != 1 {
-		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
-	}
+		if len(num) != 1 {
+			return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+		}
+
+		if maxBlockRange > 0 && num[0] > maxBlockRange {
+			return errBlockRangeTooLarge(maxBlockRange)
+		}
 
-	blocks, err := gateway.GetLastBlocks(num[0])
-	if err != nil {
-		logger.Error(err)
-		return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+		blocks, err := gateway.GetLastBlocks(num[0])
+		if err != nil {
+			logger.Error(err)
+			return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+		}
+		return makeSuccessResponse(req.ID, blocks)
 	}
-	return makeSuccessResponse(req.ID, blocks)
 }
 
-func getBlocksHandler(req Request, gateway Gatewayer) Response {
-	var params []uint64
-	if err := req.DecodeParams(&params); err != nil {
-		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
-	}
+// request params: [start, end]
+func getBlocksHandler(maxBlockRange uint64) HandlerFunc {
+	return func(req Request, gateway Gatewayer) Response {
+		var params []uint64
+		if err := req.DecodeParams(&params); err != nil {
+			return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+		}
 
-	if len(params) != 2 {
-		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
-	}
+		if len(params) != 2 {
+			return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+		}
+
+		start, end := params[0], params[1]
+		if maxBlockRange > 0 && end >= start && end-start >= maxBlockRange {
+			return errBlockRangeTooLarge(maxBlockRange)
+		}
 
-	blocks, err := gateway.GetBlocks(params[0], params[1])
-	if err != nil {
-		return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+		blocks, err := gateway.GetBlocks(start, end)
+		if err != nil {
+			return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+		}
+		return makeSuccessResponse(req.ID, blocks)
 	}
-	return makeSuccessResponse(req.ID, blocks)
 }
@@ -153,7 +153,7 @@ func Test_getLastBlocksHandler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getLastBlocksHandler(tt.args.req, tt.args.gateway)
+			got := getLastBlocksHandler(0)(tt.args.req, tt.args.gateway)
 			require.Equal(t, tt.want, got)
 		})
 	}
@@ -251,7 +251,7 @@ func Test_getBlocksHandler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getBlocksHandler(tt.args.req, tt.args.gateway)
+			got := getBlocksHandler(0)(tt.args.req, tt.args.gateway)
 			require.Equal(t, tt.want, got)
 		})
 	}
@@ -328,7 +328,66 @@ func Test_getBlocksBySeqHandler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getBlocksBySeqHandler(tt.args.req, tt.args.gateway)
+			got := getBlocksBySeqHandler(0)(tt.args.req, tt.args.gateway)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBlockRangeLimits(t *testing.T) {
+	tooLarge := MakeErrorResponse(ErrCodeInvalidParams, "Block range is too large, at most 2 blocks can be requested")
+
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+		params  string
+		want    Response
+	}{
+		{
+			name:    "get_lastblocks at the limit",
+			handler: getLastBlocksHandler(2),
+			params:  "[2]",
+			want:    makeSuccessResponse("1", decodeBlock(blockString)),
+		},
+		{
+			name:    "get_lastblocks over the limit",
+			handler: getLastBlocksHandler(2),
+			params:  "[3]",
+			want:    tooLarge,
+		},
+		{
+			name:    "get_blocks at the limit",
+			handler: getBlocksHandler(2),
+			params:  "[0,1]",
+			want:    makeSuccessResponse("1", decodeBlock(blockString)),
+		},
+		{
+			name:    "get_blocks over the limit",
+			handler: getBlocksHandler(2),
+			params:  "[0,2]",
+			want:    tooLarge,
+		},
+		{
+			name:    "get_blocks_by_seq at the limit",
+			handler: getBlocksBySeqHandler(2),
+			params:  "[0,1]",
+			want:    makeSuccessResponse("1", nil),
+		},
+		{
+			name:    "get_blocks_by_seq over the limit",
+			handler: getBlocksBySeqHandler(2),
+			params:  "[0,1,2]",
+			want:    tooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.handler(Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Params:  []byte(tt.params),
+			}, &fakeGateway{})
 			require.Equal(t, tt.want, got)
 		})
 	}
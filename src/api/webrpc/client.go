@@ -43,7 +43,9 @@ type Client struct {
 	Addr       string
 	HTTPClient *http.Client
 	UseCSRF    bool
-	reqIDCtr   int
+	// AuthToken is sent as a Bearer token, if the node requires API authentication
+	AuthToken string
+	reqIDCtr  int
 }
 
 // NewClient creates a Client
@@ -75,17 +77,9 @@ func NewClient(addr string) (*Client, error) {
 func (c *Client) Do(obj interface{}, method string, params interface{}) error {
 	c.reqIDCtr++
 
-	var csrf string
-	if c.UseCSRF {
-		var err error
-		csrf, err = c.CSRF()
-		if err != nil {
-			return err
-		}
-
-		if csrf == "" {
-			return errors.New("Remote node has CSRF disabled")
-		}
+	csrf, err := c.csrfToken()
+	if err != nil {
+		return err
 	}
 
 	req, err := NewRequest(method, params, strconv.Itoa(c.reqIDCtr))
@@ -93,8 +87,8 @@ func (c *Client) Do(obj interface{}, method string, params interface{}) error {
 		return err
 	}
 
-	rsp, err := do(c.HTTPClient, req, c.Addr, csrf)
-	if err != nil {
+	rsp := Response{}
+	if err := do(c.HTTPClient, req, &rsp, c.Addr, csrf, c.AuthToken); err != nil {
 		return err
 	}
 
@@ -105,6 +99,45 @@ func (c *Client) Do(obj interface{}, method string, params interface{}) error {
 	return decodeJSON(rsp.Result, obj)
 }
 
+// DoBatch makes a batch of RPC requests in a single HTTP request.
+// Responses are returned in the order the node answered them; match them to requests by ID.
+// Requests that fail individually are reported in the Error field of their response.
+func (c *Client) DoBatch(reqs []*Request) ([]Response, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("empty batch")
+	}
+
+	csrf, err := c.csrfToken()
+	if err != nil {
+		return nil, err
+	}
+
+	rsps := []Response{}
+	if err := do(c.HTTPClient, reqs, &rsps, c.Addr, csrf, c.AuthToken); err != nil {
+		return nil, err
+	}
+
+	return rsps, nil
+}
+
+// csrfToken returns a CSRF token if UseCSRF is set
+func (c *Client) csrfToken() (string, error) {
+	if !c.UseCSRF {
+		return "", nil
+	}
+
+	csrf, err := c.CSRF()
+	if err != nil {
+		return "", err
+	}
+
+	if csrf == "" {
+		return "", errors.New("Remote node has CSRF disabled")
+	}
+
+	return csrf, nil
+}
+
 // CSRF returns a CSRF token. If CSRF is disabled on the node, returns an empty string and nil error.
 func (c *Client) CSRF() (string, error) {
 	endpoint := c.Addr + "csrf"
@@ -243,18 +276,130 @@ func (c *Client) GetLastBlocks(n uint64) (*visor.ReadableBlocks, error) {
 	return &blocks, nil
 }
 
-// do send request to web. rpcAddress should have forward slash appended
-func do(httpClient *http.Client, rpcReq *Request, rpcAddress, csrf string) (*Response, error) {
+// GetBalance returns the balance of a set of addresses
+func (c *Client) GetBalance(addrs []string) (*BalanceResult, error) {
+	balance := BalanceResult{}
+	if err := c.Do(&balance, "get_balance", addrs); err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// GetTransactions returns the confirmed and unconfirmed transactions of a set of addresses
+func (c *Client) GetTransactions(addrs []string) (*TxnsResult, error) {
+	txns := TxnsResult{}
+	if err := c.Do(&txns, "get_transactions", addrs); err != nil {
+		return nil, err
+	}
+
+	return &txns, nil
+}
+
+// GetPendingTransactions returns the unconfirmed transactions
+func (c *Client) GetPendingTransactions() (*PendingTxnsResult, error) {
+	txns := PendingTxnsResult{}
+	if err := c.Do(&txns, "get_pending_transactions", nil); err != nil {
+		return nil, err
+	}
+
+	return &txns, nil
+}
+
+// GetCoinSupply returns the coin supply
+func (c *Client) GetCoinSupply() (*CoinSupplyResult, error) {
+	supply := CoinSupplyResult{}
+	if err := c.Do(&supply, "get_coin_supply", nil); err != nil {
+		return nil, err
+	}
+
+	return &supply, nil
+}
+
+// GetNetworkInfo returns the connections and the blockchain sync progress
+func (c *Client) GetNetworkInfo() (*NetworkInfoResult, error) {
+	info := NetworkInfoResult{}
+	if err := c.Do(&info, "get_network_info", nil); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetWallets returns the loaded wallets
+func (c *Client) GetWallets() (*WalletsResult, error) {
+	wlts := WalletsResult{}
+	if err := c.Do(&wlts, "get_wallets", nil); err != nil {
+		return nil, err
+	}
+
+	return &wlts, nil
+}
+
+// GetWalletBalance returns the balance of a wallet
+func (c *Client) GetWalletBalance(id string) (*BalanceResult, error) {
+	balance := BalanceResult{}
+	if err := c.Do(&balance, "get_wallet_balance", WalletIDParams{ID: id}); err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// CreateWallet creates a wallet
+func (c *Client) CreateWallet(params CreateWalletParams) (*WalletResult, error) {
+	wlt := WalletResult{}
+	if err := c.Do(&wlt, "create_wallet", params); err != nil {
+		return nil, err
+	}
+
+	return &wlt, nil
+}
+
+// NewAddresses generates addresses in a wallet
+func (c *Client) NewAddresses(id string, num uint64, password string) ([]string, error) {
+	addrs := AddressesResult{}
+	params := NewAddressesParams{
+		ID:       id,
+		Num:      num,
+		Password: password,
+	}
+	if err := c.Do(&addrs, "new_addresses", params); err != nil {
+		return nil, err
+	}
+
+	return addrs.Addresses, nil
+}
+
+// Spend sends coins from a wallet, coins is a decimal string. Returns the txid.
+func (c *Client) Spend(id, dst, coins, password string) (string, error) {
+	rlt := TxIDJson{}
+	params := SpendParams{
+		ID:       id,
+		Dst:      dst,
+		Coins:    coins,
+		Password: password,
+	}
+	if err := c.Do(&rlt, "spend", params); err != nil {
+		return "", err
+	}
+
+	return rlt.Txid, nil
+}
+
+// do send request to web and decodes the response into rsp. rpcAddress should have forward slash appended.
+// rsp is left unchanged if the node sends no content, which happens if every request was a notification.
+func do(httpClient *http.Client, rpcReq, rsp interface{}, rpcAddress, csrf, authToken string) error {
 	d, err := json.Marshal(rpcReq)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	url := rpcAddress + "api/v1/webrpc"
 	body := bytes.NewBuffer(d)
 	req, err := http.NewRequest(http.MethodPost, url, body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -263,30 +408,34 @@ func do(httpClient *http.Client, rpcReq *Request, rpcAddress, csrf string) (*Res
 		req.Header.Set("X-CSRF-Token", csrf)
 	}
 
+	if authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return nil
+	default:
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		return nil, ClientError{
+		return ClientError{
 			Status:     resp.Status,
 			StatusCode: resp.StatusCode,
 			Message:    strings.TrimSpace(string(body)),
 		}
 	}
 
-	res := Response{}
-	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
-		return nil, err
-	}
-	return &res, nil
+	return json.NewDecoder(resp.Body).Decode(rsp)
 }
 
 func decodeJSON(data []byte, obj interface{}) error {
@@ -436,3 +436,43 @@ func TestClientGetLastBlocks(t *testing.T) {
 	require.Len(t, blocks.Blocks, 1)
 	require.Equal(t, decodeBlock(blockString), &blocks)
 }
+
+func TestClientDoBatch(t *testing.T) {
+	s := setupWebRPC(t)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/webrpc", http.HandlerFunc(s.Handler))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	_, err = c.DoBatch(nil)
+	require.EqualError(t, err, "empty batch")
+
+	statusReq, err := NewRequest("get_status", nil, "1")
+	require.NoError(t, err)
+	unknownReq, err := NewRequest("foo", nil, "2")
+	require.NoError(t, err)
+
+	rsps, err := c.DoBatch([]*Request{statusReq, unknownReq})
+	require.NoError(t, err)
+	require.Len(t, rsps, 2)
+
+	require.Equal(t, "1", *rsps[0].ID)
+	require.Nil(t, rsps[0].Error)
+	var status StatusResult
+	require.NoError(t, json.Unmarshal(rsps[0].Result, &status))
+	require.Equal(t, uint64(455), status.BlockNum)
+
+	require.Equal(t, "2", *rsps[1].ID)
+	require.NotNil(t, rsps[1].Error)
+	require.Equal(t, ErrCodeMethodNotFound, rsps[1].Error.Code)
+
+	// the client does not need a batch for a single request
+	result, err := c.GetStatus()
+	require.NoError(t, err)
+	require.Equal(t, status, *result)
+}
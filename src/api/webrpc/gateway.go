@@ -6,6 +6,7 @@ import (
 	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/visor/historydb"
+	"github.com/skycoin/skycoin/src/wallet"
 )
 
 //go:generate goautomock -template=testify Gatewayer
@@ -20,4 +21,16 @@ type Gatewayer interface {
 	InjectBroadcastTransaction(tx coin.Transaction) error
 	GetAddrUxOuts(addr []cipher.Address) ([]*historydb.UxOut, error)
 	GetTimeNow() uint64
+	GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error)
+	GetTransactions(flts ...visor.TxFilter) ([]visor.Transaction, error)
+	GetAllUnconfirmedTxns() ([]visor.UnconfirmedTxn, error)
+	GetCoinSupply() (*visor.CoinSupply, error)
+	GetConnections() *daemon.Connections
+	GetDefaultConnections() []string
+	GetBlockchainProgress() (*daemon.BlockchainProgress, error)
+	GetWallets() (wallet.Wallets, error)
+	GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalance, error)
+	CreateWallet(wltName string, options wallet.Options) (*wallet.Wallet, error)
+	NewAddresses(wltID string, password []byte, n uint64) ([]cipher.Address, error)
+	Spend(wltID string, password []byte, coins uint64, dest cipher.Address) (*coin.Transaction, error)
 }
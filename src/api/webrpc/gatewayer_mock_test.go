@@ -15,6 +15,7 @@ import (
 	daemon "github.com/skycoin/skycoin/src/daemon"
 	visor "github.com/skycoin/skycoin/src/visor"
 	historydb "github.com/skycoin/skycoin/src/visor/historydb"
+	wallet "github.com/skycoin/skycoin/src/wallet"
 )
 
 // GatewayerMock mock
@@ -26,6 +27,33 @@ func NewGatewayerMock() *GatewayerMock {
 	return &GatewayerMock{}
 }
 
+// CreateWallet mocked method
+func (m *GatewayerMock) CreateWallet(p0 string, p1 wallet.Options) (*wallet.Wallet, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 *wallet.Wallet
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *wallet.Wallet:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetAddrUxOuts mocked method
 func (m *GatewayerMock) GetAddrUxOuts(p0 []cipher.Address) ([]*historydb.UxOut, error) {
 
@@ -53,6 +81,87 @@ func (m *GatewayerMock) GetAddrUxOuts(p0 []cipher.Address) ([]*historydb.UxOut,
 
 }
 
+// GetAllUnconfirmedTxns mocked method
+func (m *GatewayerMock) GetAllUnconfirmedTxns() ([]visor.UnconfirmedTxn, error) {
+
+	ret := m.Called()
+
+	var r0 []visor.UnconfirmedTxn
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []visor.UnconfirmedTxn:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetBalanceOfAddrs mocked method
+func (m *GatewayerMock) GetBalanceOfAddrs(p0 []cipher.Address) ([]wallet.BalancePair, error) {
+
+	ret := m.Called(p0)
+
+	var r0 []wallet.BalancePair
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []wallet.BalancePair:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetBlockchainProgress mocked method
+func (m *GatewayerMock) GetBlockchainProgress() (*daemon.BlockchainProgress, error) {
+
+	ret := m.Called()
+
+	var r0 *daemon.BlockchainProgress
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *daemon.BlockchainProgress:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetBlocks mocked method
 func (m *GatewayerMock) GetBlocks(p0 uint64, p1 uint64) (*visor.ReadableBlocks, error) {
 
@@ -107,6 +216,69 @@ func (m *GatewayerMock) GetBlocksInDepth(p0 []uint64) (*visor.ReadableBlocks, er
 
 }
 
+// GetCoinSupply mocked method
+func (m *GatewayerMock) GetCoinSupply() (*visor.CoinSupply, error) {
+
+	ret := m.Called()
+
+	var r0 *visor.CoinSupply
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *visor.CoinSupply:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetConnections mocked method
+func (m *GatewayerMock) GetConnections() *daemon.Connections {
+
+	ret := m.Called()
+
+	var r0 *daemon.Connections
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *daemon.Connections:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0
+
+}
+
+// GetDefaultConnections mocked method
+func (m *GatewayerMock) GetDefaultConnections() []string {
+
+	ret := m.Called()
+
+	var r0 []string
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []string:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0
+
+}
+
 // GetLastBlocks mocked method
 func (m *GatewayerMock) GetLastBlocks(p0 uint64) (*visor.ReadableBlocks, error) {
 
@@ -179,6 +351,33 @@ func (m *GatewayerMock) GetTransaction(p0 cipher.SHA256) (*visor.Transaction, er
 
 }
 
+// GetTransactions mocked method
+func (m *GatewayerMock) GetTransactions(p0 ...visor.TxFilter) ([]visor.Transaction, error) {
+
+	ret := m.Called(p0)
+
+	var r0 []visor.Transaction
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []visor.Transaction:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetUnspentOutputs mocked method
 func (m *GatewayerMock) GetUnspentOutputs(p0 ...daemon.OutputsFilter) (*visor.ReadableOutputSet, error) {
 
@@ -206,6 +405,69 @@ func (m *GatewayerMock) GetUnspentOutputs(p0 ...daemon.OutputsFilter) (*visor.Re
 
 }
 
+// GetWalletBalance mocked method
+func (m *GatewayerMock) GetWalletBalance(p0 string) (wallet.BalancePair, wallet.AddressBalance, error) {
+
+	ret := m.Called(p0)
+
+	var r0 wallet.BalancePair
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case wallet.BalancePair:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 wallet.AddressBalance
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case wallet.AddressBalance:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r2 error
+	switch res := ret.Get(2).(type) {
+	case nil:
+	case error:
+		r2 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1, r2
+
+}
+
+// GetWallets mocked method
+func (m *GatewayerMock) GetWallets() (wallet.Wallets, error) {
+
+	ret := m.Called()
+
+	var r0 wallet.Wallets
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case wallet.Wallets:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // InjectBroadcastTransaction mocked method
 func (m *GatewayerMock) InjectBroadcastTransaction(p0 coin.Transaction) error {
 
@@ -223,3 +485,57 @@ func (m *GatewayerMock) InjectBroadcastTransaction(p0 coin.Transaction) error {
 	return r0
 
 }
+
+// NewAddresses mocked method
+func (m *GatewayerMock) NewAddresses(p0 string, p1 []byte, p2 uint64) ([]cipher.Address, error) {
+
+	ret := m.Called(p0, p1, p2)
+
+	var r0 []cipher.Address
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []cipher.Address:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// Spend mocked method
+func (m *GatewayerMock) Spend(p0 string, p1 []byte, p2 uint64, p3 cipher.Address) (*coin.Transaction, error) {
+
+	ret := m.Called(p0, p1, p2, p3)
+
+	var r0 *coin.Transaction
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *coin.Transaction:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
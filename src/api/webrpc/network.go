@@ -0,0 +1,38 @@
+package webrpc
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/daemon"
+)
+
+// NetworkInfoResult the network info json format
+type NetworkInfoResult struct {
+	Connections        []*daemon.Connection       `json:"connections"`
+	DefaultConnections []string                   `json:"default_connections"`
+	BlockchainProgress *daemon.BlockchainProgress `json:"blockchain_progress"`
+}
+
+func getNetworkInfoHandler(req Request, gateway Gatewayer) Response {
+	if len(req.Params) > 0 {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	progress, err := gateway.GetBlockchainProgress()
+	if err != nil {
+		logger.Errorf("get blockchain progress failed: %v", err)
+		return MakeErrorResponse(ErrCodeInternalError, fmt.Sprintf("gateway.GetBlockchainProgress failed: %v", err))
+	}
+
+	res := NetworkInfoResult{
+		Connections:        []*daemon.Connection{},
+		DefaultConnections: gateway.GetDefaultConnections(),
+		BlockchainProgress: progress,
+	}
+
+	if conns := gateway.GetConnections(); conns != nil {
+		res.Connections = conns.Connections
+	}
+
+	return makeSuccessResponse(req.ID, res)
+}
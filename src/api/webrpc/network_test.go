@@ -0,0 +1,85 @@
+package webrpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/daemon"
+)
+
+func Test_getNetworkInfoHandler(t *testing.T) {
+	conns := &daemon.Connections{
+		Connections: []*daemon.Connection{
+			{
+				ID:       1,
+				Addr:     "1.2.3.4:6000",
+				Outgoing: true,
+			},
+		},
+	}
+	defaultConns := []string{"1.2.3.4:6000", "5.6.7.8:6000"}
+	progress := &daemon.BlockchainProgress{
+		Current: 10,
+		Highest: 20,
+	}
+
+	tests := []struct {
+		name     string
+		params   []byte
+		conns    *daemon.Connections
+		progress *daemon.BlockchainProgress
+		err      error
+		want     Response
+	}{
+		{
+			name:     "normal",
+			conns:    conns,
+			progress: progress,
+			want: makeSuccessResponse("1", NetworkInfoResult{
+				Connections:        conns.Connections,
+				DefaultConnections: defaultConns,
+				BlockchainProgress: progress,
+			}),
+		},
+		{
+			name:     "no connections",
+			progress: progress,
+			want: makeSuccessResponse("1", NetworkInfoResult{
+				Connections:        []*daemon.Connection{},
+				DefaultConnections: defaultConns,
+				BlockchainProgress: progress,
+			}),
+		},
+		{
+			name:   "invalid params",
+			params: []byte(`["foo"]`),
+			want:   MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams),
+		},
+		{
+			name: "gateway error",
+			err:  errors.New("failed"),
+			want: MakeErrorResponse(ErrCodeInternalError, "gateway.GetBlockchainProgress failed: failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGatewayerMock()
+			m.On("GetConnections").Return(tt.conns)
+			m.On("GetDefaultConnections").Return(defaultConns)
+			m.On("GetBlockchainProgress").Return(tt.progress, tt.err)
+
+			req := Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Method:  "get_network_info",
+				Params:  tt.params,
+			}
+
+			got := getNetworkInfoHandler(req, m)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package webrpc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/util/droplet"
+)
+
+// CoinSupplyResult the coin supply json format, coins are decimal strings
+type CoinSupplyResult struct {
+	CurrentSupply         string   `json:"current_supply"`
+	TotalSupply           string   `json:"total_supply"`
+	MaxSupply             string   `json:"max_supply"`
+	CurrentCoinHourSupply string   `json:"current_coinhour_supply"`
+	TotalCoinHourSupply   string   `json:"total_coinhour_supply"`
+	UnlockedAddresses     []string `json:"unlocked_distribution_addresses"`
+	LockedAddresses       []string `json:"locked_distribution_addresses"`
+}
+
+func getCoinSupplyHandler(req Request, gateway Gatewayer) Response {
+	if len(req.Params) > 0 {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	supply, err := gateway.GetCoinSupply()
+	if err != nil {
+		logger.Errorf("get coin supply failed: %v", err)
+		return MakeErrorResponse(ErrCodeInternalError, fmt.Sprintf("gateway.GetCoinSupply failed: %v", err))
+	}
+
+	res := CoinSupplyResult{
+		CurrentCoinHourSupply: strconv.FormatUint(supply.CurrentCoinHourSupply, 10),
+		TotalCoinHourSupply:   strconv.FormatUint(supply.TotalCoinHourSupply, 10),
+		UnlockedAddresses:     supply.UnlockedAddresses,
+		LockedAddresses:       supply.LockedAddresses,
+	}
+
+	for _, c := range []struct {
+		droplets uint64
+		s        *string
+	}{
+		{supply.CurrentSupply, &res.CurrentSupply},
+		{supply.TotalSupply, &res.TotalSupply},
+		{supply.MaxSupply, &res.MaxSupply},
+	} {
+		*c.s, err = droplet.ToString(c.droplets)
+		if err != nil {
+			logger.Error(err)
+			return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+		}
+	}
+
+	return makeSuccessResponse(req.ID, res)
+}
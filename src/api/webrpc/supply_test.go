@@ -0,0 +1,71 @@
+package webrpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func Test_getCoinSupplyHandler(t *testing.T) {
+	supply := &visor.CoinSupply{
+		CurrentSupply:         25000000000000,
+		TotalSupply:           30000000000000,
+		MaxSupply:             100000000000000,
+		CurrentCoinHourSupply: 1000,
+		TotalCoinHourSupply:   2000,
+		UnlockedAddresses:     []string{"R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ"},
+		LockedAddresses:       []string{"2EYM4WFHe4Dgz6kjAdUkM6Etep7ruz2ia6h"},
+	}
+
+	tests := []struct {
+		name   string
+		params []byte
+		supply *visor.CoinSupply
+		err    error
+		want   Response
+	}{
+		{
+			name:   "normal",
+			supply: supply,
+			want: makeSuccessResponse("1", CoinSupplyResult{
+				CurrentSupply:         "25000000.000000",
+				TotalSupply:           "30000000.000000",
+				MaxSupply:             "100000000.000000",
+				CurrentCoinHourSupply: "1000",
+				TotalCoinHourSupply:   "2000",
+				UnlockedAddresses:     []string{"R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ"},
+				LockedAddresses:       []string{"2EYM4WFHe4Dgz6kjAdUkM6Etep7ruz2ia6h"},
+			}),
+		},
+		{
+			name:   "invalid params",
+			params: []byte(`["foo"]`),
+			want:   MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams),
+		},
+		{
+			name: "gateway error",
+			err:  errors.New("failed"),
+			want: MakeErrorResponse(ErrCodeInternalError, "gateway.GetCoinSupply failed: failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGatewayerMock()
+			m.On("GetCoinSupply").Return(tt.supply, tt.err)
+
+			req := Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Method:  "get_coin_supply",
+				Params:  tt.params,
+			}
+
+			got := getCoinSupplyHandler(req, m)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
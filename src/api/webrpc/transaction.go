@@ -7,6 +7,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/visor"
 )
 
 // TxnResult wraps the daemon.TransactionResult
@@ -14,6 +15,16 @@ type TxnResult struct {
 	Transaction *daemon.TransactionResult `json:"transaction"`
 }
 
+// TxnsResult wraps the daemon.TransactionResults
+type TxnsResult struct {
+	Transactions []daemon.TransactionResult `json:"transactions"`
+}
+
+// PendingTxnsResult wraps the unconfirmed transactions
+type PendingTxnsResult struct {
+	Transactions []visor.ReadableUnconfirmedTxn `json:"transactions"`
+}
+
 // TxIDJson wraps txid with json tags
 type TxIDJson struct {
 	Txid string `json:"txid"`
@@ -81,3 +92,58 @@ func injectTransactionHandler(req Request, gateway Gatewayer) Response {
 
 	return makeSuccessResponse(req.ID, TxIDJson{txn.Hash().Hex()})
 }
+
+// request params: [addr1, addr2, ...]
+func getTransactionsHandler(req Request, gateway Gatewayer) Response {
+	var addrs []string
+	if err := req.DecodeParams(&addrs); err != nil {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	if len(addrs) == 0 {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	as := make([]cipher.Address, len(addrs))
+	for i, a := range addrs {
+		addr, err := cipher.DecodeBase58Address(a)
+		if err != nil {
+			return MakeErrorResponse(ErrCodeInvalidParams, fmt.Sprintf("invalid address: %v", a))
+		}
+		as[i] = addr
+	}
+
+	txns, err := gateway.GetTransactions(visor.AddrsFilter(as))
+	if err != nil {
+		logger.Errorf("get transactions failed: %v", err)
+		return MakeErrorResponse(ErrCodeInternalError, fmt.Sprintf("gateway.GetTransactions failed: %v", err))
+	}
+
+	txRlts, err := daemon.NewTransactionResults(txns)
+	if err != nil {
+		logger.Error(err)
+		return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+	}
+
+	return makeSuccessResponse(req.ID, TxnsResult{txRlts.Txns})
+}
+
+func getPendingTransactionsHandler(req Request, gateway Gatewayer) Response {
+	if len(req.Params) > 0 {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	txns, err := gateway.GetAllUnconfirmedTxns()
+	if err != nil {
+		logger.Errorf("get unconfirmed transactions failed: %v", err)
+		return MakeErrorResponse(ErrCodeInternalError, fmt.Sprintf("gateway.GetAllUnconfirmedTxns failed: %v", err))
+	}
+
+	rTxns, err := visor.NewReadableUnconfirmedTxns(txns)
+	if err != nil {
+		logger.Error(err)
+		return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+	}
+
+	return makeSuccessResponse(req.ID, PendingTxnsResult{rTxns})
+}
@@ -2,13 +2,16 @@ package webrpc
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/visor"
 )
 
@@ -223,3 +226,94 @@ func Test_injectTransactionHandler(t *testing.T) {
 		})
 	}
 }
+
+func Test_getTransactionsHandler(t *testing.T) {
+	addr := testutil.MakeAddress()
+
+	tests := []struct {
+		name   string
+		params string
+		err    error
+		want   Response
+	}{
+		{
+			name:   "normal",
+			params: `["` + addr.String() + `"]`,
+			want:   makeSuccessResponse("1", TxnsResult{[]daemon.TransactionResult{}}),
+		},
+		{
+			name:   "no addresses",
+			params: `[]`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams),
+		},
+		{
+			name:   "invalid address",
+			params: `["foo"]`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, "invalid address: foo"),
+		},
+		{
+			name:   "gateway error",
+			params: `["` + addr.String() + `"]`,
+			err:    errors.New("failed"),
+			want:   MakeErrorResponse(ErrCodeInternalError, "gateway.GetTransactions failed: failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGatewayerMock()
+			m.On("GetTransactions", mock.Anything).Return([]visor.Transaction{}, tt.err)
+
+			req := Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Method:  "get_transactions",
+				Params:  []byte(tt.params),
+			}
+
+			got := getTransactionsHandler(req, m)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_getPendingTransactionsHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []byte
+		err    error
+		want   Response
+	}{
+		{
+			name: "normal",
+			want: makeSuccessResponse("1", PendingTxnsResult{[]visor.ReadableUnconfirmedTxn{}}),
+		},
+		{
+			name:   "invalid params",
+			params: []byte(`["foo"]`),
+			want:   MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams),
+		},
+		{
+			name: "gateway error",
+			err:  errors.New("failed"),
+			want: MakeErrorResponse(ErrCodeInternalError, "gateway.GetAllUnconfirmedTxns failed: failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGatewayerMock()
+			m.On("GetAllUnconfirmedTxns").Return([]visor.UnconfirmedTxn{}, tt.err)
+
+			req := Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Method:  "get_pending_transactions",
+				Params:  tt.params,
+			}
+
+			got := getPendingTransactionsHandler(req, m)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
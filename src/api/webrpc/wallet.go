@@ -0,0 +1,219 @@
+package webrpc
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// WalletResult the wallet json format of get_wallets and create_wallet, secrets are never included
+type WalletResult struct {
+	ID        string   `json:"id"`
+	Label     string   `json:"label"`
+	Encrypted bool     `json:"encrypted"`
+	Addresses []string `json:"addresses"`
+}
+
+// WalletsResult wraps the loaded wallets
+type WalletsResult struct {
+	Wallets []WalletResult `json:"wallets"`
+}
+
+// AddressesResult wraps the addresses generated by new_addresses
+type AddressesResult struct {
+	Addresses []string `json:"addresses"`
+}
+
+// WalletIDParams params of get_wallet_balance
+type WalletIDParams struct {
+	ID string `json:"id"`
+}
+
+// CreateWalletParams params of create_wallet
+type CreateWalletParams struct {
+	Seed     string `json:"seed"`
+	Label    string `json:"label"`
+	Encrypt  bool   `json:"encrypt"`
+	Password string `json:"password"`
+	ScanN    uint64 `json:"scan"`
+}
+
+// NewAddressesParams params of new_addresses
+type NewAddressesParams struct {
+	ID       string `json:"id"`
+	Num      uint64 `json:"num"`
+	Password string `json:"password"`
+}
+
+// SpendParams params of spend, coins is a decimal string
+type SpendParams struct {
+	ID       string `json:"id"`
+	Dst      string `json:"dst"`
+	Coins    string `json:"coins"`
+	Password string `json:"password"`
+}
+
+func newWalletResult(w *wallet.Wallet) WalletResult {
+	return WalletResult{
+		ID:        w.Filename(),
+		Label:     w.Label(),
+		Encrypted: w.IsEncrypted(),
+		Addresses: addressStrings(w.GetAddresses()),
+	}
+}
+
+func addressStrings(addrs []cipher.Address) []string {
+	ss := make([]string, len(addrs))
+	for i, a := range addrs {
+		ss[i] = a.String()
+	}
+	return ss
+}
+
+// makeWalletErrorResponse converts a wallet error to an error response
+func makeWalletErrorResponse(err error) Response {
+	switch err.(type) {
+	case wallet.Error:
+		if err == wallet.ErrWalletAPIDisabled {
+			return MakeErrorResponse(ErrCodeWalletAPIDisabled, err.Error())
+		}
+		return MakeErrorResponse(ErrCodeInvalidParams, err.Error())
+	default:
+		logger.Error(err)
+		return MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError)
+	}
+}
+
+func getWalletsHandler(req Request, gateway Gatewayer) Response {
+	if len(req.Params) > 0 {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	wlts, err := gateway.GetWallets()
+	if err != nil {
+		return makeWalletErrorResponse(err)
+	}
+
+	res := WalletsResult{
+		Wallets: make([]WalletResult, 0, len(wlts)),
+	}
+	for _, w := range wlts {
+		res.Wallets = append(res.Wallets, newWalletResult(w))
+	}
+
+	sort.Slice(res.Wallets, func(i, j int) bool {
+		return res.Wallets[i].ID < res.Wallets[j].ID
+	})
+
+	return makeSuccessResponse(req.ID, res)
+}
+
+// request params: {"id": "wallet id"}
+func getWalletBalanceHandler(req Request, gateway Gatewayer) Response {
+	var params WalletIDParams
+	if err := req.DecodeParams(&params); err != nil {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	if params.ID == "" {
+		return MakeErrorResponse(ErrCodeInvalidParams, "missing wallet id")
+	}
+
+	walletBalance, addressBalances, err := gateway.GetWalletBalance(params.ID)
+	if err != nil {
+		return makeWalletErrorResponse(err)
+	}
+
+	return makeSuccessResponse(req.ID, BalanceResult{
+		BalancePair: walletBalance,
+		Addresses:   addressBalances,
+	})
+}
+
+// request params: {"seed": "...", "label": "...", "encrypt": false, "password": "...", "scan": 1}
+func createWalletHandler(req Request, gateway Gatewayer) Response {
+	var params CreateWalletParams
+	if err := req.DecodeParams(&params); err != nil {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	if params.Seed == "" {
+		return MakeErrorResponse(ErrCodeInvalidParams, "missing seed")
+	}
+
+	if params.ScanN == 0 {
+		params.ScanN = 1
+	}
+
+	w, err := gateway.CreateWallet("", wallet.Options{
+		Seed:     params.Seed,
+		Label:    params.Label,
+		Encrypt:  params.Encrypt,
+		Password: []byte(params.Password),
+		ScanN:    params.ScanN,
+	})
+	if err != nil {
+		return makeWalletErrorResponse(err)
+	}
+
+	return makeSuccessResponse(req.ID, newWalletResult(w))
+}
+
+// request params: {"id": "wallet id", "num": 1, "password": "..."}
+func newAddressesHandler(req Request, gateway Gatewayer) Response {
+	var params NewAddressesParams
+	if err := req.DecodeParams(&params); err != nil {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	if params.ID == "" {
+		return MakeErrorResponse(ErrCodeInvalidParams, "missing wallet id")
+	}
+
+	if params.Num == 0 {
+		params.Num = 1
+	}
+
+	addrs, err := gateway.NewAddresses(params.ID, []byte(params.Password), params.Num)
+	if err != nil {
+		return makeWalletErrorResponse(err)
+	}
+
+	return makeSuccessResponse(req.ID, AddressesResult{addressStrings(addrs)})
+}
+
+// request params: {"id": "wallet id", "dst": "address", "coins": "1.5", "password": "..."}
+func spendHandler(req Request, gateway Gatewayer) Response {
+	var params SpendParams
+	if err := req.DecodeParams(&params); err != nil {
+		return MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams)
+	}
+
+	if params.ID == "" {
+		return MakeErrorResponse(ErrCodeInvalidParams, "missing wallet id")
+	}
+
+	dst, err := cipher.DecodeBase58Address(params.Dst)
+	if err != nil {
+		return MakeErrorResponse(ErrCodeInvalidParams, fmt.Sprintf("invalid destination address: %v", params.Dst))
+	}
+
+	coins, err := droplet.FromString(params.Coins)
+	if err != nil {
+		return MakeErrorResponse(ErrCodeInvalidParams, fmt.Sprintf("invalid coins value: %v", err))
+	}
+
+	if coins == 0 {
+		return MakeErrorResponse(ErrCodeInvalidParams, "coins must be greater than 0")
+	}
+
+	txn, err := gateway.Spend(params.ID, []byte(params.Password), coins, dst)
+	if err != nil {
+		return makeWalletErrorResponse(err)
+	}
+
+	return makeSuccessResponse(req.ID, TxIDJson{txn.Hash().Hex()})
+}
@@ -0,0 +1,325 @@
+package webrpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func newTestWallet(t *testing.T, id, label string) *wallet.Wallet {
+	w, err := wallet.NewWallet(id, wallet.Options{
+		Seed:  id,
+		Label: label,
+	})
+	require.NoError(t, err)
+	return w
+}
+
+func Test_getWalletsHandler(t *testing.T) {
+	w1 := newTestWallet(t, "b.wlt", "foo")
+	w2 := newTestWallet(t, "a.wlt", "bar")
+
+	tests := []struct {
+		name   string
+		params []byte
+		wlts   wallet.Wallets
+		err    error
+		want   Response
+	}{
+		{
+			name: "normal",
+			wlts: wallet.Wallets{"b.wlt": w1, "a.wlt": w2},
+			want: makeSuccessResponse("1", WalletsResult{
+				Wallets: []WalletResult{
+					{
+						ID:        "a.wlt",
+						Label:     "bar",
+						Addresses: []string{w2.GetAddresses()[0].String()},
+					},
+					{
+						ID:        "b.wlt",
+						Label:     "foo",
+						Addresses: []string{w1.GetAddresses()[0].String()},
+					},
+				},
+			}),
+		},
+		{
+			name:   "invalid params",
+			params: []byte(`["foo"]`),
+			want:   MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidParams),
+		},
+		{
+			name: "wallet api disabled",
+			err:  wallet.ErrWalletAPIDisabled,
+			want: MakeErrorResponse(ErrCodeWalletAPIDisabled, wallet.ErrWalletAPIDisabled.Error()),
+		},
+		{
+			name: "gateway error",
+			err:  errors.New("failed"),
+			want: MakeErrorResponse(ErrCodeInternalError, ErrMsgInternalError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGatewayerMock()
+			m.On("GetWallets").Return(tt.wlts, tt.err)
+
+			req := Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Method:  "get_wallets",
+				Params:  tt.params,
+			}
+
+			got := getWalletsHandler(req, m)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_getWalletBalanceHandler(t *testing.T) {
+	addr := testutil.MakeAddress()
+	bal := wallet.BalancePair{
+		Confirmed: wallet.Balance{Coins: 1000000, Hours: 10},
+		Predicted: wallet.Balance{Coins: 1000000, Hours: 10},
+	}
+
+	tests := []struct {
+		name   string
+		params string
+		err    error
+		want   Response
+	}{
+		{
+			name:   "normal",
+			params: `{"id":"foo.wlt"}`,
+			want: makeSuccessResponse("1", BalanceResult{
+				BalancePair: bal,
+				Addresses: wallet.AddressBalance{
+					addr.String(): bal,
+				},
+			}),
+		},
+		{
+			name:   "missing wallet id",
+			params: `{}`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, "missing wallet id"),
+		},
+		{
+			name:   "wallet does not exist",
+			params: `{"id":"foo.wlt"}`,
+			err:    wallet.ErrWalletNotExist,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, wallet.ErrWalletNotExist.Error()),
+		},
+		{
+			name:   "wallet api disabled",
+			params: `{"id":"foo.wlt"}`,
+			err:    wallet.ErrWalletAPIDisabled,
+			want:   MakeErrorResponse(ErrCodeWalletAPIDisabled, wallet.ErrWalletAPIDisabled.Error()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGatewayerMock()
+			m.On("GetWalletBalance", "foo.wlt").Return(bal, wallet.AddressBalance{addr.String(): bal}, tt.err)
+
+			req := Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Method:  "get_wallet_balance",
+				Params:  []byte(tt.params),
+			}
+
+			got := getWalletBalanceHandler(req, m)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_createWalletHandler(t *testing.T) {
+	w := newTestWallet(t, "foo.wlt", "foo")
+
+	tests := []struct {
+		name    string
+		params  string
+		options wallet.Options
+		err     error
+		want    Response
+	}{
+		{
+			name:   "normal",
+			params: `{"seed":"foo.wlt","label":"foo"}`,
+			options: wallet.Options{
+				Seed:     "foo.wlt",
+				Label:    "foo",
+				Password: []byte{},
+				ScanN:    1,
+			},
+			want: makeSuccessResponse("1", WalletResult{
+				ID:        "foo.wlt",
+				Label:     "foo",
+				Addresses: []string{w.GetAddresses()[0].String()},
+			}),
+		},
+		{
+			name:   "missing seed",
+			params: `{"label":"foo"}`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, "missing seed"),
+		},
+		{
+			name:   "missing password",
+			params: `{"seed":"foo.wlt","encrypt":true,"scan":5}`,
+			options: wallet.Options{
+				Seed:     "foo.wlt",
+				Encrypt:  true,
+				Password: []byte{},
+				ScanN:    5,
+			},
+			err:  wallet.ErrMissingPassword,
+			want: MakeErrorResponse(ErrCodeInvalidParams, wallet.ErrMissingPassword.Error()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGatewayerMock()
+			m.On("CreateWallet", "", tt.options).Return(w, tt.err)
+
+			req := Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Method:  "create_wallet",
+				Params:  []byte(tt.params),
+			}
+
+			got := createWalletHandler(req, m)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_newAddressesHandler(t *testing.T) {
+	addr := testutil.MakeAddress()
+
+	tests := []struct {
+		name   string
+		params string
+		num    uint64
+		err    error
+		want   Response
+	}{
+		{
+			name:   "normal",
+			params: `{"id":"foo.wlt","password":"pwd"}`,
+			num:    1,
+			want:   makeSuccessResponse("1", AddressesResult{[]string{addr.String()}}),
+		},
+		{
+			name:   "missing wallet id",
+			params: `{"num":2}`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, "missing wallet id"),
+		},
+		{
+			name:   "invalid password",
+			params: `{"id":"foo.wlt","num":2,"password":"pwd"}`,
+			num:    2,
+			err:    wallet.ErrInvalidPassword,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, wallet.ErrInvalidPassword.Error()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGatewayerMock()
+			m.On("NewAddresses", "foo.wlt", []byte("pwd"), tt.num).Return([]cipher.Address{addr}, tt.err)
+
+			req := Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Method:  "new_addresses",
+				Params:  []byte(tt.params),
+			}
+
+			got := newAddressesHandler(req, m)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_spendHandler(t *testing.T) {
+	dst := testutil.MakeAddress()
+	txn := &coin.Transaction{}
+
+	tests := []struct {
+		name   string
+		params string
+		err    error
+		want   Response
+	}{
+		{
+			name:   "normal",
+			params: `{"id":"foo.wlt","dst":"` + dst.String() + `","coins":"1.5","password":"pwd"}`,
+			want:   makeSuccessResponse("1", TxIDJson{txn.Hash().Hex()}),
+		},
+		{
+			name:   "missing wallet id",
+			params: `{"dst":"` + dst.String() + `","coins":"1.5"}`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, "missing wallet id"),
+		},
+		{
+			name:   "invalid destination address",
+			params: `{"id":"foo.wlt","dst":"foo","coins":"1.5"}`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, "invalid destination address: foo"),
+		},
+		{
+			name:   "invalid coins",
+			params: `{"id":"foo.wlt","dst":"` + dst.String() + `","coins":"1.0000001"}`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, "invalid coins value: Droplet string conversion failed: Too many decimal places"),
+		},
+		{
+			name:   "zero coins",
+			params: `{"id":"foo.wlt","dst":"` + dst.String() + `","coins":"0"}`,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, "coins must be greater than 0"),
+		},
+		{
+			name:   "insufficient balance",
+			params: `{"id":"foo.wlt","dst":"` + dst.String() + `","coins":"1.5","password":"pwd"}`,
+			err:    wallet.ErrInsufficientBalance,
+			want:   MakeErrorResponse(ErrCodeInvalidParams, wallet.ErrInsufficientBalance.Error()),
+		},
+		{
+			name:   "wallet api disabled",
+			params: `{"id":"foo.wlt","dst":"` + dst.String() + `","coins":"1.5","password":"pwd"}`,
+			err:    wallet.ErrWalletAPIDisabled,
+			want:   MakeErrorResponse(ErrCodeWalletAPIDisabled, wallet.ErrWalletAPIDisabled.Error()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGatewayerMock()
+			m.On("Spend", "foo.wlt", []byte("pwd"), uint64(1500000), dst).Return(txn, tt.err)
+			m.On("Spend", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("unexpected spend"))
+
+			req := Request{
+				ID:      "1",
+				Jsonrpc: jsonRPC,
+				Method:  "spend",
+				Params:  []byte(tt.params),
+			}
+
+			got := spendHandler(req, m)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
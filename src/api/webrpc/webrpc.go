@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"sort"
 	"strings"
 
 	wh "github.com/skycoin/skycoin/src/util/http"
@@ -22,6 +24,8 @@ var (
 	ErrCodeInvalidParams = -32602 // Invalid params	Invalid method parameter(s).
 	// ErrCodeInternalError internal error
 	ErrCodeInternalError = -32603 // Internal error	Internal JSON-RPC error.
+	// ErrCodeWalletAPIDisabled wallet methods are disabled
+	ErrCodeWalletAPIDisabled = -32001
 
 	// ErrMsgParseError parse error message
 	ErrMsgParseError = "Parse error"
@@ -35,6 +39,13 @@ var (
 	ErrMsgNotPost = "only support http POST"
 	// ErrMsgInvalidJsonrpc invalid jsonrpc message
 	ErrMsgInvalidJsonrpc = "invalid jsonrpc"
+	// ErrMsgInvalidRequest invalid request object message
+	ErrMsgInvalidRequest = "Invalid Request"
+	// ErrMsgBatchTooLarge batch request too large message
+	ErrMsgBatchTooLarge = "too many requests in batch"
+
+	// MaxBatchSize is the maximum number of requests in a batch
+	MaxBatchSize = 100
 
 	// -32000 to -32099	Server error	Reserved for implementation-defined server-errors.
 
@@ -118,16 +129,24 @@ type operation func(rpc *WebRPC)
 // HandlerFunc represents the function type for processing the request
 type HandlerFunc func(req Request, gateway Gatewayer) Response
 
+// Config configures WebRPC
+type Config struct {
+	// Maximum number of blocks that get_blocks, get_blocks_by_seq and get_lastblocks can return. 0 is unlimited
+	MaxBlockRange uint64
+}
+
 // WebRPC manage the web rpc state and handles
 type WebRPC struct {
 	Gateway  Gatewayer
+	cfg      Config
 	handlers map[string]HandlerFunc
 }
 
 // New returns a new WebRPC object
-func New(gw Gatewayer) (*WebRPC, error) {
+func New(c Config, gw Gatewayer) (*WebRPC, error) {
 	rpc := &WebRPC{
 		Gateway:  gw,
+		cfg:      c,
 		handlers: make(map[string]HandlerFunc),
 	}
 
@@ -144,11 +163,11 @@ func (rpc *WebRPC) initHandlers() error {
 		// get service status
 		"get_status": getStatusHandler,
 		// get blocks by seq
-		"get_blocks_by_seq": getBlocksBySeqHandler,
+		"get_blocks_by_seq": getBlocksBySeqHandler(rpc.cfg.MaxBlockRange),
 		// get last N blocks
-		"get_lastblocks": getLastBlocksHandler,
+		"get_lastblocks": getLastBlocksHandler(rpc.cfg.MaxBlockRange),
 		// get blocks in specific seq range
-		"get_blocks": getBlocksHandler,
+		"get_blocks": getBlocksHandler(rpc.cfg.MaxBlockRange),
 		// get unspent outputs of address
 		"get_outputs": getOutputsHandler,
 		// get transaction by txid
@@ -157,6 +176,26 @@ func (rpc *WebRPC) initHandlers() error {
 		"inject_transaction": injectTransactionHandler,
 		// get address affected uxouts
 		"get_address_uxouts": getAddrUxOutsHandler,
+		// get balance of addresses
+		"get_balance": getBalanceHandler,
+		// get transactions of addresses
+		"get_transactions": getTransactionsHandler,
+		// get unconfirmed transactions
+		"get_pending_transactions": getPendingTransactionsHandler,
+		// get coin supply
+		"get_coin_supply": getCoinSupplyHandler,
+		// get connections and sync progress
+		"get_network_info": getNetworkInfoHandler,
+		// list loaded wallets
+		"get_wallets": getWalletsHandler,
+		// get balance of a wallet
+		"get_wallet_balance": getWalletBalanceHandler,
+		// create a wallet
+		"create_wallet": createWalletHandler,
+		// generate addresses in a wallet
+		"new_addresses": newAddressesHandler,
+		// send coins from a wallet
+		"spend": spendHandler,
 	}
 
 	// register handlers
@@ -169,6 +208,16 @@ func (rpc *WebRPC) initHandlers() error {
 	return nil
 }
 
+// Methods returns the names of the registered methods, sorted
+func (rpc *WebRPC) Methods() []string {
+	methods := make([]string, 0, len(rpc.handlers))
+	for m := range rpc.handlers {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
 // HandleFunc registers handler function
 func (rpc *WebRPC) HandleFunc(method string, h HandlerFunc) error {
 	if _, ok := rpc.handlers[method]; ok {
@@ -179,8 +228,25 @@ func (rpc *WebRPC) HandleFunc(method string, h HandlerFunc) error {
 	return nil
 }
 
-// Handler processes the http request
+// CheckFunc is called with the methods of a request, or of all the requests of a batch, before any of them
+// is handled. If it returns false, none of the requests are handled, and CheckFunc must write the HTTP response.
+type CheckFunc func(w http.ResponseWriter, r *http.Request, methods []string) bool
+
+// Handler processes the http request. The body is a single request, or a batch of requests in a JSON array.
+// Notifications, requests without an id, are handled but do not get a response.
 func (rpc *WebRPC) Handler(w http.ResponseWriter, r *http.Request) {
+	rpc.handle(w, r, nil)
+}
+
+// CheckedHandler returns a handler like Handler, which calls check with the methods of the requests
+// before handling them
+func (rpc *WebRPC) CheckedHandler(check CheckFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rpc.handle(w, r, check)
+	}
+}
+
+func (rpc *WebRPC) handle(w http.ResponseWriter, r *http.Request, check CheckFunc) {
 	if r.Method != http.MethodPost {
 		res := MakeErrorResponse(ErrCodeInvalidRequest, ErrMsgNotPost)
 		logger.Error("Only POST is allowed")
@@ -188,25 +254,139 @@ func (rpc *WebRPC) Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req := Request{}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
 		res := MakeErrorResponse(ErrCodeParseError, ErrMsgParseError)
-		logger.WithError(err).Error("Invalid request body")
+		logger.WithError(err).Error("Read request body failed")
 		wh.SendJSONOr500(logger, w, &res)
 		return
 	}
 
-	if req.Jsonrpc != jsonRPC {
-		res := MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidJsonrpc)
-		logger.Error("Invalid JSON-RPC version")
+	body = bytes.TrimSpace(body)
+	if !bytes.HasPrefix(body, []byte("[")) {
+		req := decodeRequest(body)
+		if check != nil && !check(w, r, requestMethods(req)) {
+			return
+		}
+
+		res, ok := rpc.handleRequest(req)
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, &res)
+		return
+	}
+
+	var bodies []json.RawMessage
+	if err := json.Unmarshal(body, &bodies); err != nil {
+		res := MakeErrorResponse(ErrCodeParseError, ErrMsgParseError)
+		logger.WithError(err).Error("Invalid batch request body")
+		wh.SendJSONOr500(logger, w, &res)
+		return
+	}
+
+	switch {
+	case len(bodies) == 0:
+		res := MakeErrorResponse(ErrCodeInvalidRequest, ErrMsgInvalidRequest)
+		wh.SendJSONOr500(logger, w, &res)
+		return
+	case len(bodies) > MaxBatchSize:
+		res := MakeErrorResponse(ErrCodeInvalidRequest, ErrMsgBatchTooLarge)
 		wh.SendJSONOr500(logger, w, &res)
 		return
 	}
 
+	reqs := make([]decodedRequest, len(bodies))
+	for i, b := range bodies {
+		reqs[i] = decodeRequest(b)
+	}
+
+	if check != nil && !check(w, r, requestMethods(reqs...)) {
+		return
+	}
+
+	responses := make([]Response, 0, len(reqs))
+	for _, req := range reqs {
+		if res, ok := rpc.handleRequest(req); ok {
+			responses = append(responses, res)
+		}
+	}
+
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	wh.SendJSONOr500(logger, w, responses)
+}
+
+// decodedRequest is a decoded request, or the error response of a request that could not be decoded
+type decodedRequest struct {
+	Request
+	// notification is true if the request has no id
+	notification bool
+	errRes       *Response
+}
+
+// decodeRequest decodes the body of a single request
+func decodeRequest(body []byte) decodedRequest {
+	var req struct {
+		Request
+		// ID is nil for notifications
+		ID *string `json:"id"`
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		res := MakeErrorResponse(ErrCodeInvalidRequest, ErrMsgInvalidRequest)
+		if !json.Valid(body) {
+			logger.WithError(err).Error("Invalid request body")
+			res = MakeErrorResponse(ErrCodeParseError, ErrMsgParseError)
+		} else {
+			logger.WithError(err).Error("Invalid request object")
+		}
+		return decodedRequest{
+			errRes: &res,
+		}
+	}
+
+	if req.ID != nil {
+		req.Request.ID = *req.ID
+	}
+
+	return decodedRequest{
+		Request:      req.Request,
+		notification: req.ID == nil,
+	}
+}
+
+// requestMethods returns the methods of the requests that were decoded
+func requestMethods(reqs ...decodedRequest) []string {
+	methods := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		if req.errRes == nil {
+			methods = append(methods, req.Method)
+		}
+	}
+	return methods
+}
+
+// handleRequest handles a single request. Returns false if the request is a notification,
+// which must not be responded to
+func (rpc *WebRPC) handleRequest(req decodedRequest) (Response, bool) {
+	// A request that could not be decoded is responded to, since it is not known to be a notification
+	if req.errRes != nil {
+		return *req.errRes, true
+	}
+
 	var res Response
-	if handler, ok := rpc.handlers[req.Method]; ok {
+	if req.Jsonrpc != jsonRPC {
+		logger.Error("Invalid JSON-RPC version")
+		res = MakeErrorResponse(ErrCodeInvalidParams, ErrMsgInvalidJsonrpc)
+	} else if handler, ok := rpc.handlers[req.Method]; ok {
 		logger.Infof("Handling method: %s", req.Method)
-		res = handler(req, rpc.Gateway)
+		res = handler(req.Request, rpc.Gateway)
 	} else {
 		res = MakeErrorResponse(ErrCodeMethodNotFound, ErrMsgMethodNotFound)
 	}
@@ -215,5 +395,13 @@ func (rpc *WebRPC) Handler(w http.ResponseWriter, r *http.Request) {
 		logger.Errorf("%d %s", res.Error.Code, res.Error.Message)
 	}
 
-	wh.SendJSONOr500(logger, w, &res)
+	if req.notification {
+		return Response{}, false
+	}
+
+	// Error responses are created without the request's id
+	id := req.ID
+	res.ID = &id
+
+	return res, true
 }
@@ -4,6 +4,9 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"time"
@@ -15,10 +18,11 @@ import (
 	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/visor/historydb"
+	"github.com/skycoin/skycoin/src/wallet"
 )
 
 func setupWebRPC(t *testing.T) *WebRPC {
-	rpc, err := New(&fakeGateway{})
+	rpc, err := New(Config{}, &fakeGateway{})
 	require.NoError(t, err)
 	return rpc
 }
@@ -104,9 +108,243 @@ func (fg fakeGateway) GetTimeNow() uint64 {
 	return 0
 }
 
+func (fg fakeGateway) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error) {
+	return nil, nil
+}
+
+func (fg fakeGateway) GetTransactions(flts ...visor.TxFilter) ([]visor.Transaction, error) {
+	return nil, nil
+}
+
+func (fg fakeGateway) GetAllUnconfirmedTxns() ([]visor.UnconfirmedTxn, error) {
+	return nil, nil
+}
+
+func (fg fakeGateway) GetCoinSupply() (*visor.CoinSupply, error) {
+	return nil, nil
+}
+
+func (fg fakeGateway) GetConnections() *daemon.Connections {
+	return nil
+}
+
+func (fg fakeGateway) GetDefaultConnections() []string {
+	return nil
+}
+
+func (fg fakeGateway) GetBlockchainProgress() (*daemon.BlockchainProgress, error) {
+	return nil, nil
+}
+
+func (fg fakeGateway) GetWallets() (wallet.Wallets, error) {
+	return nil, nil
+}
+
+func (fg fakeGateway) GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalance, error) {
+	return wallet.BalancePair{}, nil, nil
+}
+
+func (fg fakeGateway) CreateWallet(wltName string, options wallet.Options) (*wallet.Wallet, error) {
+	return nil, nil
+}
+
+func (fg fakeGateway) NewAddresses(wltID string, password []byte, n uint64) ([]cipher.Address, error) {
+	return nil, nil
+}
+
+func (fg fakeGateway) Spend(wltID string, password []byte, coins uint64, dest cipher.Address) (*coin.Transaction, error) {
+	return nil, nil
+}
+
 func Test_rpcHandler_HandlerFunc(t *testing.T) {
 	rpc := setupWebRPC(t)
 	rpc.HandleFunc("get_status", getStatusHandler)
 	err := rpc.HandleFunc("get_status", getStatusHandler)
 	require.Error(t, err)
 }
+
+func TestHandlerBatch(t *testing.T) {
+	statusReq := `{"jsonrpc":"2.0","method":"get_status","id":"1"}`
+	notification := `{"jsonrpc":"2.0","method":"get_status"}`
+
+	tooLarge := make([]string, MaxBatchSize+1)
+	for i := range tooLarge {
+		tooLarge[i] = statusReq
+	}
+
+	cases := []struct {
+		name   string
+		body   string
+		status int
+		// expected response ids and error codes, in order. An error code of 0 is a success.
+		ids   []string
+		codes []int
+		batch bool
+	}{
+		{
+			name:   "single request",
+			body:   statusReq,
+			status: http.StatusOK,
+			ids:    []string{"1"},
+			codes:  []int{0},
+		},
+		{
+			name:   "single notification",
+			body:   notification,
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "single request, not an object",
+			body:   `"foo"`,
+			status: http.StatusOK,
+			ids:    []string{""},
+			codes:  []int{ErrCodeInvalidRequest},
+		},
+		{
+			name:   "single request, error response has id",
+			body:   `{"jsonrpc":"2.0","method":"foo","id":"2"}`,
+			status: http.StatusOK,
+			ids:    []string{"2"},
+			codes:  []int{ErrCodeMethodNotFound},
+		},
+		{
+			name:   "batch",
+			body:   `[` + statusReq + `,` + notification + `,{"jsonrpc":"2.0","method":"foo","id":"2"},1]`,
+			status: http.StatusOK,
+			ids:    []string{"1", "2", ""},
+			codes:  []int{0, ErrCodeMethodNotFound, ErrCodeInvalidRequest},
+			batch:  true,
+		},
+		{
+			name:   "batch of notifications",
+			body:   `[` + notification + `,` + notification + `]`,
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "empty batch",
+			body:   `[]`,
+			status: http.StatusOK,
+			ids:    []string{""},
+			codes:  []int{ErrCodeInvalidRequest},
+		},
+		{
+			name:   "invalid batch",
+			body:   `[` + statusReq,
+			status: http.StatusOK,
+			ids:    []string{""},
+			codes:  []int{ErrCodeParseError},
+		},
+		{
+			name:   "batch too large",
+			body:   `[` + strings.Join(tooLarge, ",") + `]`,
+			status: http.StatusOK,
+			ids:    []string{""},
+			codes:  []int{ErrCodeInvalidRequest},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpc := setupWebRPC(t)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/webrpc", strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			rpc.Handler(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			if tc.status == http.StatusNoContent {
+				require.Empty(t, rr.Body.String())
+				return
+			}
+
+			var rsps []Response
+			if tc.batch {
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rsps))
+			} else {
+				var rsp Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rsp))
+				rsps = []Response{rsp}
+			}
+
+			require.Len(t, rsps, len(tc.ids))
+			for i, rsp := range rsps {
+				var id string
+				if rsp.ID != nil {
+					id = *rsp.ID
+				}
+				require.Equal(t, tc.ids[i], id)
+
+				if tc.codes[i] == 0 {
+					require.Nil(t, rsp.Error)
+					require.NotEmpty(t, rsp.Result)
+				} else {
+					require.NotNil(t, rsp.Error)
+					require.Equal(t, tc.codes[i], rsp.Error.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckedHandler(t *testing.T) {
+	statusReq := `{"jsonrpc":"2.0","method":"get_status","id":"1"}`
+	spendReq := `{"jsonrpc":"2.0","method":"spend","id":"2"}`
+
+	cases := []struct {
+		name    string
+		body    string
+		allow   bool
+		methods []string
+		status  int
+	}{
+		{
+			name:    "single request allowed",
+			body:    statusReq,
+			allow:   true,
+			methods: []string{"get_status"},
+			status:  http.StatusOK,
+		},
+		{
+			name:    "batch denied",
+			body:    `[` + statusReq + `,` + spendReq + `,1]`,
+			methods: []string{"get_status", "spend"},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "invalid request",
+			body:    `"foo"`,
+			allow:   true,
+			methods: []string{},
+			status:  http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpc := setupWebRPC(t)
+
+			var methods []string
+			handler := rpc.CheckedHandler(func(w http.ResponseWriter, r *http.Request, m []string) bool {
+				methods = m
+				if !tc.allow {
+					w.WriteHeader(http.StatusForbidden)
+				}
+				return tc.allow
+			})
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/webrpc", strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			require.Equal(t, tc.methods, methods)
+			if !tc.allow {
+				require.Empty(t, rr.Body.String())
+			}
+		})
+	}
+}
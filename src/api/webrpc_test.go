@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -51,7 +52,12 @@ func TestWebRPC(t *testing.T) {
 					Method:  "get_status",
 				},
 			},
-			want: webrpc.MakeErrorResponse(webrpc.ErrCodeInvalidParams, webrpc.ErrMsgInvalidJsonrpc),
+			want: func() webrpc.Response {
+				res := webrpc.MakeErrorResponse(webrpc.ErrCodeInvalidParams, webrpc.ErrMsgInvalidJsonrpc)
+				id := "1"
+				res.ID = &id
+				return res
+			}(),
 		},
 	}
 
@@ -88,3 +94,179 @@ func TestWebRPC(t *testing.T) {
 		})
 	}
 }
+
+func TestWebRPCMethodScopes(t *testing.T) {
+	rpc, err := webrpc.New(webrpc.Config{}, nil)
+	require.NoError(t, err)
+
+	for _, m := range rpc.Methods() {
+		_, ok := webrpcMethodScopes[m]
+		require.True(t, ok, "JSON-RPC method %s has no scope", m)
+	}
+}
+
+func TestWebRPCScopeCheck(t *testing.T) {
+	store, teardown := setupTokenStore(t)
+	defer teardown()
+
+	adminToken, _, err := store.CreateToken("admin", []Scope{ScopeAdmin})
+	require.NoError(t, err)
+	readToken, _, err := store.CreateToken("read", []Scope{ScopeRead})
+	require.NoError(t, err)
+	spendToken, _, err := store.CreateToken("spend", []Scope{ScopeWalletSpend})
+	require.NoError(t, err)
+
+	// The requests fail to validate their params before calling the gateway
+	spendReq := `{"jsonrpc":"2.0","method":"spend","params":{"id":"foo.wlt"},"id":"1"}`
+	injectReq := `{"jsonrpc":"2.0","method":"inject_transaction","params":[],"id":"2"}`
+	blocksReq := `{"jsonrpc":"2.0","method":"get_blocks","params":[],"id":"3"}`
+
+	cases := []struct {
+		name   string
+		token  string
+		body   string
+		status int
+		err    string
+	}{
+		{
+			name:   "admin token, spend",
+			token:  adminToken,
+			body:   spendReq,
+			status: http.StatusForbidden,
+			err:    "403 Forbidden - API token does not have the wallet.spend scope",
+		},
+		{
+			name:   "admin token, inject_transaction",
+			token:  adminToken,
+			body:   injectReq,
+			status: http.StatusOK,
+		},
+		{
+			name:   "spend token, spend",
+			token:  spendToken,
+			body:   spendReq,
+			status: http.StatusOK,
+		},
+		{
+			name:   "spend token, inject_transaction",
+			token:  spendToken,
+			body:   injectReq,
+			status: http.StatusForbidden,
+			err:    "403 Forbidden - API token does not have the admin scope",
+		},
+		{
+			name:   "read token, get_blocks",
+			token:  readToken,
+			body:   blocksReq,
+			status: http.StatusOK,
+		},
+		{
+			name:   "read token, batch with spend",
+			token:  readToken,
+			body:   "[" + blocksReq + "," + spendReq + "]",
+			status: http.StatusForbidden,
+			err:    "403 Forbidden - API token does not have the wallet.spend scope",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpc, err := webrpc.New(webrpc.Config{}, nil)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/webrpc", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(muxConfig{
+				host:            configuredHost,
+				appLoc:          ".",
+				enableJSON20RPC: true,
+				tokens:          store,
+			}, NewGatewayerMock(), &CSRFStore{}, rpc)
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "wrong status code: got `%v` want `%v`", rr.Code, tc.status)
+			if tc.err != "" {
+				require.Equal(t, tc.err+"\n", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebRPCBatchRateLimit(t *testing.T) {
+	// The requests fail to validate their params before calling the gateway
+	blocksReq := `{"jsonrpc":"2.0","method":"get_blocks","params":[],"id":"1"}`
+	batch := func(n int) string {
+		reqs := make([]string, n)
+		for i := range reqs {
+			reqs[i] = blocksReq
+		}
+		return "[" + strings.Join(reqs, ",") + "]"
+	}
+
+	cases := []struct {
+		name   string
+		bodies []string
+		status []int
+		err    []string
+		rpcErr []*webrpc.RPCError
+	}{
+		{
+			name:   "each request of a batch is charged",
+			bodies: []string{batch(3), blocksReq},
+			status: []int{http.StatusOK, http.StatusTooManyRequests},
+			err:    []string{"", "429 Too Many Requests"},
+		},
+		{
+			name:   "batch over the remaining tokens",
+			bodies: []string{blocksReq, batch(3)},
+			status: []int{http.StatusOK, http.StatusTooManyRequests},
+			err:    []string{"", "429 Too Many Requests"},
+		},
+		{
+			name:   "batch over the burst",
+			bodies: []string{batch(4)},
+			status: []int{http.StatusRequestEntityTooLarge},
+			rpcErr: []*webrpc.RPCError{{
+				Code:    webrpc.ErrCodeInvalidRequest,
+				Message: "batch of 4 requests exceeds the rate limit burst of 3",
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpc, err := webrpc.New(webrpc.Config{}, nil)
+			require.NoError(t, err)
+
+			handler := newServerMux(muxConfig{
+				host:                 configuredHost,
+				appLoc:               ".",
+				enableJSON20RPC:      true,
+				rateLimiter:          NewRateLimiter(0.001, 10),
+				expensiveRateLimiter: NewRateLimiter(0.001, 3),
+			}, NewGatewayerMock(), &CSRFStore{}, rpc)
+
+			for i, body := range tc.bodies {
+				req, err := http.NewRequest(http.MethodPost, "/api/v1/webrpc", bytes.NewBufferString(body))
+				require.NoError(t, err)
+				req.RemoteAddr = "1.2.3.4:1000"
+
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+
+				require.Equal(t, tc.status[i], rr.Code, "request %d", i)
+				if tc.rpcErr != nil {
+					var res webrpc.Response
+					require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+					require.Equal(t, tc.rpcErr[i], res.Error)
+				} else if tc.err[i] != "" {
+					require.Equal(t, tc.err[i]+"\n", rr.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -32,6 +32,18 @@ const (
 	ScryptKeyLen = 32
 )
 
+// Bounds of the scrypt parameters in the metadata of the data to decrypt,
+// checked before deriving the key so that decrypting untrusted data can't exhaust the memory or take too long
+const (
+	// ScryptMaxMemory: the maximum memory that scrypt uses to derive a key, 128*N*r bytes. It is the memory of the default parameters, 1 GiB.
+	ScryptMaxMemory = 128 * ScryptN * ScryptR
+	// ScryptMaxP: the maximum scrypt p parameter. The key derivation time is proportional to p.
+	ScryptMaxP = 4
+)
+
+// ErrScryptParamsOutOfBounds is returned by Decrypt if the scrypt parameters in the metadata are out of bounds
+var ErrScryptParamsOutOfBounds = errors.New("scrypt parameters out of bounds")
+
 // DefaultScryptChacha20poly1305 default ScryptChacha20poly1305 encryptor
 var DefaultScryptChacha20poly1305 = ScryptChacha20poly1305{
 	N:      ScryptN,
@@ -117,7 +129,7 @@ func (s ScryptChacha20poly1305) Encrypt(data, password []byte) ([]byte, error) {
 // Decrypt decrypts the data with password
 // 1. Base64 decodes the data
 // 2. Reads the first [metaLengthSize] bytes data to get the metadata length, and reads out the metadata.
-// 3. Scrypt derives key from password and paramenters in metadata
+// 3. Scrypt derives key from password and paramenters in metadata, if they are within ScryptMaxMemory and ScryptMaxP
 // 4. Chacha20poly1305 geneates AEAD
 // 5. AEAD decrypts ciphertext with nonce in metadata and [length][metadata] as additional data.
 func (s ScryptChacha20poly1305) Decrypt(data, password []byte) ([]byte, error) {
@@ -133,6 +145,10 @@ func (s ScryptChacha20poly1305) Decrypt(data, password []byte) ([]byte, error) {
 	}
 	encData = encData[:n]
 
+	if len(encData) < scryptChacha20MetaLengthSize {
+		return nil, errors.New("invalid metadata length")
+	}
+
 	length := binary.LittleEndian.Uint16(encData[:scryptChacha20MetaLengthSize])
 	if int(scryptChacha20MetaLengthSize+length) > len(encData) {
 		return nil, errors.New("invalid metadata length")
@@ -143,6 +159,10 @@ func (s ScryptChacha20poly1305) Decrypt(data, password []byte) ([]byte, error) {
 		return nil, err
 	}
 
+	if err := m.validateCost(); err != nil {
+		return nil, err
+	}
+
 	ad := encData[:scryptChacha20MetaLengthSize+length]
 	// Scrypt derives key
 	dk, err := scrypt.Key(password, m.Salt, m.N, m.R, m.P, m.KeyLen)
@@ -158,3 +178,20 @@ func (s ScryptChacha20poly1305) Decrypt(data, password []byte) ([]byte, error) {
 
 	return aead.Open(nil, m.Nonce, encData[scryptChacha20MetaLengthSize+length:], ad)
 }
+
+// validateCost checks that scrypt does not use more memory or time than allowed with the parameters of the metadata
+func (m meta) validateCost() error {
+	if m.N <= 1 || m.N&(m.N-1) != 0 || m.R <= 0 || m.P <= 0 || m.P > ScryptMaxP {
+		return ErrScryptParamsOutOfBounds
+	}
+
+	if uint64(m.N) > ScryptMaxMemory/128/uint64(m.R) {
+		return ErrScryptParamsOutOfBounds
+	}
+
+	if m.KeyLen != chacha20poly1305.KeySize {
+		return ErrScryptParamsOutOfBounds
+	}
+
+	return nil
+}
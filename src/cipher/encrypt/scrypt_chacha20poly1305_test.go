@@ -85,3 +85,111 @@ func TestScryptChacha20poly1305Decrypt(t *testing.T) {
 		})
 	}
 }
+
+func TestScryptChacha20poly1305DecryptScryptBounds(t *testing.T) {
+	encData, err := ScryptChacha20poly1305{N: 1 << 10, R: 8, P: 1, KeyLen: 32}.Encrypt([]byte("plaintext"), []byte("pwd"))
+	require.NoError(t, err)
+
+	// setMeta replaces the scrypt parameters of the metadata of encData
+	setMeta := func(n, r, p, keyLen int) []byte {
+		data, err := base64.StdEncoding.DecodeString(string(encData))
+		require.NoError(t, err)
+
+		ml := binary.LittleEndian.Uint16(data[:scryptChacha20MetaLengthSize])
+		var m meta
+		require.NoError(t, json.Unmarshal(data[scryptChacha20MetaLengthSize:scryptChacha20MetaLengthSize+ml], &m))
+		m.N = n
+		m.R = r
+		m.P = p
+		m.KeyLen = keyLen
+
+		ms, err := json.Marshal(m)
+		require.NoError(t, err)
+
+		length := make([]byte, scryptChacha20MetaLengthSize)
+		binary.LittleEndian.PutUint16(length, uint16(len(ms)))
+		rawData := append(append(length, ms...), data[scryptChacha20MetaLengthSize+ml:]...)
+		return []byte(base64.StdEncoding.EncodeToString(rawData))
+	}
+
+	tt := []struct {
+		name   string
+		n      int
+		r      int
+		p      int
+		keyLen int
+		err    error
+	}{
+		{
+			name:   "ok",
+			n:      1 << 10,
+			r:      8,
+			p:      1,
+			keyLen: 32,
+		},
+		{
+			name:   "N uses too much memory",
+			n:      1 << 30,
+			r:      8,
+			p:      1,
+			keyLen: 32,
+			err:    ErrScryptParamsOutOfBounds,
+		},
+		{
+			name:   "r uses too much memory",
+			n:      ScryptN,
+			r:      ScryptR + 1,
+			p:      1,
+			keyLen: 32,
+			err:    ErrScryptParamsOutOfBounds,
+		},
+		{
+			name:   "N*r overflows",
+			n:      1 << 62,
+			r:      1 << 30,
+			p:      1,
+			keyLen: 32,
+			err:    ErrScryptParamsOutOfBounds,
+		},
+		{
+			name:   "p too large",
+			n:      1 << 10,
+			r:      8,
+			p:      ScryptMaxP + 1,
+			keyLen: 32,
+			err:    ErrScryptParamsOutOfBounds,
+		},
+		{
+			name:   "N not a power of 2",
+			n:      1000,
+			r:      8,
+			p:      1,
+			keyLen: 32,
+			err:    ErrScryptParamsOutOfBounds,
+		},
+		{
+			name:   "invalid key length",
+			n:      1 << 10,
+			r:      8,
+			p:      1,
+			keyLen: 1 << 30,
+			err:    ErrScryptParamsOutOfBounds,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := ScryptChacha20poly1305{}.Decrypt(setMeta(tc.n, tc.r, tc.p, tc.keyLen), []byte("pwd"))
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+
+			require.Equal(t, []byte("plaintext"), data)
+		})
+	}
+
+	// Data too short to hold the metadata length
+	_, err = ScryptChacha20poly1305{}.Decrypt([]byte(base64.StdEncoding.EncodeToString([]byte{1})), []byte("pwd"))
+	require.Equal(t, errors.New("invalid metadata length"), err)
+}
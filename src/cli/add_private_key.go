@@ -51,19 +51,17 @@ func addPrivateKeyCmd(cfg Config) gcli.Command {
 				return err
 			}
 
-			pr := NewPasswordReader([]byte(c.String("p")))
+			pr := newWalletPasswordReader(c, w)
 
 			err = AddPrivateKeyToFile(w, skStr, pr)
 
 			switch err.(type) {
 			case nil:
-				fmt.Println("success")
-				return nil
+				return printSuccess(c, "success")
 			case WalletLoadError:
-				errorWithHelp(c, err)
-				return nil
+				return errorWithHelp(c, err)
 			case WalletSaveError:
-				return errors.New("save wallet failed")
+				return WalletSaveError{errors.New("save wallet failed")}
 			default:
 				return err
 			}
@@ -73,13 +73,21 @@ func addressGenCmd() gcli.Command {
 			}
 
 			if !c.Bool("only-addr") {
-				return printJSON(w)
+				return printResult(c, w, nil)
 			}
 
-			for _, e := range w.Entries {
-				fmt.Println(e.Address)
+			addrs := make([]string, len(w.Entries))
+			for i, e := range w.Entries {
+				addrs[i] = e.Address
 			}
-			return nil
+
+			return printResult(c, addressesResult{
+				Addresses: addrs,
+			}, func() {
+				for _, a := range addrs {
+					fmt.Println(a)
+				}
+			})
 		},
 	}
 }
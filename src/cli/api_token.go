@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+// APITokenResult is the output of the createAPIToken command
+type APITokenResult struct {
+	Token string `json:"token"`
+	api.APIToken
+}
+
+func apiTokensFileFlag(cfg Config) gcli.StringFlag {
+	return gcli.StringFlag{
+		Name:  "f",
+		Value: cfg.FullAPITokensPath(),
+		Usage: "[tokens file] File that the node's API tokens are stored in, see the node's -api-tokens-file option",
+	}
+}
+
+func createAPITokenCmd(cfg Config) gcli.Command {
+	name := "createAPIToken"
+	return gcli.Command{
+		Name:      name,
+		Usage:     "Create an API token for a node with API authentication enabled",
+		ArgsUsage: "[scope]...",
+		Description: `
+        Scopes can be read, wallet.read, wallet.spend or admin.
+        wallet.spend includes wallet.read, and all scopes include read.
+
+        The token is printed once and can not be recovered, only its hash is saved.
+        Send it to the node by setting the API_TOKEN environment variable.
+        The node must be able to read the tokens file, it does not need to be restarted.`,
+		Flags: []gcli.Flag{
+			apiTokensFileFlag(cfg),
+			gcli.StringFlag{
+				Name:  "l,label",
+				Usage: "[label] Label of the token",
+			},
+		},
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			if c.NArg() == 0 {
+				return errorWithHelp(c, fmt.Errorf("at least one scope is required"))
+			}
+
+			var scopes []api.Scope
+			for _, a := range c.Args() {
+				for _, s := range strings.Split(a, ",") {
+					scope, err := api.ParseScope(strings.TrimSpace(s))
+					if err != nil {
+						return errorWithHelp(c, err)
+					}
+					scopes = append(scopes, scope)
+				}
+			}
+
+			store, err := api.LoadTokenStore(c.String("f"))
+			if err != nil {
+				return err
+			}
+
+			token, t, err := store.CreateToken(c.String("label"), scopes)
+			if err != nil {
+				return err
+			}
+
+			return printResult(c, APITokenResult{
+				Token:    token,
+				APIToken: *t,
+			}, nil)
+		},
+	}
+}
+
+func revokeAPITokenCmd(cfg Config) gcli.Command {
+	name := "revokeAPIToken"
+	return gcli.Command{
+		Name:         name,
+		Usage:        "Revoke an API token",
+		ArgsUsage:    "[token id]",
+		Flags:        []gcli.Flag{apiTokensFileFlag(cfg)},
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			id := c.Args().First()
+			if id == "" {
+				return errorWithHelp(c, fmt.Errorf("token id is required"))
+			}
+
+			store, err := api.LoadTokenStore(c.String("f"))
+			if err != nil {
+				return err
+			}
+
+			if err := store.RevokeToken(id); err != nil {
+				return err
+			}
+
+			return printSuccess(c, "success")
+		},
+	}
+}
+
+func listAPITokensCmd(cfg Config) gcli.Command {
+	name := "listAPITokens"
+	return gcli.Command{
+		Name:         name,
+		Usage:        "Lists the API tokens, without the tokens themselves",
+		ArgsUsage:    " ",
+		Flags:        []gcli.Flag{apiTokensFileFlag(cfg)},
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			store, err := api.LoadTokenStore(c.String("f"))
+			if err != nil {
+				return err
+			}
+
+			tokens, err := store.Tokens()
+			if err != nil {
+				return err
+			}
+
+			return printResult(c, struct {
+				Tokens []api.APIToken `json:"tokens"`
+			}{
+				Tokens: tokens,
+			}, nil)
+		},
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"strconv"
 
 	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/visor"
 )
 
 func blocksCmd() gcli.Command {
@@ -36,12 +38,12 @@ func getBlocks(c *gcli.Context) error {
 
 	s, err := strconv.ParseUint(start, 10, 64)
 	if err != nil {
-		return fmt.Errorf("invalid block seq: %v, must be unsigned integer", start)
+		return usageError{fmt.Errorf("invalid block seq: %v, must be unsigned integer", start)}
 	}
 
 	e, err := strconv.ParseUint(end, 10, 64)
 	if err != nil {
-		return fmt.Errorf("invalid block seq: %v, must be unsigned integer", end)
+		return usageError{fmt.Errorf("invalid block seq: %v, must be unsigned integer", end)}
 	}
 
 	rlt, err := rpcClient.GetBlocks(s, e)
@@ -49,5 +51,30 @@ func getBlocks(c *gcli.Context) error {
 		return err
 	}
 
-	return printJSON(rlt)
+	return printResult(c, blocksResult(*rlt), nil)
+}
+
+// blocksResult is printed by the blocks and lastBlocks commands, with a row per block in a table
+type blocksResult visor.ReadableBlocks
+
+func (r blocksResult) columns() []string {
+	return []string{"seq", "block_hash", "previous_block_hash", "timestamp", "fee", "version", "tx_body_hash", "size", "txns"}
+}
+
+func (r blocksResult) rows() [][]string {
+	rows := make([][]string, len(r.Blocks))
+	for i, b := range r.Blocks {
+		rows[i] = []string{
+			strconv.FormatUint(b.Head.BkSeq, 10),
+			b.Head.BlockHash,
+			b.Head.PreviousBlockHash,
+			strconv.FormatUint(b.Head.Time, 10),
+			strconv.FormatUint(b.Head.Fee, 10),
+			strconv.FormatUint(uint64(b.Head.Version), 10),
+			b.Head.BodyHash,
+			strconv.Itoa(b.Size),
+			strconv.Itoa(len(b.Body.Transactions)),
+		}
+	}
+	return rows
 }
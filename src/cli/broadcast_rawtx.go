@@ -26,8 +26,13 @@ func broadcastTxCmd() gcli.Command {
 				return err
 			}
 
-			fmt.Println(txid)
-			return nil
+			return printResult(c, struct {
+				Txid string `json:"txid"`
+			}{
+				Txid: txid,
+			}, func() {
+				fmt.Println(txid)
+			})
 		},
 	}
 	// Commands = append(Commands, cmd)
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func changePasswordCmd(cfg Config) gcli.Command {
+	name := "changePassword"
+	return gcli.Command{
+		Name:      name,
+		Usage:     "Change the password or crypto type of an encrypted wallet",
+		ArgsUsage: " ",
+		Description: fmt.Sprintf(`
+		The default wallet (%s) will be
+		used if no wallet was specified.
+
+		The wallet is decrypted in memory only and re-encrypted in one step,
+		it is never saved to disk unencrypted. If the new password is empty,
+		the old password is kept, so the crypto type or scrypt parameters
+		can be changed alone.
+
+		Use caution when using the "-p" and "-n" commands. If you have command history
+		enabled your wallet encryption passwords can be recovered from the history log.
+		If you do not include the "-p" or "-n" options you will be prompted to enter
+		the passwords after you enter your command.`, cfg.FullWalletPath()),
+		Flags: []gcli.Flag{
+			gcli.StringFlag{
+				Name:  "p",
+				Usage: "[password] Current wallet password",
+			},
+			gcli.StringFlag{
+				Name:  "n,new-password",
+				Usage: "[password] New wallet password",
+			},
+			gcli.StringFlag{
+				Name:  "x,crypto-type",
+				Usage: "[crypto type] New crypto type, can be scrypt-chacha20poly1305 or sha256-xor. Defaults to the current crypto type",
+			},
+			gcli.IntFlag{
+				Name:  "scrypt-n",
+				Value: wallet.DefaultScryptParams.N,
+				Usage: "scrypt N parameter for scrypt-chacha20poly1305, a power of 2 of at least 16384",
+			},
+			gcli.IntFlag{
+				Name:  "scrypt-r",
+				Value: wallet.DefaultScryptParams.R,
+				Usage: "scrypt r parameter for scrypt-chacha20poly1305",
+			},
+			gcli.IntFlag{
+				Name:  "scrypt-p",
+				Value: wallet.DefaultScryptParams.P,
+				Usage: "scrypt p parameter for scrypt-chacha20poly1305, between 1 and 4",
+			},
+		},
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			cfg := ConfigFromContext(c)
+
+			w, err := resolveWalletPath(cfg, "")
+			if err != nil {
+				return err
+			}
+
+			var opts wallet.ChangePasswordOptions
+			if s := c.String("x"); s != "" {
+				opts.CryptoType, err = wallet.CryptoTypeFromString(s)
+				if err != nil {
+					return errorWithHelp(c, err)
+				}
+			}
+
+			if c.IsSet("scrypt-n") || c.IsSet("scrypt-r") || c.IsSet("scrypt-p") {
+				opts.ScryptParams = &wallet.ScryptParams{
+					N: c.Int("scrypt-n"),
+					R: c.Int("scrypt-r"),
+					P: c.Int("scrypt-p"),
+				}
+			}
+
+			pr := newWalletPasswordReader(c, w)
+
+			var npr PasswordReader = PasswordFromTerm{
+				Prompt: "enter new password (empty keeps the password):",
+			}
+			if np := c.String("n"); np != "" {
+				npr = PasswordFromBytes(np)
+			}
+
+			wlt, err := changePassword(w, pr, npr, opts)
+			switch err.(type) {
+			case nil:
+				lockWallet(c, w)
+			case WalletLoadError:
+				return errorWithHelp(c, err)
+			case WalletSaveError:
+				return WalletSaveError{errors.New("save wallet failed")}
+			default:
+				return err
+			}
+
+			return printResult(c, wallet.NewReadableWallet(wlt), nil)
+		},
+	}
+}
+
+func changePassword(walletFile string, pr, npr PasswordReader, opts wallet.ChangePasswordOptions) (*wallet.Wallet, error) {
+	wlt, err := wallet.Load(walletFile)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	if !wlt.IsEncrypted() {
+		return nil, wallet.ErrWalletNotEncrypted
+	}
+
+	if pr == nil || npr == nil {
+		return nil, wallet.ErrMissingPassword
+	}
+
+	password, err := pr.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	opts.NewPassword, err = npr.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wlt.ChangePassword(password, opts); err != nil {
+		return nil, err
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(walletFile))
+	if err != nil {
+		return nil, err
+	}
+
+	// save the wallet
+	if err := wlt.Save(dir); err != nil {
+		return nil, WalletSaveError{err}
+	}
+
+	return wlt, nil
+}
@@ -34,6 +34,24 @@ type BalanceResult struct {
 	Addresses []AddressBalance `json:"addresses"`
 }
 
+// columns and rows print a row per address in a table, followed by a row with the total balance,
+// whose address is "total"
+func (r BalanceResult) columns() []string {
+	return []string{"address", "confirmed_coins", "confirmed_hours", "spendable_coins", "spendable_hours", "expected_coins", "expected_hours"}
+}
+
+func (r BalanceResult) rows() [][]string {
+	row := func(address string, confirmed, spendable, expected Balance) []string {
+		return []string{address, confirmed.Coins, confirmed.Hours, spendable.Coins, spendable.Hours, expected.Coins, expected.Hours}
+	}
+
+	rows := make([][]string, 0, len(r.Addresses)+1)
+	for _, a := range r.Addresses {
+		rows = append(rows, row(a.Address, a.Confirmed, a.Spendable, a.Expected))
+	}
+	return append(rows, row("total", r.Confirmed, r.Spendable, r.Expected))
+}
+
 func walletBalanceCmd(cfg Config) gcli.Command {
 	name := "walletBalance"
 	return gcli.Command{
@@ -82,13 +100,12 @@ func checkWltBalance(c *gcli.Context) error {
 	switch err.(type) {
 	case nil:
 	case WalletLoadError:
-		errorWithHelp(c, err)
-		return nil
+		return errorWithHelp(c, err)
 	default:
 		return err
 	}
 
-	return printJSON(balRlt)
+	return printResult(c, balRlt, nil)
 }
 
 func addrBalance(c *gcli.Context) error {
@@ -99,7 +116,7 @@ func addrBalance(c *gcli.Context) error {
 	for i := 0; i < c.NArg(); i++ {
 		addrs[i] = c.Args().Get(i)
 		if _, err = cipher.DecodeBase58Address(addrs[i]); err != nil {
-			return fmt.Errorf("invalid address: %v, err: %v", addrs[i], err)
+			return usageError{fmt.Errorf("invalid address: %v, err: %v", addrs[i], err)}
 		}
 	}
 
@@ -108,7 +125,7 @@ func addrBalance(c *gcli.Context) error {
 		return err
 	}
 
-	return printJSON(balRlt)
+	return printResult(c, balRlt, nil)
 }
 
 // PUBLIC
@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/boltdb/bolt"
@@ -32,10 +33,30 @@ func wrapDB(db *bolt.DB) *dbutil.DB {
 func checkdbCmd() gcli.Command {
 	name := "checkdb"
 	return gcli.Command{
-		Name:         name,
-		Usage:        "Verify the database",
-		ArgsUsage:    "[db path]",
-		Description:  "If no argument is specificed, the default data.db in $HOME/.$COIN/ will be checked.",
+		Name:      name,
+		Usage:     "Verify the database",
+		ArgsUsage: "[db path]",
+		Description: `
+		If no argument is specificed, the default data.db in $HOME/.$COIN/ will be checked.
+
+		The blocks are verified with the blockchain public key and the key rotations saved
+		in the database. For a blockchain produced by block makers, set the "--block-makers"
+		option like the node's "-block-makers" option, and the blocks signed by a block maker
+		are verified to have the signatures of a quorum of block makers.`,
+		Flags: []gcli.Flag{
+			gcli.StringFlag{
+				Name:  "master-signer-public-keys",
+				Usage: "[pubkeys] Comma separated public keys that can sign blocks along with the blockchain public key, until the first key rotation",
+			},
+			gcli.StringFlag{
+				Name:  "block-makers",
+				Usage: "[pubkeys] Comma separated public keys of the block makers",
+			},
+			gcli.IntFlag{
+				Name:  "block-maker-quorum",
+				Usage: "[n] Number of block maker signatures that a block signed by a block maker needs. Defaults to a majority of --block-makers",
+			},
+		},
 		OnUsageError: onCommandUsageError(name),
 		Action:       checkdb,
 	}
@@ -55,6 +76,11 @@ func checkdb(c *gcli.Context) error {
 		return fmt.Errorf("db file: %v does not exist", dbpath)
 	}
 
+	bcCfg, err := checkdbBlockchainConfig(c)
+	if err != nil {
+		return errorWithHelp(c, err)
+	}
+
 	db, err := bolt.Open(dbpath, 0600, &bolt.Options{
 		Timeout:  5 * time.Second,
 		ReadOnly: true,
@@ -63,23 +89,66 @@ func checkdb(c *gcli.Context) error {
 	if err != nil {
 		return fmt.Errorf("open db failed: %v", err)
 	}
-	pubkey, err := cipher.PubKeyFromHex(blockchainPubkey)
-	if err != nil {
-		return fmt.Errorf("decode blockchain pubkey failed: %v", err)
-	}
 
 	quit := QuitChanFromContext(c)
 	go func() {
 		apputil.CatchInterrupt(quit)
 	}()
 
-	if err := visor.CheckDatabase(wrapDB(db), pubkey, quit); err != nil {
+	if err := visor.CheckDatabase(wrapDB(db), bcCfg, quit); err != nil {
 		if err == visor.ErrVerifyStopped {
 			return nil
 		}
 		return fmt.Errorf("checkdb failed: %v", err)
 	}
 
-	fmt.Println("check db success")
-	return nil
+	return printSuccess(c, "check db success")
+}
+
+// checkdbBlockchainConfig returns the configuration that the blocks are verified with, from the checkdb options
+func checkdbBlockchainConfig(c *gcli.Context) (visor.BlockchainConfig, error) {
+	pubkey, err := cipher.PubKeyFromHex(blockchainPubkey)
+	if err != nil {
+		return visor.BlockchainConfig{}, fmt.Errorf("decode blockchain pubkey failed: %v", err)
+	}
+
+	signers, err := parsePubkeys(c.String("master-signer-public-keys"))
+	if err != nil {
+		return visor.BlockchainConfig{}, fmt.Errorf("invalid master-signer-public-keys: %v", err)
+	}
+
+	makers, err := parsePubkeys(c.String("block-makers"))
+	if err != nil {
+		return visor.BlockchainConfig{}, fmt.Errorf("invalid block-makers: %v", err)
+	}
+
+	quorum := c.Int("block-maker-quorum")
+	if len(makers) != 0 && quorum == 0 {
+		quorum = len(makers)/2 + 1
+	}
+
+	return visor.BlockchainConfig{
+		Pubkey:            pubkey,
+		SignerPubkeys:     signers,
+		BlockMakerPubkeys: makers,
+		BlockMakerQuorum:  quorum,
+	}, nil
+}
+
+// parsePubkeys parses comma separated pubkeys. An empty string has no pubkeys.
+func parsePubkeys(s string) ([]cipher.PubKey, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var pubkeys []cipher.PubKey
+	for _, x := range strings.Split(s, ",") {
+		pk, err := cipher.PubKeyFromHex(x)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %v", x, err)
+		}
+		pubkeys = append(pubkeys, pk)
+	}
+
+	return pubkeys, nil
 }
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestParsePubkeys(t *testing.T) {
+	pk1, _ := cipher.GenerateKeyPair()
+	pk2, _ := cipher.GenerateKeyPair()
+
+	cases := []struct {
+		s       string
+		pubkeys []cipher.PubKey
+		err     string
+	}{
+		{"", nil, ""},
+		{pk1.Hex(), []cipher.PubKey{pk1}, ""},
+		{pk1.Hex() + "," + pk2.Hex(), []cipher.PubKey{pk1, pk2}, ""},
+		{pk1.Hex() + ",foo", nil, `"foo": Invalid public key`},
+	}
+
+	for _, tc := range cases {
+		pubkeys, err := parsePubkeys(tc.s)
+		if tc.err != "" {
+			require.EqualError(t, err, tc.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tc.pubkeys, pubkeys)
+	}
+}
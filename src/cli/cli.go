@@ -20,6 +20,7 @@ import (
 	gcli "github.com/urfave/cli"
 	"golang.org/x/crypto/ssh/terminal"
 
+	"github.com/skycoin/skycoin/src/api"
 	"github.com/skycoin/skycoin/src/api/webrpc"
 	"github.com/skycoin/skycoin/src/util/file"
 )
@@ -40,6 +41,7 @@ var (
     RPC_ADDR: Address of RPC node. Must be in scheme://host format. Default "%s"
     COIN: Name of the coin. Default "%s"
     USE_CSRF: Set to 1 or true if the remote node has CSRF enabled. Default false (unset)
+    API_TOKEN: API token to send to the remote node, if it requires API authentication. Default "" (unset)
     WALLET_DIR: Directory where wallets are stored. This value is overriden by any subcommand flag specifying a wallet filename, if that filename includes a path. Default "%s"
     WALLET_NAME: Name of wallet file (without path). This value is overriden by any subcommand flag specifying a wallet filename. Default "%s"
     DATA_DIR: Directory where everything is stored. Default "%s"`, defaultRPCAddress, defaultCoin, defaultWalletDir, defaultWalletName, defaultDataDir)
@@ -110,6 +112,7 @@ type Config struct {
 	Coin       string `json:"coin"`
 	RPCAddress string `json:"rpc_address"`
 	UseCSRF    bool   `json:"use_csrf"`
+	APIToken   string `json:"-"`
 }
 
 // LoadConfig loads config from environment, prior to parsing CLI flags
@@ -170,6 +173,7 @@ func LoadConfig() (Config, error) {
 		Coin:       coin,
 		RPCAddress: rpcAddr,
 		UseCSRF:    useCSRF,
+		APIToken:   os.Getenv("API_TOKEN"),
 	}, nil
 }
 
@@ -183,6 +187,11 @@ func (c Config) FullDBPath() string {
 	return filepath.Join(c.DataDir, "data.db")
 }
 
+// FullAPITokensPath returns the joined data directory and API tokens file name path
+func (c Config) FullAPITokensPath() string {
+	return filepath.Join(c.DataDir, api.APITokensFilename)
+}
+
 // Returns a full wallet path based on cfg and optional cli arg specifying wallet file
 // FIXME: A CLI flag for the wallet filename is redundant with the envvar. Remove the flags or the envvar.
 func resolveWalletPath(cfg Config, w string) (string, error) {
@@ -245,12 +254,14 @@ func NewApp(cfg Config) (*App, error) {
 		checkdbCmd(),
 		createRawTxCmd(cfg),
 		decodeRawTxCmd(),
+		estimateTxCmd(cfg),
 		generateAddrsCmd(cfg),
 		generateWalletCmd(cfg),
 		lastBlocksCmd(),
 		listAddressesCmd(),
 		listWalletsCmd(),
 		sendCmd(),
+		shellCmd(),
 		showConfigCmd(),
 		statusCmd(),
 		transactionCmd(),
@@ -259,26 +270,80 @@ func NewApp(cfg Config) (*App, error) {
 		walletBalanceCmd(cfg),
 		walletDirCmd(),
 		walletHisCmd(),
+		walletLedgerCmd(),
 		walletOutputsCmd(cfg),
 		encryptWalletCmd(cfg),
 		decryptWalletCmd(cfg),
+		changePasswordCmd(cfg),
 		showSeedCmd(cfg),
+		exportWalletCmd(cfg),
+		importWalletCmd(cfg),
+		walletMetadataCmd(cfg),
+		setNoteCmd(cfg),
+		setAddressLabelCmd(cfg),
+		addContactCmd(cfg),
+		removeContactCmd(cfg),
+		createAPITokenCmd(cfg),
+		revokeAPITokenCmd(cfg),
+		listAPITokensCmd(cfg),
+		keyRotationsCmd(),
+		createKeyRotationCmd(),
+		injectKeyRotationCmd(),
+	}
+
+	for i := range commands {
+		if action, ok := commands[i].Action.(func(*gcli.Context) error); ok {
+			commands[i].Action = withExitCode(action)
+		}
 	}
 
 	app.Name = fmt.Sprintf("%s-cli", cfg.Coin)
 	app.Version = Version
 	app.Usage = fmt.Sprintf("the %s command line interface", cfg.Coin)
 	app.Commands = commands
+	app.Flags = []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "output,o",
+			Usage: "Output format of the command results: json, table or csv. Without it, each command uses its default output",
+		},
+		gcli.StringFlag{
+			Name:  "script",
+			Usage: "Run the commands of a file, one per line, and stop on the first command that fails",
+		},
+		gcli.DurationFlag{
+			Name:  "session-timeout",
+			Value: defaultSessionTimeout,
+			Usage: "In a shell or script, how long a wallet stays unlocked after its password was last used. 0 disables unlocking",
+		},
+	}
+	app.Action = func(c *gcli.Context) error {
+		if script := c.String("script"); script != "" {
+			return runScript(c, script)
+		}
+
+		// Like the default action, an unknown command is reported by CommandNotFound
+		if c.Args().Present() {
+			return gcli.ShowCommandHelp(c, c.Args().First())
+		}
+
+		return gcli.ShowAppHelp(c)
+	}
+	app.Before = func(c *gcli.Context) error {
+		if err := validateOutputFormat(c); err != nil {
+			return exitError(c, err, ExitCodeUsage)
+		}
+		return nil
+	}
 	app.EnableBashCompletion = true
 	app.OnUsageError = func(context *gcli.Context, err error, isSubcommand bool) error {
 		fmt.Fprintf(context.App.Writer, "Error: %v\n\n", err)
 		gcli.ShowAppHelp(context)
-		return nil
+		return gcli.NewExitError("", ExitCodeUsage)
 	}
 	app.CommandNotFound = func(ctx *gcli.Context, command string) {
 		tmp := fmt.Sprintf("{{.HelpName}}: '%s' is not a {{.HelpName}} command. See '{{.HelpName}} --help'.\n", command)
 		gcli.HelpPrinter(app.Writer, tmp, app)
-		gcli.OsExiter(1)
+		gcli.OsExiter(ExitCodeUsage)
 	}
 
 	rpcClient, err := webrpc.NewClient(cfg.RPCAddress)
@@ -286,10 +351,15 @@ func NewApp(cfg Config) (*App, error) {
 		return nil, err
 	}
 	rpcClient.UseCSRF = cfg.UseCSRF
+	rpcClient.AuthToken = cfg.APIToken
+
+	apiClient := api.NewClient(cfg.RPCAddress)
+	apiClient.AuthToken = cfg.APIToken
 
 	app.Metadata = map[string]interface{}{
 		"config":   cfg,
 		"rpc":      rpcClient,
+		"api":      apiClient,
 		"quitChan": make(chan struct{}),
 	}
 
@@ -306,6 +376,11 @@ func RPCClientFromContext(c *gcli.Context) *webrpc.Client {
 	return c.App.Metadata["rpc"].(*webrpc.Client)
 }
 
+// APIClientFromContext returns an api.Client from a urfave/cli Context
+func APIClientFromContext(c *gcli.Context) *api.Client {
+	return c.App.Metadata["api"].(*api.Client)
+}
+
 // ConfigFromContext returns a Config from a urfave/cli Context
 func ConfigFromContext(c *gcli.Context) Config {
 	return c.App.Metadata["config"].(Config)
@@ -320,12 +395,24 @@ func onCommandUsageError(command string) gcli.OnUsageErrorFunc {
 	return func(c *gcli.Context, err error, isSubcommand bool) error {
 		fmt.Fprintf(c.App.Writer, "Error: %v\n\n", err)
 		gcli.ShowCommandHelp(c, command)
-		return nil
+		return gcli.NewExitError("", ExitCodeUsage)
 	}
 }
 
-func errorWithHelp(c *gcli.Context, err error) {
+// errorWithHelp prints an error of the command with a hint to its help, and returns the error
+// that makes the CLI exit. Errors without a class are usage errors.
+func errorWithHelp(c *gcli.Context, err error) error {
+	code := ExitCode(err)
+	if code == ExitCodeError {
+		code = ExitCodeUsage
+	}
+
+	if outputFormat(c) == outputJSON {
+		return exitError(c, err, code)
+	}
+
 	fmt.Fprintf(c.App.Writer, "Error: %v. See '%s %s --help'\n\n", err, c.App.HelpName, c.Command.Name)
+	return gcli.NewExitError("", code)
 }
 
 func formatJSON(obj interface{}) ([]byte, error) {
@@ -348,9 +435,13 @@ func printJSON(obj interface{}) error {
 }
 
 // readPasswordFromTerminal promotes user to enter password and read it.
-func readPasswordFromTerminal() ([]byte, error) {
+func readPasswordFromTerminal(prompt string) ([]byte, error) {
+	if prompt == "" {
+		prompt = "enter password:"
+	}
+
 	// Promotes to enter the wallet password
-	fmt.Fprint(os.Stdout, "enter password:")
+	fmt.Fprint(os.Stdout, prompt)
 	bp, err := terminal.ReadPassword(int(syscall.Stdin))
 	if err != nil {
 		return nil, err
@@ -386,11 +477,14 @@ func (p PasswordFromBytes) Password() ([]byte, error) {
 }
 
 // PasswordFromTerm reads password from terminal
-type PasswordFromTerm struct{}
+type PasswordFromTerm struct {
+	// Prompt is printed before reading the password, defaults to "enter password:"
+	Prompt string
+}
 
 // Password implements the PasswordReader's Password method
 func (p PasswordFromTerm) Password() ([]byte, error) {
-	v, err := readPasswordFromTerminal()
+	v, err := readPasswordFromTerminal(p.Prompt)
 	if err != nil {
 		return nil, err
 	}
@@ -90,25 +90,22 @@ func createRawTxCmd(cfg Config) gcli.Command {
 			switch err.(type) {
 			case nil:
 			case WalletLoadError:
-				errorWithHelp(c, err)
+				return errorWithHelp(c, err)
 			case WalletSaveError:
-				return errors.New("save wallet failed")
+				return WalletSaveError{errors.New("save wallet failed")}
 			default:
 				return err
 			}
 
 			rawTx := hex.EncodeToString(tx.Serialize())
 
-			if c.Bool("json") {
-				return printJSON(struct {
-					RawTx string `json:"rawtx"`
-				}{
-					RawTx: rawTx,
-				})
-			}
-
-			fmt.Println(rawTx)
-			return nil
+			return printResult(c, struct {
+				RawTx string `json:"rawtx"`
+			}{
+				RawTx: rawTx,
+			}, func() {
+				fmt.Println(rawTx)
+			})
 		},
 	}
 }
@@ -249,7 +246,7 @@ func createRawTxCmdHandler(c *gcli.Context) (*coin.Transaction, error) {
 		return nil, err
 	}
 
-	pr := NewPasswordReader([]byte(c.String("p")))
+	pr := newWalletPasswordReader(c, wltAddr.Wallet)
 	if wltAddr.Address == "" {
 		return CreateRawTxFromWallet(rpcClient, wltAddr.Wallet, chgAddr, toAddrs, pr)
 	}
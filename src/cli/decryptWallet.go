@@ -38,22 +38,21 @@ func decryptWalletCmd(cfg Config) gcli.Command {
 				return err
 			}
 
-			pr := NewPasswordReader([]byte(c.String("p")))
+			pr := newWalletPasswordReader(c, w)
 
 			wlt, err := decryptWallet(w, pr)
 			switch err.(type) {
 			case nil:
+				lockWallet(c, w)
 			case WalletLoadError:
-				errorWithHelp(c, err)
-				return nil
+				return errorWithHelp(c, err)
 			case WalletSaveError:
-				return errors.New("save wallet failed")
+				return WalletSaveError{errors.New("save wallet failed")}
 			default:
 				return err
 			}
 
-			printJSON(wallet.NewReadableWallet(wlt))
-			return nil
+			return printResult(c, wallet.NewReadableWallet(wlt), nil)
 		},
 	}
 }
@@ -46,8 +46,7 @@ func encryptWalletCmd(cfg Config) gcli.Command {
 
 			cryptoType, err := wallet.CryptoTypeFromString(c.String("x"))
 			if err != nil {
-				errorWithHelp(c, err)
-				return nil
+				return errorWithHelp(c, err)
 			}
 
 			pr := NewPasswordReader([]byte(c.String("p")))
@@ -56,16 +55,14 @@ func encryptWalletCmd(cfg Config) gcli.Command {
 			switch err.(type) {
 			case nil:
 			case WalletLoadError:
-				errorWithHelp(c, err)
-				return nil
+				return errorWithHelp(c, err)
 			case WalletSaveError:
-				return errors.New("save wallet failed")
+				return WalletSaveError{errors.New("save wallet failed")}
 			default:
 				return err
 			}
 
-			printJSON(wallet.NewReadableWallet(wlt))
-			return nil
+			return printResult(c, wallet.NewReadableWallet(wlt), nil)
 		},
 	}
 }
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+const defaultShareFactor = "0.5"
+
+func estimateTxCmd(cfg Config) gcli.Command {
+	name := "estimateTransaction"
+	return gcli.Command{
+		Name:      name,
+		Usage:     "Preview a transaction without signing it, with an estimate of how soon it would be confirmed",
+		ArgsUsage: "[to address] [amount]",
+		Description: fmt.Sprintf(`
+  Note: The [amount] argument is the coins you will spend, 1 coins = 1e6 droplets.
+
+		  The default wallet (%s) will be
+		  used if no wallet and address was specified.
+
+        Shows the outputs that would be spent, the hours sent to each destination and
+        the change address, the coin hours burned and the size of the transaction.
+        The hours remaining after the fee is burned are shared between the destinations
+        and the change output according to the share factor.
+
+        The fee per byte of the transaction is compared with the unconfirmed transactions,
+        which are confirmed first if their fee per byte is higher.
+
+        The wallet password is not required.`, cfg.FullWalletPath()),
+		Flags: []gcli.Flag{
+			gcli.StringFlag{
+				Name:  "f",
+				Usage: "[wallet file or path], From wallet",
+			},
+			gcli.StringFlag{
+				Name:  "a",
+				Usage: "[address] From address",
+			},
+			gcli.StringFlag{
+				Name: "c",
+				Usage: `[changeAddress] Specify different change address.
+				By default one of the addresses of the spent outputs will be used.`,
+			},
+			gcli.StringFlag{
+				Name: "m",
+				Usage: `[send to many] use JSON string to set multiple receive addresses and coins,
+				example: -m '[{"addr":"$addr1", "coins": "10.2"}, {"addr":"$addr2", "coins": "20"}]'`,
+			},
+			gcli.StringFlag{
+				Name:  "s",
+				Value: defaultShareFactor,
+				Usage: "[share factor] Share of the remaining coin hours sent to the destinations, between 0 and 1",
+			},
+			gcli.BoolFlag{
+				Name:  "ignore-unconfirmed",
+				Usage: "Ignore outputs spent by unconfirmed transactions, instead of returning an error",
+			},
+		},
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			estimate, err := estimateTxCmdHandler(c)
+			switch err.(type) {
+			case nil:
+			case WalletLoadError:
+				return errorWithHelp(c, err)
+			default:
+				return err
+			}
+
+			return printResult(c, estimate, nil)
+		},
+	}
+}
+
+func estimateTxCmdHandler(c *gcli.Context) (*api.EstimateTransactionResponse, error) {
+	wltAddr, err := fromWalletOrAddress(c)
+	if err != nil {
+		return nil, err
+	}
+
+	toAddrs, err := getToAddresses(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateSendAmounts(toAddrs); err != nil {
+		return nil, err
+	}
+
+	// Spend from the wallet's addresses, so that the node does not need to have the wallet loaded
+	var addrs []string
+	if wltAddr.Address != "" {
+		addrs = []string{wltAddr.Address}
+	} else {
+		wlt, err := wallet.Load(wltAddr.Wallet)
+		if err != nil {
+			return nil, WalletLoadError{err}
+		}
+
+		for _, a := range wlt.GetAddresses() {
+			addrs = append(addrs, a.String())
+		}
+	}
+
+	shareFactor := c.String("s")
+	if _, err := decimal.NewFromString(shareFactor); err != nil {
+		return nil, fmt.Errorf("invalid share factor: %v", err)
+	}
+
+	to := make([]api.Receiver, len(toAddrs))
+	for i, t := range toAddrs {
+		coins, err := droplet.ToString(t.Coins)
+		if err != nil {
+			return nil, err
+		}
+
+		to[i] = api.Receiver{
+			Address: t.Addr,
+			Coins:   coins,
+		}
+	}
+
+	req := api.CreateTransactionRequest{
+		IgnoreUnconfirmed: c.Bool("ignore-unconfirmed"),
+		HoursSelection: api.HoursSelection{
+			Type:        wallet.HoursSelectionTypeAuto,
+			Mode:        wallet.HoursSelectionModeShare,
+			ShareFactor: shareFactor,
+		},
+		Wallet: api.CreateTransactionRequestWallet{
+			Addresses: addrs,
+		},
+		To: to,
+	}
+
+	if chgAddr := c.String("c"); chgAddr != "" {
+		req.ChangeAddress = &chgAddr
+	}
+
+	return APIClientFromContext(c).EstimateTransaction(req)
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"encoding/json"
+	"net"
+
+	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/api/webrpc"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// Exit codes of the CLI, by the class of error that made a command fail
+const (
+	// ExitCodeError is the exit code of an error that has no other class
+	ExitCodeError = 1
+	// ExitCodeUsage is the exit code of an unknown command, or of invalid arguments or options
+	ExitCodeUsage = 2
+	// ExitCodeWallet is the exit code of a wallet that could not be loaded, saved, decrypted or used
+	ExitCodeWallet = 3
+	// ExitCodeNodeUnreachable is the exit code of a node that could not be reached
+	ExitCodeNodeUnreachable = 4
+	// ExitCodeNode is the exit code of a request that the node rejected
+	ExitCodeNode = 5
+)
+
+var exitCodeClasses = map[int]string{
+	ExitCodeError:           "error",
+	ExitCodeUsage:           "usage",
+	ExitCodeWallet:          "wallet",
+	ExitCodeNodeUnreachable: "node_unreachable",
+	ExitCodeNode:            "node",
+}
+
+// usageError is returned if the arguments or options of a command are invalid
+type usageError struct {
+	error
+}
+
+// ErrorResult is printed instead of the error message with --output json
+type ErrorResult struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Class   string `json:"class"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ExitCode returns the exit code for an error returned by a command
+func ExitCode(err error) int {
+	switch e := err.(type) {
+	case nil:
+		return 0
+	case gcli.ExitCoder:
+		return e.ExitCode()
+	case usageError:
+		return ExitCodeUsage
+	case WalletLoadError, WalletSaveError, wallet.Error:
+		return ExitCodeWallet
+	case webrpc.ClientError, webrpc.RPCError, *webrpc.RPCError, api.ClientError:
+		return ExitCodeNode
+	case net.Error:
+		return ExitCodeNodeUnreachable
+	}
+
+	if err == ErrWalletName {
+		return ExitCodeUsage
+	}
+
+	return ExitCodeError
+}
+
+// exitError returns the error that makes the CLI exit with the exit code of err.
+// The error message is printed as an ErrorResult with --output json.
+func exitError(c *gcli.Context, err error, code int) error {
+	msg := err.Error()
+	if outputFormat(c) == outputJSON {
+		var r ErrorResult
+		r.Error.Code = code
+		r.Error.Class = exitCodeClasses[code]
+		r.Error.Message = msg
+
+		b, err := json.Marshal(r)
+		if err != nil {
+			return gcli.NewExitError(msg, code)
+		}
+		msg = string(b)
+	}
+
+	return gcli.NewExitError(msg, code)
+}
+
+// withExitCode makes the errors returned by a command's action exit with their exit code
+func withExitCode(action func(*gcli.Context) error) func(*gcli.Context) error {
+	return func(c *gcli.Context) error {
+		err := action(c)
+		switch err.(type) {
+		case nil, gcli.ExitCoder:
+			return err
+		}
+
+		return exitError(c, err, ExitCode(err))
+	}
+}
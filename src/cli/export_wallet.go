@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+
+	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func exportWalletCmd(cfg Config) gcli.Command {
+	name := "exportWallet"
+	return gcli.Command{
+		Name:      name,
+		Usage:     "Export wallet to a password encrypted bundle",
+		ArgsUsage: " ",
+		Description: fmt.Sprintf(`
+		The bundle holds the wallet, its label and its transaction notes, address
+		labels and contacts. An encrypted wallet stays encrypted with its wallet
+		password in the bundle.
+
+		Use "-w" to export only the addresses and public keys, for a watch-only wallet.
+		The wallet password of an encrypted wallet is then required, to decrypt the
+		notes, labels and contacts. Set it with "--wallet-password" or enter it when
+		prompted.
+
+		The default wallet (%s) will be
+		used if no wallet was specified.
+
+		Use caution when using the "-p" command. If you have command history enabled
+		your bundle password can be recovered from the history log. If you
+		do not include the "-p" option you will be prompted to enter your password
+		after you enter your command.`, cfg.FullWalletPath()),
+		Flags: []gcli.Flag{
+			gcli.StringFlag{
+				Name:  "f",
+				Usage: "[wallet file or path] Wallet to export. If no path is specified your default wallet path will be used.",
+			},
+			gcli.StringFlag{
+				Name:  "p",
+				Usage: "[password] Bundle password",
+			},
+			gcli.BoolFlag{
+				Name:  "w,watch-only",
+				Usage: "Leave out the seeds and secret keys",
+			},
+			gcli.StringFlag{
+				Name:  "wallet-password",
+				Usage: "[password] Wallet password, required with -w if the wallet is encrypted",
+			},
+			gcli.StringFlag{
+				Name:  "o,output",
+				Usage: "[bundle file] Write the bundle to a file, instead of printing it",
+			},
+		},
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			cfg := ConfigFromContext(c)
+
+			w, err := resolveWalletPath(cfg, c.String("f"))
+			if err != nil {
+				return err
+			}
+
+			var pr PasswordReader = PasswordFromTerm{
+				Prompt: "enter bundle password:",
+			}
+			if p := c.String("p"); p != "" {
+				pr = PasswordFromBytes(p)
+			}
+
+			var wpr PasswordReader = PasswordFromTerm{
+				Prompt: "enter wallet password:",
+			}
+			if p := c.String("wallet-password"); p != "" {
+				wpr = PasswordFromBytes(p)
+			}
+
+			eb, err := exportWallet(w, pr, wpr, c.Bool("w"))
+			switch err.(type) {
+			case nil:
+			case WalletLoadError:
+				return errorWithHelp(c, err)
+			default:
+				return err
+			}
+
+			if out := c.String("o"); out != "" {
+				return file.SaveJSON(out, eb, 0600)
+			}
+
+			return printResult(c, eb, nil)
+		},
+	}
+}
+
+func exportWallet(walletFile string, pr, wpr PasswordReader, watchOnly bool) (*wallet.EncryptedBundle, error) {
+	wlt, err := wallet.Load(walletFile)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	var m *wallet.Metadata
+	if watchOnly {
+		var walletPassword []byte
+		if wlt.IsEncrypted() {
+			walletPassword, err = wpr.Password()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		m, err = wlt.ViewMetadata(walletPassword)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	password, err := pr.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet.NewBundle(wlt, m, watchOnly).Encrypt(password)
+}
@@ -59,38 +59,29 @@ func generateAddrs(c *gcli.Context) error {
 		return errors.New("-n must > 0")
 	}
 
-	jsonFmt := c.Bool("json")
-
 	w, err := resolveWalletPath(cfg, c.String("f"))
 	if err != nil {
 		return err
 	}
 
-	pr := NewPasswordReader([]byte(c.String("p")))
+	pr := newWalletPasswordReader(c, w)
 	addrs, err := GenerateAddressesInFile(w, num, pr)
 
 	switch err.(type) {
 	case nil:
 	case WalletLoadError:
-		errorWithHelp(c, err)
-		return nil
+		return errorWithHelp(c, err)
 	case WalletSaveError:
-		return errors.New("save wallet failed")
+		return WalletSaveError{errors.New("save wallet failed")}
 	default:
 		return err
 	}
 
-	if jsonFmt {
-		s, err := FormatAddressesAsJSON(addrs)
-		if err != nil {
-			return err
-		}
-		fmt.Println(s)
-	} else {
+	return printResult(c, addressesResult{
+		Addresses: AddressesToStrings(addrs),
+	}, func() {
 		fmt.Println(FormatAddressesAsJoinedArray(addrs))
-	}
-
-	return nil
+	})
 }
 
 // GenerateAddressesInFile generates addresses in given wallet file
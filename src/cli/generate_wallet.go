@@ -182,7 +182,7 @@ func generateWalletHandler(c *gcli.Context) error {
 		return err
 	}
 
-	return printJSON(wallet.NewReadableWallet(wlt))
+	return printResult(c, wallet.NewReadableWallet(wlt), nil)
 }
 
 func makeSeed(s string, r, rd bool) (string, error) {
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func importWalletCmd(cfg Config) gcli.Command {
+	name := "importWallet"
+	return gcli.Command{
+		Name:      name,
+		Usage:     "Import wallet from a bundle made by exportWallet",
+		ArgsUsage: "[bundle file]",
+		Description: fmt.Sprintf(`
+		The wallet is saved in the wallet directory (%s),
+		with the notes, labels and contacts in the bundle.
+		A wallet with the same seed as a wallet in the wallet directory is not imported.
+
+		Use caution when using the "-p" command. If you have command history enabled
+		your bundle password can be recovered from the history log. If you
+		do not include the "-p" option you will be prompted to enter your password
+		after you enter your command.`, cfg.WalletDir),
+		Flags: []gcli.Flag{
+			gcli.StringFlag{
+				Name:  "p",
+				Usage: "[password] Bundle password",
+			},
+		},
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			cfg := ConfigFromContext(c)
+
+			if c.NArg() != 1 {
+				return errorWithHelp(c, errors.New("missing bundle file"))
+			}
+
+			var eb wallet.EncryptedBundle
+			if err := file.LoadJSON(c.Args().First(), &eb); err != nil {
+				return fmt.Errorf("load bundle failed: %v", err)
+			}
+
+			pr := NewPasswordReader([]byte(c.String("p")))
+
+			wlt, err := importWallet(cfg.WalletDir, eb, pr)
+			if err != nil {
+				return err
+			}
+
+			// Don't print the seeds and secret keys
+			rw := wallet.NewReadableWallet(wlt)
+			rw.Erase()
+			return printResult(c, rw, nil)
+		},
+	}
+}
+
+func importWallet(walletDir string, eb wallet.EncryptedBundle, pr PasswordReader) (*wallet.Wallet, error) {
+	password, err := pr.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	// The wallet service checks for duplicate seeds in the wallet directory
+	serv, err := wallet.NewService(wallet.Config{
+		WalletDir:       walletDir,
+		EnableWalletAPI: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return serv.ImportWallet(eb, password)
+}
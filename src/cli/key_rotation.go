@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func keyRotationsCmd() gcli.Command {
+	name := "keyRotations"
+	return gcli.Command{
+		Name:         name,
+		Usage:        "Show the key rotations of the blockchain signing keys",
+		ArgsUsage:    " ",
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			if c.NArg() > 0 {
+				return errorWithHelp(c, errors.New("invalid argument"))
+			}
+
+			rotations, err := APIClientFromContext(c).KeyRotations()
+			if err != nil {
+				return err
+			}
+
+			return printResult(c, rotations, nil)
+		},
+	}
+}
+
+func createKeyRotationCmd() gcli.Command {
+	name := "createKeyRotation"
+	return gcli.Command{
+		Name:      name,
+		Usage:     "Create a key rotation of the blockchain signing keys",
+		ArgsUsage: "[seq] [pubkey...]",
+		Description: `
+		Creates a key rotation that replaces the public keys that can sign blocks with
+		the given public keys, starting from the block with sequence number seq.
+		The key rotation is signed with the secret key of a public key that can sign
+		the block with sequence number seq before the rotation. It is created offline,
+		and is added to a node with the "injectKeyRotation" command.
+
+		Use caution when using the "-k" option. If you have command history enabled
+		the secret key can be recovered from the history log.`,
+		Flags: []gcli.Flag{
+			gcli.StringFlag{
+				Name:  "k",
+				Usage: "[secret key] Secret key of a blockchain signing key, to sign the key rotation with",
+			},
+		},
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			if c.NArg() < 2 {
+				return errorWithHelp(c, errors.New("invalid argument"))
+			}
+
+			seq, err := strconv.ParseUint(c.Args().First(), 10, 64)
+			if err != nil {
+				return errorWithHelp(c, fmt.Errorf("invalid seq: %v", err))
+			}
+
+			pubkeys := make([]cipher.PubKey, 0, c.NArg()-1)
+			for _, s := range c.Args().Tail() {
+				pk, err := cipher.PubKeyFromHex(s)
+				if err != nil {
+					return errorWithHelp(c, fmt.Errorf("invalid pubkey %q: %v", s, err))
+				}
+				pubkeys = append(pubkeys, pk)
+			}
+
+			if c.String("k") == "" {
+				return errorWithHelp(c, errors.New("secret key is required"))
+			}
+
+			seckey, err := cipher.SecKeyFromHex(c.String("k"))
+			if err != nil {
+				return errorWithHelp(c, fmt.Errorf("invalid secret key: %v", err))
+			}
+
+			r, err := coin.NewKeyRotation(seq, pubkeys, seckey)
+			if err != nil {
+				return err
+			}
+
+			return printResult(c, visor.NewReadableKeyRotation(*r), nil)
+		},
+	}
+}
+
+func injectKeyRotationCmd() gcli.Command {
+	name := "injectKeyRotation"
+	return gcli.Command{
+		Name:         name,
+		Usage:        "Add a key rotation created with createKeyRotation to the node and broadcast it",
+		ArgsUsage:    "[key rotation JSON]",
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			if c.NArg() != 1 {
+				return errorWithHelp(c, errors.New("invalid argument"))
+			}
+
+			var r visor.ReadableKeyRotation
+			if err := json.Unmarshal([]byte(c.Args().First()), &r); err != nil {
+				return errorWithHelp(c, fmt.Errorf("invalid key rotation: %v", err))
+			}
+
+			rsp, err := APIClientFromContext(c).InjectKeyRotation(r)
+			if err != nil {
+				return err
+			}
+
+			return printResult(c, rsp, nil)
+		},
+	}
+}
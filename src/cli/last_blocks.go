@@ -30,7 +30,7 @@ func getLastBlocks(c *gcli.Context) error {
 
 	n, err := strconv.ParseUint(num, 10, 64)
 	if err != nil {
-		return fmt.Errorf("invalid block number, %s", err)
+		return usageError{fmt.Errorf("invalid block number, %s", err)}
 	}
 
 	blocks, err := rpcClient.GetLastBlocks(n)
@@ -39,5 +39,5 @@ func getLastBlocks(c *gcli.Context) error {
 		return err
 	}
 
-	return printJSON(blocks)
+	return printResult(c, blocksResult(*blocks), nil)
 }
@@ -1,13 +1,28 @@
 package cli
 
 import (
-	"fmt"
-
 	"github.com/skycoin/skycoin/src/wallet"
 
 	gcli "github.com/urfave/cli"
 )
 
+// addressesResult is printed by the commands that list addresses
+type addressesResult struct {
+	Addresses []string `json:"addresses"`
+}
+
+func (r addressesResult) columns() []string {
+	return []string{"address"}
+}
+
+func (r addressesResult) rows() [][]string {
+	rows := make([][]string, len(r.Addresses))
+	for i, a := range r.Addresses {
+		rows[i] = []string{a}
+	}
+	return rows
+}
+
 func listAddressesCmd() gcli.Command {
 	name := "listAddresses"
 	return gcli.Command{
@@ -36,12 +51,7 @@ func listAddresses(c *gcli.Context) error {
 
 	addrs := wlt.GetAddresses()
 
-	s, err := FormatAddressesAsJSON(addrs)
-	if err != nil {
-		return err
-	}
-
-	fmt.Println(s)
-
-	return nil
+	return printResult(c, addressesResult{
+		Addresses: AddressesToStrings(addrs),
+	}, nil)
 }
@@ -61,5 +61,5 @@ func listWallets(c *gcli.Context) error {
 		}
 	}
 
-	return printJSON(wlts)
+	return printResult(c, wlts, nil)
 }
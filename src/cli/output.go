@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	gcli "github.com/urfave/cli"
+)
+
+// Output formats of the global --output option
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+	outputCSV   = "csv"
+)
+
+var outputFormats = []string{outputJSON, outputTable, outputCSV}
+
+// tabular is implemented by command results that choose their own columns
+// when printed as a table or as CSV
+type tabular interface {
+	columns() []string
+	rows() [][]string
+}
+
+// validateOutputFormat checks the value of the global --output option
+func validateOutputFormat(c *gcli.Context) error {
+	f := c.GlobalString("output")
+	if f == "" {
+		return nil
+	}
+
+	for _, v := range outputFormats {
+		if f == v {
+			return nil
+		}
+	}
+
+	return usageError{fmt.Errorf("invalid output format %q, must be one of %s", f, strings.Join(outputFormats, ", "))}
+}
+
+// outputFormat returns the output format selected with the global --output option,
+// or with the --json option of the command. It returns an empty string if the
+// command's default output is used.
+func outputFormat(c *gcli.Context) string {
+	if f := c.GlobalString("output"); f != "" {
+		return f
+	}
+
+	if c.Bool("json") {
+		return outputJSON
+	}
+
+	return ""
+}
+
+// printResult prints the result of a command in the output format selected with --output.
+// By default, text prints the command's usual output, or the result is printed as JSON if text is nil.
+func printResult(c *gcli.Context, v interface{}, text func()) error {
+	switch f := outputFormat(c); f {
+	case outputJSON:
+		return printJSON(v)
+	case outputTable, outputCSV:
+		return writeOutput(os.Stdout, f, v)
+	}
+
+	if text == nil {
+		return printJSON(v)
+	}
+
+	text()
+	return nil
+}
+
+// SuccessResult is the result of a command that has no other result
+type SuccessResult struct {
+	Success bool `json:"success"`
+}
+
+// printSuccess prints msg, or a SuccessResult with --output
+func printSuccess(c *gcli.Context, msg string) error {
+	return printResult(c, SuccessResult{
+		Success: true,
+	}, func() {
+		fmt.Println(msg)
+	})
+}
+
+// writeOutput writes v to w as a table or as CSV
+func writeOutput(w io.Writer, format string, v interface{}) error {
+	columns, rows, err := tableOf(v)
+	if err != nil {
+		return err
+	}
+
+	if format == outputCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, r := range rows {
+		fmt.Fprintln(tw, strings.Join(r, "\t"))
+	}
+	return tw.Flush()
+}
+
+// tableOf returns the columns and rows of v. Unless v is tabular, the columns are the
+// fields of its JSON representation, in order: a list is printed with a row per item, and
+// any other value in a single row. The keys of nested objects are joined with a dot, and
+// nested lists are printed as JSON.
+func tableOf(v interface{}) ([]string, [][]string, error) {
+	if t, ok := v.(tabular); ok {
+		return t.columns(), t.rows(), nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, ErrJSONMarshal
+	}
+
+	items := []json.RawMessage{b}
+	if jsonKind(b) == '[' {
+		items = nil
+		if err := json.Unmarshal(b, &items); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var columns []string
+	seen := make(map[string]struct{})
+	fields := make([]map[string]string, len(items))
+	for i, item := range items {
+		fields[i] = make(map[string]string)
+		keys, err := flattenJSON("", item, fields[i])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, k := range keys {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				columns = append(columns, k)
+			}
+		}
+	}
+
+	rows := make([][]string, len(fields))
+	for i, f := range fields {
+		rows[i] = make([]string, len(columns))
+		for j, k := range columns {
+			rows[i][j] = f[k]
+		}
+	}
+
+	return columns, rows, nil
+}
+
+// flattenJSON adds the fields of a JSON value to fields, and returns their keys in order
+func flattenJSON(prefix string, v json.RawMessage, fields map[string]string) ([]string, error) {
+	switch jsonKind(v) {
+	case '{':
+		d := json.NewDecoder(bytes.NewReader(v))
+		// Skip the opening brace
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+
+		var keys []string
+		for d.More() {
+			t, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			var vv json.RawMessage
+			if err := d.Decode(&vv); err != nil {
+				return nil, err
+			}
+
+			k := t.(string)
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+
+			kk, err := flattenJSON(k, vv, fields)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, kk...)
+		}
+
+		return keys, nil
+	case '"':
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			return nil, err
+		}
+		fields[columnName(prefix)] = s
+	case 'n':
+		fields[columnName(prefix)] = ""
+	case '[':
+		var b bytes.Buffer
+		if err := json.Compact(&b, v); err != nil {
+			return nil, err
+		}
+		fields[columnName(prefix)] = b.String()
+	default:
+		// Numbers and booleans
+		fields[columnName(prefix)] = string(bytes.TrimSpace(v))
+	}
+
+	return []string{columnName(prefix)}, nil
+}
+
+// jsonKind returns the first character of a JSON value
+func jsonKind(v []byte) byte {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 {
+		return 0
+	}
+	return v[0]
+}
+
+func columnName(prefix string) string {
+	if prefix == "" {
+		return "value"
+	}
+	return prefix
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/api/webrpc"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestTableOf(t *testing.T) {
+	type inner struct {
+		Coins string `json:"coins"`
+		Hours uint64 `json:"hours"`
+	}
+
+	cases := []struct {
+		name    string
+		v       interface{}
+		columns []string
+		rows    [][]string
+	}{
+		{
+			name: "tabular",
+			v: walletHistory{
+				{
+					Txid:      "abc",
+					Address:   "2iNNt6fm9LszSWe51693BeyNUKX34pPaLx",
+					Amount:    "-1.5",
+					Timestamp: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+					Status:    1,
+					Note:      "rent",
+				},
+			},
+			columns: []string{"txid", "address", "amount", "timestamp", "status", "note", "label"},
+			rows: [][]string{
+				{"abc", "2iNNt6fm9LszSWe51693BeyNUKX34pPaLx", "-1.5", "2018-01-02T03:04:05Z", "1", "rent", ""},
+			},
+		},
+		{
+			name: "object",
+			v: struct {
+				Name    string   `json:"name"`
+				Balance inner    `json:"balance"`
+				Tags    []string `json:"tags"`
+				Extra   *inner   `json:"extra"`
+			}{
+				Name: "foo",
+				Balance: inner{
+					Coins: "1.000000",
+					Hours: 12,
+				},
+				Tags: []string{"a", "b"},
+			},
+			columns: []string{"name", "balance.coins", "balance.hours", "tags", "extra"},
+			rows: [][]string{
+				{"foo", "1.000000", "12", `["a","b"]`, ""},
+			},
+		},
+		{
+			name: "list",
+			v: []map[string]interface{}{
+				{"b": 1},
+				{"a": true},
+			},
+			columns: []string{"b", "a"},
+			rows: [][]string{
+				{"1", ""},
+				{"", "true"},
+			},
+		},
+		{
+			name:    "value",
+			v:       "foo",
+			columns: []string{"value"},
+			rows:    [][]string{{"foo"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			columns, rows, err := tableOf(tc.v)
+			require.NoError(t, err)
+			require.Equal(t, tc.columns, columns)
+			require.Equal(t, tc.rows, rows)
+		})
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	v := addressesResult{
+		Addresses: []string{"2iNNt6fm9LszSWe51693BeyNUKX34pPaLx", "a,b"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeOutput(&buf, outputCSV, v))
+	require.Equal(t, "address\n2iNNt6fm9LszSWe51693BeyNUKX34pPaLx\n\"a,b\"\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeOutput(&buf, outputTable, SuccessResult{
+		Success: true,
+	}))
+	require.Equal(t, "SUCCESS\ntrue\n", buf.String())
+}
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{nil, 0},
+		{errors.New("foo"), ExitCodeError},
+		{usageError{errors.New("foo")}, ExitCodeUsage},
+		{ErrWalletName, ExitCodeUsage},
+		{gcli.NewExitError("", ExitCodeWallet), ExitCodeWallet},
+		{WalletLoadError{errors.New("foo")}, ExitCodeWallet},
+		{WalletSaveError{errors.New("foo")}, ExitCodeWallet},
+		{wallet.ErrInvalidPassword, ExitCodeWallet},
+		{webrpc.ClientError{StatusCode: 403}, ExitCodeNode},
+		{&webrpc.RPCError{Code: -32603}, ExitCodeNode},
+		{&url.Error{Op: "Post", URL: "http://127.0.0.1:6420", Err: errors.New("connection refused")}, ExitCodeNodeUnreachable},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.code, ExitCode(tc.err), "%v", tc.err)
+	}
+}
+
+func TestExitError(t *testing.T) {
+	app := gcli.NewApp()
+	app.Flags = []gcli.Flag{
+		gcli.StringFlag{
+			Name: "output",
+		},
+	}
+
+	var msg string
+	app.Action = func(c *gcli.Context) error {
+		msg = exitError(c, errors.New("missing password"), ExitCodeWallet).Error()
+		return nil
+	}
+
+	require.NoError(t, app.Run([]string{"skycoin-cli"}))
+	require.Equal(t, "missing password", msg)
+
+	require.NoError(t, app.Run([]string{"skycoin-cli", "--output", "json"}))
+	require.Equal(t, `{"error":{"code":3,"class":"wallet","message":"missing password"}}`, msg)
+}
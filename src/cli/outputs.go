@@ -60,7 +60,7 @@ func getWalletOutputsCmd(c *gcli.Context) error {
 		return err
 	}
 
-	return printJSON(outputs)
+	return printResult(c, outputs, nil)
 }
 
 func getAddressOutputsCmd(c *gcli.Context) error {
@@ -80,7 +80,7 @@ func getAddressOutputsCmd(c *gcli.Context) error {
 		return err
 	}
 
-	return printJSON(outputs)
+	return printResult(c, outputs, nil)
 }
 
 // PUBLIC
@@ -57,8 +57,7 @@ func sendCmd() gcli.Command {
 
 			rawtx, err := createRawTxCmdHandler(c)
 			if err != nil {
-				errorWithHelp(c, err)
-				return nil
+				return errorWithHelp(c, err)
 			}
 
 			txid, err := rpcClient.InjectTransaction(rawtx)
@@ -66,17 +65,13 @@ func sendCmd() gcli.Command {
 				return err
 			}
 
-			jsonFmt := c.Bool("json")
-			if jsonFmt {
-				return printJSON(struct {
-					Txid string `json:"txid"`
-				}{
-					Txid: txid,
-				})
-			}
-
-			fmt.Printf("txid:%s\n", txid)
-			return nil
+			return printResult(c, struct {
+				Txid string `json:"txid"`
+			}{
+				Txid: txid,
+			}, func() {
+				fmt.Printf("txid:%s\n", txid)
+			})
 		},
 	}
 }
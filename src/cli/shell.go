@@ -0,0 +1,521 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gcli "github.com/urfave/cli"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+const (
+	shellPrompt = "> "
+	// defaultSessionTimeout is how long a wallet stays unlocked in a shell or script
+	// after its password was last used
+	defaultSessionTimeout = 5 * time.Minute
+)
+
+// Commands of the shell that are not commands of the app
+var shellBuiltins = []string{"exit", "quit", "lock", "history"}
+
+func shellCmd() gcli.Command {
+	name := "shell"
+	return gcli.Command{
+		Name:  name,
+		Usage: "Start an interactive shell that runs CLI commands",
+		Description: fmt.Sprintf(`Runs the CLI commands entered on each line, with the same arguments as on the command line.
+        The up and down keys browse the command history and tab completes command and option names.
+
+        A wallet password entered in the shell unlocks the wallet for the following commands,
+        until the wallet is not used for the time set by --session-timeout (default %s).
+
+        Shell commands:
+            history    Print the command history
+            lock       Forget the passwords of the unlocked wallets
+            exit, quit Leave the shell`, defaultSessionTimeout),
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			if _, ok := c.App.Metadata["shell"]; ok {
+				return errors.New("already in a shell")
+			}
+
+			s := newShell(c.App, c.GlobalDuration("session-timeout"))
+			defer s.close()
+
+			return s.runInteractive(os.Stdin, os.Stdout)
+		},
+	}
+}
+
+// runScript runs the commands of a script file and stops on the first command that fails
+func runScript(c *gcli.Context, path string) error {
+	if _, ok := c.App.Metadata["shell"]; ok {
+		return errors.New("already in a shell")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := newShell(c.App, c.GlobalDuration("session-timeout"))
+	defer s.close()
+
+	if err := s.runScript(f, path); err != nil {
+		// The CLI exits with the exit code of the command that failed
+		code := s.exitCode
+		if code == 0 {
+			code = ExitCode(err)
+		}
+		return gcli.NewExitError(err.Error(), code)
+	}
+
+	return nil
+}
+
+// shell runs the app's commands read from a terminal or a script, in the same app,
+// so that the config, the API clients and the unlocked wallets are shared by the commands
+type shell struct {
+	app      *gcli.App
+	sessions *walletSessions
+	history  []string
+
+	// Set when the running command exits with an exit code
+	failed   bool
+	exitCode int
+	// gcli.OsExiter of the app, restored when the shell is closed
+	osExiter func(int)
+}
+
+func newShell(app *gcli.App, sessionTimeout time.Duration) *shell {
+	s := &shell{
+		app:      app,
+		sessions: newWalletSessions(sessionTimeout),
+		osExiter: gcli.OsExiter,
+	}
+
+	app.Metadata["shell"] = s
+	app.Metadata["sessions"] = s.sessions
+
+	// A command that fails or isn't found must not exit the shell
+	gcli.OsExiter = func(code int) {
+		s.failed = true
+		s.exitCode = code
+	}
+
+	return s
+}
+
+func (s *shell) close() {
+	s.sessions.forgetAll()
+	delete(s.app.Metadata, "shell")
+	delete(s.app.Metadata, "sessions")
+	gcli.OsExiter = s.osExiter
+}
+
+// runInteractive reads commands from in until exit, quit or EOF.
+// If in is a terminal, it supports history and tab completion.
+func (s *shell) runInteractive(in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	if !terminal.IsTerminal(fd) {
+		scanner := bufio.NewScanner(in)
+		if err := s.run(scannerLines(scanner), out, false); err != nil {
+			return err
+		}
+		return scanner.Err()
+	}
+
+	t := terminal.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{in, out}, shellPrompt)
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return completeLine(s.app.Commands, line, pos)
+	}
+
+	var readErr error
+	next := func() (string, bool) {
+		// The terminal is raw only while a line is read, so that the commands can prompt for passwords
+		state, err := terminal.MakeRaw(fd)
+		if err != nil {
+			readErr = err
+			return "", false
+		}
+		defer terminal.Restore(fd, state) // nolint: errcheck
+
+		var line string
+		line, readErr = t.ReadLine()
+		return line, readErr == nil
+	}
+
+	if err := s.run(next, out, false); err != nil {
+		return err
+	}
+	if readErr != io.EOF {
+		return readErr
+	}
+	return nil
+}
+
+// runScript runs the commands read from r and stops on the first command that fails
+func (s *shell) runScript(r io.Reader, name string) error {
+	scanner := bufio.NewScanner(r)
+	lines := scannerLines(scanner)
+	n := 0
+	next := func() (string, bool) {
+		n++
+		return lines()
+	}
+
+	if err := s.run(next, os.Stdout, true); err != nil {
+		return fmt.Errorf("%s:%d: %v", name, n, err)
+	}
+
+	return scanner.Err()
+}
+
+// scannerLines returns a function that returns the lines of a bufio.Scanner
+func scannerLines(scanner *bufio.Scanner) func() (string, bool) {
+	return func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		return scanner.Text(), true
+	}
+}
+
+// run runs the commands of the lines returned by next, until next returns false or a line ends the shell.
+// Blank lines and lines starting with # are skipped.
+func (s *shell) run(next func() (string, bool), out io.Writer, stopOnError bool) error {
+	for {
+		line, ok := next()
+		if !ok {
+			return nil
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		done, err := s.runLine(line, out)
+		if err != nil {
+			if stopOnError {
+				return err
+			}
+			fmt.Fprintf(out, "Error: %v\n", err)
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// runLine runs the command of a line. It returns true if the line ends the shell.
+func (s *shell) runLine(line string, out io.Writer) (bool, error) {
+	args, err := splitCommandLine(line)
+	if err != nil {
+		return false, err
+	}
+
+	s.history = append(s.history, line)
+
+	switch args[0] {
+	case "exit", "quit":
+		return true, nil
+	case "lock":
+		s.sessions.forgetAll()
+		return false, nil
+	case "history":
+		for i, l := range s.history {
+			fmt.Fprintf(out, "%5d  %s\n", i+1, l)
+		}
+		return false, nil
+	case "shell":
+		return false, errors.New("already in a shell")
+	}
+
+	s.failed = false
+	s.exitCode = 0
+	err = s.app.Run(append([]string{s.app.Name}, args...))
+
+	// The error of a command that exits with an exit code was already printed
+	if s.failed {
+		return false, fmt.Errorf("%s failed", args[0])
+	}
+
+	return false, err
+}
+
+// commandFailed records that the command of c failed, if it runs in a shell.
+// It is called by the commands that report an error without returning it.
+func commandFailed(c *gcli.Context) {
+	if s, ok := c.App.Metadata["shell"].(*shell); ok {
+		s.failed = true
+	}
+}
+
+// splitCommandLine splits a line into arguments separated by spaces.
+// Single and double quotes group words into one argument, and a backslash escapes the next character.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var arg []rune
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg = append(arg, r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg = append(arg, r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, string(arg))
+				arg = arg[:0]
+				inArg = false
+			}
+		default:
+			arg = append(arg, r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inArg {
+		args = append(args, string(arg))
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+
+	return args, nil
+}
+
+// completeLine completes the word before the cursor with the names of the commands,
+// or with the option names of the line's command if the word starts with "-".
+// The word is completed to the longest prefix shared by the matching names.
+func completeLine(commands []gcli.Command, line string, pos int) (string, int, bool) {
+	if pos != len(line) {
+		return "", 0, false
+	}
+
+	start := strings.LastIndexAny(line, " \t") + 1
+	word := line[start:]
+
+	var names []string
+	if strings.TrimSpace(line[:start]) == "" {
+		for _, cmd := range commands {
+			names = append(names, cmd.Names()...)
+		}
+		names = append(names, shellBuiltins...)
+	} else if strings.HasPrefix(word, "-") {
+		cmdName := strings.Fields(line)[0]
+		for _, cmd := range commands {
+			if !cmd.HasName(cmdName) {
+				continue
+			}
+			for _, f := range cmd.Flags {
+				for _, n := range strings.Split(f.GetName(), ",") {
+					n = strings.TrimSpace(n)
+					if len(n) == 1 {
+						names = append(names, "-"+n)
+					} else {
+						names = append(names, "--"+n)
+					}
+				}
+			}
+		}
+	}
+
+	var matches []string
+	for _, n := range names {
+		if strings.HasPrefix(n, word) {
+			matches = append(matches, n)
+		}
+	}
+
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	sort.Strings(matches)
+	completion := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, completion) {
+			completion = completion[:len(completion)-1]
+		}
+	}
+
+	if len(matches) == 1 {
+		completion += " "
+	}
+
+	if completion == word {
+		return "", 0, false
+	}
+
+	newLine := line[:start] + completion
+	return newLine, len(newLine), true
+}
+
+// walletSessions caches the passwords of the wallets unlocked in a shell or script.
+// A password is forgotten when it was not used for the timeout.
+type walletSessions struct {
+	timeout   time.Duration
+	now       func() time.Time
+	lock      sync.Mutex
+	passwords map[string]walletSession
+}
+
+type walletSession struct {
+	password []byte
+	lastUsed time.Time
+}
+
+func newWalletSessions(timeout time.Duration) *walletSessions {
+	return &walletSessions{
+		timeout:   timeout,
+		now:       time.Now,
+		passwords: make(map[string]walletSession),
+	}
+}
+
+// get returns the password of an unlocked wallet
+func (s *walletSessions) get(walletFile string) ([]byte, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ws, ok := s.passwords[walletFile]
+	if !ok {
+		return nil, false
+	}
+
+	now := s.now()
+	if now.Sub(ws.lastUsed) >= s.timeout {
+		delete(s.passwords, walletFile)
+		return nil, false
+	}
+
+	ws.lastUsed = now
+	s.passwords[walletFile] = ws
+	return ws.password, true
+}
+
+// set unlocks a wallet with its password. Nothing is cached if the timeout is 0.
+func (s *walletSessions) set(walletFile string, password []byte) {
+	if s.timeout <= 0 {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.passwords[walletFile] = walletSession{
+		password: password,
+		lastUsed: s.now(),
+	}
+}
+
+// forget locks a wallet
+func (s *walletSessions) forget(walletFile string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.passwords, walletFile)
+}
+
+// forgetAll locks all wallets
+func (s *walletSessions) forgetAll() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.passwords = make(map[string]walletSession)
+}
+
+// passwordFromSession is a PasswordReader that returns the password of a wallet unlocked in the session,
+// or reads the password from the terminal. A password read from the terminal unlocks the wallet
+// for the session if it decrypts the wallet.
+type passwordFromSession struct {
+	sessions   *walletSessions
+	walletFile string
+}
+
+// Password implements the PasswordReader's Password method
+func (p passwordFromSession) Password() ([]byte, error) {
+	if password, ok := p.sessions.get(p.walletFile); ok {
+		return password, nil
+	}
+
+	password, err := readPasswordFromTerminal("")
+	if err != nil {
+		return nil, err
+	}
+
+	// If the wallet can't be loaded or decrypted, the caller reports the error
+	wlt, err := wallet.Load(p.walletFile)
+	if err == nil && wlt.IsEncrypted() {
+		if err := wlt.GuardView(password, func(*wallet.Wallet) error { return nil }); err == nil {
+			p.sessions.set(p.walletFile, password)
+		}
+	}
+
+	return password, nil
+}
+
+// newWalletPasswordReader creates a PasswordReader for the password of an existing wallet.
+// The password is read from the -p flag if set. Otherwise, in a shell or script, the password
+// of the unlocked wallet is used, or else the password is read from the terminal.
+func newWalletPasswordReader(c *gcli.Context, walletFile string) PasswordReader {
+	p := []byte(c.String("p"))
+	if len(p) != 0 {
+		return PasswordFromBytes(p)
+	}
+
+	if sessions, ok := c.App.Metadata["sessions"].(*walletSessions); ok {
+		return passwordFromSession{
+			sessions:   sessions,
+			walletFile: walletFile,
+		}
+	}
+
+	return NewPasswordReader(p)
+}
+
+// lockWallet forgets the password of a wallet unlocked in a shell or script,
+// after the wallet's password was changed or removed
+func lockWallet(c *gcli.Context, walletFile string) {
+	if sessions, ok := c.App.Metadata["sessions"].(*walletSessions); ok {
+		sessions.forget(walletFile)
+	}
+}
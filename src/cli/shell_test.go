@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	gcli "github.com/urfave/cli"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	cases := []struct {
+		line string
+		args []string
+		err  string
+	}{
+		{
+			line: "status",
+			args: []string{"status"},
+		},
+		{
+			line: "  send  -f foo.wlt\t2iNNt6fm9LszSWe51693BeyNUKX34pPaLx 1 ",
+			args: []string{"send", "-f", "foo.wlt", "2iNNt6fm9LszSWe51693BeyNUKX34pPaLx", "1"},
+		},
+		{
+			line: `setNote -n "paid the \"rent\"" 'it''s' a\ b ""`,
+			args: []string{"setNote", "-n", `paid the "rent"`, "its", "a b", ""},
+		},
+		{
+			line: `setNote 'a\b'`,
+			args: []string{"setNote", `a\b`},
+		},
+		{
+			line: `setNote "foo`,
+			err:  "unterminated quote",
+		},
+		{
+			line: `setNote foo\`,
+			err:  "trailing backslash",
+		},
+		{
+			line: "   ",
+			err:  "empty command",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.line, func(t *testing.T) {
+			args, err := splitCommandLine(tc.line)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestCompleteLine(t *testing.T) {
+	commands := []gcli.Command{
+		{
+			Name: "walletBalance",
+		},
+		{
+			Name: "walletHistory",
+		},
+		{
+			Name: "status",
+			Flags: []gcli.Flag{
+				gcli.BoolFlag{
+					Name: "json,j",
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		line    string
+		newLine string
+		ok      bool
+	}{
+		{
+			line:    "sta",
+			newLine: "status ",
+			ok:      true,
+		},
+		{
+			line:    "wal",
+			newLine: "wallet",
+			ok:      true,
+		},
+		{
+			line: "wallet",
+		},
+		{
+			line:    "  ex",
+			newLine: "  exit ",
+			ok:      true,
+		},
+		{
+			line:    "status --j",
+			newLine: "status --json ",
+			ok:      true,
+		},
+		{
+			line:    "status -",
+			newLine: "status -",
+		},
+		{
+			line: "status foo",
+		},
+		{
+			line: "foo",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.line, func(t *testing.T) {
+			newLine, pos, ok := completeLine(commands, tc.line, len(tc.line))
+			require.Equal(t, tc.ok, ok)
+			if !ok {
+				return
+			}
+			require.Equal(t, tc.newLine, newLine)
+			require.Equal(t, len(newLine), pos)
+		})
+	}
+
+	// Only the word at the end of the line is completed
+	_, _, ok := completeLine(commands, "sta", 1)
+	require.False(t, ok)
+}
+
+func TestWalletSessions(t *testing.T) {
+	now := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := newWalletSessions(time.Minute)
+	s.now = func() time.Time {
+		return now
+	}
+
+	_, ok := s.get("foo.wlt")
+	require.False(t, ok)
+
+	s.set("foo.wlt", []byte("pwd"))
+	s.set("bar.wlt", []byte("pwd2"))
+
+	// Using a wallet extends its session
+	now = now.Add(50 * time.Second)
+	p, ok := s.get("foo.wlt")
+	require.True(t, ok)
+	require.Equal(t, []byte("pwd"), p)
+
+	now = now.Add(50 * time.Second)
+	p, ok = s.get("foo.wlt")
+	require.True(t, ok)
+	require.Equal(t, []byte("pwd"), p)
+
+	_, ok = s.get("bar.wlt")
+	require.False(t, ok)
+
+	s.forget("foo.wlt")
+	_, ok = s.get("foo.wlt")
+	require.False(t, ok)
+
+	s.set("foo.wlt", []byte("pwd"))
+	s.forgetAll()
+	_, ok = s.get("foo.wlt")
+	require.False(t, ok)
+
+	// Nothing is cached without a timeout
+	s = newWalletSessions(0)
+	s.set("foo.wlt", []byte("pwd"))
+	_, ok = s.get("foo.wlt")
+	require.False(t, ok)
+}
+
+func TestShellRunScript(t *testing.T) {
+	app, err := NewApp(Config{
+		Coin:       defaultCoin,
+		RPCAddress: defaultRPCAddress,
+	})
+	require.NoError(t, err)
+
+	var exited bool
+	osExiter := gcli.OsExiter
+	gcli.OsExiter = func(int) {
+		exited = true
+	}
+	defer func() {
+		gcli.OsExiter = osExiter
+	}()
+
+	var out bytes.Buffer
+	app.Writer = &out
+
+	s := newShell(&app.App, time.Minute)
+	err = s.runScript(strings.NewReader(`
+# Runbook
+version -j
+
+history
+fooCommand
+version
+`), "runbook")
+	s.close()
+
+	require.EqualError(t, err, "runbook:6: fooCommand failed")
+	require.Equal(t, []string{"version -j", "history", "fooCommand"}, s.history)
+	require.Contains(t, out.String(), "'fooCommand' is not a")
+	_, ok := app.Metadata["shell"]
+	require.False(t, ok)
+
+	// The shell doesn't exit, and the OsExiter is restored when the shell is closed
+	require.False(t, exited)
+	gcli.OsExiter(1)
+	require.True(t, exited)
+
+	s = newShell(&app.App, time.Minute)
+	defer s.close()
+	err = s.runScript(strings.NewReader("version\nlock\nshell\nversion\n"), "runbook")
+	require.EqualError(t, err, "runbook:3: already in a shell")
+
+	err = s.runScript(strings.NewReader("version\nexit\nfooCommand\n"), "runbook")
+	require.NoError(t, err)
+}
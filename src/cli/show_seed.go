@@ -40,30 +40,25 @@ func showSeedCmd(cfg Config) gcli.Command {
 				return err
 			}
 
-			pr := NewPasswordReader([]byte(c.String("p")))
+			pr := newWalletPasswordReader(c, w)
 			seed, err := getSeed(w, pr)
 			switch err.(type) {
 			case nil:
 			case WalletLoadError:
-				errorWithHelp(c, err)
-				return nil
+				return errorWithHelp(c, err)
 			default:
 				return err
 			}
 
-			if c.Bool("j") {
-				v := struct {
-					Seed string `json:"seed"`
-				}{
-					Seed: seed,
-				}
-
-				printJSON(v)
-				return nil
+			v := struct {
+				Seed string `json:"seed"`
+			}{
+				Seed: seed,
 			}
 
-			fmt.Println(seed)
-			return nil
+			return printResult(c, v, func() {
+				fmt.Println(seed)
+			})
 		},
 	}
 }
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"strconv"
+
 	gcli "github.com/urfave/cli"
 
 	"github.com/skycoin/skycoin/src/api/webrpc"
@@ -13,6 +15,21 @@ type StatusResult struct {
 	UseCSRF    bool   `json:"use_csrf"`
 }
 
+func (s StatusResult) columns() []string {
+	return []string{"running", "num_of_blocks", "hash_of_last_block", "time_since_last_block", "webrpc_address", "use_csrf"}
+}
+
+func (s StatusResult) rows() [][]string {
+	return [][]string{{
+		strconv.FormatBool(s.Running),
+		strconv.FormatUint(s.BlockNum, 10),
+		s.LastBlockHash,
+		s.TimeSinceLastBlock,
+		s.RPCAddress,
+		strconv.FormatBool(s.UseCSRF),
+	}}
+}
+
 func statusCmd() gcli.Command {
 	name := "status"
 	return gcli.Command{
@@ -29,11 +46,11 @@ func statusCmd() gcli.Command {
 
 			cfg := ConfigFromContext(c)
 
-			return printJSON(StatusResult{
+			return printResult(c, StatusResult{
 				StatusResult: *status,
 				RPCAddress:   cfg.RPCAddress,
 				UseCSRF:      cfg.UseCSRF,
-			})
+			}, nil)
 		},
 	}
 }
@@ -47,7 +64,7 @@ func showConfigCmd() gcli.Command {
 		OnUsageError: onCommandUsageError(name),
 		Action: func(c *gcli.Context) error {
 			cfg := ConfigFromContext(c)
-			return printJSON(cfg)
+			return printResult(c, cfg, nil)
 		},
 	}
 }
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -22,13 +23,13 @@ func transactionCmd() gcli.Command {
 		Action: func(c *gcli.Context) error {
 			txid := c.Args().First()
 			if txid == "" {
-				return errors.New("txid is empty")
+				return usageError{errors.New("txid is empty")}
 			}
 
 			// validate the txid
 			_, err := cipher.SHA256FromHex(txid)
 			if err != nil {
-				return errors.New("invalid txid")
+				return usageError{errors.New("invalid txid")}
 			}
 
 			rpcClient := RPCClientFromContext(c)
@@ -38,7 +39,7 @@ func transactionCmd() gcli.Command {
 				return err
 			}
 
-			return printJSON(tx)
+			return printResult(c, tx, nil)
 		},
 	}
 }
@@ -53,30 +54,27 @@ func decodeRawTxCmd() gcli.Command {
 		Action: func(c *gcli.Context) error {
 			rawTxStr := c.Args().First()
 			if rawTxStr == "" {
-				errorWithHelp(c, errors.New("missing raw transaction value"))
-				return nil
+				return errorWithHelp(c, errors.New("missing raw transaction value"))
 			}
 
 			b, err := hex.DecodeString(rawTxStr)
 			if err != nil {
-				fmt.Printf("invalid raw transaction: %v\n", err)
-				return err
+				return usageError{fmt.Errorf("invalid raw transaction: %v", err)}
 			}
 
 			tx, err := coin.TransactionDeserialize(b)
 			if err != nil {
-				fmt.Printf("Unable to deserialize transaction bytes: %v\n", err)
-				return err
+				return usageError{fmt.Errorf("Unable to deserialize transaction bytes: %v", err)}
 			}
 
 			txStr, err := visor.TransactionToJSON(tx)
 			if err != nil {
-				fmt.Println(err)
 				return err
 			}
 
-			fmt.Println(txStr)
-			return nil
+			return printResult(c, json.RawMessage(txStr), func() {
+				fmt.Println(txStr)
+			})
 		},
 	}
 }
@@ -33,18 +33,13 @@ func versionCmd() gcli.Command {
 				Version,
 			}
 
-			jsonFmt := c.Bool("json")
-			if jsonFmt {
-				return printJSON(ver)
-			}
-
-			v := reflect.ValueOf(ver)
-			t := reflect.TypeOf(ver)
-			for i := 0; i < v.NumField(); i++ {
-				fmt.Printf("%s:%v\n", t.Field(i).Tag.Get("json"), v.Field(i).Interface())
-			}
-
-			return nil
+			return printResult(c, ver, func() {
+				v := reflect.ValueOf(ver)
+				t := reflect.TypeOf(ver)
+				for i := 0; i < v.NumField(); i++ {
+					fmt.Printf("%s:%v\n", t.Field(i).Tag.Get("json"), v.Field(i).Interface())
+				}
+			})
 		},
 	}
 	// Commands = append(Commands, cmd)
@@ -21,17 +21,13 @@ func walletDirCmd() gcli.Command {
 		},
 		Action: func(c *gcli.Context) error {
 			cfg := ConfigFromContext(c)
-			jsonFmt := c.Bool("json")
-			if jsonFmt {
-				return printJSON(struct {
-					WltDir string `json:"walletDir"`
-				}{
-					WltDir: cfg.WalletDir,
-				})
-			}
-
-			fmt.Println(cfg.WalletDir)
-			return nil
+			return printResult(c, struct {
+				WltDir string `json:"walletDir"`
+			}{
+				WltDir: cfg.WalletDir,
+			}, func() {
+				fmt.Println(cfg.WalletDir)
+			})
 		},
 	}
 	// Commands = append(Commands, cmd)
@@ -3,10 +3,9 @@ package cli
 import (
 	"errors"
 	"fmt"
-
-	"time"
-
 	"sort"
+	"strconv"
+	"time"
 
 	gcli "github.com/urfave/cli"
 
@@ -23,6 +22,8 @@ type AddrHistory struct {
 	Amount    string    `json:"amount"`
 	Timestamp time.Time `json:"timestamp"`
 	Status    int       `json:"status"`
+	Note      string    `json:"note,omitempty"`
+	Label     string    `json:"label,omitempty"`
 
 	coins uint64
 }
@@ -41,18 +42,49 @@ func (obt byTime) Len() int {
 	return len(obt)
 }
 
+// walletHistory is printed by the walletHistory command, with a row per event in a table
+type walletHistory []AddrHistory
+
+func (h walletHistory) columns() []string {
+	return []string{"txid", "address", "amount", "timestamp", "status", "note", "label"}
+}
+
+func (h walletHistory) rows() [][]string {
+	rows := make([][]string, len(h))
+	for i, his := range h {
+		rows[i] = []string{
+			his.Txid,
+			his.Address,
+			his.Amount,
+			his.Timestamp.Format(time.RFC3339),
+			strconv.Itoa(his.Status),
+			his.Note,
+			his.Label,
+		}
+	}
+	return rows
+}
+
 func walletHisCmd() gcli.Command {
 	name := "walletHistory"
 	return gcli.Command{
-		Name:         name,
-		Usage:        "Display the transaction history of specific wallet. Requires skycoin node rpc.",
-		ArgsUsage:    " ",
+		Name:      name,
+		Usage:     "Display the transaction history of specific wallet. Requires skycoin node rpc.",
+		ArgsUsage: " ",
+		Description: `
+		The transaction notes and address labels of the wallet are included in the history.
+		The notes and labels of an encrypted wallet are only included if the "-p" option
+		is given.`,
 		OnUsageError: onCommandUsageError(name),
 		Flags: []gcli.Flag{
 			gcli.StringFlag{
 				Name:  "f",
 				Usage: "[wallet file or path] From wallet. If no path is specified your default wallet path will be used.",
 			},
+			gcli.StringFlag{
+				Name:  "p",
+				Usage: "[password] Wallet password, to include the notes and labels of an encrypted wallet",
+			},
 		},
 		Action: walletHistoryAction,
 	}
@@ -63,9 +95,7 @@ func walletHistoryAction(c *gcli.Context) error {
 	rpcClient := RPCClientFromContext(c)
 
 	if c.NArg() > 0 {
-		fmt.Printf("Error: invalid argument\n\n")
-		gcli.ShowSubcommandHelp(c)
-		return nil
+		return errorWithHelp(c, errors.New("invalid argument"))
 	}
 
 	w, err := resolveWalletPath(cfg, c.String("f"))
@@ -101,8 +131,21 @@ func walletHistoryAction(c *gcli.Context) error {
 
 	sort.Sort(byTime(totalAddrHis))
 
+	// add the transaction notes and address labels
+	m, err := getHistoryMetadata(w, []byte(c.String("p")))
+	if err != nil {
+		return err
+	}
+
+	if m != nil {
+		for i, his := range totalAddrHis {
+			totalAddrHis[i].Note = m.Notes[his.Txid]
+			totalAddrHis[i].Label = m.Labels[his.Address]
+		}
+	}
+
 	// print the addr history
-	return printJSON(totalAddrHis)
+	return printResult(c, walletHistory(totalAddrHis), nil)
 }
 
 func makeAddrHisArray(c *webrpc.Client, ux webrpc.AddrUxoutResult) ([]AddrHistory, error) {
@@ -244,6 +287,25 @@ func createBlkTimeFinder(c *webrpc.Client, ss []uint64) (func(uint64) int64, err
 	}, nil
 }
 
+// getHistoryMetadata returns the metadata of the wallet, or nil if the wallet is encrypted
+// and no password was given
+func getHistoryMetadata(f string, password []byte) (*wallet.Metadata, error) {
+	wlt, err := wallet.Load(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if wlt.IsEncrypted() && len(password) == 0 {
+		return nil, nil
+	}
+
+	if !wlt.IsEncrypted() {
+		password = nil
+	}
+
+	return wlt.ViewMetadata(password)
+}
+
 func getAddresses(f string) ([]string, error) {
 	wlt, err := wallet.Load(f)
 	if err != nil {
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+// ledgerDateLayout is the layout of a date without a time in the --start and --end options
+const ledgerDateLayout = "2006-01-02"
+
+// walletLedger is printed by the walletLedger command, with the same columns as the CSV ledger of the API
+type walletLedger api.LedgerResponse
+
+func (l walletLedger) columns() []string {
+	return api.LedgerColumns
+}
+
+func (l walletLedger) rows() [][]string {
+	rows := make([][]string, len(l.Entries))
+	for i, e := range l.Entries {
+		rows[i] = api.LedgerRow(e)
+	}
+	return rows
+}
+
+func walletLedgerCmd() gcli.Command {
+	name := "walletLedger"
+	return gcli.Command{
+		Name:      name,
+		Usage:     "Export the transaction ledger of a wallet for accounting. Requires skycoin node API.",
+		ArgsUsage: " ",
+		Description: `
+		The ledger has an entry per incoming, outgoing and self transfer transaction
+		of the wallet, confirmed or unconfirmed, with its net coins and hours, the hours
+		it burned, its counterparty addresses, its confirmations and its note.
+
+		The "--start" and "--end" options take a date, such as 2018-01-31, or a time in
+		RFC3339 format, such as 2018-01-31T12:00:00Z. Dates are in UTC, and the end date
+		includes the whole day.
+
+		Use "--output csv" to export the ledger as CSV. The notes of an encrypted wallet
+		are only included if the "-p" option is given.`,
+		OnUsageError: onCommandUsageError(name),
+		Flags: []gcli.Flag{
+			gcli.StringFlag{
+				Name:  "f",
+				Usage: "[wallet file or path] From wallet. If no path is specified your default wallet path will be used.",
+			},
+			gcli.StringFlag{
+				Name:  "p",
+				Usage: "[password] Wallet password, to include the notes of an encrypted wallet",
+			},
+			gcli.StringFlag{
+				Name:  "start",
+				Usage: "[date] Only include transactions on or after this date",
+			},
+			gcli.StringFlag{
+				Name:  "end",
+				Usage: "[date] Only include transactions on or before this date",
+			},
+		},
+		Action: walletLedgerAction,
+	}
+}
+
+func walletLedgerAction(c *gcli.Context) error {
+	cfg := ConfigFromContext(c)
+
+	if c.NArg() > 0 {
+		return errorWithHelp(c, errors.New("invalid argument"))
+	}
+
+	start, err := parseLedgerTime(c.String("start"), false)
+	if err != nil {
+		return errorWithHelp(c, fmt.Errorf("invalid start: %v", err))
+	}
+
+	end, err := parseLedgerTime(c.String("end"), true)
+	if err != nil {
+		return errorWithHelp(c, fmt.Errorf("invalid end: %v", err))
+	}
+
+	if end != 0 && end < start {
+		return errorWithHelp(c, errors.New("end must not be before start"))
+	}
+
+	w, err := resolveWalletPath(cfg, c.String("f"))
+	if err != nil {
+		return err
+	}
+
+	addrs, err := getAddresses(w)
+	if err != nil {
+		return err
+	}
+
+	if len(addrs) == 0 {
+		return errors.New("Wallet is empty")
+	}
+
+	ledger, err := APIClientFromContext(c).Ledger(addrs, start, end)
+	if err != nil {
+		return err
+	}
+
+	m, err := getHistoryMetadata(w, []byte(c.String("p")))
+	if err != nil {
+		return err
+	}
+
+	if m != nil {
+		for i, e := range ledger.Entries {
+			ledger.Entries[i].Note = m.Notes[e.Txid]
+		}
+	}
+
+	return printResult(c, walletLedger(*ledger), nil)
+}
+
+// parseLedgerTime parses a date or an RFC3339 time into a unix timestamp. If end is true,
+// a date is parsed as the last second of the day. An empty string is parsed as 0, no bound.
+func parseLedgerTime(s string, end bool) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	if t, err := time.Parse(ledgerDateLayout, s); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		return unixTime(t)
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a date such as 2018-01-31 or an RFC3339 time", s)
+	}
+
+	return unixTime(t)
+}
+
+func unixTime(t time.Time) (uint64, error) {
+	if t.Unix() <= 0 {
+		return 0, errors.New("time must be after 1970-01-01T00:00:00Z")
+	}
+	return uint64(t.Unix()), nil
+}
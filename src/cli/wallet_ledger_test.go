@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestParseLedgerTime(t *testing.T) {
+	cases := []struct {
+		s   string
+		end bool
+		t   uint64
+		err string
+	}{
+		{"", false, 0, ""},
+		{"", true, 0, ""},
+		{"2018-01-31", false, 1517356800, ""},
+		{"2018-01-31", true, 1517443199, ""},
+		{"2018-01-31T12:00:00Z", false, 1517400000, ""},
+		{"2018-01-31T12:00:00+01:00", true, 1517396400, ""},
+		{"31/01/2018", false, 0, `"31/01/2018" is not a date such as 2018-01-31 or an RFC3339 time`},
+		{"1960-01-01", false, 0, "time must be after 1970-01-01T00:00:00Z"},
+	}
+
+	for _, tc := range cases {
+		ts, err := parseLedgerTime(tc.s, tc.end)
+		if tc.err != "" {
+			require.EqualError(t, err, tc.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tc.t, ts, tc.s)
+	}
+}
+
+func TestWalletLedgerRows(t *testing.T) {
+	l := walletLedger{
+		Entries: []visor.ReadableLedgerEntry{
+			{
+				Txid:           "abc",
+				Type:           visor.LedgerIncoming,
+				Timestamp:      1517400000,
+				Confirmed:      true,
+				Confirmations:  4,
+				BlockSeq:       12,
+				NetCoins:       "1.500000",
+				NetHours:       3,
+				Counterparties: []string{"2iNNt6fm9LszSWe51693BeyNUKX34pPaLx", "fyqX5YuwXMUs4GEUE3LjLyhrqvNztFHQ4B"},
+				Note:           "invoice 7",
+			},
+		},
+	}
+
+	require.Equal(t, api.LedgerColumns, l.columns())
+	require.Equal(t, [][]string{
+		{
+			"abc",
+			"incoming",
+			"2018-01-31T12:00:00Z",
+			"true",
+			"4",
+			"12",
+			"1.500000",
+			"3",
+			"0",
+			"2iNNt6fm9LszSWe51693BeyNUKX34pPaLx;fyqX5YuwXMUs4GEUE3LjLyhrqvNztFHQ4B",
+			"invoice 7",
+		},
+	}, l.rows())
+}
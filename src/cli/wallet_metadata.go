@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	gcli "github.com/urfave/cli"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+const walletMetadataDescription = `
+		The default wallet (%s) will be
+		used if no wallet was specified.
+
+		The notes, labels and contacts of an encrypted wallet are encrypted
+		with the wallet, the password is required to read or change them.
+
+		Use caution when using the "-p" command. If you have command history enabled
+		your wallet encryption password can be recovered from the history log. If you
+		do not include the "-p" option you will be prompted to enter your password
+		after you enter your command.`
+
+func walletMetadataFlags() []gcli.Flag {
+	return []gcli.Flag{
+		gcli.StringFlag{
+			Name:  "f",
+			Usage: "[wallet file or path] The wallet. If no path is specified your default wallet path will be used.",
+		},
+		gcli.StringFlag{
+			Name:  "p",
+			Usage: "[password] Wallet password, if encrypted",
+		},
+	}
+}
+
+func walletMetadataCmd(cfg Config) gcli.Command {
+	name := "walletMetadata"
+	return gcli.Command{
+		Name:         name,
+		Usage:        "Show the transaction notes, address labels and contacts of a wallet",
+		ArgsUsage:    " ",
+		Description:  fmt.Sprintf(walletMetadataDescription, cfg.FullWalletPath()),
+		Flags:        walletMetadataFlags(),
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			if c.NArg() > 0 {
+				return errorWithHelp(c, errors.New("invalid argument"))
+			}
+
+			m, err := viewWalletMetadata(c)
+			switch err.(type) {
+			case nil:
+			case WalletLoadError:
+				return errorWithHelp(c, err)
+			default:
+				return err
+			}
+
+			return printResult(c, m, nil)
+		},
+	}
+}
+
+func setNoteCmd(cfg Config) gcli.Command {
+	name := "setNote"
+	return gcli.Command{
+		Name:         name,
+		Usage:        "Set the note of a transaction in a wallet, an empty note removes the note",
+		ArgsUsage:    "[txid] [note]",
+		Description:  fmt.Sprintf(walletMetadataDescription, cfg.FullWalletPath()),
+		Flags:        walletMetadataFlags(),
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			if c.NArg() < 1 || c.NArg() > 2 {
+				return errorWithHelp(c, errors.New("invalid argument"))
+			}
+
+			txid, err := cipher.SHA256FromHex(c.Args().First())
+			if err != nil {
+				return errorWithHelp(c, fmt.Errorf("invalid txid: %v", err))
+			}
+
+			note := c.Args().Get(1)
+			return updateWalletMetadataAction(c, func(m *wallet.Metadata) error {
+				m.SetNote(txid, note)
+				return nil
+			})
+		},
+	}
+}
+
+func setAddressLabelCmd(cfg Config) gcli.Command {
+	name := "setAddressLabel"
+	return gcli.Command{
+		Name:         name,
+		Usage:        "Set the label of an address in a wallet, an empty label removes the label",
+		ArgsUsage:    "[address] [label]",
+		Description:  fmt.Sprintf(walletMetadataDescription, cfg.FullWalletPath()),
+		Flags:        walletMetadataFlags(),
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			if c.NArg() < 1 || c.NArg() > 2 {
+				return errorWithHelp(c, errors.New("invalid argument"))
+			}
+
+			addr, err := cipher.DecodeBase58Address(c.Args().First())
+			if err != nil {
+				return errorWithHelp(c, fmt.Errorf("invalid address: %v", err))
+			}
+
+			label := c.Args().Get(1)
+			return updateWalletMetadataAction(c, func(m *wallet.Metadata) error {
+				m.SetLabel(addr, label)
+				return nil
+			})
+		},
+	}
+}
+
+func addContactCmd(cfg Config) gcli.Command {
+	name := "addContact"
+	return gcli.Command{
+		Name:         name,
+		Usage:        "Add a contact to the address book of a wallet, or change the address of a contact",
+		ArgsUsage:    "[name] [address]",
+		Description:  fmt.Sprintf(walletMetadataDescription, cfg.FullWalletPath()),
+		Flags:        walletMetadataFlags(),
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			if c.NArg() != 2 {
+				return errorWithHelp(c, errors.New("invalid argument"))
+			}
+
+			name := c.Args().First()
+			addr, err := cipher.DecodeBase58Address(c.Args().Get(1))
+			if err != nil {
+				return errorWithHelp(c, fmt.Errorf("invalid address: %v", err))
+			}
+
+			return updateWalletMetadataAction(c, func(m *wallet.Metadata) error {
+				return m.SetContact(name, addr)
+			})
+		},
+	}
+}
+
+func removeContactCmd(cfg Config) gcli.Command {
+	name := "removeContact"
+	return gcli.Command{
+		Name:         name,
+		Usage:        "Remove a contact from the address book of a wallet",
+		ArgsUsage:    "[name]",
+		Description:  fmt.Sprintf(walletMetadataDescription, cfg.FullWalletPath()),
+		Flags:        walletMetadataFlags(),
+		OnUsageError: onCommandUsageError(name),
+		Action: func(c *gcli.Context) error {
+			if c.NArg() != 1 {
+				return errorWithHelp(c, errors.New("invalid argument"))
+			}
+
+			name := c.Args().First()
+			return updateWalletMetadataAction(c, func(m *wallet.Metadata) error {
+				return m.DeleteContact(name)
+			})
+		},
+	}
+}
+
+// updateWalletMetadataAction updates the metadata of the wallet selected by the command flags
+// and prints the updated metadata
+func updateWalletMetadataAction(c *gcli.Context, fn func(*wallet.Metadata) error) error {
+	cfg := ConfigFromContext(c)
+
+	w, err := resolveWalletPath(cfg, c.String("f"))
+	if err != nil {
+		return err
+	}
+
+	pr := newWalletPasswordReader(c, w)
+	m, err := updateWalletMetadata(w, pr, fn)
+	switch err.(type) {
+	case nil:
+	case WalletLoadError:
+		return errorWithHelp(c, err)
+	case WalletSaveError:
+		return WalletSaveError{errors.New("save wallet failed")}
+	default:
+		return err
+	}
+
+	return printResult(c, m, nil)
+}
+
+func viewWalletMetadata(c *gcli.Context) (*wallet.Metadata, error) {
+	cfg := ConfigFromContext(c)
+
+	w, err := resolveWalletPath(cfg, c.String("f"))
+	if err != nil {
+		return nil, err
+	}
+
+	wlt, err := wallet.Load(w)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	password, err := walletMetadataPassword(wlt, newWalletPasswordReader(c, w))
+	if err != nil {
+		return nil, err
+	}
+
+	return wlt.ViewMetadata(password)
+}
+
+func updateWalletMetadata(walletFile string, pr PasswordReader, fn func(*wallet.Metadata) error) (*wallet.Metadata, error) {
+	wlt, err := wallet.Load(walletFile)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	password, err := walletMetadataPassword(wlt, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *wallet.Metadata
+	if err := wlt.UpdateMetadata(password, func(wm *wallet.Metadata) error {
+		if err := fn(wm); err != nil {
+			return err
+		}
+		m = wm
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(walletFile))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wlt.Save(dir); err != nil {
+		return nil, WalletSaveError{err}
+	}
+
+	return m, nil
+}
+
+// walletMetadataPassword reads the password of an encrypted wallet,
+// an unencrypted wallet doesn't need a password
+func walletMetadataPassword(wlt *wallet.Wallet, pr PasswordReader) ([]byte, error) {
+	if !wlt.IsEncrypted() {
+		if p, ok := pr.(PasswordFromBytes); ok && len(p) != 0 {
+			return nil, wallet.ErrWalletNotEncrypted
+		}
+		return nil, nil
+	}
+
+	if pr == nil {
+		return nil, wallet.ErrMissingPassword
+	}
+
+	return pr.Password()
+}
@@ -43,6 +43,9 @@ type BlockBody struct {
 type SignedBlock struct {
 	Block
 	Sig cipher.Sig
+	// Signatures of the block header hash by the block makers that voted for the block.
+	// They are not part of the block encoding, and are stored and sent next to it.
+	MakerSigs []cipher.Sig `enc:"-"`
 }
 
 // VerifySignature verifies that the block is signed by pubkey
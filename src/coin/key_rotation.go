@@ -0,0 +1,99 @@
+package coin
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// KeyRotation replaces the public keys that can sign blocks, starting from the block with sequence number Seq.
+// It is signed by a public key that can sign the block with sequence number Seq before the rotation.
+type KeyRotation struct {
+	Seq     uint64
+	Pubkeys []cipher.PubKey
+	Sig     cipher.Sig
+}
+
+// keyRotationBody is the signed part of a KeyRotation
+type keyRotationBody struct {
+	Seq     uint64
+	Pubkeys []cipher.PubKey
+}
+
+// NewKeyRotation creates a KeyRotation signed by seckey
+func NewKeyRotation(seq uint64, pubkeys []cipher.PubKey, seckey cipher.SecKey) (*KeyRotation, error) {
+	r := &KeyRotation{
+		Seq:     seq,
+		Pubkeys: pubkeys,
+	}
+
+	if err := r.verifyPubkeys(); err != nil {
+		return nil, err
+	}
+
+	if err := seckey.Verify(); err != nil {
+		return nil, err
+	}
+
+	r.Sig = cipher.SignHash(r.Hash(), seckey)
+
+	return r, nil
+}
+
+// Hash returns the hash of the signed part of the key rotation
+func (r KeyRotation) Hash() cipher.SHA256 {
+	return cipher.SumSHA256(encoder.Serialize(keyRotationBody{
+		Seq:     r.Seq,
+		Pubkeys: r.Pubkeys,
+	}))
+}
+
+// Signer returns the public key that signed the key rotation
+func (r KeyRotation) Signer() (cipher.PubKey, error) {
+	pubkey, err := cipher.PubKeyFromSig(r.Sig, r.Hash())
+	if err != nil {
+		return cipher.PubKey{}, errors.New("Invalid key rotation sig: PubKey recovery failed")
+	}
+
+	return pubkey, nil
+}
+
+// Verify checks that the key rotation is well formed and signed by its signer.
+// It does not check that the signer was allowed to sign it.
+func (r KeyRotation) Verify() error {
+	if r.Seq == 0 {
+		return errors.New("Key rotation can't start at the genesis block")
+	}
+
+	if err := r.verifyPubkeys(); err != nil {
+		return err
+	}
+
+	signer, err := r.Signer()
+	if err != nil {
+		return err
+	}
+
+	return cipher.VerifySignature(signer, r.Sig, r.Hash())
+}
+
+func (r KeyRotation) verifyPubkeys() error {
+	if len(r.Pubkeys) == 0 {
+		return errors.New("Key rotation has no pubkeys")
+	}
+
+	pubkeys := make(map[cipher.PubKey]struct{}, len(r.Pubkeys))
+	for _, pk := range r.Pubkeys {
+		if err := pk.Verify(); err != nil {
+			return fmt.Errorf("Invalid key rotation pubkey %s: %v", pk.Hex(), err)
+		}
+		if _, ok := pubkeys[pk]; ok {
+			return fmt.Errorf("Duplicate key rotation pubkey %s", pk.Hex())
+		}
+		pubkeys[pk] = struct{}{}
+	}
+
+	return nil
+}
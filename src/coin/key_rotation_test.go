@@ -0,0 +1,86 @@
+package coin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestKeyRotation(t *testing.T) {
+	pk, sk := cipher.GenerateKeyPair()
+	newPk, _ := cipher.GenerateKeyPair()
+
+	r, err := NewKeyRotation(10, []cipher.PubKey{newPk}, sk)
+	require.NoError(t, err)
+	require.NoError(t, r.Verify())
+
+	signer, err := r.Signer()
+	require.NoError(t, err)
+	require.Equal(t, pk, signer)
+
+	// Changing the signed content changes the signer
+	r2 := *r
+	r2.Seq = 11
+	signer, err = r2.Signer()
+	if err == nil {
+		require.NotEqual(t, pk, signer)
+	}
+
+	r2 = *r
+	r2.Pubkeys = []cipher.PubKey{pk}
+	signer, err = r2.Signer()
+	if err == nil {
+		require.NotEqual(t, pk, signer)
+	}
+}
+
+func TestKeyRotationVerify(t *testing.T) {
+	_, sk := cipher.GenerateKeyPair()
+	pk, _ := cipher.GenerateKeyPair()
+
+	cases := []struct {
+		name    string
+		seq     uint64
+		pubkeys []cipher.PubKey
+		err     string
+	}{
+		{
+			name:    "genesis block",
+			seq:     0,
+			pubkeys: []cipher.PubKey{pk},
+			err:     "Key rotation can't start at the genesis block",
+		},
+		{
+			name: "no pubkeys",
+			seq:  1,
+			err:  "Key rotation has no pubkeys",
+		},
+		{
+			name:    "duplicate pubkey",
+			seq:     1,
+			pubkeys: []cipher.PubKey{pk, pk},
+			err:     "Duplicate key rotation pubkey " + pk.Hex(),
+		},
+		{
+			name:    "invalid pubkey",
+			seq:     1,
+			pubkeys: []cipher.PubKey{{}},
+			err:     "Invalid key rotation pubkey " + cipher.PubKey{}.Hex() + ": Invalid public key",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := KeyRotation{
+				Seq:     tc.seq,
+				Pubkeys: tc.pubkeys,
+			}
+			r.Sig = cipher.SignHash(r.Hash(), sk)
+
+			testutil.RequireError(t, r.Verify(), tc.err)
+		})
+	}
+}
@@ -312,6 +312,19 @@ func (txn *Transaction) OutputHours() (uint64, error) {
 	return hours, nil
 }
 
+// OutputCoins returns the coins sent as outputs
+func (txn *Transaction) OutputCoins() (uint64, error) {
+	coins := uint64(0)
+	for i := range txn.Out {
+		var err error
+		coins, err = AddUint64(coins, txn.Out[i].Coins)
+		if err != nil {
+			return 0, errors.New("Transaction output coins overflow")
+		}
+	}
+	return coins, nil
+}
+
 // Transactions transaction slice
 type Transactions []Transaction
 
@@ -336,6 +336,20 @@ func TestTransactionOutputHours(t *testing.T) {
 	testutil.RequireError(t, err, "Transaction output hours overflow")
 }
 
+func TestTransactionOutputCoins(t *testing.T) {
+	tx := Transaction{}
+	tx.PushOutput(makeAddress(), 1e6, 100)
+	tx.PushOutput(makeAddress(), 2e6, 200)
+	tx.PushOutput(makeAddress(), 5e6, 500)
+	coins, err := tx.OutputCoins()
+	require.NoError(t, err)
+	require.Equal(t, coins, uint64(8e6))
+
+	tx.PushOutput(makeAddress(), math.MaxUint64-7e6, 0)
+	_, err = tx.OutputCoins()
+	testutil.RequireError(t, err, "Transaction output coins overflow")
+}
+
 type outAddr struct {
 	Addr  cipher.Address
 	Coins uint64
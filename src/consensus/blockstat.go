@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"container/heap"
 	"fmt"
+	"sort"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/secp256k1-go"
@@ -372,6 +373,37 @@ func (self *BlockStat) GetBestHashPubkeySig() (
 	return best_h, best_p, best_s
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// The number of unique signers that published 'hash' for this seqno.
+func (self *BlockStat) GetSignerCount(hash cipher.SHA256) int {
+	info, have := self.hash2info[hash]
+	if !have {
+		return 0
+	}
+	return len(info.pubkey2sig)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// The signatures of the unique signers that published 'hash' for this
+// seqno, sorted so that every ConsensusParticipant returns them in the
+// same order.
+func (self *BlockStat) GetSigs(hash cipher.SHA256) []cipher.Sig {
+	info, have := self.hash2info[hash]
+	if !have {
+		return nil
+	}
+
+	sigs := make([]cipher.Sig, 0, len(info.pubkey2sig))
+	for _, sig := range info.pubkey2sig {
+		sigs = append(sigs, sig)
+	}
+	sort.Slice(sigs, func(i, j int) bool {
+		return bytes.Compare(sigs[i][:], sigs[j][:]) < 0
+	})
+
+	return sigs
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 func (self *BlockStat) Print() {
 
@@ -467,6 +499,26 @@ func (self *BlockStatQueue) Print() {
 	fmt.Printf("}")
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Removes the BlockStat entries with seqno smaller than 'seqno'. Without
+// this, the queue only accepts blocks within
+// 'Cfg_consensus_candidate_max_seqno_gap' of its first entry.
+func (self *BlockStatQueue) trim_below(seqno uint64) {
+	for len(self.queue) > 0 && self.queue[0].seqno < seqno {
+		heap.Pop(&self.queue)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+func (self *BlockStatQueue) get_by_seqno(seqno uint64) *BlockStat {
+	for _, statPtr := range self.queue {
+		if statPtr.seqno == seqno {
+			return statPtr
+		}
+	}
+	return nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 func (self *BlockStatQueue) try_append_to_BlockStatQueue(
 	blockPtr *BlockBase) int {
@@ -206,3 +206,80 @@ func TestBlockStat_02(t *testing.T) {
 		t.Fail()
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+type nullConnectionManager struct {
+	sent int
+}
+
+func (self *nullConnectionManager) SendBlockToAllMySubscriber(blockPtr *BlockBase) {
+	self.sent += 1
+}
+
+func (self *nullConnectionManager) Print() {}
+
+////////////////////////////////////////////////////////////////////////////////
+func TestConsensusParticipant_GetBlockStat(t *testing.T) {
+	cm := &nullConnectionManager{}
+	cp := NewConsensusParticipantPtr(cm)
+
+	hash := cipher.SumSHA256(secp256k1.RandByte(888))
+	n := 3
+
+	sigs := make(map[cipher.Sig]struct{}, n)
+	for i := 0; i < n; i++ {
+		_, seckey := cipher.GenerateKeyPair()
+		b := BlockBase{Hash: hash, Seqno: 5, Sig: cipher.SignHash(hash, seckey)}
+		sigs[b.Sig] = struct{}{}
+		cp.OnBlockHeaderArrived(&b)
+		// A duplicate is not counted again nor forwarded
+		cp.OnBlockHeaderArrived(&b)
+	}
+
+	if cm.sent != n {
+		t.Log("ConsensusParticipant::OnBlockHeaderArrived() forwarded a wrong number of blocks.")
+		t.Fail()
+	}
+
+	if cp.GetBlockStat(4) != nil {
+		t.Log("ConsensusParticipant::GetBlockStat() returned a BlockStat for an unknown seqno.")
+		t.Fail()
+	}
+
+	bs := cp.GetBlockStat(5)
+	if bs == nil || bs.GetSignerCount(hash) != n {
+		t.Log("ConsensusParticipant::GetBlockStat() or BlockStat::GetSignerCount() issue.")
+		t.Fail()
+	}
+
+	if bs != nil {
+		got := bs.GetSigs(hash)
+		for _, sig := range got {
+			delete(sigs, sig)
+		}
+		if len(got) != n || len(sigs) != 0 {
+			t.Log("BlockStat::GetSigs() did not return the signatures of the signers.")
+			t.Fail()
+		}
+	}
+
+	if bs != nil && bs.GetSignerCount(cipher.SumSHA256(secp256k1.RandByte(888))) != 0 {
+		t.Log("BlockStat::GetSignerCount() counted signers of an unknown hash.")
+		t.Fail()
+	}
+
+	cp.RemoveBlockStatsBelow(6)
+	if cp.GetBlockStat(5) != nil || cp.Get_block_stat_queue_Len() != 0 {
+		t.Log("ConsensusParticipant::RemoveBlockStatsBelow() did not remove the BlockStat.")
+		t.Fail()
+	}
+
+	// Blocks far ahead of the removed BlockStat are accepted
+	_, seckey := cipher.GenerateKeyPair()
+	b := BlockBase{Hash: hash, Seqno: 5 + Cfg_consensus_candidate_max_seqno_gap + 1, Sig: cipher.SignHash(hash, seckey)}
+	cp.OnBlockHeaderArrived(&b)
+	if bs := cp.GetBlockStat(b.Seqno); bs == nil || bs.GetSignerCount(hash) != 1 {
+		t.Log("ConsensusParticipant::OnBlockHeaderArrived() rejected a block after RemoveBlockStatsBelow().")
+		t.Fail()
+	}
+}
@@ -112,6 +112,20 @@ func (self *ConsensusParticipant) Get_block_stat_queue_element_at(
 	return self.block_stat_queue.queue[j] // A pointer, BTW
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// Returns the BlockStat of the candidates with sequence number 'seqno', or
+// nil if no candidate was received for it.
+func (self *ConsensusParticipant) GetBlockStat(seqno uint64) *BlockStat {
+	return self.block_stat_queue.get_by_seqno(seqno)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Forgets the candidates with sequence number smaller than 'seqno', e.g.
+// after the block with sequence number 'seqno - 1' was executed.
+func (self *ConsensusParticipant) RemoveBlockStatsBelow(seqno uint64) {
+	self.block_stat_queue.trim_below(seqno)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 func (self *ConsensusParticipant) OnBlockHeaderArrived(blockPtr *BlockBase) {
 
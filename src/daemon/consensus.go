@@ -0,0 +1,329 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/consensus"
+	"github.com/skycoin/skycoin/src/util/utc"
+)
+
+var (
+	// ErrNotBlockMaker is returned if a block proposal or vote is not signed by a block maker
+	ErrNotBlockMaker = errors.New("Not signed by a block maker")
+	// ErrBlockProposalKnown is returned if a block proposal was already received
+	ErrBlockProposalKnown = errors.New("Block proposal already received")
+)
+
+// blockConsensus produces blocks with a quorum of block makers instead of a master.
+// The block makers take turns to propose a block for the next sequence number.
+// Every block maker verifies the proposed block and votes for it by signing its hash.
+// The votes are collected by a consensus.ConsensusParticipant, and the block is
+// executed once a quorum of block makers voted for it.
+// If the proposed block does not reach the quorum within the proposal timeout,
+// the next block maker proposes a block.
+// All methods are called from the daemon run loop.
+type blockConsensus struct {
+	daemon      *Daemon
+	participant *consensus.ConsensusParticipant
+
+	makers  []cipher.PubKey
+	quorum  int
+	timeout time.Duration
+	pubkey  cipher.PubKey
+	isMaker bool
+
+	// The sequence number of the next block, that the votes are collected for
+	seq uint64
+	// When the votes for seq started to be collected
+	seqStart time.Time
+	// Proposed blocks for seq, by hash
+	proposals map[cipher.SHA256]blockProposal
+	// Block makers that this node voted for, for seq
+	voted map[cipher.PubKey]struct{}
+	// This node's proposal for seq
+	proposal *coin.SignedBlock
+}
+
+// blockProposal is a block proposed by a block maker
+type blockProposal struct {
+	Block    coin.SignedBlock
+	Proposer cipher.PubKey
+}
+
+func newBlockConsensus(d *Daemon) *blockConsensus {
+	cfg := d.Visor.Config
+
+	c := &blockConsensus{
+		daemon:    d,
+		makers:    cfg.BlockMakerPubkeys,
+		quorum:    cfg.BlockMakerQuorum,
+		timeout:   d.Config.BlockProposalTimeout,
+		isMaker:   cfg.IsBlockMaker(),
+		proposals: make(map[cipher.SHA256]blockProposal),
+		voted:     make(map[cipher.PubKey]struct{}),
+	}
+
+	c.participant = consensus.NewConsensusParticipantPtr(c)
+	if c.isMaker {
+		c.pubkey = cipher.PubKeyFromSecKey(cfg.BlockMakerSeckey)
+		c.participant.SetPubkeySeckey(c.pubkey, cfg.BlockMakerSeckey)
+	}
+
+	// A block maker votes at most once for each block maker's proposal
+	if n := len(c.makers) * len(c.makers); consensus.Cfg_consensus_max_candidate_messages < n {
+		consensus.Cfg_consensus_max_candidate_messages = n
+	}
+
+	return c
+}
+
+// SendBlockToAllMySubscriber sends a vote to all connections, implements consensus.ConnectionManagerInterface.
+// It is called by the consensus participant for new votes, which relays the votes through the network.
+func (c *blockConsensus) SendBlockToAllMySubscriber(b *consensus.BlockBase) {
+	if c.daemon.Config.DisableOutgoingConnections {
+		return
+	}
+
+	m := NewBlockVoteMessage(*b)
+	if err := c.daemon.Pool.Pool.BroadcastMessage(m); err != nil {
+		logger.Errorf("Broadcast BlockVoteMessage failed: %v", err)
+	}
+}
+
+// Print implements consensus.ConnectionManagerInterface
+func (c *blockConsensus) Print() {
+	logger.Debugf("blockConsensus={seq=%d,proposals=%d}", c.seq, len(c.proposals))
+}
+
+// makerIndex returns the index of a block maker, or -1 if pubkey is not a block maker
+func (c *blockConsensus) makerIndex(pubkey cipher.PubKey) int {
+	for i, pk := range c.makers {
+		if pk == pubkey {
+			return i
+		}
+	}
+	return -1
+}
+
+// round returns the number of proposal timeouts since the votes for seq started to be collected
+func (c *blockConsensus) round() int {
+	if c.timeout <= 0 {
+		return 0
+	}
+	return int(utc.Now().Sub(c.seqStart) / c.timeout)
+}
+
+// proposerRound returns the first round in which the block maker proposes a block for seq
+func (c *blockConsensus) proposerRound(makerIdx int) int {
+	n := len(c.makers)
+	return (makerIdx - int(c.seq%uint64(n)) + n) % n
+}
+
+// nextSeq returns the sequence number of the next block, and resets the collected proposals
+// and votes if the head block changed
+func (c *blockConsensus) nextSeq() (uint64, error) {
+	headSeq, ok, err := c.daemon.Visor.HeadBkSeq()
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, errors.New("No HeadBkSeq found")
+	}
+
+	seq := headSeq + 1
+	if seq != c.seq {
+		c.seq = seq
+		c.seqStart = utc.Now()
+		c.proposals = make(map[cipher.SHA256]blockProposal)
+		c.voted = make(map[cipher.PubKey]struct{})
+		c.proposal = nil
+		c.participant.RemoveBlockStatsBelow(seq)
+	}
+
+	return seq, nil
+}
+
+// propose proposes a block if it is this block maker's turn.
+// Returns nil, nil if it is not this block maker's turn.
+func (c *blockConsensus) propose() (*coin.SignedBlock, error) {
+	if !c.isMaker {
+		return nil, nil
+	}
+
+	if _, err := c.nextSeq(); err != nil {
+		return nil, err
+	}
+
+	// Vote for the proposals received before their proposer's turn came
+	for hash, p := range c.proposals {
+		if err := c.maybeVote(hash, p.Proposer); err != nil {
+			return nil, err
+		}
+	}
+
+	// Once it is this block maker's turn, the proposal is sent again at every block creation interval
+	if c.round() < c.proposerRound(c.makerIndex(c.pubkey)) {
+		return nil, nil
+	}
+
+	if c.proposal == nil {
+		sb, err := c.daemon.Visor.CreateBlockProposal()
+		if err != nil {
+			return nil, err
+		}
+		c.proposal = &sb
+	}
+
+	sb := *c.proposal
+	switch err := c.onProposal(sb); err {
+	case nil:
+	case ErrBlockProposalKnown:
+		// Send the proposal again, for the block makers that connected since
+		if err := c.daemon.broadcastBlockProposal(sb); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	return &sb, nil
+}
+
+// onProposal handles a proposed block. The block is saved, relayed to the peers and voted for.
+func (c *blockConsensus) onProposal(sb coin.SignedBlock) error {
+	hash := sb.HashHeader()
+
+	signer, err := cipher.PubKeyFromSig(sb.Sig, hash)
+	if err != nil {
+		return err
+	}
+
+	if c.makerIndex(signer) < 0 {
+		return ErrNotBlockMaker
+	}
+
+	seq, err := c.nextSeq()
+	if err != nil {
+		return err
+	}
+
+	if sb.Seq() != seq {
+		return fmt.Errorf("Block proposal seq %d is not the next seq %d", sb.Seq(), seq)
+	}
+
+	if _, ok := c.proposals[hash]; ok {
+		return ErrBlockProposalKnown
+	}
+
+	if err := c.daemon.Visor.VerifyBlockProposal(sb); err != nil {
+		return err
+	}
+
+	c.proposals[hash] = blockProposal{
+		Block:    sb,
+		Proposer: signer,
+	}
+
+	// Relay the proposal to the block makers that are not connected to its proposer
+	if err := c.daemon.broadcastBlockProposal(sb); err != nil {
+		logger.WithError(err).Error("Broadcast ProposeBlockMessage failed")
+	}
+
+	if err := c.maybeVote(hash, signer); err != nil {
+		return err
+	}
+
+	return c.maybeExecute(hash)
+}
+
+// maybeVote votes for a proposed block if this node is a block maker and the proposer's turn has come.
+// A block maker votes once for the proposal of each block maker.
+func (c *blockConsensus) maybeVote(hash cipher.SHA256, proposer cipher.PubKey) error {
+	if !c.isMaker {
+		return nil
+	}
+
+	if _, ok := c.voted[proposer]; ok {
+		return nil
+	}
+
+	if c.round() < c.proposerRound(c.makerIndex(proposer)) {
+		return nil
+	}
+
+	c.voted[proposer] = struct{}{}
+
+	return c.onVote(consensus.BlockBase{
+		Sig:   c.participant.SignatureOf(hash),
+		Hash:  hash,
+		Seqno: c.seq,
+	})
+}
+
+// onVote handles a block maker's vote for a proposed block. The consensus participant
+// relays new votes to the peers.
+func (c *blockConsensus) onVote(vote consensus.BlockBase) error {
+	signer, err := cipher.PubKeyFromSig(vote.Sig, vote.Hash)
+	if err != nil {
+		return err
+	}
+
+	if c.makerIndex(signer) < 0 {
+		return ErrNotBlockMaker
+	}
+
+	seq, err := c.nextSeq()
+	if err != nil {
+		return err
+	}
+
+	// Votes for other blocks than the next block can't be used
+	if vote.Seqno != seq {
+		return nil
+	}
+
+	c.participant.OnBlockHeaderArrived(&vote)
+
+	return c.maybeExecute(vote.Hash)
+}
+
+// maybeExecute executes a proposed block once a quorum of block makers voted for it
+func (c *blockConsensus) maybeExecute(hash cipher.SHA256) error {
+	p, ok := c.proposals[hash]
+	if !ok {
+		return nil
+	}
+	sb := p.Block
+
+	bs := c.participant.GetBlockStat(c.seq)
+	if bs == nil {
+		return nil
+	}
+
+	votes := bs.GetSignerCount(hash)
+	if votes < c.quorum {
+		return nil
+	}
+
+	// The votes are executed and sent with the block, so that the other nodes can verify the quorum
+	sb.MakerSigs = bs.GetSigs(hash)
+
+	if err := c.daemon.Visor.ExecuteSignedBlock(sb); err != nil {
+		return err
+	}
+
+	// Not a critical error, but we want it visible in logs
+	head := sb.Block.Head
+	logger.Critical().Infof("Executed block with %d of %d block maker votes, version=%d seq=%d time=%d", votes, len(c.makers), head.Version, head.BkSeq, head.Time)
+
+	if _, err := c.nextSeq(); err != nil {
+		return err
+	}
+
+	// Send the block to the nodes that don't follow the votes
+	return c.daemon.broadcastBlock(sb)
+}
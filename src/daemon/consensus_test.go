@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/consensus"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestBlockConsensusProposerRound(t *testing.T) {
+	makers := make([]cipher.PubKey, 3)
+	for i := range makers {
+		makers[i], _ = cipher.GenerateKeyPair()
+	}
+
+	c := &blockConsensus{
+		makers: makers,
+	}
+
+	require.Equal(t, 0, c.makerIndex(makers[0]))
+	require.Equal(t, 2, c.makerIndex(makers[2]))
+	pk, _ := cipher.GenerateKeyPair()
+	require.Equal(t, -1, c.makerIndex(pk))
+
+	cases := []struct {
+		seq    uint64
+		rounds []int
+	}{
+		{0, []int{0, 1, 2}},
+		{1, []int{2, 0, 1}},
+		{2, []int{1, 2, 0}},
+		{3, []int{0, 1, 2}},
+		{10, []int{2, 0, 1}},
+	}
+
+	for _, tc := range cases {
+		c.seq = tc.seq
+		for i, r := range tc.rounds {
+			require.Equal(t, r, c.proposerRound(i), "seq=%d maker=%d", tc.seq, i)
+		}
+	}
+}
+
+func TestBlockConsensusOnVoteNotBlockMaker(t *testing.T) {
+	pk, _ := cipher.GenerateKeyPair()
+	c := &blockConsensus{
+		makers: []cipher.PubKey{pk},
+	}
+
+	_, sk := cipher.GenerateKeyPair()
+	hash := cipher.SumSHA256([]byte("block"))
+	err := c.onVote(consensus.BlockBase{
+		Sig:   cipher.SignHash(hash, sk),
+		Hash:  hash,
+		Seqno: 1,
+	})
+	require.Equal(t, ErrNotBlockMaker, err)
+}
+
+func TestGiveBlocksMessageMakerSigs(t *testing.T) {
+	hash := testutil.RandSHA256(t)
+	_, sk := cipher.GenerateKeyPair()
+	sig := cipher.SignHash(hash, sk)
+
+	blocks := []coin.SignedBlock{
+		{
+			Block: coin.Block{Head: coin.BlockHeader{BkSeq: 1}},
+			Sig:   sig,
+		},
+		{
+			Block:     coin.Block{Head: coin.BlockHeader{BkSeq: 2}},
+			Sig:       sig,
+			MakerSigs: []cipher.Sig{sig, sig},
+		},
+	}
+
+	// Without block maker sigs, the message is encoded as before
+	m := NewGiveBlocksMessage(blocks[:1])
+	require.Nil(t, m.MakerSigs)
+	legacy := encoder.Serialize(struct {
+		Blocks []coin.SignedBlock
+	}{
+		Blocks: blocks[:1],
+	})
+	require.Equal(t, legacy, encoder.Serialize(*m))
+
+	var got GiveBlocksMessage
+	require.NoError(t, encoder.DeserializeRaw(legacy, &got))
+	require.Equal(t, blocks[:1], got.Blocks)
+	require.Empty(t, got.MakerSigs)
+
+	// The block maker sigs are sent next to the blocks
+	m = NewGiveBlocksMessage(blocks)
+	require.Equal(t, [][]cipher.Sig{nil, {sig, sig}}, m.MakerSigs)
+
+	got = GiveBlocksMessage{}
+	require.NoError(t, encoder.DeserializeRaw(encoder.Serialize(*m), &got))
+	require.Len(t, got.Blocks, 2)
+	require.Empty(t, got.Blocks[1].MakerSigs)
+	require.Len(t, got.MakerSigs, 2)
+	require.Empty(t, got.MakerSigs[0])
+	require.Equal(t, []cipher.Sig{sig, sig}, got.MakerSigs[1])
+}
@@ -137,6 +137,10 @@ type DaemonConfig struct { // nolint: golint
 	PrivateRate time.Duration
 	// Number of outgoing connections to maintain
 	OutgoingMax int
+	// Peers that are trusted along with the default connections
+	TrustedPeers []string
+	// Peers that are private, which are always connected to and never shared with other peers
+	PrivatePeers []string
 	// Maximum number of connections to try at once
 	PendingMax int
 	// How long to wait for a version packet
@@ -167,6 +171,9 @@ type DaemonConfig struct { // nolint: golint
 	MaxTxnAnnounceNum int
 	// How often new blocks are created by the signing node, in seconds
 	BlockCreationInterval uint64
+	// How long to wait for a block proposed by a block maker to reach the quorum,
+	// before the next block maker proposes a block
+	BlockProposalTimeout time.Duration
 	// How often to check the unconfirmed pool for transactions that become valid
 	UnconfirmedRefreshRate time.Duration
 	// How often to remove transactions that become permanently invalid from the unconfirmed pool
@@ -197,6 +204,7 @@ func NewDaemonConfig() DaemonConfig {
 		BlocksResponseCount:          20,
 		MaxTxnAnnounceNum:            16,
 		BlockCreationInterval:        10,
+		BlockProposalTimeout:         time.Second * 30,
 		UnconfirmedRefreshRate:       time.Minute,
 		UnconfirmedRemoveInvalidRate: time.Minute,
 	}
@@ -216,6 +224,9 @@ type Daemon struct {
 
 	DefaultConnections []string
 
+	// Block production by a quorum of block makers, nil if blocks are created by a master
+	consensus *blockConsensus
+
 	// Cache of announced transactions that are flushed to the database periodically
 	announcedTxns *announcedTxnsCache
 	// Cache of reported peer blockchain heights
@@ -301,9 +312,90 @@ func NewDaemon(config Config, db *dbutil.DB, defaultConns []string) (*Daemon, er
 	d.Messages.Config.Register()
 	d.Pool = NewPool(config.Pool, d)
 
+	if len(config.Visor.BlockMakerPubkeys) != 0 {
+		d.consensus = newBlockConsensus(d)
+	}
+
+	// Mark the trusted and private peers of the config in the peer list
+	d.Config.TrustedPeers, d.Config.PrivatePeers = nil, nil
+	d.setConfiguredPeers(config.Daemon.TrustedPeers, config.Daemon.PrivatePeers)
+
 	return d, nil
 }
 
+// RuntimeConfig are the daemon options that can be changed while the daemon runs
+type RuntimeConfig struct {
+	// Number of outgoing connections to maintain
+	OutgoingMax int
+	// Peers that are trusted along with the default connections
+	TrustedPeers []string
+	// Peers that are private
+	PrivatePeers []string
+	// Enable the wallet seed API
+	EnableSeedAPI bool
+}
+
+// setConfiguredPeers marks the trusted and private peers in the peer list. The peers that are no longer
+// in the config are unmarked, except the default connections, which are always trusted.
+func (dm *Daemon) setConfiguredPeers(trusted, private []string) {
+	for _, addr := range dm.Config.TrustedPeers {
+		if !containsString(trusted, addr) && !containsString(dm.DefaultConnections, addr) {
+			if err := dm.Pex.SetTrusted(addr, false); err != nil {
+				logger.WithError(err).Errorf("Failed to untrust peer %s", addr)
+			}
+		}
+	}
+
+	for _, addr := range dm.Config.PrivatePeers {
+		if !containsString(private, addr) {
+			if err := dm.Pex.SetPrivate(addr, false); err != nil {
+				logger.WithError(err).Errorf("Failed to unset private peer %s", addr)
+			}
+		}
+	}
+
+	for _, addr := range trusted {
+		if err := dm.Pex.AddPeer(addr); err != nil {
+			logger.WithError(err).Errorf("Failed to add trusted peer %s", addr)
+		}
+		if err := dm.Pex.SetTrusted(addr, true); err != nil {
+			logger.WithError(err).Errorf("Failed to trust peer %s", addr)
+		}
+	}
+
+	for _, addr := range private {
+		if err := dm.Pex.AddPeer(addr); err != nil {
+			logger.WithError(err).Errorf("Failed to add private peer %s", addr)
+		}
+		if err := dm.Pex.SetPrivate(addr, true); err != nil {
+			logger.WithError(err).Errorf("Failed to set private peer %s", addr)
+		}
+	}
+
+	dm.Config.TrustedPeers = trusted
+	dm.Config.PrivatePeers = private
+}
+
+// setRuntimeConfig applies the runtime options. Must be called from the daemon's loop.
+func (dm *Daemon) setRuntimeConfig(c RuntimeConfig) {
+	if c.OutgoingMax != dm.Config.OutgoingMax {
+		logger.Infof("Max outgoing connections changed from %d to %d", dm.Config.OutgoingMax, c.OutgoingMax)
+		dm.Config.OutgoingMax = c.OutgoingMax
+	}
+
+	dm.setConfiguredPeers(c.TrustedPeers, c.PrivatePeers)
+	dm.Visor.Wallets.SetEnableSeedAPI(c.EnableSeedAPI)
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
 // ConnectEvent generated when a client connects
 type ConnectEvent struct {
 	Addr      string
@@ -392,7 +484,7 @@ func (dm *Daemon) Run() error {
 
 	blockInterval := time.Duration(dm.Config.BlockCreationInterval)
 	blockCreationTicker := time.NewTicker(time.Second * blockInterval)
-	if !dm.Visor.Config.IsMaster {
+	if !dm.Visor.Config.IsMaster && !dm.Visor.Config.IsBlockMaker() {
 		blockCreationTicker.Stop()
 	}
 
@@ -565,7 +657,18 @@ loop:
 		case <-blockCreationTicker.C:
 			// Create blocks, if master chain
 			elapser.Register("blockCreationTicker.C")
-			if dm.Visor.Config.IsMaster {
+			if dm.consensus != nil {
+				// Propose blocks, if block maker
+				sb, err := dm.consensus.propose()
+				if err != nil {
+					logger.Errorf("Failed to propose block: %v", err)
+					continue
+				}
+
+				if sb != nil {
+					logger.Infof("Proposed block seq=%d hash=%s", sb.Seq(), sb.HashHeader().Hex())
+				}
+			} else if dm.Visor.Config.IsMaster {
 				sb, err := dm.CreateAndPublishBlock()
 				if err != nil {
 					logger.Errorf("Failed to create and publish block: %v", err)
@@ -600,6 +703,18 @@ loop:
 				logger.Infof("Remove %d txns from pool that began violating hard constraints", len(removedTxns))
 			}
 
+			// Remove transactions that expired
+			if dm.Visor.Config.UnconfirmedTxnsMaxAge > 0 {
+				expiredTxns, err := dm.Visor.RemoveExpiredUnconfirmed(0)
+				if err != nil {
+					logger.Errorf("dm.Visor.RemoveExpiredUnconfirmed failed: %v", err)
+					continue
+				}
+				if len(expiredTxns) > 0 {
+					logger.Infof("Remove %d expired txns from pool", len(expiredTxns))
+				}
+			}
+
 		case <-blocksRequestTicker:
 			elapser.Register("blocksRequestTicker")
 			dm.RequestBlocks()
@@ -1230,6 +1345,57 @@ func (dm *Daemon) CreateAndPublishBlock() (*coin.SignedBlock, error) {
 	return &sb, err
 }
 
+// Sends a block proposed by a block maker to all connections.
+func (dm *Daemon) broadcastBlockProposal(sb coin.SignedBlock) error {
+	if dm.Config.DisableOutgoingConnections {
+		return nil
+	}
+
+	m := NewProposeBlockMessage(sb)
+	return dm.Pool.Pool.BroadcastMessage(m)
+}
+
+// InjectBroadcastKeyRotation adds a key rotation of the blockchain signing keys and broadcasts it
+func (dm *Daemon) InjectBroadcastKeyRotation(r coin.KeyRotation) error {
+	if err := dm.Visor.AddKeyRotation(r); err != nil {
+		return err
+	}
+
+	return dm.broadcastKeyRotations([]coin.KeyRotation{r})
+}
+
+// sendKeyRotations sends the key rotations that start in the range [start, end] to a connection
+func (dm *Daemon) sendKeyRotations(addr string, start, end uint64) error {
+	rotations, err := dm.Visor.GetKeyRotations()
+	if err != nil {
+		return err
+	}
+
+	var send []coin.KeyRotation
+	for _, r := range rotations {
+		if r.Seq >= start && r.Seq <= end {
+			send = append(send, r)
+		}
+	}
+
+	if len(send) == 0 {
+		return nil
+	}
+
+	m := NewGiveKeyRotationsMessage(send)
+	return dm.Pool.Pool.SendMessage(addr, m)
+}
+
+// Sends key rotations to all connections
+func (dm *Daemon) broadcastKeyRotations(rotations []coin.KeyRotation) error {
+	if dm.Config.DisableOutgoingConnections {
+		return nil
+	}
+
+	m := NewGiveKeyRotationsMessage(rotations)
+	return dm.Pool.Pool.BroadcastMessage(m)
+}
+
 // Sends a signed block to all connections.
 func (dm *Daemon) broadcastBlock(sb coin.SignedBlock) error {
 	if dm.Config.DisableOutgoingConnections {
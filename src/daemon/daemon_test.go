@@ -1,11 +1,14 @@
 package daemon
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon/pex"
 )
 
 func TestDivideHashes(t *testing.T) {
@@ -103,3 +106,48 @@ func TestDivideHashes(t *testing.T) {
 		})
 	}
 }
+
+func TestSetConfiguredPeers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "daemon")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defaultConns := []string{"118.178.135.93:6000"}
+
+	cfg := pex.NewConfig()
+	cfg.DataDirectory = dir
+	px, err := pex.New(cfg, defaultConns)
+	require.NoError(t, err)
+
+	d := &Daemon{
+		Pex:                px,
+		DefaultConnections: defaultConns,
+	}
+
+	isTrusted := func(addr string) bool {
+		p, ok := px.GetPeerByAddr(addr)
+		require.True(t, ok)
+		return p.Trusted
+	}
+
+	isPrivate := func(addr string) bool {
+		p, ok := px.GetPeerByAddr(addr)
+		require.True(t, ok)
+		return p.Private
+	}
+
+	d.setConfiguredPeers([]string{"47.88.33.156:6000", "118.178.135.93:6000"}, []string{"121.41.103.148:6000"})
+	require.True(t, isTrusted("47.88.33.156:6000"))
+	require.True(t, isTrusted("118.178.135.93:6000"))
+	require.True(t, isPrivate("121.41.103.148:6000"))
+	require.False(t, isTrusted("121.41.103.148:6000"))
+
+	// The peers removed from the config are unmarked, except the default connections
+	d.setConfiguredPeers([]string{"120.77.69.188:6000"}, nil)
+	require.True(t, isTrusted("120.77.69.188:6000"))
+	require.False(t, isTrusted("47.88.33.156:6000"))
+	require.True(t, isTrusted("118.178.135.93:6000"))
+	require.False(t, isPrivate("121.41.103.148:6000"))
+	require.Equal(t, []string{"120.77.69.188:6000"}, d.Config.TrustedPeers)
+	require.Empty(t, d.Config.PrivatePeers)
+}
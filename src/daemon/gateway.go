@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"errors"
 	"sort"
 	"strings"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon/strand"
+	"github.com/skycoin/skycoin/src/util/logging"
 	"github.com/skycoin/skycoin/src/util/utc"
 	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/wallet"
@@ -44,6 +46,8 @@ type Gateway struct {
 	// Requests are queued on this channel
 	requests chan strand.Request
 	quit     chan struct{}
+	// ID of the API request that the Gateway's calls are made for, added to the log lines of the calls
+	requestID string
 }
 
 // NewGateway create and init an Gateway instance.
@@ -66,12 +70,34 @@ func (gw *Gateway) Shutdown() {
 
 func (gw *Gateway) strand(name string, f func()) {
 	name = fmt.Sprintf("daemon.Gateway.%s", name)
-	strand.Strand(logger, gw.requests, name, func() error {
+	strand.Strand(gw.log(), gw.requests, name, func() error {
+		if gw.requestID != "" && gw.v != nil {
+			gw.v.SetRequestID(gw.requestID)
+			defer gw.v.SetRequestID("")
+		}
 		f()
 		return nil
 	}, gw.quit, nil)
 }
 
+// WithRequestID returns a Gateway that adds the ID of an API request to the log lines
+// of its calls, including the log lines of the visor while it handles the calls
+func (gw *Gateway) WithRequestID(id string) *Gateway {
+	g := *gw
+	g.requestID = id
+	return &g
+}
+
+// log returns the logger of the Gateway's log lines
+func (gw *Gateway) log() *logging.Logger {
+	if gw.requestID == "" {
+		return logger
+	}
+	return &logging.Logger{
+		FieldLogger: logger.WithField(logging.RequestIDKey, gw.requestID),
+	}
+}
+
 // Connection a connection's state within the daemon
 type Connection struct {
 	ID           int    `json:"id"`
@@ -109,14 +135,14 @@ func (gw *Gateway) getConnections() *Connections {
 
 	n, err := gw.d.Pool.Pool.Size()
 	if err != nil {
-		logger.Error(err)
+		gw.log().Error(err)
 		return nil
 	}
 
 	conns := make([]*Connection, 0, n)
 	cs, err := gw.d.Pool.Pool.GetConnections()
 	if err != nil {
-		logger.Error(err)
+		gw.log().Error(err)
 		return nil
 	}
 
@@ -164,7 +190,7 @@ func (gw *Gateway) getConnection(addr string) *Connection {
 
 	c, err := gw.d.Pool.Pool.GetConnection(addr)
 	if err != nil {
-		logger.Error(err)
+		gw.log().Error(err)
 		return nil
 	}
 
@@ -209,6 +235,13 @@ func (gw *Gateway) GetExchgConnection() []string {
 	return conn
 }
 
+// SetRuntimeConfig applies the daemon options that can be changed while the daemon runs
+func (gw *Gateway) SetRuntimeConfig(c RuntimeConfig) {
+	gw.strand("SetRuntimeConfig", func() {
+		gw.d.setRuntimeConfig(c)
+	})
+}
+
 /* Blockchain & Transaction status */
 
 // BlockchainProgress current sync blockchain status
@@ -653,14 +686,14 @@ func (gw *Gateway) GetTransactionsForAddress(a cipher.Address) ([]ReadableTransa
 		var txns []visor.Transaction
 		txns, err = gw.v.GetAddressTxns(a)
 		if err != nil {
-			logger.Errorf("Gateway.GetTransactionsForAddress: gw.v.GetAddressTxns failed: %v", err)
+			gw.log().Errorf("Gateway.GetTransactionsForAddress: gw.v.GetAddressTxns failed: %v", err)
 			return
 		}
 
 		var head *coin.SignedBlock
 		head, err = gw.v.GetHeadBlock()
 		if err != nil {
-			logger.Errorf("Gateway.GetTransactionsForAddress: gw.v.GetHeadBlock failed: %v", err)
+			gw.log().Errorf("Gateway.GetTransactionsForAddress: gw.v.GetHeadBlock failed: %v", err)
 			return
 		}
 
@@ -672,7 +705,7 @@ func (gw *Gateway) GetTransactionsForAddress(a cipher.Address) ([]ReadableTransa
 				var input *historydb.UxOut
 				input, err = gw.v.GetUxOutByID(inputID)
 				if err != nil {
-					logger.Errorf("Gateway.GetTransactionsForAddress: gw.v.GetUxOutByID failed: %v", err)
+					gw.log().Errorf("Gateway.GetTransactionsForAddress: gw.v.GetUxOutByID failed: %v", err)
 					return
 				}
 				if input == nil {
@@ -691,7 +724,7 @@ func (gw *Gateway) GetTransactionsForAddress(a cipher.Address) ([]ReadableTransa
 				var readableInput *visor.ReadableTransactionInput
 				readableInput, err = visor.NewReadableTransactionInput(input.Out, t)
 				if err != nil {
-					logger.Errorf("Gateway.GetTransactionsForAddress: visor.NewReadableTransactionInput failed: %v", err)
+					gw.log().Errorf("Gateway.GetTransactionsForAddress: visor.NewReadableTransactionInput failed: %v", err)
 					return
 				}
 
@@ -701,7 +734,7 @@ func (gw *Gateway) GetTransactionsForAddress(a cipher.Address) ([]ReadableTransa
 			var rTxn ReadableTransaction
 			rTxn, err = NewReadableTransaction(txn, inputs)
 			if err != nil {
-				logger.Errorf("Gateway.GetTransactionsForAddress: NewReadableTransaction failed: %v", err)
+				gw.log().Errorf("Gateway.GetTransactionsForAddress: NewReadableTransaction failed: %v", err)
 				return
 			}
 
@@ -727,6 +760,16 @@ func (gw *Gateway) GetTransactions(flts ...visor.TxFilter) ([]visor.Transaction,
 	return txns, err
 }
 
+// GetLedger returns the ledger of addresses, with start <= time <= end. If end is 0, there is no upper bound.
+func (gw *Gateway) GetLedger(addrs []cipher.Address, start, end uint64) ([]visor.LedgerEntry, error) {
+	var entries []visor.LedgerEntry
+	var err error
+	gw.strand("GetLedger", func() {
+		entries, err = gw.v.GetLedger(addrs, start, end)
+	})
+	return entries, err
+}
+
 // GetUxOutByID gets UxOut by hash id.
 func (gw *Gateway) GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, error) {
 	var uxout *historydb.UxOut
@@ -776,6 +819,39 @@ func (gw *Gateway) GetAllUnconfirmedTxns() ([]visor.UnconfirmedTxn, error) {
 	return txns, err
 }
 
+// GetUnconfirmedTxnPoolStats returns the unconfirmed pool size and eviction and replacement counters
+func (gw *Gateway) GetUnconfirmedTxnPoolStats() (*visor.UnconfirmedTxnPoolStats, error) {
+	var stats *visor.UnconfirmedTxnPoolStats
+	var err error
+	gw.strand("GetUnconfirmedTxnPoolStats", func() {
+		stats, err = gw.v.GetUnconfirmedTxnPoolStats()
+	})
+	return stats, err
+}
+
+// GetExpiredUnconfirmedTxns returns the unconfirmed transactions received more than maxAge ago.
+// If maxAge is 0, the configured max age is used.
+func (gw *Gateway) GetExpiredUnconfirmedTxns(maxAge time.Duration) ([]visor.UnconfirmedTxn, error) {
+	var txns []visor.UnconfirmedTxn
+	var err error
+	gw.strand("GetExpiredUnconfirmedTxns", func() {
+		txns, err = gw.v.GetExpiredUnconfirmedTxns(maxAge)
+	})
+	return txns, err
+}
+
+// RemoveExpiredUnconfirmedTxns removes the unconfirmed transactions received more than maxAge ago,
+// and the transactions spending their outputs.
+// If maxAge is 0, the configured max age is used.
+func (gw *Gateway) RemoveExpiredUnconfirmedTxns(maxAge time.Duration) ([]cipher.SHA256, error) {
+	var hashes []cipher.SHA256
+	var err error
+	gw.strand("RemoveExpiredUnconfirmedTxns", func() {
+		hashes, err = gw.v.RemoveExpiredUnconfirmed(maxAge)
+	})
+	return hashes, err
+}
+
 // GetUnconfirmedTxns returns addresses related unconfirmed transactions
 func (gw *Gateway) GetUnconfirmedTxns(addrs []cipher.Address) ([]visor.UnconfirmedTxn, error) {
 	var txns []visor.UnconfirmedTxn
@@ -805,7 +881,7 @@ func (gw *Gateway) Spend(wltID string, password []byte, coins uint64, dest ciphe
 		// Inject transaction
 		err = gw.d.InjectBroadcastTransaction(*txn)
 		if err != nil {
-			logger.Errorf("Inject transaction failed: %v", err)
+			gw.log().Errorf("Inject transaction failed: %v", err)
 			return
 		}
 	})
@@ -838,6 +914,23 @@ func (gw *Gateway) CreateTransaction(params wallet.CreateTransactionParams) (*co
 	return txn, inputs, err
 }
 
+// EstimateTransaction creates an unsigned transaction to preview a spend, and estimates how soon it would be confirmed.
+// The wallet API must be enabled if the transaction spends from a wallet.
+func (gw *Gateway) EstimateTransaction(params wallet.CreateTransactionParams) (*visor.TransactionEstimate, error) {
+	if params.Wallet.ID != "" && !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	var estimate *visor.TransactionEstimate
+	var err error
+
+	gw.strand("EstimateTransaction", func() {
+		estimate, err = gw.v.EstimateTransaction(params)
+	})
+
+	return estimate, err
+}
+
 // CreateWallet creates wallet
 func (gw *Gateway) CreateWallet(wltName string, options wallet.Options) (*wallet.Wallet, error) {
 	if !gw.Config.EnableWalletAPI {
@@ -880,6 +973,90 @@ func (gw *Gateway) DecryptWallet(wltID string, password []byte) (*wallet.Wallet,
 	return w, err
 }
 
+// ChangeWalletPassword re-encrypts an encrypted wallet with a new password and/or crypto type
+func (gw *Gateway) ChangeWalletPassword(wltID string, password []byte, opts wallet.ChangePasswordOptions) (*wallet.Wallet, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	var err error
+	var w *wallet.Wallet
+	gw.strand("ChangeWalletPassword", func() {
+		w, err = gw.v.Wallets.ChangeWalletPassword(wltID, password, opts)
+	})
+	return w, err
+}
+
+// GetWalletMetadata returns the notes, address labels and contacts of a wallet
+func (gw *Gateway) GetWalletMetadata(wltID string, password []byte) (*wallet.Metadata, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	var err error
+	var m *wallet.Metadata
+	gw.strand("GetWalletMetadata", func() {
+		m, err = gw.v.Wallets.GetWalletMetadata(wltID, password)
+	})
+	return m, err
+}
+
+// SetWalletNote sets the note of a transaction in a wallet
+func (gw *Gateway) SetWalletNote(wltID string, password []byte, txid cipher.SHA256, note string) (*wallet.Metadata, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	var err error
+	var m *wallet.Metadata
+	gw.strand("SetWalletNote", func() {
+		m, err = gw.v.Wallets.SetWalletNote(wltID, password, txid, note)
+	})
+	return m, err
+}
+
+// SetWalletAddressLabel sets the label of an address in a wallet
+func (gw *Gateway) SetWalletAddressLabel(wltID string, password []byte, addr cipher.Address, label string) (*wallet.Metadata, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	var err error
+	var m *wallet.Metadata
+	gw.strand("SetWalletAddressLabel", func() {
+		m, err = gw.v.Wallets.SetWalletAddressLabel(wltID, password, addr, label)
+	})
+	return m, err
+}
+
+// SetWalletContact adds or changes a contact in the address book of a wallet
+func (gw *Gateway) SetWalletContact(wltID string, password []byte, name string, addr cipher.Address) (*wallet.Metadata, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	var err error
+	var m *wallet.Metadata
+	gw.strand("SetWalletContact", func() {
+		m, err = gw.v.Wallets.SetWalletContact(wltID, password, name, addr)
+	})
+	return m, err
+}
+
+// DeleteWalletContact removes a contact from the address book of a wallet
+func (gw *Gateway) DeleteWalletContact(wltID string, password []byte, name string) (*wallet.Metadata, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	var err error
+	var m *wallet.Metadata
+	gw.strand("DeleteWalletContact", func() {
+		m, err = gw.v.Wallets.DeleteWalletContact(wltID, password, name)
+	})
+	return m, err
+}
+
 // GetWalletBalance returns balance pairs of specific wallet
 func (gw *Gateway) GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalance, error) {
 	var addressBalances wallet.AddressBalance
@@ -1035,6 +1212,28 @@ func (gw *Gateway) GetWalletUnconfirmedTxns(wltID string) ([]visor.UnconfirmedTx
 	return txns, err
 }
 
+// GetWalletLedger returns the ledger of the addresses of a wallet, with start <= time <= end.
+// If end is 0, there is no upper bound.
+func (gw *Gateway) GetWalletLedger(wltID string, start, end uint64) ([]visor.LedgerEntry, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	var entries []visor.LedgerEntry
+	var err error
+	gw.strand("GetWalletLedger", func() {
+		var addrs []cipher.Address
+		addrs, err = gw.v.Wallets.GetAddresses(wltID)
+		if err != nil {
+			return
+		}
+
+		entries, err = gw.v.GetLedger(addrs, start, end)
+	})
+
+	return entries, err
+}
+
 // ReloadWallets reloads all wallets
 func (gw *Gateway) ReloadWallets() error {
 	if !gw.Config.EnableWalletAPI {
@@ -1076,6 +1275,35 @@ func (gw *Gateway) GetWalletSeed(id string, password []byte) (string, error) {
 	return seed, err
 }
 
+// ExportWallet exports the wallet of given id, with its notes, labels and contacts, to a bundle encrypted with password.
+// walletPassword is required to export an encrypted wallet to a watch-only bundle.
+func (gw *Gateway) ExportWallet(wltID string, password, walletPassword []byte, watchOnly bool) (*wallet.EncryptedBundle, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	var eb *wallet.EncryptedBundle
+	var err error
+	gw.strand("ExportWallet", func() {
+		eb, err = gw.v.Wallets.ExportWallet(wltID, password, walletPassword, watchOnly)
+	})
+	return eb, err
+}
+
+// ImportWallet restores a wallet from a bundle encrypted with password
+func (gw *Gateway) ImportWallet(eb wallet.EncryptedBundle, password []byte) (*wallet.Wallet, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	var w *wallet.Wallet
+	var err error
+	gw.strand("ImportWallet", func() {
+		w, err = gw.v.Wallets.ImportWallet(eb, password)
+	})
+	return w, err
+}
+
 // IsWalletAPIEnabled returns if all wallet related apis are disabled
 func (gw *Gateway) IsWalletAPIEnabled() bool {
 	return gw.Config.EnableWalletAPI
@@ -1090,38 +1318,78 @@ func (gw *Gateway) GetBuildInfo() visor.BuildInfo {
 	return bi
 }
 
-// GetRichlist returns rich list as desc order.
-func (gw *Gateway) GetRichlist(includeDistribution bool) (visor.Richlist, error) {
-	rbOuts, err := gw.GetUnspentOutputs()
-	if err != nil {
-		return nil, err
-	}
+// GetRichlist returns the top n of the rich list as desc order. If n is 0, returns the whole rich list.
+func (gw *Gateway) GetRichlist(includeDistribution bool, n int) (visor.Richlist, error) {
+	var richlist visor.Richlist
+	var err error
+	gw.strand("GetRichlist", func() {
+		richlist, err = gw.v.GetRichlist(includeDistribution, n)
+	})
+	return richlist, err
+}
 
-	allAccounts, err := rbOuts.AggregateUnspentOutputs()
-	if err != nil {
-		return nil, err
-	}
+// GetRichlistSummary returns the top n of the rich list as desc order, with the total coins and count of its addresses
+func (gw *Gateway) GetRichlistSummary(includeDistribution bool, n int) (*visor.RichlistSummary, error) {
+	var summary *visor.RichlistSummary
+	var err error
+	gw.strand("GetRichlistSummary", func() {
+		summary, err = gw.v.GetRichlistSummary(includeDistribution, n)
+	})
+	return summary, err
+}
 
-	lockedAddrs := visor.GetLockedDistributionAddresses()
-	addrsMap := make(map[string]struct{}, len(lockedAddrs))
-	for _, a := range lockedAddrs {
-		addrsMap[a] = struct{}{}
-	}
+// GetCoinSupply returns the coin supply
+func (gw *Gateway) GetCoinSupply() (*visor.CoinSupply, error) {
+	var supply *visor.CoinSupply
+	var err error
+	gw.strand("GetCoinSupply", func() {
+		supply, err = gw.v.GetCoinSupply()
+	})
+	return supply, err
+}
 
-	richlist, err := visor.NewRichlist(allAccounts, addrsMap)
-	if err != nil {
-		return nil, err
-	}
+// GetSupplySnapshots returns the daily coin supply snapshots between start and end
+func (gw *Gateway) GetSupplySnapshots(start, end uint64) ([]visor.SupplySnapshot, error) {
+	var snapshots []visor.SupplySnapshot
+	var err error
+	gw.strand("GetSupplySnapshots", func() {
+		snapshots, err = gw.v.GetSupplySnapshots(start, end)
+	})
+	return snapshots, err
+}
 
-	if !includeDistribution {
-		unlockedAddrs := visor.GetUnlockedDistributionAddresses()
-		for _, a := range unlockedAddrs {
-			addrsMap[a] = struct{}{}
-		}
-		richlist = richlist.FilterAddresses(addrsMap)
+// GetKeyRotations returns the key rotations of the blockchain signing keys, ordered by seq
+func (gw *Gateway) GetKeyRotations() ([]coin.KeyRotation, error) {
+	var rotations []coin.KeyRotation
+	var err error
+	gw.strand("GetKeyRotations", func() {
+		rotations, err = gw.v.GetKeyRotations()
+	})
+	return rotations, err
+}
+
+// InjectBroadcastKeyRotation adds a key rotation of the blockchain signing keys and broadcasts it
+func (gw *Gateway) InjectBroadcastKeyRotation(r coin.KeyRotation) error {
+	var err error
+	gw.strand("InjectBroadcastKeyRotation", func() {
+		err = gw.d.InjectBroadcastKeyRotation(r)
+	})
+	return err
+}
+
+// CreateAndPublishBlock creates a block from the unconfirmed transactions and broadcasts it.
+// Only a master node can create blocks.
+func (gw *Gateway) CreateAndPublishBlock() (*coin.SignedBlock, error) {
+	if !gw.v.Config.IsMaster {
+		return nil, errors.New("Only a master node can create blocks")
 	}
 
-	return richlist, nil
+	var sb *coin.SignedBlock
+	var err error
+	gw.strand("CreateAndPublishBlock", func() {
+		sb, err = gw.d.CreateAndPublishBlock()
+	})
+	return sb, err
 }
 
 // GetAddressCount returns count number of unique address with uxouts > 0.
@@ -450,3 +450,18 @@ func TestGateway_CreateTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestGateway_WithRequestID(t *testing.T) {
+	gw := &Gateway{
+		Config: GatewayConfig{
+			EnableWalletAPI: true,
+		},
+	}
+
+	rgw := gw.WithRequestID("abc")
+	require.Equal(t, "abc", rgw.requestID)
+	require.Equal(t, gw.Config, rgw.Config)
+	require.Empty(t, gw.requestID)
+	require.Equal(t, logger, gw.log())
+	require.NotEqual(t, logger, rgw.log())
+}
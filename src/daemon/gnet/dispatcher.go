@@ -8,6 +8,18 @@ import (
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/util/metrics"
+)
+
+var (
+	messagesSent = metrics.NewCounterVec("skycoin_gnet_messages_sent_total",
+		"Number of messages sent to peers, by message type", "type")
+	bytesSent = metrics.NewCounterVec("skycoin_gnet_bytes_sent_total",
+		"Number of bytes sent to peers, including the length prefix, by message type", "type")
+	messagesReceived = metrics.NewCounterVec("skycoin_gnet_messages_received_total",
+		"Number of messages received from peers, by message type", "type")
+	bytesReceived = metrics.NewCounterVec("skycoin_gnet_bytes_received_total",
+		"Number of bytes received from peers, including the length prefix, by message type", "type")
 )
 
 // SendResult result of a single message send
@@ -28,7 +40,15 @@ func newSendResult(addr string, m Message, err error) SendResult {
 // Serializes a Message over a net.Conn
 func sendMessage(conn net.Conn, msg Message, timeout time.Duration) error {
 	m := EncodeMessage(msg)
-	return sendByteMessage(conn, m, timeout)
+	if err := sendByteMessage(conn, m, timeout); err != nil {
+		return err
+	}
+
+	msgType := string(m[messageLengthSize : messageLengthSize+messagePrefixLength])
+	messagesSent.WithLabelValues(msgType).Inc()
+	bytesSent.WithLabelValues(msgType).Add(float64(len(m)))
+
+	return nil
 }
 
 // Event handler that is called after a Connection sends a complete message
@@ -38,12 +58,16 @@ func convertToMessage(id int, msg []byte, debugPrint bool) (Message, error) {
 		return nil, errors.New("Not enough data to read msg id")
 	}
 	copy(msgID[:], msg[:len(msgID)])
+	size := len(msg) + messageLengthSize
 	msg = msg[len(msgID):]
 	t, succ := MessageIDReverseMap[msgID]
 	if !succ {
 		return nil, fmt.Errorf("Unknown message %s received", string(msgID[:]))
 	}
 
+	messagesReceived.WithLabelValues(string(msgID[:])).Inc()
+	bytesReceived.WithLabelValues(string(msgID[:])).Add(float64(size))
+
 	if debugPrint {
 		logger.Debugf("convertToMessage for connection %d, message type %v", id, t)
 	}
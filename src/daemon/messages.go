@@ -6,14 +6,17 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"reflect"
 	"strings"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/consensus"
 	"github.com/skycoin/skycoin/src/daemon/gnet"
 	"github.com/skycoin/skycoin/src/daemon/pex"
 	"github.com/skycoin/skycoin/src/util/iputil"
 	"github.com/skycoin/skycoin/src/util/utc"
+	"github.com/skycoin/skycoin/src/visor"
 )
 
 // Message represent a packet to be serialized over the network by
@@ -55,6 +58,9 @@ func getMessageConfigs() []MessageConfig {
 		NewMessageConfig("GETT", GetTxnsMessage{}),
 		NewMessageConfig("GIVT", GiveTxnsMessage{}),
 		NewMessageConfig("ANNT", AnnounceTxnsMessage{}),
+		NewMessageConfig("PRPB", ProposeBlockMessage{}),
+		NewMessageConfig("VOTB", BlockVoteMessage{}),
+		NewMessageConfig("GIVK", GiveKeyRotationsMessage{}),
 	}
 }
 
@@ -71,9 +77,13 @@ func NewMessagesConfig() MessagesConfig {
 	}
 }
 
-// Register registers our Messages with gnet
+// Register registers our Messages with gnet.
+// Messages that are already registered are skipped, so that several daemons can run in one process.
 func (msc *MessagesConfig) Register() {
 	for _, mc := range msc.Messages {
+		if gnet.MessageIDReverseMap[mc.Prefix] == reflect.TypeOf(mc.Message) {
+			continue
+		}
 		gnet.RegisterMessage(mc.Prefix, mc.Message)
 	}
 	gnet.VerifyMessages()
@@ -443,6 +453,11 @@ func (gbm *GetBlocksMessage) Process(d *Daemon) {
 
 	logger.Debugf("Got %d blocks since %d", len(blocks), gbm.LastBlock)
 
+	// Send the key rotations of these blocks first, the peer can't verify the blocks signed by rotated keys without them
+	if err := d.sendKeyRotations(gbm.c.Addr, gbm.LastBlock+1, blocks[len(blocks)-1].Seq()); err != nil {
+		logger.Errorf("Send GiveKeyRotationsMessage to %s failed: %v", gbm.c.Addr, err)
+	}
+
 	m := NewGiveBlocksMessage(blocks)
 	if err := d.Pool.Pool.SendMessage(gbm.c.Addr, m); err != nil {
 		logger.Errorf("Send GiveBlocksMessage to %s failed: %v", gbm.c.Addr, err)
@@ -453,12 +468,27 @@ func (gbm *GetBlocksMessage) Process(d *Daemon) {
 type GiveBlocksMessage struct {
 	Blocks []coin.SignedBlock
 	c      *gnet.MessageContext `enc:"-"`
+	// The block maker signatures of each block, if any block has them.
+	// It is the last field so that nodes that don't know it ignore it.
+	MakerSigs [][]cipher.Sig `enc:",omitempty"`
 }
 
 // NewGiveBlocksMessage creates GiveBlocksMessage
 func NewGiveBlocksMessage(blocks []coin.SignedBlock) *GiveBlocksMessage {
+	var makerSigs [][]cipher.Sig
+	for i, b := range blocks {
+		if len(b.MakerSigs) == 0 {
+			continue
+		}
+		if makerSigs == nil {
+			makerSigs = make([][]cipher.Sig, len(blocks))
+		}
+		makerSigs[i] = b.MakerSigs
+	}
+
 	return &GiveBlocksMessage{
-		Blocks: blocks,
+		Blocks:    blocks,
+		MakerSigs: makerSigs,
 	}
 }
 
@@ -489,7 +519,16 @@ func (gbm *GiveBlocksMessage) Process(d *Daemon) {
 		return
 	}
 
-	for _, b := range gbm.Blocks {
+	if len(gbm.MakerSigs) != 0 && len(gbm.MakerSigs) != len(gbm.Blocks) {
+		logger.Errorf("GiveBlocksMessage from %s has block maker sigs for %d of %d blocks", gbm.c.Addr, len(gbm.MakerSigs), len(gbm.Blocks))
+		return
+	}
+
+	for i, b := range gbm.Blocks {
+		if len(gbm.MakerSigs) != 0 {
+			b.MakerSigs = gbm.MakerSigs[i]
+		}
+
 		// To minimize waste when receiving multiple responses from peers
 		// we only break out of the loop if the block itself is invalid.
 		// E.g. if we request 20 blocks since 0 from 2 peers, and one peer
@@ -736,3 +775,137 @@ func (gtm *GiveTxnsMessage) Process(d *Daemon) {
 		d.Pool.Pool.BroadcastMessage(m)
 	}
 }
+
+// ProposeBlockMessage sends a block proposed by a block maker, when blocks are produced
+// by a quorum of block makers. The block makers vote for the block with BlockVoteMessage.
+type ProposeBlockMessage struct {
+	Block coin.SignedBlock
+	c     *gnet.MessageContext `enc:"-"`
+}
+
+// NewProposeBlockMessage creates ProposeBlockMessage
+func NewProposeBlockMessage(b coin.SignedBlock) *ProposeBlockMessage {
+	return &ProposeBlockMessage{
+		Block: b,
+	}
+}
+
+// Handle handles message
+func (pbm *ProposeBlockMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	pbm.c = mc
+	return daemon.(*Daemon).recordMessageEvent(pbm, mc)
+}
+
+// Process process message
+func (pbm *ProposeBlockMessage) Process(d *Daemon) {
+	if d.Config.DisableNetworking {
+		return
+	}
+
+	if d.consensus == nil {
+		logger.Debugf("Ignoring ProposeBlockMessage from %s, block makers are not configured", pbm.c.Addr)
+		return
+	}
+
+	switch err := d.consensus.onProposal(pbm.Block); err {
+	case nil, ErrBlockProposalKnown:
+	default:
+		logger.Warningf("Block proposal %d from %s rejected: %v", pbm.Block.Seq(), pbm.c.Addr, err)
+	}
+}
+
+// BlockVoteMessage sends a block maker's vote for a proposed block. The vote is the
+// block maker's signature of the block header hash.
+type BlockVoteMessage struct {
+	Seq  uint64
+	Hash cipher.SHA256
+	Sig  cipher.Sig
+	c    *gnet.MessageContext `enc:"-"`
+}
+
+// NewBlockVoteMessage creates BlockVoteMessage
+func NewBlockVoteMessage(b consensus.BlockBase) *BlockVoteMessage {
+	return &BlockVoteMessage{
+		Seq:  b.Seqno,
+		Hash: b.Hash,
+		Sig:  b.Sig,
+	}
+}
+
+// Handle handles message
+func (bvm *BlockVoteMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	bvm.c = mc
+	return daemon.(*Daemon).recordMessageEvent(bvm, mc)
+}
+
+// Process process message
+func (bvm *BlockVoteMessage) Process(d *Daemon) {
+	if d.Config.DisableNetworking {
+		return
+	}
+
+	if d.consensus == nil {
+		logger.Debugf("Ignoring BlockVoteMessage from %s, block makers are not configured", bvm.c.Addr)
+		return
+	}
+
+	if err := d.consensus.onVote(consensus.BlockBase{
+		Sig:   bvm.Sig,
+		Hash:  bvm.Hash,
+		Seqno: bvm.Seq,
+	}); err != nil {
+		logger.Warningf("Block vote %d from %s rejected: %v", bvm.Seq, bvm.c.Addr, err)
+	}
+}
+
+// GiveKeyRotationsMessage sends key rotations of the blockchain signing keys.
+// It is sent before the blocks in response to GetBlocksMessage, and broadcast when a new key rotation is added.
+type GiveKeyRotationsMessage struct {
+	Rotations []coin.KeyRotation
+	c         *gnet.MessageContext `enc:"-"`
+}
+
+// NewGiveKeyRotationsMessage creates GiveKeyRotationsMessage
+func NewGiveKeyRotationsMessage(rotations []coin.KeyRotation) *GiveKeyRotationsMessage {
+	return &GiveKeyRotationsMessage{
+		Rotations: rotations,
+	}
+}
+
+// Handle handles message
+func (gkm *GiveKeyRotationsMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	gkm.c = mc
+	return daemon.(*Daemon).recordMessageEvent(gkm, mc)
+}
+
+// Process process message
+func (gkm *GiveKeyRotationsMessage) Process(d *Daemon) {
+	if d.Config.DisableNetworking {
+		return
+	}
+
+	var added []coin.KeyRotation
+	for _, r := range gkm.Rotations {
+		err := d.Visor.AddKeyRotation(r)
+		if _, ok := err.(visor.ErrKeyRotationConflict); ok {
+			logger.Errorf("Key rotation from %s: %v. A key of the blockchain authority is compromised, add the hash of the genuine key rotation to -trusted-key-rotations", gkm.c.Addr, err)
+			continue
+		}
+
+		switch err {
+		case nil:
+			logger.Infof("Added key rotation at seq %d from %s", r.Seq, gkm.c.Addr)
+			added = append(added, r)
+		case visor.ErrKeyRotationKnown:
+		default:
+			logger.Warningf("Key rotation at seq %d from %s rejected: %v", r.Seq, gkm.c.Addr, err)
+		}
+	}
+
+	// Relay the new key rotations
+	if len(added) != 0 {
+		if err := d.broadcastKeyRotations(added); err != nil {
+			logger.Errorf("Broadcast GiveKeyRotationsMessage failed: %v", err)
+		}
+	}
+}
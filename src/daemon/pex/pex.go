@@ -228,7 +228,7 @@ func New(cfg Config, defaultConns []string) (*Pex, error) {
 			logger.Critical().Errorf("add peer failed:%v", err)
 			continue
 		}
-		if err := pex.SetTrusted(addr); err != nil {
+		if err := pex.SetTrusted(addr, true); err != nil {
 			logger.Critical().Errorf("pex.SetTrust failed: %v", err)
 		}
 	}
@@ -422,7 +422,7 @@ func (px *Pex) SetPrivate(addr string, private bool) error {
 }
 
 // SetTrusted updates peer's trusted value
-func (px *Pex) SetTrusted(addr string) error {
+func (px *Pex) SetTrusted(addr string, trusted bool) error {
 	px.Lock()
 	defer px.Unlock()
 
@@ -432,7 +432,7 @@ func (px *Pex) SetTrusted(addr string) error {
 		return ErrInvalidAddress
 	}
 
-	return px.peerlist.setTrusted(cleanAddr, true)
+	return px.peerlist.setTrusted(cleanAddr, trusted)
 }
 
 // SetHasIncomingPort sets if the peer has public port
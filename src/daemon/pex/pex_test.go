@@ -1178,18 +1178,28 @@ func TestPexSetTrusted(t *testing.T) {
 		name      string
 		initPeers []Peer
 		peer      string
+		trusted   bool
 		err       error
 	}{
 		{
 			"set trust true",
 			[]Peer{*NewPeer(testPeers[0])},
 			testPeers[0],
+			true,
+			nil,
+		},
+		{
+			"set trust false",
+			[]Peer{{Addr: testPeers[0], Trusted: true}},
+			testPeers[0],
+			false,
 			nil,
 		},
 		{
 			"set failed",
 			[]Peer{*NewPeer(testPeers[1])},
 			testPeers[0],
+			true,
 			fmt.Errorf("set peer.Trusted failed: %v does not exist in peer list", testPeers[0]),
 		},
 	}
@@ -1203,7 +1213,7 @@ func TestPexSetTrusted(t *testing.T) {
 			// init peer
 			pex.peerlist.setPeers(tc.initPeers)
 
-			err := pex.SetTrusted(tc.peer)
+			err := pex.SetTrusted(tc.peer, tc.trusted)
 			require.Equal(t, tc.err, err)
 			if err != nil {
 				return
@@ -1211,7 +1221,7 @@ func TestPexSetTrusted(t *testing.T) {
 
 			p, ok := pex.peerlist.peers[tc.peer]
 			require.True(t, ok)
-			require.True(t, p.Trusted)
+			require.Equal(t, tc.trusted, p.Trusted)
 		})
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/util/metrics"
 )
 
 const (
@@ -16,6 +17,16 @@ const (
 var (
 	// Debug enables debug logging
 	Debug = false
+
+	queueDepth = metrics.NewHistogram("skycoin_strand_queue_depth",
+		"Number of requests already queued in the strand request channel when a request is queued",
+		[]float64{0, 1, 2, 5, 10, 25, 50, 100, 250})
+	queueWait = metrics.NewHistogram("skycoin_strand_queue_wait_seconds",
+		"Time a request waits in the strand request channel before it is called", metrics.DefaultDurationBuckets)
+	callDuration = metrics.NewHistogramVec("skycoin_strand_call_duration_seconds",
+		"Time spent in stranded calls, by name", metrics.DefaultDurationBuckets, "name")
+	slowCalls = metrics.NewCounterVec("skycoin_strand_slow_calls_total",
+		"Number of stranded calls that took longer than 100ms, by name", "name")
 )
 
 // Request is sent to the channel provided to Strand
@@ -37,6 +48,7 @@ func Strand(logger *logging.Logger, c chan Request, name string, f func() error,
 
 	done := make(chan struct{})
 	var err error
+	var queued time.Time
 
 	req := Request{
 		Name: name,
@@ -47,6 +59,7 @@ func Strand(logger *logging.Logger, c chan Request, name string, f func() error,
 			// logger.Debugf("%s begin", name)
 
 			t := time.Now()
+			queueWait.ObserveDuration(t.Sub(queued))
 
 			// Log function duration at an exponential time interval,
 			// this will notify us of any long running functions to look at.
@@ -85,7 +98,9 @@ func Strand(logger *logging.Logger, c chan Request, name string, f func() error,
 
 			// Notify us if the function call took too long
 			elapsed := time.Now().Sub(t)
+			callDuration.WithLabelValues(name).ObserveDuration(elapsed)
 			if elapsed > logDurationThreshold {
+				slowCalls.WithLabelValues(name).Inc()
 				logger.Warningf("%s took %s", name, elapsed)
 			} else {
 				//logger.Debugf("%s took %s", name, elapsed)
@@ -95,8 +110,11 @@ func Strand(logger *logging.Logger, c chan Request, name string, f func() error,
 		},
 	}
 
+	queueDepth.Observe(float64(len(c)))
+
 	// Log a message if waiting too long to write due to a full queue
 	t := time.Now()
+	queued = t
 loop:
 	for {
 		select {
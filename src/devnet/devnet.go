@@ -0,0 +1,479 @@
+/*
+Package devnet runs a local network of skycoin nodes on a fresh blockchain, for testing.
+
+The genesis keys and distribution addresses are generated deterministically from a seed,
+so a devnet created twice with the same seed has the same genesis block.
+The first node is the master, the other nodes connect to all nodes.
+The nodes run in the process that creates the devnet, or in separate skycoin processes
+started with the run.sh script written to each node's data directory.
+*/
+package devnet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/util/utc"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+var (
+	logger = logging.MustGetLogger("devnet")
+
+	// ErrTimeout is returned when waiting for the nodes times out
+	ErrTimeout = errors.New("Timed out waiting for the devnet nodes")
+)
+
+const (
+	// GenesisWalletFilename is the filename of the master node's wallet that holds
+	// the genesis address and the distribution addresses
+	GenesisWalletFilename = "genesis.wlt"
+
+	// pollRate is how often the nodes are checked while waiting for them
+	pollRate = time.Millisecond * 100
+)
+
+// Config configures a devnet
+type Config struct {
+	// Number of nodes, the first node is the master
+	Nodes int
+	// Seed that the genesis keys and distribution addresses are generated from
+	Seed string
+	// Directory that the nodes' data directories are created in
+	Dir string
+	// Port of the first node, the other nodes use the following ports
+	Port int
+	// Web interface port of the first node, the other nodes use the following ports
+	WebInterfacePort int
+	// Number of coins in the genesis block, in droplets
+	GenesisCoinVolume uint64
+	// Genesis block timestamp
+	GenesisTimestamp uint64
+	// Number of distribution addresses that the genesis coins are distributed to
+	DistributionAddresses int
+}
+
+// NewConfig returns a Config with defaults set
+func NewConfig() Config {
+	return Config{
+		Nodes:                 3,
+		Seed:                  "devnet",
+		Port:                  46000,
+		WebInterfacePort:      46420,
+		GenesisCoinVolume:     100e12,
+		GenesisTimestamp:      1426562704,
+		DistributionAddresses: 100,
+	}
+}
+
+// Verify checks that the config is valid
+func (c Config) Verify() error {
+	if c.Nodes < 1 {
+		return errors.New("Devnet needs at least one node")
+	}
+	if c.Seed == "" {
+		return errors.New("Devnet seed is required")
+	}
+	if c.Dir == "" {
+		return errors.New("Devnet directory is required")
+	}
+	if c.DistributionAddresses < 1 {
+		return errors.New("Devnet needs at least one distribution address")
+	}
+	if c.GenesisCoinVolume < uint64(c.DistributionAddresses)*1e6 {
+		return errors.New("Genesis coin volume is too small for the distribution addresses")
+	}
+
+	return nil
+}
+
+// Genesis is the generated genesis block configuration of a devnet
+type Genesis struct {
+	BlockchainPubkey      cipher.PubKey
+	BlockchainSeckey      cipher.SecKey
+	Address               cipher.Address
+	Signature             cipher.Sig
+	Timestamp             uint64
+	CoinVolume            uint64
+	DistributionAddresses []cipher.Address
+}
+
+// NewGenesis generates the genesis keys and distribution addresses from a seed.
+// The genesis address and the distribution addresses are the addresses of a deterministic wallet
+// with this seed. The blockchain keys are generated from the seed with a " blockchain" suffix.
+func NewGenesis(seed string, coinVolume, timestamp uint64, distributionAddresses int) (*Genesis, error) {
+	pubkey, seckey := cipher.GenerateDeterministicKeyPair([]byte(seed + " blockchain"))
+
+	seckeys := cipher.GenerateDeterministicKeyPairs([]byte(seed), distributionAddresses+1)
+	addrs := make([]cipher.Address, len(seckeys))
+	for i, sk := range seckeys {
+		addrs[i] = cipher.AddressFromSecKey(sk)
+	}
+
+	b, err := coin.NewGenesisBlock(addrs[0], coinVolume, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Genesis{
+		BlockchainPubkey:      pubkey,
+		BlockchainSeckey:      seckey,
+		Address:               addrs[0],
+		Signature:             cipher.SignHash(b.HashHeader(), seckey),
+		Timestamp:             timestamp,
+		CoinVolume:            coinVolume,
+		DistributionAddresses: addrs[1:],
+	}, nil
+}
+
+// readableGenesis is the format of the genesis.json file
+type readableGenesis struct {
+	BlockchainPubkey      string   `json:"blockchain_pubkey"`
+	BlockchainSeckey      string   `json:"blockchain_seckey"`
+	Address               string   `json:"genesis_address"`
+	Signature             string   `json:"genesis_signature"`
+	Timestamp             uint64   `json:"genesis_timestamp"`
+	CoinVolume            uint64   `json:"genesis_coin_volume"`
+	DistributionAddresses []string `json:"distribution_addresses"`
+}
+
+func newReadableGenesis(g Genesis) readableGenesis {
+	addrs := make([]string, len(g.DistributionAddresses))
+	for i, a := range g.DistributionAddresses {
+		addrs[i] = a.String()
+	}
+
+	return readableGenesis{
+		BlockchainPubkey:      g.BlockchainPubkey.Hex(),
+		BlockchainSeckey:      g.BlockchainSeckey.Hex(),
+		Address:               g.Address.String(),
+		Signature:             g.Signature.Hex(),
+		Timestamp:             g.Timestamp,
+		CoinVolume:            g.CoinVolume,
+		DistributionAddresses: addrs,
+	}
+}
+
+// Devnet is a local network of skycoin nodes
+type Devnet struct {
+	Config  Config
+	Genesis *Genesis
+	Nodes   []*Node
+}
+
+// New creates a devnet and writes the genesis configuration and the nodes' run.sh scripts to Config.Dir.
+// The nodes are not started.
+func New(c Config) (*Devnet, error) {
+	if err := c.Verify(); err != nil {
+		return nil, err
+	}
+
+	g, err := NewGenesis(c.Seed, c.GenesisCoinVolume, c.GenesisTimestamp, c.DistributionAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, c.Nodes)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("127.0.0.1:%d", c.Port+i)
+	}
+
+	dn := &Devnet{
+		Config:  c,
+		Genesis: g,
+	}
+
+	for i := 0; i < c.Nodes; i++ {
+		var conns []string
+		for j, a := range addrs {
+			if j != i {
+				conns = append(conns, a)
+			}
+		}
+
+		dn.Nodes = append(dn.Nodes, newNode(NodeConfig{
+			Index:              i,
+			Master:             i == 0,
+			DataDirectory:      filepath.Join(c.Dir, fmt.Sprintf("node%d", i)),
+			Port:               c.Port + i,
+			WebInterfacePort:   c.WebInterfacePort + i,
+			DefaultConnections: conns,
+		}, g))
+	}
+
+	if err := dn.writeConfigs(); err != nil {
+		return nil, err
+	}
+
+	return dn, nil
+}
+
+func (dn *Devnet) writeConfigs() error {
+	if err := os.MkdirAll(dn.Config.Dir, 0700); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(newReadableGenesis(*dn.Genesis), "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dn.Config.Dir, "genesis.json"), b, 0600); err != nil {
+		return err
+	}
+
+	for _, n := range dn.Nodes {
+		if err := n.writeConfig(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Master returns the master node
+func (dn *Devnet) Master() *Node {
+	return dn.Nodes[0]
+}
+
+// Start starts the nodes in this process. On a new devnet, the genesis coins
+// are distributed to the distribution addresses in the first block.
+func (dn *Devnet) Start() error {
+	for _, n := range dn.Nodes {
+		if err := n.Start(); err != nil {
+			dn.Shutdown()
+			return fmt.Errorf("Start node %d failed: %v", n.Config.Index, err)
+		}
+	}
+
+	if err := dn.createGenesisWallet(); err != nil {
+		dn.Shutdown()
+		return err
+	}
+
+	headSeq, err := dn.Master().HeadSeq()
+	if err != nil {
+		dn.Shutdown()
+		return err
+	}
+
+	if headSeq == 0 {
+		if err := dn.distribute(); err != nil {
+			dn.Shutdown()
+			return fmt.Errorf("Distribute genesis coins failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops the nodes running in this process
+func (dn *Devnet) Shutdown() {
+	var wg sync.WaitGroup
+	for _, n := range dn.Nodes {
+		wg.Add(1)
+		go func(n *Node) {
+			defer wg.Done()
+			n.Shutdown()
+		}(n)
+	}
+	wg.Wait()
+}
+
+// createGenesisWallet creates the master node's wallet of the genesis address and the distribution addresses
+func (dn *Devnet) createGenesisWallet() error {
+	gw := dn.Master().Gateway()
+
+	if _, err := gw.GetWallet(GenesisWalletFilename); err == nil {
+		return nil
+	} else if err != wallet.ErrWalletNotExist {
+		return err
+	}
+
+	if _, err := gw.CreateWallet(GenesisWalletFilename, wallet.Options{
+		Label: "genesis",
+		Seed:  dn.Config.Seed,
+	}); err != nil {
+		return err
+	}
+
+	_, err := gw.NewAddresses(GenesisWalletFilename, nil, uint64(len(dn.Genesis.DistributionAddresses)))
+	return err
+}
+
+// distribute sends the genesis coins to the distribution addresses in whole coins, with half of the
+// genesis coin hours shared between them. The wallet does not spend the genesis output because it
+// has no source transaction, so the transaction is made like the skycoin distribution transaction.
+func (dn *Devnet) distribute() error {
+	gw := dn.Master().Gateway()
+
+	outputs, err := gw.GetUnspentOutputs(daemon.FbyAddresses([]string{dn.Genesis.Address.String()}))
+	if err != nil {
+		return err
+	}
+
+	if len(outputs.HeadOutputs) != 1 {
+		return fmt.Errorf("Genesis address has %d outputs, expected 1", len(outputs.HeadOutputs))
+	}
+
+	genesisUx, err := cipher.SHA256FromHex(outputs.HeadOutputs[0].Hash)
+	if err != nil {
+		return err
+	}
+
+	addrs := dn.Genesis.DistributionAddresses
+	coins := dn.Genesis.CoinVolume / uint64(len(addrs))
+	coins -= coins % 1e6
+	hours := dn.Genesis.CoinVolume / 2 / uint64(len(addrs))
+
+	var txn coin.Transaction
+	txn.PushInput(genesisUx)
+	for _, a := range addrs {
+		txn.PushOutput(a, coins, hours)
+	}
+	if change := dn.Genesis.CoinVolume - coins*uint64(len(addrs)); change > 0 {
+		txn.PushOutput(dn.Genesis.Address, change, 0)
+	}
+
+	seckeys := cipher.GenerateDeterministicKeyPairs([]byte(dn.Config.Seed), 1)
+	txn.SignInputs(seckeys)
+	txn.UpdateHeader()
+
+	if err := gw.InjectBroadcastTransaction(txn); err != nil {
+		// The transaction was injected but not broadcast, e.g. a devnet of one node has no connections
+		if tx, getErr := gw.GetTransaction(txn.Hash()); getErr != nil || tx == nil {
+			return err
+		}
+		logger.Warningf("Broadcast distribution transaction failed: %v", err)
+	}
+
+	_, err = dn.MineBlock()
+	return err
+}
+
+// MineBlock creates a block on the master node from its unconfirmed transactions and broadcasts it.
+// Block times must increase, so it waits for the next second after the head block's time if necessary.
+func (dn *Devnet) MineBlock() (*coin.SignedBlock, error) {
+	gw := dn.Master().Gateway()
+
+	bcm, err := gw.GetBlockchainMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	for uint64(utc.UnixNow()) <= bcm.Head.Time {
+		time.Sleep(pollRate)
+	}
+
+	sb, err := gw.CreateAndPublishBlock()
+	if err != nil && sb != nil {
+		// The block was created but not broadcast, the other nodes request it from the master
+		logger.Warningf("Broadcast block %d failed: %v", sb.Seq(), err)
+		return sb, nil
+	}
+
+	return sb, err
+}
+
+// Fund sends coins from the distribution addresses to an address and mines a block with the transaction
+func (dn *Devnet) Fund(addr cipher.Address, coins uint64) (*coin.Transaction, error) {
+	txn, err := dn.Master().Gateway().Spend(GenesisWalletFilename, nil, coins, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dn.MineBlock(); err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+// FundWallet sends coins to the first address of a wallet of a node and mines a block with the transaction
+func (dn *Devnet) FundWallet(n *Node, wltID string, coins uint64) (*coin.Transaction, error) {
+	w, err := n.Gateway().GetWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(w.Entries) == 0 {
+		return nil, fmt.Errorf("Wallet %s has no addresses", wltID)
+	}
+
+	return dn.Fund(w.Entries[0].Address, coins)
+}
+
+// WaitForConnections waits until every node is connected to at least one other node that reported its height.
+// Only one connection is kept between two nodes, so a node may have incoming connections only.
+func (dn *Devnet) WaitForConnections(timeout time.Duration) error {
+	if len(dn.Nodes) == 1 {
+		return nil
+	}
+
+	return dn.waitFor(timeout, func(n *Node) (bool, error) {
+		p, err := n.Gateway().GetBlockchainProgress()
+		if err != nil {
+			return false, err
+		}
+		return len(p.Peers) > 0, nil
+	})
+}
+
+// WaitForPropagation waits until every node has the master node's head block
+func (dn *Devnet) WaitForPropagation(timeout time.Duration) error {
+	seq, err := dn.Master().HeadSeq()
+	if err != nil {
+		return err
+	}
+
+	return dn.WaitForHeight(seq, timeout)
+}
+
+// WaitForHeight waits until every node has the block with sequence number seq
+func (dn *Devnet) WaitForHeight(seq uint64, timeout time.Duration) error {
+	return dn.waitFor(timeout, func(n *Node) (bool, error) {
+		headSeq, err := n.HeadSeq()
+		if err != nil {
+			return false, err
+		}
+		return headSeq >= seq, nil
+	})
+}
+
+// waitFor polls the nodes until ready returns true for every node
+func (dn *Devnet) waitFor(timeout time.Duration, ready func(*Node) (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		allReady := true
+		for _, n := range dn.Nodes {
+			ok, err := ready(n)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				allReady = false
+				break
+			}
+		}
+
+		if allReady {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return ErrTimeout
+		}
+
+		time.Sleep(pollRate)
+	}
+}
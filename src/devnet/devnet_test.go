@@ -0,0 +1,177 @@
+package devnet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestNewGenesis(t *testing.T) {
+	g, err := NewGenesis("devnet", 100e12, 1426562704, 10)
+	require.NoError(t, err)
+	require.Len(t, g.DistributionAddresses, 10)
+	require.Equal(t, g.BlockchainPubkey, cipher.PubKeyFromSecKey(g.BlockchainSeckey))
+
+	// The genesis address and the distribution addresses are the addresses of a wallet with the seed
+	w, err := wallet.NewWallet("test.wlt", wallet.Options{
+		Seed: "devnet",
+	})
+	require.NoError(t, err)
+	_, err = w.GenerateAddresses(10)
+	require.NoError(t, err)
+	require.Equal(t, g.Address, w.Entries[0].Address)
+	for i, a := range g.DistributionAddresses {
+		require.Equal(t, a, w.Entries[i+1].Address)
+	}
+
+	// The same seed generates the same keys
+	g2, err := NewGenesis("devnet", 100e12, 1426562704, 10)
+	require.NoError(t, err)
+	require.Equal(t, g.BlockchainPubkey, g2.BlockchainPubkey)
+	require.Equal(t, g.Address, g2.Address)
+	require.Equal(t, g.DistributionAddresses, g2.DistributionAddresses)
+
+	g3, err := NewGenesis("devnet2", 100e12, 1426562704, 10)
+	require.NoError(t, err)
+	require.NotEqual(t, g.BlockchainPubkey, g3.BlockchainPubkey)
+	require.NotEqual(t, g.Address, g3.Address)
+}
+
+func TestNewWritesConfigs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devnet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	c.Dir = dir
+	dn, err := New(c)
+	require.NoError(t, err)
+	require.Len(t, dn.Nodes, 3)
+	require.True(t, dn.Master().Config.Master)
+
+	_, err = os.Stat(filepath.Join(dir, "genesis.json"))
+	require.NoError(t, err)
+
+	for i, n := range dn.Nodes {
+		require.Equal(t, c.Port+i, n.Config.Port)
+		require.Equal(t, c.WebInterfacePort+i, n.Config.WebInterfacePort)
+		require.Len(t, n.Config.DefaultConnections, 2)
+		require.NotContains(t, n.Config.DefaultConnections, n.Addr())
+
+		b, err := ioutil.ReadFile(filepath.Join(n.Config.DataDirectory, "run.sh"))
+		require.NoError(t, err)
+		require.Contains(t, string(b), "-genesis-address="+dn.Genesis.Address.String())
+		require.Equal(t, n.Config.Master, contains(n.Args(), "-master"))
+	}
+
+	c.Dir = ""
+	_, err = New(c)
+	require.Equal(t, "Devnet directory is required", err.Error())
+}
+
+func TestDevnet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping devnet test in short mode")
+	}
+
+	dir, err := ioutil.TempDir("", "devnet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	c.Dir = dir
+	c.Port = 46100
+	c.WebInterfacePort = 46520
+	c.DistributionAddresses = 10
+
+	dn, err := New(c)
+	require.NoError(t, err)
+
+	require.NoError(t, dn.Start())
+	defer dn.Shutdown()
+
+	require.NoError(t, dn.WaitForConnections(time.Second*30))
+
+	// The genesis coins were distributed in the first block
+	require.NoError(t, dn.WaitForHeight(1, time.Second*30))
+
+	// Fund a wallet of another node
+	n := dn.Nodes[2]
+	w, err := n.Gateway().CreateWallet("", wallet.Options{
+		Seed: "devnet test wallet",
+	})
+	require.NoError(t, err)
+
+	txn, err := dn.FundWallet(n, w.Filename(), 10e6)
+	require.NoError(t, err)
+
+	require.NoError(t, dn.WaitForPropagation(time.Second*30))
+
+	for _, n := range dn.Nodes {
+		seq, err := n.HeadSeq()
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), seq)
+
+		_, err = n.Gateway().GetTransaction(txn.Hash())
+		require.NoError(t, err)
+	}
+
+	balance, _, err := n.Gateway().GetWalletBalance(w.Filename())
+	require.NoError(t, err)
+	require.Equal(t, uint64(10e6), balance.Confirmed.Coins)
+
+	// The API client of a node works
+	bcm, err := n.Client().BlockchainMetadata()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), bcm.Head.BkSeq)
+}
+
+func TestDevnetSingleNode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping devnet test in short mode")
+	}
+
+	dir, err := ioutil.TempDir("", "devnet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	c.Nodes = 1
+	c.Dir = dir
+	c.Port = 46200
+	c.WebInterfacePort = 46620
+	c.DistributionAddresses = 5
+
+	dn, err := New(c)
+	require.NoError(t, err)
+
+	// The genesis coins are distributed without connections to broadcast to
+	require.NoError(t, dn.Start())
+	defer dn.Shutdown()
+
+	seq, err := dn.Master().HeadSeq()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), seq)
+
+	balances, err := dn.Master().Gateway().GetBalanceOfAddrs(dn.Genesis.DistributionAddresses)
+	require.NoError(t, err)
+	for _, b := range balances {
+		require.Equal(t, c.GenesisCoinVolume/5, b.Confirmed.Coins)
+	}
+}
+
+func contains(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
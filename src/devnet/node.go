@@ -0,0 +1,240 @@
+package devnet
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// NodeConfig configures a devnet node
+type NodeConfig struct {
+	// Index of the node in the devnet
+	Index int
+	// Run the node as the blockchain master
+	Master bool
+	// Directory that the node's database, wallets and peers are stored in
+	DataDirectory string
+	// Port for connections to other nodes
+	Port int
+	// Port of the web interface
+	WebInterfacePort int
+	// Addresses of the other nodes
+	DefaultConnections []string
+}
+
+// Node is a devnet node
+type Node struct {
+	Config NodeConfig
+
+	genesis *Genesis
+
+	db     *dbutil.DB
+	daemon *daemon.Daemon
+	server *api.Server
+	wg     sync.WaitGroup
+}
+
+func newNode(c NodeConfig, g *Genesis) *Node {
+	return &Node{
+		Config:  c,
+		genesis: g,
+	}
+}
+
+// Addr returns the address for connections to the node
+func (n *Node) Addr() string {
+	return fmt.Sprintf("127.0.0.1:%d", n.Config.Port)
+}
+
+// WebInterfaceAddr returns the address of the node's web interface
+func (n *Node) WebInterfaceAddr() string {
+	return fmt.Sprintf("127.0.0.1:%d", n.Config.WebInterfacePort)
+}
+
+// Gateway returns the node's daemon gateway. The node must be running in this process.
+func (n *Node) Gateway() *daemon.Gateway {
+	return n.daemon.Gateway
+}
+
+// Client returns an API client for the node's web interface
+func (n *Node) Client() *api.Client {
+	return api.NewClient("http://" + n.WebInterfaceAddr())
+}
+
+// HeadSeq returns the sequence number of the node's head block
+func (n *Node) HeadSeq() (uint64, error) {
+	p, err := n.Gateway().GetBlockchainProgress()
+	if err != nil {
+		return 0, err
+	}
+	return p.Current, nil
+}
+
+// Args returns the command line options of the skycoin node for this node
+func (n *Node) Args() []string {
+	args := []string{
+		"-data-dir=" + n.Config.DataDirectory,
+		fmt.Sprintf("-port=%d", n.Config.Port),
+		fmt.Sprintf("-web-interface-port=%d", n.Config.WebInterfacePort),
+		"-localhost-only",
+		"-download-peerlist=false",
+		"-launch-browser=false",
+		"-enable-wallet-api",
+		"-connection-rate=1s",
+		fmt.Sprintf("-max-default-peer-outgoing-connections=%d", len(n.Config.DefaultConnections)),
+		"-default-connections=" + strings.Join(n.Config.DefaultConnections, ","),
+		"-genesis-address=" + n.genesis.Address.String(),
+		"-genesis-signature=" + n.genesis.Signature.Hex(),
+		fmt.Sprintf("-genesis-timestamp=%d", n.genesis.Timestamp),
+		fmt.Sprintf("-genesis-coin-volume=%d", n.genesis.CoinVolume),
+		"-master-public-key=" + n.genesis.BlockchainPubkey.Hex(),
+	}
+
+	if n.Config.Master {
+		args = append(args, "-master", "-master-secret-key="+n.genesis.BlockchainSeckey.Hex())
+	}
+
+	return args
+}
+
+// writeConfig writes a run.sh script to the node's data directory, that runs the node in a
+// separate skycoin process. The skycoin binary is set with the SKYCOIN environment variable.
+func (n *Node) writeConfig() error {
+	if err := os.MkdirAll(n.Config.DataDirectory, 0700); err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf("#!/usr/bin/env bash\n\nexec \"${SKYCOIN:-skycoin}\" \\\n    %s \\\n    \"$@\"\n",
+		strings.Join(n.Args(), " \\\n    "))
+
+	return ioutil.WriteFile(filepath.Join(n.Config.DataDirectory, "run.sh"), []byte(script), 0700)
+}
+
+func (n *Node) daemonConfig() daemon.Config {
+	dc := daemon.NewConfig()
+
+	for _, c := range n.Config.DefaultConnections {
+		dc.Pool.DefaultPeerConnections[c] = struct{}{}
+	}
+	dc.Pool.MaxDefaultPeerOutgoingConnections = len(n.Config.DefaultConnections)
+
+	dc.Pex.DataDirectory = n.Config.DataDirectory
+	dc.Pex.DownloadPeerList = false
+	dc.Daemon.Port = n.Config.Port
+	dc.Daemon.LocalhostOnly = true
+	dc.Daemon.DataDirectory = n.Config.DataDirectory
+	dc.Daemon.LogPings = false
+	// All nodes have the same IP, allow an incoming and an outgoing connection with every other node
+	dc.Daemon.IPCountsMax = 2 * len(n.Config.DefaultConnections)
+	dc.Daemon.OutgoingRate = time.Second
+	dc.Daemon.BlocksRequestRate = time.Second
+	dc.Daemon.BlocksAnnounceRate = time.Second
+
+	dc.Visor.IsMaster = n.Config.Master
+	dc.Visor.Arbitrating = n.Config.Master
+	dc.Visor.BlockchainPubkey = n.genesis.BlockchainPubkey
+	if n.Config.Master {
+		dc.Visor.BlockchainSeckey = n.genesis.BlockchainSeckey
+	}
+
+	dc.Visor.GenesisAddress = n.genesis.Address
+	dc.Visor.GenesisSignature = n.genesis.Signature
+	dc.Visor.GenesisTimestamp = n.genesis.Timestamp
+	dc.Visor.GenesisCoinVolume = n.genesis.CoinVolume
+	dc.Visor.DBPath = filepath.Join(n.Config.DataDirectory, "data.db")
+	dc.Visor.EnableWalletAPI = true
+	dc.Visor.WalletDirectory = filepath.Join(n.Config.DataDirectory, "wallets")
+	dc.Visor.WalletCryptoType = wallet.CryptoTypeScryptChacha20poly1305
+
+	dc.Gateway.EnableWalletAPI = true
+
+	return dc
+}
+
+// Start opens the node's database and starts its daemon and web interface in this process
+func (n *Node) Start() error {
+	if n.daemon != nil {
+		return errors.New("Node is already running")
+	}
+
+	dc := n.daemonConfig()
+
+	if err := os.MkdirAll(dc.Visor.WalletDirectory, 0700); err != nil {
+		return err
+	}
+
+	db, err := visor.OpenDB(dc.Visor.DBPath, false)
+	if err != nil {
+		return err
+	}
+
+	d, err := daemon.NewDaemon(dc, db, n.Config.DefaultConnections)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	server, err := api.Create(n.WebInterfaceAddr(), api.Config{
+		EnableWalletAPI: true,
+		EnableJSON20RPC: true,
+		DisableCSRF:     true,
+		ReadTimeout:     time.Second * 10,
+		WriteTimeout:    time.Second * 60,
+		IdleTimeout:     time.Second * 120,
+	}, d.Gateway)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	n.db = db
+	n.daemon = d
+	n.server = server
+
+	n.wg.Add(2)
+	go func() {
+		defer n.wg.Done()
+		if err := d.Run(); err != nil {
+			logger.Errorf("Node %d daemon stopped: %v", n.Config.Index, err)
+		}
+	}()
+
+	go func() {
+		defer n.wg.Done()
+		if err := server.Serve(); err != nil {
+			logger.Errorf("Node %d web interface stopped: %v", n.Config.Index, err)
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops the node's daemon and web interface and closes its database
+func (n *Node) Shutdown() {
+	if n.daemon == nil {
+		return
+	}
+
+	n.server.Shutdown()
+	n.daemon.Shutdown()
+	n.wg.Wait()
+
+	if err := n.db.Close(); err != nil {
+		logger.WithError(err).Errorf("Failed to close node %d DB", n.Config.Index)
+	}
+
+	n.db = nil
+	n.daemon = nil
+	n.server = nil
+}
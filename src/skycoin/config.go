@@ -1,15 +1,17 @@
 package skycoin
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"log"
-
+	"github.com/skycoin/skycoin/src/api"
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin/src/util/logging"
 	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/wallet"
 )
@@ -48,6 +50,22 @@ type NodeConfig struct {
 	EnableSeedAPI bool
 	// Enable unversioned API endpoints (without the /api/v1 prefix)
 	EnableUnversionedAPI bool
+	// Require scoped API tokens for the API endpoints
+	EnableAPIAuth bool
+	// File that the hashed API tokens are stored in
+	APITokensFile string
+	// Requests per second allowed per API client, 0 disables the limit
+	RateLimit float64
+	// Number of requests an API client can make at once
+	RateLimitBurst int
+	// Requests per second allowed per API client to the expensive API endpoints, 0 disables the limit
+	RateLimitExpensive float64
+	// Number of requests an API client can make at once to the expensive API endpoints
+	RateLimitExpensiveBurst int
+	// Maximum number of blocks returned by the block range API endpoints, 0 is unlimited
+	MaxBlockRange uint64
+	// Maximum number of addresses per API request, 0 is unlimited
+	MaxRequestAddresses int
 
 	// Only run on localhost and only connect to others on localhost
 	LocalhostOnly bool
@@ -64,6 +82,14 @@ type NodeConfig struct {
 	OutgoingConnectionsRate time.Duration
 	// PeerlistSize represents the maximum number of peers that the pex would maintain
 	PeerlistSize int
+	// Maximum total size of the unconfirmed transaction pool, in bytes. 0 means no limit.
+	MaxUnconfirmedTxnsSize int
+	// Maximum number of unconfirmed ancestors a transaction in the unconfirmed pool may have
+	MaxUnconfirmedChainDepth int
+	// Fee per kB that a transaction replacing unconfirmed transactions must burn in addition to their fees
+	MinReplacementFeeKB uint64
+	// Unconfirmed transactions received longer ago than this are removed from the pool. 0 means they never expire.
+	UnconfirmedTxnsMaxAge time.Duration
 	// Wallet Address Version
 	//AddressVersion string
 	// Remote web interface
@@ -74,6 +100,14 @@ type NodeConfig struct {
 	WebInterfaceKey   string
 	WebInterfaceHTTPS bool
 
+	// gRPC interface
+	GRPCInterface     bool
+	GRPCInterfacePort int
+	GRPCInterfaceAddr string
+	GRPCInterfaceCert string
+	GRPCInterfaceKey  string
+	GRPCInterfaceTLS  bool
+
 	RPCInterface bool
 
 	// Launch System Default Browser after client startup
@@ -95,6 +129,10 @@ type NodeConfig struct {
 	ColorLog bool
 	// This is the value registered with flag, it is converted to LogLevel after parsing
 	LogLevel string
+	// Log levels of modules that override LogLevel, formatted as "module=level,module=level"
+	LogModuleLevels string
+	// Format of the log lines, text or json
+	LogFormat string
 	// Disable "Reply to ping", "Received pong" log messages
 	DisablePingPong bool
 
@@ -111,6 +149,21 @@ type NodeConfig struct {
 
 	RunMaster bool
 
+	// Config file that the options are loaded from
+	ConfigFile string
+	// If true, print the effective config and exit
+	PrintConfig bool
+
+	// Comma separated public keys of the block makers. If set, blocks are produced
+	// by a quorum of block makers instead of a master
+	BlockMakerPubkeysStr string
+	// Number of block maker votes needed to execute a block, defaults to a majority of the block makers
+	BlockMakerQuorum int
+	// Secret key of this node's block maker
+	BlockMakerSeckeyStr string
+	// How long to wait for a proposed block to reach the quorum before the next block maker proposes
+	BlockProposalTimeout time.Duration
+
 	/* Developer options */
 
 	// Enable cpu profiling
@@ -126,13 +179,33 @@ type NodeConfig struct {
 	LogToFile   bool
 	Version     bool // show node version
 
+	// Start a new log file when the log file would exceed this size in MB. 0 disables size-based rotation
+	LogFileMaxSize int
+	// Start a new log file when the log file is older than this. 0 disables time-based rotation
+	LogFileMaxAge time.Duration
+	// Number of log files kept in the logs directory. 0 keeps all log files
+	LogFileMaxFiles int
+
 	GenesisSignatureStr string
 	GenesisAddressStr   string
 	BlockchainPubkeyStr string
 	BlockchainSeckeyStr string
-	GenesisTimestamp    uint64
-	GenesisCoinVolume   uint64
-	DefaultConnections  []string
+	// Comma separated public keys that can sign blocks along with the master public key, until the first key rotation
+	BlockchainSignerPubkeysStr string
+	// Comma separated secret keys that the master can sign blocks with, along with the master secret key.
+	// The master signs each block with a key that is in effect at its seq, following the key rotations.
+	BlockchainSignerSeckeysStr string
+	// Comma separated hashes of the key rotations that the operator trusts, to resolve conflicting key rotations
+	TrustedKeyRotationsStr string
+	GenesisTimestamp       uint64
+	GenesisCoinVolume      uint64
+	DefaultConnections     []string
+	// Comma separated addresses of the default connections. If set, replaces DefaultConnections
+	DefaultConnectionsStr string
+	// Comma separated addresses of the peers that are trusted along with the default connections
+	TrustedPeers string
+	// Comma separated addresses of the private peers, which are always connected to and not shared with other peers
+	PrivatePeers string
 
 	genesisSignature cipher.Sig
 	genesisTimestamp uint64
@@ -140,6 +213,14 @@ type NodeConfig struct {
 
 	blockchainPubkey cipher.PubKey
 	blockchainSeckey cipher.SecKey
+
+	blockchainSignerPubkeys []cipher.PubKey
+	blockchainSignerSeckeys []cipher.SecKey
+
+	trustedKeyRotations []cipher.SHA256
+
+	blockMakerPubkeys []cipher.PubKey
+	blockMakerSeckey  cipher.SecKey
 }
 
 // NewNodeConfig returns a new node config instance
@@ -170,6 +251,16 @@ func NewNodeConfig(mode string, node NodeParameters) *NodeConfig {
 		EnableSeedAPI: false,
 		// Disable CSRF check in the wallet API
 		DisableCSRF: false,
+		// Require API tokens
+		EnableAPIAuth: false,
+		APITokensFile: "",
+		// API rate limits and response size caps
+		RateLimit:               0,
+		RateLimitBurst:          20,
+		RateLimitExpensive:      0,
+		RateLimitExpensiveBurst: 5,
+		MaxBlockRange:           0,
+		MaxRequestAddresses:     0,
 		// Only run on localhost and only connect to others on localhost
 		LocalhostOnly: false,
 		// Which address to serve on. Leave blank to automatically assign to a
@@ -186,6 +277,14 @@ func NewNodeConfig(mode string, node NodeParameters) *NodeConfig {
 		// How often to make outgoing connections, in seconds
 		OutgoingConnectionsRate: time.Second * 5,
 		PeerlistSize:            65535,
+		// Maximum total size of the unconfirmed transaction pool, in bytes
+		MaxUnconfirmedTxnsSize: visor.DefaultMaxUnconfirmedTxnsSize,
+		// Maximum chain of unconfirmed transactions
+		MaxUnconfirmedChainDepth: visor.DefaultMaxUnconfirmedChainDepth,
+		// Coin hours per kB that a replacement transaction must burn in addition to the transactions it replaces
+		MinReplacementFeeKB: visor.DefaultMinReplacementFeeKB,
+		// How long unconfirmed transactions are kept in the pool
+		UnconfirmedTxnsMaxAge: visor.DefaultUnconfirmedTxnsMaxAge,
 		// Wallet Address Version
 		//AddressVersion: "test",
 		// Remote web interface
@@ -195,6 +294,13 @@ func NewNodeConfig(mode string, node NodeParameters) *NodeConfig {
 		WebInterfaceCert:  "",
 		WebInterfaceKey:   "",
 		WebInterfaceHTTPS: false,
+		// gRPC interface
+		GRPCInterface:     false,
+		GRPCInterfacePort: 6422,
+		GRPCInterfaceAddr: "127.0.0.1",
+		GRPCInterfaceCert: "",
+		GRPCInterfaceKey:  "",
+		GRPCInterfaceTLS:  false,
 
 		RPCInterface: true,
 
@@ -206,6 +312,7 @@ func NewNodeConfig(mode string, node NodeParameters) *NodeConfig {
 		// Logging
 		ColorLog:        true,
 		LogLevel:        "INFO",
+		LogFormat:       string(logging.FormatText),
 		LogToFile:       false,
 		DisablePingPong: false,
 
@@ -224,6 +331,10 @@ func NewNodeConfig(mode string, node NodeParameters) *NodeConfig {
 
 		// Centralized network configuration
 		RunMaster: false,
+		// Block production by a quorum of block makers
+		BlockMakerPubkeysStr: "",
+		BlockMakerQuorum:     0,
+		BlockProposalTimeout: time.Second * 30,
 		/* Developer options */
 
 		// Enable cpu profiling
@@ -239,33 +350,125 @@ func NewNodeConfig(mode string, node NodeParameters) *NodeConfig {
 	return nodeConfig
 }
 
-func (c *Config) postProcess() {
+// postProcess parses and validates the options, and sets the options that default to other options.
+// Errors name the invalid option.
+func (c *Config) postProcess() error {
 	var err error
 	if c.Node.GenesisSignatureStr != "" {
 		c.Node.genesisSignature, err = cipher.SigFromHex(c.Node.GenesisSignatureStr)
-		panicIfError(err, "Invalid Signature")
+		if err != nil {
+			return fmt.Errorf("invalid genesis-signature: %v", err)
+		}
 	}
 
 	if c.Node.GenesisAddressStr != "" {
 		c.Node.genesisAddress, err = cipher.DecodeBase58Address(c.Node.GenesisAddressStr)
-		panicIfError(err, "Invalid Address")
+		if err != nil {
+			return fmt.Errorf("invalid genesis-address: %v", err)
+		}
 	}
 	if c.Node.BlockchainPubkeyStr != "" {
 		c.Node.blockchainPubkey, err = cipher.PubKeyFromHex(c.Node.BlockchainPubkeyStr)
-		panicIfError(err, "Invalid Pubkey")
+		if err != nil {
+			return fmt.Errorf("invalid master-public-key: %v", err)
+		}
 	}
 	if c.Node.BlockchainSeckeyStr != "" {
 		c.Node.blockchainSeckey, err = cipher.SecKeyFromHex(c.Node.BlockchainSeckeyStr)
-		panicIfError(err, "Invalid Seckey")
+		if err != nil {
+			return fmt.Errorf("invalid master-secret-key: %v", err)
+		}
 		c.Node.BlockchainSeckeyStr = ""
 	}
 	if c.Node.BlockchainSeckeyStr != "" {
 		c.Node.blockchainSeckey = cipher.SecKey{}
 	}
+	if c.Node.BlockchainSignerPubkeysStr != "" {
+		for _, s := range strings.Split(c.Node.BlockchainSignerPubkeysStr, ",") {
+			pk, err := cipher.PubKeyFromHex(strings.TrimSpace(s))
+			if err != nil {
+				return fmt.Errorf("invalid master-signer-public-keys: %q: %v", s, err)
+			}
+			c.Node.blockchainSignerPubkeys = append(c.Node.blockchainSignerPubkeys, pk)
+		}
+	}
+	if c.Node.BlockchainSignerSeckeysStr != "" {
+		for _, s := range strings.Split(c.Node.BlockchainSignerSeckeysStr, ",") {
+			sk, err := cipher.SecKeyFromHex(strings.TrimSpace(s))
+			if err != nil {
+				return fmt.Errorf("invalid master-signer-secret-keys: %v", err)
+			}
+			c.Node.blockchainSignerSeckeys = append(c.Node.blockchainSignerSeckeys, sk)
+		}
+		c.Node.BlockchainSignerSeckeysStr = ""
+	}
+	if c.Node.TrustedKeyRotationsStr != "" {
+		for _, s := range strings.Split(c.Node.TrustedKeyRotationsStr, ",") {
+			h, err := cipher.SHA256FromHex(strings.TrimSpace(s))
+			if err != nil {
+				return fmt.Errorf("invalid trusted-key-rotations: %q: %v", s, err)
+			}
+			c.Node.trustedKeyRotations = append(c.Node.trustedKeyRotations, h)
+		}
+	}
+
+	if c.Node.BlockMakerPubkeysStr != "" {
+		for _, s := range strings.Split(c.Node.BlockMakerPubkeysStr, ",") {
+			pk, err := cipher.PubKeyFromHex(strings.TrimSpace(s))
+			if err != nil {
+				return fmt.Errorf("invalid block-makers: %q: %v", s, err)
+			}
+			c.Node.blockMakerPubkeys = append(c.Node.blockMakerPubkeys, pk)
+		}
+
+		if c.Node.BlockMakerQuorum == 0 {
+			c.Node.BlockMakerQuorum = len(c.Node.blockMakerPubkeys)/2 + 1
+		}
+	}
+	if c.Node.BlockMakerSeckeyStr != "" {
+		c.Node.blockMakerSeckey, err = cipher.SecKeyFromHex(c.Node.BlockMakerSeckeyStr)
+		if err != nil {
+			return fmt.Errorf("invalid block-maker-secret-key: %v", err)
+		}
+		c.Node.BlockMakerSeckeyStr = ""
+	}
+
+	if c.Node.DefaultConnectionsStr != "" {
+		c.Node.DefaultConnections = nil
+		for _, s := range strings.Split(c.Node.DefaultConnectionsStr, ",") {
+			c.Node.DefaultConnections = append(c.Node.DefaultConnections, strings.TrimSpace(s))
+		}
+	}
+
+	if _, err := logging.LevelFromString(c.Node.LogLevel); err != nil {
+		return fmt.Errorf("invalid log-level: %v", err)
+	}
+
+	if _, err := logging.ModuleLevelsFromString(c.Node.LogModuleLevels); err != nil {
+		return fmt.Errorf("invalid log-module-levels: %v", err)
+	}
+
+	if _, err := logging.FormatFromString(c.Node.LogFormat); err != nil {
+		return fmt.Errorf("invalid log-format: %v", err)
+	}
+
+	if c.Node.LogFileMaxSize < 0 {
+		return errors.New("invalid log-file-max-size: must not be negative")
+	}
+
+	if c.Node.LogFileMaxAge < 0 {
+		return errors.New("invalid log-file-max-age: must not be negative")
+	}
+
+	if c.Node.LogFileMaxFiles < 0 {
+		return errors.New("invalid log-file-max-files: must not be negative")
+	}
 
 	home := file.UserHome()
 	c.Node.DataDirectory, err = file.InitDataDir(replaceHome(c.Node.DataDirectory, home))
-	panicIfError(err, "Invalid DataDirectory")
+	if err != nil {
+		return fmt.Errorf("invalid data-dir: %v", err)
+	}
 
 	if c.Node.WebInterfaceCert == "" {
 		c.Node.WebInterfaceCert = filepath.Join(c.Node.DataDirectory, "cert.pem")
@@ -279,6 +482,24 @@ func (c *Config) postProcess() {
 		c.Node.WebInterfaceKey = replaceHome(c.Node.WebInterfaceKey, home)
 	}
 
+	if c.Node.GRPCInterfaceCert == "" {
+		c.Node.GRPCInterfaceCert = c.Node.WebInterfaceCert
+	} else {
+		c.Node.GRPCInterfaceCert = replaceHome(c.Node.GRPCInterfaceCert, home)
+	}
+
+	if c.Node.GRPCInterfaceKey == "" {
+		c.Node.GRPCInterfaceKey = c.Node.WebInterfaceKey
+	} else {
+		c.Node.GRPCInterfaceKey = replaceHome(c.Node.GRPCInterfaceKey, home)
+	}
+
+	if c.Node.APITokensFile == "" {
+		c.Node.APITokensFile = filepath.Join(c.Node.DataDirectory, api.APITokensFilename)
+	} else {
+		c.Node.APITokensFile = replaceHome(c.Node.APITokensFile, home)
+	}
+
 	if c.Node.WalletDirectory == "" {
 		c.Node.WalletDirectory = filepath.Join(c.Node.DataDirectory, "wallets")
 	} else {
@@ -305,69 +526,112 @@ func (c *Config) postProcess() {
 	if c.Node.EnableGUI {
 		c.Node.GUIDirectory = file.ResolveResourceDirectory(c.Node.GUIDirectory)
 	}
+
+	return nil
 }
 
-func (c *Config) register() {
-	flag.BoolVar(&help, "help", false, "Show help")
-	flag.BoolVar(&c.Node.DisablePEX, "disable-pex", c.Node.DisablePEX, "disable PEX peer discovery")
-	flag.BoolVar(&c.Node.DownloadPeerList, "download-peerlist", c.Node.DownloadPeerList, "download a peers.txt from -peerlist-url")
-	flag.StringVar(&c.Node.PeerListURL, "peerlist-url", c.Node.PeerListURL, "with -download-peerlist=true, download a peers.txt file from this url")
-	flag.BoolVar(&c.Node.DisableOutgoingConnections, "disable-outgoing", c.Node.DisableOutgoingConnections, "Don't make outgoing connections")
-	flag.BoolVar(&c.Node.DisableIncomingConnections, "disable-incoming", c.Node.DisableIncomingConnections, "Don't make incoming connections")
-	flag.BoolVar(&c.Node.DisableNetworking, "disable-networking", c.Node.DisableNetworking, "Disable all network activity")
-	flag.BoolVar(&c.Node.EnableWalletAPI, "enable-wallet-api", c.Node.EnableWalletAPI, "Enable the wallet API")
-	flag.BoolVar(&c.Node.EnableGUI, "enable-gui", c.Node.EnableGUI, "Enable GUI")
-	flag.BoolVar(&c.Node.EnableUnversionedAPI, "enable-unversioned-api", c.Node.EnableUnversionedAPI, "Enable the deprecated unversioned API endpoints without /api/v1 prefix")
-	flag.BoolVar(&c.Node.DisableCSRF, "disable-csrf", c.Node.DisableCSRF, "disable CSRF check")
-	flag.BoolVar(&c.Node.EnableSeedAPI, "enable-seed-api", c.Node.EnableSeedAPI, "enable /api/v1/wallet/seed api")
-	flag.StringVar(&c.Node.Address, "address", c.Node.Address, "IP Address to run application on. Leave empty to default to a public interface")
-	flag.IntVar(&c.Node.Port, "port", c.Node.Port, "Port to run application on")
-
-	flag.BoolVar(&c.Node.WebInterface, "web-interface", c.Node.WebInterface, "enable the web interface")
-	flag.IntVar(&c.Node.WebInterfacePort, "web-interface-port", c.Node.WebInterfacePort, "port to serve web interface on")
-	flag.StringVar(&c.Node.WebInterfaceAddr, "web-interface-addr", c.Node.WebInterfaceAddr, "addr to serve web interface on")
-	flag.StringVar(&c.Node.WebInterfaceCert, "web-interface-cert", c.Node.WebInterfaceCert, "cert.pem file for web interface HTTPS. If not provided, will use cert.pem in -data-directory")
-	flag.StringVar(&c.Node.WebInterfaceKey, "web-interface-key", c.Node.WebInterfaceKey, "key.pem file for web interface HTTPS. If not provided, will use key.pem in -data-directory")
-	flag.BoolVar(&c.Node.WebInterfaceHTTPS, "web-interface-https", c.Node.WebInterfaceHTTPS, "enable HTTPS for web interface")
-
-	flag.BoolVar(&c.Node.RPCInterface, "rpc-interface", c.Node.RPCInterface, "enable the rpc interface")
-
-	flag.BoolVar(&c.Node.LaunchBrowser, "launch-browser", c.Node.LaunchBrowser, "launch system default webbrowser at client startup")
-	flag.BoolVar(&c.Node.PrintWebInterfaceAddress, "print-web-interface-address", c.Node.PrintWebInterfaceAddress, "print configured web interface address and exit")
-	flag.StringVar(&c.Node.DataDirectory, "data-dir", c.Node.DataDirectory, "directory to store app data (defaults to ~/.skycoin)")
-	flag.StringVar(&c.Node.DBPath, "db-path", c.Node.DBPath, "path of database file (defaults to ~/.skycoin/data.db)")
-	flag.BoolVar(&c.Node.DBReadOnly, "db-read-only", c.Node.DBReadOnly, "open bolt db read-only")
-	flag.BoolVar(&c.Node.ProfileCPU, "profile-cpu", c.Node.ProfileCPU, "enable cpu profiling")
-	flag.StringVar(&c.Node.ProfileCPUFile, "profile-cpu-file", c.Node.ProfileCPUFile, "where to write the cpu profile file")
-	flag.BoolVar(&c.Node.HTTPProf, "http-prof", c.Node.HTTPProf, "Run the http profiling interface")
-	flag.StringVar(&c.Node.LogLevel, "log-level", c.Node.LogLevel, "Choices are: debug, info, warn, error, fatal, panic")
-	flag.BoolVar(&c.Node.ColorLog, "color-log", c.Node.ColorLog, "Add terminal colors to log output")
-	flag.BoolVar(&c.Node.DisablePingPong, "no-ping-log", c.Node.DisablePingPong, `disable "reply to ping" and "received pong" debug log messages`)
-	flag.BoolVar(&c.Node.LogToFile, "logtofile", c.Node.LogToFile, "log to file")
-	flag.StringVar(&c.Node.GUIDirectory, "gui-dir", c.Node.GUIDirectory, "static content directory for the HTML interface")
-
-	flag.BoolVar(&c.Node.VerifyDB, "verify-db", c.Node.VerifyDB, "check the database for corruption")
-	flag.BoolVar(&c.Node.ResetCorruptDB, "reset-corrupt-db", c.Node.ResetCorruptDB, "reset the database if corrupted, and continue running instead of exiting")
+func (c *Config) register(fs *flag.FlagSet) {
+	fs.BoolVar(&help, "help", false, "Show help")
+	fs.StringVar(&c.Node.ConfigFile, "config", c.Node.ConfigFile, "config file (TOML, YAML or JSON) to load options from. Its keys are the option names. Options are loaded from the defaults, then this file, then "+EnvPrefix+"<OPTION> environment variables, then the command line")
+	fs.BoolVar(&c.Node.PrintConfig, "print-config", c.Node.PrintConfig, "print the effective config in TOML, with secrets redacted, and exit")
+	fs.BoolVar(&c.Node.DisablePEX, "disable-pex", c.Node.DisablePEX, "disable PEX peer discovery")
+	fs.BoolVar(&c.Node.DownloadPeerList, "download-peerlist", c.Node.DownloadPeerList, "download a peers.txt from -peerlist-url")
+	fs.StringVar(&c.Node.PeerListURL, "peerlist-url", c.Node.PeerListURL, "with -download-peerlist=true, download a peers.txt file from this url")
+	fs.BoolVar(&c.Node.DisableOutgoingConnections, "disable-outgoing", c.Node.DisableOutgoingConnections, "Don't make outgoing connections")
+	fs.BoolVar(&c.Node.DisableIncomingConnections, "disable-incoming", c.Node.DisableIncomingConnections, "Don't make incoming connections")
+	fs.BoolVar(&c.Node.DisableNetworking, "disable-networking", c.Node.DisableNetworking, "Disable all network activity")
+	fs.BoolVar(&c.Node.EnableWalletAPI, "enable-wallet-api", c.Node.EnableWalletAPI, "Enable the wallet API")
+	fs.BoolVar(&c.Node.EnableGUI, "enable-gui", c.Node.EnableGUI, "Enable GUI")
+	fs.BoolVar(&c.Node.EnableUnversionedAPI, "enable-unversioned-api", c.Node.EnableUnversionedAPI, "Enable the deprecated unversioned API endpoints without /api/v1 prefix")
+	fs.BoolVar(&c.Node.DisableCSRF, "disable-csrf", c.Node.DisableCSRF, "disable CSRF check")
+	fs.BoolVar(&c.Node.EnableSeedAPI, "enable-seed-api", c.Node.EnableSeedAPI, "enable /api/v1/wallet/seed api")
+	fs.BoolVar(&c.Node.EnableAPIAuth, "enable-api-auth", c.Node.EnableAPIAuth, "require scoped API tokens for the API endpoints. Tokens are created with the CLI")
+	fs.StringVar(&c.Node.APITokensFile, "api-tokens-file", c.Node.APITokensFile, "file that the hashed API tokens are stored in. If not provided, will use api_tokens.json in -data-directory")
+	fs.Float64Var(&c.Node.RateLimit, "rate-limit", c.Node.RateLimit, "requests per second allowed per API client (IP address, or API token with -enable-api-auth). 0 disables the limit")
+	fs.IntVar(&c.Node.RateLimitBurst, "rate-limit-burst", c.Node.RateLimitBurst, "number of requests an API client can make at once before -rate-limit applies")
+	fs.Float64Var(&c.Node.RateLimitExpensive, "rate-limit-expensive", c.Node.RateLimitExpensive, "requests per second allowed per API client to the expensive API endpoints, such as /blocks and /transactions. 0 disables the limit")
+	fs.IntVar(&c.Node.RateLimitExpensiveBurst, "rate-limit-expensive-burst", c.Node.RateLimitExpensiveBurst, "number of requests an API client can make at once to the expensive API endpoints before -rate-limit-expensive applies")
+	fs.Uint64Var(&c.Node.MaxBlockRange, "max-block-range", c.Node.MaxBlockRange, "maximum number of blocks returned by /api/v1/blocks, /api/v1/last_blocks and the gRPC GetBlocks and GetLastBlocks. 0 is unlimited")
+	fs.IntVar(&c.Node.MaxRequestAddresses, "max-request-addresses", c.Node.MaxRequestAddresses, "maximum number of addresses in an /api/v1/balance, /api/v1/outputs, /api/v1/transactions or gRPC request. 0 is unlimited")
+	fs.StringVar(&c.Node.Address, "address", c.Node.Address, "IP Address to run application on. Leave empty to default to a public interface")
+	fs.IntVar(&c.Node.Port, "port", c.Node.Port, "Port to run application on")
+
+	fs.BoolVar(&c.Node.WebInterface, "web-interface", c.Node.WebInterface, "enable the web interface")
+	fs.IntVar(&c.Node.WebInterfacePort, "web-interface-port", c.Node.WebInterfacePort, "port to serve web interface on")
+	fs.StringVar(&c.Node.WebInterfaceAddr, "web-interface-addr", c.Node.WebInterfaceAddr, "addr to serve web interface on")
+	fs.StringVar(&c.Node.WebInterfaceCert, "web-interface-cert", c.Node.WebInterfaceCert, "cert.pem file for web interface HTTPS. If not provided, will use cert.pem in -data-directory")
+	fs.StringVar(&c.Node.WebInterfaceKey, "web-interface-key", c.Node.WebInterfaceKey, "key.pem file for web interface HTTPS. If not provided, will use key.pem in -data-directory")
+	fs.BoolVar(&c.Node.WebInterfaceHTTPS, "web-interface-https", c.Node.WebInterfaceHTTPS, "enable HTTPS for web interface")
+	fs.BoolVar(&c.Node.GRPCInterface, "grpc-interface", c.Node.GRPCInterface, "enable the gRPC interface")
+	fs.IntVar(&c.Node.GRPCInterfacePort, "grpc-interface-port", c.Node.GRPCInterfacePort, "port to serve gRPC interface on")
+	fs.StringVar(&c.Node.GRPCInterfaceAddr, "grpc-interface-addr", c.Node.GRPCInterfaceAddr, "addr to serve gRPC interface on")
+	fs.StringVar(&c.Node.GRPCInterfaceCert, "grpc-interface-cert", c.Node.GRPCInterfaceCert, "cert.pem file for gRPC interface TLS. If not provided, will use the web interface cert")
+	fs.StringVar(&c.Node.GRPCInterfaceKey, "grpc-interface-key", c.Node.GRPCInterfaceKey, "key.pem file for gRPC interface TLS. If not provided, will use the web interface key")
+	fs.BoolVar(&c.Node.GRPCInterfaceTLS, "grpc-interface-tls", c.Node.GRPCInterfaceTLS, "enable TLS for gRPC interface")
+
+	fs.DurationVar(&c.Node.ReadTimeout, "read-timeout", c.Node.ReadTimeout, "maximum duration for the web interface to read a request")
+	fs.DurationVar(&c.Node.WriteTimeout, "write-timeout", c.Node.WriteTimeout, "maximum duration for the web interface to write a response")
+	fs.DurationVar(&c.Node.IdleTimeout, "idle-timeout", c.Node.IdleTimeout, "maximum duration that the web interface keeps an idle keep-alive connection open")
+
+	fs.BoolVar(&c.Node.RPCInterface, "rpc-interface", c.Node.RPCInterface, "enable the rpc interface")
+
+	fs.BoolVar(&c.Node.LaunchBrowser, "launch-browser", c.Node.LaunchBrowser, "launch system default webbrowser at client startup")
+	fs.BoolVar(&c.Node.PrintWebInterfaceAddress, "print-web-interface-address", c.Node.PrintWebInterfaceAddress, "print configured web interface address and exit")
+	fs.StringVar(&c.Node.DataDirectory, "data-dir", c.Node.DataDirectory, "directory to store app data (defaults to ~/.skycoin)")
+	fs.StringVar(&c.Node.DBPath, "db-path", c.Node.DBPath, "path of database file (defaults to ~/.skycoin/data.db)")
+	fs.BoolVar(&c.Node.DBReadOnly, "db-read-only", c.Node.DBReadOnly, "open bolt db read-only")
+	fs.BoolVar(&c.Node.ProfileCPU, "profile-cpu", c.Node.ProfileCPU, "enable cpu profiling")
+	fs.StringVar(&c.Node.ProfileCPUFile, "profile-cpu-file", c.Node.ProfileCPUFile, "where to write the cpu profile file")
+	fs.BoolVar(&c.Node.HTTPProf, "http-prof", c.Node.HTTPProf, "Run the http profiling interface")
+	fs.StringVar(&c.Node.LogLevel, "log-level", c.Node.LogLevel, "Choices are: debug, info, warn, error, fatal, panic")
+	fs.StringVar(&c.Node.LogModuleLevels, "log-module-levels", c.Node.LogModuleLevels, "Comma separated log levels of modules that override log-level, e.g. daemon=debug,visor=warn")
+	fs.StringVar(&c.Node.LogFormat, "log-format", c.Node.LogFormat, "Format of the log lines. Choices are: text, json")
+	fs.BoolVar(&c.Node.ColorLog, "color-log", c.Node.ColorLog, "Add terminal colors to log output")
+	fs.BoolVar(&c.Node.DisablePingPong, "no-ping-log", c.Node.DisablePingPong, `disable "reply to ping" and "received pong" debug log messages`)
+	fs.BoolVar(&c.Node.LogToFile, "logtofile", c.Node.LogToFile, "log to file")
+	fs.IntVar(&c.Node.LogFileMaxSize, "log-file-max-size", c.Node.LogFileMaxSize, "start a new log file when the log file would exceed this size in MB. 0 disables size-based rotation")
+	fs.DurationVar(&c.Node.LogFileMaxAge, "log-file-max-age", c.Node.LogFileMaxAge, "start a new log file when the log file is older than this, e.g. 24h. 0 disables time-based rotation")
+	fs.IntVar(&c.Node.LogFileMaxFiles, "log-file-max-files", c.Node.LogFileMaxFiles, "number of log files to keep in the logs directory. 0 keeps all log files")
+	fs.StringVar(&c.Node.GUIDirectory, "gui-dir", c.Node.GUIDirectory, "static content directory for the HTML interface")
+
+	fs.BoolVar(&c.Node.VerifyDB, "verify-db", c.Node.VerifyDB, "check the database for corruption")
+	fs.BoolVar(&c.Node.ResetCorruptDB, "reset-corrupt-db", c.Node.ResetCorruptDB, "reset the database if corrupted, and continue running instead of exiting")
 
 	// Key Configuration Data
-	flag.BoolVar(&c.Node.RunMaster, "master", c.Node.RunMaster, "run the daemon as blockchain master server")
-
-	flag.StringVar(&c.Node.BlockchainPubkeyStr, "master-public-key", c.Node.BlockchainPubkeyStr, "public key of the master chain")
-	flag.StringVar(&c.Node.BlockchainSeckeyStr, "master-secret-key", c.Node.BlockchainSeckeyStr, "secret key, set for master")
-
-	flag.StringVar(&c.Node.GenesisAddressStr, "genesis-address", c.Node.GenesisAddressStr, "genesis address")
-	flag.StringVar(&c.Node.GenesisSignatureStr, "genesis-signature", c.Node.GenesisSignatureStr, "genesis block signature")
-	flag.Uint64Var(&c.Node.GenesisTimestamp, "genesis-timestamp", c.Node.GenesisTimestamp, "genesis block timestamp")
-
-	flag.StringVar(&c.Node.WalletDirectory, "wallet-dir", c.Node.WalletDirectory, "location of the wallet files. Defaults to ~/.skycoin/wallet/")
-	flag.IntVar(&c.Node.MaxOutgoingConnections, "max-outgoing-connections", c.Node.MaxOutgoingConnections, "The maximum outgoing connections allowed")
-	flag.IntVar(&c.Node.MaxDefaultPeerOutgoingConnections, "max-default-peer-outgoing-connections", c.Node.MaxDefaultPeerOutgoingConnections, "The maximum default peer outgoing connections allowed")
-	flag.IntVar(&c.Node.PeerlistSize, "peerlist-size", c.Node.PeerlistSize, "The peer list size")
-	flag.DurationVar(&c.Node.OutgoingConnectionsRate, "connection-rate", c.Node.OutgoingConnectionsRate, "How often to make an outgoing connection")
-	flag.BoolVar(&c.Node.LocalhostOnly, "localhost-only", c.Node.LocalhostOnly, "Run on localhost and only connect to localhost peers")
-	flag.BoolVar(&c.Node.Arbitrating, "arbitrating", c.Node.Arbitrating, "Run node in arbitrating mode")
-	flag.StringVar(&c.Node.WalletCryptoType, "wallet-crypto-type", c.Node.WalletCryptoType, "wallet crypto type. Can be sha256-xor or scrypt-chacha20poly1305")
-	flag.BoolVar(&c.Node.Version, "version", false, "show node version")
+	fs.BoolVar(&c.Node.RunMaster, "master", c.Node.RunMaster, "run the daemon as blockchain master server")
+
+	fs.StringVar(&c.Node.BlockchainPubkeyStr, "master-public-key", c.Node.BlockchainPubkeyStr, "public key of the master chain")
+	fs.StringVar(&c.Node.BlockchainSeckeyStr, "master-secret-key", c.Node.BlockchainSeckeyStr, "secret key, set for master")
+	fs.StringVar(&c.Node.BlockchainSignerPubkeysStr, "master-signer-public-keys", c.Node.BlockchainSignerPubkeysStr, "comma separated public keys that can sign blocks along with the master public key, until the first key rotation")
+	fs.StringVar(&c.Node.BlockchainSignerSeckeysStr, "master-signer-secret-keys", c.Node.BlockchainSignerSeckeysStr, "comma separated secret keys that the master can sign blocks with. The master selects the key in effect at each block's seq, following the key rotations")
+	fs.StringVar(&c.Node.TrustedKeyRotationsStr, "trusted-key-rotations", c.Node.TrustedKeyRotationsStr, "comma separated hashes of key rotations to trust when a blockchain authority signed conflicting key rotations")
+
+	fs.StringVar(&c.Node.BlockMakerPubkeysStr, "block-makers", c.Node.BlockMakerPubkeysStr, "comma separated public keys of the block makers. If set, blocks are produced by a quorum of block makers instead of a master")
+	fs.IntVar(&c.Node.BlockMakerQuorum, "block-maker-quorum", c.Node.BlockMakerQuorum, "number of block maker votes needed to execute a block. Defaults to a majority of -block-makers")
+	fs.StringVar(&c.Node.BlockMakerSeckeyStr, "block-maker-secret-key", c.Node.BlockMakerSeckeyStr, "secret key of this node's block maker, set for block makers")
+	fs.DurationVar(&c.Node.BlockProposalTimeout, "block-proposal-timeout", c.Node.BlockProposalTimeout, "how long to wait for a proposed block to reach the quorum, before the next block maker proposes a block")
+
+	fs.StringVar(&c.Node.GenesisAddressStr, "genesis-address", c.Node.GenesisAddressStr, "genesis address")
+	fs.StringVar(&c.Node.GenesisSignatureStr, "genesis-signature", c.Node.GenesisSignatureStr, "genesis block signature")
+	fs.Uint64Var(&c.Node.GenesisTimestamp, "genesis-timestamp", c.Node.GenesisTimestamp, "genesis block timestamp")
+	fs.Uint64Var(&c.Node.GenesisCoinVolume, "genesis-coin-volume", c.Node.GenesisCoinVolume, "number of coins in the genesis block, in droplets")
+	fs.StringVar(&c.Node.DefaultConnectionsStr, "default-connections", c.Node.DefaultConnectionsStr, "comma separated addresses of the default connections. If set, replaces the hardcoded default connections")
+	fs.StringVar(&c.Node.TrustedPeers, "trusted-peers", c.Node.TrustedPeers, "comma separated addresses of the peers to trust along with the default connections")
+	fs.StringVar(&c.Node.PrivatePeers, "private-peers", c.Node.PrivatePeers, "comma separated addresses of the private peers, which are always connected to and not shared with other peers")
+
+	fs.StringVar(&c.Node.WalletDirectory, "wallet-dir", c.Node.WalletDirectory, "location of the wallet files. Defaults to ~/.skycoin/wallet/")
+	fs.IntVar(&c.Node.MaxOutgoingConnections, "max-outgoing-connections", c.Node.MaxOutgoingConnections, "The maximum outgoing connections allowed")
+	fs.IntVar(&c.Node.MaxDefaultPeerOutgoingConnections, "max-default-peer-outgoing-connections", c.Node.MaxDefaultPeerOutgoingConnections, "The maximum default peer outgoing connections allowed")
+	fs.IntVar(&c.Node.PeerlistSize, "peerlist-size", c.Node.PeerlistSize, "The peer list size")
+	fs.IntVar(&c.Node.MaxUnconfirmedTxnsSize, "max-unconfirmed-txns-size", c.Node.MaxUnconfirmedTxnsSize, "Maximum total size of the unconfirmed transaction pool in bytes. Transactions with the lowest fee per kB are evicted when full. 0 means no limit")
+	fs.IntVar(&c.Node.MaxUnconfirmedChainDepth, "max-unconfirmed-chain-depth", c.Node.MaxUnconfirmedChainDepth, "Maximum number of unconfirmed ancestors of a transaction in the unconfirmed pool. 0 disallows spending unconfirmed outputs")
+	fs.Uint64Var(&c.Node.MinReplacementFeeKB, "min-replacement-fee-kb", c.Node.MinReplacementFeeKB, "Coin hours per kB that a transaction replacing unconfirmed transactions must burn in addition to their fees")
+	fs.DurationVar(&c.Node.UnconfirmedTxnsMaxAge, "unconfirmed-txns-max-age", c.Node.UnconfirmedTxnsMaxAge, "Remove unconfirmed transactions received longer ago than this from the pool. 0 means they never expire")
+	fs.DurationVar(&c.Node.OutgoingConnectionsRate, "connection-rate", c.Node.OutgoingConnectionsRate, "How often to make an outgoing connection")
+	fs.BoolVar(&c.Node.LocalhostOnly, "localhost-only", c.Node.LocalhostOnly, "Run on localhost and only connect to localhost peers")
+	fs.BoolVar(&c.Node.Arbitrating, "arbitrating", c.Node.Arbitrating, "Run node in arbitrating mode")
+	fs.StringVar(&c.Node.WalletCryptoType, "wallet-crypto-type", c.Node.WalletCryptoType, "wallet crypto type. Can be sha256-xor or scrypt-chacha20poly1305")
+	fs.BoolVar(&c.Node.Version, "version", false, "show node version")
 }
 
 func (n *NodeConfig) applyConfigMode(configMode string) {
@@ -391,12 +655,17 @@ func (n *NodeConfig) applyConfigMode(configMode string) {
 	}
 }
 
-func panicIfError(err error, msg string, args ...interface{}) {
-	if err != nil {
-		log.Panicf(msg+": %v", append(args, err)...)
-	}
-}
-
 func replaceHome(path, home string) string {
 	return strings.Replace(path, "$HOME", home, 1)
 }
+
+// splitAddrs splits a comma separated list of addresses
+func splitAddrs(s string) []string {
+	var addrs []string
+	for _, a := range strings.Split(s, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			addrs = append(addrs, a)
+		}
+	}
+	return addrs
+}
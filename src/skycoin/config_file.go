@@ -0,0 +1,249 @@
+package skycoin
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+// EnvPrefix is the prefix of the environment variables that set the node's options.
+// The variable of an option is the option name in upper case, with "-" replaced by "_",
+// e.g. SKYCOIN_WEB_INTERFACE_PORT sets -web-interface-port
+var EnvPrefix = "SKYCOIN_"
+
+// commandOptions are the options that are actions of the command line only,
+// and are not loaded from a config file or printed by -print-config
+var commandOptions = map[string]struct{}{
+	"help":         {},
+	"config":       {},
+	"print-config": {},
+	"version":      {},
+}
+
+// redactedValue replaces the values of secret options printed by -print-config
+const redactedValue = "REDACTED"
+
+// envName returns the name of the environment variable of an option
+func envName(option string) string {
+	return EnvPrefix + strings.ToUpper(strings.Replace(option, "-", "_", -1))
+}
+
+// parse sets the options registered in fs from the defaults, the config file, the environment
+// variables and the command line args, in increasing order of precedence
+func (c *Config) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Options set on the command line are not overridden
+	set := make(map[string]struct{})
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = struct{}{}
+	})
+
+	if _, ok := set["config"]; !ok {
+		if v, ok := os.LookupEnv(envName("config")); ok {
+			c.Node.ConfigFile = v
+		}
+	}
+
+	if c.Node.ConfigFile != "" {
+		if err := loadConfigFile(fs, c.Node.ConfigFile, set); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		if _, ok := set[f.Name]; ok {
+			return
+		}
+		if _, ok := commandOptions[f.Name]; ok {
+			return
+		}
+
+		name := envName(f.Name)
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		if setErr := fs.Set(f.Name, v); setErr != nil {
+			err = fmt.Errorf("invalid value %q for environment variable %s: %v", v, name, setErr)
+		}
+	})
+
+	return err
+}
+
+// loadConfigFile sets the options in a TOML, YAML or JSON config file, except the options in skip.
+// The keys of the file are the option names, "_" can be used instead of "-".
+func loadConfigFile(fs *flag.FlagSet, path string, skip map[string]struct{}) error {
+	v := viper.New()
+	v.SetConfigFile(replaceHome(path, file.UserHome()))
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("read config file %s failed: %v", path, err)
+	}
+
+	settings := v.AllSettings()
+
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		name := strings.Replace(k, "_", "-", -1)
+
+		_, isCommandOption := commandOptions[name]
+		if fs.Lookup(name) == nil || isCommandOption {
+			return fmt.Errorf("config file %s: unknown key %q", path, k)
+		}
+
+		if _, ok := skip[name]; ok {
+			continue
+		}
+
+		s, err := configValueString(settings[k])
+		if err != nil {
+			return fmt.Errorf("config file %s: invalid value for key %q: %v", path, k, err)
+		}
+
+		if err := fs.Set(name, s); err != nil {
+			return fmt.Errorf("config file %s: invalid value %q for key %q: %v", path, s, k, err)
+		}
+	}
+
+	return nil
+}
+
+// configValueString formats a config file value like the command line value of an option.
+// Lists are joined with commas.
+func configValueString(v interface{}) (string, error) {
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, len(x))
+		for i, item := range x {
+			s, err := configValueString(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		return "", errors.New("tables are not supported")
+	default:
+		return fmt.Sprint(x), nil
+	}
+}
+
+// printConfig writes the effective options registered in fs as a TOML config file, with the secrets redacted.
+// It must be called after postProcess.
+func (c *Config) printConfig(w io.Writer, fs *flag.FlagSet) error {
+	secrets := c.secretOptions()
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		if _, ok := commandOptions[f.Name]; ok {
+			return
+		}
+
+		var value interface{}
+		if getter, ok := f.Value.(flag.Getter); ok {
+			value = getter.Get()
+		} else {
+			value = f.Value.String()
+		}
+
+		if secret, ok := secrets[f.Name]; ok {
+			value = ""
+			if secret != "" {
+				value = redactedValue
+			}
+		}
+
+		if f.Name == "default-connections" {
+			value = strings.Join(c.Node.DefaultConnections, ",")
+		}
+
+		switch x := value.(type) {
+		case string:
+			_, err = fmt.Fprintf(w, "%s = %q\n", f.Name, x)
+		case time.Duration:
+			_, err = fmt.Fprintf(w, "%s = %q\n", f.Name, x.String())
+		default:
+			_, err = fmt.Fprintf(w, "%s = %v\n", f.Name, x)
+		}
+	})
+
+	return err
+}
+
+// optionValues returns the effective values of the options registered in fs, by option name.
+// It must be called after postProcess. The values include the secrets and must not be printed.
+func (c *Config) optionValues(fs *flag.FlagSet) map[string]string {
+	secrets := c.secretOptions()
+
+	values := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := commandOptions[f.Name]; ok {
+			return
+		}
+
+		value := f.Value.String()
+		if secret, ok := secrets[f.Name]; ok {
+			value = secret
+		}
+		if f.Name == "default-connections" {
+			value = strings.Join(c.Node.DefaultConnections, ",")
+		}
+
+		values[f.Name] = value
+	})
+
+	return values
+}
+
+// secretOptions returns the values of the secret options, which postProcess parses and clears
+func (c *Config) secretOptions() map[string]string {
+	secKeyHex := func(k cipher.SecKey) string {
+		if k == (cipher.SecKey{}) {
+			return ""
+		}
+		return k.Hex()
+	}
+
+	signers := make([]string, len(c.Node.blockchainSignerSeckeys))
+	for i, k := range c.Node.blockchainSignerSeckeys {
+		signers[i] = k.Hex()
+	}
+
+	return map[string]string{
+		"master-secret-key":         secKeyHex(c.Node.blockchainSeckey),
+		"master-signer-secret-keys": strings.Join(signers, ","),
+		"block-maker-secret-key":    secKeyHex(c.Node.blockMakerSeckey),
+	}
+}
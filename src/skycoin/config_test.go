@@ -0,0 +1,255 @@
+package skycoin
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func newTestConfig(t *testing.T, dataDir string) (*Config, *flag.FlagSet) {
+	c := &Config{
+		Node: *NewNodeConfig("", NodeParameters{
+			Port:             6000,
+			WebInterfacePort: 6420,
+			DataDirectory:    dataDir,
+			DefaultConnections: []string{
+				"118.178.135.93:6000",
+			},
+		}),
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	c.register(fs)
+
+	return c, fs
+}
+
+func writeTestConfigFile(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "skycoin-config")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestParseConfigPrecedence(t *testing.T) {
+	path := writeTestConfigFile(t, "node.toml", `
+port = 7000
+web_interface_port = 7420
+connection-rate = "3s"
+rate-limit = 2.5
+default-connections = ["1.2.3.4:6000", "5.6.7.8:6000"]
+launch-browser = true
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	os.Setenv("SKYCOIN_WEB_INTERFACE_PORT", "8420")
+	defer os.Unsetenv("SKYCOIN_WEB_INTERFACE_PORT")
+	os.Setenv("SKYCOIN_MAX_OUTGOING_CONNECTIONS", "16")
+	defer os.Unsetenv("SKYCOIN_MAX_OUTGOING_CONNECTIONS")
+	os.Setenv("SKYCOIN_LAUNCH_BROWSER", "true")
+	defer os.Unsetenv("SKYCOIN_LAUNCH_BROWSER")
+
+	c, fs := newTestConfig(t, filepath.Join(filepath.Dir(path), "data"))
+	err := c.parse(fs, []string{"-config", path, "-launch-browser=false", "-log-level", "debug"})
+	require.NoError(t, err)
+
+	// The file overrides the defaults
+	require.Equal(t, 7000, c.Node.Port)
+	require.Equal(t, time.Second*3, c.Node.OutgoingConnectionsRate)
+	require.Equal(t, 2.5, c.Node.RateLimit)
+	require.Equal(t, "1.2.3.4:6000,5.6.7.8:6000", c.Node.DefaultConnectionsStr)
+	// Environment variables override the file
+	require.Equal(t, 8420, c.Node.WebInterfacePort)
+	require.Equal(t, 16, c.Node.MaxOutgoingConnections)
+	// Flags override the environment variables and the file
+	require.False(t, c.Node.LaunchBrowser)
+	require.Equal(t, "debug", c.Node.LogLevel)
+	// Defaults are kept
+	require.Equal(t, 1, c.Node.MaxDefaultPeerOutgoingConnections)
+
+	require.NoError(t, c.postProcess())
+	require.Equal(t, []string{"1.2.3.4:6000", "5.6.7.8:6000"}, c.Node.DefaultConnections)
+}
+
+func TestParseConfigFileFromEnv(t *testing.T) {
+	path := writeTestConfigFile(t, "node.yaml", "port: 7001\nlog-level: warn\n")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	os.Setenv("SKYCOIN_CONFIG", path)
+	defer os.Unsetenv("SKYCOIN_CONFIG")
+
+	c, fs := newTestConfig(t, "")
+	require.NoError(t, c.parse(fs, nil))
+	require.Equal(t, 7001, c.Node.Port)
+	require.Equal(t, "warn", c.Node.LogLevel)
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		err     string
+	}{
+		{
+			name:    "unknown key",
+			file:    "node.toml",
+			content: "port = 7000\nfoo-bar = 1\n",
+			err:     `unknown key "foo-bar"`,
+		},
+		{
+			name:    "command option",
+			file:    "node.toml",
+			content: "print-config = true\n",
+			err:     `unknown key "print-config"`,
+		},
+		{
+			name:    "invalid value",
+			file:    "node.toml",
+			content: "web-interface-port = \"abc\"\n",
+			err:     `invalid value "abc" for key "web-interface-port"`,
+		},
+		{
+			name:    "table",
+			file:    "node.toml",
+			content: "[port]\nfoo = 1\n",
+			err:     `invalid value for key "port": tables are not supported`,
+		},
+		{
+			name:    "unsupported file type",
+			file:    "node.ini",
+			content: "port = 7000\n",
+			err:     "read config file",
+		},
+		{
+			name: "invalid env value",
+			env: map[string]string{
+				"SKYCOIN_CONNECTION_RATE": "often",
+			},
+			err: `invalid value "often" for environment variable SKYCOIN_CONNECTION_RATE`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var args []string
+			if tc.file != "" {
+				path := writeTestConfigFile(t, tc.file, tc.content)
+				defer os.RemoveAll(filepath.Dir(path))
+				args = []string{"-config", path}
+			}
+
+			for k, v := range tc.env {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
+
+			c, fs := newTestConfig(t, "")
+			err := c.parse(fs, args)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestPostProcessErrors(t *testing.T) {
+	cases := []struct {
+		args []string
+		err  string
+	}{
+		{
+			args: []string{"-genesis-address", "foo"},
+			err:  "invalid genesis-address",
+		},
+		{
+			args: []string{"-master-secret-key", "foo"},
+			err:  "invalid master-secret-key",
+		},
+		{
+			args: []string{"-block-makers", "foo"},
+			err:  "invalid block-makers",
+		},
+		{
+			args: []string{"-log-level", "loud"},
+			err:  "invalid log-level",
+		},
+		{
+			args: []string{"-log-module-levels", "daemon"},
+			err:  "invalid log-module-levels",
+		},
+		{
+			args: []string{"-log-format", "xml"},
+			err:  "invalid log-format",
+		},
+		{
+			args: []string{"-log-file-max-files", "-1"},
+			err:  "invalid log-file-max-files",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.err, func(t *testing.T) {
+			c, fs := newTestConfig(t, "")
+			require.NoError(t, c.parse(fs, tc.args))
+			err := c.postProcess()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	_, seckey := cipher.GenerateKeyPair()
+
+	dir, err := ioutil.TempDir("", "skycoin-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c, fs := newTestConfig(t, "")
+	require.NoError(t, c.parse(fs, []string{
+		"-data-dir", dir,
+		"-master-secret-key", seckey.Hex(),
+		"-connection-rate", "3s",
+		"-rate-limit", "1.5",
+	}))
+	require.NoError(t, c.postProcess())
+
+	var buf bytes.Buffer
+	require.NoError(t, c.printConfig(&buf, fs))
+	out := buf.String()
+
+	require.NotContains(t, out, seckey.Hex())
+	require.Contains(t, out, `master-secret-key = "REDACTED"`+"\n")
+	require.Contains(t, out, `block-maker-secret-key = ""`+"\n")
+	require.Contains(t, out, `connection-rate = "3s"`+"\n")
+	require.Contains(t, out, "rate-limit = 1.5\n")
+	require.Contains(t, out, `default-connections = "118.178.135.93:6000"`+"\n")
+	require.Contains(t, out, `wallet-dir = "`+filepath.Join(dir, "wallets")+`"`+"\n")
+	require.NotContains(t, out, "print-config")
+
+	// The printed config can be loaded as a config file, once the redacted secrets are removed
+	printed := bytes.Replace(buf.Bytes(), []byte(`"REDACTED"`), []byte(`""`), -1)
+	path := writeTestConfigFile(t, "printed.toml", string(printed))
+	defer os.RemoveAll(filepath.Dir(path))
+
+	c2, fs2 := newTestConfig(t, "")
+	require.NoError(t, c2.parse(fs2, []string{"-config", path}))
+	require.NoError(t, c2.postProcess())
+	require.Equal(t, c.Node.DataDirectory, c2.Node.DataDirectory)
+	require.Equal(t, c.Node.OutgoingConnectionsRate, c2.Node.OutgoingConnectionsRate)
+	require.Equal(t, c.Node.RateLimit, c2.Node.RateLimit)
+	require.Equal(t, c.Node.WalletDirectory, c2.Node.WalletDirectory)
+	require.Equal(t, c.Node.DefaultConnections, c2.Node.DefaultConnections)
+}
@@ -0,0 +1,191 @@
+package skycoin
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/util/logging"
+)
+
+// ReloadConfig parses the config file, the environment variables and the command line args again,
+// and applies the changed options that can be changed while the node runs:
+// log-level, log-module-levels, max-outgoing-connections, trusted-peers, private-peers, disable-csrf,
+// enable-unversioned-api, enable-seed-api and the rate limits that are enabled.
+// The other changed options are reported as requiring a restart.
+// If the config is invalid, no option is applied.
+func (c *Coin) ReloadConfig() (*api.ConfigReloadResult, error) {
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+
+	if c.daemon == nil {
+		return nil, errors.New("The node is not running")
+	}
+
+	c.logger.Info("Reloading config")
+
+	nc, fs, err := c.reparseConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	result := c.updateConfig(nc, fs)
+	if len(result.Applied) != 0 {
+		c.setRuntimeConfig()
+	}
+
+	c.logger.Infof("Config reloaded, applied options: %v, options that require a restart: %v", result.Applied, result.RestartRequired)
+
+	return result, nil
+}
+
+// reparseConfig parses the config from the defaults, the config file, the environment variables
+// and the command line args, like ParseConfig
+func (c *Coin) reparseConfig() (*Config, *flag.FlagSet, error) {
+	nc := c.defaults
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	nc.register(fs)
+	if err := nc.parse(fs, c.args); err != nil {
+		return nil, nil, err
+	}
+	if err := nc.postProcess(); err != nil {
+		return nil, nil, err
+	}
+
+	return &nc, fs, nil
+}
+
+// updateConfig compares the options of the running config with the options of nc registered in fs.
+// The changed options that are reloadable are copied to the running config.
+func (c *Coin) updateConfig(nc *Config, fs *flag.FlagSet) *api.ConfigReloadResult {
+	running := c.config
+	runningFs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	running.register(runningFs)
+
+	oldValues := running.optionValues(runningFs)
+	newValues := nc.optionValues(fs)
+
+	result := &api.ConfigReloadResult{
+		Applied:         []string{},
+		RestartRequired: []string{},
+	}
+
+	names := make([]string, 0, len(newValues))
+	for name := range newValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if oldValues[name] == newValues[name] {
+			continue
+		}
+
+		if c.reloadable(name, nc) {
+			c.applyOption(name, nc)
+			result.Applied = append(result.Applied, name)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, name)
+		}
+	}
+
+	return result
+}
+
+// reloadable returns whether a change of an option to its value in nc can be applied while the node runs
+func (c *Coin) reloadable(option string, nc *Config) bool {
+	switch option {
+	case "log-level",
+		"log-module-levels",
+		"max-outgoing-connections",
+		"trusted-peers",
+		"private-peers",
+		"disable-csrf",
+		"enable-unversioned-api",
+		"enable-seed-api":
+		return true
+	case "rate-limit", "rate-limit-burst":
+		// The rate limiters are only created for the limits enabled at startup,
+		// so a limit can be changed but not enabled or disabled
+		return c.config.Node.RateLimit > 0 && nc.Node.RateLimit > 0
+	case "rate-limit-expensive", "rate-limit-expensive-burst":
+		return c.config.Node.RateLimitExpensive > 0 && nc.Node.RateLimitExpensive > 0
+	default:
+		return false
+	}
+}
+
+// applyOption copies the value of a reloadable option from nc to the running config
+func (c *Coin) applyOption(option string, nc *Config) {
+	switch option {
+	case "log-level":
+		c.config.Node.LogLevel = nc.Node.LogLevel
+	case "log-module-levels":
+		c.config.Node.LogModuleLevels = nc.Node.LogModuleLevels
+	case "max-outgoing-connections":
+		c.config.Node.MaxOutgoingConnections = nc.Node.MaxOutgoingConnections
+	case "trusted-peers":
+		c.config.Node.TrustedPeers = nc.Node.TrustedPeers
+	case "private-peers":
+		c.config.Node.PrivatePeers = nc.Node.PrivatePeers
+	case "disable-csrf":
+		c.config.Node.DisableCSRF = nc.Node.DisableCSRF
+	case "enable-unversioned-api":
+		c.config.Node.EnableUnversionedAPI = nc.Node.EnableUnversionedAPI
+	case "enable-seed-api":
+		c.config.Node.EnableSeedAPI = nc.Node.EnableSeedAPI
+	case "rate-limit":
+		c.config.Node.RateLimit = nc.Node.RateLimit
+	case "rate-limit-burst":
+		c.config.Node.RateLimitBurst = nc.Node.RateLimitBurst
+	case "rate-limit-expensive":
+		c.config.Node.RateLimitExpensive = nc.Node.RateLimitExpensive
+	case "rate-limit-expensive-burst":
+		c.config.Node.RateLimitExpensiveBurst = nc.Node.RateLimitExpensiveBurst
+	}
+}
+
+// setRuntimeConfig applies the reloadable options of the running config to the running node
+func (c *Coin) setRuntimeConfig() {
+	// The log levels were validated by postProcess
+	if logLevel, err := logging.LevelFromString(c.config.Node.LogLevel); err == nil {
+		logging.SetLevel(logLevel)
+	}
+	if moduleLevels, err := logging.ModuleLevelsFromString(c.config.Node.LogModuleLevels); err == nil {
+		logging.SetModuleLevels(moduleLevels)
+	}
+
+	c.daemon.Gateway.SetRuntimeConfig(daemon.RuntimeConfig{
+		OutgoingMax:   c.config.Node.MaxOutgoingConnections,
+		TrustedPeers:  splitAddrs(c.config.Node.TrustedPeers),
+		PrivatePeers:  splitAddrs(c.config.Node.PrivatePeers),
+		EnableSeedAPI: c.config.Node.EnableSeedAPI,
+	})
+
+	if c.webInterface != nil {
+		c.webInterface.SetRuntimeConfig(api.RuntimeConfig{
+			DisableCSRF:          c.config.Node.DisableCSRF,
+			EnableUnversionedAPI: c.config.Node.EnableUnversionedAPI,
+			RateLimit: api.RateLimitConfig{
+				Rate:           c.config.Node.RateLimit,
+				Burst:          c.config.Node.RateLimitBurst,
+				ExpensiveRate:  c.config.Node.RateLimitExpensive,
+				ExpensiveBurst: c.config.Node.RateLimitExpensiveBurst,
+			},
+		})
+	}
+
+	if c.grpcInterface != nil {
+		c.grpcInterface.SetRateLimit(api.RateLimitConfig{
+			Rate:           c.config.Node.RateLimit,
+			Burst:          c.config.Node.RateLimitBurst,
+			ExpensiveRate:  c.config.Node.RateLimitExpensive,
+			ExpensiveBurst: c.config.Node.RateLimitExpensiveBurst,
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package skycoin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/logging"
+)
+
+func TestReloadConfig(t *testing.T) {
+	path := writeTestConfigFile(t, "node.toml", `
+log-level = "info"
+rate-limit = 10
+max-outgoing-connections = 8
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	c, fs := newTestConfig(t, filepath.Join(filepath.Dir(path), "data"))
+	coin := &Coin{
+		defaults: *c,
+		args:     []string{"-config", path, "-port", "7000"},
+		logger:   logging.MustGetLogger("test"),
+	}
+
+	require.NoError(t, c.parse(fs, coin.args))
+	require.NoError(t, c.postProcess())
+	coin.config = *c
+
+	// Nothing changed
+	nc, fs, err := coin.reparseConfig()
+	require.NoError(t, err)
+	result := coin.updateConfig(nc, fs)
+	require.Empty(t, result.Applied)
+	require.Empty(t, result.RestartRequired)
+
+	_, seckey := cipher.GenerateKeyPair()
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+log-level = "debug"
+log-module-levels = "daemon=warn"
+log-format = "json"
+rate-limit = 20
+rate-limit-expensive = 5
+max-outgoing-connections = 4
+trusted-peers = "1.2.3.4:6000"
+disable-csrf = true
+port = 7001
+web-interface-port = 7420
+master-secret-key = "`+seckey.Hex()+`"
+`), 0600))
+
+	// The command line args still override the file
+	nc, fs, err = coin.reparseConfig()
+	require.NoError(t, err)
+	result = coin.updateConfig(nc, fs)
+	require.Equal(t, []string{
+		"disable-csrf",
+		"log-level",
+		"log-module-levels",
+		"max-outgoing-connections",
+		"rate-limit",
+		"trusted-peers",
+	}, result.Applied)
+	// The expensive rate limit was disabled at startup, and can't be enabled without a restart
+	require.Equal(t, []string{
+		"log-format",
+		"master-secret-key",
+		"rate-limit-expensive",
+		"web-interface-port",
+	}, result.RestartRequired)
+
+	require.Equal(t, "debug", coin.config.Node.LogLevel)
+	require.Equal(t, "daemon=warn", coin.config.Node.LogModuleLevels)
+	require.Equal(t, "text", coin.config.Node.LogFormat)
+	require.Equal(t, 20.0, coin.config.Node.RateLimit)
+	require.Equal(t, 4, coin.config.Node.MaxOutgoingConnections)
+	require.Equal(t, "1.2.3.4:6000", coin.config.Node.TrustedPeers)
+	require.True(t, coin.config.Node.DisableCSRF)
+	require.Equal(t, 7000, coin.config.Node.Port)
+	require.NotEqual(t, 7420, coin.config.Node.WebInterfacePort)
+	require.Equal(t, 0.0, coin.config.Node.RateLimitExpensive)
+
+	// The options that require a restart are reported until the node is restarted
+	nc, fs, err = coin.reparseConfig()
+	require.NoError(t, err)
+	result = coin.updateConfig(nc, fs)
+	require.Empty(t, result.Applied)
+	require.Equal(t, []string{
+		"log-format",
+		"master-secret-key",
+		"rate-limit-expensive",
+		"web-interface-port",
+	}, result.RestartRequired)
+
+	// An invalid config is not applied
+	require.NoError(t, ioutil.WriteFile(path, []byte("log-level = \"loud\"\n"), 0600))
+	_, _, err = coin.reparseConfig()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid log-level")
+}
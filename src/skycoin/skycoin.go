@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/api/grpcapi"
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon"
@@ -29,6 +30,16 @@ import (
 type Coin struct {
 	config Config
 	logger *logging.Logger
+
+	// The config before parsing and the command line args, to parse the config again on reload
+	defaults Config
+	args     []string
+
+	// Set by Run for reloading the config
+	reloadLock    sync.Mutex
+	daemon        *daemon.Daemon
+	webInterface  *api.Server
+	grpcInterface *grpcapi.Server
 }
 
 // Run starts the node
@@ -42,6 +53,7 @@ func (c *Coin) Run() {
 	var db *dbutil.DB
 	var d *daemon.Daemon
 	var webInterface *api.Server
+	var grpcInterface *grpcapi.Server
 	errC := make(chan error, 10)
 
 	if c.config.Node.Version {
@@ -57,16 +69,23 @@ func (c *Coin) Run() {
 
 	logging.SetLevel(logLevel)
 
+	// The module levels and the format were validated by postProcess
+	moduleLevels, _ := logging.ModuleLevelsFromString(c.config.Node.LogModuleLevels)
+	logging.SetModuleLevels(moduleLevels)
+
+	logFormat, _ := logging.FormatFromString(c.config.Node.LogFormat)
+	logging.SetFormat(logFormat)
+
 	if c.config.Node.ColorLog {
 		logging.EnableColors()
 	} else {
 		logging.DisableColors()
 	}
 
-	var logFile *os.File
+	var logFile *logging.RotatingFile
 	if c.config.Node.LogToFile {
 		var err error
-		logFile, err = c.initLogFile()
+		logFile, err = c.initLogFile(logFormat)
 		if err != nil {
 			c.logger.Error(err)
 			return
@@ -109,7 +128,7 @@ func (c *Coin) Run() {
 	if c.config.Node.ResetCorruptDB {
 		// Check the database integrity and recreate it if necessary
 		c.logger.Info("Checking database and resetting if corrupted")
-		if newDB, err := visor.ResetCorruptDB(db, c.config.Node.blockchainPubkey, quit); err != nil {
+		if newDB, err := visor.ResetCorruptDB(db, dconf.Visor.BlockchainConfig(), quit); err != nil {
 			if err != visor.ErrVerifyStopped {
 				c.logger.Errorf("visor.ResetCorruptDB failed: %v", err)
 			}
@@ -119,7 +138,7 @@ func (c *Coin) Run() {
 		}
 	} else if c.config.Node.VerifyDB {
 		c.logger.Info("Checking database")
-		if err := visor.CheckDatabase(db, c.config.Node.blockchainPubkey, quit); err != nil {
+		if err := visor.CheckDatabase(db, dconf.Visor.BlockchainConfig(), quit); err != nil {
 			if err != visor.ErrVerifyStopped {
 				c.logger.Errorf("visor.CheckDatabase failed: %v", err)
 			}
@@ -141,6 +160,25 @@ func (c *Coin) Run() {
 		}
 	}
 
+	if c.config.Node.GRPCInterface {
+		grpcInterface, err = c.createGRPC(d)
+		if err != nil {
+			c.logger.Error(err)
+			goto earlyShutdown
+		}
+	}
+
+	c.daemon = d
+	c.webInterface = webInterface
+	c.grpcInterface = grpcInterface
+
+	// Catch SIGHUP (reloads the config)
+	go apputil.CatchHangup(quit, func() {
+		if _, err := c.ReloadConfig(); err != nil {
+			c.logger.WithError(err).Error("Config reload failed")
+		}
+	})
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -182,6 +220,18 @@ func (c *Coin) Run() {
 		}
 	}
 
+	if grpcInterface != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := grpcInterface.Serve(); err != nil {
+				c.logger.Error(err)
+				errC <- err
+			}
+		}()
+	}
+
 	/*
 	   time.Sleep(5)
 	   tx := InitTransaction()
@@ -221,6 +271,11 @@ func (c *Coin) Run() {
 		webInterface.Shutdown()
 	}
 
+	if grpcInterface != nil {
+		c.logger.Info("Closing gRPC interface")
+		grpcInterface.Shutdown()
+	}
+
 	c.logger.Info("Closing daemon")
 	d.Shutdown()
 
@@ -252,7 +307,7 @@ func NewCoin(config Config, logger *logging.Logger) *Coin {
 	}
 }
 
-func (c *Coin) initLogFile() (*os.File, error) {
+func (c *Coin) initLogFile(format logging.Format) (*logging.RotatingFile, error) {
 	logDir := filepath.Join(c.config.Node.DataDirectory, "logs")
 	if err := createDirIfNotExist(logDir); err != nil {
 		c.logger.Errorf("createDirIfNotExist(%s) failed: %v", logDir, err)
@@ -261,15 +316,21 @@ func (c *Coin) initLogFile() (*os.File, error) {
 
 	// open log file
 	tf := "2006-01-02-030405"
-	logfile := filepath.Join(logDir, fmt.Sprintf("%s-v%s.log", time.Now().Format(tf), c.config.Build.Version))
-
-	f, err := os.OpenFile(logfile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	f, err := logging.NewRotatingFile(logging.RotatingFileConfig{
+		Dir: logDir,
+		Name: func(t time.Time) string {
+			return fmt.Sprintf("%s-v%s.log", t.Format(tf), c.config.Build.Version)
+		},
+		MaxSize:  int64(c.config.Node.LogFileMaxSize) * 1024 * 1024,
+		MaxAge:   c.config.Node.LogFileMaxAge,
+		MaxFiles: c.config.Node.LogFileMaxFiles,
+	})
 	if err != nil {
-		c.logger.Errorf("os.OpenFile(%s) failed: %v", logfile, err)
+		c.logger.Errorf("logging.NewRotatingFile(%s) failed: %v", logDir, err)
 		return nil, err
 	}
 
-	hook := logging.NewWriteHook(f)
+	hook := logging.NewWriteHook(f, format)
 	logging.AddHook(hook)
 
 	return f, nil
@@ -316,20 +377,34 @@ func (c *Coin) configureDaemon() daemon.Config {
 	dc.Daemon.DataDirectory = c.config.Node.DataDirectory
 	dc.Daemon.LogPings = !c.config.Node.DisablePingPong
 
-	if c.config.Node.OutgoingConnectionsRate == 0 {
-		c.config.Node.OutgoingConnectionsRate = time.Millisecond
-	}
 	dc.Daemon.OutgoingRate = c.config.Node.OutgoingConnectionsRate
+	if dc.Daemon.OutgoingRate == 0 {
+		dc.Daemon.OutgoingRate = time.Millisecond
+	}
+	dc.Daemon.TrustedPeers = splitAddrs(c.config.Node.TrustedPeers)
+	dc.Daemon.PrivatePeers = splitAddrs(c.config.Node.PrivatePeers)
 	dc.Visor.IsMaster = c.config.Node.RunMaster
 
 	dc.Visor.BlockchainPubkey = c.config.Node.blockchainPubkey
 	dc.Visor.BlockchainSeckey = c.config.Node.blockchainSeckey
+	dc.Visor.BlockchainSignerPubkeys = c.config.Node.blockchainSignerPubkeys
+	dc.Visor.BlockchainSignerSeckeys = c.config.Node.blockchainSignerSeckeys
+	dc.Visor.TrustedKeyRotations = c.config.Node.trustedKeyRotations
+
+	dc.Visor.BlockMakerPubkeys = c.config.Node.blockMakerPubkeys
+	dc.Visor.BlockMakerQuorum = c.config.Node.BlockMakerQuorum
+	dc.Visor.BlockMakerSeckey = c.config.Node.blockMakerSeckey
+	dc.Daemon.BlockProposalTimeout = c.config.Node.BlockProposalTimeout
 
 	dc.Visor.GenesisAddress = c.config.Node.genesisAddress
 	dc.Visor.GenesisSignature = c.config.Node.genesisSignature
 	dc.Visor.GenesisTimestamp = c.config.Node.GenesisTimestamp
 	dc.Visor.GenesisCoinVolume = c.config.Node.GenesisCoinVolume
 	dc.Visor.DBPath = c.config.Node.DBPath
+	dc.Visor.MaxUnconfirmedTxnsSize = c.config.Node.MaxUnconfirmedTxnsSize
+	dc.Visor.MaxUnconfirmedChainDepth = c.config.Node.MaxUnconfirmedChainDepth
+	dc.Visor.MinReplacementFeeKB = c.config.Node.MinReplacementFeeKB
+	dc.Visor.UnconfirmedTxnsMaxAge = c.config.Node.UnconfirmedTxnsMaxAge
 	dc.Visor.Arbitrating = c.config.Node.Arbitrating
 	dc.Visor.EnableWalletAPI = c.config.Node.EnableWalletAPI
 	dc.Visor.WalletDirectory = c.config.Node.WalletDirectory
@@ -364,9 +439,20 @@ func (c *Coin) createGUI(d *daemon.Daemon, host string) (*api.Server, error) {
 		EnableJSON20RPC:      c.config.Node.RPCInterface,
 		EnableGUI:            c.config.Node.EnableGUI,
 		EnableUnversionedAPI: c.config.Node.EnableUnversionedAPI,
+		EnableAuth:           c.config.Node.EnableAPIAuth,
+		TokensFile:           c.config.Node.APITokensFile,
+		MaxBlockRange:        c.config.Node.MaxBlockRange,
+		MaxRequestAddresses:  c.config.Node.MaxRequestAddresses,
 		ReadTimeout:          c.config.Node.ReadTimeout,
 		WriteTimeout:         c.config.Node.WriteTimeout,
 		IdleTimeout:          c.config.Node.IdleTimeout,
+		ConfigReloader:       c,
+		RateLimit: api.RateLimitConfig{
+			Rate:           c.config.Node.RateLimit,
+			Burst:          c.config.Node.RateLimitBurst,
+			ExpensiveRate:  c.config.Node.RateLimitExpensive,
+			ExpensiveBurst: c.config.Node.RateLimitExpensiveBurst,
+		},
 	}
 
 	if c.config.Node.WebInterfaceHTTPS {
@@ -388,15 +474,76 @@ func (c *Coin) createGUI(d *daemon.Daemon, host string) (*api.Server, error) {
 	return s, nil
 }
 
-// ParseConfig prepare the config
+func (c *Coin) createGRPC(d *daemon.Daemon) (*grpcapi.Server, error) {
+	var s *grpcapi.Server
+	var err error
+
+	host := fmt.Sprintf("%s:%d", c.config.Node.GRPCInterfaceAddr, c.config.Node.GRPCInterfacePort)
+
+	config := grpcapi.Config{
+		MaxBlockRange:       c.config.Node.MaxBlockRange,
+		MaxRequestAddresses: c.config.Node.MaxRequestAddresses,
+		RateLimit: api.RateLimitConfig{
+			Rate:           c.config.Node.RateLimit,
+			Burst:          c.config.Node.RateLimitBurst,
+			ExpensiveRate:  c.config.Node.RateLimitExpensive,
+			ExpensiveBurst: c.config.Node.RateLimitExpensiveBurst,
+		},
+	}
+
+	if c.config.Node.EnableAPIAuth {
+		config.Tokens, err = api.LoadTokenStore(c.config.Node.APITokensFile)
+		if err != nil {
+			c.logger.Errorf("Failed to load the API tokens for the gRPC interface: %v", err)
+			return nil, err
+		}
+	}
+
+	if c.config.Node.GRPCInterfaceTLS {
+		// Verify cert/key parameters, and if neither exist, create them
+		if err := cert.CreateCertIfNotExists(host, c.config.Node.GRPCInterfaceCert, c.config.Node.GRPCInterfaceKey, "Skycoind"); err != nil {
+			c.logger.Errorf("cert.CreateCertIfNotExists failure: %v", err)
+			return nil, err
+		}
+
+		s, err = grpcapi.CreateTLS(host, config, d.Gateway, c.config.Node.GRPCInterfaceCert, c.config.Node.GRPCInterfaceKey)
+	} else {
+		s, err = grpcapi.Create(host, config, d.Gateway)
+	}
+	if err != nil {
+		c.logger.Errorf("Failed to start gRPC interface: %v", err)
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// ParseConfig prepare the config from the defaults, the config file, the environment variables
+// and the command line flags, in increasing order of precedence
 func (c *Coin) ParseConfig() {
-	c.config.register()
-	flag.Parse()
+	c.defaults = c.config
+	c.args = os.Args[1:]
+
+	c.config.register(flag.CommandLine)
+	if err := c.config.parse(flag.CommandLine, c.args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 	if help {
 		flag.Usage()
 		os.Exit(0)
 	}
-	c.config.postProcess()
+	if err := c.config.postProcess(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if c.config.Node.PrintConfig {
+		if err := c.config.printConfig(os.Stdout, flag.CommandLine); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 }
 
 // InitTransaction creates the initialize transaction
@@ -46,6 +46,21 @@ func CatchDebug() {
 	}
 }
 
+// CatchHangup calls f each time SIGHUP is received, until quit is closed
+func CatchHangup(quit <-chan struct{}, f func()) {
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, syscall.SIGHUP)
+	defer signal.Stop(sigchan)
+	for {
+		select {
+		case <-sigchan:
+			f()
+		case <-quit:
+			return
+		}
+	}
+}
+
 // PrintProgramStatus prints all goroutine data to stdout
 func PrintProgramStatus() {
 	p := pprof.Lookup("goroutine")
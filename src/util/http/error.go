@@ -80,6 +80,11 @@ func Error405(w http.ResponseWriter) {
 	httpError(w, http.StatusMethodNotAllowed)
 }
 
+// Error413JSONOr500 returns a 413 error with an object as JSON, writting a 500 error if it fails
+func Error413JSONOr500(log *logging.Logger, w http.ResponseWriter, m interface{}) {
+	errorXXXJSONOr500(log, w, http.StatusRequestEntityTooLarge, m)
+}
+
 // Error415 respond with a 415 error
 func Error415(w http.ResponseWriter) {
 	httpError(w, http.StatusUnsupportedMediaType)
@@ -95,6 +100,11 @@ func Error422JSONOr500(log *logging.Logger, w http.ResponseWriter, m interface{}
 	errorXXXJSONOr500(log, w, http.StatusUnprocessableEntity, m)
 }
 
+// Error429 respond with a 429 error and include a message
+func Error429(w http.ResponseWriter, msg string) {
+	errorXXXMsg(w, http.StatusTooManyRequests, msg)
+}
+
 // Error501 respond with a 501 error
 func Error501(w http.ResponseWriter) {
 	httpError(w, http.StatusNotImplemented)
@@ -7,17 +7,23 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/skycoin/skycoin/src/util/logging"
 )
 
-// ElapsedHandler records and logs an HTTP request with the elapsed time and status code
+// ElapsedHandler records and logs an HTTP request with the elapsed time, status code and request ID
 func ElapsedHandler(logger logrus.FieldLogger, handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		lrw := newWrappedResponseWriter(w)
 		start := time.Now()
 		handler.ServeHTTP(lrw, r)
-		logMethod := logger.Infof
+		log := logger
+		if id := RequestID(r.Context()); id != "" {
+			log = log.WithField(logging.RequestIDKey, id)
+		}
+		logMethod := log.Infof
 		if lrw.statusCode >= 400 {
-			logMethod = logger.WithFields(logrus.Fields{
+			logMethod = log.WithFields(logrus.Fields{
 				"body": strings.TrimSpace(lrw.response.String()),
 			}).Errorf
 		}
@@ -0,0 +1,62 @@
+package httphelper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDHandler(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{
+			name: "generated",
+		},
+		{
+			name:   "from header",
+			header: "abc-123.def_4",
+			keep:   true,
+		},
+		{
+			name:   "invalid header",
+			header: "abc 123",
+		},
+		{
+			name:   "header too long",
+			header: strings.Repeat("a", 65),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var id string
+			handler := RequestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				id = RequestID(r.Context())
+			}))
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+			if tc.header != "" {
+				req.Header.Set(RequestIDHeader, tc.header)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.NotEmpty(t, id)
+			require.Equal(t, id, rr.Header().Get(RequestIDHeader))
+			if tc.keep {
+				require.Equal(t, tc.header, id)
+			} else {
+				require.NotEqual(t, tc.header, id)
+				require.Len(t, id, 16)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Format is the format of the log lines
+type Format string
+
+const (
+	// FormatText formats log lines as text
+	FormatText Format = "text"
+	// FormatJSON formats log lines as JSON objects, one per line
+	FormatJSON Format = "json"
+)
+
+// FormatFromString returns a Format from a string identifier
+func FormatFromString(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q", s)
+	}
+}
+
+// ModuleLevelsFromString parses per-module log levels formatted as "module=level,module=level",
+// for example "daemon=debug,visor=warn"
+func ModuleLevelsFromString(s string) (map[string]logrus.Level, error) {
+	levels := make(map[string]logrus.Level)
+	for _, ml := range strings.Split(s, ",") {
+		ml = strings.TrimSpace(ml)
+		if ml == "" {
+			continue
+		}
+
+		pts := strings.Split(ml, "=")
+		if len(pts) != 2 || strings.TrimSpace(pts[0]) == "" {
+			return nil, fmt.Errorf("invalid module log level %q, must be formatted as module=level", ml)
+		}
+
+		level, err := LevelFromString(strings.TrimSpace(pts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid module log level %q: %v", ml, err)
+		}
+
+		levels[strings.TrimSpace(pts[0])] = level
+	}
+
+	return levels, nil
+}
+
+// newFormatter creates a logrus.Formatter for a Format
+func newFormatter(format Format, colors bool) logrus.Formatter {
+	switch format {
+	case FormatJSON:
+		return &JSONFormatter{}
+	default:
+		return &TextFormatter{
+			FullTimestamp:      true,
+			AlwaysQuoteStrings: true,
+			QuoteEmptyFields:   true,
+			ForceFormatting:    true,
+			DisableColors:      !colors,
+		}
+	}
+}
+
+// JSONFormatter formats log entries as JSON objects.
+// The module and priority fields are named "module" and "priority".
+type JSONFormatter struct {
+	logrus.JSONFormatter
+}
+
+// Format renders a single log entry
+func (f *JSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	_, hasModule := entry.Data[logModuleKey]
+	_, hasPriority := entry.Data[logPriorityKey]
+	if !hasModule && !hasPriority {
+		return f.JSONFormatter.Format(entry)
+	}
+
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		switch k {
+		case logModuleKey:
+			data["module"] = v
+		case logPriorityKey:
+			data["priority"] = v
+		default:
+			data[k] = v
+		}
+	}
+
+	e := *entry
+	e.Data = data
+	return f.JSONFormatter.Format(&e)
+}
@@ -2,27 +2,24 @@ package logging
 
 import (
 	"io"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
 
 // WriteHook is a logrus.Hook that logs to an io.Writer
 type WriteHook struct {
+	// Serializes the writes of the module loggers to w
+	lock      sync.Mutex
 	w         io.Writer
 	formatter logrus.Formatter
 }
 
-// NewWriteHook returns a new WriteHook
-func NewWriteHook(w io.Writer) *WriteHook {
+// NewWriteHook returns a new WriteHook that writes log lines in the given format, without colors
+func NewWriteHook(w io.Writer, format Format) *WriteHook {
 	return &WriteHook{
-		w: w,
-		formatter: &TextFormatter{
-			DisableColors:      true,
-			FullTimestamp:      true,
-			AlwaysQuoteStrings: true,
-			QuoteEmptyFields:   true,
-			ForceFormatting:    true,
-		},
+		w:         w,
+		formatter: newFormatter(format, false),
 	}
 }
 
@@ -39,6 +36,8 @@ func (f *WriteHook) Fire(e *logrus.Entry) error {
 		return err
 	}
 
+	f.lock.Lock()
+	defer f.lock.Unlock()
 	_, err = f.w.Write(b)
 	return err
 }
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"os"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
@@ -20,6 +21,15 @@ func (logger *Logger) Critical() logrus.FieldLogger {
 // MasterLogger wraps logrus.Logger and is able to create new package-aware loggers
 type MasterLogger struct {
 	*logrus.Logger
+
+	// Serializes the writes of the module loggers to Out
+	outLock sync.Mutex
+
+	lock sync.Mutex
+	// The logrus.Logger of each module, which filters the module's entries by its level
+	modules map[string]*logrus.Logger
+	// Module levels that override the logger's level
+	moduleLevels map[string]logrus.Level
 }
 
 // NewMasterLogger creates a new package-aware logger with formatting string
@@ -28,25 +38,36 @@ func NewMasterLogger() *MasterLogger {
 
 	return &MasterLogger{
 		Logger: &logrus.Logger{
-			Out: os.Stdout,
-			Formatter: &TextFormatter{
-				FullTimestamp:      true,
-				AlwaysQuoteStrings: true,
-				QuoteEmptyFields:   true,
-				ForceFormatting:    true,
-				DisableColors:      false,
-				ForceColors:        false,
-			},
-			Hooks: hooks,
-			Level: logrus.DebugLevel,
+			Out:       os.Stdout,
+			Formatter: newFormatter(FormatText, true),
+			Hooks:     hooks,
+			Level:     logrus.DebugLevel,
 		},
+		modules:      make(map[string]*logrus.Logger),
+		moduleLevels: make(map[string]logrus.Level),
 	}
 }
 
-// PackageLogger instantiates a package-aware logger
+// PackageLogger instantiates a package-aware logger.
+// The entries of the logger are written to the master logger's output with its formatter and hooks,
+// if their level is enabled for the module.
 func (logger *MasterLogger) PackageLogger(moduleName string) *Logger {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	ml, ok := logger.modules[moduleName]
+	if !ok {
+		ml = &logrus.Logger{
+			Out:       masterWriter{logger},
+			Formatter: masterFormatter{logger},
+			Hooks:     logger.Hooks,
+			Level:     logger.moduleLevel(moduleName),
+		}
+		logger.modules[moduleName] = ml
+	}
+
 	return &Logger{
-		FieldLogger: logger.WithField(logModuleKey, moduleName),
+		FieldLogger: ml.WithField(logModuleKey, moduleName),
 	}
 }
 
@@ -55,17 +76,83 @@ func (logger *MasterLogger) AddHook(hook logrus.Hook) {
 	logger.Hooks.Add(hook)
 }
 
-// SetLevel sets the log level for the logger and its module loggers
+// SetLevel sets the log level for the logger and its module loggers,
+// except the modules with a level override
 func (logger *MasterLogger) SetLevel(level logrus.Level) {
-	logger.Level = level
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	logger.Logger.SetLevel(level)
+	logger.updateModuleLevels()
 }
 
-// EnableColors enables colored logging
+// SetModuleLevels replaces the log level overrides of the module loggers.
+// The modules without an override log at the logger's level.
+func (logger *MasterLogger) SetModuleLevels(levels map[string]logrus.Level) {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	logger.moduleLevels = make(map[string]logrus.Level, len(levels))
+	for module, level := range levels {
+		logger.moduleLevels[module] = level
+	}
+	logger.updateModuleLevels()
+}
+
+// updateModuleLevels sets the level of the module loggers. Must be called under lock.
+func (logger *MasterLogger) updateModuleLevels() {
+	for module, ml := range logger.modules {
+		ml.SetLevel(logger.moduleLevel(module))
+	}
+}
+
+// moduleLevel returns the level of a module. Must be called under lock.
+func (logger *MasterLogger) moduleLevel(module string) logrus.Level {
+	if level, ok := logger.moduleLevels[module]; ok {
+		return level
+	}
+	return logger.Logger.Level
+}
+
+// SetFormat sets the format of the log lines written to the logger's output
+func (logger *MasterLogger) SetFormat(format Format) {
+	colors := false
+	if f, ok := logger.Formatter.(*TextFormatter); ok {
+		colors = !f.DisableColors
+	}
+	logger.Formatter = newFormatter(format, colors)
+}
+
+// EnableColors enables colored logging. Only the text format is colored.
 func (logger *MasterLogger) EnableColors() {
-	logger.Formatter.(*TextFormatter).DisableColors = false
+	if f, ok := logger.Formatter.(*TextFormatter); ok {
+		f.DisableColors = false
+	}
 }
 
 // DisableColors disables colored logging
 func (logger *MasterLogger) DisableColors() {
-	logger.Formatter.(*TextFormatter).DisableColors = true
+	if f, ok := logger.Formatter.(*TextFormatter); ok {
+		f.DisableColors = true
+	}
+}
+
+// masterWriter writes the entries of the module loggers to the master logger's output
+type masterWriter struct {
+	logger *MasterLogger
+}
+
+func (w masterWriter) Write(p []byte) (int, error) {
+	w.logger.outLock.Lock()
+	defer w.logger.outLock.Unlock()
+	return w.logger.Out.Write(p)
+}
+
+// masterFormatter formats the entries of the module loggers with the master logger's formatter
+type masterFormatter struct {
+	logger *MasterLogger
+}
+
+func (f masterFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	return f.logger.Formatter.Format(e)
 }
@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleLevels(t *testing.T) {
+	master := NewMasterLogger()
+	var buf bytes.Buffer
+	master.Out = &buf
+	master.SetLevel(logrus.InfoLevel)
+
+	daemon := master.PackageLogger("daemon")
+	visor := master.PackageLogger("visor")
+	api := master.PackageLogger("api")
+
+	master.SetModuleLevels(map[string]logrus.Level{
+		"daemon": logrus.DebugLevel,
+		"visor":  logrus.WarnLevel,
+	})
+
+	// A module logger created after the levels are set uses its module's level
+	wallet := master.PackageLogger("wallet")
+	visor2 := master.PackageLogger("visor")
+
+	daemon.Debug("daemon debug")
+	visor.Info("visor info")
+	visor.WithField("foo", "bar").Warning("visor warning")
+	api.Debug("api debug")
+	api.Info("api info")
+	wallet.Debug("wallet debug")
+	visor2.Info("visor info 2")
+
+	out := buf.String()
+	require.Contains(t, out, "daemon debug")
+	require.NotContains(t, out, "visor info")
+	require.Contains(t, out, "visor warning")
+	require.NotContains(t, out, "api debug")
+	require.Contains(t, out, "api info")
+	require.NotContains(t, out, "wallet debug")
+
+	// The modules without an override follow the logger's level
+	buf.Reset()
+	master.SetLevel(logrus.DebugLevel)
+	api.Debug("api debug")
+	visor.Info("visor info")
+	require.Contains(t, buf.String(), "api debug")
+	require.NotContains(t, buf.String(), "visor info")
+
+	// Removing the overrides
+	buf.Reset()
+	master.SetModuleLevels(nil)
+	visor.Info("visor info")
+	require.Contains(t, buf.String(), "visor info")
+}
+
+func TestModuleLevelsFromString(t *testing.T) {
+	levels, err := ModuleLevelsFromString(" daemon=debug, visor=WARN,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]logrus.Level{
+		"daemon": logrus.DebugLevel,
+		"visor":  logrus.WarnLevel,
+	}, levels)
+
+	levels, err = ModuleLevelsFromString("")
+	require.NoError(t, err)
+	require.Empty(t, levels)
+
+	for _, s := range []string{"daemon", "=debug", "daemon=loud", "daemon=debug=info"} {
+		_, err := ModuleLevelsFromString(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	master := NewMasterLogger()
+	var buf bytes.Buffer
+	master.Out = &buf
+	master.SetFormat(FormatJSON)
+	master.EnableColors()
+
+	logger := master.PackageLogger("daemon")
+	logger.Critical().WithField(RequestIDKey, "abc").Info("foo")
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "daemon", line["module"])
+	require.Equal(t, logPriorityCritical, line["priority"])
+	require.Equal(t, "abc", line[RequestIDKey])
+	require.Equal(t, "foo", line["msg"])
+	require.Equal(t, "info", line["level"])
+	require.NotContains(t, line, logModuleKey)
+
+	buf.Reset()
+	master.SetFormat(FormatText)
+	logger.Info("bar")
+	require.Contains(t, buf.String(), "bar")
+	require.Error(t, json.Unmarshal(buf.Bytes(), &line))
+}
@@ -18,6 +18,9 @@ const (
 	logPriorityKey = "_priority"
 	// logPriorityCritical is the log entry value for priority log statements
 	logPriorityCritical = "CRITICAL"
+
+	// RequestIDKey is the log entry key for the ID of the API request that caused the log statement
+	RequestIDKey = "request_id"
 )
 
 // LevelFromString returns a logrus.Level from a string identifier
@@ -65,6 +68,16 @@ func SetLevel(level logrus.Level) {
 	log.SetLevel(level)
 }
 
+// SetModuleLevels sets the log levels of modules that override the logger's minimum log level
+func SetModuleLevels(levels map[string]logrus.Level) {
+	log.SetModuleLevels(levels)
+}
+
+// SetFormat sets the format of the log lines
+func SetFormat(format Format) {
+	log.SetFormat(format)
+}
+
 // SetOutputTo sets the logger's output to an io.Writer
 func SetOutputTo(w io.Writer) {
 	log.Out = w
@@ -0,0 +1,207 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFileConfig configures a RotatingFile
+type RotatingFileConfig struct {
+	// Directory of the log files
+	Dir string
+	// Name returns the name of a log file created at a time. The name must end with ".log".
+	Name func(time.Time) string
+	// A new file is started when a write would make the file larger than MaxSize bytes. 0 disables size-based rotation.
+	MaxSize int64
+	// A new file is started when the file is older than MaxAge. 0 disables time-based rotation.
+	MaxAge time.Duration
+	// Number of log files kept in Dir, including the current one. The oldest files are removed. 0 keeps all files.
+	MaxFiles int
+}
+
+// RotatingFile is an io.WriteCloser that writes to a log file and starts a new file
+// when the file reaches a size or age
+type RotatingFile struct {
+	config RotatingFileConfig
+	now    func() time.Time
+
+	lock    sync.Mutex
+	f       *os.File
+	size    int64
+	created time.Time
+}
+
+// NewRotatingFile creates a RotatingFile and opens its first log file
+func NewRotatingFile(c RotatingFileConfig) (*RotatingFile, error) {
+	if c.Name == nil {
+		return nil, errors.New("RotatingFileConfig.Name is required")
+	}
+	if c.MaxSize < 0 || c.MaxAge < 0 || c.MaxFiles < 0 {
+		return nil, errors.New("RotatingFileConfig limits must not be negative")
+	}
+
+	r := &RotatingFile{
+		config: c,
+		now:    time.Now,
+	}
+
+	if err := r.rotate(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Write writes to the log file, starting a new file first if the limits would be exceeded
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+
+	if r.shouldRotate(len(p)) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Name returns the path of the current log file
+func (r *RotatingFile) Name() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.f == nil {
+		return ""
+	}
+	return r.f.Name()
+}
+
+// Close closes the current log file
+func (r *RotatingFile) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.f == nil {
+		return nil
+	}
+
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// shouldRotate returns whether a new file should be started before writing n bytes.
+// An empty file is never rotated, so that a line larger than MaxSize is still written.
+func (r *RotatingFile) shouldRotate(n int) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.config.MaxSize > 0 && r.size+int64(n) > r.config.MaxSize {
+		return true
+	}
+	return r.config.MaxAge > 0 && r.now().Sub(r.created) >= r.config.MaxAge
+}
+
+// rotate closes the current file, opens a new file and removes the files exceeding MaxFiles
+func (r *RotatingFile) rotate() error {
+	if r.f != nil {
+		if err := r.f.Close(); err != nil {
+			return err
+		}
+		r.f = nil
+	}
+
+	now := r.now()
+	path, err := r.newPath(now)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	r.f = f
+	r.size = 0
+	r.created = now
+
+	return r.removeOldFiles()
+}
+
+// newPath returns the path of a new log file that doesn't exist yet.
+// If a file with the name already exists, a counter is added to the name.
+func (r *RotatingFile) newPath(now time.Time) (string, error) {
+	name := r.config.Name(now)
+	if !strings.HasSuffix(name, ".log") {
+		return "", fmt.Errorf("log file name %q must end with .log", name)
+	}
+
+	path := filepath.Join(r.config.Dir, name)
+	base := strings.TrimSuffix(path, ".log")
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path, nil
+		} else if err != nil {
+			return "", err
+		}
+		path = fmt.Sprintf("%s.%d.log", base, i)
+	}
+}
+
+// removeOldFiles removes the oldest log files of Dir, keeping MaxFiles files
+func (r *RotatingFile) removeOldFiles() error {
+	if r.config.MaxFiles == 0 {
+		return nil
+	}
+
+	entries, err := ioutil.ReadDir(r.config.Dir)
+	if err != nil {
+		return err
+	}
+
+	var files []os.FileInfo
+	for _, e := range entries {
+		if e.Mode().IsRegular() && strings.HasSuffix(e.Name(), ".log") {
+			files = append(files, e)
+		}
+	}
+
+	if len(files) <= r.config.MaxFiles {
+		return nil
+	}
+
+	current := filepath.Base(r.f.Name())
+	sort.Slice(files, func(i, j int) bool {
+		// The current file is the newest, even if the clock moved backwards
+		if files[i].Name() == current || files[j].Name() == current {
+			return files[j].Name() == current
+		}
+		if files[i].ModTime().Equal(files[j].ModTime()) {
+			return files[i].Name() < files[j].Name()
+		}
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, f := range files[:len(files)-r.config.MaxFiles] {
+		if err := os.Remove(filepath.Join(r.config.Dir, f.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func listLogFiles(t *testing.T, dir string) map[string]string {
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+
+	files := make(map[string]string, len(entries))
+	for _, e := range entries {
+		b, err := ioutil.ReadFile(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		files[e.Name()] = string(b)
+	}
+	return files
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotatingfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = NewRotatingFile(RotatingFileConfig{
+		Dir: dir,
+		Name: func(time.Time) string {
+			return "foo.txt"
+		},
+	})
+	require.Error(t, err)
+
+	r, err := NewRotatingFile(RotatingFileConfig{
+		Dir: dir,
+		Name: func(time.Time) string {
+			return "foo.log"
+		},
+		MaxSize: 10,
+	})
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		"aaaa\n",
+		"bbbb\n",
+		// A line larger than MaxSize is written to a new file
+		"cccccccccccc\n",
+		"dd\n",
+	} {
+		n, err := r.Write([]byte(line))
+		require.NoError(t, err)
+		require.Equal(t, len(line), n)
+	}
+
+	// The name of a new file is made unique if the file exists
+	require.Equal(t, filepath.Join(dir, "foo.2.log"), r.Name())
+	require.NoError(t, r.Close())
+	require.Equal(t, "", r.Name())
+
+	require.Equal(t, map[string]string{
+		"foo.log":   "aaaa\nbbbb\n",
+		"foo.1.log": "cccccccccccc\n",
+		"foo.2.log": "dd\n",
+	}, listLogFiles(t, dir))
+}
+
+func TestRotatingFileMaxAgeAndMaxFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotatingfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := &RotatingFile{
+		config: RotatingFileConfig{
+			Dir: dir,
+			Name: func(t time.Time) string {
+				return fmt.Sprintf("%s.log", t.Format("150405"))
+			},
+			MaxAge:   time.Hour,
+			MaxFiles: 2,
+		},
+		now: func() time.Time {
+			return now
+		},
+	}
+	require.NoError(t, r.rotate())
+
+	// Files that are not log files are kept
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0600))
+
+	_, err = r.Write([]byte("a\n"))
+	require.NoError(t, err)
+
+	now = now.Add(59 * time.Minute)
+	_, err = r.Write([]byte("b\n"))
+	require.NoError(t, err)
+
+	for _, line := range []string{"c\n", "d\n", "e\n"} {
+		now = now.Add(time.Hour)
+		_, err = r.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.Close())
+
+	files := listLogFiles(t, dir)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	require.Equal(t, []string{"060305.log", "070305.log", "notes.txt"}, names)
+	require.Equal(t, "d\n", files["060305.log"])
+	require.Equal(t, "e\n", files["070305.log"])
+
+	_, err = r.Write([]byte("f\n"))
+	require.Equal(t, os.ErrClosed, err)
+}
@@ -0,0 +1,484 @@
+/*
+Package metrics implements counters, gauges and histograms that can be exported
+in the Prometheus text exposition format, without depending on the Prometheus client library.
+
+Metrics are registered in a Registry, usually DefaultRegistry, when they are created:
+
+	var blocksExecuted = metrics.NewCounter("skycoin_blocks_executed_total", "Number of blocks executed")
+
+	blocksExecuted.Inc()
+
+Metrics with labels are created with the Vec constructors, and a metric is created for each set of label values:
+
+	var requests = metrics.NewCounterVec("skycoin_http_requests_total", "HTTP requests", "route", "code")
+
+	requests.WithLabelValues("/api/v1/health", "200").Inc()
+*/
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+var (
+	// DefaultRegistry is the Registry that metrics are registered in by the package level constructors
+	DefaultRegistry = NewRegistry()
+
+	// DefaultDurationBuckets are histogram buckets for durations in seconds, from 1ms to 10s
+	DefaultDurationBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+)
+
+// collector is a family of metrics with the same name
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry is a set of metrics that are written together
+type Registry struct {
+	collectors map[string]collector
+	sync.Mutex
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make(map[string]collector),
+	}
+}
+
+// register adds a collector to the registry. Panics if a metric with the same name is already registered.
+func (r *Registry) register(c collector) {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.collectors[c.name()]; ok {
+		panic(fmt.Sprintf("metric %s is already registered", c.name()))
+	}
+
+	r.collectors[c.name()] = c
+}
+
+// WriteText writes the metrics in the Prometheus text exposition format, ordered by name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.Lock()
+	collectors := make([]collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].name() < collectors[j].name()
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+// desc describes a family of metrics
+type desc struct {
+	fqName     string
+	help       string
+	metricType string
+	labelNames []string
+}
+
+func (d *desc) name() string {
+	return d.fqName
+}
+
+func (d *desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.fqName, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.fqName, d.metricType)
+}
+
+// formatLabels formats label names and values as {a="x",b="y"}, with extra appended to the labels
+func formatLabels(names, values []string, extra ...string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(names)+len(extra)/2)
+	for i, n := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", n, escapeLabelValue(values[i])))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", extra[i], escapeLabelValue(extra[i+1])))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeHelp(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return strings.Replace(s, "\n", `\n`, -1)
+}
+
+// escapeLabelValue escapes newlines, since %q escapes quotes and backslashes
+func escapeLabelValue(s string) string {
+	return strings.Replace(s, "\n", " ", -1)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// atomicFloat is a float64 that can be updated atomically
+type atomicFloat struct {
+	bits uint64
+}
+
+func (f *atomicFloat) add(v float64) {
+	for {
+		old := atomic.LoadUint64(&f.bits)
+		n := math.Float64bits(math.Float64frombits(old) + v)
+		if atomic.CompareAndSwapUint64(&f.bits, old, n) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) set(v float64) {
+	atomic.StoreUint64(&f.bits, math.Float64bits(v))
+}
+
+func (f *atomicFloat) load() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&f.bits))
+}
+
+// Counter is a value that only increases
+type Counter struct {
+	v atomicFloat
+}
+
+// Inc increments the counter by 1
+func (c *Counter) Inc() {
+	c.v.add(1)
+}
+
+// Add increments the counter by v. v must not be negative.
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("counter cannot decrease")
+	}
+	c.v.add(v)
+}
+
+// Value returns the value of the counter
+func (c *Counter) Value() float64 {
+	return c.v.load()
+}
+
+// Gauge is a value that can increase and decrease
+type Gauge struct {
+	v atomicFloat
+}
+
+// Set sets the gauge to v
+func (g *Gauge) Set(v float64) {
+	g.v.set(v)
+}
+
+// Add adds v to the gauge
+func (g *Gauge) Add(v float64) {
+	g.v.add(v)
+}
+
+// Inc increments the gauge by 1
+func (g *Gauge) Inc() {
+	g.v.add(1)
+}
+
+// Dec decrements the gauge by 1
+func (g *Gauge) Dec() {
+	g.v.add(-1)
+}
+
+// Value returns the value of the gauge
+func (g *Gauge) Value() float64 {
+	return g.v.load()
+}
+
+// Histogram counts observations in buckets
+type Histogram struct {
+	upperBounds []float64
+	counts      []uint64
+	count       uint64
+	sum         float64
+	sync.Mutex
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	upperBounds := make([]float64, len(buckets))
+	copy(upperBounds, buckets)
+	sort.Float64s(upperBounds)
+
+	return &Histogram{
+		upperBounds: upperBounds,
+		counts:      make([]uint64, len(upperBounds)),
+	}
+}
+
+// Observe adds an observation
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upperBounds, v)
+
+	h.Lock()
+	defer h.Unlock()
+
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+// ObserveDuration adds a duration observation, in seconds
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+// ObserveSince adds the duration since t as an observation, in seconds
+func (h *Histogram) ObserveSince(t time.Time) {
+	h.ObserveDuration(time.Since(t))
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 {
+	h.Lock()
+	defer h.Unlock()
+	return h.count
+}
+
+func (h *Histogram) writeSamples(w *bufio.Writer, d *desc, labelValues []string) {
+	h.Lock()
+	counts := make([]uint64, len(h.counts))
+	copy(counts, h.counts)
+	count := h.count
+	sum := h.sum
+	h.Unlock()
+
+	var cumulative uint64
+	for i, upper := range h.upperBounds {
+		cumulative += counts[i]
+		fmt.Fprintf(w, "%s_bucket%s %d\n", d.fqName, formatLabels(d.labelNames, labelValues, "le", formatFloat(upper)), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", d.fqName, formatLabels(d.labelNames, labelValues, "le", "+Inf"), count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", d.fqName, formatLabels(d.labelNames, labelValues), formatFloat(sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", d.fqName, formatLabels(d.labelNames, labelValues), count)
+}
+
+// single is a collector for a metric without labels
+type single struct {
+	desc
+	counter   *Counter
+	gauge     *Gauge
+	gaugeFunc func() float64
+	histogram *Histogram
+}
+
+func (s *single) write(w *bufio.Writer) {
+	s.writeHeader(w)
+	switch {
+	case s.counter != nil:
+		fmt.Fprintf(w, "%s %s\n", s.fqName, formatFloat(s.counter.Value()))
+	case s.gauge != nil:
+		fmt.Fprintf(w, "%s %s\n", s.fqName, formatFloat(s.gauge.Value()))
+	case s.gaugeFunc != nil:
+		fmt.Fprintf(w, "%s %s\n", s.fqName, formatFloat(s.gaugeFunc()))
+	case s.histogram != nil:
+		s.histogram.writeSamples(w, &s.desc, nil)
+	}
+}
+
+// NewCounter creates a Counter registered in DefaultRegistry
+func NewCounter(name, help string) *Counter {
+	c := &Counter{}
+	DefaultRegistry.register(&single{
+		desc:    desc{fqName: name, help: help, metricType: typeCounter},
+		counter: c,
+	})
+	return c
+}
+
+// NewGauge creates a Gauge registered in DefaultRegistry
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{}
+	DefaultRegistry.register(&single{
+		desc:  desc{fqName: name, help: help, metricType: typeGauge},
+		gauge: g,
+	})
+	return g
+}
+
+// NewGaugeFunc registers a gauge in DefaultRegistry whose value is returned by f when the metrics are written.
+// f must be safe to call concurrently.
+func NewGaugeFunc(name, help string, f func() float64) {
+	DefaultRegistry.register(&single{
+		desc:      desc{fqName: name, help: help, metricType: typeGauge},
+		gaugeFunc: f,
+	})
+}
+
+// NewHistogram creates a Histogram registered in DefaultRegistry
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := newHistogram(buckets)
+	DefaultRegistry.register(&single{
+		desc:      desc{fqName: name, help: help, metricType: typeHistogram},
+		histogram: h,
+	})
+	return h
+}
+
+// vec is a collector for a family of metrics with labels
+type vec struct {
+	desc
+	newMetric func() interface{}
+	metrics   map[string]interface{}
+	values    map[string][]string
+	sync.Mutex
+}
+
+func newVec(d desc, newMetric func() interface{}) *vec {
+	return &vec{
+		desc:      d,
+		newMetric: newMetric,
+		metrics:   make(map[string]interface{}),
+		values:    make(map[string][]string),
+	}
+}
+
+func (v *vec) get(labelValues []string) interface{} {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d label values", v.fqName, len(v.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	v.Lock()
+	defer v.Unlock()
+
+	m, ok := v.metrics[key]
+	if !ok {
+		m = v.newMetric()
+		v.metrics[key] = m
+		v.values[key] = append([]string(nil), labelValues...)
+	}
+
+	return m
+}
+
+func (v *vec) write(w *bufio.Writer) {
+	v.Lock()
+	keys := make([]string, 0, len(v.metrics))
+	for k := range v.metrics {
+		keys = append(keys, k)
+	}
+	metrics := make(map[string]interface{}, len(v.metrics))
+	values := make(map[string][]string, len(v.values))
+	for k, m := range v.metrics {
+		metrics[k] = m
+		values[k] = v.values[k]
+	}
+	v.Unlock()
+
+	sort.Strings(keys)
+
+	v.writeHeader(w)
+	for _, k := range keys {
+		switch m := metrics[k].(type) {
+		case *Counter:
+			fmt.Fprintf(w, "%s%s %s\n", v.fqName, formatLabels(v.labelNames, values[k]), formatFloat(m.Value()))
+		case *Gauge:
+			fmt.Fprintf(w, "%s%s %s\n", v.fqName, formatLabels(v.labelNames, values[k]), formatFloat(m.Value()))
+		case *Histogram:
+			m.writeSamples(w, &v.desc, values[k])
+		}
+	}
+}
+
+// CounterVec is a family of Counters with labels
+type CounterVec struct {
+	v *vec
+}
+
+// NewCounterVec creates a CounterVec registered in DefaultRegistry
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	v := newVec(desc{fqName: name, help: help, metricType: typeCounter, labelNames: labelNames}, func() interface{} {
+		return &Counter{}
+	})
+	DefaultRegistry.register(v)
+	return &CounterVec{v: v}
+}
+
+// WithLabelValues returns the Counter for the label values, creating it if necessary
+func (c *CounterVec) WithLabelValues(labelValues ...string) *Counter {
+	return c.v.get(labelValues).(*Counter)
+}
+
+// GaugeVec is a family of Gauges with labels
+type GaugeVec struct {
+	v *vec
+}
+
+// NewGaugeVec creates a GaugeVec registered in DefaultRegistry
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	v := newVec(desc{fqName: name, help: help, metricType: typeGauge, labelNames: labelNames}, func() interface{} {
+		return &Gauge{}
+	})
+	DefaultRegistry.register(v)
+	return &GaugeVec{v: v}
+}
+
+// WithLabelValues returns the Gauge for the label values, creating it if necessary
+func (g *GaugeVec) WithLabelValues(labelValues ...string) *Gauge {
+	return g.v.get(labelValues).(*Gauge)
+}
+
+// HistogramVec is a family of Histograms with labels
+type HistogramVec struct {
+	v *vec
+}
+
+// NewHistogramVec creates a HistogramVec registered in DefaultRegistry
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	v := newVec(desc{fqName: name, help: help, metricType: typeHistogram, labelNames: labelNames}, func() interface{} {
+		return newHistogram(buckets)
+	})
+	DefaultRegistry.register(v)
+	return &HistogramVec{v: v}
+}
+
+// WithLabelValues returns the Histogram for the label values, creating it if necessary
+func (h *HistogramVec) WithLabelValues(labelValues ...string) *Histogram {
+	return h.v.get(labelValues).(*Histogram)
+}
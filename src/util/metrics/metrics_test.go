@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupRegistry() func() {
+	old := DefaultRegistry
+	DefaultRegistry = NewRegistry()
+	return func() {
+		DefaultRegistry = old
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	defer setupRegistry()()
+
+	c := NewCounter("test_counter_total", "A counter")
+	c.Inc()
+	c.Add(2.5)
+
+	g := NewGauge("test_gauge", "A gauge\nwith two lines")
+	g.Set(10)
+	g.Dec()
+
+	NewGaugeFunc("test_gauge_func", "A gauge func", func() float64 {
+		return 7
+	})
+
+	h := NewHistogram("test_histogram_seconds", "A histogram", []float64{1, 0.1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.ObserveDuration(time.Second * 2)
+
+	cv := NewCounterVec("test_counter_vec_total", "A counter vec", "route", "code")
+	cv.WithLabelValues("/b", "200").Inc()
+	cv.WithLabelValues("/a", "404").Add(3)
+	cv.WithLabelValues("/b", "200").Inc()
+
+	gv := NewGaugeVec("test_gauge_vec", "A gauge vec", "direction")
+	gv.WithLabelValues(`"out"`).Set(4)
+
+	hv := NewHistogramVec("test_histogram_vec", "A histogram vec", []float64{1}, "op")
+	hv.WithLabelValues("view").Observe(2)
+
+	var buf bytes.Buffer
+	require.NoError(t, DefaultRegistry.WriteText(&buf))
+
+	expected := `# HELP test_counter_total A counter
+# TYPE test_counter_total counter
+test_counter_total 3.5
+# HELP test_counter_vec_total A counter vec
+# TYPE test_counter_vec_total counter
+test_counter_vec_total{route="/a",code="404"} 3
+test_counter_vec_total{route="/b",code="200"} 2
+# HELP test_gauge A gauge\nwith two lines
+# TYPE test_gauge gauge
+test_gauge 9
+# HELP test_gauge_func A gauge func
+# TYPE test_gauge_func gauge
+test_gauge_func 7
+# HELP test_gauge_vec A gauge vec
+# TYPE test_gauge_vec gauge
+test_gauge_vec{direction="\"out\""} 4
+# HELP test_histogram_seconds A histogram
+# TYPE test_histogram_seconds histogram
+test_histogram_seconds_bucket{le="0.1"} 1
+test_histogram_seconds_bucket{le="1"} 2
+test_histogram_seconds_bucket{le="+Inf"} 3
+test_histogram_seconds_sum 2.55
+test_histogram_seconds_count 3
+# HELP test_histogram_vec A histogram vec
+# TYPE test_histogram_vec histogram
+test_histogram_vec_bucket{op="view",le="1"} 0
+test_histogram_vec_bucket{op="view",le="+Inf"} 1
+test_histogram_vec_sum{op="view"} 2
+test_histogram_vec_count{op="view"} 1
+`
+
+	require.Equal(t, expected, buf.String())
+	require.Equal(t, uint64(3), h.Count())
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	defer setupRegistry()()
+
+	NewCounter("test_duplicate", "")
+	require.Panics(t, func() {
+		NewGauge("test_duplicate", "")
+	})
+}
+
+func TestWithLabelValuesWrongCount(t *testing.T) {
+	defer setupRegistry()()
+
+	cv := NewCounterVec("test_labels", "", "a", "b")
+	require.Panics(t, func() {
+		cv.WithLabelValues("x")
+	})
+}
+
+func TestCounterAddNegative(t *testing.T) {
+	defer setupRegistry()()
+
+	c := NewCounter("test_negative", "")
+	require.Panics(t, func() {
+		c.Add(-1)
+	})
+}
@@ -3,6 +3,7 @@ package visor
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -23,8 +24,40 @@ const (
 var (
 	// ErrVerifyStopped is returned when database verification is interrupted
 	ErrVerifyStopped = errors.New("database verification stopped")
+	// ErrKeyRotationKnown is returned when adding a key rotation that was already added
+	ErrKeyRotationKnown = errors.New("Key rotation already exists")
 )
 
+// ErrKeyRotationConflict is returned when a key rotation is signed by the same blockchain authority as a different
+// key rotation that was already added. Only one of them can be genuine, so a key of that authority was compromised.
+// The conflict is recorded, and blocks from DisputedSeq on are refused until an operator trusts one of the
+// key rotations with BlockchainConfig.TrustedKeyRotations.
+type ErrKeyRotationConflict struct {
+	Stored      coin.KeyRotation
+	Conflicting coin.KeyRotation
+}
+
+// NewErrKeyRotationConflict creates ErrKeyRotationConflict from a recorded conflict
+func NewErrKeyRotationConflict(c blockdb.KeyRotationConflict) ErrKeyRotationConflict {
+	return ErrKeyRotationConflict{
+		Stored:      c.Stored,
+		Conflicting: c.Conflicting,
+	}
+}
+
+func (e ErrKeyRotationConflict) Error() string {
+	return fmt.Sprintf("Key rotation %s at seq %d conflicts with key rotation %s at seq %d, blocks from seq %d are refused until one of them is trusted",
+		e.Conflicting.Hash().Hex(), e.Conflicting.Seq, e.Stored.Hash().Hex(), e.Stored.Seq, e.DisputedSeq())
+}
+
+// DisputedSeq returns the first block seq whose signing keys the conflicting key rotations disagree on
+func (e ErrKeyRotationConflict) DisputedSeq() uint64 {
+	if e.Conflicting.Seq < e.Stored.Seq {
+		return e.Conflicting.Seq
+	}
+	return e.Stored.Seq
+}
+
 //Warning: 10e6 is 10 million, 1e6 is 1 million
 
 // Note: DebugLevel1 adds additional checks for hash collisions that
@@ -55,7 +88,14 @@ type chainStore interface {
 	UnspentPool() blockdb.UnspentPooler
 	GetGenesisBlock(*dbutil.Tx) (*coin.SignedBlock, error)
 	GetBlockSignature(*dbutil.Tx, *coin.Block) (cipher.Sig, bool, error)
+	GetBlockMakerSigs(*dbutil.Tx, *coin.Block) ([]cipher.Sig, error)
 	ForEachBlock(*dbutil.Tx, func(*coin.Block) error) error
+	AddKeyRotation(*dbutil.Tx, coin.KeyRotation) error
+	GetKeyRotations(*dbutil.Tx) ([]coin.KeyRotation, error)
+	RemoveKeyRotationsFrom(*dbutil.Tx, uint64) error
+	AddKeyRotationConflict(*dbutil.Tx, blockdb.KeyRotationConflict) error
+	GetKeyRotationConflicts(*dbutil.Tx) ([]blockdb.KeyRotationConflict, error)
+	RemoveKeyRotationConflict(*dbutil.Tx, cipher.SHA256) error
 }
 
 // DefaultWalker default blockchain walker
@@ -80,6 +120,9 @@ func CreateBuckets(db *dbutil.DB) error {
 		return dbutil.CreateBuckets(tx, [][]byte{
 			UnconfirmedTxnsBkt,
 			UnconfirmedUnspentsBkt,
+			UnconfirmedSpendsBkt,
+			UnconfirmedOutputsBkt,
+			SupplySnapshotsBkt,
 		})
 	})
 }
@@ -91,6 +134,14 @@ type BlockchainConfig struct {
 	// node will throw the error and return.
 	Arbitrating bool
 	Pubkey      cipher.PubKey
+	// Other pubkeys that can sign blocks along with Pubkey, until the first key rotation
+	SignerPubkeys []cipher.PubKey
+	// Blocks signed by one of the block makers are valid too, if a quorum of block makers signed them
+	BlockMakerPubkeys []cipher.PubKey
+	// Number of block maker signatures that a block signed by a block maker needs
+	BlockMakerQuorum int
+	// Hashes of the key rotations that an operator trusts, which resolve key rotation conflicts
+	TrustedKeyRotations []cipher.SHA256
 }
 
 // Blockchain maintains blockchain and provides apis for accessing the chain.
@@ -102,6 +153,10 @@ type Blockchain struct {
 
 // NewBlockchain creates a Blockchain
 func NewBlockchain(db *dbutil.DB, cfg BlockchainConfig) (*Blockchain, error) {
+	if len(cfg.BlockMakerPubkeys) != 0 && (cfg.BlockMakerQuorum < 1 || cfg.BlockMakerQuorum > len(cfg.BlockMakerPubkeys)) {
+		return nil, fmt.Errorf("Block maker quorum must be between 1 and %d", len(cfg.BlockMakerPubkeys))
+	}
+
 	chainstore, err := blockdb.NewBlockchain(db, DefaultWalker)
 	if err != nil {
 		return nil, err
@@ -214,6 +269,19 @@ func (bc Blockchain) NewBlock(tx *dbutil.Tx, txns coin.Transactions, currentTime
 }
 
 func (bc *Blockchain) processBlock(tx *dbutil.Tx, b coin.SignedBlock) (coin.SignedBlock, error) {
+	if err := bc.checkKeyRotationConflicts(tx, b.Seq()); err != nil {
+		return coin.SignedBlock{}, err
+	}
+
+	if err := bc.VerifySignature(tx, &b); err != nil {
+		return coin.SignedBlock{}, err
+	}
+
+	return bc.processBlockContents(tx, b)
+}
+
+// processBlockContents verifies the header and the transactions of a block, but not its signatures
+func (bc *Blockchain) processBlockContents(tx *dbutil.Tx, b coin.SignedBlock) (coin.SignedBlock, error) {
 	length, err := bc.Len(tx)
 	if err != nil {
 		return coin.SignedBlock{}, err
@@ -277,6 +345,31 @@ func (bc *Blockchain) ExecuteBlock(tx *dbutil.Tx, sb *coin.SignedBlock) error {
 	return nil
 }
 
+// VerifyBlock checks that the block could be executed on top of the head block, without executing it.
+// The signatures of the block are not verified, a proposed block has no block maker signatures yet.
+func (bc *Blockchain) VerifyBlock(tx *dbutil.Tx, sb *coin.SignedBlock) error {
+	length, err := bc.Len(tx)
+	if err != nil {
+		return err
+	}
+
+	if length == 0 {
+		return errors.New("Blockchain has no genesis block")
+	}
+
+	nb, err := bc.processBlockContents(tx, *sb)
+	if err != nil {
+		return err
+	}
+
+	// In arbitrating mode, processBlock skips the invalid transactions
+	if len(nb.Body.Transactions) != len(sb.Body.Transactions) {
+		return errors.New("Block has invalid transactions")
+	}
+
+	return nil
+}
+
 // isGenesisBlock checks if the block is genesis block
 func (bc Blockchain) isGenesisBlock(tx *dbutil.Tx, b coin.Block) (bool, error) {
 	gb, err := bc.store.GetGenesisBlock(tx)
@@ -650,12 +743,348 @@ type sigHash struct {
 
 // VerifySignature checks that BlockSigs state correspond with coin.Blockchain state
 // and that all signatures are valid.
-func (bc *Blockchain) VerifySignature(block *coin.SignedBlock) error {
-	err := cipher.VerifySignature(bc.cfg.Pubkey, block.Sig, block.HashHeader())
+// The block must be signed by one of the pubkeys that can sign blocks at its seq, following the key rotations,
+// or by a block maker along with a quorum of block makers.
+func (bc *Blockchain) VerifySignature(tx *dbutil.Tx, block *coin.SignedBlock) error {
+	pubkeys, err := bc.SignerPubkeys(tx, block.Seq())
 	if err != nil {
+		return err
+	}
+
+	if err := bc.verifyBlockSigs(pubkeys, block); err != nil {
 		logger.Errorf("Signature verification failed: %v", err)
+		return err
 	}
-	return err
+
+	return nil
+}
+
+// SignerPubkeys returns the pubkeys that can sign the block with sequence number seq on their own:
+// the blockchain pubkeys in effect at seq. A block signed by a block maker also needs the signatures
+// of a quorum of block makers.
+func (bc *Blockchain) SignerPubkeys(tx *dbutil.Tx, seq uint64) ([]cipher.PubKey, error) {
+	return bc.blockchainPubkeys(tx, seq)
+}
+
+// blockchainPubkeys returns the pubkeys of the blockchain authority in effect at seq.
+// These are Pubkey and SignerPubkeys until the first key rotation, then the pubkeys of the last key rotation
+// that starts at or before seq.
+func (bc *Blockchain) blockchainPubkeys(tx *dbutil.Tx, seq uint64) ([]cipher.PubKey, error) {
+	rotations, err := bc.store.GetKeyRotations(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	pubkeys := append([]cipher.PubKey{bc.cfg.Pubkey}, bc.cfg.SignerPubkeys...)
+	for _, r := range rotations {
+		if r.Seq > seq {
+			break
+		}
+		pubkeys = r.Pubkeys
+	}
+
+	// Return a copy, the caller may append to it
+	return append([]cipher.PubKey{}, pubkeys...), nil
+}
+
+// GetKeyRotations returns all key rotations, ordered by seq
+func (bc *Blockchain) GetKeyRotations(tx *dbutil.Tx) ([]coin.KeyRotation, error) {
+	return bc.store.GetKeyRotations(tx)
+}
+
+// AddKeyRotation verifies and saves a key rotation.
+// The key rotation must be signed by a blockchain pubkey in effect at its seq.
+// If the blockchain already has blocks at or after its seq, they must be signed by the new pubkeys.
+// Returns ErrKeyRotationKnown if the key rotation was already added.
+//
+// Key rotations are not part of the blocks, a node trusts the first key rotation it receives for each
+// blockchain authority. If the authority that signed a key rotation already rotated to other pubkeys,
+// a key of that authority signed both, so one of them is not genuine:
+//   - if the stored key rotation is trusted, the new one is rejected with an error
+//   - if the new key rotation is trusted and no block depends on the stored one yet, it replaces the stored key
+//     rotation and the key rotations after it
+//   - otherwise the conflict is recorded and ErrKeyRotationConflict is returned. Blocks from the disputed seq on are
+//     refused until an operator adds the hash of the genuine key rotation to TrustedKeyRotations.
+func (bc *Blockchain) AddKeyRotation(tx *dbutil.Tx, r coin.KeyRotation) error {
+	if err := r.Verify(); err != nil {
+		return err
+	}
+
+	rotations, err := bc.store.GetKeyRotations(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, kr := range rotations {
+		if kr.Seq == r.Seq && kr.Hash() == r.Hash() {
+			return ErrKeyRotationKnown
+		}
+	}
+
+	signer, err := r.Signer()
+	if err != nil {
+		return err
+	}
+
+	authority, ok := bc.keyRotationAuthority(rotations, signer, r.Seq)
+	if !ok {
+		return errors.New("Key rotation is not signed by a blockchain pubkey")
+	}
+
+	// The authority of the signer already rotated to other pubkeys
+	if authority < len(rotations) {
+		return bc.addKeyRotationConflict(tx, blockdb.KeyRotationConflict{
+			Stored:      rotations[authority],
+			Conflicting: r,
+		})
+	}
+
+	headSeq, ok, err := bc.HeadSeq(tx)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		for seq := r.Seq; seq <= headSeq; seq++ {
+			b, err := bc.GetSignedBlockBySeq(tx, seq)
+			if err != nil {
+				return err
+			}
+			if b == nil {
+				return fmt.Errorf("Block %d not found", seq)
+			}
+
+			if err := bc.verifyBlockSigs(r.Pubkeys, b); err != nil {
+				return fmt.Errorf("Key rotation does not match block %d: %v", seq, err)
+			}
+		}
+	}
+
+	return bc.store.AddKeyRotation(tx, r)
+}
+
+// keyRotationAuthority returns the index of the blockchain authority that signed a key rotation starting at seq:
+// 0 for the pubkeys of the config, i for the pubkeys of rotations[i-1].
+// An authority can only rotate at a seq after its first block. The latest authority is preferred.
+func (bc *Blockchain) keyRotationAuthority(rotations []coin.KeyRotation, signer cipher.PubKey, seq uint64) (int, bool) {
+	for i := len(rotations); i > 0; i-- {
+		if seq > rotations[i-1].Seq && containsPubkey(rotations[i-1].Pubkeys, signer) {
+			return i, true
+		}
+	}
+
+	if containsPubkey(append([]cipher.PubKey{bc.cfg.Pubkey}, bc.cfg.SignerPubkeys...), signer) {
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// isTrustedKeyRotation returns true if the operator trusts the key rotation
+func (bc *Blockchain) isTrustedKeyRotation(r coin.KeyRotation) bool {
+	hash := r.Hash()
+	for _, h := range bc.cfg.TrustedKeyRotations {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// addKeyRotationConflict resolves a key rotation conflict with the trusted key rotations, or records it
+func (bc *Blockchain) addKeyRotationConflict(tx *dbutil.Tx, c blockdb.KeyRotationConflict) error {
+	if bc.isTrustedKeyRotation(c.Stored) {
+		return fmt.Errorf("Key rotation conflicts with trusted key rotation %s at seq %d", c.Stored.Hash().Hex(), c.Stored.Seq)
+	}
+
+	resolved, err := bc.resolveKeyRotationConflict(tx, c)
+	if err != nil || resolved {
+		return err
+	}
+
+	if err := bc.store.AddKeyRotationConflict(tx, c); err != nil {
+		return err
+	}
+
+	return NewErrKeyRotationConflict(c)
+}
+
+// resolveKeyRotationConflict resolves a key rotation conflict if one of the key rotations is trusted.
+// Returns false if neither is trusted. Returns an error if the conflicting key rotation is trusted, and
+// blocks that depend on the stored key rotation have been executed.
+func (bc *Blockchain) resolveKeyRotationConflict(tx *dbutil.Tx, c blockdb.KeyRotationConflict) (bool, error) {
+	switch {
+	case bc.isTrustedKeyRotation(c.Stored):
+		logger.Warningf("Key rotation %s at seq %d rejected, it conflicts with trusted key rotation %s", c.Conflicting.Hash().Hex(), c.Conflicting.Seq, c.Stored.Hash().Hex())
+		return true, bc.store.RemoveKeyRotationConflict(tx, c.Conflicting.Hash())
+
+	case bc.isTrustedKeyRotation(c.Conflicting):
+		e := NewErrKeyRotationConflict(c)
+		headSeq, ok, err := bc.HeadSeq(tx)
+		if err != nil {
+			return false, err
+		}
+
+		if ok && headSeq >= e.DisputedSeq() {
+			return false, fmt.Errorf("Trusted key rotation %s at seq %d conflicts with key rotation %s at seq %d, which blocks up to seq %d depend on. Delete the database to resync the blockchain",
+				c.Conflicting.Hash().Hex(), c.Conflicting.Seq, c.Stored.Hash().Hex(), c.Stored.Seq, headSeq)
+		}
+
+		logger.Warningf("Key rotation %s at seq %d replaced by trusted key rotation %s", c.Stored.Hash().Hex(), c.Stored.Seq, c.Conflicting.Hash().Hex())
+
+		if err := bc.store.RemoveKeyRotationsFrom(tx, c.Stored.Seq); err != nil {
+			return false, err
+		}
+
+		// Remove the conflicts with the removed key rotations
+		conflicts, err := bc.store.GetKeyRotationConflicts(tx)
+		if err != nil {
+			return false, err
+		}
+
+		for _, rc := range conflicts {
+			if rc.Stored.Seq >= c.Stored.Seq {
+				if err := bc.store.RemoveKeyRotationConflict(tx, rc.Conflicting.Hash()); err != nil {
+					return false, err
+				}
+			}
+		}
+
+		if err := bc.AddKeyRotation(tx, c.Conflicting); err != nil && err != ErrKeyRotationKnown {
+			return false, err
+		}
+
+		return true, nil
+
+	default:
+		return false, nil
+	}
+}
+
+// ResolveKeyRotationConflicts resolves the recorded key rotation conflicts with the trusted key rotations.
+// Returns the conflicts that remain unresolved.
+func (bc *Blockchain) ResolveKeyRotationConflicts(tx *dbutil.Tx) ([]ErrKeyRotationConflict, error) {
+	// Resolving a conflict can remove other conflicts, so reload them after each resolution
+	for {
+		conflicts, err := bc.store.GetKeyRotationConflicts(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		var unresolved []ErrKeyRotationConflict
+		resolved := false
+		for _, c := range conflicts {
+			resolved, err = bc.resolveKeyRotationConflict(tx, c)
+			if err != nil {
+				return nil, err
+			}
+
+			if resolved {
+				break
+			}
+
+			unresolved = append(unresolved, NewErrKeyRotationConflict(c))
+		}
+
+		if !resolved {
+			return unresolved, nil
+		}
+	}
+}
+
+// checkKeyRotationConflicts returns ErrKeyRotationConflict if a recorded key rotation conflict disputes
+// the signing keys of the block with sequence number seq
+func (bc *Blockchain) checkKeyRotationConflicts(tx *dbutil.Tx, seq uint64) error {
+	conflicts, err := bc.store.GetKeyRotationConflicts(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range conflicts {
+		if e := NewErrKeyRotationConflict(c); seq >= e.DisputedSeq() {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func containsPubkey(pubkeys []cipher.PubKey, pubkey cipher.PubKey) bool {
+	for _, pk := range pubkeys {
+		if pk == pubkey {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyBlockSignature checks that the block is signed by one of pubkeys
+func verifyBlockSignature(pubkeys []cipher.PubKey, block *coin.SignedBlock) error {
+	hash := block.HashHeader()
+	pubkey, err := cipher.PubKeyFromSig(block.Sig, hash)
+	if err != nil {
+		return errors.New("Invalid sig: PubKey recovery failed")
+	}
+
+	if containsPubkey(pubkeys, pubkey) {
+		return cipher.VerifySignature(pubkey, block.Sig, hash)
+	}
+
+	return errors.New("Invalid sig: block is not signed by the blockchain pubkey or a block maker")
+}
+
+// verifyBlockSigs checks that the block is signed by one of pubkeys, or by a block maker along with
+// a quorum of block makers. A block signed by a single block maker is not valid.
+func (bc *Blockchain) verifyBlockSigs(pubkeys []cipher.PubKey, block *coin.SignedBlock) error {
+	hash := block.HashHeader()
+	signer, err := cipher.PubKeyFromSig(block.Sig, hash)
+	if err != nil {
+		return errors.New("Invalid sig: PubKey recovery failed")
+	}
+
+	if containsPubkey(pubkeys, signer) || !containsPubkey(bc.cfg.BlockMakerPubkeys, signer) {
+		return verifyBlockSignature(pubkeys, block)
+	}
+
+	if err := cipher.VerifySignature(signer, block.Sig, hash); err != nil {
+		return err
+	}
+
+	return verifyBlockMakerQuorum(bc.cfg.BlockMakerPubkeys, bc.cfg.BlockMakerQuorum, block)
+}
+
+// verifyBlockMakerQuorum checks that the block maker signatures of the block are valid signatures
+// of its header hash by at least quorum distinct block makers
+func verifyBlockMakerQuorum(makers []cipher.PubKey, quorum int, block *coin.SignedBlock) error {
+	// Recovering the pubkeys is expensive, a block maker signs a block once
+	if len(block.MakerSigs) > len(makers) {
+		return fmt.Errorf("Invalid block maker sigs: %d sigs for %d block makers", len(block.MakerSigs), len(makers))
+	}
+
+	hash := block.HashHeader()
+	signers := make(map[cipher.PubKey]struct{}, len(block.MakerSigs))
+	for _, sig := range block.MakerSigs {
+		pubkey, err := cipher.PubKeyFromSig(sig, hash)
+		if err != nil {
+			return errors.New("Invalid block maker sig: PubKey recovery failed")
+		}
+
+		if !containsPubkey(makers, pubkey) {
+			return errors.New("Invalid block maker sig: not signed by a block maker")
+		}
+
+		if err := cipher.VerifySignature(pubkey, sig, hash); err != nil {
+			return fmt.Errorf("Invalid block maker sig: %v", err)
+		}
+
+		signers[pubkey] = struct{}{}
+	}
+
+	if len(signers) < quorum {
+		return fmt.Errorf("Block is signed by %d block makers, %d are needed", len(signers), quorum)
+	}
+
+	return nil
 }
 
 // WalkChain walk through the blockchain concurrently
@@ -732,9 +1161,15 @@ func (bc *Blockchain) WalkChain(workers int, f func(*dbutil.Tx, *coin.SignedBloc
 					return blockdb.NewErrMissingSignature(block)
 				}
 
+				makerSigs, err := bc.store.GetBlockMakerSigs(tx, block)
+				if err != nil {
+					return err
+				}
+
 				signedBlock := &coin.SignedBlock{
-					Sig:   sig,
-					Block: *block,
+					Sig:       sig,
+					Block:     *block,
+					MakerSigs: makerSigs,
 				}
 
 				select {
@@ -76,9 +76,10 @@ func makeSpendTx(t *testing.T, uxs coin.UxArray, keys []cipher.SecKey, toAddr ci
 
 /* Helpers */
 type fakeChainStore struct {
-	len    uint64
-	blocks []coin.SignedBlock
-	up     blockdb.UnspentPooler
+	len       uint64
+	blocks    []coin.SignedBlock
+	up        blockdb.UnspentPooler
+	rotations []coin.KeyRotation
 }
 
 func (fcs *fakeChainStore) Head(tx *dbutil.Tx) (*coin.SignedBlock, error) {
@@ -113,6 +114,10 @@ func (fcs *fakeChainStore) GetBlockSignature(tx *dbutil.Tx, b *coin.Block) (ciph
 	return cipher.Sig{}, false, nil
 }
 
+func (fcs *fakeChainStore) GetBlockMakerSigs(tx *dbutil.Tx, b *coin.Block) ([]cipher.Sig, error) {
+	return nil, nil
+}
+
 func (fcs *fakeChainStore) GetBlockByHash(tx *dbutil.Tx, hash cipher.SHA256) (*coin.Block, error) {
 	return nil, nil
 }
@@ -145,6 +150,38 @@ func (fcs *fakeChainStore) ForEachBlock(tx *dbutil.Tx, f func(*coin.Block) error
 	return nil
 }
 
+func (fcs *fakeChainStore) AddKeyRotation(tx *dbutil.Tx, r coin.KeyRotation) error {
+	fcs.rotations = append(fcs.rotations, r)
+	return nil
+}
+
+func (fcs *fakeChainStore) GetKeyRotations(tx *dbutil.Tx) ([]coin.KeyRotation, error) {
+	return fcs.rotations, nil
+}
+
+func (fcs *fakeChainStore) RemoveKeyRotationsFrom(tx *dbutil.Tx, seq uint64) error {
+	var rotations []coin.KeyRotation
+	for _, r := range fcs.rotations {
+		if r.Seq < seq {
+			rotations = append(rotations, r)
+		}
+	}
+	fcs.rotations = rotations
+	return nil
+}
+
+func (fcs *fakeChainStore) AddKeyRotationConflict(tx *dbutil.Tx, c blockdb.KeyRotationConflict) error {
+	return nil
+}
+
+func (fcs *fakeChainStore) GetKeyRotationConflicts(tx *dbutil.Tx) ([]blockdb.KeyRotationConflict, error) {
+	return nil, nil
+}
+
+func (fcs *fakeChainStore) RemoveKeyRotationConflict(tx *dbutil.Tx, hash cipher.SHA256) error {
+	return nil
+}
+
 func makeBlock(t *testing.T, preBlock coin.Block, tm uint64) *coin.Block {
 	uxHash := testutil.RandSHA256(t)
 	tx := coin.Transaction{}
@@ -785,6 +822,9 @@ func TestProcessBlock(t *testing.T) {
 	bc := &Blockchain{
 		db:    db,
 		store: store,
+		cfg: BlockchainConfig{
+			Pubkey: genPublic,
+		},
 	}
 
 	gb, err := coin.NewGenesisBlock(genAddress, genCoins, genTime)
@@ -843,6 +883,9 @@ func TestExecuteBlock(t *testing.T) {
 	bc := &Blockchain{
 		db:    db,
 		store: store,
+		cfg: BlockchainConfig{
+			Pubkey: genPublic,
+		},
 	}
 
 	gb, err := coin.NewGenesisBlock(genAddress, genCoins, genTime)
@@ -507,10 +507,8 @@ func testVerifyTransactionAddressLocking(t *testing.T, toAddr string, expectedEr
 	db, close := prepareDB(t)
 	defer close()
 
-	_, s := cipher.GenerateKeyPair()
-
 	// Setup blockchain
-	bc := MakeBlockchain(t, db, s)
+	bc := MakeBlockchain(t, db, GenesisSecret)
 
 	// Send coins to the initial address
 	var coins = GenesisCoins
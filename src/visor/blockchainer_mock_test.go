@@ -25,6 +25,24 @@ func NewBlockchainerMock() *BlockchainerMock {
 	return &BlockchainerMock{}
 }
 
+// AddKeyRotation mocked method
+func (m *BlockchainerMock) AddKeyRotation(p0 *dbutil.Tx, p1 coin.KeyRotation) error {
+
+	ret := m.Called(p0, p1)
+
+	var r0 error
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case error:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0
+
+}
+
 // ExecuteBlock mocked method
 func (m *BlockchainerMock) ExecuteBlock(p0 *dbutil.Tx, p1 *coin.SignedBlock) error {
 
@@ -97,6 +115,33 @@ func (m *BlockchainerMock) GetGenesisBlock(p0 *dbutil.Tx) (*coin.SignedBlock, er
 
 }
 
+// GetKeyRotations mocked method
+func (m *BlockchainerMock) GetKeyRotations(p0 *dbutil.Tx) ([]coin.KeyRotation, error) {
+
+	ret := m.Called(p0)
+
+	var r0 []coin.KeyRotation
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []coin.KeyRotation:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetLastBlocks mocked method
 func (m *BlockchainerMock) GetLastBlocks(p0 *dbutil.Tx, p1 uint64) ([]coin.SignedBlock, error) {
 
@@ -295,6 +340,33 @@ func (m *BlockchainerMock) NewBlock(p0 *dbutil.Tx, p1 coin.Transactions, p2 uint
 
 }
 
+// SignerPubkeys mocked method
+func (m *BlockchainerMock) SignerPubkeys(p0 *dbutil.Tx, p1 uint64) ([]cipher.PubKey, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 []cipher.PubKey
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []cipher.PubKey:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // Time mocked method
 func (m *BlockchainerMock) Time(p0 *dbutil.Tx) (uint64, error) {
 
@@ -358,8 +430,8 @@ func (m *BlockchainerMock) Unspent() blockdb.UnspentPooler {
 
 }
 
-// VerifyBlockTxnConstraints mocked method
-func (m *BlockchainerMock) VerifyBlockTxnConstraints(p0 *dbutil.Tx, p1 coin.Transaction) error {
+// VerifyBlock mocked method
+func (m *BlockchainerMock) VerifyBlock(p0 *dbutil.Tx, p1 *coin.SignedBlock) error {
 
 	ret := m.Called(p0, p1)
 
@@ -376,10 +448,10 @@ func (m *BlockchainerMock) VerifyBlockTxnConstraints(p0 *dbutil.Tx, p1 coin.Tran
 
 }
 
-// VerifySingleTxnSoftHardConstraints mocked method
-func (m *BlockchainerMock) VerifySingleTxnSoftHardConstraints(p0 *dbutil.Tx, p1 coin.Transaction, p2 int) error {
+// VerifyBlockTxnConstraints mocked method
+func (m *BlockchainerMock) VerifyBlockTxnConstraints(p0 *dbutil.Tx, p1 coin.Transaction) error {
 
-	ret := m.Called(p0, p1, p2)
+	ret := m.Called(p0, p1)
 
 	var r0 error
 	switch res := ret.Get(0).(type) {
@@ -411,3 +483,21 @@ func (m *BlockchainerMock) VerifySingleTxnHardConstraints(p0 *dbutil.Tx, p1 coin
 	return r0
 
 }
+
+// VerifySingleTxnSoftHardConstraints mocked method
+func (m *BlockchainerMock) VerifySingleTxnSoftHardConstraints(p0 *dbutil.Tx, p1 coin.Transaction, p2 int) error {
+
+	ret := m.Called(p0, p1, p2)
+
+	var r0 error
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case error:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0
+
+}
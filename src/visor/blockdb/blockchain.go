@@ -42,7 +42,11 @@ func CreateBuckets(tx *dbutil.Tx) error {
 		BlockchainMetaBkt,
 		UnspentPoolBkt,
 		UnspentPoolAddrIndexBkt,
+		UnspentPoolAddrBalanceBkt,
 		UnspentMetaBkt,
+		KeyRotationsBkt,
+		KeyRotationConflictsBkt,
+		BlockMakerSigsBkt,
 	})
 }
 
@@ -61,6 +65,22 @@ type BlockSigs interface {
 	ForEach(*dbutil.Tx, func(cipher.SHA256, cipher.Sig) error) error
 }
 
+// BlockMakerSigs block maker signatures storage
+type BlockMakerSigs interface {
+	Add(*dbutil.Tx, cipher.SHA256, []cipher.Sig) error
+	Get(*dbutil.Tx, cipher.SHA256) ([]cipher.Sig, error)
+}
+
+// KeyRotations key rotation storage
+type KeyRotations interface {
+	Add(*dbutil.Tx, coin.KeyRotation) error
+	GetAll(*dbutil.Tx) ([]coin.KeyRotation, error)
+	RemoveFrom(*dbutil.Tx, uint64) error
+	AddConflict(*dbutil.Tx, KeyRotationConflict) error
+	GetConflicts(*dbutil.Tx) ([]KeyRotationConflict, error)
+	RemoveConflict(*dbutil.Tx, cipher.SHA256) error
+}
+
 // UnspentPooler unspent outputs pool
 type UnspentPooler interface {
 	MaybeBuildIndexes(*dbutil.Tx, uint64) error
@@ -73,6 +93,8 @@ type UnspentPooler interface {
 	GetUnspentsOfAddrs(*dbutil.Tx, []cipher.Address) (coin.AddressUxOuts, error)
 	ProcessBlock(*dbutil.Tx, *coin.SignedBlock) error
 	AddressCount(*dbutil.Tx) (uint64, error)
+	GetAddressBalances(*dbutil.Tx, []cipher.Address) (map[cipher.Address]AddressBalance, error)
+	ForEachAddressBalance(*dbutil.Tx, func(cipher.Address, AddressBalance) error) error
 }
 
 // ChainMeta blockchain metadata
@@ -83,12 +105,14 @@ type ChainMeta interface {
 
 // Blockchain maintain the buckets for blockchain
 type Blockchain struct {
-	db      *dbutil.DB
-	meta    ChainMeta
-	unspent UnspentPooler
-	tree    BlockTree
-	sigs    BlockSigs
-	walker  Walker
+	db        *dbutil.DB
+	meta      ChainMeta
+	unspent   UnspentPooler
+	tree      BlockTree
+	sigs      BlockSigs
+	makerSigs BlockMakerSigs
+	keys      KeyRotations
+	walker    Walker
 }
 
 // NewBlockchain creates a new blockchain instance
@@ -102,12 +126,14 @@ func NewBlockchain(db *dbutil.DB, walker Walker) (*Blockchain, error) {
 	}
 
 	return &Blockchain{
-		db:      db,
-		unspent: NewUnspentPool(),
-		meta:    &chainMeta{},
-		tree:    &blockTree{},
-		sigs:    &blockSigs{},
-		walker:  walker,
+		db:        db,
+		unspent:   NewUnspentPool(),
+		meta:      &chainMeta{},
+		tree:      &blockTree{},
+		sigs:      &blockSigs{},
+		makerSigs: &blockMakerSigs{},
+		keys:      &keyRotations{},
+		walker:    walker,
 	}, nil
 }
 
@@ -122,6 +148,12 @@ func (bc *Blockchain) AddBlock(tx *dbutil.Tx, sb *coin.SignedBlock) error {
 		return fmt.Errorf("save signature failed: %v", err)
 	}
 
+	if len(sb.MakerSigs) != 0 {
+		if err := bc.makerSigs.Add(tx, sb.HashHeader(), sb.MakerSigs); err != nil {
+			return fmt.Errorf("save block maker signatures failed: %v", err)
+		}
+	}
+
 	if err := bc.tree.AddBlock(tx, &sb.Block); err != nil {
 		return fmt.Errorf("save block failed: %v", err)
 	}
@@ -186,6 +218,41 @@ func (bc *Blockchain) GetBlockSignature(tx *dbutil.Tx, b *coin.Block) (cipher.Si
 	return bc.sigs.Get(tx, b.HashHeader())
 }
 
+// GetBlockMakerSigs returns the signatures of the block makers that voted for a block
+func (bc *Blockchain) GetBlockMakerSigs(tx *dbutil.Tx, b *coin.Block) ([]cipher.Sig, error) {
+	return bc.makerSigs.Get(tx, b.HashHeader())
+}
+
+// AddKeyRotation adds a key rotation
+func (bc *Blockchain) AddKeyRotation(tx *dbutil.Tx, r coin.KeyRotation) error {
+	return bc.keys.Add(tx, r)
+}
+
+// GetKeyRotations returns all key rotations, ordered by seq
+func (bc *Blockchain) GetKeyRotations(tx *dbutil.Tx) ([]coin.KeyRotation, error) {
+	return bc.keys.GetAll(tx)
+}
+
+// RemoveKeyRotationsFrom removes the key rotations whose seq is at least seq
+func (bc *Blockchain) RemoveKeyRotationsFrom(tx *dbutil.Tx, seq uint64) error {
+	return bc.keys.RemoveFrom(tx, seq)
+}
+
+// AddKeyRotationConflict records a key rotation conflict
+func (bc *Blockchain) AddKeyRotationConflict(tx *dbutil.Tx, c KeyRotationConflict) error {
+	return bc.keys.AddConflict(tx, c)
+}
+
+// GetKeyRotationConflicts returns the recorded key rotation conflicts
+func (bc *Blockchain) GetKeyRotationConflicts(tx *dbutil.Tx) ([]KeyRotationConflict, error) {
+	return bc.keys.GetConflicts(tx)
+}
+
+// RemoveKeyRotationConflict removes the key rotation conflict of the conflicting key rotation with the given hash
+func (bc *Blockchain) RemoveKeyRotationConflict(tx *dbutil.Tx, hash cipher.SHA256) error {
+	return bc.keys.RemoveConflict(tx, hash)
+}
+
 // GetBlockByHash returns block of given hash
 func (bc *Blockchain) GetBlockByHash(tx *dbutil.Tx, hash cipher.SHA256) (*coin.Block, error) {
 	b, err := bc.tree.GetBlock(tx, hash)
@@ -216,9 +283,15 @@ func (bc *Blockchain) GetSignedBlockByHash(tx *dbutil.Tx, hash cipher.SHA256) (*
 		return nil, NewErrMissingSignature(b)
 	}
 
+	makerSigs, err := bc.makerSigs.Get(tx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("find block maker signatures of block: %v failed: %v", hash.Hex(), err)
+	}
+
 	return &coin.SignedBlock{
-		Block: *b,
-		Sig:   sig,
+		Block:     *b,
+		Sig:       sig,
+		MakerSigs: makerSigs,
 	}, nil
 }
 
@@ -241,9 +314,15 @@ func (bc *Blockchain) GetSignedBlockBySeq(tx *dbutil.Tx, seq uint64) (*coin.Sign
 		return nil, NewErrMissingSignature(b)
 	}
 
+	makerSigs, err := bc.makerSigs.Get(tx, b.HashHeader())
+	if err != nil {
+		return nil, fmt.Errorf("find block maker signatures of block: %v failed: %v", seq, err)
+	}
+
 	return &coin.SignedBlock{
-		Block: *b,
-		Sig:   sig,
+		Block:     *b,
+		Sig:       sig,
+		MakerSigs: makerSigs,
 	}, nil
 }
 
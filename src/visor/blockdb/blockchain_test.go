@@ -254,6 +254,40 @@ func (fup *fakeUnspentPool) AddressCount(tx *dbutil.Tx) (uint64, error) {
 	return uint64(len(addrs)), nil
 }
 
+func (fup *fakeUnspentPool) addressBalances() map[cipher.Address]AddressBalance {
+	balances := make(map[cipher.Address]AddressBalance)
+	for _, out := range fup.outs {
+		b := balances[out.Body.Address]
+		b.Coins += out.Body.Coins
+		b.Hours += out.Body.Hours
+		balances[out.Body.Address] = b
+	}
+
+	return balances
+}
+
+func (fup *fakeUnspentPool) GetAddressBalances(tx *dbutil.Tx, addrs []cipher.Address) (map[cipher.Address]AddressBalance, error) {
+	all := fup.addressBalances()
+	balances := make(map[cipher.Address]AddressBalance, len(addrs))
+	for _, a := range addrs {
+		if b, ok := all[a]; ok {
+			balances[a] = b
+		}
+	}
+
+	return balances, nil
+}
+
+func (fup *fakeUnspentPool) ForEachAddressBalance(tx *dbutil.Tx, f func(cipher.Address, AddressBalance) error) error {
+	for a, b := range fup.addressBalances() {
+		if err := f(a, b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type fakeChainMeta struct {
 	headSeq   uint64
 	didSetSeq bool
@@ -374,12 +408,13 @@ func TestBlockchainAddBlockWithTx(t *testing.T) {
 			tc.fakeStorage.unspent.saveFailed = tc.failedSaves.unspent
 
 			bc := &Blockchain{
-				db:      db,
-				unspent: tc.fakeStorage.unspent,
-				meta:    tc.fakeStorage.chainMeta,
-				tree:    tc.fakeStorage.tree,
-				sigs:    tc.fakeStorage.sigs,
-				walker:  DefaultWalker,
+				db:        db,
+				unspent:   tc.fakeStorage.unspent,
+				meta:      tc.fakeStorage.chainMeta,
+				tree:      tc.fakeStorage.tree,
+				sigs:      tc.fakeStorage.sigs,
+				makerSigs: &blockMakerSigs{},
+				walker:    DefaultWalker,
 			}
 
 			gb := makeGenesisBlock(t)
@@ -0,0 +1,105 @@
+package blockdb
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	// KeyRotationsBkt holds the key rotations, by the seq of the first block they apply to
+	KeyRotationsBkt = []byte("key_rotations")
+	// KeyRotationConflictsBkt holds the key rotations that conflict with a key rotation in KeyRotationsBkt, by hash
+	KeyRotationConflictsBkt = []byte("key_rotation_conflicts")
+)
+
+// KeyRotationConflict records a key rotation signed by the same blockchain authority as a stored key rotation
+type KeyRotationConflict struct {
+	Stored      coin.KeyRotation
+	Conflicting coin.KeyRotation
+}
+
+// keyRotations manages the key rotations of the blockchain signing keys
+type keyRotations struct{}
+
+// Add adds a key rotation to the db
+func (kr *keyRotations) Add(tx *dbutil.Tx, r coin.KeyRotation) error {
+	return dbutil.PutBucketValue(tx, KeyRotationsBkt, dbutil.Itob(r.Seq), encoder.Serialize(r))
+}
+
+// GetAll returns all key rotations, ordered by seq.
+// Databases created before key rotations were added don't have the bucket, and have no key rotations.
+func (kr *keyRotations) GetAll(tx *dbutil.Tx) ([]coin.KeyRotation, error) {
+	if !dbutil.Exists(tx, KeyRotationsBkt) {
+		return nil, nil
+	}
+
+	var rotations []coin.KeyRotation
+	if err := dbutil.ForEach(tx, KeyRotationsBkt, func(_, v []byte) error {
+		var r coin.KeyRotation
+		if err := encoder.DeserializeRaw(v, &r); err != nil {
+			return err
+		}
+
+		rotations = append(rotations, r)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return rotations, nil
+}
+
+// RemoveFrom removes the key rotations whose seq is at least seq
+func (kr *keyRotations) RemoveFrom(tx *dbutil.Tx, seq uint64) error {
+	rotations, err := kr.GetAll(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rotations {
+		if r.Seq < seq {
+			continue
+		}
+
+		if err := dbutil.Delete(tx, KeyRotationsBkt, dbutil.Itob(r.Seq)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddConflict records a key rotation conflict, by the hash of the conflicting key rotation
+func (kr *keyRotations) AddConflict(tx *dbutil.Tx, c KeyRotationConflict) error {
+	return dbutil.PutBucketValue(tx, KeyRotationConflictsBkt, []byte(c.Conflicting.Hash().Hex()), encoder.Serialize(c))
+}
+
+// GetConflicts returns the recorded key rotation conflicts.
+// Databases created before conflicts were recorded don't have the bucket, and have no conflicts.
+func (kr *keyRotations) GetConflicts(tx *dbutil.Tx) ([]KeyRotationConflict, error) {
+	if !dbutil.Exists(tx, KeyRotationConflictsBkt) {
+		return nil, nil
+	}
+
+	var conflicts []KeyRotationConflict
+	if err := dbutil.ForEach(tx, KeyRotationConflictsBkt, func(_, v []byte) error {
+		var c KeyRotationConflict
+		if err := encoder.DeserializeRaw(v, &c); err != nil {
+			return err
+		}
+
+		conflicts = append(conflicts, c)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+// RemoveConflict removes the key rotation conflict of the conflicting key rotation with the given hash
+func (kr *keyRotations) RemoveConflict(tx *dbutil.Tx, hash cipher.SHA256) error {
+	return dbutil.Delete(tx, KeyRotationConflictsBkt, []byte(hash.Hex()))
+}
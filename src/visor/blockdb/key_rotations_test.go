@@ -0,0 +1,53 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestKeyRotations(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	keys := &keyRotations{}
+
+	// The bucket does not exist in databases created before key rotations
+	err := db.View("", func(tx *dbutil.Tx) error {
+		rotations, err := keys.GetAll(tx)
+		require.NoError(t, err)
+		require.Empty(t, rotations)
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, sk := cipher.GenerateKeyPair()
+	var rotations []coin.KeyRotation
+	for _, seq := range []uint64{300, 2, 10} {
+		pk, _ := cipher.GenerateKeyPair()
+		r, err := coin.NewKeyRotation(seq, []cipher.PubKey{pk}, sk)
+		require.NoError(t, err)
+		rotations = append(rotations, *r)
+	}
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, dbutil.CreateBuckets(tx, [][]byte{KeyRotationsBkt}))
+		for _, r := range rotations {
+			require.NoError(t, keys.Add(tx, r))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		got, err := keys.GetAll(tx)
+		require.NoError(t, err)
+		require.Equal(t, []coin.KeyRotation{rotations[1], rotations[2], rotations[0]}, got)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,37 @@
+package blockdb
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	// BlockMakerSigsBkt holds the signatures of the block makers that voted for a block, by block hash
+	BlockMakerSigsBkt = []byte("block_maker_sigs")
+)
+
+// blockMakerSigs manages the signatures of the block makers that voted for the blocks
+type blockMakerSigs struct{}
+
+// Get returns the block maker signatures of a specific block.
+// Databases created before block makers were added don't have the bucket, and have no block maker signatures.
+func (bs blockMakerSigs) Get(tx *dbutil.Tx, hash cipher.SHA256) ([]cipher.Sig, error) {
+	if !dbutil.Exists(tx, BlockMakerSigsBkt) {
+		return nil, nil
+	}
+
+	var sigs []cipher.Sig
+	if ok, err := dbutil.GetBucketObjectDecoded(tx, BlockMakerSigsBkt, hash[:], &sigs); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	return sigs, nil
+}
+
+// Add adds the block maker signatures of a block to the db
+func (bs *blockMakerSigs) Add(tx *dbutil.Tx, hash cipher.SHA256, sigs []cipher.Sig) error {
+	return dbutil.PutBucketValue(tx, BlockMakerSigsBkt, hash[:], encoder.Serialize(sigs))
+}
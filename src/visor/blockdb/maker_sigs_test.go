@@ -0,0 +1,52 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestBlockMakerSigs(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	makerSigs := &blockMakerSigs{}
+	hash := testutil.RandSHA256(t)
+
+	// The bucket does not exist in databases created before block makers
+	err := db.View("", func(tx *dbutil.Tx) error {
+		sigs, err := makerSigs.Get(tx, hash)
+		require.NoError(t, err)
+		require.Empty(t, sigs)
+		return nil
+	})
+	require.NoError(t, err)
+
+	var sigs []cipher.Sig
+	for i := 0; i < 3; i++ {
+		_, sk := cipher.GenerateKeyPair()
+		sigs = append(sigs, cipher.SignHash(hash, sk))
+	}
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, dbutil.CreateBuckets(tx, [][]byte{BlockMakerSigsBkt}))
+		return makerSigs.Add(tx, hash, sigs)
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		got, err := makerSigs.Get(tx, hash)
+		require.NoError(t, err)
+		require.Equal(t, sigs, got)
+
+		got, err = makerSigs.Get(tx, testutil.RandSHA256(t))
+		require.NoError(t, err)
+		require.Empty(t, got)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/encoder"
@@ -14,11 +15,15 @@ import (
 var (
 	xorhashKey         = []byte("xorhash")
 	addrIndexHeightKey = []byte("addr_index_height")
+	// The balance index is rebuilt when its encoding changes, by changing the height key
+	addrBalanceHeightKey = []byte("addr_balance_v2_height")
 
 	// UnspentPoolBkt holds unspent outputs, indexed by unspent output hash
 	UnspentPoolBkt = []byte("unspent_pool")
 	// UnspentPoolAddrIndexBkt maps addresses to their unspent outputs
 	UnspentPoolAddrIndexBkt = []byte("unspent_pool_addr_index")
+	// UnspentPoolAddrBalanceBkt maps addresses to the aggregated balance of their unspent outputs
+	UnspentPoolAddrBalanceBkt = []byte("unspent_pool_addr_balance")
 	// UnspentMetaBkt holds unspent output metadata
 	UnspentMetaBkt = []byte("unspent_meta")
 )
@@ -71,6 +76,21 @@ func (m *unspentMeta) setAddrIndexHeight(tx *dbutil.Tx, height uint64) error {
 	return dbutil.PutBucketValue(tx, UnspentMetaBkt, addrIndexHeightKey, dbutil.Itob(height))
 }
 
+func (m *unspentMeta) getAddrBalanceHeight(tx *dbutil.Tx) (uint64, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, UnspentMetaBkt, addrBalanceHeightKey)
+	if err != nil {
+		return 0, false, err
+	} else if v == nil {
+		return 0, false, nil
+	}
+
+	return dbutil.Btoi(v), true, nil
+}
+
+func (m *unspentMeta) setAddrBalanceHeight(tx *dbutil.Tx, height uint64) error {
+	return dbutil.PutBucketValue(tx, UnspentMetaBkt, addrBalanceHeightKey, dbutil.Itob(height))
+}
+
 type pool struct{}
 
 func (pl pool) get(tx *dbutil.Tx, hash cipher.SHA256) (*coin.UxOut, error) {
@@ -209,19 +229,242 @@ func (p poolAddrIndex) adjust(tx *dbutil.Tx, addr cipher.Address, addHashes, rmH
 	return p.set(tx, addr, newHashes)
 }
 
+// AddressBalance is the aggregated balance of an address's unspent outputs
+type AddressBalance struct {
+	Coins uint64
+	// Hours is the total initial coin hours of the address's unspent outputs
+	Hours uint64
+	// CoinSeconds and DropletSeconds are the coin seconds earned by the address's unspent outputs
+	// until Time, as whole coin seconds and the remaining droplet seconds.
+	// They are only rounded down to coin hours when the balance is read, so that no fraction of
+	// a coin hour is lost when outputs are added or removed.
+	CoinSeconds    uint64
+	DropletSeconds uint64
+	// Time is the block time that the earned coin seconds were calculated at
+	Time uint64
+}
+
+// CoinHours returns the coin hours of the balance at time t.
+// Coin hours are earned by the aggregated coins instead of per output, so the result can exceed
+// the sum of the individual outputs' coin hours by less than one coin hour per output.
+func (b AddressBalance) CoinHours(t uint64) (uint64, error) {
+	b.earn(t)
+	return coin.AddUint64(b.Hours, b.CoinSeconds/3600)
+}
+
+// earn adds the coin seconds earned by the balance's coins since Time, and advances Time to t
+func (b *AddressBalance) earn(t uint64) {
+	if t <= b.Time {
+		return
+	}
+
+	b.addCoinSeconds(b.Coins, t-b.Time)
+	b.Time = t
+}
+
+// add adds an unspent output's coins and hours to the balance, at time t
+func (b *AddressBalance) add(ux coin.UxOut, t uint64) error {
+	coins, err := coin.AddUint64(b.Coins, ux.Body.Coins)
+	if err != nil {
+		return err
+	}
+
+	b.earn(t)
+
+	b.Coins = coins
+	b.Hours = addHoursSaturating(b.Hours, ux.Body.Hours)
+	if t > ux.Head.Time {
+		b.addCoinSeconds(ux.Body.Coins, t-ux.Head.Time)
+	}
+
+	return nil
+}
+
+// sub removes an unspent output's coins and hours from the balance, at time t
+func (b *AddressBalance) sub(ux coin.UxOut, t uint64) error {
+	if ux.Body.Coins > b.Coins {
+		return fmt.Errorf("address balance of %s has fewer coins than uxout %s", ux.Body.Address.String(), ux.Hash().Hex())
+	}
+
+	b.earn(t)
+
+	b.Coins -= ux.Body.Coins
+	if ux.Body.Hours > b.Hours {
+		// The initial hours can only be lower than the output's hours if their sum overflowed
+		b.Hours = 0
+	} else {
+		b.Hours -= ux.Body.Hours
+	}
+	if t > ux.Head.Time {
+		b.subCoinSeconds(ux.Body.Coins, t-ux.Head.Time)
+	}
+
+	return nil
+}
+
+// addCoinSeconds adds the coin seconds earned by droplets in seconds.
+// Overflowing coin seconds saturate, like overflowing coin hours.
+func (b *AddressBalance) addCoinSeconds(droplets, seconds uint64) {
+	coinSeconds, dropletSeconds := splitCoinSeconds(droplets, seconds)
+
+	b.DropletSeconds += dropletSeconds
+	coinSeconds = addHoursSaturating(coinSeconds, b.DropletSeconds/1e6)
+	b.DropletSeconds %= 1e6
+
+	b.CoinSeconds = addHoursSaturating(b.CoinSeconds, coinSeconds)
+}
+
+// subCoinSeconds removes the coin seconds earned by droplets in seconds
+func (b *AddressBalance) subCoinSeconds(droplets, seconds uint64) {
+	coinSeconds, dropletSeconds := splitCoinSeconds(droplets, seconds)
+
+	if dropletSeconds > b.DropletSeconds {
+		b.DropletSeconds += 1e6
+		coinSeconds++
+	}
+	b.DropletSeconds -= dropletSeconds
+
+	if coinSeconds > b.CoinSeconds {
+		// The earned coin seconds can only be lower than the output's if their sum overflowed
+		b.CoinSeconds = 0
+		b.DropletSeconds = 0
+	} else {
+		b.CoinSeconds -= coinSeconds
+	}
+}
+
+// splitCoinSeconds returns the coin seconds earned by droplets in seconds,
+// as whole coin seconds and the remaining droplet seconds
+func splitCoinSeconds(droplets, seconds uint64) (uint64, uint64) {
+	dropletSeconds := mulHoursSaturating(droplets%1e6, seconds)
+	coinSeconds := addHoursSaturating(mulHoursSaturating(droplets/1e6, seconds), dropletSeconds/1e6)
+	return coinSeconds, dropletSeconds % 1e6
+}
+
+func mulHoursSaturating(a, b uint64) uint64 {
+	c := a * b
+	if a != 0 && c/a != b {
+		return math.MaxUint64
+	}
+	return c
+}
+
+func addHoursSaturating(a, b uint64) uint64 {
+	c, err := coin.AddUint64(a, b)
+	if err != nil {
+		return math.MaxUint64
+	}
+	return c
+}
+
+type poolAddrBalance struct{}
+
+func (p poolAddrBalance) get(tx *dbutil.Tx, addr cipher.Address) (*AddressBalance, error) {
+	var b AddressBalance
+
+	if ok, err := dbutil.GetBucketObjectDecoded(tx, UnspentPoolAddrBalanceBkt, addr.Bytes(), &b); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	return &b, nil
+}
+
+func (p poolAddrBalance) set(tx *dbutil.Tx, addr cipher.Address, b AddressBalance) error {
+	// Delete the row if there are no coins left, so that the length of the bucket can
+	// be used to determine the number of addresses with unspents
+	if b.Coins == 0 {
+		return dbutil.Delete(tx, UnspentPoolAddrBalanceBkt, addr.Bytes())
+	}
+
+	return dbutil.PutBucketValue(tx, UnspentPoolAddrBalanceBkt, addr.Bytes(), encoder.Serialize(b))
+}
+
+func (p poolAddrBalance) forEach(tx *dbutil.Tx, f func(cipher.Address, AddressBalance) error) error {
+	return dbutil.ForEach(tx, UnspentPoolAddrBalanceBkt, func(k, v []byte) error {
+		addr, err := cipher.AddressFromBytes(k)
+		if err != nil {
+			return err
+		}
+
+		var b AddressBalance
+		if err := encoder.DeserializeRaw(v, &b); err != nil {
+			return err
+		}
+
+		return f(addr, b)
+	})
+}
+
+// adjust applies added and removed unspent outputs to the balances of their addresses, at time t
+func (p poolAddrBalance) adjust(tx *dbutil.Tx, addUxs, rmUxs coin.UxArray, t uint64) error {
+	balances := make(map[cipher.Address]*AddressBalance)
+
+	getBalance := func(addr cipher.Address) (*AddressBalance, error) {
+		if b, ok := balances[addr]; ok {
+			return b, nil
+		}
+
+		b, err := p.get(tx, addr)
+		if err != nil {
+			return nil, err
+		} else if b == nil {
+			b = &AddressBalance{
+				Time: t,
+			}
+		}
+
+		balances[addr] = b
+		return b, nil
+	}
+
+	for _, ux := range rmUxs {
+		b, err := getBalance(ux.Body.Address)
+		if err != nil {
+			return err
+		}
+
+		if err := b.sub(ux, t); err != nil {
+			return fmt.Errorf("poolAddrBalance.adjust: %v", err)
+		}
+	}
+
+	for _, ux := range addUxs {
+		b, err := getBalance(ux.Body.Address)
+		if err != nil {
+			return err
+		}
+
+		if err := b.add(ux, t); err != nil {
+			return fmt.Errorf("poolAddrBalance.adjust: %v", err)
+		}
+	}
+
+	for addr, b := range balances {
+		if err := p.set(tx, addr, *b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Unspents unspent outputs pool
 type Unspents struct {
-	pool          *pool
-	poolAddrIndex *poolAddrIndex
-	meta          *unspentMeta
+	pool            *pool
+	poolAddrIndex   *poolAddrIndex
+	poolAddrBalance *poolAddrBalance
+	meta            *unspentMeta
 }
 
 // NewUnspentPool creates new unspent pool instance
 func NewUnspentPool() *Unspents {
 	return &Unspents{
-		pool:          &pool{},
-		poolAddrIndex: &poolAddrIndex{},
-		meta:          &unspentMeta{},
+		pool:            &pool{},
+		poolAddrIndex:   &poolAddrIndex{},
+		poolAddrBalance: &poolAddrBalance{},
+		meta:            &unspentMeta{},
 	}
 }
 
@@ -229,6 +472,14 @@ func NewUnspentPool() *Unspents {
 func (up *Unspents) MaybeBuildIndexes(tx *dbutil.Tx, headSeq uint64) error {
 	logger.Info("Unspents.MaybeBuildIndexes")
 
+	if err := up.maybeBuildAddrIndex(tx, headSeq); err != nil {
+		return err
+	}
+
+	return up.maybeBuildAddrBalanceIndex(tx, headSeq)
+}
+
+func (up *Unspents) maybeBuildAddrIndex(tx *dbutil.Tx, headSeq uint64) error {
 	// Compare the addrIndexHeight to the head block,
 	// if not equal, rebuild the address index
 	addrIndexHeight, ok, err := up.meta.getAddrIndexHeight(tx)
@@ -249,6 +500,27 @@ func (up *Unspents) MaybeBuildIndexes(tx *dbutil.Tx, headSeq uint64) error {
 	return up.buildAddrIndex(tx)
 }
 
+func (up *Unspents) maybeBuildAddrBalanceIndex(tx *dbutil.Tx, headSeq uint64) error {
+	// Compare the addrBalanceHeight to the head block,
+	// if not equal, rebuild the address balance index
+	addrBalanceHeight, ok, err := up.meta.getAddrBalanceHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if ok && addrBalanceHeight == headSeq {
+		return nil
+	}
+
+	if addrBalanceHeight > headSeq {
+		logger.Critical().Warningf("addrBalanceHeight > headSeq (%d > %d)", addrBalanceHeight, headSeq)
+	}
+
+	logger.Infof("Rebuilding unspent_pool_addr_balance (addrBalanceHeightExists=%v, addrBalanceHeight=%d, headSeq=%d)", ok, addrBalanceHeight, headSeq)
+
+	return up.buildAddrBalanceIndex(tx)
+}
+
 func (up *Unspents) buildAddrIndex(tx *dbutil.Tx) error {
 	logger.Info("Building unspent address index")
 
@@ -302,6 +574,63 @@ func (up *Unspents) buildAddrIndex(tx *dbutil.Tx) error {
 	return nil
 }
 
+func (up *Unspents) buildAddrBalanceIndex(tx *dbutil.Tx) error {
+	logger.Info("Building unspent address balance index")
+
+	// The bucket is created here if missing, since databases created before the
+	// balance index was added will not have it
+	if dbutil.Exists(tx, UnspentPoolAddrBalanceBkt) {
+		if err := dbutil.Reset(tx, UnspentPoolAddrBalanceBkt); err != nil {
+			return err
+		}
+	} else if err := dbutil.CreateBuckets(tx, [][]byte{UnspentPoolAddrBalanceBkt}); err != nil {
+		return err
+	}
+
+	var uxs coin.UxArray
+	addrs := make(map[cipher.Address]struct{})
+	var maxBlockSeq, maxBlockTime uint64
+	if err := dbutil.ForEach(tx, UnspentPoolBkt, func(_, v []byte) error {
+		var ux coin.UxOut
+		if err := encoder.DeserializeRaw(v, &ux); err != nil {
+			return err
+		}
+
+		if ux.Head.BkSeq > maxBlockSeq {
+			maxBlockSeq = ux.Head.BkSeq
+		}
+
+		if ux.Head.Time > maxBlockTime {
+			maxBlockTime = ux.Head.Time
+		}
+
+		uxs = append(uxs, ux)
+		addrs[ux.Body.Address] = struct{}{}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if len(uxs) == 0 {
+		logger.Infof("No unspents to index")
+		return nil
+	}
+
+	// All balances are calculated at the time of the most recent unspent output
+	if err := up.poolAddrBalance.adjust(tx, uxs, nil, maxBlockTime); err != nil {
+		return err
+	}
+
+	if err := up.meta.setAddrBalanceHeight(tx, maxBlockSeq); err != nil {
+		return err
+	}
+
+	logger.Infof("Indexed balances for %d addresses", len(addrs))
+
+	return nil
+}
+
 // ProcessBlock adds unspents from a block to the unspent pool
 func (up *Unspents) ProcessBlock(tx *dbutil.Tx, b *coin.SignedBlock) error {
 	// Gather all transaction inputs
@@ -386,6 +715,14 @@ func (up *Unspents) ProcessBlock(tx *dbutil.Tx, b *coin.SignedBlock) error {
 		}
 	}
 
+	if err := up.poolAddrBalance.adjust(tx, txnUxs, uxs, b.Block.Head.Time); err != nil {
+		return err
+	}
+
+	if err := up.meta.setAddrBalanceHeight(tx, b.Block.Head.BkSeq); err != nil {
+		return err
+	}
+
 	// Check that the addrIndexHeight is incremental
 	addrIndexHeight, ok, err := up.meta.getAddrIndexHeight(tx)
 	if err != nil {
@@ -478,6 +815,30 @@ func (up *Unspents) GetUxHash(tx *dbutil.Tx) (cipher.SHA256, error) {
 	return up.meta.getXorHash(tx)
 }
 
+// GetAddressBalances returns the aggregated balances of a set of addresses.
+// Addresses without unspents are not included in the returned map.
+func (up *Unspents) GetAddressBalances(tx *dbutil.Tx, addrs []cipher.Address) (map[cipher.Address]AddressBalance, error) {
+	balances := make(map[cipher.Address]AddressBalance, len(addrs))
+
+	for _, addr := range addrs {
+		b, err := up.poolAddrBalance.get(tx, addr)
+		if err != nil {
+			return nil, err
+		} else if b == nil {
+			continue
+		}
+
+		balances[addr] = *b
+	}
+
+	return balances, nil
+}
+
+// ForEachAddressBalance iterates over the aggregated balances of all addresses with unspents
+func (up *Unspents) ForEachAddressBalance(tx *dbutil.Tx, f func(cipher.Address, AddressBalance) error) error {
+	return up.poolAddrBalance.forEach(tx, f)
+}
+
 // AddressCount returns the total number of addresses with unspents
 func (up *Unspents) AddressCount(tx *dbutil.Tx) (uint64, error) {
 	return dbutil.Len(tx, UnspentPoolAddrIndexBkt)
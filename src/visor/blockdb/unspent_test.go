@@ -73,7 +73,11 @@ func addUxOut(db *dbutil.DB, up *Unspents, ux coin.UxOut) error {
 			return err
 		}
 
-		return up.poolAddrIndex.adjust(tx, ux.Body.Address, []cipher.SHA256{ux.Hash()}, nil)
+		if err := up.poolAddrIndex.adjust(tx, ux.Body.Address, []cipher.SHA256{ux.Hash()}, nil); err != nil {
+			return err
+		}
+
+		return up.poolAddrBalance.adjust(tx, coin.UxArray{ux}, nil, ux.Head.Time)
 	})
 }
 
@@ -617,6 +621,36 @@ func TestUnspentProcessBlock(t *testing.T) {
 					require.Nil(t, addrUxHashes)
 				}
 
+				// addr balance height should equal the number of blocks added
+				addrBalanceHeight, ok, err := up.meta.getAddrBalanceHeight(tx)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, uint64(1), addrBalanceHeight)
+
+				// addr balance index should have a row for each indexed address
+				addrBalanceLength, err := dbutil.Len(tx, UnspentPoolAddrBalanceBkt)
+				require.NoError(t, err)
+				require.Equal(t, tc.nIndexedAddrs, addrBalanceLength)
+
+				// addr balances should match the coins of the unspents indexed for the address
+				err = up.ForEachAddressBalance(tx, func(addr cipher.Address, b AddressBalance) error {
+					addrUxs, err := up.GetUnspentsOfAddrs(tx, []cipher.Address{addr})
+					require.NoError(t, err)
+
+					coins, err := addrUxs[addr].Coins()
+					require.NoError(t, err)
+					require.Equal(t, coins, b.Coins)
+
+					hours, err := addrUxs[addr].CoinHours(block.Head.Time)
+					require.NoError(t, err)
+					balanceHours, err := b.CoinHours(block.Head.Time)
+					require.NoError(t, err)
+					require.Equal(t, hours, balanceHours)
+
+					return nil
+				})
+				require.NoError(t, err)
+
 				// none of the rows in the addr index should have empty arrays of hashes
 				err = dbutil.ForEach(tx, UnspentPoolAddrIndexBkt, func(k, v []byte) error {
 					_, err := cipher.AddressFromBytes(k)
@@ -1045,6 +1079,37 @@ func testUnspentMaybeBuildIndexes(t *testing.T, headIndex uint64, setupDB func(*
 
 		require.Empty(t, addrHashes)
 
+		// Check the address->balance index
+		addrCoins := make(map[cipher.Address]uint64)
+		err = dbutil.ForEach(tx, UnspentPoolBkt, func(k, v []byte) error {
+			var ux coin.UxOut
+			err := encoder.DeserializeRaw(v, &ux)
+			require.NoError(t, err)
+
+			addrCoins[ux.Body.Address] += ux.Body.Coins
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		height, ok, err = u.meta.getAddrBalanceHeight(tx)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, uint64(180), height)
+
+		err = u.ForEachAddressBalance(tx, func(addr cipher.Address, b AddressBalance) error {
+			coins, ok := addrCoins[addr]
+			require.True(t, ok)
+			require.Equal(t, coins, b.Coins)
+
+			delete(addrCoins, addr)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Empty(t, addrCoins)
+
 		return nil
 	})
 	require.NoError(t, err)
@@ -1120,3 +1185,68 @@ func setupNoUnspentAddrIndexDB(t *testing.T) (*dbutil.DB, func()) {
 		os.Remove(tmpFile.Name())
 	}
 }
+
+func TestAddressBalanceCoinHours(t *testing.T) {
+	addr := testutil.MakeAddress()
+	now := uint64(1e9)
+
+	makeUx := func(coins, hours uint64) coin.UxOut {
+		return coin.UxOut{
+			Head: coin.UxHead{
+				Time: now,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        addr,
+				Coins:          coins,
+				Hours:          hours,
+			},
+		}
+	}
+
+	// The address holds 1.5 coins, and every 10 minutes it receives a small output
+	// and spends the previous one. Each update earns less than one coin hour.
+	var b AddressBalance
+	uxs := coin.UxArray{makeUx(1.5e6, 10)}
+	require.NoError(t, b.add(uxs[0], now))
+
+	for i := 0; i < 100; i++ {
+		now += 600
+
+		ux := makeUx(1e3, uint64(i))
+		require.NoError(t, b.add(ux, now))
+		uxs = append(uxs, ux)
+
+		if len(uxs) > 2 {
+			require.NoError(t, b.sub(uxs[1], now))
+			uxs = append(uxs[:1], uxs[2:]...)
+		}
+
+		for _, dt := range []uint64{0, 1, 3599, 3600, 86400} {
+			expectHours, err := uxs.CoinHours(now + dt)
+			require.NoError(t, err)
+
+			hours, err := b.CoinHours(now + dt)
+			require.NoError(t, err)
+
+			// The aggregated coin seconds are rounded down once instead of per output
+			require.True(t, hours >= expectHours, "%d < %d", hours, expectHours)
+			require.True(t, hours-expectHours < uint64(len(uxs)), "%d - %d >= %d", hours, expectHours, len(uxs))
+		}
+
+		coins, err := uxs.Coins()
+		require.NoError(t, err)
+		require.Equal(t, coins, b.Coins)
+	}
+
+	// Spending all outputs leaves no coin hours behind
+	now += 86400
+	for _, ux := range uxs {
+		require.NoError(t, b.sub(ux, now))
+	}
+
+	hours, err := b.CoinHours(now)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), hours)
+	require.Equal(t, uint64(0), b.Coins)
+}
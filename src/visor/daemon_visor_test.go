@@ -28,7 +28,7 @@ func setupSimpleVisor(t *testing.T, db *dbutil.DB, bc *Blockchain) *Visor {
 	cfg := NewVisorConfig()
 	cfg.DBPath = db.Path()
 
-	pool, err := NewUnconfirmedTxnPool(db)
+	pool, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{})
 	require.NoError(t, err)
 
 	return &Visor{
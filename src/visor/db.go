@@ -11,7 +11,6 @@ import (
 
 	"github.com/boltdb/bolt"
 
-	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/visor/blockdb"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
@@ -29,8 +28,9 @@ type ErrCorruptDB struct {
 	error
 }
 
-// CheckDatabase checks the database for corruption
-func CheckDatabase(db *dbutil.DB, pubkey cipher.PubKey, quit chan struct{}) error {
+// CheckDatabase checks the database for corruption.
+// Blocks must be signed by one of the pubkeys that cfg and the key rotations saved in the database allow.
+func CheckDatabase(db *dbutil.DB, cfg BlockchainConfig, quit chan struct{}) error {
 	var blocksBktExist bool
 	db.View("CheckDatabase", func(tx *dbutil.Tx) error {
 		blocksBktExist = dbutil.Exists(tx, blockdb.BlocksBkt)
@@ -42,7 +42,7 @@ func CheckDatabase(db *dbutil.DB, pubkey cipher.PubKey, quit chan struct{}) erro
 		return nil
 	}
 
-	bc, err := NewBlockchain(db, BlockchainConfig{Pubkey: pubkey})
+	bc, err := NewBlockchain(db, cfg)
 	if err != nil {
 		return err
 	}
@@ -51,7 +51,7 @@ func CheckDatabase(db *dbutil.DB, pubkey cipher.PubKey, quit chan struct{}) erro
 	indexesMap := historydb.NewIndexesMap()
 	verifyFunc := func(tx *dbutil.Tx, b *coin.SignedBlock) error {
 		// Verify signature
-		if err := bc.VerifySignature(b); err != nil {
+		if err := bc.VerifySignature(tx, b); err != nil {
 			return err
 		}
 
@@ -72,8 +72,8 @@ func CheckDatabase(db *dbutil.DB, pubkey cipher.PubKey, quit chan struct{}) erro
 
 // ResetCorruptDB checks the database for corruption and if corrupted, then it erases the db and starts over.
 // A copy of the corrupted database is saved.
-func ResetCorruptDB(db *dbutil.DB, pubkey cipher.PubKey, quit chan struct{}) (*dbutil.DB, error) {
-	err := CheckDatabase(db, pubkey, quit)
+func ResetCorruptDB(db *dbutil.DB, cfg BlockchainConfig, quit chan struct{}) (*dbutil.DB, error) {
+	err := CheckDatabase(db, cfg, quit)
 
 	switch err.(type) {
 	case nil:
@@ -1,6 +1,7 @@
 package dbutil
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher/encoder"
 	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/util/metrics"
 )
 
 var (
@@ -23,11 +25,23 @@ var (
 	txUpdateTrace                = false
 	txDurationLog                = true
 	txDurationReportingThreshold = time.Millisecond * 100
+
+	txDuration = metrics.NewHistogramVec("skycoin_db_tx_duration_seconds",
+		"Time spent in database transactions, by op (view or update)", metrics.DefaultDurationBuckets, "op")
+	txLockWait = metrics.NewHistogramVec("skycoin_db_tx_lock_wait_seconds",
+		"Time spent waiting for a database transaction to start, by op (view or update)", metrics.DefaultDurationBuckets, "op")
 )
 
 // Tx wraps a Tx
 type Tx struct {
 	*bolt.Tx
+	rollbackHandlers []func()
+}
+
+// OnRollback adds a handler function to be executed after an Update transaction fails and is rolled back.
+// It is the counterpart of bolt.Tx.OnCommit, for state kept in memory alongside the database.
+func (tx *Tx) OnRollback(fn func()) {
+	tx.rollbackHandlers = append(tx.rollbackHandlers, fn)
 }
 
 // String is implemented to prevent a panic when mocking methods with *Tx arguments.
@@ -86,7 +100,11 @@ func (db *DB) View(name string, f func(*Tx) error) error {
 	t0 := time.Now()
 
 	err := db.DB.View(func(tx *bolt.Tx) error {
-		return f(&Tx{tx})
+		start := time.Now()
+		txLockWait.WithLabelValues("view").ObserveDuration(start.Sub(t0))
+		defer txDuration.WithLabelValues("view").ObserveSince(start)
+
+		return f(&Tx{Tx: tx})
 	})
 
 	t1 := time.Now()
@@ -113,10 +131,22 @@ func (db *DB) Update(name string, f func(*Tx) error) error {
 
 	t0 := time.Now()
 
+	var t *Tx
 	err := db.DB.Update(func(tx *bolt.Tx) error {
-		return f(&Tx{tx})
+		start := time.Now()
+		txLockWait.WithLabelValues("update").ObserveDuration(start.Sub(t0))
+		defer txDuration.WithLabelValues("update").ObserveSince(start)
+
+		t = &Tx{Tx: tx}
+		return f(t)
 	})
 
+	if err != nil && t != nil {
+		for _, fn := range t.rollbackHandlers {
+			fn()
+		}
+	}
+
 	t1 := time.Now()
 	delta := t1.Sub(t0)
 	if db.DurationLog && delta > db.DurationReportingThreshold {
@@ -292,6 +322,28 @@ func ForEach(tx *Tx, bktName []byte, f func(k, v []byte) error) error {
 	return bkt.ForEach(f)
 }
 
+// ForEachRange calls f for each key in the bucket with min <= key <= max, in key order.
+// If max is nil, there is no upper bound.
+func ForEachRange(tx *Tx, bktName, min, max []byte, f func(k, v []byte) error) error {
+	bkt := tx.Bucket(bktName)
+	if bkt == nil {
+		return NewErrBucketNotExist(bktName)
+	}
+
+	c := bkt.Cursor()
+	for k, v := c.Seek(min); k != nil; k, v = c.Next() {
+		if max != nil && bytes.Compare(k, max) > 0 {
+			return nil
+		}
+
+		if err := f(k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Delete deletes from a bucket
 func Delete(tx *Tx, bktName, key []byte) error {
 	bkt := tx.Bucket(bktName)
@@ -302,7 +354,9 @@ func Delete(tx *Tx, bktName, key []byte) error {
 	return bkt.Delete(key)
 }
 
-// Len returns the number of keys in a bucket
+// Len returns the number of keys in a bucket.
+// It reads the bucket stats, which are expensive to compute and do not include
+// writes made earlier in an uncommitted transaction.
 func Len(tx *Tx, bktName []byte) (uint64, error) {
 	bkt := tx.Bucket(bktName)
 	if bkt == nil {
@@ -320,11 +374,14 @@ func Len(tx *Tx, bktName []byte) (uint64, error) {
 
 // IsEmpty returns true if the bucket is empty
 func IsEmpty(tx *Tx, bktName []byte) (bool, error) {
-	length, err := Len(tx, bktName)
-	if err != nil {
-		return false, err
+	bkt := tx.Bucket(bktName)
+	if bkt == nil {
+		return false, NewErrBucketNotExist(bktName)
 	}
-	return length == 0, nil
+
+	// Checks the first key rather than using Len, see Len
+	k, _ := bkt.Cursor().First()
+	return k == nil, nil
 }
 
 // Exists returns true if the bucket exists
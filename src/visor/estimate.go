@@ -0,0 +1,213 @@
+package visor
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// feeRatePlaces is the number of decimal places fee rates are rounded to
+const feeRatePlaces = 3
+
+var errEstimateWalletRequired = errors.New("Wallet.ID or Wallet.Addresses is required")
+
+// FeeRateDistribution summarizes the fee rates of the valid unconfirmed transactions.
+// Fee rates are in coin hours burned per byte.
+type FeeRateDistribution struct {
+	// Number of transactions
+	Count int
+	// Total size of the transactions, in bytes
+	Size      int
+	Min       decimal.Decimal
+	Quartile1 decimal.Decimal
+	Median    decimal.Decimal
+	Quartile3 decimal.Decimal
+	Max       decimal.Decimal
+}
+
+// TransactionEstimate is an unsigned transaction created to preview a spend,
+// with an estimate of how soon it would be confirmed
+type TransactionEstimate struct {
+	// Transaction with empty signatures
+	Transaction coin.Transaction
+	// Outputs spent by the transaction, in the order of its inputs
+	Inputs []wallet.UxBalance
+	// Coin hours burned
+	Fee uint64
+	// Coin hours burned per byte
+	FeeRate decimal.Decimal
+	// Total size of the unconfirmed transactions with a fee rate at least as high,
+	// which are included in blocks before this transaction
+	SizeAhead int
+	// Number of blocks until the transaction would be included, if no other transactions are received
+	BlocksToConfirm int
+	// Fee rates of the unconfirmed transactions
+	Unconfirmed FeeRateDistribution
+}
+
+// feeRate returns the fee per byte of a transaction
+func feeRate(txnFee uint64, size int) decimal.Decimal {
+	if size <= 0 {
+		return decimal.Zero
+	}
+
+	f := decimal.NewFromBigInt(new(big.Int).SetUint64(txnFee), 0)
+	return f.Div(decimal.New(int64(size), 0))
+}
+
+// newFeeRateDistribution summarizes the fee rates of fees
+func newFeeRateDistribution(fees []UnconfirmedTxnFee) FeeRateDistribution {
+	d := FeeRateDistribution{
+		Count: len(fees),
+	}
+
+	if len(fees) == 0 {
+		return d
+	}
+
+	rates := make([]decimal.Decimal, len(fees))
+	for i, f := range fees {
+		rates[i] = feeRate(f.Fee, f.Size)
+		d.Size += f.Size
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].LessThan(rates[j])
+	})
+
+	percentile := func(p int) decimal.Decimal {
+		return rates[(len(rates)-1)*p/100].Round(feeRatePlaces)
+	}
+
+	d.Min = percentile(0)
+	d.Quartile1 = percentile(25)
+	d.Median = percentile(50)
+	d.Quartile3 = percentile(75)
+	d.Max = percentile(100)
+
+	return d
+}
+
+// GetUnconfirmedFeeRates returns the distribution of the fee rates of the valid unconfirmed transactions
+func (vs *Visor) GetUnconfirmedFeeRates() (*FeeRateDistribution, error) {
+	var fees []UnconfirmedTxnFee
+	if err := vs.DB.View("GetUnconfirmedFeeRates", func(tx *dbutil.Tx) error {
+		var err error
+		fees, err = vs.Unconfirmed.GetFees(tx, vs.Blockchain)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	d := newFeeRateDistribution(fees)
+	return &d, nil
+}
+
+// EstimateTransaction creates an unsigned transaction based upon the parameters in wallet.CreateTransactionParams,
+// choosing the inputs and hours the same way as CreateTransaction, and estimates how soon it would be confirmed.
+// Either params.Wallet.ID or params.Wallet.Addresses must be set. The wallet password is not required.
+func (vs *Visor) EstimateTransaction(params wallet.CreateTransactionParams) (*TransactionEstimate, error) {
+	var allAddrs []cipher.Address
+	if params.Wallet.ID != "" {
+		w, err := vs.Wallets.GetWallet(params.Wallet.ID)
+		if err != nil {
+			return nil, err
+		}
+		allAddrs = w.GetAddresses()
+	} else {
+		if len(params.Wallet.Addresses) == 0 {
+			return nil, wallet.NewError(errEstimateWalletRequired)
+		}
+		allAddrs = params.Wallet.Addresses
+	}
+
+	var auxs coin.AddressUxOuts
+	var head *coin.SignedBlock
+	var fees []UnconfirmedTxnFee
+
+	if err := vs.DB.View("EstimateTransaction", func(tx *dbutil.Tx) error {
+		var err error
+		head, err = vs.Blockchain.Head(tx)
+		if err != nil {
+			return err
+		}
+
+		auxs, err = vs.getCreateTransactionAuxs(tx, params, allAddrs)
+		if err != nil {
+			return err
+		}
+
+		fees, err = vs.Unconfirmed.GetFees(tx, vs.Blockchain)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	txn, inputs, err := wallet.EstimateTransaction(params, auxs, head.Time())
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the constraints that the transaction would be checked against when injected,
+	// except for the signatures
+	if err := VerifySingleTxnUserConstraints(*txn); err != nil {
+		return nil, err
+	}
+
+	uxMap := make(map[cipher.SHA256]coin.UxOut)
+	for _, ux := range auxs.Flatten() {
+		uxMap[ux.Hash()] = ux
+	}
+
+	uxIn := make(coin.UxArray, len(txn.In))
+	for i, h := range txn.In {
+		ux, ok := uxMap[h]
+		if !ok {
+			return nil, errors.New("Estimated transaction's input is not in the unspent outputs, this should not occur")
+		}
+		uxIn[i] = ux
+	}
+
+	if err := VerifySingleTxnSoftConstraints(*txn, head.Time(), uxIn, vs.Config.MaxBlockSize); err != nil {
+		return nil, err
+	}
+
+	txnFee, err := fee.TransactionFee(txn, head.Time(), uxIn)
+	if err != nil {
+		return nil, err
+	}
+
+	size := txn.Size()
+	rate := feeRate(txnFee, size)
+
+	// Blocks are filled with the transactions with the highest fee rate first
+	var sizeAhead int
+	for _, f := range fees {
+		if !feeRate(f.Fee, f.Size).LessThan(rate) {
+			sizeAhead += f.Size
+		}
+	}
+
+	blocks := 1
+	if vs.Config.MaxBlockSize > 0 {
+		blocks = (sizeAhead + size + vs.Config.MaxBlockSize - 1) / vs.Config.MaxBlockSize
+	}
+
+	return &TransactionEstimate{
+		Transaction:     *txn,
+		Inputs:          inputs,
+		Fee:             txnFee,
+		FeeRate:         rate.Round(feeRatePlaces),
+		SizeAhead:       sizeAhead,
+		BlocksToConfirm: blocks,
+		Unconfirmed:     newFeeRateDistribution(fees),
+	}, nil
+}
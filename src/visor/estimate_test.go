@@ -0,0 +1,121 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestNewFeeRateDistribution(t *testing.T) {
+	d := newFeeRateDistribution(nil)
+	require.Equal(t, FeeRateDistribution{}, d)
+
+	fees := []UnconfirmedTxnFee{
+		{Fee: 500, Size: 250},
+		{Fee: 100, Size: 300},
+		{Fee: 1000, Size: 200},
+		{Fee: 0, Size: 100},
+		{Fee: 300, Size: 150},
+	}
+
+	d = newFeeRateDistribution(fees)
+	require.Equal(t, 5, d.Count)
+	require.Equal(t, 1000, d.Size)
+	require.Equal(t, "0", d.Min.String())
+	require.Equal(t, "0.333", d.Quartile1.String())
+	require.Equal(t, "2", d.Median.String())
+	require.Equal(t, "2", d.Quartile3.String())
+	require.Equal(t, "5", d.Max.String())
+}
+
+func TestEstimateTransaction(t *testing.T) {
+	v, addr, sec, shutdown := setupUnconfirmedPoolVisor(t, UnconfirmedTxnPoolConfig{}, 3)
+	defer shutdown()
+
+	uxs := getAddrUxOuts(t, v, addr)
+	require.Len(t, uxs, 3)
+
+	pending, pendingFee := makeFeeTxn(t, v, uxs[0], sec, 10)
+	_, _, err := v.InjectTransaction(pending)
+	require.NoError(t, err)
+
+	shareFactor := decimal.New(5, -1)
+	params := wallet.CreateTransactionParams{
+		HoursSelection: wallet.HoursSelection{
+			Type:        wallet.HoursSelectionTypeAuto,
+			Mode:        wallet.HoursSelectionModeShare,
+			ShareFactor: &shareFactor,
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Coins:   15e6,
+			},
+		},
+	}
+
+	// A wallet or addresses are required
+	_, err = v.EstimateTransaction(params)
+	require.Equal(t, wallet.NewError(errEstimateWalletRequired), err)
+
+	// Unconfirmed spends must be ignored explicitly
+	params.Wallet.Addresses = []cipher.Address{addr}
+	_, err = v.EstimateTransaction(params)
+	require.Equal(t, wallet.ErrSpendingUnconfirmed, err)
+
+	params.IgnoreUnconfirmed = true
+	estimate, err := v.EstimateTransaction(params)
+	require.NoError(t, err)
+
+	// The output spent by the pending transaction is not used
+	require.Len(t, estimate.Inputs, 2)
+	for _, in := range estimate.Inputs {
+		require.NotEqual(t, uxs[0].Hash(), in.Hash)
+	}
+
+	txn := estimate.Transaction
+	require.Equal(t, len(txn.In), len(txn.Sigs))
+	require.Len(t, txn.Out, 2)
+	require.Equal(t, uint64(15e6), txn.Out[0].Coins)
+
+	var inHours, outHours uint64
+	for _, in := range estimate.Inputs {
+		inHours += in.Hours
+	}
+	for _, o := range txn.Out {
+		outHours += o.Hours
+	}
+	require.Equal(t, inHours-outHours, estimate.Fee)
+	require.Equal(t, feeRate(estimate.Fee, txn.Size()).Round(feeRatePlaces), estimate.FeeRate)
+
+	// The pending transaction burns nearly all of its hours, so it is confirmed first
+	pendingRate := feeRate(pendingFee, pending.Size())
+	require.True(t, pendingRate.GreaterThan(estimate.FeeRate))
+	require.Equal(t, pending.Size(), estimate.SizeAhead)
+	require.Equal(t, 1, estimate.BlocksToConfirm)
+
+	require.Equal(t, FeeRateDistribution{
+		Count:     1,
+		Size:      pending.Size(),
+		Min:       pendingRate.Round(feeRatePlaces),
+		Quartile1: pendingRate.Round(feeRatePlaces),
+		Median:    pendingRate.Round(feeRatePlaces),
+		Quartile3: pendingRate.Round(feeRatePlaces),
+		Max:       pendingRate.Round(feeRatePlaces),
+	}, estimate.Unconfirmed)
+
+	rates, err := v.GetUnconfirmedFeeRates()
+	require.NoError(t, err)
+	require.Equal(t, estimate.Unconfirmed, *rates)
+
+	// Nothing was added to the pool
+	require.Equal(t, map[cipher.SHA256]struct{}{
+		pending.Hash(): {},
+	}, getUnconfirmedHashes(t, v))
+}
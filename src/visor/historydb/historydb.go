@@ -23,6 +23,8 @@ func CreateBuckets(tx *dbutil.Tx) error {
 		HistoryMetaBkt,
 		UxOutsBkt,
 		TransactionsBkt,
+		TimeTxnsBkt,
+		AmountTxnsBkt,
 	})
 }
 
@@ -32,16 +34,20 @@ type HistoryDB struct {
 	outputs      *UxOuts       // outputs bucket
 	addrUx       *addressUx    // bucket which stores all UxOuts that address received
 	addrTxns     *addressTxns  // address related transaction bucket
+	timeTxns     *txnIndex     // transactions indexed by block time
+	amountTxns   *txnIndex     // transactions indexed by output coins
 	*historyMeta               // stores history meta info
 }
 
 // New create HistoryDB instance
 func New() *HistoryDB {
 	return &HistoryDB{
-		outputs:  &UxOuts{},
-		txns:     &transactions{},
-		addrUx:   &addressUx{},
-		addrTxns: &addressTxns{},
+		outputs:    &UxOuts{},
+		txns:       &transactions{},
+		addrUx:     &addressUx{},
+		addrTxns:   &addressTxns{},
+		timeTxns:   &txnIndex{bkt: TimeTxnsBkt},
+		amountTxns: &txnIndex{bkt: AmountTxnsBkt},
 	}
 }
 
@@ -76,7 +82,17 @@ func (hd *HistoryDB) NeedsReset(tx *dbutil.Tx) (bool, error) {
 		return false, err
 	}
 
-	if addrTxnsEmpty || addrUxEmpty || txnsEmpty || outputsEmpty {
+	timeTxnsEmpty, err := hd.timeTxns.IsEmpty(tx)
+	if err != nil {
+		return false, err
+	}
+
+	amountTxnsEmpty, err := hd.amountTxns.IsEmpty(tx)
+	if err != nil {
+		return false, err
+	}
+
+	if addrTxnsEmpty || addrUxEmpty || txnsEmpty || outputsEmpty || timeTxnsEmpty || amountTxnsEmpty {
 		return true, nil
 	}
 
@@ -102,6 +118,14 @@ func (hd *HistoryDB) Erase(tx *dbutil.Tx) error {
 		return err
 	}
 
+	if err := hd.timeTxns.Reset(tx); err != nil {
+		return err
+	}
+
+	if err := hd.amountTxns.Reset(tx); err != nil {
+		return err
+	}
+
 	return hd.txns.Reset(tx)
 }
 
@@ -122,6 +146,19 @@ func (hd *HistoryDB) ParseBlock(tx *dbutil.Tx, b coin.Block) error {
 			return err
 		}
 
+		if err := hd.timeTxns.Add(tx, b.Time(), t.Hash()); err != nil {
+			return err
+		}
+
+		coins, err := t.OutputCoins()
+		if err != nil {
+			return err
+		}
+
+		if err := hd.amountTxns.Add(tx, coins, t.Hash()); err != nil {
+			return err
+		}
+
 		for _, in := range t.In {
 			o, err := hd.outputs.Get(tx, in)
 			if err != nil {
@@ -192,6 +229,26 @@ func (hd HistoryDB) GetAddressTxns(tx *dbutil.Tx, address cipher.Address) ([]Tra
 	return hd.txns.GetSlice(tx, hashes)
 }
 
+// GetAddressTxnHashes returns the hashes of all the address related transactions
+func (hd HistoryDB) GetAddressTxnHashes(tx *dbutil.Tx, address cipher.Address) ([]cipher.SHA256, error) {
+	return hd.addrTxns.Get(tx, address)
+}
+
+// GetTimeRangeTxnHashes returns the hashes of transactions executed in blocks with start <= time <= end
+func (hd HistoryDB) GetTimeRangeTxnHashes(tx *dbutil.Tx, start, end uint64) ([]cipher.SHA256, error) {
+	return hd.timeTxns.GetRange(tx, start, end)
+}
+
+// GetAmountRangeTxnHashes returns the hashes of transactions which send min <= coins <= max in their outputs
+func (hd HistoryDB) GetAmountRangeTxnHashes(tx *dbutil.Tx, min, max uint64) ([]cipher.SHA256, error) {
+	return hd.amountTxns.GetRange(tx, min, max)
+}
+
+// GetTransactions returns the transactions of given hashes, skipping hashes that are not found
+func (hd HistoryDB) GetTransactions(tx *dbutil.Tx, hashes []cipher.SHA256) ([]Transaction, error) {
+	return hd.txns.GetSlice(tx, hashes)
+}
+
 // ForEachTxn traverses the transactions bucket
 func (hd HistoryDB) ForEachTxn(tx *dbutil.Tx, f func(cipher.SHA256, *Transaction) error) error {
 	return hd.txns.ForEach(tx, f)
@@ -233,6 +290,20 @@ type AddressIndexes struct {
 
 // Verify checks if the historydb is corrupted
 func (hd HistoryDB) Verify(tx *dbutil.Tx, b *coin.SignedBlock, indexesMap *IndexesMap) error {
+	// The time and amount indexes were added after the other buckets. If they are not built yet,
+	// they are not verified, they will be built when the history is reparsed on startup.
+	timeTxnsBuilt, err := hd.timeTxns.IsBuilt(tx)
+	if err != nil {
+		return err
+	}
+
+	amountTxnsBuilt, err := hd.amountTxns.IsBuilt(tx)
+	if err != nil {
+		return err
+	}
+
+	txnIndexesBuilt := timeTxnsBuilt && amountTxnsBuilt
+
 	for _, t := range b.Body.Transactions {
 		txnHash := t.Hash()
 		txn, err := hd.txns.Get(tx, txnHash)
@@ -245,6 +316,28 @@ func (hd HistoryDB) Verify(tx *dbutil.Tx, b *coin.SignedBlock, indexesMap *Index
 			return ErrHistoryDBCorrupted{err}
 		}
 
+		// Checks the time and amount indexes
+		if txnIndexesBuilt {
+			if ok, err := hd.timeTxns.Has(tx, b.Time(), txnHash); err != nil {
+				return err
+			} else if !ok {
+				err := fmt.Errorf("HistoryDB.Verify: time index of transaction %v does not exist in historydb", txnHash.Hex())
+				return ErrHistoryDBCorrupted{err}
+			}
+
+			coins, err := t.OutputCoins()
+			if err != nil {
+				return err
+			}
+
+			if ok, err := hd.amountTxns.Has(tx, coins, txnHash); err != nil {
+				return err
+			} else if !ok {
+				err := fmt.Errorf("HistoryDB.Verify: amount index of transaction %v does not exist in historydb", txnHash.Hex())
+				return ErrHistoryDBCorrupted{err}
+			}
+		}
+
 		for _, in := range t.In {
 			// Checks the existence of transaction input
 			o, err := hd.outputs.Get(tx, in)
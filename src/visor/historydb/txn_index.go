@@ -0,0 +1,85 @@
+package historydb
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	// TimeTxnsBkt indexes transaction hashes by the time of the block that executed them
+	TimeTxnsBkt = []byte("time_txns")
+	// AmountTxnsBkt indexes transaction hashes by the number of coins sent in their outputs
+	AmountTxnsBkt = []byte("amount_txns")
+)
+
+// txnIndex is a bucket which indexes transaction hashes by a uint64 value,
+// key as value + transaction hash, value is empty.
+// The value is big endian encoded so that the keys are ordered by value,
+// allowing transactions with a value in a given range to be found with a cursor.
+type txnIndex struct {
+	bkt []byte
+}
+
+func txnIndexKey(v uint64, hash cipher.SHA256) []byte {
+	return append(dbutil.Itob(v), hash[:]...)
+}
+
+// Add adds a transaction hash with value v
+func (ti *txnIndex) Add(tx *dbutil.Tx, v uint64, hash cipher.SHA256) error {
+	return dbutil.PutBucketValue(tx, ti.bkt, txnIndexKey(v, hash), []byte{})
+}
+
+// Has returns true if the transaction hash is indexed with value v
+func (ti *txnIndex) Has(tx *dbutil.Tx, v uint64, hash cipher.SHA256) (bool, error) {
+	return dbutil.BucketHasKey(tx, ti.bkt, txnIndexKey(v, hash))
+}
+
+// GetRange returns the hashes of transactions with min <= value <= max, ordered by value
+func (ti *txnIndex) GetRange(tx *dbutil.Tx, min, max uint64) ([]cipher.SHA256, error) {
+	var hashes []cipher.SHA256
+	if err := dbutil.ForEachRange(tx, ti.bkt, txnIndexKey(min, cipher.SHA256{}), txnIndexKey(max, maxSHA256()), func(k, _ []byte) error {
+		hash, err := cipher.SHA256FromBytes(k[8:])
+		if err != nil {
+			return err
+		}
+
+		hashes = append(hashes, hash)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// IsEmpty checks if the index bucket is empty
+func (ti *txnIndex) IsEmpty(tx *dbutil.Tx) (bool, error) {
+	return dbutil.IsEmpty(tx, ti.bkt)
+}
+
+// IsBuilt returns true if the index bucket exists and is not empty
+func (ti *txnIndex) IsBuilt(tx *dbutil.Tx) (bool, error) {
+	if !dbutil.Exists(tx, ti.bkt) {
+		return false, nil
+	}
+
+	empty, err := dbutil.IsEmpty(tx, ti.bkt)
+	if err != nil {
+		return false, err
+	}
+
+	return !empty, nil
+}
+
+// Reset resets the bucket
+func (ti *txnIndex) Reset(tx *dbutil.Tx) error {
+	return dbutil.Reset(tx, ti.bkt)
+}
+
+func maxSHA256() cipher.SHA256 {
+	var h cipher.SHA256
+	for i := range h {
+		h[i] = 0xff
+	}
+	return h
+}
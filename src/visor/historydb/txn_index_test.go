@@ -0,0 +1,106 @@
+package historydb
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestTxnIndexGetRange(t *testing.T) {
+	var hashes []cipher.SHA256
+	for i := 0; i < 5; i++ {
+		hashes = append(hashes, cipher.SumSHA256([]byte(fmt.Sprintf("tx%d", i))))
+	}
+
+	values := []uint64{10, 20, 20, 30, math.MaxUint64}
+
+	var testCases = []struct {
+		name   string
+		min    uint64
+		max    uint64
+		expect []cipher.SHA256
+	}{
+		{
+			"all",
+			0,
+			math.MaxUint64,
+			hashes,
+		},
+		{
+			"inclusive bounds",
+			20,
+			30,
+			hashes[1:4],
+		},
+		{
+			"single value",
+			20,
+			20,
+			hashes[1:3],
+		},
+		{
+			"no match",
+			11,
+			19,
+			nil,
+		},
+		{
+			"max value",
+			31,
+			math.MaxUint64,
+			hashes[4:],
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, td := prepareDB(t)
+			defer td()
+
+			ti := &txnIndex{bkt: AmountTxnsBkt}
+
+			err := db.Update("", func(tx *dbutil.Tx) error {
+				for i, h := range hashes {
+					if err := ti.Add(tx, values[i], h); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+
+			err = db.View("", func(tx *dbutil.Tx) error {
+				hs, err := ti.GetRange(tx, tc.min, tc.max)
+				require.NoError(t, err)
+
+				// Transactions with the same value are ordered by hash
+				expect := make(map[cipher.SHA256]struct{}, len(tc.expect))
+				for _, h := range tc.expect {
+					expect[h] = struct{}{}
+				}
+				require.Len(t, hs, len(tc.expect))
+				for _, h := range hs {
+					require.Contains(t, expect, h)
+				}
+
+				for i, h := range hashes {
+					ok, err := ti.Has(tx, values[i], h)
+					require.NoError(t, err)
+					require.True(t, ok)
+				}
+
+				ok, err := ti.Has(tx, values[0]+1, hashes[0])
+				require.NoError(t, err)
+				require.False(t, ok)
+
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
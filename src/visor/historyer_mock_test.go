@@ -88,6 +88,33 @@ func (m *HistoryerMock) GetAddrUxOuts(p0 *dbutil.Tx, p1 cipher.Address) ([]*hist
 
 }
 
+// GetAddressTxnHashes mocked method
+func (m *HistoryerMock) GetAddressTxnHashes(p0 *dbutil.Tx, p1 cipher.Address) ([]cipher.SHA256, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 []cipher.SHA256
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []cipher.SHA256:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetAddressTxns mocked method
 func (m *HistoryerMock) GetAddressTxns(p0 *dbutil.Tx, p1 cipher.Address) ([]historydb.Transaction, error) {
 
@@ -115,6 +142,60 @@ func (m *HistoryerMock) GetAddressTxns(p0 *dbutil.Tx, p1 cipher.Address) ([]hist
 
 }
 
+// GetAmountRangeTxnHashes mocked method
+func (m *HistoryerMock) GetAmountRangeTxnHashes(p0 *dbutil.Tx, p1 uint64, p2 uint64) ([]cipher.SHA256, error) {
+
+	ret := m.Called(p0, p1, p2)
+
+	var r0 []cipher.SHA256
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []cipher.SHA256:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetTimeRangeTxnHashes mocked method
+func (m *HistoryerMock) GetTimeRangeTxnHashes(p0 *dbutil.Tx, p1 uint64, p2 uint64) ([]cipher.SHA256, error) {
+
+	ret := m.Called(p0, p1, p2)
+
+	var r0 []cipher.SHA256
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []cipher.SHA256:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetTransaction mocked method
 func (m *HistoryerMock) GetTransaction(p0 *dbutil.Tx, p1 cipher.SHA256) (*historydb.Transaction, error) {
 
@@ -142,6 +223,33 @@ func (m *HistoryerMock) GetTransaction(p0 *dbutil.Tx, p1 cipher.SHA256) (*histor
 
 }
 
+// GetTransactions mocked method
+func (m *HistoryerMock) GetTransactions(p0 *dbutil.Tx, p1 []cipher.SHA256) ([]historydb.Transaction, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 []historydb.Transaction
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []historydb.Transaction:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetUxOuts mocked method
 func (m *HistoryerMock) GetUxOuts(p0 *dbutil.Tx, p1 []cipher.SHA256) ([]*historydb.UxOut, error) {
 
@@ -0,0 +1,281 @@
+package visor
+
+import (
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// LedgerEntryType is the direction of a ledger entry
+type LedgerEntryType string
+
+const (
+	// LedgerIncoming is a transaction that doesn't spend outputs of the ledger's addresses
+	LedgerIncoming LedgerEntryType = "incoming"
+	// LedgerOutgoing is a transaction that spends outputs of the ledger's addresses,
+	// and creates outputs for other addresses
+	LedgerOutgoing LedgerEntryType = "outgoing"
+	// LedgerSelfTransfer is a transaction that spends outputs of the ledger's addresses,
+	// and only creates outputs for the ledger's addresses
+	LedgerSelfTransfer LedgerEntryType = "self_transfer"
+)
+
+// LedgerEntry is a transaction seen from a set of addresses, such as the addresses of a wallet.
+// The coins and hours spent and received are netted across the addresses.
+type LedgerEntry struct {
+	Txid   cipher.SHA256
+	Type   LedgerEntryType
+	Status TransactionStatus
+	// Time of the block that executed the transaction, or the time an unconfirmed transaction was received
+	Time uint64
+	// Coins and hours of the outputs of the addresses spent by the transaction
+	CoinsSpent uint64
+	HoursSpent uint64
+	// Coins and hours of the outputs created for the addresses
+	CoinsReceived uint64
+	HoursReceived uint64
+	// Hours burned by the transaction, if it spends outputs of the addresses
+	HoursBurned uint64
+	// Input addresses of an incoming transaction, or output addresses of an outgoing transaction
+	// that are not addresses of the ledger
+	Counterparties []cipher.Address
+}
+
+// GetLedger returns the ledger of a set of addresses: their confirmed transactions in the historydb,
+// followed by their unconfirmed transactions, with start <= time <= end. If end is 0, there is no upper bound.
+func (vs *Visor) GetLedger(addrs []cipher.Address, start, end uint64) ([]LedgerEntry, error) {
+	addrsMap := make(map[cipher.Address]struct{}, len(addrs))
+	for _, a := range addrs {
+		addrsMap[a] = struct{}{}
+	}
+
+	var entries []LedgerEntry
+	if err := vs.DB.View("GetLedger", func(tx *dbutil.Tx) error {
+		if len(addrs) == 0 {
+			return nil
+		}
+
+		// The time range filter finds the transactions with the historydb indexes,
+		// including the unconfirmed transactions that spend outputs of the addresses
+		txns, err := vs.searchTxns(tx, addrs, []indexedTxFilter{timeRangeFilter{
+			Start: start,
+			End:   end,
+		}}, nil)
+		if err != nil {
+			return err
+		}
+
+		head, err := vs.Blockchain.Head(tx)
+		if err != nil {
+			return err
+		}
+
+		// The hours of the inputs of a confirmed transaction are calculated at the time of
+		// the previous block, like when the block was executed
+		blockTimes := make(map[uint64]uint64)
+		blockTime := func(seq uint64) (uint64, error) {
+			if t, ok := blockTimes[seq]; ok {
+				return t, nil
+			}
+
+			b, err := vs.Blockchain.GetSignedBlockBySeq(tx, seq)
+			if err != nil {
+				return 0, err
+			}
+
+			var t uint64
+			if b != nil {
+				t = b.Time()
+			}
+			blockTimes[seq] = t
+			return t, nil
+		}
+
+		entries = make([]LedgerEntry, 0, len(txns))
+		for _, txn := range txns {
+			inputs, _, err := vs.getTxnInputs(tx, txn.Txn.In)
+			if err != nil {
+				return err
+			}
+
+			hoursTime := head.Time()
+			if txn.Status.Confirmed && txn.Status.BlockSeq > 0 {
+				hoursTime, err = blockTime(txn.Status.BlockSeq - 1)
+				if err != nil {
+					return err
+				}
+			}
+
+			entries = append(entries, vs.newLedgerEntry(txn, inputs, hoursTime, addrsMap))
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// The unconfirmed transactions are ordered by the time they were received
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Status.Confirmed != entries[j].Status.Confirmed {
+			return entries[i].Status.Confirmed
+		}
+		return !entries[i].Status.Confirmed && entries[i].Time < entries[j].Time
+	})
+
+	return entries, nil
+}
+
+// newLedgerEntry creates the LedgerEntry of a transaction that spends inputs, whose hours are calculated at hoursTime
+func (vs *Visor) newLedgerEntry(txn Transaction, inputs []coin.UxOut, hoursTime uint64, addrs map[cipher.Address]struct{}) LedgerEntry {
+	e := LedgerEntry{
+		Txid:   txn.Txn.Hash(),
+		Status: txn.Status,
+		Time:   txn.Time,
+	}
+
+	isOwned := func(a cipher.Address) bool {
+		_, ok := addrs[a]
+		return ok
+	}
+
+	var inputHours, outputHours uint64
+	var spends bool
+	var inputAddrs []cipher.Address
+	for _, in := range inputs {
+		// The overflow bug causes this to fail for some transactions, allow it to pass
+		hours, err := in.CoinHours(hoursTime)
+		if err != nil {
+			vs.log().Critical().Warningf("Ignoring newLedgerEntry ux.CoinHours failed: %v", err)
+			hours = 0
+		}
+		inputHours += hours
+
+		if isOwned(in.Body.Address) {
+			spends = true
+			e.CoinsSpent += in.Body.Coins
+			e.HoursSpent += hours
+		} else {
+			inputAddrs = append(inputAddrs, in.Body.Address)
+		}
+	}
+
+	var outputAddrs []cipher.Address
+	for _, out := range txn.Txn.Out {
+		outputHours += out.Hours
+
+		if isOwned(out.Address) {
+			e.CoinsReceived += out.Coins
+			e.HoursReceived += out.Hours
+		} else {
+			outputAddrs = append(outputAddrs, out.Address)
+		}
+	}
+
+	switch {
+	case !spends:
+		e.Type = LedgerIncoming
+		e.Counterparties = uniqueAddresses(inputAddrs)
+	case len(outputAddrs) == 0:
+		e.Type = LedgerSelfTransfer
+	default:
+		e.Type = LedgerOutgoing
+		e.Counterparties = uniqueAddresses(outputAddrs)
+	}
+
+	if spends && inputHours > outputHours {
+		e.HoursBurned = inputHours - outputHours
+	}
+
+	return e
+}
+
+// uniqueAddresses removes the duplicate addresses, keeping the order of their first appearance
+func uniqueAddresses(addrs []cipher.Address) []cipher.Address {
+	seen := make(map[cipher.Address]struct{}, len(addrs))
+	var unique []cipher.Address
+	for _, a := range addrs {
+		if _, ok := seen[a]; ok {
+			continue
+		}
+		seen[a] = struct{}{}
+		unique = append(unique, a)
+	}
+	return unique
+}
+
+// ReadableLedgerEntry is the readable form of a LedgerEntry
+type ReadableLedgerEntry struct {
+	Txid          string          `json:"txid"`
+	Type          LedgerEntryType `json:"type"`
+	Timestamp     uint64          `json:"timestamp"`
+	Confirmed     bool            `json:"confirmed"`
+	Confirmations uint64          `json:"confirmations"`
+	BlockSeq      uint64          `json:"block_seq"`
+	// Coins received minus coins spent, in decimal coins
+	NetCoins string `json:"net_coins"`
+	// Hours received minus hours spent
+	NetHours       int64    `json:"net_hours"`
+	HoursBurned    uint64   `json:"hours_burned"`
+	Counterparties []string `json:"counterparties"`
+	Note           string   `json:"note"`
+}
+
+// NewReadableLedgerEntry creates a ReadableLedgerEntry, without a note
+func NewReadableLedgerEntry(e LedgerEntry) (ReadableLedgerEntry, error) {
+	netCoins, err := signedDroplets(e.CoinsReceived, e.CoinsSpent)
+	if err != nil {
+		return ReadableLedgerEntry{}, err
+	}
+
+	counterparties := make([]string, len(e.Counterparties))
+	for i, a := range e.Counterparties {
+		counterparties[i] = a.String()
+	}
+
+	var blockSeq uint64
+	if e.Status.Confirmed {
+		blockSeq = e.Status.BlockSeq
+	}
+
+	return ReadableLedgerEntry{
+		Txid:           e.Txid.Hex(),
+		Type:           e.Type,
+		Timestamp:      e.Time,
+		Confirmed:      e.Status.Confirmed,
+		Confirmations:  e.Status.Height,
+		BlockSeq:       blockSeq,
+		NetCoins:       netCoins,
+		NetHours:       int64(e.HoursReceived) - int64(e.HoursSpent),
+		HoursBurned:    e.HoursBurned,
+		Counterparties: counterparties,
+	}, nil
+}
+
+// NewReadableLedger creates the ReadableLedgerEntries of a ledger
+func NewReadableLedger(entries []LedgerEntry) ([]ReadableLedgerEntry, error) {
+	rEntries := make([]ReadableLedgerEntry, len(entries))
+	for i, e := range entries {
+		var err error
+		rEntries[i], err = NewReadableLedgerEntry(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rEntries, nil
+}
+
+// signedDroplets returns received - spent as a decimal coins string, with a minus sign if it is negative
+func signedDroplets(received, spent uint64) (string, error) {
+	if received >= spent {
+		return droplet.ToString(received - spent)
+	}
+
+	s, err := droplet.ToString(spent - received)
+	if err != nil {
+		return "", err
+	}
+	return "-" + s, nil
+}
@@ -0,0 +1,224 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestNewLedgerEntry(t *testing.T) {
+	wltAddr1 := testutil.MakeAddress()
+	wltAddr2 := testutil.MakeAddress()
+	otherAddr1 := testutil.MakeAddress()
+	otherAddr2 := testutil.MakeAddress()
+
+	addrs := map[cipher.Address]struct{}{
+		wltAddr1: {},
+		wltAddr2: {},
+	}
+
+	// The inputs have no earned hours at hoursTime
+	const hoursTime = 1000
+	makeUxOut := func(addr cipher.Address, coins, hours uint64) coin.UxOut {
+		return coin.UxOut{
+			Head: coin.UxHead{
+				Time: hoursTime,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        addr,
+				Coins:          coins,
+				Hours:          hours,
+			},
+		}
+	}
+
+	makeTxn := func(outs ...coin.TransactionOutput) Transaction {
+		return Transaction{
+			Txn: coin.Transaction{
+				Out: outs,
+			},
+			Status: NewConfirmedTransactionStatus(3, 10),
+			Time:   2000,
+		}
+	}
+
+	cases := []struct {
+		name   string
+		txn    Transaction
+		inputs []coin.UxOut
+		entry  LedgerEntry
+		net    string
+	}{
+		{
+			name: "incoming",
+			txn: makeTxn(
+				coin.TransactionOutput{Address: wltAddr1, Coins: 2e6, Hours: 10},
+				coin.TransactionOutput{Address: otherAddr1, Coins: 3e6, Hours: 10},
+			),
+			inputs: []coin.UxOut{
+				makeUxOut(otherAddr1, 3e6, 30),
+				makeUxOut(otherAddr2, 2e6, 20),
+				makeUxOut(otherAddr1, 1e6, 10),
+			},
+			entry: LedgerEntry{
+				Type:           LedgerIncoming,
+				CoinsReceived:  2e6,
+				HoursReceived:  10,
+				Counterparties: []cipher.Address{otherAddr1, otherAddr2},
+			},
+			net: "2.000000",
+		},
+		{
+			name: "outgoing",
+			txn: makeTxn(
+				coin.TransactionOutput{Address: otherAddr1, Coins: 1500000, Hours: 5},
+				coin.TransactionOutput{Address: wltAddr2, Coins: 500000, Hours: 5},
+			),
+			inputs: []coin.UxOut{
+				makeUxOut(wltAddr1, 1e6, 20),
+				makeUxOut(wltAddr2, 1e6, 20),
+			},
+			entry: LedgerEntry{
+				Type:           LedgerOutgoing,
+				CoinsSpent:     2e6,
+				HoursSpent:     40,
+				CoinsReceived:  500000,
+				HoursReceived:  5,
+				HoursBurned:    30,
+				Counterparties: []cipher.Address{otherAddr1},
+			},
+			net: "-1.500000",
+		},
+		{
+			name: "self transfer",
+			txn: makeTxn(
+				coin.TransactionOutput{Address: wltAddr2, Coins: 1e6, Hours: 4},
+			),
+			inputs: []coin.UxOut{
+				makeUxOut(wltAddr1, 1e6, 8),
+			},
+			entry: LedgerEntry{
+				Type:          LedgerSelfTransfer,
+				CoinsSpent:    1e6,
+				HoursSpent:    8,
+				CoinsReceived: 1e6,
+				HoursReceived: 4,
+				HoursBurned:   4,
+			},
+			net: "0.000000",
+		},
+	}
+
+	vs := &Visor{}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.entry.Txid = tc.txn.Txn.Hash()
+			tc.entry.Status = tc.txn.Status
+			tc.entry.Time = tc.txn.Time
+
+			e := vs.newLedgerEntry(tc.txn, tc.inputs, hoursTime, addrs)
+			require.Equal(t, tc.entry, e)
+
+			r, err := NewReadableLedgerEntry(e)
+			require.NoError(t, err)
+			require.Equal(t, tc.net, r.NetCoins)
+			require.Equal(t, int64(e.HoursReceived)-int64(e.HoursSpent), r.NetHours)
+			require.Equal(t, uint64(3), r.Confirmations)
+			require.Equal(t, uint64(10), r.BlockSeq)
+			require.Len(t, r.Counterparties, len(e.Counterparties))
+		})
+	}
+}
+
+func TestNewReadableLedgerEntryUnconfirmed(t *testing.T) {
+	r, err := NewReadableLedgerEntry(LedgerEntry{
+		Type:       LedgerOutgoing,
+		Status:     NewUnconfirmedTransactionStatus(),
+		CoinsSpent: 1,
+	})
+	require.NoError(t, err)
+	require.False(t, r.Confirmed)
+	require.Equal(t, uint64(0), r.Confirmations)
+	require.Equal(t, "-0.000001", r.NetCoins)
+	require.Equal(t, []string{}, r.Counterparties)
+}
+
+func TestGetLedger(t *testing.T) {
+	v, txns, shutdown := prepareSearchTestVisor(t)
+	defer shutdown()
+
+	type expectEntry struct {
+		txn            coin.Transaction
+		typ            LedgerEntryType
+		confirmations  uint64
+		counterparties []cipher.Address
+	}
+
+	tt := []struct {
+		name       string
+		addrs      []cipher.Address
+		start, end uint64
+		expect     []expectEntry
+	}{
+		{
+			name:  "one address",
+			addrs: []cipher.Address{txns.addrA},
+			expect: []expectEntry{
+				{txns.txn1, LedgerIncoming, 3, []cipher.Address{genAddress}},
+				{txns.txn3, LedgerOutgoing, 1, []cipher.Address{txns.addrB}},
+				{txns.txn4, LedgerIncoming, 0, []cipher.Address{txns.addrB}},
+			},
+		},
+		{
+			name:  "transfers between the addresses",
+			addrs: []cipher.Address{txns.addrA, txns.addrB},
+			expect: []expectEntry{
+				{txns.txn1, LedgerIncoming, 3, []cipher.Address{genAddress}},
+				{txns.txn2, LedgerIncoming, 2, []cipher.Address{genAddress}},
+				{txns.txn3, LedgerSelfTransfer, 1, nil},
+				{txns.txn4, LedgerSelfTransfer, 0, nil},
+			},
+		},
+		{
+			name:  "time range",
+			addrs: []cipher.Address{txns.addrA},
+			start: genTime + 250,
+			end:   genTime + 350,
+			expect: []expectEntry{
+				{txns.txn3, LedgerOutgoing, 1, []cipher.Address{txns.addrB}},
+			},
+		},
+		{
+			name: "no addresses",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := v.GetLedger(tc.addrs, tc.start, tc.end)
+			require.NoError(t, err)
+			require.Len(t, entries, len(tc.expect))
+
+			for i, e := range entries {
+				x := tc.expect[i]
+				require.Equal(t, x.txn.Hash(), e.Txid)
+				require.Equal(t, x.typ, e.Type)
+				require.Equal(t, x.confirmations, e.Status.Height)
+				require.Equal(t, x.counterparties, e.Counterparties)
+			}
+		})
+	}
+
+	// A spent all of its 10 coins in txn3
+	entries, err := v.GetLedger([]cipher.Address{txns.addrA}, genTime+250, genTime+350)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10e6), entries[0].CoinsSpent)
+	require.Equal(t, uint64(0), entries[0].CoinsReceived)
+	require.NotZero(t, entries[0].HoursBurned)
+	require.Equal(t, entries[0].HoursSpent-txns.txn3.Out[0].Hours, entries[0].HoursBurned)
+}
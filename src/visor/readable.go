@@ -559,6 +559,59 @@ func NewReadableBlocks(blocks []coin.SignedBlock) (*ReadableBlocks, error) {
 	}, nil
 }
 
+// ReadableKeyRotation represents a readable key rotation of the blockchain signing keys
+type ReadableKeyRotation struct {
+	Seq     uint64   `json:"seq"`
+	Pubkeys []string `json:"pubkeys"`
+	Sig     string   `json:"sig"`
+}
+
+// NewReadableKeyRotation creates ReadableKeyRotation
+func NewReadableKeyRotation(r coin.KeyRotation) ReadableKeyRotation {
+	pubkeys := make([]string, len(r.Pubkeys))
+	for i, pk := range r.Pubkeys {
+		pubkeys[i] = pk.Hex()
+	}
+
+	return ReadableKeyRotation{
+		Seq:     r.Seq,
+		Pubkeys: pubkeys,
+		Sig:     r.Sig.Hex(),
+	}
+}
+
+// NewReadableKeyRotations converts []coin.KeyRotation to readable key rotations
+func NewReadableKeyRotations(rotations []coin.KeyRotation) []ReadableKeyRotation {
+	rrs := make([]ReadableKeyRotation, len(rotations))
+	for i, r := range rotations {
+		rrs[i] = NewReadableKeyRotation(r)
+	}
+	return rrs
+}
+
+// ToKeyRotation converts ReadableKeyRotation to coin.KeyRotation
+func (r ReadableKeyRotation) ToKeyRotation() (*coin.KeyRotation, error) {
+	pubkeys := make([]cipher.PubKey, len(r.Pubkeys))
+	for i, s := range r.Pubkeys {
+		pk, err := cipher.PubKeyFromHex(s)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pubkey %q: %v", s, err)
+		}
+		pubkeys[i] = pk
+	}
+
+	sig, err := cipher.SigFromHex(r.Sig)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid sig: %v", err)
+	}
+
+	return &coin.KeyRotation{
+		Seq:     r.Seq,
+		Pubkeys: pubkeys,
+		Sig:     sig,
+	}, nil
+}
+
 /*
 	Transactions to and from JSON
 */
@@ -1,9 +1,11 @@
 package visor
 
 import (
+	"container/heap"
 	"sort"
 	"strings"
 
+	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/util/droplet"
 )
 
@@ -41,24 +43,120 @@ func NewRichlist(allAccounts map[string]uint64, lockedAddrs map[string]struct{})
 		})
 	}
 
-	// Sort order:
-	// Higher coins
-	// Locked > unlocked
-	// Address alphabetical
 	sort.Slice(richlist, func(i, j int) bool {
-		if richlist[i].coins == richlist[j].coins {
-			if richlist[i].Locked == richlist[j].Locked {
-				return strings.Compare(richlist[i].Address, richlist[j].Address) < 0
-			}
-			return richlist[i].Locked
-		}
-
-		return richlist[i].coins > richlist[j].coins
+		return richlist[i].ranksBefore(richlist[j])
 	})
 
 	return richlist, nil
 }
 
+// ranksBefore returns true if the balance comes before b in the richlist.
+// Sort order:
+// Higher coins
+// Locked > unlocked
+// Address alphabetical
+func (r RichlistBalance) ranksBefore(b RichlistBalance) bool {
+	if r.coins == b.coins {
+		if r.Locked == b.Locked {
+			return strings.Compare(r.Address, b.Address) < 0
+		}
+		return r.Locked
+	}
+
+	return r.coins > b.coins
+}
+
+// RichlistSummary is the top of the richlist, with the total coins and count of all the addresses considered
+type RichlistSummary struct {
+	Richlist     Richlist
+	TotalCoins   uint64
+	AddressCount int
+}
+
+// richlistHeap is a heap of richlist balances, whose root ranks last in the richlist
+type richlistHeap []RichlistBalance
+
+func (h richlistHeap) Len() int            { return len(h) }
+func (h richlistHeap) Less(i, j int) bool  { return h[j].ranksBefore(h[i]) }
+func (h richlistHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *richlistHeap) Push(x interface{}) { *h = append(*h, x.(RichlistBalance)) }
+func (h *richlistHeap) Pop() interface{} {
+	old := *h
+	b := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return b
+}
+
+// richlistBuilder builds the top n of the richlist from balances added one at a time,
+// without holding the balances that do not make it into the top n
+type richlistBuilder struct {
+	n           int
+	lockedAddrs map[string]struct{}
+	excluded    map[string]struct{}
+	top         richlistHeap
+	summary     RichlistSummary
+}
+
+// newRichlistBuilder creates a richlistBuilder. If n is 0, all balances are kept.
+// Addresses in excluded are ignored.
+func newRichlistBuilder(n int, lockedAddrs, excluded map[string]struct{}) *richlistBuilder {
+	return &richlistBuilder{
+		n:           n,
+		lockedAddrs: lockedAddrs,
+		excluded:    excluded,
+	}
+}
+
+func (rb *richlistBuilder) add(addr string, coins uint64) error {
+	if _, ok := rb.excluded[addr]; ok {
+		return nil
+	}
+
+	total, err := coin.AddUint64(rb.summary.TotalCoins, coins)
+	if err != nil {
+		return err
+	}
+	rb.summary.TotalCoins = total
+	rb.summary.AddressCount++
+
+	_, locked := rb.lockedAddrs[addr]
+	b := RichlistBalance{
+		Address: addr,
+		Locked:  locked,
+		coins:   coins,
+	}
+
+	switch {
+	case rb.n <= 0 || len(rb.top) < rb.n:
+		heap.Push(&rb.top, b)
+	case b.ranksBefore(rb.top[0]):
+		rb.top[0] = b
+		heap.Fix(&rb.top, 0)
+	}
+
+	return nil
+}
+
+// summarize returns the top n of the richlist, in richlist order
+func (rb *richlistBuilder) summarize() (*RichlistSummary, error) {
+	richlist := make(Richlist, len(rb.top))
+	for i := len(richlist) - 1; i >= 0; i-- {
+		b := heap.Pop(&rb.top).(RichlistBalance)
+
+		coins, err := droplet.ToString(b.coins)
+		if err != nil {
+			return nil, err
+		}
+		b.Coins = coins
+
+		richlist[i] = b
+	}
+
+	summary := rb.summary
+	summary.Richlist = richlist
+	return &summary, nil
+}
+
 // FilterAddresses returns the richlist without addresses from the map
 func (r Richlist) FilterAddresses(addrs map[string]struct{}) Richlist {
 	var s Richlist
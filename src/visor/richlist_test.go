@@ -96,3 +96,68 @@ func TestRichlist(t *testing.T) {
 		})
 	}
 }
+
+func TestRichlistBuilder(t *testing.T) {
+	expectedRichlist, err := NewRichlist(getAllAccounts(), getLockedMap())
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		n        int
+		excluded map[string]struct{}
+		result   *RichlistSummary
+	}{
+		{
+			name: "all",
+			result: &RichlistSummary{
+				Richlist:     expectedRichlist,
+				TotalCoins:   15153456,
+				AddressCount: 8,
+			},
+		},
+		{
+			name: "top n",
+			n:    3,
+			result: &RichlistSummary{
+				Richlist:     expectedRichlist[:3],
+				TotalCoins:   15153456,
+				AddressCount: 8,
+			},
+		},
+		{
+			name: "top n ties",
+			n:    5,
+			result: &RichlistSummary{
+				Richlist:     expectedRichlist[:5],
+				TotalCoins:   15153456,
+				AddressCount: 8,
+			},
+		},
+		{
+			name:     "excluded",
+			n:        2,
+			excluded: map[string]struct{}{"c2": struct{}{}, "a2": struct{}{}},
+			result: &RichlistSummary{
+				Richlist: Richlist{
+					RichlistBalance{Address: "c1", Coins: "2.123456", Locked: true, coins: 2123456},
+					RichlistBalance{Address: "b2", Coins: "2.010000", Locked: false, coins: 2010000},
+				},
+				TotalCoins:   8133456,
+				AddressCount: 6,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rb := newRichlistBuilder(tc.n, getLockedMap(), tc.excluded)
+			for addr, coins := range getAllAccounts() {
+				assert.NoError(t, rb.add(addr, coins))
+			}
+
+			summary, err := rb.summarize()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.result, summary)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	// SupplySnapshotsBkt holds daily coin supply snapshots, indexed by the unix time of the start of the day (UTC)
+	SupplySnapshotsBkt = []byte("supply_snapshots")
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// CoinSupply records the coin supply info, in droplets and coin hours
+type CoinSupply struct {
+	// Coins distributed beyond the project
+	CurrentSupply uint64
+	// TotalSupply is CurrentSupply plus coins held by the distribution addresses that are spendable
+	TotalSupply uint64
+	// MaxSupply is the maximum number of coins to be distributed ever
+	MaxSupply uint64
+	// CurrentCoinHourSupply is coins hours in non distribution addresses
+	CurrentCoinHourSupply uint64
+	// TotalCoinHourSupply is coin hours in all addresses including unlocked distribution addresses
+	TotalCoinHourSupply uint64
+	// Distribution addresses which count towards total supply
+	UnlockedAddresses []string
+	// Distribution addresses which are locked and do not count towards total supply
+	LockedAddresses []string
+}
+
+// SupplySnapshot records the coin supply and number of holders at the first block of a day
+type SupplySnapshot struct {
+	// Unix time of the start of the day (UTC)
+	Time uint64
+	// Seq of the block the snapshot was taken at
+	BkSeq uint64
+	// Time of the block the snapshot was taken at
+	BlockTime             uint64
+	CurrentSupply         uint64
+	TotalSupply           uint64
+	CurrentCoinHourSupply uint64
+	TotalCoinHourSupply   uint64
+	// Number of addresses with unspent outputs
+	AddressCount uint64
+}
+
+// dayStart returns the unix time of the start of the day (UTC) that t is in
+func dayStart(t uint64) uint64 {
+	return t - t%secondsPerDay
+}
+
+type supplySnapshots struct{}
+
+func (s supplySnapshots) has(tx *dbutil.Tx, day uint64) (bool, error) {
+	return dbutil.BucketHasKey(tx, SupplySnapshotsBkt, dbutil.Itob(day))
+}
+
+func (s supplySnapshots) put(tx *dbutil.Tx, ss SupplySnapshot) error {
+	return dbutil.PutBucketValue(tx, SupplySnapshotsBkt, dbutil.Itob(ss.Time), encoder.Serialize(ss))
+}
+
+// getRange returns snapshots with start <= Time <= end, ordered by time.
+// If end is 0, there is no upper bound.
+func (s supplySnapshots) getRange(tx *dbutil.Tx, start, end uint64) ([]SupplySnapshot, error) {
+	var snapshots []SupplySnapshot
+
+	if err := dbutil.ForEach(tx, SupplySnapshotsBkt, func(k, v []byte) error {
+		t := dbutil.Btoi(k)
+		if t < start || (end != 0 && t > end) {
+			return nil
+		}
+
+		var ss SupplySnapshot
+		if err := encoder.DeserializeRaw(v, &ss); err != nil {
+			return err
+		}
+
+		snapshots = append(snapshots, ss)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// newCoinSupply calculates the coin supply from the unspent pool's address balance index, at headTime
+func newCoinSupply(tx *dbutil.Tx, unspent blockdb.UnspentPooler, headTime uint64) (*CoinSupply, error) {
+	unlockedAddrs := GetUnlockedDistributionAddresses()
+	lockedAddrs := GetLockedDistributionAddresses()
+
+	unlockedCipherAddrs, err := decodeAddresses(unlockedAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	unlockedBalances, err := unspent.GetAddressBalances(tx, unlockedCipherAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	var unlockedSupply uint64
+	for _, b := range unlockedBalances {
+		unlockedSupply, err = coin.AddUint64(unlockedSupply, b.Coins)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// "total supply" is the number of coins unlocked.
+	// Each distribution address was allocated DistributionAddressInitialBalance coins.
+	totalSupply := uint64(len(unlockedAddrs)) * DistributionAddressInitialBalance
+	totalSupply *= droplet.Multiplier
+
+	if unlockedSupply > totalSupply {
+		return nil, errors.New("unlocked distribution addresses hold more coins than the total supply")
+	}
+
+	// "current supply" is the number of coins distributed from the unlocked pool
+	currentSupply := totalSupply - unlockedSupply
+
+	unlockedAddrMap := makeAddressSet(unlockedCipherAddrs)
+	lockedCipherAddrs, err := decodeAddresses(lockedAddrs)
+	if err != nil {
+		return nil, err
+	}
+	lockedAddrMap := makeAddressSet(lockedCipherAddrs)
+
+	// total coin hours excludes locked distribution addresses,
+	// current coin hours excludes all distribution addresses
+	var totalCoinHours, currentCoinHours uint64
+	if err := unspent.ForEachAddressBalance(tx, func(addr cipher.Address, b blockdb.AddressBalance) error {
+		if _, ok := lockedAddrMap[addr]; ok {
+			return nil
+		}
+
+		hours, err := b.CoinHours(headTime)
+		if err != nil {
+			// Treat overflowing coin hours calculations as 0, as is done for outputs
+			hours = 0
+		}
+
+		totalCoinHours, err = coin.AddUint64(totalCoinHours, hours)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := unlockedAddrMap[addr]; ok {
+			return nil
+		}
+
+		currentCoinHours, err = coin.AddUint64(currentCoinHours, hours)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return &CoinSupply{
+		CurrentSupply:         currentSupply,
+		TotalSupply:           totalSupply,
+		MaxSupply:             MaxCoinSupply * droplet.Multiplier,
+		CurrentCoinHourSupply: currentCoinHours,
+		TotalCoinHourSupply:   totalCoinHours,
+		UnlockedAddresses:     unlockedAddrs,
+		LockedAddresses:       lockedAddrs,
+	}, nil
+}
+
+func decodeAddresses(addrs []string) ([]cipher.Address, error) {
+	cipherAddrs := make([]cipher.Address, len(addrs))
+	for i, a := range addrs {
+		var err error
+		cipherAddrs[i], err = cipher.DecodeBase58Address(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s: %v", a, err)
+		}
+	}
+	return cipherAddrs, nil
+}
+
+func makeAddressSet(addrs []cipher.Address) map[cipher.Address]struct{} {
+	m := make(map[cipher.Address]struct{}, len(addrs))
+	for _, a := range addrs {
+		m[a] = struct{}{}
+	}
+	return m
+}
+
+// maybeSaveSupplySnapshot records a supply snapshot if b is the first block of its day.
+// Snapshots are only recorded as blocks are executed. A database that was synced before the
+// snapshots were added has no snapshots for the days before the upgrade, they are not backfilled.
+func (vs *Visor) maybeSaveSupplySnapshot(tx *dbutil.Tx, b *coin.SignedBlock) error {
+	day := dayStart(b.Time())
+
+	if ok, err := vs.supplySnapshots.has(tx, day); err != nil {
+		return err
+	} else if ok {
+		return nil
+	}
+
+	supply, err := newCoinSupply(tx, vs.Blockchain.Unspent(), b.Time())
+	if err != nil {
+		return err
+	}
+
+	// Count the balances rather than using AddressCount, which is based on dbutil.Len
+	var addrCount uint64
+	if err := vs.Blockchain.Unspent().ForEachAddressBalance(tx, func(cipher.Address, blockdb.AddressBalance) error {
+		addrCount++
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	logger.Infof("Saving supply snapshot for %s at block %d", time.Unix(int64(day), 0).UTC().Format("2006-01-02"), b.Seq())
+
+	return vs.supplySnapshots.put(tx, SupplySnapshot{
+		Time:                  day,
+		BkSeq:                 b.Seq(),
+		BlockTime:             b.Time(),
+		CurrentSupply:         supply.CurrentSupply,
+		TotalSupply:           supply.TotalSupply,
+		CurrentCoinHourSupply: supply.CurrentCoinHourSupply,
+		TotalCoinHourSupply:   supply.TotalCoinHourSupply,
+		AddressCount:          addrCount,
+	})
+}
+
+// GetCoinSupply returns the coin supply, calculated from the address balance index
+func (vs *Visor) GetCoinSupply() (*CoinSupply, error) {
+	var supply *CoinSupply
+	if err := vs.DB.View("GetCoinSupply", func(tx *dbutil.Tx) error {
+		headTime, err := vs.Blockchain.Time(tx)
+		if err != nil {
+			return err
+		}
+
+		supply, err = newCoinSupply(tx, vs.Blockchain.Unspent(), headTime)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return supply, nil
+}
+
+// GetSupplySnapshots returns the daily supply snapshots taken between start and end (unix time, inclusive).
+// If end is 0, all snapshots after start are returned.
+func (vs *Visor) GetSupplySnapshots(start, end uint64) ([]SupplySnapshot, error) {
+	var snapshots []SupplySnapshot
+	if err := vs.DB.View("GetSupplySnapshots", func(tx *dbutil.Tx) error {
+		var err error
+		snapshots, err = vs.supplySnapshots.getRange(tx, start, end)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,170 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+func TestDayStart(t *testing.T) {
+	require.Equal(t, uint64(0), dayStart(0))
+	require.Equal(t, uint64(0), dayStart(secondsPerDay-1))
+	require.Equal(t, uint64(secondsPerDay), dayStart(secondsPerDay))
+	require.Equal(t, uint64(1526342400), dayStart(1526342413))
+}
+
+func TestSupplySnapshots(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{})
+	require.NoError(t, err)
+
+	cfg := NewVisorConfig()
+	cfg.DBPath = db.Path()
+	cfg.IsMaster = true
+	cfg.BlockchainSeckey = genSecret
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		Unconfirmed: unconfirmed,
+		Blockchain:  bc,
+		DB:          db,
+		history:     historydb.New(),
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	// The genesis block is the first block of its day
+	snapshots, err := v.GetSupplySnapshots(0, 0)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+
+	totalSupply := uint64(len(GetUnlockedDistributionAddresses())) * DistributionAddressInitialBalance * droplet.Multiplier
+	genHours := gb.Block.Body.Transactions[0].Out[0].Hours
+	require.Equal(t, SupplySnapshot{
+		Time:                  dayStart(genTime),
+		BkSeq:                 0,
+		BlockTime:             genTime,
+		CurrentSupply:         totalSupply,
+		TotalSupply:           totalSupply,
+		CurrentCoinHourSupply: genHours,
+		TotalCoinHourSupply:   genHours,
+		AddressCount:          1,
+	}, snapshots[0])
+
+	executeBlock := func(when uint64, toAddr cipher.Address) {
+		var head *coin.SignedBlock
+		var uxs coin.UxArray
+		err := db.View("", func(tx *dbutil.Tx) error {
+			var err error
+			head, err = v.Blockchain.Head(tx)
+			if err != nil {
+				return err
+			}
+
+			auxs, err := v.Blockchain.Unspent().GetUnspentsOfAddrs(tx, []cipher.Address{genAddress})
+			if err != nil {
+				return err
+			}
+			uxs = auxs[genAddress]
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, uxs, 1)
+
+		hours, err := uxs[0].CoinHours(when)
+		require.NoError(t, err)
+
+		txn := coin.Transaction{}
+		txn.PushInput(uxs[0].Hash())
+		txn.PushOutput(toAddr, 1e6, hours/4)
+		txn.PushOutput(genAddress, uxs[0].Body.Coins-1e6, hours/4)
+		txn.SignInputs([]cipher.SecKey{genSecret})
+		txn.UpdateHeader()
+
+		var uxHash cipher.SHA256
+		err = db.View("", func(tx *dbutil.Tx) error {
+			var err error
+			uxHash, err = v.Blockchain.Unspent().GetUxHash(tx)
+			return err
+		})
+		require.NoError(t, err)
+
+		b, err := coin.NewBlock(head.Block, when, uxHash, coin.Transactions{txn}, func(t *coin.Transaction) (uint64, error) {
+			return 0, nil
+		})
+		require.NoError(t, err)
+
+		err = v.ExecuteSignedBlock(signBlock(t, v, *b))
+		require.NoError(t, err)
+	}
+
+	// A block on the same day does not create a new snapshot
+	executeBlock(genTime+100, testutil.MakeAddress())
+
+	snapshots, err = v.GetSupplySnapshots(0, 0)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+
+	// A block on the next day creates a new snapshot
+	nextDay := dayStart(genTime) + secondsPerDay
+	executeBlock(nextDay+10, testutil.MakeAddress())
+
+	snapshots, err = v.GetSupplySnapshots(0, 0)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	require.Equal(t, nextDay, snapshots[1].Time)
+	require.Equal(t, uint64(2), snapshots[1].BkSeq)
+	require.Equal(t, nextDay+10, snapshots[1].BlockTime)
+	require.Equal(t, uint64(3), snapshots[1].AddressCount)
+	require.NotEqual(t, uint64(0), snapshots[1].TotalCoinHourSupply)
+
+	// Range queries
+	snapshots, err = v.GetSupplySnapshots(nextDay, 0)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	require.Equal(t, nextDay, snapshots[0].Time)
+
+	snapshots, err = v.GetSupplySnapshots(0, nextDay-1)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	require.Equal(t, dayStart(genTime), snapshots[0].Time)
+
+	// Coin supply matches the latest snapshot, since no blocks were added since
+	supply, err := v.GetCoinSupply()
+	require.NoError(t, err)
+	require.Equal(t, totalSupply, supply.TotalSupply)
+	require.Equal(t, totalSupply, supply.CurrentSupply)
+	require.Equal(t, MaxCoinSupply*droplet.Multiplier, supply.MaxSupply)
+
+	// Richlist is built from the address balance index
+	richlist, err := v.GetRichlist(true, 0)
+	require.NoError(t, err)
+	require.Len(t, richlist, 3)
+	require.Equal(t, genAddress.String(), richlist[0].Address)
+	coins, err := droplet.ToString(gb.Block.Body.Transactions[0].Out[0].Coins - 2e6)
+	require.NoError(t, err)
+	require.Equal(t, coins, richlist[0].Coins)
+
+	// The summary counts all the addresses, not only the top n
+	summary, err := v.GetRichlistSummary(true, 1)
+	require.NoError(t, err)
+	require.Equal(t, richlist[:1], summary.Richlist)
+	require.Equal(t, gb.Block.Body.Transactions[0].Out[0].Coins, summary.TotalCoins)
+	require.Equal(t, 3, summary.AddressCount)
+}
@@ -1,13 +1,20 @@
 package visor
 
 import (
+	"bytes"
 	"errors"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/encoder"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/fee"
 	"github.com/skycoin/skycoin/src/util/utc"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
 )
 
@@ -16,8 +23,37 @@ var (
 	UnconfirmedTxnsBkt = []byte("unconfirmed_txns")
 	// UnconfirmedUnspentsBkt holds unconfirmed unspent outputs
 	UnconfirmedUnspentsBkt = []byte("unconfirmed_unspents")
+	// UnconfirmedSpendsBkt indexes the unconfirmed transactions by the outputs they spend
+	UnconfirmedSpendsBkt = []byte("unconfirmed_spends")
+	// UnconfirmedOutputsBkt indexes the unconfirmed unspent outputs by their hash
+	UnconfirmedOutputsBkt = []byte("unconfirmed_outputs")
 
 	errUpdateObjectDoesNotExist = errors.New("object does not exist in bucket")
+
+	// ErrTxnPoolFull is returned if the unconfirmed pool is full and the transaction's fee per kB is
+	// too low for it to evict any other transaction
+	ErrTxnPoolFull = errors.New("Unconfirmed transaction pool is full and the transaction fee rate is too low")
+	// ErrTxnReplacementFeeTooLow is returned if a transaction spends outputs that are already spent by
+	// transactions in the unconfirmed pool, and does not burn enough coin hours more than all of them
+	ErrTxnReplacementFeeTooLow = errors.New("Transaction double spends unconfirmed transactions and does not burn enough coin hours more than them")
+
+	// errTxnChainTooDeep is returned if a transaction spends outputs of unconfirmed transactions
+	// whose chain of unconfirmed ancestors is longer than the maximum chain depth
+	errTxnChainTooDeep = errors.New("Transaction chain of unconfirmed spends is too deep")
+)
+
+const (
+	// DefaultMaxUnconfirmedTxnsSize is the default maximum total size of the unconfirmed transaction pool, in bytes
+	DefaultMaxUnconfirmedTxnsSize = 1024 * DefaultMaxBlockSize
+	// DefaultMaxUnconfirmedChainDepth is the default maximum number of unconfirmed ancestors of an unconfirmed transaction.
+	// Spending outputs of unconfirmed transactions is disabled by default
+	DefaultMaxUnconfirmedChainDepth = 0
+	// DefaultMinReplacementFeeKB is the default fee per kB of a replacement transaction that it must burn
+	// in addition to the fees of the transactions it replaces
+	DefaultMinReplacementFeeKB = 10
+	// DefaultUnconfirmedTxnsMaxAge is the default age after which unconfirmed transactions expire.
+	// Unconfirmed transactions do not expire by default
+	DefaultUnconfirmedTxnsMaxAge time.Duration = 0
 )
 
 // TxnUnspents maps from coin.Transaction hash to its expected unspents.  The unspents'
@@ -40,7 +76,7 @@ func (tus TxnUnspents) AllForAddress(a cipher.Address) coin.UxArray {
 // UnconfirmedTxn unconfirmed transaction
 type UnconfirmedTxn struct {
 	Txn coin.Transaction
-	// Time the txn was last received
+	// Time the txn was first received
 	Received int64
 	// Time the txn was last checked against the blockchain
 	Checked int64
@@ -202,18 +238,355 @@ func (txus *txUnspents) forEach(tx *dbutil.Tx, f func(cipher.SHA256, coin.UxArra
 	})
 }
 
+// txnSpends indexes the transactions of the pool by the outputs they spend.
+// An output can be spent by several transactions, but only one of them is valid.
+type txnSpends struct{}
+
+func (ts *txnSpends) get(tx *dbutil.Tx, in cipher.SHA256) ([]cipher.SHA256, error) {
+	var hashes []cipher.SHA256
+
+	if ok, err := dbutil.GetBucketObjectDecoded(tx, UnconfirmedSpendsBkt, []byte(in.Hex()), &hashes); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	return hashes, nil
+}
+
+func (ts *txnSpends) add(tx *dbutil.Tx, hash cipher.SHA256, inputs []cipher.SHA256) error {
+	for _, in := range inputs {
+		hashes, err := ts.get(tx, in)
+		if err != nil {
+			return err
+		}
+
+		hashes = append(hashes, hash)
+		if err := dbutil.PutBucketValue(tx, UnconfirmedSpendsBkt, []byte(in.Hex()), encoder.Serialize(hashes)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ts *txnSpends) remove(tx *dbutil.Tx, hash cipher.SHA256, inputs []cipher.SHA256) error {
+	for _, in := range inputs {
+		hashes, err := ts.get(tx, in)
+		if err != nil {
+			return err
+		}
+
+		kept := hashes[:0]
+		for _, h := range hashes {
+			if h != hash {
+				kept = append(kept, h)
+			}
+		}
+
+		if len(kept) == 0 {
+			err = dbutil.Delete(tx, UnconfirmedSpendsBkt, []byte(in.Hex()))
+		} else {
+			err = dbutil.PutBucketValue(tx, UnconfirmedSpendsBkt, []byte(in.Hex()), encoder.Serialize(kept))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// txnOutputs indexes the predicted outputs of the transactions of the pool by their hash
+type txnOutputs struct{}
+
+func (to *txnOutputs) get(tx *dbutil.Tx, hash cipher.SHA256) (*coin.UxOut, error) {
+	var ux coin.UxOut
+
+	if ok, err := dbutil.GetBucketObjectDecoded(tx, UnconfirmedOutputsBkt, []byte(hash.Hex()), &ux); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	return &ux, nil
+}
+
+func (to *txnOutputs) put(tx *dbutil.Tx, uxs coin.UxArray) error {
+	for i := range uxs {
+		if err := dbutil.PutBucketValue(tx, UnconfirmedOutputsBkt, []byte(uxs[i].Hash().Hex()), encoder.Serialize(uxs[i])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (to *txnOutputs) delete(tx *dbutil.Tx, uxs coin.UxArray) error {
+	for i := range uxs {
+		if err := dbutil.Delete(tx, UnconfirmedOutputsBkt, []byte(uxs[i].Hash().Hex())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (to *txnOutputs) forEach(tx *dbutil.Tx, f func(coin.UxOut) error) error {
+	return dbutil.ForEach(tx, UnconfirmedOutputsBkt, func(_, v []byte) error {
+		var ux coin.UxOut
+		if err := encoder.DeserializeRaw(v, &ux); err != nil {
+			return err
+		}
+
+		return f(ux)
+	})
+}
+
+// feeRateEntry is a transaction of the unconfirmed pool in the feeRateIndex
+type feeRateEntry struct {
+	hash cipher.SHA256
+	size int
+	// Fee per kB, calculated like coin.NewSortableTransactions
+	feeKB uint64
+	// False if the fee could not be calculated
+	hasFee bool
+}
+
+func newFeeRateEntry(txn *coin.Transaction, feeCalc coin.FeeCalculator) feeRateEntry {
+	size, hash := txn.SizeHash()
+	e := feeRateEntry{
+		hash: hash,
+		size: size,
+	}
+
+	fee, err := feeCalc(txn)
+	if err != nil {
+		return e
+	}
+
+	e.hasFee = true
+	if fee > math.MaxUint64/1024 {
+		e.feeKB = math.MaxUint64 / uint64(size)
+	} else {
+		e.feeKB = fee * 1024 / uint64(size)
+	}
+
+	return e
+}
+
+// evictBefore returns true if e is evicted before o: transactions whose fee could not be calculated first,
+// then by fee per kB ascending and by hash descending, the reverse of coin.SortableTransactions
+func (e feeRateEntry) evictBefore(o feeRateEntry) bool {
+	if e.hasFee != o.hasFee {
+		return !e.hasFee
+	}
+
+	if e.feeKB != o.feeKB {
+		return e.feeKB < o.feeKB
+	}
+
+	return bytes.Compare(e.hash[:], o.hash[:]) > 0
+}
+
+// feeRateIndex keeps the total size of the unconfirmed pool and its transactions in eviction order,
+// so that the pool does not have to be read to evict transactions when it is full.
+// The fee per kB of a transaction is calculated when it is added to the index.
+// The index is loaded from the pool when it is first needed, and again after a db transaction
+// that changed the pool was rolled back.
+type feeRateIndex struct {
+	sync.Mutex
+	loaded  bool
+	size    int
+	entries []feeRateEntry
+	byHash  map[cipher.SHA256]feeRateEntry
+}
+
+// load replaces the index with the transactions of the pool
+func (idx *feeRateIndex) load(txns coin.Transactions, feeCalc coin.FeeCalculator) {
+	idx.Lock()
+	defer idx.Unlock()
+
+	idx.size = 0
+	idx.entries = make([]feeRateEntry, 0, len(txns))
+	idx.byHash = make(map[cipher.SHA256]feeRateEntry, len(txns))
+
+	for i := range txns {
+		e := newFeeRateEntry(&txns[i], feeCalc)
+		idx.size += e.size
+		idx.entries = append(idx.entries, e)
+		idx.byHash[e.hash] = e
+	}
+
+	sort.Slice(idx.entries, func(i, j int) bool {
+		return idx.entries[i].evictBefore(idx.entries[j])
+	})
+
+	idx.loaded = true
+}
+
+// reset discards the index, which is loaded again when next needed
+func (idx *feeRateIndex) reset() {
+	idx.Lock()
+	defer idx.Unlock()
+
+	idx.loaded = false
+	idx.size = 0
+	idx.entries = nil
+	idx.byHash = nil
+}
+
+// isLoaded returns true if the index has been loaded from the pool
+func (idx *feeRateIndex) isLoaded() bool {
+	idx.Lock()
+	defer idx.Unlock()
+	return idx.loaded
+}
+
+// search returns the position of e in the entries, or where it would be inserted
+func (idx *feeRateIndex) search(e feeRateEntry) int {
+	return sort.Search(len(idx.entries), func(i int) bool {
+		return !idx.entries[i].evictBefore(e)
+	})
+}
+
+// add adds a transaction to the index, if the index is loaded
+func (idx *feeRateIndex) add(txn *coin.Transaction, feeCalc coin.FeeCalculator) {
+	idx.Lock()
+	defer idx.Unlock()
+
+	if !idx.loaded {
+		return
+	}
+
+	e := newFeeRateEntry(txn, feeCalc)
+	if _, ok := idx.byHash[e.hash]; ok {
+		return
+	}
+
+	i := idx.search(e)
+	idx.entries = append(idx.entries, feeRateEntry{})
+	copy(idx.entries[i+1:], idx.entries[i:])
+	idx.entries[i] = e
+
+	idx.byHash[e.hash] = e
+	idx.size += e.size
+}
+
+// remove removes a transaction from the index, if the index is loaded
+func (idx *feeRateIndex) remove(hash cipher.SHA256) {
+	idx.Lock()
+	defer idx.Unlock()
+
+	if !idx.loaded {
+		return
+	}
+
+	e, ok := idx.byHash[hash]
+	if !ok {
+		return
+	}
+
+	i := idx.search(e)
+	idx.entries = append(idx.entries[:i], idx.entries[i+1:]...)
+
+	delete(idx.byHash, hash)
+	idx.size -= e.size
+}
+
+// totalSize returns the total size of the transactions in the index
+func (idx *feeRateIndex) totalSize() int {
+	idx.Lock()
+	defer idx.Unlock()
+	return idx.size
+}
+
+// evictable returns the transactions to evict, in eviction order, for the total size to be at most maxSize
+func (idx *feeRateIndex) evictable(maxSize int) []cipher.SHA256 {
+	idx.Lock()
+	defer idx.Unlock()
+
+	var hashes []cipher.SHA256
+	size := idx.size
+	for i := 0; i < len(idx.entries) && size > maxSize; i++ {
+		hashes = append(hashes, idx.entries[i].hash)
+		size -= idx.entries[i].size
+	}
+
+	return hashes
+}
+
+// UnconfirmedTxnPoolConfig configures the UnconfirmedTxnPool
+type UnconfirmedTxnPoolConfig struct {
+	// Maximum total size of the transactions in the pool, in bytes.
+	// When exceeded, the transactions with the lowest fee per kB are evicted.
+	// If 0, the pool size is not limited.
+	MaxSize int
+	// Maximum number of unconfirmed ancestors of a transaction spending outputs of unconfirmed transactions.
+	// If 0, transactions spending outputs of unconfirmed transactions are not valid.
+	MaxChainDepth int
+	// Fee per kB that a transaction replacing transactions in the pool must burn in addition to their fees
+	MinReplacementFeeKB uint64
+}
+
+// UnconfirmedTxnPoolStats records the size of the unconfirmed pool and how many transactions
+// were evicted or replaced since startup
+type UnconfirmedTxnPoolStats struct {
+	// Number of transactions in the pool
+	Count uint64
+	// Total size of the transactions in the pool, in bytes
+	Size uint64
+	// Maximum total size of the transactions in the pool, 0 if not limited
+	MaxSize uint64
+	// Transactions evicted because the pool was full
+	Evicted uint64
+	// Transactions replaced by a double spending transaction with a higher fee
+	Replaced uint64
+}
+
+// UnconfirmedTxnFee records the fee and size of an unconfirmed transaction
+type UnconfirmedTxnFee struct {
+	Hash cipher.SHA256
+	// Coin hours burned
+	Fee uint64
+	// Size in bytes
+	Size int
+}
+
 // UnconfirmedTxnPool manages unconfirmed transactions
 type UnconfirmedTxnPool struct {
 	db   *dbutil.DB
+	cfg  UnconfirmedTxnPoolConfig
 	txns *unconfirmedTxns
 	// Predicted unspents, assuming txns are valid.  Needed to predict
 	// our future balance and avoid double spending our own coins
 	// Maps from Transaction.Hash() to UxArray.
 	unspent *txUnspents
+	// Indexes of the transactions by the outputs they spend and of their outputs by hash,
+	// so that the pool is not scanned to resolve inputs or find double spends
+	spends  *txnSpends
+	outputs *txnOutputs
+
+	// Total size and eviction order of the transactions, to evict transactions when the pool is full
+	fees *feeRateIndex
+
+	// Counters, accessed atomically
+	evicted  uint64
+	replaced uint64
 }
 
 // NewUnconfirmedTxnPool creates an UnconfirmedTxnPool instance
-func NewUnconfirmedTxnPool(db *dbutil.DB) (*UnconfirmedTxnPool, error) {
+func NewUnconfirmedTxnPool(db *dbutil.DB, cfg UnconfirmedTxnPoolConfig) (*UnconfirmedTxnPool, error) {
+	utp := &UnconfirmedTxnPool{
+		db:      db,
+		cfg:     cfg,
+		txns:    &unconfirmedTxns{},
+		unspent: &txUnspents{},
+		spends:  &txnSpends{},
+		outputs: &txnOutputs{},
+		fees:    &feeRateIndex{},
+	}
+
 	if err := db.View("Check unconfirmed txn pool size", func(tx *dbutil.Tx) error {
 		n, err := dbutil.Len(tx, UnconfirmedTxnsBkt)
 		if err != nil {
@@ -226,11 +599,44 @@ func NewUnconfirmedTxnPool(db *dbutil.DB) (*UnconfirmedTxnPool, error) {
 		return nil, err
 	}
 
-	return &UnconfirmedTxnPool{
-		db:      db,
-		txns:    &unconfirmedTxns{},
-		unspent: &txUnspents{},
-	}, nil
+	if !db.IsReadOnly() {
+		if err := db.Update("Build unconfirmed txn pool indexes", utp.maybeBuildIndexes); err != nil {
+			return nil, err
+		}
+	}
+
+	return utp, nil
+}
+
+// maybeBuildIndexes indexes the transactions of the pool if the indexes are empty,
+// since databases created before the indexes were added do not have them.
+// Every transaction spends an input, so the spends index is only empty if the pool is.
+func (utp *UnconfirmedTxnPool) maybeBuildIndexes(tx *dbutil.Tx) error {
+	empty, err := dbutil.IsEmpty(tx, UnconfirmedSpendsBkt)
+	if err != nil || !empty {
+		return err
+	}
+
+	empty, err = dbutil.IsEmpty(tx, UnconfirmedTxnsBkt)
+	if err != nil || empty {
+		return err
+	}
+
+	logger.Info("Building unconfirmed txn pool indexes")
+
+	if err := dbutil.Reset(tx, UnconfirmedOutputsBkt); err != nil {
+		return err
+	}
+
+	if err := utp.txns.forEach(tx, func(hash cipher.SHA256, utxn UnconfirmedTxn) error {
+		return utp.spends.add(tx, hash, utxn.Txn.In)
+	}); err != nil {
+		return err
+	}
+
+	return utp.unspent.forEach(tx, func(_ cipher.SHA256, uxa coin.UxArray) error {
+		return utp.outputs.put(tx, uxa)
+	})
 }
 
 // SetTxnsAnnounced updates announced time of specific tx
@@ -277,11 +683,17 @@ func createUnconfirmedTxn(txn coin.Transaction) UnconfirmedTxn {
 // existed in the pool.
 // If the transaction violates hard constraints, it is rejected.
 // Soft constraints violations mark a txn as invalid, but the txn is inserted. The soft violation is returned.
+// If a valid transaction spends outputs that are spent by valid transactions in the pool, it replaces them if it burns
+// more coin hours than all of them by at least the minimum replacement fee per kB of its size,
+// otherwise it is rejected with ErrTxnReplacementFeeTooLow.
+// If the pool is full, the transactions with the lowest fee per kB are evicted. If the new transaction
+// would be evicted itself, it is rejected with ErrTxnPoolFull.
+// The transaction may spend outputs of unconfirmed transactions, see VerifySingleTxnSoftHardConstraints.
 func (utp *UnconfirmedTxnPool) InjectTransaction(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, maxSize int) (bool, *ErrTxnViolatesSoftConstraint, error) {
 	var isValid int8 = 1
 	var softErr *ErrTxnViolatesSoftConstraint
-	if err := bc.VerifySingleTxnSoftHardConstraints(tx, txn, maxSize); err != nil {
-		logger.Warningf("bc.VerifySingleTxnSoftHardConstraints failed for txn %s: %v", txn.TxIDHex(), err)
+	if err := utp.VerifySingleTxnSoftHardConstraints(tx, bc, txn, maxSize); err != nil {
+		logger.Warningf("VerifySingleTxnSoftHardConstraints failed for txn %s: %v", txn.TxIDHex(), err)
 		switch err.(type) {
 		case ErrTxnViolatesSoftConstraint:
 			e := err.(ErrTxnViolatesSoftConstraint)
@@ -305,9 +717,7 @@ func (utp *UnconfirmedTxnPool) InjectTransaction(tx *dbutil.Tx, bc Blockchainer,
 	// Update if we already have this txn
 	if known {
 		if err := utp.txns.update(tx, hash, func(utxn *UnconfirmedTxn) error {
-			now := utc.Now().UnixNano()
-			utxn.Received = now
-			utxn.Checked = now
+			utxn.Checked = utc.Now().UnixNano()
 			utxn.IsValid = isValid
 			return nil
 		}); err != nil {
@@ -318,6 +728,32 @@ func (utp *UnconfirmedTxnPool) InjectTransaction(tx *dbutil.Tx, bc Blockchainer,
 		return true, softErr, nil
 	}
 
+	head, err := bc.Head(tx)
+	if err != nil {
+		logger.Errorf("InjectTransaction bc.Head() failed: %v", err)
+		return false, nil, err
+	}
+
+	feeCalc := utp.transactionFee(tx, bc, head.Time())
+
+	// Replace any valid transactions spending the same outputs.
+	// Invalid transactions are not announced or included in blocks, so they do not conflict.
+	var replaced int
+	if isValid == 1 {
+		conflicts, err := utp.getConflicts(tx, txn)
+		if err != nil {
+			logger.Errorf("InjectTransaction getConflicts failed: %v", err)
+			return false, nil, err
+		}
+
+		if len(conflicts) != 0 {
+			replaced, err = utp.replace(tx, feeCalc, txn, conflicts)
+			if err != nil {
+				return false, nil, err
+			}
+		}
+	}
+
 	utx := createUnconfirmedTxn(txn)
 	utx.IsValid = isValid
 
@@ -327,21 +763,239 @@ func (utp *UnconfirmedTxnPool) InjectTransaction(tx *dbutil.Tx, bc Blockchainer,
 		return false, nil, err
 	}
 
-	head, err := bc.Head(tx)
-	if err != nil {
-		logger.Errorf("InjectTransaction bc.Head() failed: %v", err)
-		return false, nil, err
-	}
+	tx.OnRollback(utp.fees.reset)
+	utp.fees.add(&txn, feeCalc)
 
 	// update unconfirmed unspent
-	if err := utp.unspent.put(tx, hash, coin.CreateUnspents(head.Head, txn)); err != nil {
+	uxs := coin.CreateUnspents(head.Head, txn)
+	if err := utp.unspent.put(tx, hash, uxs); err != nil {
 		logger.Errorf("InjectTransaction put new unspent outputs: %v", err)
 		return false, nil, err
 	}
 
+	if err := utp.outputs.put(tx, uxs); err != nil {
+		logger.Errorf("InjectTransaction index new unspent outputs: %v", err)
+		return false, nil, err
+	}
+
+	if err := utp.spends.add(tx, hash, txn.In); err != nil {
+		logger.Errorf("InjectTransaction index spent outputs: %v", err)
+		return false, nil, err
+	}
+
+	evicted, err := utp.evict(tx, feeCalc, hash)
+	if err != nil {
+		return false, nil, err
+	}
+
+	atomic.AddUint64(&utp.replaced, uint64(replaced))
+	atomic.AddUint64(&utp.evicted, uint64(evicted))
+
 	return false, softErr, nil
 }
 
+// getConflicts returns the valid transactions in the pool that spend any of the inputs of txn
+func (utp *UnconfirmedTxnPool) getConflicts(tx *dbutil.Tx, txn coin.Transaction) ([]UnconfirmedTxn, error) {
+	seen := make(map[cipher.SHA256]struct{})
+	var conflicts []UnconfirmedTxn
+	for _, in := range txn.In {
+		hashes, err := utp.spends.get(tx, in)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range hashes {
+			if _, ok := seen[h]; ok {
+				continue
+			}
+			seen[h] = struct{}{}
+
+			utxn, err := utp.txns.get(tx, h)
+			if err != nil {
+				return nil, err
+			}
+
+			if utxn != nil && utxn.IsValid == 1 {
+				conflicts = append(conflicts, *utxn)
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+// minReplacementFee returns the fee that a replacement of the given size must burn
+// in addition to the fees of the transactions it replaces, rounded up
+func (utp *UnconfirmedTxnPool) minReplacementFee(size int) uint64 {
+	if utp.cfg.MinReplacementFeeKB > (math.MaxUint64-1023)/uint64(size) {
+		return math.MaxUint64
+	}
+
+	return (utp.cfg.MinReplacementFeeKB*uint64(size) + 1023) / 1024
+}
+
+// replace removes the conflicting transactions from the pool, if txn burns more coin hours
+// than all of them together, by at least the minimum replacement fee for its size.
+// Otherwise ErrTxnReplacementFeeTooLow is returned.
+// Returns the number of transactions removed.
+func (utp *UnconfirmedTxnPool) replace(tx *dbutil.Tx, feeCalc coin.FeeCalculator, txn coin.Transaction, conflicts []UnconfirmedTxn) (int, error) {
+	txnFee, err := feeCalc(&txn)
+	if err != nil {
+		return 0, err
+	}
+
+	var conflictsFee uint64
+	hashes := make([]cipher.SHA256, len(conflicts))
+	for i, c := range conflicts {
+		hashes[i] = c.Hash()
+
+		// A conflicting transaction whose fee can't be calculated is invalid and does not need to be outbid
+		cFee, err := feeCalc(&c.Txn)
+		if err != nil {
+			continue
+		}
+
+		conflictsFee, err = coin.AddUint64(conflictsFee, cFee)
+		if err != nil {
+			return 0, ErrTxnReplacementFeeTooLow
+		}
+	}
+
+	if txnFee <= conflictsFee {
+		return 0, ErrTxnReplacementFeeTooLow
+	}
+
+	if txnFee-conflictsFee < utp.minReplacementFee(txn.Size()) {
+		return 0, ErrTxnReplacementFeeTooLow
+	}
+
+	// Transactions spending the outputs of the replaced transactions become invalid
+	hashes, err = utp.withDescendants(tx, hashes)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, h := range hashes {
+		logger.Infof("Transaction %s replaced by %s", h.Hex(), txn.Hash().Hex())
+	}
+
+	if err := utp.RemoveTransactions(tx, hashes); err != nil {
+		return 0, err
+	}
+
+	return len(hashes), nil
+}
+
+// evict removes the transactions with the lowest fee per kB until the pool is no larger than the maximum size.
+// Transactions whose fee can't be calculated are evicted first.
+// If the newly injected transaction would be evicted, ErrTxnPoolFull is returned.
+// Returns the number of transactions removed.
+func (utp *UnconfirmedTxnPool) evict(tx *dbutil.Tx, feeCalc coin.FeeCalculator, newHash cipher.SHA256) (int, error) {
+	if utp.cfg.MaxSize <= 0 {
+		return 0, nil
+	}
+
+	if !utp.fees.isLoaded() {
+		txns, err := utp.RawTxns(tx)
+		if err != nil {
+			return 0, err
+		}
+
+		utp.fees.load(txns, feeCalc)
+	}
+
+	evict := utp.fees.evictable(utp.cfg.MaxSize)
+	if len(evict) == 0 {
+		return 0, nil
+	}
+
+	// Transactions spending the outputs of evicted transactions become invalid
+	evict, err := utp.withDescendants(tx, evict)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, h := range evict {
+		if h == newHash {
+			return 0, ErrTxnPoolFull
+		}
+	}
+
+	for _, h := range evict {
+		logger.Infof("Evicting transaction %s from the full unconfirmed pool", h.Hex())
+	}
+
+	if err := utp.RemoveTransactions(tx, evict); err != nil {
+		return 0, err
+	}
+
+	return len(evict), nil
+}
+
+// Stats returns the pool size and the number of evicted and replaced transactions since startup
+func (utp *UnconfirmedTxnPool) Stats(tx *dbutil.Tx) (*UnconfirmedTxnPoolStats, error) {
+	count, err := utp.txns.length(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The size is only counted if the fee rate index has not been loaded yet
+	var size uint64
+	if utp.fees.isLoaded() {
+		size = uint64(utp.fees.totalSize())
+	} else {
+		txns, err := utp.RawTxns(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range txns {
+			size += uint64(txns[i].Size())
+		}
+	}
+
+	return &UnconfirmedTxnPoolStats{
+		Count:    count,
+		Size:     size,
+		MaxSize:  uint64(utp.cfg.MaxSize),
+		Evicted:  atomic.LoadUint64(&utp.evicted),
+		Replaced: atomic.LoadUint64(&utp.replaced),
+	}, nil
+}
+
+// GetFees returns the fee and size of the valid transactions in the pool.
+// Transactions whose fee can't be calculated are skipped.
+func (utp *UnconfirmedTxnPool) GetFees(tx *dbutil.Tx, bc Blockchainer) ([]UnconfirmedTxnFee, error) {
+	utxns, err := utp.GetTxns(tx, IsValid)
+	if err != nil {
+		return nil, err
+	}
+
+	headTime, err := bc.Time(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	feeCalc := utp.transactionFee(tx, bc, headTime)
+
+	fees := make([]UnconfirmedTxnFee, 0, len(utxns))
+	for i := range utxns {
+		f, err := feeCalc(&utxns[i].Txn)
+		if err != nil {
+			continue
+		}
+
+		size, hash := utxns[i].Txn.SizeHash()
+		fees = append(fees, UnconfirmedTxnFee{
+			Hash: hash,
+			Fee:  f,
+			Size: size,
+		})
+	}
+
+	return fees, nil
+}
+
 // RawTxns returns underlying coin.Transactions
 func (utp *UnconfirmedTxnPool) RawTxns(tx *dbutil.Tx) (coin.Transactions, error) {
 	utxns, err := utp.txns.getAll(tx)
@@ -358,10 +1012,35 @@ func (utp *UnconfirmedTxnPool) RawTxns(tx *dbutil.Tx) (coin.Transactions, error)
 
 // Remove a single txn by hash
 func (utp *UnconfirmedTxnPool) removeTxn(tx *dbutil.Tx, txHash cipher.SHA256) error {
+	utxn, err := utp.txns.get(tx, txHash)
+	if err != nil {
+		return err
+	}
+
+	if utxn == nil {
+		return nil
+	}
+
+	if err := utp.spends.remove(tx, txHash, utxn.Txn.In); err != nil {
+		return err
+	}
+
+	uxs, err := utp.unspent.get(tx, txHash)
+	if err != nil {
+		return err
+	}
+
+	if err := utp.outputs.delete(tx, uxs); err != nil {
+		return err
+	}
+
 	if err := utp.txns.delete(tx, txHash); err != nil {
 		return err
 	}
 
+	tx.OnRollback(utp.fees.reset)
+	utp.fees.remove(txHash)
+
 	return utp.unspent.delete(tx, txHash)
 }
 
@@ -376,6 +1055,321 @@ func (utp *UnconfirmedTxnPool) RemoveTransactions(tx *dbutil.Tx, txHashes []ciph
 	return nil
 }
 
+// VerifySingleTxnSoftHardConstraints checks that the transaction does not violate hard or soft constraints,
+// like Blockchain.VerifySingleTxnSoftHardConstraints.
+// If the maximum chain depth is not 0, transactions may spend the predicted outputs of unconfirmed transactions.
+// A transaction whose chain of unconfirmed ancestors is longer than the maximum chain depth violates soft constraints.
+// If it is 0, a transaction spending outputs of unconfirmed transactions violates hard constraints, like in the blockchain.
+func (utp *UnconfirmedTxnPool) VerifySingleTxnSoftHardConstraints(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, maxSize int) error {
+	err := bc.VerifySingleTxnSoftHardConstraints(tx, txn, maxSize)
+	if !isErrUnspentNotExist(err) || utp.cfg.MaxChainDepth == 0 {
+		return err
+	}
+
+	// Some inputs are not confirmed, look for them in the outputs of the unconfirmed transactions
+	uxIn, depth, err := utp.getInputs(tx, bc, txn)
+	if err != nil {
+		return err
+	}
+
+	head, err := bc.Head(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := VerifySingleTxnHardConstraints(txn, head, uxIn); err != nil {
+		return err
+	}
+
+	if depth > utp.cfg.MaxChainDepth {
+		return NewErrTxnViolatesSoftConstraint(errTxnChainTooDeep)
+	}
+
+	return VerifySingleTxnSoftConstraints(txn, head.Time(), uxIn, maxSize)
+}
+
+// verifySingleTxnHardConstraints checks that the transaction does not violate hard constraints,
+// like Blockchain.VerifySingleTxnHardConstraints, for transactions that may spend outputs of unconfirmed transactions
+func (utp *UnconfirmedTxnPool) verifySingleTxnHardConstraints(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction) error {
+	err := bc.VerifySingleTxnHardConstraints(tx, txn)
+	if !isErrUnspentNotExist(err) || utp.cfg.MaxChainDepth == 0 {
+		return err
+	}
+
+	uxIn, _, err := utp.getInputs(tx, bc, txn)
+	if err != nil {
+		return err
+	}
+
+	head, err := bc.Head(tx)
+	if err != nil {
+		return err
+	}
+
+	return VerifySingleTxnHardConstraints(txn, head, uxIn)
+}
+
+// isErrUnspentNotExist returns true if err is a hard constraint violation caused by a missing input
+func isErrUnspentNotExist(err error) bool {
+	e, ok := err.(ErrTxnViolatesHardConstraint)
+	if !ok {
+		return false
+	}
+
+	_, ok = e.Err.(blockdb.ErrUnspentNotExist)
+	return ok
+}
+
+// transactionFee calculates the fee of a transaction like Blockchain.TransactionFee,
+// for transactions that may spend outputs of unconfirmed transactions
+func (utp *UnconfirmedTxnPool) transactionFee(tx *dbutil.Tx, bc Blockchainer, headTime uint64) coin.FeeCalculator {
+	bcFeeCalc := bc.TransactionFee(tx, headTime)
+	return func(txn *coin.Transaction) (uint64, error) {
+		f, err := bcFeeCalc(txn)
+		if _, ok := err.(blockdb.ErrUnspentNotExist); !ok {
+			return f, err
+		}
+
+		uxIn, _, err := utp.getInputs(tx, bc, *txn)
+		if err != nil {
+			return 0, err
+		}
+
+		return fee.TransactionFee(txn, headTime, uxIn)
+	}
+}
+
+// unconfirmedOutputs returns the predicted outputs of all unconfirmed transactions, indexed by their hash
+func (utp *UnconfirmedTxnPool) unconfirmedOutputs(tx *dbutil.Tx) (map[cipher.SHA256]coin.UxOut, error) {
+	outs := make(map[cipher.SHA256]coin.UxOut)
+	if err := utp.outputs.forEach(tx, func(ux coin.UxOut) error {
+		outs[ux.Hash()] = ux
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return outs, nil
+}
+
+// getInputs returns the outputs spent by txn, which are either in the blockchain's unspent pool
+// or predicted outputs of unconfirmed transactions, and the number of unconfirmed ancestors in the
+// longest chain of unconfirmed spends leading to txn. The depth is 0 if all inputs are confirmed.
+// If an input can't be found, ErrTxnViolatesHardConstraint is returned.
+func (utp *UnconfirmedTxnPool) getInputs(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction) (coin.UxArray, int, error) {
+	uxIn, depth, err := utp.resolveInputs(tx, bc, txn, make(map[cipher.SHA256]int))
+	if err != nil {
+		if _, ok := err.(blockdb.ErrUnspentNotExist); ok {
+			return nil, 0, NewErrTxnViolatesHardConstraint(err)
+		}
+		return nil, 0, err
+	}
+
+	return uxIn, depth, nil
+}
+
+// resolveInputs returns the outputs spent by txn and its depth in the chain of unconfirmed spends.
+// depths caches the depths of the unconfirmed transactions already visited.
+func (utp *UnconfirmedTxnPool) resolveInputs(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, depths map[cipher.SHA256]int) (coin.UxArray, int, error) {
+	uxIn := make(coin.UxArray, len(txn.In))
+	depth := 0
+
+	for i, h := range txn.In {
+		ux, err := bc.Unspent().Get(tx, h)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if ux != nil {
+			uxIn[i] = *ux
+			continue
+		}
+
+		out, err := utp.outputs.get(tx, h)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if out == nil {
+			return nil, 0, blockdb.NewErrUnspentNotExist(h.Hex())
+		}
+
+		uxIn[i] = *out
+
+		parentDepth, err := utp.txnDepth(tx, bc, out.Body.SrcTransaction, depths)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if parentDepth+1 > depth {
+			depth = parentDepth + 1
+		}
+	}
+
+	return uxIn, depth, nil
+}
+
+// txnDepth returns the depth of an unconfirmed transaction in the chain of unconfirmed spends
+func (utp *UnconfirmedTxnPool) txnDepth(tx *dbutil.Tx, bc Blockchainer, hash cipher.SHA256, depths map[cipher.SHA256]int) (int, error) {
+	if d, ok := depths[hash]; ok {
+		return d, nil
+	}
+
+	utxn, err := utp.txns.get(tx, hash)
+	if err != nil {
+		return 0, err
+	}
+
+	if utxn == nil {
+		return 0, blockdb.NewErrUnspentNotExist(hash.Hex())
+	}
+
+	_, d, err := utp.resolveInputs(tx, bc, utxn.Txn, depths)
+	if err != nil {
+		return 0, err
+	}
+
+	depths[hash] = d
+	return d, nil
+}
+
+// withDescendants returns hashes and the hashes of the unconfirmed transactions spending their outputs,
+// recursively
+func (utp *UnconfirmedTxnPool) withDescendants(tx *dbutil.Tx, hashes []cipher.SHA256) ([]cipher.SHA256, error) {
+	removed := make(map[cipher.SHA256]struct{}, len(hashes))
+	for _, h := range hashes {
+		removed[h] = struct{}{}
+	}
+
+	// hashes grows as descendants are found, and their outputs are followed in turn
+	for i := 0; i < len(hashes); i++ {
+		uxa, err := utp.unspent.get(tx, hashes[i])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ux := range uxa {
+			children, err := utp.spends.get(tx, ux.Hash())
+			if err != nil {
+				return nil, err
+			}
+
+			for _, h := range children {
+				if _, ok := removed[h]; ok {
+					continue
+				}
+
+				removed[h] = struct{}{}
+				hashes = append(hashes, h)
+			}
+		}
+	}
+
+	return hashes, nil
+}
+
+// GetSpendableOutputs returns the predicted outputs of valid unconfirmed transactions owned by addrs,
+// which are not spent by other unconfirmed transactions and can be spent without exceeding the maximum chain depth
+func (utp *UnconfirmedTxnPool) GetSpendableOutputs(tx *dbutil.Tx, bc Blockchainer, addrs []cipher.Address) (coin.AddressUxOuts, error) {
+	auxs := make(coin.AddressUxOuts, len(addrs))
+	if utp.cfg.MaxChainDepth == 0 {
+		return auxs, nil
+	}
+
+	addrm := make(map[cipher.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		addrm[addr] = struct{}{}
+	}
+
+	utxns, err := utp.txns.getAll(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	depths := make(map[cipher.SHA256]int)
+	for _, utxn := range utxns {
+		if utxn.IsValid != 1 {
+			continue
+		}
+
+		hash := utxn.Hash()
+		depth, err := utp.txnDepth(tx, bc, hash, depths)
+		if err != nil {
+			if _, ok := err.(blockdb.ErrUnspentNotExist); ok {
+				continue
+			}
+			return nil, err
+		}
+
+		// Spending an output of this transaction creates a transaction of depth+1
+		if depth+1 > utp.cfg.MaxChainDepth {
+			continue
+		}
+
+		uxa, err := utp.unspent.get(tx, hash)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ux := range uxa {
+			if _, ok := addrm[ux.Body.Address]; !ok {
+				continue
+			}
+
+			spenders, err := utp.spends.get(tx, ux.Hash())
+			if err != nil {
+				return nil, err
+			}
+
+			if len(spenders) != 0 {
+				continue
+			}
+
+			auxs[ux.Body.Address] = append(auxs[ux.Body.Address], ux)
+		}
+	}
+
+	return auxs, nil
+}
+
+// GetExpired returns the transactions received more than maxAge ago
+func (utp *UnconfirmedTxnPool) GetExpired(tx *dbutil.Tx, maxAge time.Duration) ([]UnconfirmedTxn, error) {
+	cutoff := utc.Now().Add(-maxAge).UnixNano()
+	return utp.GetTxns(tx, func(utxn UnconfirmedTxn) bool {
+		return utxn.Received < cutoff
+	})
+}
+
+// RemoveExpired removes the transactions received more than maxAge ago,
+// with the transactions spending their outputs.
+// The transactions that were removed are returned.
+func (utp *UnconfirmedTxnPool) RemoveExpired(tx *dbutil.Tx, maxAge time.Duration) ([]cipher.SHA256, error) {
+	expired, err := utp.GetExpired(tx, maxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	hashes := make([]cipher.SHA256, len(expired))
+	for i, utxn := range expired {
+		hashes[i] = utxn.Hash()
+	}
+
+	hashes, err = utp.withDescendants(tx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utp.RemoveTransactions(tx, hashes); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
 // Refresh checks all unconfirmed txns against the blockchain.
 // If the transaction becomes invalid it is marked invalid.
 // If the transaction becomes valid it is marked valid and is returned to the caller.
@@ -391,7 +1385,7 @@ func (utp *UnconfirmedTxnPool) Refresh(tx *dbutil.Tx, bc Blockchainer, maxBlockS
 	for _, utxn := range utxns {
 		utxn.Checked = now.UnixNano()
 
-		err := bc.VerifySingleTxnSoftHardConstraints(tx, utxn.Txn, maxBlockSize)
+		err := utp.VerifySingleTxnSoftHardConstraints(tx, bc, utxn.Txn, maxBlockSize)
 
 		switch err.(type) {
 		case ErrTxnViolatesSoftConstraint, ErrTxnViolatesHardConstraint:
@@ -414,7 +1408,8 @@ func (utp *UnconfirmedTxnPool) Refresh(tx *dbutil.Tx, bc Blockchainer, maxBlockS
 }
 
 // RemoveInvalid checks all unconfirmed txns against the blockchain.
-// If a transaction violates hard constraints it is removed from the pool.
+// If a transaction violates hard constraints it is removed from the pool,
+// with the transactions spending its outputs.
 // The transactions that were removed are returned.
 func (utp *UnconfirmedTxnPool) RemoveInvalid(tx *dbutil.Tx, bc Blockchainer) ([]cipher.SHA256, error) {
 	var removeUtxns []cipher.SHA256
@@ -425,7 +1420,7 @@ func (utp *UnconfirmedTxnPool) RemoveInvalid(tx *dbutil.Tx, bc Blockchainer) ([]
 	}
 
 	for _, utxn := range utxns {
-		err := bc.VerifySingleTxnHardConstraints(tx, utxn.Txn)
+		err := utp.verifySingleTxnHardConstraints(tx, bc, utxn.Txn)
 		if err != nil {
 			switch err.(type) {
 			case ErrTxnViolatesHardConstraint:
@@ -436,6 +1431,15 @@ func (utp *UnconfirmedTxnPool) RemoveInvalid(tx *dbutil.Tx, bc Blockchainer) ([]
 		}
 	}
 
+	if len(removeUtxns) == 0 {
+		return nil, nil
+	}
+
+	removeUtxns, err = utp.withDescendants(tx, removeUtxns)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := utp.RemoveTransactions(tx, removeUtxns); err != nil {
 		return nil, err
 	}
@@ -544,6 +1548,11 @@ func (utp *UnconfirmedTxnPool) GetIncomingOutputs(tx *dbutil.Tx, bh coin.BlockHe
 	return outs, nil
 }
 
+// GetUnconfirmedOutputs returns the predicted outputs of all unconfirmed transactions, indexed by their hash
+func (utp *UnconfirmedTxnPool) GetUnconfirmedOutputs(tx *dbutil.Tx) (map[cipher.SHA256]coin.UxOut, error) {
+	return utp.unconfirmedOutputs(tx)
+}
+
 // Get returns the unconfirmed transaction of given tx hash.
 func (utp *UnconfirmedTxnPool) Get(tx *dbutil.Tx, hash cipher.SHA256) (*UnconfirmedTxn, error) {
 	return utp.txns.get(tx, hash)
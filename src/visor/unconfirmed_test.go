@@ -0,0 +1,619 @@
+package visor
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/utc"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+// setupUnconfirmedPoolVisor creates a visor whose unconfirmed pool uses cfg, with a genesis block
+// and a second block splitting the genesis output into n outputs owned by the returned address
+func setupUnconfirmedPoolVisor(t *testing.T, cfg UnconfirmedTxnPoolConfig, n int) (*Visor, cipher.Address, cipher.SecKey, func()) {
+	db, shutdown := prepareDB(t)
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTxnPool(db, cfg)
+	require.NoError(t, err)
+
+	vcfg := NewVisorConfig()
+	vcfg.DBPath = db.Path()
+	vcfg.IsMaster = true
+	vcfg.BlockchainSeckey = genSecret
+	vcfg.BlockchainPubkey = genPublic
+	vcfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      vcfg,
+		Unconfirmed: unconfirmed,
+		Blockchain:  bc,
+		DB:          db,
+		history:     historydb.New(),
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+	genUx := coin.UxOut{
+		Head: coin.UxHead{
+			Time:  gb.Time(),
+			BkSeq: gb.Seq(),
+		},
+		Body: coin.UxBody{
+			SrcTransaction: gb.Body.Transactions[0].InnerHash,
+			Address:        genAddress,
+			Coins:          gb.Body.Transactions[0].Out[0].Coins,
+			Hours:          gb.Body.Transactions[0].Out[0].Hours,
+		},
+	}
+
+	pub, sec := cipher.GenerateKeyPair()
+	addr := cipher.AddressFromPubKey(pub)
+
+	when := genTime + 100
+	hours, err := genUx.CoinHours(when)
+	require.NoError(t, err)
+
+	txn := coin.Transaction{}
+	txn.PushInput(genUx.Hash())
+	for i := 0; i < n; i++ {
+		// Vary the hours, duplicate outputs are not allowed
+		txn.PushOutput(addr, 10e6, hours/uint64(4*n)+uint64(i))
+	}
+	txn.PushOutput(genAddress, genUx.Body.Coins-uint64(n)*10e6, hours/4)
+	txn.SignInputs([]cipher.SecKey{genSecret})
+	txn.UpdateHeader()
+
+	var uxHash cipher.SHA256
+	err = db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		uxHash, err = v.Blockchain.Unspent().GetUxHash(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	b, err := coin.NewBlock(gb.Block, when, uxHash, coin.Transactions{txn}, func(t *coin.Transaction) (uint64, error) {
+		return 0, nil
+	})
+	require.NoError(t, err)
+
+	err = v.ExecuteSignedBlock(signBlock(t, v, *b))
+	require.NoError(t, err)
+
+	return v, addr, sec, shutdown
+}
+
+func getAddrUxOuts(t *testing.T, v *Visor, addr cipher.Address) coin.UxArray {
+	var uxs coin.UxArray
+	err := v.DB.View("", func(tx *dbutil.Tx) error {
+		auxs, err := v.Blockchain.Unspent().GetUnspentsOfAddrs(tx, []cipher.Address{addr})
+		if err != nil {
+			return err
+		}
+		uxs = auxs[addr]
+		return nil
+	})
+	require.NoError(t, err)
+	return uxs
+}
+
+// makeFeeTxn spends ux to a new address, burning all but outHours of the output's coin hours
+func makeFeeTxn(t *testing.T, v *Visor, ux coin.UxOut, sec cipher.SecKey, outHours uint64) (coin.Transaction, uint64) {
+	var headTime uint64
+	err := v.DB.View("", func(tx *dbutil.Tx) error {
+		var err error
+		headTime, err = v.Blockchain.Time(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	hours, err := ux.CoinHours(headTime)
+	require.NoError(t, err)
+	require.True(t, outHours <= hours/2)
+
+	txn := coin.Transaction{}
+	txn.PushInput(ux.Hash())
+	txn.PushOutput(cipher.AddressFromPubKey(cipher.PubKeyFromSecKey(sec)), ux.Body.Coins, outHours)
+	txn.SignInputs([]cipher.SecKey{sec})
+	txn.UpdateHeader()
+
+	return txn, hours - outHours
+}
+
+func getUnconfirmedHashes(t *testing.T, v *Visor) map[cipher.SHA256]struct{} {
+	txns, err := v.GetAllUnconfirmedTxns()
+	require.NoError(t, err)
+
+	hashes := make(map[cipher.SHA256]struct{}, len(txns))
+	for _, txn := range txns {
+		hashes[txn.Hash()] = struct{}{}
+	}
+	return hashes
+}
+
+func TestUnconfirmedTxnPoolEviction(t *testing.T) {
+	// All transactions created by makeFeeTxn have the same size
+	size := (&coin.Transaction{
+		In:   make([]cipher.SHA256, 1),
+		Sigs: make([]cipher.Sig, 1),
+		Out:  make([]coin.TransactionOutput, 1),
+	}).Size()
+
+	v, addr, sec, shutdown := setupUnconfirmedPoolVisor(t, UnconfirmedTxnPoolConfig{
+		MaxSize: 2 * size,
+	}, 4)
+	defer shutdown()
+
+	uxs := getAddrUxOuts(t, v, addr)
+	require.Len(t, uxs, 4)
+
+	// Burn more coin hours for each next output
+	txns := make([]coin.Transaction, len(uxs))
+	for i, ux := range uxs {
+		txns[i], _ = makeFeeTxn(t, v, ux, sec, 100-uint64(i)*10)
+		require.Equal(t, size, txns[i].Size())
+	}
+
+	// The pool holds two transactions
+	for _, txn := range txns[1:3] {
+		_, softErr, err := v.InjectTransaction(txn)
+		require.NoError(t, err)
+		require.Nil(t, softErr)
+	}
+
+	stats, err := v.GetUnconfirmedTxnPoolStats()
+	require.NoError(t, err)
+	require.Equal(t, UnconfirmedTxnPoolStats{
+		Count:   2,
+		Size:    uint64(2 * size),
+		MaxSize: uint64(2 * size),
+	}, *stats)
+
+	// A transaction with a lower fee than everything in the full pool is rejected
+	_, _, err = v.InjectTransaction(txns[0])
+	require.Equal(t, ErrTxnPoolFull, err)
+	require.Equal(t, map[cipher.SHA256]struct{}{
+		txns[1].Hash(): {},
+		txns[2].Hash(): {},
+	}, getUnconfirmedHashes(t, v))
+
+	// The rolled back transaction is not counted
+	fees := v.Unconfirmed.(*UnconfirmedTxnPool).fees
+	require.False(t, fees.isLoaded())
+	stats, err = v.GetUnconfirmedTxnPoolStats()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2*size), stats.Size)
+
+	// A transaction with a higher fee evicts the lowest fee transaction
+	_, _, err = v.InjectTransaction(txns[3])
+	require.NoError(t, err)
+	require.Equal(t, map[cipher.SHA256]struct{}{
+		txns[2].Hash(): {},
+		txns[3].Hash(): {},
+	}, getUnconfirmedHashes(t, v))
+
+	stats, err = v.GetUnconfirmedTxnPoolStats()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), stats.Count)
+	require.Equal(t, uint64(2*size), stats.Size)
+	require.Equal(t, uint64(1), stats.Evicted)
+	require.Equal(t, uint64(0), stats.Replaced)
+
+	// The index is kept up to date with the pool
+	require.True(t, fees.isLoaded())
+	require.Equal(t, 2*size, fees.totalSize())
+	require.Equal(t, []cipher.SHA256{txns[2].Hash()}, fees.evictable(size))
+}
+
+func TestFeeRateIndex(t *testing.T) {
+	txns := make(coin.Transactions, 4)
+	fees := make(map[cipher.SHA256]uint64, len(txns))
+	for i := range txns {
+		txns[i] = coin.Transaction{
+			In:   []cipher.SHA256{cipher.SumSHA256([]byte{byte(i)})},
+			Sigs: make([]cipher.Sig, 1),
+			Out:  make([]coin.TransactionOutput, 1),
+		}
+		txns[i].UpdateHeader()
+	}
+	size := txns[0].Size()
+
+	// txns[0] has no fee, txns[1] and txns[2] have the same fee
+	fees[txns[1].Hash()] = 10
+	fees[txns[2].Hash()] = 10
+	fees[txns[3].Hash()] = 5
+	feeCalc := func(txn *coin.Transaction) (uint64, error) {
+		f, ok := fees[txn.Hash()]
+		if !ok {
+			return 0, errors.New("no fee")
+		}
+		return f, nil
+	}
+
+	// Ties are evicted by hash descending
+	high, low := txns[1].Hash(), txns[2].Hash()
+	if bytes.Compare(high[:], low[:]) < 0 {
+		high, low = low, high
+	}
+
+	var idx feeRateIndex
+
+	// The index is not updated until it is loaded
+	idx.add(&txns[0], feeCalc)
+	require.False(t, idx.isLoaded())
+	require.Equal(t, 0, idx.totalSize())
+
+	idx.load(txns[:3], feeCalc)
+	require.True(t, idx.isLoaded())
+	require.Equal(t, 3*size, idx.totalSize())
+	require.Equal(t, []cipher.SHA256{txns[0].Hash(), high, low}, idx.evictable(0))
+
+	idx.add(&txns[3], feeCalc)
+	idx.add(&txns[3], feeCalc)
+	require.Equal(t, 4*size, idx.totalSize())
+	require.Equal(t, []cipher.SHA256{txns[0].Hash(), txns[3].Hash(), high, low}, idx.evictable(0))
+	require.Equal(t, []cipher.SHA256{txns[0].Hash(), txns[3].Hash()}, idx.evictable(2*size))
+	require.Empty(t, idx.evictable(4*size))
+
+	idx.remove(high)
+	idx.remove(high)
+	require.Equal(t, 3*size, idx.totalSize())
+	require.Equal(t, []cipher.SHA256{txns[0].Hash(), txns[3].Hash(), low}, idx.evictable(0))
+
+	idx.reset()
+	require.False(t, idx.isLoaded())
+	require.Equal(t, 0, idx.totalSize())
+}
+
+func TestUnconfirmedTxnPoolReplaceByFee(t *testing.T) {
+	v, addr, sec, shutdown := setupUnconfirmedPoolVisor(t, UnconfirmedTxnPoolConfig{}, 2)
+	defer shutdown()
+
+	uxs := getAddrUxOuts(t, v, addr)
+	require.Len(t, uxs, 2)
+
+	txn, fee := makeFeeTxn(t, v, uxs[0], sec, 100)
+	_, _, err := v.InjectTransaction(txn)
+	require.NoError(t, err)
+
+	other, _ := makeFeeTxn(t, v, uxs[1], sec, 100)
+	_, _, err = v.InjectTransaction(other)
+	require.NoError(t, err)
+
+	// A double spend that burns fewer coin hours is rejected
+	lower, lowerFee := makeFeeTxn(t, v, uxs[0], sec, 110)
+	require.True(t, lowerFee < fee)
+	_, _, err = v.InjectTransaction(lower)
+	require.Equal(t, ErrTxnReplacementFeeTooLow, err)
+
+	// A double spend that burns more coin hours replaces the original transaction
+	higher, higherFee := makeFeeTxn(t, v, uxs[0], sec, 90)
+	require.True(t, higherFee > fee)
+	_, _, err = v.InjectTransaction(higher)
+	require.NoError(t, err)
+
+	require.Equal(t, map[cipher.SHA256]struct{}{
+		higher.Hash(): {},
+		other.Hash():  {},
+	}, getUnconfirmedHashes(t, v))
+
+	// The replaced transaction's predicted unspent outputs are removed
+	err = v.DB.View("", func(tx *dbutil.Tx) error {
+		uxs, err := v.Unconfirmed.GetUnspentsOfAddr(tx, addr)
+		require.NoError(t, err)
+		require.Len(t, uxs, 2)
+		for _, ux := range uxs {
+			require.NotEqual(t, txn.Hash(), ux.Body.SrcTransaction)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	stats, err := v.GetUnconfirmedTxnPoolStats()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), stats.Count)
+	require.Equal(t, uint64(1), stats.Replaced)
+	require.Equal(t, uint64(0), stats.Evicted)
+	require.Equal(t, uint64(0), stats.MaxSize)
+}
+
+func TestUnconfirmedTxnPoolReplaceByFeeIncrement(t *testing.T) {
+	v, addr, sec, shutdown := setupUnconfirmedPoolVisor(t, UnconfirmedTxnPoolConfig{
+		MinReplacementFeeKB: 100,
+	}, 1)
+	defer shutdown()
+
+	uxs := getAddrUxOuts(t, v, addr)
+	require.Len(t, uxs, 1)
+
+	txn, fee := makeFeeTxn(t, v, uxs[0], sec, 200)
+	_, _, err := v.InjectTransaction(txn)
+	require.NoError(t, err)
+
+	// The replacement must burn at least 100 coin hours per kB of its size more than the transaction it replaces
+	minIncrement := (100*uint64(txn.Size()) + 1023) / 1024
+	require.True(t, minIncrement > 1)
+
+	lower, lowerFee := makeFeeTxn(t, v, uxs[0], sec, 200-minIncrement+1)
+	require.Equal(t, txn.Size(), lower.Size())
+	require.True(t, lowerFee > fee)
+	_, _, err = v.InjectTransaction(lower)
+	require.Equal(t, ErrTxnReplacementFeeTooLow, err)
+
+	higher, higherFee := makeFeeTxn(t, v, uxs[0], sec, 200-minIncrement)
+	require.Equal(t, fee+minIncrement, higherFee)
+	_, _, err = v.InjectTransaction(higher)
+	require.NoError(t, err)
+
+	require.Equal(t, map[cipher.SHA256]struct{}{
+		higher.Hash(): {},
+	}, getUnconfirmedHashes(t, v))
+}
+
+// getUnconfirmedOutput returns the predicted output of the unconfirmed transaction txn
+func getUnconfirmedOutput(t *testing.T, v *Visor, txn coin.Transaction) coin.UxOut {
+	var uxa coin.UxArray
+	err := v.DB.View("", func(tx *dbutil.Tx) error {
+		var err error
+		uxa, err = v.Unconfirmed.GetUnspentsOfAddr(tx, txn.Out[0].Address)
+		return err
+	})
+	require.NoError(t, err)
+
+	for _, ux := range uxa {
+		if ux.Body.SrcTransaction == txn.Hash() {
+			return ux
+		}
+	}
+
+	t.Fatalf("output of unconfirmed transaction %s not found", txn.Hash().Hex())
+	return coin.UxOut{}
+}
+
+func getSpendableOutputs(t *testing.T, v *Visor, addr cipher.Address) coin.UxArray {
+	var auxs coin.AddressUxOuts
+	err := v.DB.View("", func(tx *dbutil.Tx) error {
+		var err error
+		auxs, err = v.Unconfirmed.GetSpendableOutputs(tx, v.Blockchain, []cipher.Address{addr})
+		return err
+	})
+	require.NoError(t, err)
+	return auxs[addr]
+}
+
+func TestUnconfirmedTxnChain(t *testing.T) {
+	v, addr, sec, shutdown := setupUnconfirmedPoolVisor(t, UnconfirmedTxnPoolConfig{
+		MaxChainDepth: 2,
+	}, 1)
+	defer shutdown()
+
+	uxs := getAddrUxOuts(t, v, addr)
+	require.Len(t, uxs, 1)
+
+	// Nothing is spendable while the pool is empty
+	require.Empty(t, getSpendableOutputs(t, v, addr))
+
+	txn1, fee1 := makeFeeTxn(t, v, uxs[0], sec, 400)
+	_, softErr, err := v.InjectTransaction(txn1)
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+
+	// The output of txn1 can be spent by a transaction of depth 1
+	spendable := getSpendableOutputs(t, v, addr)
+	require.Len(t, spendable, 1)
+	require.Equal(t, txn1.Hash(), spendable[0].Body.SrcTransaction)
+
+	txn2, _ := makeFeeTxn(t, v, getUnconfirmedOutput(t, v, txn1), sec, 200)
+	_, softErr, err = v.InjectTransaction(txn2)
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+
+	// The output of txn2 can be spent by a transaction of depth 2
+	spendable = getSpendableOutputs(t, v, addr)
+	require.Len(t, spendable, 1)
+	require.Equal(t, txn2.Hash(), spendable[0].Body.SrcTransaction)
+
+	txn3, _ := makeFeeTxn(t, v, getUnconfirmedOutput(t, v, txn2), sec, 100)
+	_, softErr, err = v.InjectTransaction(txn3)
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+
+	// Spending the output of txn3 would exceed the max chain depth
+	require.Empty(t, getSpendableOutputs(t, v, addr))
+
+	txn4, _ := makeFeeTxn(t, v, getUnconfirmedOutput(t, v, txn3), sec, 50)
+	_, err = v.InjectTransactionStrict(txn4)
+	require.Equal(t, NewErrTxnViolatesSoftConstraint(errTxnChainTooDeep), err)
+
+	_, softErr, err = v.InjectTransaction(txn4)
+	require.NoError(t, err)
+	require.NotNil(t, softErr)
+	require.Equal(t, errTxnChainTooDeep, softErr.Err)
+
+	// A transaction spending an output that does not exist violates hard constraints
+	bad, _ := makeFeeTxn(t, v, getUnconfirmedOutput(t, v, txn3), sec, 50)
+	bad.In[0] = txn4.Hash()
+	bad.Sigs = nil
+	bad.SignInputs([]cipher.SecKey{sec})
+	bad.UpdateHeader()
+	_, _, err = v.InjectTransaction(bad)
+	require.Error(t, err)
+	require.IsType(t, ErrTxnViolatesHardConstraint{}, err)
+
+	require.Equal(t, map[cipher.SHA256]struct{}{
+		txn1.Hash(): {},
+		txn2.Hash(): {},
+		txn3.Hash(): {},
+		txn4.Hash(): {},
+	}, getUnconfirmedHashes(t, v))
+
+	// The indexes of a pool created before they were added are built when the pool is created
+	spends, outputs := getUnconfirmedIndexes(t, v)
+	require.Len(t, spends, 4)
+	require.Len(t, outputs, 4)
+
+	err = v.DB.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, dbutil.Reset(tx, UnconfirmedSpendsBkt))
+		return dbutil.Reset(tx, UnconfirmedOutputsBkt)
+	})
+	require.NoError(t, err)
+
+	_, err = NewUnconfirmedTxnPool(v.DB, UnconfirmedTxnPoolConfig{})
+	require.NoError(t, err)
+
+	rebuiltSpends, rebuiltOutputs := getUnconfirmedIndexes(t, v)
+	require.Equal(t, spends, rebuiltSpends)
+	require.Equal(t, outputs, rebuiltOutputs)
+
+	// Replacing txn1 removes the transactions spending its outputs
+	higher, higherFee := makeFeeTxn(t, v, uxs[0], sec, 300)
+	require.True(t, higherFee > fee1)
+	_, _, err = v.InjectTransaction(higher)
+	require.NoError(t, err)
+
+	require.Equal(t, map[cipher.SHA256]struct{}{
+		higher.Hash(): {},
+	}, getUnconfirmedHashes(t, v))
+
+	// The removed transactions are removed from the indexes
+	spends, outputs = getUnconfirmedIndexes(t, v)
+	require.Equal(t, map[string][]byte{
+		uxs[0].Hash().Hex(): encoder.Serialize([]cipher.SHA256{higher.Hash()}),
+	}, spends)
+	require.Len(t, outputs, 1)
+	for _, b := range outputs {
+		var ux coin.UxOut
+		require.NoError(t, encoder.DeserializeRaw(b, &ux))
+		require.Equal(t, higher.Hash(), ux.Body.SrcTransaction)
+	}
+}
+
+// getUnconfirmedIndexes returns the contents of the spends and outputs indexes of the unconfirmed pool
+func getUnconfirmedIndexes(t *testing.T, v *Visor) (map[string][]byte, map[string][]byte) {
+	spends := make(map[string][]byte)
+	outputs := make(map[string][]byte)
+	err := v.DB.View("", func(tx *dbutil.Tx) error {
+		if err := dbutil.ForEach(tx, UnconfirmedSpendsBkt, func(k, v []byte) error {
+			spends[string(k)] = append([]byte{}, v...)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return dbutil.ForEach(tx, UnconfirmedOutputsBkt, func(k, v []byte) error {
+			outputs[string(k)] = append([]byte{}, v...)
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	return spends, outputs
+}
+
+func TestUnconfirmedTxnChainDisabled(t *testing.T) {
+	v, addr, sec, shutdown := setupUnconfirmedPoolVisor(t, UnconfirmedTxnPoolConfig{}, 1)
+	defer shutdown()
+
+	uxs := getAddrUxOuts(t, v, addr)
+	require.Len(t, uxs, 1)
+
+	txn1, _ := makeFeeTxn(t, v, uxs[0], sec, 400)
+	_, softErr, err := v.InjectTransaction(txn1)
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+
+	require.Empty(t, getSpendableOutputs(t, v, addr))
+
+	// Spending an unconfirmed output is rejected, as if the pool did not resolve unconfirmed inputs
+	txn2, _ := makeFeeTxn(t, v, getUnconfirmedOutput(t, v, txn1), sec, 200)
+	_, err = v.InjectTransactionStrict(txn2)
+	require.True(t, isErrUnspentNotExist(err), "%v", err)
+
+	_, softErr, err = v.InjectTransaction(txn2)
+	require.True(t, isErrUnspentNotExist(err), "%v", err)
+	require.Nil(t, softErr)
+	require.Equal(t, map[cipher.SHA256]struct{}{
+		txn1.Hash(): {},
+	}, getUnconfirmedHashes(t, v))
+}
+
+func TestUnconfirmedTxnExpiry(t *testing.T) {
+	v, addr, sec, shutdown := setupUnconfirmedPoolVisor(t, UnconfirmedTxnPoolConfig{
+		MaxChainDepth: 1,
+	}, 2)
+	defer shutdown()
+
+	v.Config.UnconfirmedTxnsMaxAge = 0
+
+	uxs := getAddrUxOuts(t, v, addr)
+	require.Len(t, uxs, 2)
+
+	old, _ := makeFeeTxn(t, v, uxs[0], sec, 100)
+	_, _, err := v.InjectTransaction(old)
+	require.NoError(t, err)
+
+	child, _ := makeFeeTxn(t, v, getUnconfirmedOutput(t, v, old), sec, 50)
+	_, _, err = v.InjectTransaction(child)
+	require.NoError(t, err)
+
+	recent, _ := makeFeeTxn(t, v, uxs[1], sec, 100)
+	_, _, err = v.InjectTransaction(recent)
+	require.NoError(t, err)
+
+	// Make the first transaction two hours old
+	err = v.DB.Update("", func(tx *dbutil.Tx) error {
+		utxn, err := v.Unconfirmed.Get(tx, old.Hash())
+		require.NoError(t, err)
+		require.NotNil(t, utxn)
+		utxn.Received = utc.Now().Add(-2 * time.Hour).UnixNano()
+		return v.Unconfirmed.(*UnconfirmedTxnPool).txns.put(tx, utxn)
+	})
+	require.NoError(t, err)
+
+	// Expiry is disabled by config, a max age must be given
+	_, err = v.GetExpiredUnconfirmedTxns(0)
+	require.Equal(t, ErrUnconfirmedExpiryDisabled, err)
+	_, err = v.RemoveExpiredUnconfirmed(0)
+	require.Equal(t, ErrUnconfirmedExpiryDisabled, err)
+
+	expired, err := v.GetExpiredUnconfirmedTxns(3 * time.Hour)
+	require.NoError(t, err)
+	require.Empty(t, expired)
+
+	v.Config.UnconfirmedTxnsMaxAge = time.Hour
+
+	expired, err = v.GetExpiredUnconfirmedTxns(0)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	require.Equal(t, old.Hash(), expired[0].Hash())
+
+	// The expired transaction is removed with the transaction spending its output
+	removed, err := v.RemoveExpiredUnconfirmed(0)
+	require.NoError(t, err)
+	require.Len(t, removed, 2)
+	require.Equal(t, map[cipher.SHA256]struct{}{
+		old.Hash():   {},
+		child.Hash(): {},
+	}, map[cipher.SHA256]struct{}{
+		removed[0]: {},
+		removed[1]: {},
+	})
+
+	require.Equal(t, map[cipher.SHA256]struct{}{
+		recent.Hash(): {},
+	}, getUnconfirmedHashes(t, v))
+
+	removed, err = v.RemoveExpiredUnconfirmed(0)
+	require.NoError(t, err)
+	require.Empty(t, removed)
+}
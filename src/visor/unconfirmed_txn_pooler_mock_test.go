@@ -7,6 +7,7 @@ package visor
 
 import (
 	"fmt"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -69,6 +70,60 @@ func (m *UnconfirmedTxnPoolerMock) Get(p0 *dbutil.Tx, p1 cipher.SHA256) (*Unconf
 
 }
 
+// GetExpired mocked method
+func (m *UnconfirmedTxnPoolerMock) GetExpired(p0 *dbutil.Tx, p1 time.Duration) ([]UnconfirmedTxn, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 []UnconfirmedTxn
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []UnconfirmedTxn:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetFees mocked method
+func (m *UnconfirmedTxnPoolerMock) GetFees(p0 *dbutil.Tx, p1 Blockchainer) ([]UnconfirmedTxnFee, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 []UnconfirmedTxnFee
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []UnconfirmedTxnFee:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetIncomingOutputs mocked method
 func (m *UnconfirmedTxnPoolerMock) GetIncomingOutputs(p0 *dbutil.Tx, p1 coin.BlockHeader) (coin.UxArray, error) {
 
@@ -123,6 +178,33 @@ func (m *UnconfirmedTxnPoolerMock) GetKnown(p0 *dbutil.Tx, p1 []cipher.SHA256) (
 
 }
 
+// GetSpendableOutputs mocked method
+func (m *UnconfirmedTxnPoolerMock) GetSpendableOutputs(p0 *dbutil.Tx, p1 Blockchainer, p2 []cipher.Address) (coin.AddressUxOuts, error) {
+
+	ret := m.Called(p0, p1, p2)
+
+	var r0 coin.AddressUxOuts
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case coin.AddressUxOuts:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetTxHashes mocked method
 func (m *UnconfirmedTxnPoolerMock) GetTxHashes(p0 *dbutil.Tx, p1 func(tx UnconfirmedTxn) bool) ([]cipher.SHA256, error) {
 
@@ -177,6 +259,33 @@ func (m *UnconfirmedTxnPoolerMock) GetTxns(p0 *dbutil.Tx, p1 func(tx Unconfirmed
 
 }
 
+// GetUnconfirmedOutputs mocked method
+func (m *UnconfirmedTxnPoolerMock) GetUnconfirmedOutputs(p0 *dbutil.Tx) (map[cipher.SHA256]coin.UxOut, error) {
+
+	ret := m.Called(p0)
+
+	var r0 map[cipher.SHA256]coin.UxOut
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case map[cipher.SHA256]coin.UxOut:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetUnknown mocked method
 func (m *UnconfirmedTxnPoolerMock) GetUnknown(p0 *dbutil.Tx, p1 []cipher.SHA256) ([]cipher.SHA256, error) {
 
@@ -375,6 +484,33 @@ func (m *UnconfirmedTxnPoolerMock) Refresh(p0 *dbutil.Tx, p1 Blockchainer, p2 in
 
 }
 
+// RemoveExpired mocked method
+func (m *UnconfirmedTxnPoolerMock) RemoveExpired(p0 *dbutil.Tx, p1 time.Duration) ([]cipher.SHA256, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 []cipher.SHA256
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []cipher.SHA256:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // RemoveInvalid mocked method
 func (m *UnconfirmedTxnPoolerMock) RemoveInvalid(p0 *dbutil.Tx, p1 Blockchainer) ([]cipher.SHA256, error) {
 
@@ -437,3 +573,48 @@ func (m *UnconfirmedTxnPoolerMock) SetTxnsAnnounced(p0 *dbutil.Tx, p1 map[cipher
 	return r0
 
 }
+
+// Stats mocked method
+func (m *UnconfirmedTxnPoolerMock) Stats(p0 *dbutil.Tx) (*UnconfirmedTxnPoolStats, error) {
+
+	ret := m.Called(p0)
+
+	var r0 *UnconfirmedTxnPoolStats
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case *UnconfirmedTxnPoolStats:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// VerifySingleTxnSoftHardConstraints mocked method
+func (m *UnconfirmedTxnPoolerMock) VerifySingleTxnSoftHardConstraints(p0 *dbutil.Tx, p1 Blockchainer, p2 coin.Transaction, p3 int) error {
+
+	ret := m.Called(p0, p1, p2, p3)
+
+	var r0 error
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case error:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0
+
+}
@@ -12,6 +12,7 @@ import (
 
 	cipher "github.com/skycoin/skycoin/src/cipher"
 	coin "github.com/skycoin/skycoin/src/coin"
+	blockdb "github.com/skycoin/skycoin/src/visor/blockdb"
 	dbutil "github.com/skycoin/skycoin/src/visor/dbutil"
 )
 
@@ -78,6 +79,24 @@ func (m *UnspentPoolerMock) Contains(p0 *dbutil.Tx, p1 cipher.SHA256) (bool, err
 
 }
 
+// ForEachAddressBalance mocked method
+func (m *UnspentPoolerMock) ForEachAddressBalance(p0 *dbutil.Tx, p1 func(cipher.Address, blockdb.AddressBalance) error) error {
+
+	ret := m.Called(p0, p1)
+
+	var r0 error
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case error:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0
+
+}
+
 // Get mocked method
 func (m *UnspentPoolerMock) Get(p0 *dbutil.Tx, p1 cipher.SHA256) (*coin.UxOut, error) {
 
@@ -105,6 +124,33 @@ func (m *UnspentPoolerMock) Get(p0 *dbutil.Tx, p1 cipher.SHA256) (*coin.UxOut, e
 
 }
 
+// GetAddressBalances mocked method
+func (m *UnspentPoolerMock) GetAddressBalances(p0 *dbutil.Tx, p1 []cipher.Address) (map[cipher.Address]blockdb.AddressBalance, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 map[cipher.Address]blockdb.AddressBalance
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case map[cipher.Address]blockdb.AddressBalance:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetAll mocked method
 func (m *UnspentPoolerMock) GetAll(p0 *dbutil.Tx) (coin.UxArray, error) {
 
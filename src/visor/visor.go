@@ -3,7 +3,9 @@ package visor
 import (
 	"errors"
 	"fmt"
+	"math"
 	"sort"
+	"sync/atomic"
 
 	"time"
 
@@ -19,6 +21,7 @@ import (
 	"github.com/skycoin/skycoin/src/wallet"
 
 	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/util/metrics"
 )
 
 var (
@@ -26,10 +29,16 @@ var (
 
 	// errInvalidDecimals is returned by DropletPrecisionCheck if a coin amount has an invalid number of decimal places
 	errInvalidDecimals = errors.New("invalid amount, too many decimal places")
+	// ErrUnconfirmedExpiryDisabled is returned when looking for expired unconfirmed transactions without a max age,
+	// if unconfirmed transactions do not expire
+	ErrUnconfirmedExpiryDisabled = errors.New("unconfirmed transactions do not expire, a max age is required")
 
 	// maxDropletDivisor represents the modulus divisor when checking droplet precision rules.
 	// It is computed from MaxDropletPrecision in init()
 	maxDropletDivisor uint64
+
+	blockExecutionDuration = metrics.NewHistogram("skycoin_block_execution_duration_seconds",
+		"Time spent executing signed blocks, including waiting for the database", metrics.DefaultDurationBuckets)
 )
 
 // MaxDropletDivisor represents the modulus divisor when checking droplet precision rules.
@@ -97,8 +106,34 @@ type Config struct {
 	//Secret key of blockchain authority (if master)
 	BlockchainSeckey cipher.SecKey
 
+	// Other public keys of the blockchain authority, that can sign blocks until the first key rotation
+	BlockchainSignerPubkeys []cipher.PubKey
+	// Other secret keys of the blockchain authority (if master). The master signs blocks with
+	// the first of BlockchainSeckey and BlockchainSignerSeckeys that can sign at the block's seq.
+	BlockchainSignerSeckeys []cipher.SecKey
+	// Hashes of the key rotations trusted by the operator. Used to resolve conflicting key rotations
+	// signed by the same blockchain authority, see Blockchain.AddKeyRotation
+	TrustedKeyRotations []cipher.SHA256
+
+	// Public keys of the block makers. If set, blocks are proposed by the block makers
+	// and executed once a quorum of them signed the block, instead of being created by a master.
+	// Blocks signed by BlockchainPubkey, or by a block maker along with a quorum of block makers, are valid.
+	BlockMakerPubkeys []cipher.PubKey
+	// Number of block maker signatures needed to execute a block
+	BlockMakerQuorum int
+	// Secret key of this node's block maker (if block maker)
+	BlockMakerSeckey cipher.SecKey
+
 	// Maximum size of a block, in bytes.
 	MaxBlockSize int
+	// Maximum total size of the unconfirmed transaction pool, in bytes. 0 means no limit.
+	MaxUnconfirmedTxnsSize int
+	// Maximum number of unconfirmed ancestors of an unconfirmed transaction. 0 disables spending unconfirmed outputs.
+	MaxUnconfirmedChainDepth int
+	// Fee per kB that a transaction replacing unconfirmed transactions must burn in addition to their fees
+	MinReplacementFeeKB uint64
+	// Unconfirmed transactions received longer ago than this are removed from the pool. 0 means they never expire.
+	UnconfirmedTxnsMaxAge time.Duration
 
 	// Where the blockchain is saved
 	BlockchainFile string
@@ -139,7 +174,11 @@ func NewVisorConfig() Config {
 		BlockchainPubkey: cipher.PubKey{},
 		BlockchainSeckey: cipher.SecKey{},
 
-		MaxBlockSize: DefaultMaxBlockSize,
+		MaxBlockSize:             DefaultMaxBlockSize,
+		MaxUnconfirmedTxnsSize:   DefaultMaxUnconfirmedTxnsSize,
+		MaxUnconfirmedChainDepth: DefaultMaxUnconfirmedChainDepth,
+		MinReplacementFeeKB:      DefaultMinReplacementFeeKB,
+		UnconfirmedTxnsMaxAge:    DefaultUnconfirmedTxnsMaxAge,
 
 		GenesisAddress:    cipher.Address{},
 		GenesisSignature:  cipher.Sig{},
@@ -153,14 +192,87 @@ func NewVisorConfig() Config {
 // Verify verifies the configuration
 func (c Config) Verify() error {
 	if c.IsMaster {
-		if c.BlockchainPubkey != cipher.PubKeyFromSecKey(c.BlockchainSeckey) {
-			return errors.New("Cannot run in master: invalid seckey for pubkey")
+		// A master that only has signer seckeys, e.g. after rotating away from a lost key, doesn't need BlockchainSeckey
+		if c.BlockchainSeckey != (cipher.SecKey{}) || len(c.BlockchainSignerSeckeys) == 0 {
+			if c.BlockchainPubkey != cipher.PubKeyFromSecKey(c.BlockchainSeckey) {
+				return errors.New("Cannot run in master: invalid seckey for pubkey")
+			}
+		}
+
+		for _, sk := range c.BlockchainSignerSeckeys {
+			if err := sk.Verify(); err != nil {
+				return fmt.Errorf("Cannot run in master: invalid signer seckey: %v", err)
+			}
+		}
+	}
+
+	for _, pk := range c.BlockchainSignerPubkeys {
+		if err := pk.Verify(); err != nil {
+			return fmt.Errorf("Invalid blockchain signer pubkey %s: %v", pk.Hex(), err)
+		}
+	}
+
+	if len(c.BlockMakerPubkeys) == 0 {
+		if c.BlockMakerSeckey != (cipher.SecKey{}) {
+			return errors.New("Cannot run as block maker without block maker pubkeys")
+		}
+		return nil
+	}
+
+	if c.IsMaster {
+		return errors.New("Cannot run in master with block maker pubkeys")
+	}
+
+	makers := make(map[cipher.PubKey]struct{}, len(c.BlockMakerPubkeys))
+	for _, pk := range c.BlockMakerPubkeys {
+		if err := pk.Verify(); err != nil {
+			return fmt.Errorf("Invalid block maker pubkey %s: %v", pk.Hex(), err)
+		}
+		if _, ok := makers[pk]; ok {
+			return fmt.Errorf("Duplicate block maker pubkey %s", pk.Hex())
+		}
+		makers[pk] = struct{}{}
+	}
+
+	if c.BlockMakerQuorum < 1 || c.BlockMakerQuorum > len(c.BlockMakerPubkeys) {
+		return fmt.Errorf("Block maker quorum must be between 1 and %d", len(c.BlockMakerPubkeys))
+	}
+
+	if c.BlockMakerSeckey != (cipher.SecKey{}) {
+		if _, ok := makers[cipher.PubKeyFromSecKey(c.BlockMakerSeckey)]; !ok {
+			return errors.New("Cannot run as block maker: seckey is not of a block maker pubkey")
 		}
 	}
 
 	return nil
 }
 
+// IsBlockMaker returns true if the node is one of the block makers
+func (c Config) IsBlockMaker() bool {
+	return len(c.BlockMakerPubkeys) != 0 && c.BlockMakerSeckey != (cipher.SecKey{})
+}
+
+// BlockchainConfig returns the configuration of the Blockchain
+func (c Config) BlockchainConfig() BlockchainConfig {
+	return BlockchainConfig{
+		Pubkey:              c.BlockchainPubkey,
+		SignerPubkeys:       c.BlockchainSignerPubkeys,
+		BlockMakerPubkeys:   c.BlockMakerPubkeys,
+		BlockMakerQuorum:    c.BlockMakerQuorum,
+		TrustedKeyRotations: c.TrustedKeyRotations,
+		Arbitrating:         c.Arbitrating,
+	}
+}
+
+// blockchainSeckeys returns the secret keys of the blockchain authority (if master)
+func (c Config) blockchainSeckeys() []cipher.SecKey {
+	var seckeys []cipher.SecKey
+	if c.BlockchainSeckey != (cipher.SecKey{}) {
+		seckeys = append(seckeys, c.BlockchainSeckey)
+	}
+	return append(seckeys, c.BlockchainSignerSeckeys...)
+}
+
 //go:generate go install
 //go:generate goautomock -template=testify Historyer
 
@@ -175,6 +287,10 @@ type Historyer interface {
 	Erase(tx *dbutil.Tx) error
 	ParsedHeight(tx *dbutil.Tx) (uint64, bool, error)
 	ForEachTxn(tx *dbutil.Tx, f func(cipher.SHA256, *historydb.Transaction) error) error
+	GetAddressTxnHashes(tx *dbutil.Tx, address cipher.Address) ([]cipher.SHA256, error)
+	GetTimeRangeTxnHashes(tx *dbutil.Tx, start, end uint64) ([]cipher.SHA256, error)
+	GetAmountRangeTxnHashes(tx *dbutil.Tx, min, max uint64) ([]cipher.SHA256, error)
+	GetTransactions(tx *dbutil.Tx, hashes []cipher.SHA256) ([]historydb.Transaction, error)
 }
 
 // Blockchainer is the interface that provides methods for accessing the blockchain data
@@ -191,10 +307,14 @@ type Blockchainer interface {
 	Time(tx *dbutil.Tx) (uint64, error)
 	NewBlock(tx *dbutil.Tx, txns coin.Transactions, currentTime uint64) (*coin.Block, error)
 	ExecuteBlock(tx *dbutil.Tx, sb *coin.SignedBlock) error
+	VerifyBlock(tx *dbutil.Tx, sb *coin.SignedBlock) error
 	VerifyBlockTxnConstraints(tx *dbutil.Tx, txn coin.Transaction) error
 	VerifySingleTxnHardConstraints(tx *dbutil.Tx, txn coin.Transaction) error
 	VerifySingleTxnSoftHardConstraints(tx *dbutil.Tx, txn coin.Transaction, maxSize int) error
 	TransactionFee(tx *dbutil.Tx, hours uint64) coin.FeeCalculator
+	SignerPubkeys(tx *dbutil.Tx, seq uint64) ([]cipher.PubKey, error)
+	GetKeyRotations(tx *dbutil.Tx) ([]coin.KeyRotation, error)
+	AddKeyRotation(tx *dbutil.Tx, r coin.KeyRotation) error
 }
 
 // UnconfirmedTxnPooler is the interface that provides methods for
@@ -210,12 +330,19 @@ type UnconfirmedTxnPooler interface {
 	GetKnown(tx *dbutil.Tx, txns []cipher.SHA256) (coin.Transactions, error)
 	RecvOfAddresses(tx *dbutil.Tx, bh coin.BlockHeader, addrs []cipher.Address) (coin.AddressUxOuts, error)
 	GetIncomingOutputs(tx *dbutil.Tx, bh coin.BlockHeader) (coin.UxArray, error)
+	GetUnconfirmedOutputs(tx *dbutil.Tx) (map[cipher.SHA256]coin.UxOut, error)
 	Get(tx *dbutil.Tx, hash cipher.SHA256) (*UnconfirmedTxn, error)
 	GetTxns(tx *dbutil.Tx, filter func(tx UnconfirmedTxn) bool) ([]UnconfirmedTxn, error)
 	GetTxHashes(tx *dbutil.Tx, filter func(tx UnconfirmedTxn) bool) ([]cipher.SHA256, error)
 	ForEach(tx *dbutil.Tx, f func(cipher.SHA256, UnconfirmedTxn) error) error
 	GetUnspentsOfAddr(tx *dbutil.Tx, addr cipher.Address) (coin.UxArray, error)
 	Len(tx *dbutil.Tx) (uint64, error)
+	Stats(tx *dbutil.Tx) (*UnconfirmedTxnPoolStats, error)
+	GetFees(tx *dbutil.Tx, bc Blockchainer) ([]UnconfirmedTxnFee, error)
+	VerifySingleTxnSoftHardConstraints(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, maxSize int) error
+	GetSpendableOutputs(tx *dbutil.Tx, bc Blockchainer, addrs []cipher.Address) (coin.AddressUxOuts, error)
+	GetExpired(tx *dbutil.Tx, maxAge time.Duration) ([]UnconfirmedTxn, error)
+	RemoveExpired(tx *dbutil.Tx, maxAge time.Duration) ([]cipher.SHA256, error)
 }
 
 // Visor manages the Blockchain as both a Master and a Normal
@@ -228,7 +355,32 @@ type Visor struct {
 	Wallets     *wallet.Service
 	StartedAt   time.Time
 
-	history Historyer
+	history         Historyer
+	supplySnapshots supplySnapshots
+
+	// Logger with the ID of the API request that the daemon loop is handling, see SetRequestID
+	requestLogger atomic.Value
+}
+
+// SetRequestID sets the ID of the API request that the visor is handling,
+// which is added to the visor's log lines. An empty ID removes it.
+// The daemon's Gateway sets it while the daemon loop handles a call made for an API request.
+func (vs *Visor) SetRequestID(id string) {
+	l := logger
+	if id != "" {
+		l = &logging.Logger{
+			FieldLogger: logger.WithField(logging.RequestIDKey, id),
+		}
+	}
+	vs.requestLogger.Store(l)
+}
+
+// log returns the logger of the visor's log lines
+func (vs *Visor) log() *logging.Logger {
+	if l, ok := vs.requestLogger.Load().(*logging.Logger); ok {
+		return l
+	}
+	return logger
 }
 
 // NewVisor creates a Visor for managing the blockchain database
@@ -237,6 +389,9 @@ func NewVisor(c Config, db *dbutil.DB) (*Visor, error) {
 	if c.IsMaster {
 		logger.Info("Visor is master")
 	}
+	if c.IsBlockMaker() {
+		logger.Info("Visor is block maker")
+	}
 
 	if err := c.Verify(); err != nil {
 		return nil, err
@@ -262,10 +417,7 @@ func NewVisor(c Config, db *dbutil.DB) (*Visor, error) {
 		}
 	}
 
-	bc, err := NewBlockchain(db, BlockchainConfig{
-		Pubkey:      c.BlockchainPubkey,
-		Arbitrating: c.Arbitrating,
-	})
+	bc, err := NewBlockchain(db, c.BlockchainConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -287,9 +439,28 @@ func NewVisor(c Config, db *dbutil.DB) (*Visor, error) {
 		}); err != nil {
 			return nil, err
 		}
+
+		if err := db.Update("resolve key rotation conflicts", func(tx *dbutil.Tx) error {
+			conflicts, err := bc.ResolveKeyRotationConflicts(tx)
+			if err != nil {
+				return err
+			}
+
+			for _, c := range conflicts {
+				logger.Errorf("%v. Add the hash of the genuine key rotation to -trusted-key-rotations", c)
+			}
+
+			return nil
+		}); err != nil {
+			return nil, err
+		}
 	}
 
-	utp, err := NewUnconfirmedTxnPool(db)
+	utp, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{
+		MaxSize:             c.MaxUnconfirmedTxnsSize,
+		MaxChainDepth:       c.MaxUnconfirmedChainDepth,
+		MinReplacementFeeKB: c.MinReplacementFeeKB,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -309,7 +480,7 @@ func NewVisor(c Config, db *dbutil.DB) (*Visor, error) {
 
 // Init initializes starts the visor
 func (vs *Visor) Init() error {
-	logger.Info("Visor init")
+	vs.log().Info("Visor init")
 
 	if vs.DB.IsReadOnly() {
 		return nil
@@ -324,7 +495,7 @@ func (vs *Visor) Init() error {
 		if err != nil {
 			return err
 		}
-		logger.Infof("Removed %d invalid txns from pool", len(removed))
+		vs.log().Infof("Removed %d invalid txns from pool", len(removed))
 
 		return nil
 	})
@@ -390,7 +561,7 @@ func parseHistoryTo(tx *dbutil.Tx, history *historydb.HistoryDB, bc *Blockchain,
 
 // maybeCreateGenesisBlock creates a genesis block if necessary
 func (vs *Visor) maybeCreateGenesisBlock(tx *dbutil.Tx) error {
-	logger.Info("Visor maybeCreateGenesisBlock")
+	vs.log().Info("Visor maybeCreateGenesisBlock")
 	gb, err := vs.Blockchain.GetGenesisBlock(tx)
 	if err != nil {
 		return err
@@ -399,7 +570,7 @@ func (vs *Visor) maybeCreateGenesisBlock(tx *dbutil.Tx) error {
 		return nil
 	}
 
-	logger.Info("Create genesis block")
+	vs.log().Info("Create genesis block")
 	vs.GenesisPreconditions()
 	b, err := coin.NewGenesisBlock(vs.Config.GenesisAddress, vs.Config.GenesisCoinVolume, vs.Config.GenesisTimestamp)
 	if err != nil {
@@ -409,8 +580,11 @@ func (vs *Visor) maybeCreateGenesisBlock(tx *dbutil.Tx) error {
 	var sb coin.SignedBlock
 	// record the signature of genesis block
 	if vs.Config.IsMaster {
-		sb = vs.signBlock(*b)
-		logger.Infof("Genesis block signature=%s", sb.Sig.Hex())
+		sb, err = vs.signBlock(tx, *b)
+		if err != nil {
+			return err
+		}
+		vs.log().Infof("Genesis block signature=%s", sb.Sig.Hex())
 	} else {
 		sb = coin.SignedBlock{
 			Block: *b,
@@ -425,7 +599,7 @@ func (vs *Visor) maybeCreateGenesisBlock(tx *dbutil.Tx) error {
 func (vs *Visor) GenesisPreconditions() {
 	if vs.Config.BlockchainSeckey != (cipher.SecKey{}) {
 		if vs.Config.BlockchainPubkey != cipher.PubKeyFromSecKey(vs.Config.BlockchainSeckey) {
-			logger.Panic("Cannot create genesis block. Invalid secret key for pubkey")
+			vs.log().Panic("Cannot create genesis block. Invalid secret key for pubkey")
 		}
 	}
 }
@@ -463,8 +637,8 @@ func (vs *Visor) RemoveInvalidUnconfirmed() ([]cipher.SHA256, error) {
 
 // CreateBlock creates a SignedBlock from pending transactions
 func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, error) {
-	if !vs.Config.IsMaster {
-		logger.Panic("Only master chain can create blocks")
+	if !vs.Config.IsMaster && !vs.Config.IsBlockMaker() {
+		vs.log().Panic("Only master chain or block makers can create blocks")
 	}
 
 	// Gather all unconfirmed transactions
@@ -477,7 +651,7 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 		return coin.SignedBlock{}, errors.New("No transactions")
 	}
 
-	logger.Infof("Unconfirmed pool has %d transactions pending", len(txns))
+	vs.log().Infof("Unconfirmed pool has %d transactions pending", len(txns))
 
 	// Filter transactions that violate all constraints
 	var filteredTxns coin.Transactions
@@ -485,7 +659,7 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 		if err := vs.Blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, vs.Config.MaxBlockSize); err != nil {
 			switch err.(type) {
 			case ErrTxnViolatesHardConstraint, ErrTxnViolatesSoftConstraint:
-				logger.Warningf("Transaction %s violates constraints: %v", txn.TxIDHex(), err)
+				vs.log().Warningf("Transaction %s violates constraints: %v", txn.TxIDHex(), err)
 			default:
 				return coin.SignedBlock{}, err
 			}
@@ -496,13 +670,13 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 
 	nRemoved := len(txns) - len(filteredTxns)
 	if nRemoved > 0 {
-		logger.Infof("CreateBlock ignored %d transactions violating constraints", nRemoved)
+		vs.log().Infof("CreateBlock ignored %d transactions violating constraints", nRemoved)
 	}
 
 	txns = filteredTxns
 
 	if len(txns) == 0 {
-		logger.Info("No transactions after filtering for constraint violations")
+		vs.log().Info("No transactions after filtering for constraint violations")
 		return coin.SignedBlock{}, errors.New("No transactions after filtering for constraint violations")
 	}
 
@@ -518,18 +692,18 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 	txns = txns.TruncateBytesTo(vs.Config.MaxBlockSize)
 
 	if len(txns) == 0 {
-		logger.Panic("TruncateBytesTo removed all transactions")
+		vs.log().Panic("TruncateBytesTo removed all transactions")
 	}
 
-	logger.Infof("Creating new block with %d transactions, head time %d", len(txns), when)
+	vs.log().Infof("Creating new block with %d transactions, head time %d", len(txns), when)
 
 	b, err := vs.Blockchain.NewBlock(tx, txns, when)
 	if err != nil {
-		logger.Warningf("Blockchain.NewBlock failed: %v", err)
+		vs.log().Warningf("Blockchain.NewBlock failed: %v", err)
 		return coin.SignedBlock{}, err
 	}
 
-	return vs.signBlock(*b), nil
+	return vs.signBlock(tx, *b)
 }
 
 // CreateAndExecuteBlock creates a SignedBlock from pending transactions and executes it
@@ -549,21 +723,45 @@ func (vs *Visor) CreateAndExecuteBlock() (coin.SignedBlock, error) {
 	return sb, err
 }
 
+// CreateBlockProposal creates a SignedBlock from pending transactions, signed by the block maker,
+// without executing it. The block is executed once a quorum of block makers signed it.
+func (vs *Visor) CreateBlockProposal() (coin.SignedBlock, error) {
+	var sb coin.SignedBlock
+
+	err := vs.DB.View("CreateBlockProposal", func(tx *dbutil.Tx) error {
+		var err error
+		sb, err = vs.createBlock(tx, uint64(utc.UnixNow()))
+		return err
+	})
+
+	return sb, err
+}
+
+// VerifyBlockProposal checks that a block proposed by a block maker is signed by a block maker
+// and could be executed on top of the current head block
+func (vs *Visor) VerifyBlockProposal(b coin.SignedBlock) error {
+	if err := verifyBlockSignature(vs.Config.BlockMakerPubkeys, &b); err != nil {
+		return err
+	}
+
+	return vs.DB.View("VerifyBlockProposal", func(tx *dbutil.Tx) error {
+		return vs.Blockchain.VerifyBlock(tx, &b)
+	})
+}
+
 // ExecuteSignedBlock adds a block to the blockchain, or returns error.
-// Blocks must be executed in sequence, and be signed by the master server
+// Blocks must be executed in sequence, and be signed by a pubkey that can sign blocks at their seq
 func (vs *Visor) ExecuteSignedBlock(b coin.SignedBlock) error {
+	defer blockExecutionDuration.ObserveSince(time.Now())
+
 	return vs.DB.Update("ExecuteSignedBlock", func(tx *dbutil.Tx) error {
 		return vs.executeSignedBlock(tx, b)
 	})
 }
 
 // executeSignedBlock adds a block to the blockchain, or returns error.
-// Blocks must be executed in sequence, and be signed by the master server
+// Blocks must be executed in sequence, and be signed by a pubkey that can sign blocks at their seq
 func (vs *Visor) executeSignedBlock(tx *dbutil.Tx, b coin.SignedBlock) error {
-	if err := b.VerifySignature(vs.Config.BlockchainPubkey); err != nil {
-		return err
-	}
-
 	if err := vs.Blockchain.ExecuteBlock(tx, &b); err != nil {
 		return err
 	}
@@ -579,21 +777,87 @@ func (vs *Visor) executeSignedBlock(tx *dbutil.Tx, b coin.SignedBlock) error {
 	}
 
 	// Update the HistoryDB
-	return vs.history.ParseBlock(tx, b.Block)
+	if err := vs.history.ParseBlock(tx, b.Block); err != nil {
+		return err
+	}
+
+	return vs.maybeSaveSupplySnapshot(tx, &b)
 }
 
-// signBlock signs a block for master.  Will panic if anything is invalid
-func (vs *Visor) signBlock(b coin.Block) coin.SignedBlock {
-	if !vs.Config.IsMaster {
-		logger.Panic("Only master chain can sign blocks")
+// signBlock signs a block for master or block maker.  Will panic if the node is neither.
+// A master signs with the first of its secret keys whose pubkey can sign the block at its seq,
+// which changes with the key rotations.
+func (vs *Visor) signBlock(tx *dbutil.Tx, b coin.Block) (coin.SignedBlock, error) {
+	var seckey cipher.SecKey
+	switch {
+	case vs.Config.IsMaster:
+		pubkeys, err := vs.Blockchain.SignerPubkeys(tx, b.Seq())
+		if err != nil {
+			return coin.SignedBlock{}, err
+		}
+
+		var ok bool
+		seckey, ok = selectSeckey(vs.Config.blockchainSeckeys(), pubkeys)
+		if !ok {
+			return coin.SignedBlock{}, fmt.Errorf("Cannot sign block %d: no secret key of a blockchain pubkey in effect at this seq", b.Seq())
+		}
+	case vs.Config.IsBlockMaker():
+		seckey = vs.Config.BlockMakerSeckey
+	default:
+		vs.log().Panic("Only master chain or block makers can sign blocks")
 	}
 
-	sig := cipher.SignHash(b.HashHeader(), vs.Config.BlockchainSeckey)
+	sig := cipher.SignHash(b.HashHeader(), seckey)
 
 	return coin.SignedBlock{
 		Block: b,
 		Sig:   sig,
+	}, nil
+}
+
+// selectSeckey returns the first seckey whose pubkey is one of pubkeys
+func selectSeckey(seckeys []cipher.SecKey, pubkeys []cipher.PubKey) (cipher.SecKey, bool) {
+	for _, sk := range seckeys {
+		if containsPubkey(pubkeys, cipher.PubKeyFromSecKey(sk)) {
+			return sk, true
+		}
+	}
+	return cipher.SecKey{}, false
+}
+
+// GetKeyRotations returns the key rotations of the blockchain signing keys, ordered by seq
+func (vs *Visor) GetKeyRotations() ([]coin.KeyRotation, error) {
+	var rotations []coin.KeyRotation
+	if err := vs.DB.View("GetKeyRotations", func(tx *dbutil.Tx) error {
+		var err error
+		rotations, err = vs.Blockchain.GetKeyRotations(tx)
+		return err
+	}); err != nil {
+		return nil, err
 	}
+
+	return rotations, nil
+}
+
+// AddKeyRotation verifies and saves a key rotation of the blockchain signing keys.
+// Returns ErrKeyRotationKnown if the key rotation was already added.
+// Returns ErrKeyRotationConflict if the key rotation conflicts with a stored key rotation,
+// after the conflict is recorded.
+func (vs *Visor) AddKeyRotation(r coin.KeyRotation) error {
+	var conflict error
+	if err := vs.DB.Update("AddKeyRotation", func(tx *dbutil.Tx) error {
+		err := vs.Blockchain.AddKeyRotation(tx, r)
+		if _, ok := err.(ErrKeyRotationConflict); ok {
+			// Commit the recorded conflict
+			conflict = err
+			return nil
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return conflict
 }
 
 /*
@@ -827,7 +1091,7 @@ func (vs *Visor) InjectTransactionStrict(txn coin.Transaction) (bool, error) {
 	var known bool
 
 	if err := vs.DB.Update("InjectTransactionStrict", func(tx *dbutil.Tx) error {
-		err := vs.Blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, vs.Config.MaxBlockSize)
+		err := vs.Unconfirmed.VerifySingleTxnSoftHardConstraints(tx, vs.Blockchain, txn, vs.Config.MaxBlockSize)
 		if err != nil {
 			return err
 		}
@@ -897,7 +1161,7 @@ func (vs *Visor) GetAddressTxns(a cipher.Address) ([]Transaction, error) {
 			}
 
 			if utxn == nil {
-				logger.Critical().Error("Unconfirmed unspent missing unconfirmed txn")
+				vs.log().Critical().Error("Unconfirmed unspent missing unconfirmed txn")
 				continue
 			}
 
@@ -1015,18 +1279,155 @@ func ConfirmedTxFilter(isConfirmed bool) TxFilter {
 	}}
 }
 
+// indexedTxFilter is a TxFilter that can be answered with a historydb index
+type indexedTxFilter interface {
+	TxFilter
+	// txnHashes returns the hashes of the confirmed transactions which may pass the filter
+	txnHashes(tx *dbutil.Tx, history Historyer) ([]cipher.SHA256, error)
+}
+
+// TimeRangeFilter collects the transactions with start <= time <= end.
+// The time of a confirmed transaction is the time of the block that executed it,
+// the time of an unconfirmed transaction is the time it was received.
+// If end is 0, there is no upper bound.
+func TimeRangeFilter(start, end uint64) TxFilter {
+	return timeRangeFilter{Start: start, End: end}
+}
+
+type timeRangeFilter struct {
+	Start uint64
+	End   uint64
+}
+
+// Match implements the TxFilter interface
+func (f timeRangeFilter) Match(tx *Transaction) bool {
+	return tx.Time >= f.Start && (f.End == 0 || tx.Time <= f.End)
+}
+
+func (f timeRangeFilter) txnHashes(tx *dbutil.Tx, history Historyer) ([]cipher.SHA256, error) {
+	end := f.End
+	if end == 0 {
+		end = math.MaxUint64
+	}
+	return history.GetTimeRangeTxnHashes(tx, f.Start, end)
+}
+
+// AmountRangeFilter collects the transactions which send min <= coins <= max in their outputs.
+// Amounts are in droplets. If max is 0, there is no upper bound.
+func AmountRangeFilter(min, max uint64) TxFilter {
+	return amountRangeFilter{Min: min, Max: max}
+}
+
+type amountRangeFilter struct {
+	Min uint64
+	Max uint64
+}
+
+// Match implements the TxFilter interface
+func (f amountRangeFilter) Match(tx *Transaction) bool {
+	coins, err := tx.Txn.OutputCoins()
+	if err != nil {
+		return false
+	}
+	return coins >= f.Min && (f.Max == 0 || coins <= f.Max)
+}
+
+func (f amountRangeFilter) txnHashes(tx *dbutil.Tx, history Historyer) ([]cipher.SHA256, error) {
+	max := f.Max
+	if max == 0 {
+		max = math.MaxUint64
+	}
+	return history.GetAmountRangeTxnHashes(tx, f.Min, max)
+}
+
+// OutputAddrsFilter collects the transactions which create an output for any of the addresses
+func OutputAddrsFilter(addrs []cipher.Address) TxFilter {
+	return outputAddrsFilter{Addrs: addrs}
+}
+
+type outputAddrsFilter struct {
+	Addrs []cipher.Address
+}
+
+// Match implements the TxFilter interface
+func (f outputAddrsFilter) Match(tx *Transaction) bool {
+	for _, o := range tx.Txn.Out {
+		if containsAddress(f.Addrs, o.Address) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f outputAddrsFilter) txnHashes(tx *dbutil.Tx, history Historyer) ([]cipher.SHA256, error) {
+	return getAddressesTxnHashes(tx, history, f.Addrs)
+}
+
+// InputAddrsFilter collects the transactions which spend an output owned by any of the addresses
+func InputAddrsFilter(addrs []cipher.Address) TxFilter {
+	return inputAddrsFilter{Addrs: addrs}
+}
+
+type inputAddrsFilter struct {
+	Addrs []cipher.Address
+}
+
+// Match implements the TxFilter interface, this actually won't be used, the input addresses
+// are not known from the transaction alone so matchInputs is used instead.
+func (f inputAddrsFilter) Match(tx *Transaction) bool { return true }
+
+func (f inputAddrsFilter) matchInputs(inAddrs []cipher.Address) bool {
+	for _, a := range inAddrs {
+		if containsAddress(f.Addrs, a) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f inputAddrsFilter) txnHashes(tx *dbutil.Tx, history Historyer) ([]cipher.SHA256, error) {
+	return getAddressesTxnHashes(tx, history, f.Addrs)
+}
+
+func containsAddress(addrs []cipher.Address, a cipher.Address) bool {
+	for _, b := range addrs {
+		if a == b {
+			return true
+		}
+	}
+	return false
+}
+
+// getAddressesTxnHashes returns the hashes of all transactions related to any of the addresses
+func getAddressesTxnHashes(tx *dbutil.Tx, history Historyer, addrs []cipher.Address) ([]cipher.SHA256, error) {
+	var hashes []cipher.SHA256
+	for _, a := range addrs {
+		hs, err := history.GetAddressTxnHashes(tx, a)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hs...)
+	}
+	return hashes, nil
+}
+
 // GetTransactions returns transactions that can pass the filters.
 // If any 'AddrsFilter' exist, call vs.getTransactionsOfAddrs, cause
 // there's an address index of transactions in db which, having address as key and transaction hashes as value.
+// If any filter that can be answered with a historydb index exists (time range, amount range, input and
+// output addresses), the candidate transactions are found by intersecting the indexes, see vs.searchTxns.
 // If no filters is provided, returns all transactions.
 func (vs *Visor) GetTransactions(flts ...TxFilter) ([]Transaction, error) {
 	var addrFlts []addrsFilter
+	var idxFlts []indexedTxFilter
 	var otherFlts []TxFilter
-	// Splits the filters into AddrsFilter and other filters
+	// Splits the filters into AddrsFilter, indexed filters and other filters
 	for _, f := range flts {
 		switch v := f.(type) {
 		case addrsFilter:
 			addrFlts = append(addrFlts, v)
+		case indexedTxFilter:
+			idxFlts = append(idxFlts, v)
 		default:
 			otherFlts = append(otherFlts, f)
 		}
@@ -1035,6 +1436,19 @@ func (vs *Visor) GetTransactions(flts ...TxFilter) ([]Transaction, error) {
 	// Accumulates all addresses in address filters
 	addrs := accumulateAddressInFilter(addrFlts)
 
+	// Uses the historydb indexes to find the transactions if there's any indexed filter
+	if len(idxFlts) != 0 {
+		var txns []Transaction
+		if err := vs.DB.View("GetTransactions searchTxns", func(tx *dbutil.Tx) error {
+			var err error
+			txns, err = vs.searchTxns(tx, addrs, idxFlts, otherFlts)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		return txns, nil
+	}
+
 	// Traverses all transactions to do collection if there's no address filter.
 	if len(addrs) == 0 {
 		var txns []Transaction
@@ -1109,6 +1523,180 @@ func accumulateAddressInFilter(afs []addrsFilter) []cipher.Address {
 	return addrs
 }
 
+// searchTxns returns the transactions that pass all of the filters.
+// Confirmed transactions are looked up from the intersection of the historydb indexes of the
+// address and indexed filters, then checked against every filter.
+// Unconfirmed transactions are not indexed, each of them is checked against every filter.
+// If addrs is not empty, only transactions related to any of the addresses are returned.
+func (vs *Visor) searchTxns(tx *dbutil.Tx, addrs []cipher.Address, idxFlts []indexedTxFilter, otherFlts []TxFilter) ([]Transaction, error) {
+	// Get the head block seq, for calculating the tx status
+	headBkSeq, ok, err := vs.Blockchain.HeadSeq(tx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("No head block seq")
+	}
+
+	// Intersects the transaction hashes found in each index
+	var candidates map[cipher.SHA256]struct{}
+	intersect := func(hashes []cipher.SHA256) {
+		m := make(map[cipher.SHA256]struct{}, len(hashes))
+		for _, h := range hashes {
+			if candidates != nil {
+				if _, ok := candidates[h]; !ok {
+					continue
+				}
+			}
+			m[h] = struct{}{}
+		}
+		candidates = m
+	}
+
+	if len(addrs) != 0 {
+		hashes, err := getAddressesTxnHashes(tx, vs.history, addrs)
+		if err != nil {
+			return nil, err
+		}
+		intersect(hashes)
+	}
+
+	for _, f := range idxFlts {
+		hashes, err := f.txnHashes(tx, vs.history)
+		if err != nil {
+			return nil, err
+		}
+		intersect(hashes)
+	}
+
+	hashes := make([]cipher.SHA256, 0, len(candidates))
+	for h := range candidates {
+		hashes = append(hashes, h)
+	}
+
+	hTxns, err := vs.history.GetTransactions(tx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var txns []Transaction
+	for _, hTxn := range hTxns {
+		if headBkSeq < hTxn.BlockSeq {
+			err := errors.New("Transaction block sequence is less than the head block sequence")
+			vs.log().Critical().WithError(err).WithFields(logrus.Fields{
+				"headBkSeq":  headBkSeq,
+				"txBlockSeq": hTxn.BlockSeq,
+			}).Error()
+			return nil, err
+		}
+
+		bk, err := vs.Blockchain.GetSignedBlockBySeq(tx, hTxn.BlockSeq)
+		if err != nil {
+			return nil, err
+		}
+
+		if bk == nil {
+			return nil, fmt.Errorf("block of seq: %d doesn't exist", hTxn.BlockSeq)
+		}
+
+		txn := Transaction{
+			Txn:    hTxn.Tx,
+			Status: NewConfirmedTransactionStatus(headBkSeq-hTxn.BlockSeq+1, hTxn.BlockSeq),
+			Time:   bk.Time(),
+		}
+
+		// Candidates from the address index are already related to addrs
+		ok, err := vs.matchTxn(tx, &txn, nil, idxFlts, otherFlts)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			txns = append(txns, txn)
+		}
+	}
+
+	txns = sortTxns(txns)
+
+	unconfirmedTxns, err := vs.Unconfirmed.GetTxns(tx, func(txn UnconfirmedTxn) bool {
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ux := range unconfirmedTxns {
+		txn := Transaction{
+			Txn:    ux.Txn,
+			Status: NewUnconfirmedTransactionStatus(),
+			Time:   uint64(nanoToTime(ux.Received).Unix()),
+		}
+
+		ok, err := vs.matchTxn(tx, &txn, addrs, idxFlts, otherFlts)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			txns = append(txns, txn)
+		}
+	}
+
+	return txns, nil
+}
+
+// matchTxn returns true if the transaction passes all of the filters.
+// If addrs is not empty, the transaction must also spend from or create an output for one of the addresses.
+func (vs *Visor) matchTxn(tx *dbutil.Tx, txn *Transaction, addrs []cipher.Address, idxFlts []indexedTxFilter, otherFlts []TxFilter) (bool, error) {
+	for _, f := range otherFlts {
+		if !f.Match(txn) {
+			return false, nil
+		}
+	}
+
+	var inFlts []inputAddrsFilter
+	for _, f := range idxFlts {
+		if f, ok := f.(inputAddrsFilter); ok {
+			inFlts = append(inFlts, f)
+			continue
+		}
+
+		if !f.Match(txn) {
+			return false, nil
+		}
+	}
+
+	if len(inFlts) == 0 && len(addrs) == 0 {
+		return true, nil
+	}
+
+	// Looks up the addresses of the outputs spent by the transaction
+	uxs, _, err := vs.getTxnInputs(tx, txn.Txn.In)
+	if err != nil {
+		return false, err
+	}
+
+	inAddrs := make([]cipher.Address, len(uxs))
+	for i, ux := range uxs {
+		inAddrs[i] = ux.Body.Address
+	}
+
+	for _, f := range inFlts {
+		if !f.matchInputs(inAddrs) {
+			return false, nil
+		}
+	}
+
+	if len(addrs) != 0 {
+		related := inputAddrsFilter{Addrs: addrs}.matchInputs(inAddrs) || outputAddrsFilter{Addrs: addrs}.Match(txn)
+		if !related {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
 // getTransactionsOfAddrs returns all addresses related transactions.
 // Including both confirmed and unconfirmed transactions.
 func (vs *Visor) getTransactionsOfAddrs(tx *dbutil.Tx, addrs []cipher.Address) (map[cipher.Address][]Transaction, error) {
@@ -1135,7 +1723,7 @@ func (vs *Visor) getTransactionsOfAddrs(tx *dbutil.Tx, addrs []cipher.Address) (
 		for _, txn := range addrTxns {
 			if headBkSeq < txn.BlockSeq {
 				err := errors.New("Transaction block sequence is less than the head block sequence")
-				logger.Critical().WithError(err).WithFields(logrus.Fields{
+				vs.log().Critical().WithError(err).WithFields(logrus.Fields{
 					"headBkSeq":  headBkSeq,
 					"txBlockSeq": txn.BlockSeq,
 				}).Error()
@@ -1172,7 +1760,7 @@ func (vs *Visor) getTransactionsOfAddrs(tx *dbutil.Tx, addrs []cipher.Address) (
 			}
 
 			if txn == nil {
-				logger.Critical().Error("Unconfirmed unspent missing unconfirmed txn")
+				vs.log().Critical().Error("Unconfirmed unspent missing unconfirmed txn")
 				continue
 			}
 
@@ -1206,7 +1794,7 @@ func (vs *Visor) traverseTxns(tx *dbutil.Tx, flts ...TxFilter) ([]Transaction, e
 	if err := vs.history.ForEachTxn(tx, func(_ cipher.SHA256, hTxn *historydb.Transaction) error {
 		if headBkSeq < hTxn.BlockSeq {
 			err := errors.New("Transaction block sequence is less than the head block sequence")
-			logger.Critical().WithError(err).WithFields(logrus.Fields{
+			vs.log().Critical().WithError(err).WithFields(logrus.Fields{
 				"headBkSeq":  headBkSeq,
 				"txBlockSeq": hTxn.BlockSeq,
 			}).Error()
@@ -1359,6 +1947,76 @@ func (vs *Visor) GetAllUnconfirmedTxns() ([]UnconfirmedTxn, error) {
 	return txns, nil
 }
 
+// unconfirmedMaxAge returns maxAge, or the configured max age of unconfirmed transactions if maxAge is 0
+func (vs *Visor) unconfirmedMaxAge(maxAge time.Duration) (time.Duration, error) {
+	if maxAge == 0 {
+		maxAge = vs.Config.UnconfirmedTxnsMaxAge
+	}
+
+	if maxAge <= 0 {
+		return 0, ErrUnconfirmedExpiryDisabled
+	}
+
+	return maxAge, nil
+}
+
+// GetExpiredUnconfirmedTxns returns the unconfirmed transactions received more than maxAge ago.
+// If maxAge is 0, the configured UnconfirmedTxnsMaxAge is used.
+func (vs *Visor) GetExpiredUnconfirmedTxns(maxAge time.Duration) ([]UnconfirmedTxn, error) {
+	maxAge, err := vs.unconfirmedMaxAge(maxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	var txns []UnconfirmedTxn
+	if err := vs.DB.View("GetExpiredUnconfirmedTxns", func(tx *dbutil.Tx) error {
+		var err error
+		txns, err = vs.Unconfirmed.GetExpired(tx, maxAge)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return txns, nil
+}
+
+// RemoveExpiredUnconfirmed removes the unconfirmed transactions received more than maxAge ago,
+// and the transactions spending their outputs.
+// If maxAge is 0, the configured UnconfirmedTxnsMaxAge is used.
+// Returns the transaction hashes that were removed.
+func (vs *Visor) RemoveExpiredUnconfirmed(maxAge time.Duration) ([]cipher.SHA256, error) {
+	maxAge, err := vs.unconfirmedMaxAge(maxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []cipher.SHA256
+	if err := vs.DB.Update("RemoveExpiredUnconfirmed", func(tx *dbutil.Tx) error {
+		var err error
+		hashes, err = vs.Unconfirmed.RemoveExpired(tx, maxAge)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// GetUnconfirmedTxnPoolStats returns the unconfirmed pool size and eviction and replacement counters
+func (vs *Visor) GetUnconfirmedTxnPoolStats() (*UnconfirmedTxnPoolStats, error) {
+	var stats *UnconfirmedTxnPoolStats
+
+	if err := vs.DB.View("GetUnconfirmedTxnPoolStats", func(tx *dbutil.Tx) error {
+		var err error
+		stats, err = vs.Unconfirmed.Stats(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
 // GetAllValidUnconfirmedTxHashes returns all valid unconfirmed transaction hashes
 func (vs *Visor) GetAllValidUnconfirmedTxHashes() ([]cipher.SHA256, error) {
 	var hashes []cipher.SHA256
@@ -1449,6 +2107,46 @@ func (vs Visor) GetHeadBlockTime() (uint64, error) {
 	return t, nil
 }
 
+// getTxnInputs returns the outputs spent by a transaction from the historydb.
+// Outputs created by unconfirmed transactions are not in the historydb, they are resolved
+// from the predicted outputs of the unconfirmed transaction pool instead.
+// The returned bool is true if any of the outputs was created by an unconfirmed transaction.
+// historydb.ErrUxOutNotExist is returned if an output can't be found.
+func (vs *Visor) getTxnInputs(tx *dbutil.Tx, uxIDs []cipher.SHA256) (coin.UxArray, bool, error) {
+	uxa := make(coin.UxArray, len(uxIDs))
+	var unconfirmedOuts map[cipher.SHA256]coin.UxOut
+	var hasUnconfirmed bool
+
+	for i, id := range uxIDs {
+		outs, err := vs.history.GetUxOuts(tx, []cipher.SHA256{id})
+		switch err.(type) {
+		case nil:
+			uxa[i] = outs[0].Out
+			continue
+		case historydb.ErrUxOutNotExist:
+		default:
+			return nil, false, err
+		}
+
+		if unconfirmedOuts == nil {
+			unconfirmedOuts, err = vs.Unconfirmed.GetUnconfirmedOutputs(tx)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+
+		ux, ok := unconfirmedOuts[id]
+		if !ok {
+			return nil, false, historydb.NewErrUxOutNotExist(id.Hex())
+		}
+
+		uxa[i] = ux
+		hasUnconfirmed = true
+	}
+
+	return uxa, hasUnconfirmed, nil
+}
+
 // GetUxOutByID gets UxOut by hash id.
 func (vs Visor) GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, error) {
 	var outs []*historydb.UxOut
@@ -1595,7 +2293,15 @@ func (vs *Visor) unconfirmedSpendsOfAddresses(tx *dbutil.Tx, addrs []cipher.Addr
 
 	uxa, err := vs.Blockchain.Unspent().GetArray(tx, inputs)
 	if err != nil {
-		return nil, err
+		if _, ok := err.(blockdb.ErrUnspentNotExist); !ok {
+			return nil, err
+		}
+
+		// Some unconfirmed transactions spend outputs of other unconfirmed transactions
+		uxa, err = vs.getUnconfirmedInputs(tx, inputs)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	outs := make(coin.AddressUxOuts, len(addrs))
@@ -1663,7 +2369,15 @@ func (vs Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair,
 		// Get unspents for the inputs being spent
 		uxa, err = vs.Blockchain.Unspent().GetArray(tx, inputs)
 		if err != nil {
-			return fmt.Errorf("GetArray failed when checking addresses balance: %v", err)
+			if _, ok := err.(blockdb.ErrUnspentNotExist); !ok {
+				return fmt.Errorf("GetArray failed when checking addresses balance: %v", err)
+			}
+
+			// Some unconfirmed transactions spend outputs of other unconfirmed transactions
+			uxa, err = vs.getUnconfirmedInputs(tx, inputs)
+			if err != nil {
+				return fmt.Errorf("getUnconfirmedInputs failed when checking addresses balance: %v", err)
+			}
 		}
 
 		// Get unspents owned by the addresses
@@ -1703,7 +2417,8 @@ func (vs Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair,
 
 		outUxs := spendUxs[addr]
 		inUxs := recvUxs[addr]
-		predictedUxs := uxs.Sub(outUxs).Add(inUxs)
+		// Incoming outputs may be spent by other unconfirmed transactions, so add them before subtracting the spends
+		predictedUxs := uxs.Add(inUxs).Sub(outUxs)
 
 		coins, err := uxs.Coins()
 		if err != nil {
@@ -1786,21 +2501,24 @@ func (vs *Visor) VerifyTxnVerbose(txn *coin.Transaction) ([]wallet.UxBalance, bo
 		switch err.(type) {
 		case nil:
 		case blockdb.ErrUnspentNotExist:
-			uxid := err.(blockdb.ErrUnspentNotExist).UxID
-			// Gets uxouts of txn.In from historydb
-			outs, err := vs.history.GetUxOuts(tx, txn.In)
+			// Gets uxouts of txn.In from historydb, or from the unconfirmed transactions
+			var hasUnconfirmed bool
+			uxa, hasUnconfirmed, err = vs.getTxnInputs(tx, txn.In)
 			if err != nil {
+				if e, ok := err.(historydb.ErrUxOutNotExist); ok {
+					err = fmt.Errorf("transaction input of %s does not exist in either unspent pool or historydb", e.UxID)
+					return NewErrTxnViolatesHardConstraint(err)
+				}
 				return err
 			}
 
-			if len(outs) == 0 {
-				err = fmt.Errorf("transaction input of %s does not exist in either unspent pool or historydb", uxid)
-				return NewErrTxnViolatesHardConstraint(err)
-			}
+			if hasUnconfirmed {
+				// The transaction spends outputs of unconfirmed transactions, verify it like the pool would
+				if err := VerifySingleTxnUserConstraints(*txn); err != nil {
+					return err
+				}
 
-			uxa = coin.UxArray{}
-			for _, out := range outs {
-				uxa = append(uxa, out.Out)
+				return vs.Unconfirmed.VerifySingleTxnSoftHardConstraints(tx, vs.Blockchain, *txn, vs.Config.MaxBlockSize)
 			}
 
 			// Checks if the transaction is confirmed
@@ -1845,6 +2563,49 @@ func (vs *Visor) VerifyTxnVerbose(txn *coin.Transaction) ([]wallet.UxBalance, bo
 	return uxs, isTxnConfirmed, err
 }
 
+// GetRichlist returns the top n of the richlist, built from the address balance index.
+// If n is 0, the whole richlist is returned.
+// If includeDistribution is false, distribution addresses are excluded.
+func (vs *Visor) GetRichlist(includeDistribution bool, n int) (Richlist, error) {
+	summary, err := vs.GetRichlistSummary(includeDistribution, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return summary.Richlist, nil
+}
+
+// GetRichlistSummary returns the top n of the richlist, with the total coins and count of the addresses in the richlist.
+// The address balances are streamed from the address balance index, only the top n are held in memory.
+// If n is 0, the whole richlist is returned.
+// If includeDistribution is false, distribution addresses are excluded.
+func (vs *Visor) GetRichlistSummary(includeDistribution bool, n int) (*RichlistSummary, error) {
+	lockedAddrs := GetLockedDistributionAddresses()
+	lockedAddrsMap := make(map[string]struct{}, len(lockedAddrs))
+	for _, a := range lockedAddrs {
+		lockedAddrsMap[a] = struct{}{}
+	}
+
+	excluded := map[string]struct{}{}
+	if !includeDistribution {
+		for _, a := range append(lockedAddrs, GetUnlockedDistributionAddresses()...) {
+			excluded[a] = struct{}{}
+		}
+	}
+
+	rb := newRichlistBuilder(n, lockedAddrsMap, excluded)
+
+	if err := vs.DB.View("GetRichlistSummary", func(tx *dbutil.Tx) error {
+		return vs.Blockchain.Unspent().ForEachAddressBalance(tx, func(addr cipher.Address, b blockdb.AddressBalance) error {
+			return rb.add(addr.String(), b.Coins)
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return rb.summarize()
+}
+
 // AddressCount returns the total number of addresses with unspents
 func (vs *Visor) AddressCount() (uint64, error) {
 	var count uint64
@@ -1864,7 +2625,7 @@ func (vs *Visor) AddressCount() (uint64, error) {
 func (vs *Visor) CreateTransactionDeprecated(wltID string, password []byte, coins uint64, dest cipher.Address) (*coin.Transaction, error) {
 	w, err := vs.Wallets.GetWallet(wltID)
 	if err != nil {
-		logger.WithError(err).Error("Wallets.GetWallet failed")
+		vs.log().WithError(err).Error("Wallets.GetWallet failed")
 		return nil, err
 	}
 
@@ -1877,15 +2638,15 @@ func (vs *Visor) CreateTransactionDeprecated(wltID string, password []byte, coin
 	if err := vs.DB.View("CreateTransactionDeprecated", func(tx *dbutil.Tx) error {
 		head, err = vs.Blockchain.Head(tx)
 		if err != nil {
-			logger.Errorf("Blockchain.Head failed: %v", err)
+			vs.log().Errorf("Blockchain.Head failed: %v", err)
 			return err
 		}
 
 		// Get unspent outputs, while checking that there are no unconfirmed outputs
-		auxs, err = vs.getUnspentsForSpending(tx, addrs, false)
+		auxs, err = vs.getUnspentsForSpending(tx, addrs, false, false)
 		if err != nil {
 			if err != wallet.ErrSpendingUnconfirmed {
-				logger.WithError(err).Error("getUnspentsForSpending failed")
+				vs.log().WithError(err).Error("getUnspentsForSpending failed")
 			}
 			return err
 		}
@@ -1902,7 +2663,7 @@ func (vs *Visor) CreateTransactionDeprecated(wltID string, password []byte, coin
 		txn, err = w.CreateAndSignTransaction(auxs, head.Time(), coins, dest)
 		return err
 	}); err != nil {
-		logger.WithError(err).Error("CreateAndSignTransaction failed")
+		vs.log().WithError(err).Error("CreateAndSignTransaction failed")
 		return nil, err
 	}
 
@@ -1912,13 +2673,13 @@ func (vs *Visor) CreateTransactionDeprecated(wltID string, password []byte, coin
 	// NOTE: this isn't inside the database transaction, but it's safe,
 	// if a racing database write caused this transaction to be invalid, it would be caught here
 	if err := VerifySingleTxnUserConstraints(*txn); err != nil {
-		logger.WithError(err).Error("Created transaction violates transaction constraints")
+		vs.log().WithError(err).Error("Created transaction violates transaction constraints")
 		return nil, err
 	}
 	if err := vs.DB.View("VerifySingleTxnSoftHardConstraints", func(tx *dbutil.Tx) error {
-		return vs.Blockchain.VerifySingleTxnSoftHardConstraints(tx, *txn, vs.Config.MaxBlockSize)
+		return vs.Unconfirmed.VerifySingleTxnSoftHardConstraints(tx, vs.Blockchain, *txn, vs.Config.MaxBlockSize)
 	}); err != nil {
-		logger.WithError(err).Error("Created transaction violates transaction constraints")
+		vs.log().WithError(err).Error("Created transaction violates transaction constraints")
 		return nil, err
 	}
 
@@ -1933,7 +2694,7 @@ func (vs *Visor) CreateTransaction(params wallet.CreateTransactionParams) (*coin
 
 	w, err := vs.Wallets.GetWallet(params.Wallet.ID)
 	if err != nil {
-		logger.WithError(err).Error("Wallets.GetWallet failed")
+		vs.log().WithError(err).Error("Wallets.GetWallet failed")
 		return nil, nil, err
 	}
 
@@ -1947,7 +2708,7 @@ func (vs *Visor) CreateTransaction(params wallet.CreateTransactionParams) (*coin
 		var err error
 		head, err = vs.Blockchain.Head(tx)
 		if err != nil {
-			logger.WithError(err).Error("Blockchain.Head failed")
+			vs.log().WithError(err).Error("Blockchain.Head failed")
 			return err
 		}
 
@@ -1965,7 +2726,7 @@ func (vs *Visor) CreateTransaction(params wallet.CreateTransactionParams) (*coin
 		txn, inputs, err = w.CreateAndSignTransactionAdvanced(params, auxs, head.Time())
 		return err
 	}); err != nil {
-		logger.WithError(err).Error("CreateAndSignTransactionAdvanced failed")
+		vs.log().WithError(err).Error("CreateAndSignTransactionAdvanced failed")
 		return nil, nil, err
 	}
 
@@ -1975,13 +2736,13 @@ func (vs *Visor) CreateTransaction(params wallet.CreateTransactionParams) (*coin
 	// NOTE: this isn't inside the database transaction, but it's safe,
 	// if a racing database write caused this transaction to be invalid, it would be caught here
 	if err := VerifySingleTxnUserConstraints(*txn); err != nil {
-		logger.WithError(err).Error("Created transaction violates transaction constraints")
+		vs.log().WithError(err).Error("Created transaction violates transaction constraints")
 		return nil, nil, err
 	}
 	if err := vs.DB.View("VerifySingleTxnSoftHardConstraints", func(tx *dbutil.Tx) error {
-		return vs.Blockchain.VerifySingleTxnSoftHardConstraints(tx, *txn, vs.Config.MaxBlockSize)
+		return vs.Unconfirmed.VerifySingleTxnSoftHardConstraints(tx, vs.Blockchain, *txn, vs.Config.MaxBlockSize)
 	}); err != nil {
-		logger.WithError(err).Error("Created transaction violates transaction constraints")
+		vs.log().WithError(err).Error("Created transaction violates transaction constraints")
 		return nil, nil, err
 	}
 
@@ -2013,7 +2774,7 @@ func (vs *Visor) getCreateTransactionAuxs(tx *dbutil.Tx, params wallet.CreateTra
 			unconfirmedSpends = append(unconfirmedSpends, txn.In...)
 		}
 
-		if params.IgnoreUnconfirmed {
+		if params.IgnoreUnconfirmed || params.SpendUnconfirmed {
 			// Filter unconfirmed spends
 			prevLen := len(hashesMap)
 			for _, h := range unconfirmedSpends {
@@ -2036,7 +2797,12 @@ func (vs *Visor) getCreateTransactionAuxs(tx *dbutil.Tx, params wallet.CreateTra
 
 		// Retrieve the uxouts from the pool.
 		// An error is returned if any do not exist
-		uxouts, err := vs.Blockchain.Unspent().GetArray(tx, params.Wallet.UxOuts)
+		var uxouts coin.UxArray
+		if params.SpendUnconfirmed {
+			uxouts, err = vs.getSpendableUxOuts(tx, params.Wallet.UxOuts, allAddrs)
+		} else {
+			uxouts, err = vs.Blockchain.Unspent().GetArray(tx, params.Wallet.UxOuts)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -2065,7 +2831,7 @@ func (vs *Visor) getCreateTransactionAuxs(tx *dbutil.Tx, params wallet.CreateTra
 
 		// Get unspent outputs, while checking that there are no unconfirmed outputs
 		var err error
-		auxs, err = vs.getUnspentsForSpending(tx, addrs, params.IgnoreUnconfirmed)
+		auxs, err = vs.getUnspentsForSpending(tx, addrs, params.IgnoreUnconfirmed, params.SpendUnconfirmed)
 		if err != nil {
 			return nil, err
 		}
@@ -2075,8 +2841,14 @@ func (vs *Visor) getCreateTransactionAuxs(tx *dbutil.Tx, params wallet.CreateTra
 }
 
 // getUnspentsForSpending returns the unspent outputs for a set of addresses,
-// but returns an error if any of the unspents are in the unconfirmed outputs pool
-func (vs *Visor) getUnspentsForSpending(tx *dbutil.Tx, addrs []cipher.Address, ignoredUnconfirmed bool) (coin.AddressUxOuts, error) {
+// but returns an error if any of the unspents are in the unconfirmed outputs pool.
+// If spendUnconfirmed is true, unspents spent by unconfirmed transactions are ignored, and the
+// outputs of unconfirmed transactions that can be spent are included.
+func (vs *Visor) getUnspentsForSpending(tx *dbutil.Tx, addrs []cipher.Address, ignoredUnconfirmed, spendUnconfirmed bool) (coin.AddressUxOuts, error) {
+	if spendUnconfirmed {
+		ignoredUnconfirmed = true
+	}
+
 	unconfirmedAuxs, err := vs.unconfirmedSpendsOfAddresses(tx, addrs)
 	if err != nil {
 		err = fmt.Errorf("UnconfirmedSpendsOfAddresses failed: %v", err)
@@ -2101,5 +2873,93 @@ func (vs *Visor) getUnspentsForSpending(tx *dbutil.Tx, addrs []cipher.Address, i
 		auxs = auxs.Sub(unconfirmedAuxs)
 	}
 
+	if spendUnconfirmed {
+		spendable, err := vs.Unconfirmed.GetSpendableOutputs(tx, vs.Blockchain, addrs)
+		if err != nil {
+			return nil, err
+		}
+
+		auxs = auxs.Add(spendable)
+	}
+
 	return auxs, nil
 }
+
+// getSpendableUxOuts returns the outputs with the given hashes, which are either unspent outputs
+// or outputs of unconfirmed transactions owned by addrs that can be spent
+func (vs *Visor) getSpendableUxOuts(tx *dbutil.Tx, hashes []cipher.SHA256, addrs []cipher.Address) (coin.UxArray, error) {
+	spendable, err := vs.Unconfirmed.GetSpendableOutputs(tx, vs.Blockchain, addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	spendableMap := make(map[cipher.SHA256]coin.UxOut)
+	for _, uxa := range spendable {
+		for _, ux := range uxa {
+			spendableMap[ux.Hash()] = ux
+		}
+	}
+
+	uxouts := make(coin.UxArray, len(hashes))
+	for i, h := range hashes {
+		ux, err := vs.Blockchain.Unspent().Get(tx, h)
+		if err != nil {
+			return nil, err
+		}
+
+		if ux != nil {
+			uxouts[i] = *ux
+			continue
+		}
+
+		out, ok := spendableMap[h]
+		if !ok {
+			return nil, blockdb.NewErrUnspentNotExist(h.Hex())
+		}
+
+		uxouts[i] = out
+	}
+
+	return uxouts, nil
+}
+
+// getUnconfirmedInputs returns the outputs spent by unconfirmed transactions, given their inputs.
+// The outputs are either unspent outputs or outputs of other unconfirmed transactions.
+func (vs *Visor) getUnconfirmedInputs(tx *dbutil.Tx, inputs []cipher.SHA256) (coin.UxArray, error) {
+	head, err := vs.Blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	incoming, err := vs.Unconfirmed.GetIncomingOutputs(tx, head.Head)
+	if err != nil {
+		return nil, err
+	}
+
+	incomingMap := make(map[cipher.SHA256]coin.UxOut, len(incoming))
+	for _, ux := range incoming {
+		incomingMap[ux.Hash()] = ux
+	}
+
+	uxa := make(coin.UxArray, len(inputs))
+	for i, h := range inputs {
+		ux, err := vs.Blockchain.Unspent().Get(tx, h)
+		if err != nil {
+			return nil, err
+		}
+
+		if ux != nil {
+			uxa[i] = *ux
+			continue
+		}
+
+		out, ok := incomingMap[h]
+		if !ok {
+			return nil, blockdb.NewErrUnspentNotExist(h.Hex())
+		}
+
+		uxa[i] = out
+	}
+
+	return uxa, nil
+}
@@ -109,6 +109,17 @@ func addGenesisBlockToVisor(t *testing.T, vs *Visor) *coin.SignedBlock {
 	return &sb
 }
 
+func signBlock(t *testing.T, vs *Visor, b coin.Block) coin.SignedBlock {
+	var sb coin.SignedBlock
+	err := vs.DB.View("", func(tx *dbutil.Tx) error {
+		var err error
+		sb, err = vs.signBlock(tx, b)
+		return err
+	})
+	require.NoError(t, err)
+	return sb
+}
+
 func TestErrMissingSignatureRecreateDB(t *testing.T) {
 	badDBFile := "./testdata/data.db.nosig" // about 8MB size
 	badDBData := readAll(t, badDBFile)
@@ -146,7 +157,7 @@ func TestErrMissingSignatureRecreateDB(t *testing.T) {
 
 		// err = db.View("", func(tx *dbutil.Tx) error {
 		f := func(tx *dbutil.Tx, b *coin.SignedBlock) error {
-			return bc.VerifySignature(b)
+			return bc.VerifySignature(tx, b)
 		}
 
 		err = bc.WalkChain(BlockchainVerifyTheadNum, f, nil)
@@ -163,7 +174,7 @@ func TestErrMissingSignatureRecreateDB(t *testing.T) {
 	require.NotEmpty(t, badDB.Path())
 	t.Logf("badDB.Path() == %s", badDB.Path())
 
-	db, err := ResetCorruptDB(badDB, pubkey, nil)
+	db, err := ResetCorruptDB(badDB, BlockchainConfig{Pubkey: pubkey}, nil)
 	require.NoError(t, err)
 
 	err = db.Close()
@@ -262,7 +273,7 @@ func TestVisorCreateBlock(t *testing.T) {
 		Pubkey: genPublic,
 	})
 
-	unconfirmed, err := NewUnconfirmedTxnPool(db)
+	unconfirmed, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -282,7 +293,7 @@ func TestVisorCreateBlock(t *testing.T) {
 	}
 
 	// CreateBlock panics if called when not master
-	_require.PanicsWithLogMessage(t, "Only master chain can create blocks", func() {
+	_require.PanicsWithLogMessage(t, "Only master chain or block makers can create blocks", func() {
 		err := db.Update("", func(tx *dbutil.Tx) error {
 			_, err := v.createBlock(tx, when)
 			return err
@@ -453,6 +464,547 @@ func TestVisorCreateBlock(t *testing.T) {
 	}
 }
 
+func TestConfigVerifyBlockMakers(t *testing.T) {
+	otherPublic, _ := cipher.GenerateKeyPair()
+	makers := []cipher.PubKey{genPublic, otherPublic}
+
+	tt := []struct {
+		name   string
+		config func(c *Config)
+		err    string
+	}{
+		{
+			name:   "no block makers",
+			config: func(c *Config) {},
+		},
+		{
+			name: "block maker seckey without block makers",
+			config: func(c *Config) {
+				c.BlockMakerSeckey = genSecret
+			},
+			err: "Cannot run as block maker without block maker pubkeys",
+		},
+		{
+			name: "master with block makers",
+			config: func(c *Config) {
+				c.IsMaster = true
+				c.BlockchainPubkey = genPublic
+				c.BlockchainSeckey = genSecret
+				c.BlockMakerPubkeys = makers
+				c.BlockMakerQuorum = 1
+			},
+			err: "Cannot run in master with block maker pubkeys",
+		},
+		{
+			name: "duplicate block maker",
+			config: func(c *Config) {
+				c.BlockMakerPubkeys = []cipher.PubKey{genPublic, genPublic}
+				c.BlockMakerQuorum = 1
+			},
+			err: fmt.Sprintf("Duplicate block maker pubkey %s", genPublic.Hex()),
+		},
+		{
+			name: "quorum too low",
+			config: func(c *Config) {
+				c.BlockMakerPubkeys = makers
+			},
+			err: "Block maker quorum must be between 1 and 2",
+		},
+		{
+			name: "quorum too high",
+			config: func(c *Config) {
+				c.BlockMakerPubkeys = makers
+				c.BlockMakerQuorum = 3
+			},
+			err: "Block maker quorum must be between 1 and 2",
+		},
+		{
+			name: "seckey of another pubkey",
+			config: func(c *Config) {
+				c.BlockMakerPubkeys = makers[1:]
+				c.BlockMakerQuorum = 1
+				c.BlockMakerSeckey = genSecret
+			},
+			err: "Cannot run as block maker: seckey is not of a block maker pubkey",
+		},
+		{
+			name: "block maker",
+			config: func(c *Config) {
+				c.BlockMakerPubkeys = makers
+				c.BlockMakerQuorum = 2
+				c.BlockMakerSeckey = genSecret
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewVisorConfig()
+			tc.config(&c)
+			err := c.Verify()
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				testutil.RequireError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestVisorBlockProposal(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	otherPublic, otherSecret := cipher.GenerateKeyPair()
+	makerPublic, makerSecret := cipher.GenerateKeyPair()
+	makers := []cipher.PubKey{makerPublic, otherPublic}
+
+	_, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey:            genPublic,
+		BlockMakerPubkeys: makers,
+	})
+	testutil.RequireError(t, err, "Block maker quorum must be between 1 and 2")
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey:            genPublic,
+		BlockMakerPubkeys: makers,
+		BlockMakerQuorum:  2,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{})
+	require.NoError(t, err)
+
+	cfg := NewVisorConfig()
+	cfg.DBPath = db.Path()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+	cfg.BlockMakerPubkeys = makers
+	cfg.BlockMakerQuorum = 2
+	cfg.BlockMakerSeckey = makerSecret
+	require.NoError(t, cfg.Verify())
+	require.True(t, cfg.IsBlockMaker())
+
+	v := &Visor{
+		Config:      cfg,
+		Unconfirmed: unconfirmed,
+		Blockchain:  bc,
+		DB:          db,
+		history:     historydb.New(),
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	_, err = v.CreateBlockProposal()
+	testutil.RequireError(t, err, "No transactions")
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTx(t, uxs, []cipher.SecKey{genSecret}, genAddress, 1e6)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		_, _, err := unconfirmed.InjectTransaction(tx, bc, txn, v.Config.MaxBlockSize)
+		return err
+	})
+	require.NoError(t, err)
+
+	// The proposal is signed by the block maker and is not executed
+	sb, err := v.CreateBlockProposal()
+	require.NoError(t, err)
+	require.NoError(t, sb.VerifySignature(makerPublic))
+	require.Equal(t, uint64(1), sb.Seq())
+	headSeq, _, err := v.HeadBkSeq()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), headSeq)
+
+	require.NoError(t, v.VerifyBlockProposal(sb))
+
+	// A proposal of another block maker is valid
+	sb2 := coin.SignedBlock{
+		Block: sb.Block,
+		Sig:   cipher.SignHash(sb.HashHeader(), otherSecret),
+	}
+	require.NoError(t, v.VerifyBlockProposal(sb2))
+
+	// A block signed by the blockchain pubkey is not a proposal of a block maker
+	sb3 := coin.SignedBlock{
+		Block: sb.Block,
+		Sig:   cipher.SignHash(sb.HashHeader(), genSecret),
+	}
+	testutil.RequireError(t, v.VerifyBlockProposal(sb3), "Invalid sig: block is not signed by the blockchain pubkey or a block maker")
+
+	// A block that can't be executed on the head block is invalid
+	sb4 := sb
+	sb4.Block.Head.BkSeq = 2
+	sb4.Sig = cipher.SignHash(sb4.HashHeader(), makerSecret)
+	testutil.RequireError(t, v.VerifyBlockProposal(sb4), "BkSeq invalid")
+
+	// A block signed by a single block maker can't be executed
+	testutil.RequireError(t, v.ExecuteSignedBlock(sb2), "Block is signed by 0 block makers, 2 are needed")
+
+	sb2.MakerSigs = []cipher.Sig{cipher.SignHash(sb.HashHeader(), otherSecret)}
+	testutil.RequireError(t, v.ExecuteSignedBlock(sb2), "Block is signed by 1 block makers, 2 are needed")
+
+	// A block maker is counted once
+	sb2.MakerSigs = append(sb2.MakerSigs, cipher.SignHash(sb.HashHeader(), otherSecret))
+	testutil.RequireError(t, v.ExecuteSignedBlock(sb2), "Block is signed by 1 block makers, 2 are needed")
+
+	// The block maker sigs must be signed by block makers
+	sb2.MakerSigs = []cipher.Sig{sb2.Sig, cipher.SignHash(sb.HashHeader(), genSecret)}
+	testutil.RequireError(t, v.ExecuteSignedBlock(sb2), "Invalid block maker sig: not signed by a block maker")
+
+	sb2.MakerSigs = []cipher.Sig{sb2.Sig, sb.Sig, sb.Sig}
+	testutil.RequireError(t, v.ExecuteSignedBlock(sb2), "Invalid block maker sigs: 3 sigs for 2 block makers")
+
+	// A block signed by a block maker along with a quorum of block makers is executed, its block maker sigs
+	// are saved with it and it passes the signature verification of the blockchain
+	sb2.MakerSigs = []cipher.Sig{
+		cipher.SignHash(sb.HashHeader(), makerSecret),
+		cipher.SignHash(sb.HashHeader(), otherSecret),
+	}
+	require.NoError(t, v.ExecuteSignedBlock(sb2))
+	headSeq, _, err = v.HeadBkSeq()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), headSeq)
+	err = db.View("", func(tx *dbutil.Tx) error {
+		b, err := bc.GetSignedBlockBySeq(tx, 1)
+		require.NoError(t, err)
+		require.Equal(t, sb2.MakerSigs, b.MakerSigs)
+
+		require.NoError(t, bc.VerifySignature(tx, b))
+		require.NoError(t, bc.VerifySignature(tx, gb))
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The chain passes the database check with the block makers, and fails it without them
+	require.NoError(t, CheckDatabase(db, bc.cfg, nil))
+	err = CheckDatabase(db, BlockchainConfig{Pubkey: genPublic}, nil)
+	testutil.RequireError(t, err, "Invalid sig: block is not signed by the blockchain pubkey or a block maker")
+
+	// The executed proposal can't be proposed again
+	testutil.RequireError(t, v.VerifyBlockProposal(sb), "BkSeq invalid")
+}
+
+func TestVisorKeyRotation(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	newPublic, newSecret := cipher.GenerateKeyPair()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{})
+	require.NoError(t, err)
+
+	// The master has the key of the first block signer, and the key that it rotates to
+	cfg := NewVisorConfig()
+	cfg.DBPath = db.Path()
+	cfg.IsMaster = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.BlockchainSignerSeckeys = []cipher.SecKey{newSecret}
+	cfg.GenesisAddress = genAddress
+	require.NoError(t, cfg.Verify())
+
+	v := &Visor{
+		Config:      cfg,
+		Unconfirmed: unconfirmed,
+		Blockchain:  bc,
+		DB:          db,
+		history:     historydb.New(),
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	createBlock := func(uxs coin.UxArray, when uint64) coin.SignedBlock {
+		txn := makeSpendTx(t, uxs, []cipher.SecKey{genSecret}, testutil.MakeAddress(), 1e6)
+		err := db.Update("", func(tx *dbutil.Tx) error {
+			_, _, err := unconfirmed.InjectTransaction(tx, bc, txn, v.Config.MaxBlockSize)
+			return err
+		})
+		require.NoError(t, err)
+
+		var sb coin.SignedBlock
+		err = db.Update("", func(tx *dbutil.Tx) error {
+			var err error
+			sb, err = v.createBlock(tx, when)
+			if err != nil {
+				return err
+			}
+			return v.executeSignedBlock(tx, sb)
+		})
+		require.NoError(t, err)
+		return sb
+	}
+
+	sb1 := createBlock(coin.CreateUnspents(gb.Head, gb.Body.Transactions[0]), genTime+100)
+	require.NoError(t, sb1.VerifySignature(genPublic))
+
+	// A key rotation must match the blocks that already exist
+	r, err := coin.NewKeyRotation(1, []cipher.PubKey{newPublic}, genSecret)
+	require.NoError(t, err)
+	testutil.RequireError(t, v.AddKeyRotation(*r), "Key rotation does not match block 1: Invalid sig: block is not signed by the blockchain pubkey or a block maker")
+
+	// A key rotation must be signed by a blockchain pubkey in effect at its seq
+	_, otherSecret := cipher.GenerateKeyPair()
+	r, err = coin.NewKeyRotation(2, []cipher.PubKey{newPublic}, otherSecret)
+	require.NoError(t, err)
+	testutil.RequireError(t, v.AddKeyRotation(*r), "Key rotation is not signed by a blockchain pubkey")
+
+	r, err = coin.NewKeyRotation(2, []cipher.PubKey{newPublic}, genSecret)
+	require.NoError(t, err)
+	require.NoError(t, v.AddKeyRotation(*r))
+	require.Equal(t, ErrKeyRotationKnown, v.AddKeyRotation(*r))
+
+	rotations, err := v.GetKeyRotations()
+	require.NoError(t, err)
+	require.Equal(t, []coin.KeyRotation{*r}, rotations)
+
+	// The master selects the key that is in effect at the block's seq
+	sb2 := createBlock(coin.CreateUnspents(sb1.Head, sb1.Body.Transactions[0])[1:], genTime+200)
+	require.Equal(t, uint64(2), sb2.Seq())
+	require.NoError(t, sb2.VerifySignature(newPublic))
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		pubkeys, err := bc.SignerPubkeys(tx, 1)
+		require.NoError(t, err)
+		require.Equal(t, []cipher.PubKey{genPublic}, pubkeys)
+
+		pubkeys, err = bc.SignerPubkeys(tx, 2)
+		require.NoError(t, err)
+		require.Equal(t, []cipher.PubKey{newPublic}, pubkeys)
+
+		// The block is not valid if signed by the key that was rotated away from
+		sb := coin.SignedBlock{
+			Block: sb2.Block,
+			Sig:   cipher.SignHash(sb2.HashHeader(), genSecret),
+		}
+		testutil.RequireError(t, bc.VerifySignature(tx, &sb), "Invalid sig: block is not signed by the blockchain pubkey or a block maker")
+		require.NoError(t, bc.VerifySignature(tx, &sb2))
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The blocks pass the database check, which follows the key rotations
+	require.NoError(t, CheckDatabase(db, BlockchainConfig{Pubkey: genPublic}, nil))
+
+	// Without the key for the next seq, the master can't sign blocks
+	v.Config.BlockchainSignerSeckeys = nil
+	err = db.View("", func(tx *dbutil.Tx) error {
+		_, err := v.signBlock(tx, sb2.Block)
+		return err
+	})
+	testutil.RequireError(t, err, "Cannot sign block 2: no secret key of a blockchain pubkey in effect at this seq")
+}
+
+func TestVisorKeyRotationConflict(t *testing.T) {
+	newPublic, newSecret := cipher.GenerateKeyPair()
+
+	// setup creates a visor with a block signed by genSecret, and the key rotation r from genPublic to newPublic at seq 2
+	setup := func(t *testing.T, trusted ...cipher.SHA256) (*Visor, coin.KeyRotation, func(uint64) (coin.SignedBlock, error), func()) {
+		db, shutdown := prepareDB(t)
+
+		bc, err := NewBlockchain(db, BlockchainConfig{
+			Pubkey:              genPublic,
+			TrustedKeyRotations: trusted,
+		})
+		require.NoError(t, err)
+
+		unconfirmed, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{})
+		require.NoError(t, err)
+
+		cfg := NewVisorConfig()
+		cfg.DBPath = db.Path()
+		cfg.IsMaster = true
+		cfg.BlockchainPubkey = genPublic
+		cfg.BlockchainSeckey = genSecret
+		cfg.BlockchainSignerSeckeys = []cipher.SecKey{newSecret}
+		cfg.GenesisAddress = genAddress
+		require.NoError(t, cfg.Verify())
+
+		v := &Visor{
+			Config:      cfg,
+			Unconfirmed: unconfirmed,
+			Blockchain:  bc,
+			DB:          db,
+			history:     historydb.New(),
+		}
+
+		gb := addGenesisBlockToVisor(t, v)
+		uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+		// createBlock creates and executes a block that spends the last unspent output.
+		// The spending transaction stays in the pool if the block is refused.
+		var pending *coin.Transaction
+		createBlock := func(when uint64) (coin.SignedBlock, error) {
+			if pending == nil {
+				txn := makeSpendTx(t, uxs, []cipher.SecKey{genSecret}, testutil.MakeAddress(), 1e6)
+				err := db.Update("", func(tx *dbutil.Tx) error {
+					_, _, err := unconfirmed.InjectTransaction(tx, bc, txn, v.Config.MaxBlockSize)
+					return err
+				})
+				require.NoError(t, err)
+				pending = &txn
+			}
+
+			var sb coin.SignedBlock
+			err := db.Update("", func(tx *dbutil.Tx) error {
+				var err error
+				sb, err = v.createBlock(tx, when)
+				if err != nil {
+					return err
+				}
+				return v.executeSignedBlock(tx, sb)
+			})
+			if err != nil {
+				return coin.SignedBlock{}, err
+			}
+
+			pending = nil
+			uxs = coin.CreateUnspents(sb.Head, sb.Body.Transactions[0])[1:]
+			return sb, nil
+		}
+
+		_, err = createBlock(genTime + 100)
+		require.NoError(t, err)
+
+		r, err := coin.NewKeyRotation(2, []cipher.PubKey{newPublic}, genSecret)
+		require.NoError(t, err)
+		require.NoError(t, v.AddKeyRotation(*r))
+
+		return v, *r, createBlock, shutdown
+	}
+
+	getConflicts := func(t *testing.T, v *Visor) []blockdb.KeyRotationConflict {
+		var conflicts []blockdb.KeyRotationConflict
+		err := v.DB.View("", func(tx *dbutil.Tx) error {
+			var err error
+			conflicts, err = v.Blockchain.(*Blockchain).store.GetKeyRotationConflicts(tx)
+			return err
+		})
+		require.NoError(t, err)
+		return conflicts
+	}
+
+	// r2 is signed by genSecret, which already rotated to newPublic at seq 2
+	r2, err := coin.NewKeyRotation(3, []cipher.PubKey{genPublic}, genSecret)
+	require.NoError(t, err)
+
+	t.Run("conflict halts the blockchain", func(t *testing.T) {
+		v, r, createBlock, shutdown := setup(t)
+		defer shutdown()
+
+		conflict := ErrKeyRotationConflict{
+			Stored:      r,
+			Conflicting: *r2,
+		}
+		require.Equal(t, uint64(2), conflict.DisputedSeq())
+		require.Equal(t, conflict, v.AddKeyRotation(*r2))
+		require.Equal(t, []blockdb.KeyRotationConflict{{
+			Stored:      r,
+			Conflicting: *r2,
+		}}, getConflicts(t, v))
+
+		// A conflicting key rotation at the same seq
+		r3, err := coin.NewKeyRotation(2, []cipher.PubKey{genPublic}, genSecret)
+		require.NoError(t, err)
+		_, ok := v.AddKeyRotation(*r3).(ErrKeyRotationConflict)
+		require.True(t, ok)
+		require.Len(t, getConflicts(t, v), 2)
+
+		// The stored key rotation is kept
+		rotations, err := v.GetKeyRotations()
+		require.NoError(t, err)
+		require.Equal(t, []coin.KeyRotation{r}, rotations)
+
+		// Blocks from the disputed seq are refused
+		_, err = createBlock(genTime + 200)
+		e, ok := err.(ErrKeyRotationConflict)
+		require.True(t, ok)
+		require.Equal(t, uint64(2), e.DisputedSeq())
+
+		// The conflicts stay unresolved without a trusted key rotation
+		err = v.DB.Update("", func(tx *dbutil.Tx) error {
+			unresolved, err := v.Blockchain.(*Blockchain).ResolveKeyRotationConflicts(tx)
+			require.Len(t, unresolved, 2)
+			return err
+		})
+		require.NoError(t, err)
+
+		// Trusting the stored key rotation resolves the conflicts
+		v.Blockchain.(*Blockchain).cfg.TrustedKeyRotations = []cipher.SHA256{r.Hash()}
+		err = v.DB.Update("", func(tx *dbutil.Tx) error {
+			unresolved, err := v.Blockchain.(*Blockchain).ResolveKeyRotationConflicts(tx)
+			require.Empty(t, unresolved)
+			return err
+		})
+		require.NoError(t, err)
+		require.Empty(t, getConflicts(t, v))
+
+		sb, err := createBlock(genTime + 200)
+		require.NoError(t, err)
+		require.NoError(t, sb.VerifySignature(newPublic))
+
+		// Key rotations that conflict with a trusted key rotation are rejected
+		testutil.RequireError(t, v.AddKeyRotation(*r2), fmt.Sprintf("Key rotation conflicts with trusted key rotation %s at seq 2", r.Hash().Hex()))
+		require.Empty(t, getConflicts(t, v))
+	})
+
+	t.Run("trusted conflicting key rotation replaces the stored key rotation", func(t *testing.T) {
+		v, r, createBlock, shutdown := setup(t)
+		defer shutdown()
+
+		require.Equal(t, ErrKeyRotationConflict{
+			Stored:      r,
+			Conflicting: *r2,
+		}, v.AddKeyRotation(*r2))
+
+		v.Blockchain.(*Blockchain).cfg.TrustedKeyRotations = []cipher.SHA256{r2.Hash()}
+		err := v.DB.Update("", func(tx *dbutil.Tx) error {
+			unresolved, err := v.Blockchain.(*Blockchain).ResolveKeyRotationConflicts(tx)
+			require.Empty(t, unresolved)
+			return err
+		})
+		require.NoError(t, err)
+		require.Empty(t, getConflicts(t, v))
+
+		rotations, err := v.GetKeyRotations()
+		require.NoError(t, err)
+		require.Equal(t, []coin.KeyRotation{*r2}, rotations)
+
+		sb, err := createBlock(genTime + 200)
+		require.NoError(t, err)
+		require.NoError(t, sb.VerifySignature(genPublic))
+	})
+
+	t.Run("trusted key rotation is added over the stored key rotation", func(t *testing.T) {
+		v, _, _, shutdown := setup(t, r2.Hash())
+		defer shutdown()
+
+		require.NoError(t, v.AddKeyRotation(*r2))
+		require.Empty(t, getConflicts(t, v))
+
+		rotations, err := v.GetKeyRotations()
+		require.NoError(t, err)
+		require.Equal(t, []coin.KeyRotation{*r2}, rotations)
+	})
+
+	t.Run("trusted key rotation conflicts with executed blocks", func(t *testing.T) {
+		v, r, createBlock, shutdown := setup(t, r2.Hash())
+		defer shutdown()
+
+		_, err := createBlock(genTime + 200)
+		require.NoError(t, err)
+
+		err = v.AddKeyRotation(*r2)
+		testutil.RequireError(t, err, fmt.Sprintf("Trusted key rotation %s at seq 3 conflicts with key rotation %s at seq 2, which blocks up to seq 2 depend on. Delete the database to resync the blockchain",
+			r2.Hash().Hex(), r.Hash().Hex()))
+		require.Empty(t, getConflicts(t, v))
+	})
+}
+
 func TestVisorInjectTransaction(t *testing.T) {
 	when := uint64(time.Now().UTC().Unix())
 
@@ -464,7 +1016,7 @@ func TestVisorInjectTransaction(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTxnPool(db)
+	unconfirmed, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -484,7 +1036,7 @@ func TestVisorInjectTransaction(t *testing.T) {
 	}
 
 	// CreateBlock panics if called when not master
-	_require.PanicsWithLogMessage(t, "Only master chain can create blocks", func() {
+	_require.PanicsWithLogMessage(t, "Only master chain or block makers can create blocks", func() {
 		err := db.Update("", func(tx *dbutil.Tx) error {
 			_, err := v.createBlock(tx, when)
 			return err
@@ -1914,7 +2466,7 @@ func TestRefreshUnconfirmed(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTxnPool(db)
+	unconfirmed, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -2044,7 +2596,7 @@ func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTxnPool(db)
+	unconfirmed, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -2078,9 +2630,9 @@ func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
 
 	// Create two valid transactions, both spending the same inputs, one with a higher fee
-	// Then, create a block from these transactions.
-	// The one with the higher fee should be included in the block, and the other should be ignored.
-	// A call to RemoveInvalidUnconfirmed will remove the other txn, because it would now be a double spend.
+	// The one with the higher fee replaces the other in the unconfirmed pool.
+	// Then, create a block from the lower fee transaction, as if it was relayed to the block creator by another node.
+	// A call to RemoveInvalidUnconfirmed will remove the higher fee txn, because it would now be a double spend.
 
 	var coins uint64 = 10e6
 	txn1 := makeSpendTx(t, uxs, []cipher.SecKey{genSecret}, genAddress, coins)
@@ -2104,20 +2656,45 @@ func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 	require.Nil(t, softErr)
 	require.NoError(t, err)
 
+	// txn1 is replaced by txn2
 	err = db.View("", func(tx *dbutil.Tx) error {
 		length, err := unconfirmed.Len(tx)
 		require.NoError(t, err)
-		require.Equal(t, uint64(2), length)
+		require.Equal(t, uint64(1), length)
+
+		utxn, err := unconfirmed.Get(tx, txn2.Hash())
+		require.NoError(t, err)
+		require.NotNil(t, utxn)
 		return nil
 	})
 	require.NoError(t, err)
 
-	// Execute a block, txn2 should be included because it has a higher fee
-	sb, err := v.CreateAndExecuteBlock()
+	// txn1 can't be injected again, its fee is lower than txn2's
+	_, _, err = v.InjectTransaction(txn1)
+	require.Equal(t, ErrTxnReplacementFeeTooLow, err)
+
+	// Execute a block containing txn1
+	var head *coin.SignedBlock
+	var uxHash cipher.SHA256
+	err = db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		head, err = v.Blockchain.Head(tx)
+		if err != nil {
+			return err
+		}
+
+		uxHash, err = v.Blockchain.Unspent().GetUxHash(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	b, err := coin.NewBlock(head.Block, head.Time()+100, uxHash, coin.Transactions{txn1}, func(t *coin.Transaction) (uint64, error) {
+		return 0, nil
+	})
+	require.NoError(t, err)
+
+	err = v.ExecuteSignedBlock(signBlock(t, v, *b))
 	require.NoError(t, err)
-	require.Equal(t, 1, len(sb.Body.Transactions))
-	require.Equal(t, 2, len(sb.Body.Transactions[0].Out))
-	require.Equal(t, txn2.TxIDHex(), sb.Body.Transactions[0].TxIDHex())
 
 	err = db.View("", func(tx *dbutil.Tx) error {
 		length, err := unconfirmed.Len(tx)
@@ -2132,10 +2709,10 @@ func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	// Call RemoveInvalidUnconfirmed, the first txn will be removed because it is now a double-spend txn
+	// Call RemoveInvalidUnconfirmed, the second txn will be removed because it is now a double-spend txn
 	removed, err := v.RemoveInvalidUnconfirmed()
 	require.NoError(t, err)
-	require.Equal(t, []cipher.SHA256{txn1.Hash()}, removed)
+	require.Equal(t, []cipher.SHA256{txn2.Hash()}, removed)
 	err = db.View("", func(tx *dbutil.Tx) error {
 		length, err := unconfirmed.Len(tx)
 		require.NoError(t, err)
@@ -2172,6 +2749,8 @@ func TestGetCreateTransactionAuxs(t *testing.T) {
 		getArrayInputs        []cipher.SHA256
 		getArrayRet           coin.UxArray
 		getUnspentsOfAddrsRet coin.AddressUxOuts
+
+		getSpendableOutputsRet coin.AddressUxOuts
 	}{
 		{
 			name:  "all addresses, ok",
@@ -2405,6 +2984,55 @@ func TestGetCreateTransactionAuxs(t *testing.T) {
 			},
 		},
 
+		{
+			name: "some addresses, unconfirmed outputs spent",
+			params: wallet.CreateTransactionParams{
+				SpendUnconfirmed: true,
+				Wallet: wallet.CreateTransactionWalletParams{
+					Addresses: allAddrs[0:2],
+				},
+			},
+			addrs: allAddrs[0:2],
+			getUnspentsOfAddrsRet: coin.AddressUxOuts{
+				allAddrs[0]: []coin.UxOut{
+					coin.UxOut{
+						Body: coin.UxBody{
+							SrcTransaction: srcTxns[8],
+							Address:        allAddrs[0],
+						},
+					},
+				},
+			},
+			getSpendableOutputsRet: coin.AddressUxOuts{
+				allAddrs[1]: []coin.UxOut{
+					coin.UxOut{
+						Body: coin.UxBody{
+							SrcTransaction: srcTxns[9],
+							Address:        allAddrs[1],
+						},
+					},
+				},
+			},
+			expectedAuxs: coin.AddressUxOuts{
+				allAddrs[0]: []coin.UxOut{
+					coin.UxOut{
+						Body: coin.UxBody{
+							SrcTransaction: srcTxns[8],
+							Address:        allAddrs[0],
+						},
+					},
+				},
+				allAddrs[1]: []coin.UxOut{
+					coin.UxOut{
+						Body: coin.UxBody{
+							SrcTransaction: srcTxns[9],
+							Address:        allAddrs[1],
+						},
+					},
+				},
+			},
+		},
+
 		{
 			name: "some addresses, unconfirmed spends ignored",
 			params: wallet.CreateTransactionParams{
@@ -2697,6 +3325,9 @@ func TestGetCreateTransactionAuxs(t *testing.T) {
 			if tc.getUnspentsOfAddrsRet != nil {
 				unspent.On("GetUnspentsOfAddrs", matchTx, tc.addrs).Return(tc.getUnspentsOfAddrsRet, nil)
 			}
+			if tc.getSpendableOutputsRet != nil {
+				unconfirmed.On("GetSpendableOutputs", matchTx, bc, tc.addrs).Return(tc.getSpendableOutputsRet, nil)
+			}
 			bc.On("Unspent").Return(unspent)
 
 			var auxs coin.AddressUxOuts
@@ -2890,7 +3521,8 @@ func TestVerifyTxnVerbose(t *testing.T) {
 			isConfirmed: false,
 			err:         ErrTxnViolatesHardConstraint{fmt.Errorf("transaction input of %s does not exist in either unspent pool or historydb", inputs[0].Hash().Hex())},
 
-			getArrayErr: blockdb.ErrUnspentNotExist{UxID: inputs[0].Hash().Hex()},
+			getArrayErr:         blockdb.ErrUnspentNotExist{UxID: inputs[0].Hash().Hex()},
+			getHistoryUxOutsErr: historydb.NewErrUxOutNotExist(inputs[0].Hash().Hex()),
 		},
 		{
 			name:        "transaction violate user constratins, send to null address",
@@ -2974,10 +3606,14 @@ func TestVerifyTxnVerbose(t *testing.T) {
 			history.On("GetTransaction", matchTx, tc.txn.Hash()).Return(tc.getHistoryTxnRet, tc.getHistoryTxnErr)
 			history.On("GetUxOuts", matchTx, tc.txn.In).Return(tc.getHistoryUxOutsRet, tc.getHistoryUxOutsErr)
 
+			unconfirmed := NewUnconfirmedTxnPoolerMock()
+			unconfirmed.On("GetUnconfirmedOutputs", matchTx).Return(map[cipher.SHA256]coin.UxOut{}, nil)
+
 			v := &Visor{
-				Blockchain: bc,
-				DB:         db,
-				history:    history,
+				Blockchain:  bc,
+				Unconfirmed: unconfirmed,
+				DB:          db,
+				history:     history,
 				Config: Config{
 					MaxBlockSize: tc.maxBlockSize,
 				},
@@ -3045,3 +3681,288 @@ func (m *UnconfirmedTxnPoolerMock2) GetTxns(tx *dbutil.Tx, f func(tx Unconfirmed
 	}
 	return txs, nil
 }
+
+// searchTestTxns are the transactions of the visor created by prepareSearchTestVisor
+type searchTestTxns struct {
+	// The genesis transaction
+	txn0 coin.Transaction
+	// genesis -> A
+	txn1 coin.Transaction
+	// genesis -> B
+	txn2 coin.Transaction
+	// A -> B, no change
+	txn3 coin.Transaction
+	// B -> A, unconfirmed
+	txn4 coin.Transaction
+
+	addrA cipher.Address
+	secA  cipher.SecKey
+	addrB cipher.Address
+}
+
+// prepareSearchTestVisor creates a visor with a transaction in each block, and an unconfirmed transaction
+func prepareSearchTestVisor(t *testing.T) (*Visor, searchTestTxns, func()) {
+	db, shutdown := prepareDB(t)
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTxnPool(db, UnconfirmedTxnPoolConfig{
+		MaxChainDepth: 1,
+	})
+	require.NoError(t, err)
+
+	cfg := NewVisorConfig()
+	cfg.DBPath = db.Path()
+	cfg.IsMaster = true
+	cfg.BlockchainSeckey = genSecret
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		Unconfirmed: unconfirmed,
+		Blockchain:  bc,
+		DB:          db,
+		history:     historydb.New(),
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+	txn0 := gb.Body.Transactions[0]
+
+	pubA, secA := cipher.GenerateKeyPair()
+	addrA := cipher.AddressFromPubKey(pubA)
+	pubB, secB := cipher.GenerateKeyPair()
+	addrB := cipher.AddressFromPubKey(pubB)
+
+	getUx := func(addr cipher.Address) coin.UxOut {
+		var uxs coin.UxArray
+		err := db.View("", func(tx *dbutil.Tx) error {
+			auxs, err := v.Blockchain.Unspent().GetUnspentsOfAddrs(tx, []cipher.Address{addr})
+			if err != nil {
+				return err
+			}
+			uxs = auxs[addr]
+			return nil
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, uxs)
+		return uxs[0]
+	}
+
+	// makeTxn spends an output of from, sending coins to to and the change back to from
+	makeTxn := func(when uint64, from cipher.Address, sec cipher.SecKey, to cipher.Address, coins uint64) coin.Transaction {
+		ux := getUx(from)
+		hours, err := ux.CoinHours(when)
+		require.NoError(t, err)
+
+		txn := coin.Transaction{}
+		txn.PushInput(ux.Hash())
+		txn.PushOutput(to, coins, hours/4)
+		if ux.Body.Coins > coins {
+			txn.PushOutput(from, ux.Body.Coins-coins, hours/4)
+		}
+		txn.SignInputs([]cipher.SecKey{sec})
+		txn.UpdateHeader()
+		return txn
+	}
+
+	executeBlock := func(when uint64, txn coin.Transaction) {
+		var head *coin.SignedBlock
+		var uxHash cipher.SHA256
+		err := db.View("", func(tx *dbutil.Tx) error {
+			var err error
+			head, err = v.Blockchain.Head(tx)
+			if err != nil {
+				return err
+			}
+
+			uxHash, err = v.Blockchain.Unspent().GetUxHash(tx)
+			return err
+		})
+		require.NoError(t, err)
+
+		b, err := coin.NewBlock(head.Block, when, uxHash, coin.Transactions{txn}, func(t *coin.Transaction) (uint64, error) {
+			return 0, nil
+		})
+		require.NoError(t, err)
+
+		err = v.ExecuteSignedBlock(signBlock(t, v, *b))
+		require.NoError(t, err)
+	}
+
+	// genesis -> A
+	txn1 := makeTxn(genTime+100, genAddress, genSecret, addrA, 10e6)
+	executeBlock(genTime+100, txn1)
+
+	// genesis -> B
+	txn2 := makeTxn(genTime+200, genAddress, genSecret, addrB, 50e6)
+	executeBlock(genTime+200, txn2)
+
+	// A -> B, no change
+	txn3 := makeTxn(genTime+300, addrA, secA, addrB, 10e6)
+	executeBlock(genTime+300, txn3)
+
+	// B -> A, unconfirmed
+	txn4 := makeTxn(genTime+400, addrB, secB, addrA, 1e6)
+	_, err = v.InjectTransactionStrict(txn4)
+	require.NoError(t, err)
+
+	return v, searchTestTxns{
+		txn0:  txn0,
+		txn1:  txn1,
+		txn2:  txn2,
+		txn3:  txn3,
+		txn4:  txn4,
+		addrA: addrA,
+		addrB: addrB,
+		secA:  secA,
+	}, shutdown
+}
+
+func TestGetTransactionsSearch(t *testing.T) {
+	v, txns, shutdown := prepareSearchTestVisor(t)
+	defer shutdown()
+
+	txn0, txn1, txn2, txn3, txn4 := txns.txn0, txns.txn1, txns.txn2, txns.txn3, txns.txn4
+	addrA, addrB := txns.addrA, txns.addrB
+
+	tt := []struct {
+		name    string
+		filters []TxFilter
+		expect  []coin.Transaction
+	}{
+		{
+			name:    "time range",
+			filters: []TxFilter{TimeRangeFilter(genTime+150, genTime+250)},
+			expect:  []coin.Transaction{txn2},
+		},
+		{
+			name:    "time range no upper bound, confirmed",
+			filters: []TxFilter{TimeRangeFilter(genTime+150, 0), ConfirmedTxFilter(true)},
+			expect:  []coin.Transaction{txn2, txn3},
+		},
+		{
+			name:    "amount range",
+			filters: []TxFilter{AmountRangeFilter(0, 20e6)},
+			expect:  []coin.Transaction{txn3},
+		},
+		{
+			name:    "amount range no upper bound",
+			filters: []TxFilter{AmountRangeFilter(genCoins, 0)},
+			expect:  []coin.Transaction{txn0, txn1},
+		},
+		{
+			name:    "input address",
+			filters: []TxFilter{InputAddrsFilter([]cipher.Address{addrA})},
+			expect:  []coin.Transaction{txn3},
+		},
+		{
+			name:    "output address",
+			filters: []TxFilter{OutputAddrsFilter([]cipher.Address{addrA})},
+			expect:  []coin.Transaction{txn1, txn4},
+		},
+		{
+			name: "input and output address",
+			filters: []TxFilter{
+				InputAddrsFilter([]cipher.Address{genAddress}),
+				OutputAddrsFilter([]cipher.Address{addrB}),
+			},
+			expect: []coin.Transaction{txn2},
+		},
+		{
+			name: "address and time range",
+			filters: []TxFilter{
+				AddrsFilter([]cipher.Address{addrA}),
+				TimeRangeFilter(genTime+250, genTime+350),
+			},
+			expect: []coin.Transaction{txn3},
+		},
+		{
+			name: "unconfirmed input address",
+			filters: []TxFilter{
+				InputAddrsFilter([]cipher.Address{addrB}),
+				ConfirmedTxFilter(false),
+			},
+			expect: []coin.Transaction{txn4},
+		},
+		{
+			name: "no match",
+			filters: []TxFilter{
+				InputAddrsFilter([]cipher.Address{addrA}),
+				TimeRangeFilter(genTime, genTime+200),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			txns, err := v.GetTransactions(tc.filters...)
+			require.NoError(t, err)
+			require.Len(t, txns, len(tc.expect))
+			for i, txn := range txns {
+				require.Equal(t, tc.expect[i].Hash(), txn.Txn.Hash())
+			}
+		})
+	}
+}
+
+func TestChainedUnconfirmedInputs(t *testing.T) {
+	v, txns, shutdown := prepareSearchTestVisor(t)
+	defer shutdown()
+
+	// A -> B, spending the output of the unconfirmed txn4, which is not in the historydb
+	ux := coin.UxOut{
+		Body: coin.UxBody{
+			SrcTransaction: txns.txn4.Hash(),
+			Address:        txns.addrA,
+			Coins:          txns.txn4.Out[0].Coins,
+			Hours:          txns.txn4.Out[0].Hours,
+		},
+	}
+
+	txn5 := coin.Transaction{}
+	txn5.PushInput(ux.Hash())
+	txn5.PushOutput(txns.addrB, ux.Body.Coins, ux.Body.Hours/4)
+	txn5.SignInputs([]cipher.SecKey{txns.secA})
+	txn5.UpdateHeader()
+
+	t.Run("verify", func(t *testing.T) {
+		balances, isConfirmed, err := v.VerifyTxnVerbose(&txn5)
+		require.NoError(t, err)
+		require.False(t, isConfirmed)
+		require.Len(t, balances, 1)
+		require.Equal(t, ux.Hash(), balances[0].Hash)
+		require.Equal(t, txns.addrA, balances[0].Address)
+	})
+
+	_, err := v.InjectTransactionStrict(txn5)
+	require.NoError(t, err)
+
+	t.Run("transactions", func(t *testing.T) {
+		result, err := v.GetTransactions(AddrsFilter([]cipher.Address{txns.addrB}), InputAddrsFilter([]cipher.Address{txns.addrA}))
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, txns.txn3.Hash(), result[0].Txn.Hash())
+		require.Equal(t, txn5.Hash(), result[1].Txn.Hash())
+	})
+
+	t.Run("ledger", func(t *testing.T) {
+		entries, err := v.GetLedger([]cipher.Address{txns.addrA}, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+
+		var e *LedgerEntry
+		for i := range entries {
+			if entries[i].Txid == txn5.Hash() {
+				e = &entries[i]
+			}
+		}
+		require.NotNil(t, e)
+		require.Equal(t, LedgerOutgoing, e.Type)
+		require.Equal(t, ux.Body.Coins, e.CoinsSpent)
+		require.Equal(t, []cipher.Address{txns.addrB}, e.Counterparties)
+	})
+}
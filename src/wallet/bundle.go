@@ -0,0 +1,186 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encrypt"
+)
+
+// BundleVersion is the version of the wallet bundle format
+const BundleVersion = "1"
+
+var (
+	// ErrInvalidBundle is returned if a bundle can not be decrypted or does not hold a valid wallet
+	ErrInvalidBundle = NewError(errors.New("invalid wallet bundle"))
+	// ErrUnsupportedBundleVersion is returned if a bundle was made by a newer version
+	ErrUnsupportedBundleVersion = NewError(errors.New("unsupported wallet bundle version"))
+)
+
+// bundleCryptoType is the crypto type of exported bundles, regardless of the wallet's crypto type
+const bundleCryptoType = CryptoTypeScryptChacha20poly1305
+
+// Bundle is a portable copy of a wallet.
+// The notes, labels and contacts of a watch-only bundle are in Metadata. Otherwise they stay in the wallet,
+// encrypted with its secrets if the wallet is encrypted.
+type Bundle struct {
+	Wallet   ReadableWallet `json:"wallet"`
+	Metadata *Metadata      `json:"metadata,omitempty"`
+}
+
+// EncryptedBundle is a password encrypted Bundle, the format of exported wallets.
+// Data is the encrypted JSON of the Bundle.
+type EncryptedBundle struct {
+	Version    string     `json:"version"`
+	CryptoType CryptoType `json:"crypto_type"`
+	WatchOnly  bool       `json:"watch_only"`
+	Data       string     `json:"data"`
+}
+
+// NewBundle creates a Bundle of a wallet. If watchOnly is true, the seeds and secret keys are left out,
+// an encrypted wallet is exported unencrypted, and m, the metadata of the wallet, is bundled separately
+// since it is erased with the secrets of an encrypted wallet. m is ignored if watchOnly is false.
+func NewBundle(w *Wallet, m *Metadata, watchOnly bool) *Bundle {
+	rw := NewReadableWallet(w)
+
+	if !watchOnly {
+		return &Bundle{
+			Wallet: *rw,
+		}
+	}
+
+	rw.Erase()
+	rw.Meta[metaSeed] = ""
+	rw.Meta[metaLastSeed] = ""
+	rw.Meta[metaEncrypted] = "false"
+	rw.Meta[metaWatchOnly] = "true"
+	delete(rw.Meta, metaCryptoType)
+	delete(rw.Meta, metaScryptN)
+	delete(rw.Meta, metaScryptR)
+	delete(rw.Meta, metaScryptP)
+	delete(rw.Meta, metaMetadata)
+
+	if m == nil {
+		m = NewMetadata()
+	}
+
+	return &Bundle{
+		Wallet:   *rw,
+		Metadata: m,
+	}
+}
+
+// Encrypt encrypts the bundle with password
+func (b *Bundle) Encrypt(password []byte) (*EncryptedBundle, error) {
+	if len(password) == 0 {
+		return nil, ErrMissingPassword
+	}
+
+	d, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	crypto, err := getCrypto(bundleCryptoType)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := crypto.Encrypt(d, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedBundle{
+		Version:    BundleVersion,
+		CryptoType: bundleCryptoType,
+		WatchOnly:  b.Wallet.Meta[metaWatchOnly] == "true",
+		Data:       string(data),
+	}, nil
+}
+
+// Decrypt decrypts the bundle with password and validates its wallet.
+// Returns ErrInvalidScryptParams if the bundle was encrypted with scrypt parameters above MaxScryptMemory or MaxScryptP.
+// The metadata of a watch-only bundle is set in the returned wallet.
+func (eb *EncryptedBundle) Decrypt(password []byte) (*Bundle, *Wallet, error) {
+	if len(password) == 0 {
+		return nil, nil, ErrMissingPassword
+	}
+
+	if eb.Version != BundleVersion {
+		return nil, nil, ErrUnsupportedBundleVersion
+	}
+
+	crypto, err := getCrypto(eb.CryptoType)
+	if err != nil {
+		return nil, nil, ErrInvalidBundle
+	}
+
+	// The scrypt parameters of the bundle are bounded by the crypto before the key is derived
+	d, err := crypto.Decrypt([]byte(eb.Data), password)
+	switch err {
+	case nil:
+	case encrypt.ErrScryptParamsOutOfBounds:
+		return nil, nil, ErrInvalidScryptParams
+	default:
+		return nil, nil, ErrInvalidPassword
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(d, &b); err != nil {
+		return nil, nil, ErrInvalidBundle
+	}
+
+	if b.Wallet.Meta == nil {
+		return nil, nil, ErrInvalidBundle
+	}
+
+	w, err := b.Wallet.ToWallet()
+	if err != nil {
+		return nil, nil, NewError(err)
+	}
+
+	if len(w.Entries) == 0 {
+		return nil, nil, ErrInvalidBundle
+	}
+
+	if w.IsWatchOnly() != eb.WatchOnly {
+		return nil, nil, ErrInvalidBundle
+	}
+
+	if w.IsWatchOnly() {
+		if w.seed() != "" || w.lastSeed() != "" {
+			return nil, nil, ErrInvalidBundle
+		}
+
+		for _, e := range w.Entries {
+			if e.Secret != (cipher.SecKey{}) {
+				return nil, nil, ErrInvalidBundle
+			}
+		}
+
+		if b.Metadata == nil {
+			return nil, nil, ErrInvalidBundle
+		}
+
+		if err := b.Metadata.validate(); err != nil {
+			return nil, nil, ErrInvalidBundle
+		}
+
+		if err := w.setMetadata(b.Metadata); err != nil {
+			return nil, nil, err
+		}
+	} else if b.Metadata != nil {
+		return nil, nil, ErrInvalidBundle
+	}
+
+	return &b, w, nil
+}
+
+// isValidWalletFilename checks that a filename from a bundle names a wallet file in the wallet directory
+func isValidWalletFilename(name string) bool {
+	return name != "" && filepath.Base(name) == name && strings.HasSuffix(name, "."+WalletExt)
+}
@@ -0,0 +1,200 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestBundleEncryptDecrypt(t *testing.T) {
+	txid := cipher.SumSHA256([]byte("txn"))
+
+	tt := []struct {
+		name      string
+		opts      Options
+		watchOnly bool
+		password  []byte
+		decryptPw []byte
+		expectErr error
+	}{
+		{
+			name: "unencrypted wallet",
+			opts: Options{
+				Seed: "seed",
+			},
+			password:  []byte("pwd"),
+			decryptPw: []byte("pwd"),
+		},
+		{
+			name: "encrypted wallet",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("wltpwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			password:  []byte("pwd"),
+			decryptPw: []byte("pwd"),
+		},
+		{
+			name: "watch-only",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("wltpwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			watchOnly: true,
+			password:  []byte("pwd"),
+			decryptPw: []byte("pwd"),
+		},
+		{
+			name: "wrong password",
+			opts: Options{
+				Seed: "seed",
+			},
+			password:  []byte("pwd"),
+			decryptPw: []byte("wrong"),
+			expectErr: ErrInvalidPassword,
+		},
+		{
+			name: "missing password",
+			opts: Options{
+				Seed: "seed",
+			},
+			password:  []byte("pwd"),
+			expectErr: ErrMissingPassword,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := NewWallet("test.wlt", tc.opts)
+			require.NoError(t, err)
+
+			err = w.UpdateMetadata(tc.opts.Password, func(m *Metadata) error {
+				m.SetNote(txid, "rent")
+				m.SetLabel(w.Entries[0].Address, "savings")
+				return nil
+			})
+			require.NoError(t, err)
+
+			m, err := w.ViewMetadata(tc.opts.Password)
+			require.NoError(t, err)
+
+			eb, err := NewBundle(w, m, tc.watchOnly).Encrypt(tc.password)
+			require.NoError(t, err)
+			require.Equal(t, BundleVersion, eb.Version)
+			require.Equal(t, CryptoTypeScryptChacha20poly1305, eb.CryptoType)
+			require.Equal(t, tc.watchOnly, eb.WatchOnly)
+
+			b, w2, err := eb.Decrypt(tc.decryptPw)
+			require.Equal(t, tc.expectErr, err)
+			if err != nil {
+				return
+			}
+
+			require.Equal(t, tc.watchOnly, w2.IsWatchOnly())
+			require.Equal(t, w.Filename(), w2.Filename())
+			require.Equal(t, w.GetAddresses(), w2.GetAddresses())
+
+			if tc.watchOnly {
+				require.Equal(t, m, b.Metadata)
+				require.False(t, w2.IsEncrypted())
+				checkNoSensitiveData(t, w2)
+
+				// The metadata is kept in the clear, even if the wallet was encrypted
+				m2, err := w2.ViewMetadata(nil)
+				require.NoError(t, err)
+				require.Equal(t, m, m2)
+
+				_, err = w2.GenerateAddresses(1)
+				require.Equal(t, ErrWalletWatchOnly, err)
+				return
+			}
+
+			require.Nil(t, b.Metadata)
+			require.Equal(t, w.IsEncrypted(), w2.IsEncrypted())
+			require.Equal(t, w.seed(), w2.seed())
+			require.Equal(t, w.Entries, w2.Entries)
+
+			m2, err := w2.ViewMetadata(tc.opts.Password)
+			require.NoError(t, err)
+			require.Equal(t, m, m2)
+		})
+	}
+}
+
+func TestBundleDecryptInvalid(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+
+	eb, err := NewBundle(w, nil, false).Encrypt([]byte("pwd"))
+	require.NoError(t, err)
+
+	badVersion := *eb
+	badVersion.Version = "2"
+	_, _, err = badVersion.Decrypt([]byte("pwd"))
+	require.Equal(t, ErrUnsupportedBundleVersion, err)
+
+	// The scrypt parameters of the encrypted data are bounded
+	badScrypt := *eb
+	badScrypt.Data = setScryptHeaderN(t, eb.Data, 1<<30)
+	_, _, err = badScrypt.Decrypt([]byte("pwd"))
+	require.Equal(t, ErrInvalidScryptParams, err)
+
+	badCrypto := *eb
+	badCrypto.CryptoType = "foo"
+	_, _, err = badCrypto.Decrypt([]byte("pwd"))
+	require.Equal(t, ErrInvalidBundle, err)
+
+	// The watch-only flag must match the wallet
+	badWatchOnly := *eb
+	badWatchOnly.WatchOnly = true
+	_, _, err = badWatchOnly.Decrypt([]byte("pwd"))
+	require.Equal(t, ErrInvalidBundle, err)
+
+	// A watch-only wallet must not hold secrets
+	b := NewBundle(w, nil, false)
+	b.Wallet.Meta[metaWatchOnly] = "true"
+	eb, err = b.Encrypt([]byte("pwd"))
+	require.NoError(t, err)
+	_, _, err = eb.Decrypt([]byte("pwd"))
+	require.Equal(t, ErrInvalidBundle, err)
+
+	// Only a watch-only bundle has metadata
+	b = NewBundle(w, nil, false)
+	b.Metadata = NewMetadata()
+	eb, err = b.Encrypt([]byte("pwd"))
+	require.NoError(t, err)
+	_, _, err = eb.Decrypt([]byte("pwd"))
+	require.Equal(t, ErrInvalidBundle, err)
+
+	// A watch-only bundle must have metadata
+	b = NewBundle(w, nil, true)
+	b.Metadata = nil
+	eb, err = b.Encrypt([]byte("pwd"))
+	require.NoError(t, err)
+	_, _, err = eb.Decrypt([]byte("pwd"))
+	require.Equal(t, ErrInvalidBundle, err)
+
+	// The metadata must be valid
+	b = NewBundle(w, nil, true)
+	b.Metadata.Labels["foo"] = "bar"
+	eb, err = b.Encrypt([]byte("pwd"))
+	require.NoError(t, err)
+	_, _, err = eb.Decrypt([]byte("pwd"))
+	require.Equal(t, ErrInvalidBundle, err)
+}
+
+func TestIsValidWalletFilename(t *testing.T) {
+	require.True(t, isValidWalletFilename("foo.wlt"))
+	require.False(t, isValidWalletFilename(""))
+	require.False(t, isValidWalletFilename("foo.txt"))
+	require.False(t, isValidWalletFilename("../foo.wlt"))
+	require.False(t, isValidWalletFilename("a/foo.wlt"))
+}
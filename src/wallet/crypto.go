@@ -49,3 +49,71 @@ func getCrypto(cryptoType CryptoType) (cryptor, error) {
 
 	return c, nil
 }
+
+// ScryptParams are the scrypt key derivation parameters of scrypt-chacha20poly1305 encryption
+type ScryptParams struct {
+	N int
+	R int
+	P int
+}
+
+// DefaultScryptParams are the scrypt parameters of the default scrypt-chacha20poly1305 crypto
+var DefaultScryptParams = ScryptParams{
+	N: encrypt.ScryptN,
+	R: encrypt.ScryptR,
+	P: encrypt.ScryptP,
+}
+
+// Bounds of the scrypt parameters
+const (
+	// MinScryptN is the minimum scrypt N of a wallet encryption, so that the security of a wallet can't be lowered below it
+	MinScryptN = 1 << 14
+	// MaxScryptMemory is the maximum memory that scrypt uses to derive a key, 128*N*r bytes.
+	// It is the memory of the default parameters, 1 GiB. Decrypt refuses data encrypted with more.
+	MaxScryptMemory = encrypt.ScryptMaxMemory
+	// MaxScryptP is the maximum scrypt p. The key derivation time is proportional to p.
+	MaxScryptP = encrypt.ScryptMaxP
+)
+
+// Validate checks that the parameters can be used to encrypt a wallet:
+// N must be a power of 2 of at least MinScryptN, r must be positive, p must be between 1 and MaxScryptP,
+// and scrypt must not use more than MaxScryptMemory
+func (sp ScryptParams) Validate() error {
+	if sp.N < MinScryptN || sp.N&(sp.N-1) != 0 {
+		return ErrInvalidScryptParams
+	}
+
+	return sp.validateCost()
+}
+
+// validateCost checks that scrypt does not use more memory or time than allowed with the parameters.
+// It is checked before decrypting a wallet, the wallets encrypted before MinScryptN was set can still be decrypted.
+func (sp ScryptParams) validateCost() error {
+	if sp.N <= 1 || sp.R <= 0 || sp.P <= 0 || sp.P > MaxScryptP {
+		return ErrInvalidScryptParams
+	}
+
+	if uint64(sp.N) > MaxScryptMemory/128/uint64(sp.R) {
+		return ErrInvalidScryptParams
+	}
+
+	return nil
+}
+
+// getCryptoWithScryptParams gets crypto of given type, using the scrypt parameters sp for scrypt-chacha20poly1305
+func getCryptoWithScryptParams(cryptoType CryptoType, sp ScryptParams) (cryptor, error) {
+	if cryptoType != CryptoTypeScryptChacha20poly1305 {
+		return getCrypto(cryptoType)
+	}
+
+	if err := sp.Validate(); err != nil {
+		return nil, err
+	}
+
+	return encrypt.ScryptChacha20poly1305{
+		N:      sp.N,
+		R:      sp.R,
+		P:      sp.P,
+		KeyLen: encrypt.ScryptKeyLen,
+	}, nil
+}
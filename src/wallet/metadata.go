@@ -0,0 +1,191 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+var (
+	// ErrMissingContactName is returned if a contact is added without a name
+	ErrMissingContactName = NewError(errors.New("missing contact name"))
+	// ErrContactNotExist is returned if a contact does not exist in the address book
+	ErrContactNotExist = NewError(errors.New("contact doesn't exist"))
+)
+
+// Metadata is the user metadata of a wallet: notes on transactions, labels of the wallet's addresses
+// and an address book of contacts.
+// It is stored in the wallet file, and is encrypted with the secrets of an encrypted wallet.
+type Metadata struct {
+	Notes    map[string]string `json:"notes"`    // transaction id -> note
+	Labels   map[string]string `json:"labels"`   // address -> label
+	Contacts map[string]string `json:"contacts"` // contact name -> address
+}
+
+// NewMetadata creates an empty Metadata
+func NewMetadata() *Metadata {
+	return &Metadata{
+		Notes:    make(map[string]string),
+		Labels:   make(map[string]string),
+		Contacts: make(map[string]string),
+	}
+}
+
+// IsEmpty returns true if the metadata has no notes, labels or contacts
+func (m *Metadata) IsEmpty() bool {
+	return len(m.Notes) == 0 && len(m.Labels) == 0 && len(m.Contacts) == 0
+}
+
+// SetNote sets the note of a transaction. An empty note deletes the note.
+func (m *Metadata) SetNote(txid cipher.SHA256, note string) {
+	if note == "" {
+		delete(m.Notes, txid.Hex())
+		return
+	}
+	m.Notes[txid.Hex()] = note
+}
+
+// SetLabel sets the label of an address. An empty label deletes the label.
+func (m *Metadata) SetLabel(addr cipher.Address, label string) {
+	if label == "" {
+		delete(m.Labels, addr.String())
+		return
+	}
+	m.Labels[addr.String()] = label
+}
+
+// SetContact adds a contact to the address book, or changes the address of an existing contact
+func (m *Metadata) SetContact(name string, addr cipher.Address) error {
+	if name == "" {
+		return ErrMissingContactName
+	}
+	m.Contacts[name] = addr.String()
+	return nil
+}
+
+// DeleteContact removes a contact from the address book
+func (m *Metadata) DeleteContact(name string) error {
+	if _, ok := m.Contacts[name]; !ok {
+		return ErrContactNotExist
+	}
+	delete(m.Contacts, name)
+	return nil
+}
+
+func (m *Metadata) validate() error {
+	for txid := range m.Notes {
+		if _, err := cipher.SHA256FromHex(txid); err != nil {
+			return fmt.Errorf("invalid note transaction id %q: %v", txid, err)
+		}
+	}
+
+	for addr := range m.Labels {
+		if _, err := cipher.DecodeBase58Address(addr); err != nil {
+			return fmt.Errorf("invalid label address %q: %v", addr, err)
+		}
+	}
+
+	for name, addr := range m.Contacts {
+		if name == "" {
+			return errors.New("invalid contact with empty name")
+		}
+		if _, err := cipher.DecodeBase58Address(addr); err != nil {
+			return fmt.Errorf("invalid address of contact %q: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// metadata returns the metadata of an unencrypted or unlocked wallet
+func (w *Wallet) metadata() (*Metadata, error) {
+	if w.IsEncrypted() {
+		return nil, ErrWalletEncrypted
+	}
+
+	m := NewMetadata()
+	s, ok := w.Meta[metaMetadata]
+	if !ok || s == "" {
+		return m, nil
+	}
+
+	if err := json.Unmarshal([]byte(s), m); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %v", err)
+	}
+
+	// JSON null leaves the maps nil
+	if m.Notes == nil {
+		m.Notes = make(map[string]string)
+	}
+	if m.Labels == nil {
+		m.Labels = make(map[string]string)
+	}
+	if m.Contacts == nil {
+		m.Contacts = make(map[string]string)
+	}
+
+	return m, nil
+}
+
+// setMetadata sets the metadata of an unencrypted or unlocked wallet
+func (w *Wallet) setMetadata(m *Metadata) error {
+	if w.IsEncrypted() {
+		return ErrWalletEncrypted
+	}
+
+	if m.IsEmpty() {
+		delete(w.Meta, metaMetadata)
+		return nil
+	}
+
+	d, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	w.Meta[metaMetadata] = string(d)
+	return nil
+}
+
+// ViewMetadata returns the metadata of the wallet. The password is required to decrypt the metadata of an encrypted wallet.
+func (w *Wallet) ViewMetadata(password []byte) (*Metadata, error) {
+	if !w.IsEncrypted() {
+		return w.metadata()
+	}
+
+	var m *Metadata
+	if err := w.GuardView(password, func(wlt *Wallet) error {
+		var err error
+		m, err = wlt.metadata()
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// UpdateMetadata updates the metadata of the wallet with fn. The metadata of an encrypted wallet is
+// decrypted with password, and encrypted again after the update.
+func (w *Wallet) UpdateMetadata(password []byte, fn func(m *Metadata) error) error {
+	update := func(wlt *Wallet) error {
+		m, err := wlt.metadata()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(m); err != nil {
+			return err
+		}
+
+		return wlt.setMetadata(m)
+	}
+
+	if !w.IsEncrypted() {
+		return update(w)
+	}
+
+	return w.GuardUpdate(password, update)
+}
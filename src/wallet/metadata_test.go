@@ -0,0 +1,125 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestMetadata(t *testing.T) {
+	m := NewMetadata()
+	require.True(t, m.IsEmpty())
+
+	txid := testutil.RandSHA256(t)
+	addr := testutil.MakeAddress()
+
+	m.SetNote(txid, "note")
+	m.SetLabel(addr, "label")
+	require.NoError(t, m.SetContact("alice", addr))
+	require.False(t, m.IsEmpty())
+	require.Equal(t, "note", m.Notes[txid.Hex()])
+	require.Equal(t, "label", m.Labels[addr.String()])
+	require.Equal(t, addr.String(), m.Contacts["alice"])
+	require.NoError(t, m.validate())
+
+	require.Equal(t, ErrMissingContactName, m.SetContact("", addr))
+	require.Equal(t, ErrContactNotExist, m.DeleteContact("bob"))
+
+	m.SetNote(txid, "")
+	m.SetLabel(addr, "")
+	require.NoError(t, m.DeleteContact("alice"))
+	require.True(t, m.IsEmpty())
+
+	m.Notes["abc"] = "note"
+	require.Error(t, m.validate())
+}
+
+func TestWalletMetadata(t *testing.T) {
+	txid := testutil.RandSHA256(t)
+	contact := testutil.MakeAddress()
+
+	for ct := range cryptoTable {
+		t.Run(string(ct), func(t *testing.T) {
+			w, err := NewWallet("t.wlt", Options{
+				Seed: "seed",
+			})
+			require.NoError(t, err)
+			addr := w.Entries[0].Address
+
+			// Updates the metadata of the unencrypted wallet
+			err = w.UpdateMetadata(nil, func(m *Metadata) error {
+				m.SetNote(txid, "note")
+				m.SetLabel(addr, "label")
+				return m.SetContact("alice", contact)
+			})
+			require.NoError(t, err)
+			require.NoError(t, w.Validate())
+
+			m, err := w.ViewMetadata(nil)
+			require.NoError(t, err)
+			require.Equal(t, "note", m.Notes[txid.Hex()])
+			require.Equal(t, "label", m.Labels[addr.String()])
+			require.Equal(t, contact.String(), m.Contacts["alice"])
+
+			// The metadata is encrypted with the secrets
+			require.NoError(t, w.Lock([]byte("pwd"), ct))
+			require.NotContains(t, w.Meta, metaMetadata)
+			require.NoError(t, w.Validate())
+
+			_, err = w.ViewMetadata(nil)
+			require.Equal(t, ErrMissingPassword, err)
+			_, err = w.ViewMetadata([]byte("wrong"))
+			require.Equal(t, ErrInvalidPassword, err)
+
+			m2, err := w.ViewMetadata([]byte("pwd"))
+			require.NoError(t, err)
+			require.Equal(t, m, m2)
+
+			// Updates the metadata of the encrypted wallet
+			err = w.UpdateMetadata([]byte("pwd"), func(m *Metadata) error {
+				return m.DeleteContact("alice")
+			})
+			require.NoError(t, err)
+			require.True(t, w.IsEncrypted())
+			require.NotContains(t, w.Meta, metaMetadata)
+			checkNoSensitiveData(t, w)
+
+			err = w.UpdateMetadata([]byte("pwd"), func(m *Metadata) error {
+				return m.DeleteContact("alice")
+			})
+			require.Equal(t, ErrContactNotExist, err)
+
+			// The metadata is restored by unlocking
+			w2, err := w.Unlock([]byte("pwd"))
+			require.NoError(t, err)
+			m3, err := w2.ViewMetadata(nil)
+			require.NoError(t, err)
+			require.Equal(t, "note", m3.Notes[txid.Hex()])
+			require.Equal(t, "label", m3.Labels[addr.String()])
+			require.Empty(t, m3.Contacts)
+		})
+	}
+}
+
+func TestWalletValidateMetadata(t *testing.T) {
+	w, err := NewWallet("t.wlt", Options{
+		Seed: "seed",
+	})
+	require.NoError(t, err)
+
+	w.Meta[metaMetadata] = "{"
+	require.Error(t, w.Validate())
+
+	w.Meta[metaMetadata] = `{"labels":{"foo":"label"}}`
+	require.Error(t, w.Validate())
+
+	w.Meta[metaMetadata] = `{"contacts":{"alice":"` + w.Entries[0].Address.String() + `"}}`
+	require.NoError(t, w.Validate())
+
+	// Encrypted wallets must not have unencrypted metadata
+	require.NoError(t, w.Lock([]byte("pwd"), CryptoTypeSha256Xor))
+	w.Meta[metaMetadata] = "{}"
+	require.Error(t, w.Validate())
+}
@@ -6,6 +6,7 @@ import "encoding/json"
 const (
 	secretSeed     = "seed"
 	secretLastSeed = "lastSeed"
+	secretMetadata = "metadata"
 )
 
 type secrets map[string]string
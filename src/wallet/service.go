@@ -192,6 +192,118 @@ func (serv *Service) DecryptWallet(wltID string, password []byte) (*Wallet, erro
 	return unlockWlt, nil
 }
 
+// ChangeWalletPassword re-encrypts an encrypted wallet with a new password and/or crypto type,
+// without saving it decrypted
+func (serv *Service) ChangeWalletPassword(wltID string, password []byte, opts ChangePasswordOptions) (*Wallet, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.enableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.ChangePassword(password, opts); err != nil {
+		return nil, err
+	}
+
+	// Save to disk first
+	if err := w.Save(serv.walletDirectory); err != nil {
+		return nil, err
+	}
+
+	// Sets the re-encrypted wallet
+	serv.wallets.set(w)
+	return w, nil
+}
+
+// GetWalletMetadata returns the notes, address labels and contacts of a wallet.
+// The password is required if the wallet is encrypted.
+func (serv *Service) GetWalletMetadata(wltID string, password []byte) (*Metadata, error) {
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.enableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.ViewMetadata(password)
+}
+
+// SetWalletNote sets the note of a transaction in a wallet. An empty note deletes the note.
+func (serv *Service) SetWalletNote(wltID string, password []byte, txid cipher.SHA256, note string) (*Metadata, error) {
+	return serv.updateWalletMetadata(wltID, password, func(w *Wallet, m *Metadata) error {
+		m.SetNote(txid, note)
+		return nil
+	})
+}
+
+// SetWalletAddressLabel sets the label of an address in a wallet. An empty label deletes the label.
+func (serv *Service) SetWalletAddressLabel(wltID string, password []byte, addr cipher.Address, label string) (*Metadata, error) {
+	return serv.updateWalletMetadata(wltID, password, func(w *Wallet, m *Metadata) error {
+		if _, ok := w.GetEntry(addr); !ok {
+			return ErrUnknownAddress
+		}
+
+		m.SetLabel(addr, label)
+		return nil
+	})
+}
+
+// SetWalletContact adds a contact to the address book of a wallet, or changes the address of a contact
+func (serv *Service) SetWalletContact(wltID string, password []byte, name string, addr cipher.Address) (*Metadata, error) {
+	return serv.updateWalletMetadata(wltID, password, func(w *Wallet, m *Metadata) error {
+		return m.SetContact(name, addr)
+	})
+}
+
+// DeleteWalletContact removes a contact from the address book of a wallet
+func (serv *Service) DeleteWalletContact(wltID string, password []byte, name string) (*Metadata, error) {
+	return serv.updateWalletMetadata(wltID, password, func(w *Wallet, m *Metadata) error {
+		return m.DeleteContact(name)
+	})
+}
+
+// updateWalletMetadata updates the metadata of a wallet with fn and saves the wallet
+func (serv *Service) updateWalletMetadata(wltID string, password []byte, fn func(w *Wallet, m *Metadata) error) (*Metadata, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.enableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata *Metadata
+	if err := w.UpdateMetadata(password, func(m *Metadata) error {
+		if err := fn(w, m); err != nil {
+			return err
+		}
+		metadata = m
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Save to disk first
+	if err := w.Save(serv.walletDirectory); err != nil {
+		return nil, err
+	}
+
+	serv.wallets.set(w)
+	return metadata, nil
+}
+
 // NewAddresses generate address entries in given wallet,
 // return nil if wallet does not exist.
 // Set password as nil if the wallet is not encrypted, otherwise the password must be provided.
@@ -431,6 +543,74 @@ func (serv *Service) Remove(wltID string) error {
 	return nil
 }
 
+// ExportWallet exports the wallet of given id, with its notes, labels and contacts, to a bundle encrypted with password.
+// If watchOnly is true, the seeds and secret keys are left out.
+// An encrypted wallet is exported with its secrets still encrypted with the wallet password,
+// unless watchOnly is true. Then walletPassword is required to decrypt the metadata of the wallet.
+func (serv *Service) ExportWallet(wltID string, password, walletPassword []byte, watchOnly bool) (*EncryptedBundle, error) {
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.enableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *Metadata
+	if watchOnly {
+		m, err = w.ViewMetadata(walletPassword)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewBundle(w, m, watchOnly).Encrypt(password)
+}
+
+// ImportWallet restores a wallet, with its notes, labels and contacts, from a bundle encrypted with password.
+// Returns ErrSeedUsed if a loaded wallet has the same first address.
+// The wallet keeps the filename it was exported with, unless a wallet with that filename is loaded.
+func (serv *Service) ImportWallet(eb EncryptedBundle, password []byte) (*Wallet, error) {
+	if !serv.enableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	// Deriving the key of the bundle can take seconds, so it is decrypted before taking the lock
+	_, w, err := eb.Decrypt(password)
+	if err != nil {
+		return nil, err
+	}
+
+	serv.Lock()
+	defer serv.Unlock()
+
+	// Check for duplicate wallets by initial seed, the same check as removeDup
+	if _, ok := serv.firstAddrIDMap[w.Entries[0].Address.String()]; ok {
+		return nil, ErrSeedUsed
+	}
+
+	if _, ok := serv.wallets.get(w.Filename()); ok || !isValidWalletFilename(w.Filename()) {
+		w.setFilename(serv.generateUniqueWalletFilename())
+	}
+
+	if err := serv.wallets.add(w); err != nil {
+		return nil, err
+	}
+
+	if err := w.Save(serv.walletDirectory); err != nil {
+		// If save fails, remove the added wallet
+		serv.wallets.remove(w.Filename())
+		return nil, err
+	}
+
+	serv.firstAddrIDMap[w.Entries[0].Address.String()] = w.Filename()
+
+	return w.clone(), nil
+}
+
 func (serv *Service) removeDup(wlts Wallets) Wallets {
 	var rmWltIDS []string
 	// remove dup wallets
@@ -472,6 +652,13 @@ func (serv *Service) removeDup(wlts Wallets) Wallets {
 	return wlts
 }
 
+// SetEnableSeedAPI enables or disables GetWalletSeed
+func (serv *Service) SetEnableSeedAPI(enable bool) {
+	serv.Lock()
+	defer serv.Unlock()
+	serv.enableSeedAPI = enable
+}
+
 // GetWalletSeed returns seed of encrypted wallet of given wallet id
 // Returns ErrWalletNotEncrypted if it's not encrypted
 func (serv *Service) GetWalletSeed(wltID string, password []byte) (string, error) {
@@ -2586,3 +2586,272 @@ func checkNoSensitiveData(t *testing.T, w *Wallet) {
 		require.Equal(t, empty, e.Secret)
 	}
 }
+
+func TestServiceExportImportWallet(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	w, err := s.CreateWallet("t.wlt", Options{
+		Seed:  "seed",
+		Label: "label",
+	}, nil)
+	require.NoError(t, err)
+
+	txid := cipher.SumSHA256([]byte("txn"))
+	_, err = s.SetWalletNote("t.wlt", nil, txid, "rent")
+	require.NoError(t, err)
+	m, err := s.SetWalletAddressLabel("t.wlt", nil, w.Entries[0].Address, "savings")
+	require.NoError(t, err)
+
+	_, err = s.ExportWallet("none-exist.wlt", []byte("pwd"), nil, false)
+	require.Equal(t, ErrWalletNotExist, err)
+
+	eb, err := s.ExportWallet("t.wlt", []byte("pwd"), nil, false)
+	require.NoError(t, err)
+
+	// Importing into the same service finds the wallet's seed is in use
+	_, err = s.ImportWallet(*eb, []byte("pwd"))
+	require.Equal(t, ErrSeedUsed, err)
+
+	dir2 := prepareWltDir()
+	s2, err := NewService(Config{
+		WalletDir:       dir2,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s2.ImportWallet(*eb, []byte("wrong"))
+	require.Equal(t, ErrInvalidPassword, err)
+
+	w2, err := s2.ImportWallet(*eb, []byte("pwd"))
+	require.NoError(t, err)
+	require.Equal(t, "t.wlt", w2.Filename())
+	require.Equal(t, w.GetAddresses(), w2.GetAddresses())
+	require.Equal(t, w.seed(), w2.seed())
+
+	// The wallet is saved and loaded
+	w3, err := s2.GetWallet("t.wlt")
+	require.NoError(t, err)
+	require.Equal(t, w2, w3)
+	w3, err = Load(filepath.Join(dir2, "t.wlt"))
+	require.NoError(t, err)
+	require.Equal(t, w.GetAddresses(), w3.GetAddresses())
+
+	// The metadata is imported with the wallet
+	m2, err := s2.GetWalletMetadata("t.wlt", nil)
+	require.NoError(t, err)
+	require.Equal(t, m, m2)
+
+	// A watch-only copy with a conflicting filename gets a new filename
+	dir3 := prepareWltDir()
+	s3, err := NewService(Config{
+		WalletDir:       dir3,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+	_, err = s3.CreateWallet("t.wlt", Options{
+		Seed: "other seed",
+	}, nil)
+	require.NoError(t, err)
+
+	// The metadata of an encrypted wallet is decrypted with the wallet password
+	_, err = s.EncryptWallet("t.wlt", []byte("wallet pwd"))
+	require.NoError(t, err)
+
+	_, err = s.ExportWallet("t.wlt", []byte("pwd"), nil, true)
+	require.Equal(t, ErrMissingPassword, err)
+
+	_, err = s.ExportWallet("t.wlt", []byte("pwd"), []byte("wrong"), true)
+	require.Equal(t, ErrInvalidPassword, err)
+
+	eb, err = s.ExportWallet("t.wlt", []byte("pwd"), []byte("wallet pwd"), true)
+	require.NoError(t, err)
+	require.True(t, eb.WatchOnly)
+
+	w4, err := s3.ImportWallet(*eb, []byte("pwd"))
+	require.NoError(t, err)
+	require.NotEqual(t, "t.wlt", w4.Filename())
+	require.True(t, w4.IsWatchOnly())
+	require.False(t, w4.IsEncrypted())
+	checkNoSensitiveData(t, w4)
+
+	m4, err := s3.GetWalletMetadata(w4.Filename(), nil)
+	require.NoError(t, err)
+	require.Equal(t, m, m4)
+
+	_, err = s3.NewAddresses(w4.Filename(), nil, 1)
+	require.Equal(t, ErrWalletWatchOnly, err)
+
+	// Wallet API disabled
+	s4, err := NewService(Config{
+		WalletDir:       prepareWltDir(),
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: false,
+	})
+	require.NoError(t, err)
+	_, err = s4.ExportWallet("t.wlt", []byte("pwd"), nil, false)
+	require.Equal(t, ErrWalletAPIDisabled, err)
+	_, err = s4.ImportWallet(*eb, []byte("pwd"))
+	require.Equal(t, ErrWalletAPIDisabled, err)
+}
+
+func TestServiceChangeWalletPassword(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("t.wlt", Options{
+		Seed:     "seed",
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = s.ChangeWalletPassword("none-exist.wlt", []byte("pwd"), ChangePasswordOptions{
+		NewPassword: []byte("newpwd"),
+	})
+	require.Equal(t, ErrWalletNotExist, err)
+
+	_, err = s.ChangeWalletPassword("t.wlt", []byte("wrong"), ChangePasswordOptions{
+		NewPassword: []byte("newpwd"),
+	})
+	require.Equal(t, ErrInvalidPassword, err)
+
+	sp := ScryptParams{N: MinScryptN, R: 8, P: 1}
+	w, err := s.ChangeWalletPassword("t.wlt", []byte("pwd"), ChangePasswordOptions{
+		NewPassword:  []byte("newpwd"),
+		CryptoType:   CryptoTypeScryptChacha20poly1305,
+		ScryptParams: &sp,
+	})
+	require.NoError(t, err)
+	require.True(t, w.IsEncrypted())
+	require.Equal(t, CryptoTypeScryptChacha20poly1305, w.cryptoType())
+	checkNoSensitiveData(t, w)
+
+	// The re-encrypted wallet is loaded and saved with its scrypt parameters
+	w1, err := s.GetWallet("t.wlt")
+	require.NoError(t, err)
+	require.Equal(t, w, w1)
+
+	w2, err := Load(filepath.Join(dir, "t.wlt"))
+	require.NoError(t, err)
+	require.True(t, w2.IsEncrypted())
+	checkNoSensitiveData(t, w2)
+	p, ok := w2.ScryptParams()
+	require.True(t, ok)
+	require.Equal(t, sp, p)
+
+	_, err = w2.Unlock([]byte("pwd"))
+	require.Equal(t, ErrInvalidPassword, err)
+	w3, err := w2.Unlock([]byte("newpwd"))
+	require.NoError(t, err)
+	require.Equal(t, "seed", w3.seed())
+
+	// Wallet API disabled
+	s2, err := NewService(Config{
+		WalletDir:       prepareWltDir(),
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: false,
+	})
+	require.NoError(t, err)
+	_, err = s2.ChangeWalletPassword("t.wlt", []byte("pwd"), ChangePasswordOptions{})
+	require.Equal(t, ErrWalletAPIDisabled, err)
+}
+
+func TestServiceWalletMetadata(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	w, err := s.CreateWallet("t.wlt", Options{
+		Seed:     "seed",
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	}, nil)
+	require.NoError(t, err)
+	addr := w.Entries[0].Address
+
+	txid := testutil.RandSHA256(t)
+	contact := testutil.MakeAddress()
+
+	_, err = s.SetWalletNote("none-exist.wlt", []byte("pwd"), txid, "note")
+	require.Equal(t, ErrWalletNotExist, err)
+
+	_, err = s.SetWalletNote("t.wlt", nil, txid, "note")
+	require.Equal(t, ErrMissingPassword, err)
+
+	_, err = s.SetWalletNote("t.wlt", []byte("wrong"), txid, "note")
+	require.Equal(t, ErrInvalidPassword, err)
+
+	m, err := s.SetWalletNote("t.wlt", []byte("pwd"), txid, "note")
+	require.NoError(t, err)
+	require.Equal(t, "note", m.Notes[txid.Hex()])
+
+	_, err = s.SetWalletAddressLabel("t.wlt", []byte("pwd"), contact, "label")
+	require.Equal(t, ErrUnknownAddress, err)
+
+	_, err = s.SetWalletAddressLabel("t.wlt", []byte("pwd"), addr, "label")
+	require.NoError(t, err)
+
+	_, err = s.SetWalletContact("t.wlt", []byte("pwd"), "alice", contact)
+	require.NoError(t, err)
+
+	_, err = s.SetWalletContact("t.wlt", []byte("pwd"), "bob", contact)
+	require.NoError(t, err)
+
+	m, err = s.DeleteWalletContact("t.wlt", []byte("pwd"), "bob")
+	require.NoError(t, err)
+
+	_, err = s.DeleteWalletContact("t.wlt", []byte("pwd"), "bob")
+	require.Equal(t, ErrContactNotExist, err)
+
+	expect := &Metadata{
+		Notes:    map[string]string{txid.Hex(): "note"},
+		Labels:   map[string]string{addr.String(): "label"},
+		Contacts: map[string]string{"alice": contact.String()},
+	}
+	require.Equal(t, expect, m)
+
+	_, err = s.GetWalletMetadata("t.wlt", nil)
+	require.Equal(t, ErrMissingPassword, err)
+
+	m, err = s.GetWalletMetadata("t.wlt", []byte("pwd"))
+	require.NoError(t, err)
+	require.Equal(t, expect, m)
+
+	// The wallet is saved with encrypted metadata
+	w, err = Load(filepath.Join(dir, "t.wlt"))
+	require.NoError(t, err)
+	require.True(t, w.IsEncrypted())
+	require.NotContains(t, w.Meta, metaMetadata)
+	m, err = w.ViewMetadata([]byte("pwd"))
+	require.NoError(t, err)
+	require.Equal(t, expect, m)
+
+	// Wallet API disabled
+	s2, err := NewService(Config{
+		WalletDir:       prepareWltDir(),
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: false,
+	})
+	require.NoError(t, err)
+	_, err = s2.GetWalletMetadata("t.wlt", nil)
+	require.Equal(t, ErrWalletAPIDisabled, err)
+	_, err = s2.SetWalletNote("t.wlt", nil, txid, "note")
+	require.Equal(t, ErrWalletAPIDisabled, err)
+}
@@ -16,6 +16,7 @@ import (
 	"encoding/hex"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encrypt"
 	"github.com/skycoin/skycoin/src/coin"
 
 	"github.com/shopspring/decimal"
@@ -89,6 +90,10 @@ var (
 	ErrUnknownUxOut = NewError(errors.New("uxout is not owned by any address in the wallet"))
 	// ErrNoUnspents is returned if a wallet has no unspents to spend
 	ErrNoUnspents = NewError(errors.New("no unspents to spend"))
+	// ErrWalletWatchOnly is returned if secret keys are needed from a watch-only wallet
+	ErrWalletWatchOnly = NewError(errors.New("wallet is watch-only"))
+	// ErrInvalidScryptParams is returned if scrypt parameters can not be used for key derivation
+	ErrInvalidScryptParams = NewError(errors.New("invalid scrypt parameters"))
 )
 
 const (
@@ -117,6 +122,11 @@ const (
 	metaSeed       = "seed"       // wallet seed
 	metaLastSeed   = "lastSeed"   // seed for generating next address
 	metaSecrets    = "secrets"    // secrets which records the encrypted seeds and secrets of address entries
+	metaWatchOnly  = "watchOnly"  // whether the wallet only has the public keys of its addresses
+	metaScryptN    = "scryptN"    // scrypt N parameter of scrypt-chacha20poly1305 encryption
+	metaScryptR    = "scryptR"    // scrypt r parameter of scrypt-chacha20poly1305 encryption
+	metaScryptP    = "scryptP"    // scrypt p parameter of scrypt-chacha20poly1305 encryption
+	metaMetadata   = "metadata"   // notes, address labels and contacts, moved to secrets if the wallet is encrypted
 )
 
 // CoinType represents the wallet coin type
@@ -161,6 +171,7 @@ type CreateTransactionWalletParams struct {
 // CreateTransactionParams defines control parameters for transaction construction
 type CreateTransactionParams struct {
 	IgnoreUnconfirmed bool
+	SpendUnconfirmed  bool // Spend outputs of unconfirmed transactions, implies IgnoreUnconfirmed
 	HoursSelection    HoursSelection
 	Wallet            CreateTransactionWalletParams
 	ChangeAddress     *cipher.Address
@@ -169,6 +180,11 @@ type CreateTransactionParams struct {
 
 // Validate validates CreateTransactionParams
 func (c CreateTransactionParams) Validate() error {
+	return c.validate(true)
+}
+
+// validate validates CreateTransactionParams. Wallet.ID is only checked if walletRequired
+func (c CreateTransactionParams) validate(walletRequired bool) error {
 	if c.ChangeAddress != nil && c.ChangeAddress.Null() {
 		return NewError(errors.New("ChangeAddress must not be the null address"))
 	}
@@ -201,7 +217,7 @@ func (c CreateTransactionParams) Validate() error {
 		return NewError(errors.New("To contains duplicate values"))
 	}
 
-	if c.Wallet.ID == "" {
+	if walletRequired && c.Wallet.ID == "" {
 		return NewError(errors.New("Wallet.ID is required"))
 	}
 
@@ -383,6 +399,11 @@ func NewWalletScanAhead(wltName string, opts Options, bg BalanceGetter) (*Wallet
 
 // Lock encrypts the wallet with the given password and specific crypto type
 func (w *Wallet) Lock(password []byte, cryptoType CryptoType) error {
+	return w.lock(password, cryptoType, DefaultScryptParams)
+}
+
+// lock encrypts the wallet like Lock, with the scrypt parameters sp if cryptoType is scrypt-chacha20poly1305
+func (w *Wallet) lock(password []byte, cryptoType CryptoType, sp ScryptParams) error {
 	if len(password) == 0 {
 		return ErrMissingPassword
 	}
@@ -391,6 +412,10 @@ func (w *Wallet) Lock(password []byte, cryptoType CryptoType) error {
 		return ErrWalletEncrypted
 	}
 
+	if w.IsWatchOnly() {
+		return ErrWalletWatchOnly
+	}
+
 	wlt := w.clone()
 
 	// Records seeds in secrets
@@ -409,12 +434,18 @@ func (w *Wallet) Lock(password []byte, cryptoType CryptoType) error {
 		ss.set(e.Address.String(), e.Secret.Hex())
 	}
 
+	// Moves the metadata to secrets
+	if md, ok := wlt.Meta[metaMetadata]; ok {
+		ss.set(secretMetadata, md)
+		delete(wlt.Meta, metaMetadata)
+	}
+
 	sb, err := ss.serialize()
 	if err != nil {
 		return err
 	}
 
-	crypto, err := getCrypto(cryptoType)
+	crypto, err := getCryptoWithScryptParams(cryptoType, sp)
 	if err != nil {
 		return err
 	}
@@ -428,6 +459,13 @@ func (w *Wallet) Lock(password []byte, cryptoType CryptoType) error {
 	// Sets the crypto type
 	wlt.setCryptoType(cryptoType)
 
+	// Records the scrypt parameters
+	if cryptoType == CryptoTypeScryptChacha20poly1305 {
+		wlt.setScryptParams(sp)
+	} else {
+		wlt.deleteScryptParams()
+	}
+
 	// Updates the secrets data in wallet
 	wlt.setSecrets(string(encSecret))
 
@@ -473,15 +511,27 @@ func (w *Wallet) Unlock(password []byte) (*Wallet, error) {
 		return nil, errors.New("missing crypto type")
 	}
 
+	// Refuses scrypt parameters that would exhaust the memory or hold the wallet for too long
+	if sp, ok := w.ScryptParams(); ok {
+		if err := sp.validateCost(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Gets the crypto
 	crypto, err := getCrypto(ct)
 	if err != nil {
 		return nil, err
 	}
 
-	// Decrypts the secrets
+	// Decrypts the secrets. The scrypt parameters of the encrypted secrets are bounded
+	// by the crypto, they may differ from the ones recorded in the wallet
 	sb, err := crypto.Decrypt([]byte(sstr), password)
-	if err != nil {
+	switch err {
+	case nil:
+	case encrypt.ErrScryptParamsOutOfBounds:
+		return nil, ErrInvalidScryptParams
+	default:
 		return nil, ErrInvalidPassword
 	}
 
@@ -518,12 +568,72 @@ func (w *Wallet) Unlock(password []byte) (*Wallet, error) {
 		copy(wlt.Entries[i].Secret[:], s[:])
 	}
 
+	// Restores the metadata
+	if md, ok := ss.get(secretMetadata); ok {
+		wlt.Meta[metaMetadata] = md
+	}
+
 	wlt.setEncrypted(false)
 	wlt.setSecrets("")
 	wlt.setCryptoType("")
+	wlt.deleteScryptParams()
 	return wlt, nil
 }
 
+// ChangePasswordOptions are the options of changing the password or crypto type of an encrypted wallet
+type ChangePasswordOptions struct {
+	NewPassword  []byte        // new password, the old password is kept if empty
+	CryptoType   CryptoType    // new crypto type, the crypto type is kept if empty
+	ScryptParams *ScryptParams // scrypt parameters, if the new crypto type is scrypt-chacha20poly1305
+}
+
+// ChangePassword re-encrypts the wallet with a new password and/or crypto type.
+// The secrets are decrypted in memory only, so the wallet is never written to disk unencrypted.
+// If no scrypt parameters are given, the wallet's scrypt parameters are kept, or the defaults are used.
+func (w *Wallet) ChangePassword(oldPassword []byte, opts ChangePasswordOptions) error {
+	if !w.IsEncrypted() {
+		return ErrWalletNotEncrypted
+	}
+
+	newPassword := opts.NewPassword
+	if len(newPassword) == 0 {
+		newPassword = oldPassword
+	}
+
+	cryptoType := opts.CryptoType
+	if cryptoType == "" {
+		cryptoType = w.cryptoType()
+	}
+
+	sp := DefaultScryptParams
+	if opts.ScryptParams != nil {
+		sp = *opts.ScryptParams
+	} else if cryptoType == w.cryptoType() {
+		if p, ok := w.ScryptParams(); ok {
+			sp = p
+		}
+	}
+
+	if cryptoType == CryptoTypeScryptChacha20poly1305 {
+		if err := sp.Validate(); err != nil {
+			return err
+		}
+	}
+
+	unlockWlt, err := w.Unlock(oldPassword)
+	if err != nil {
+		return err
+	}
+	defer unlockWlt.erase()
+
+	if err := unlockWlt.lock(newPassword, cryptoType, sp); err != nil {
+		return err
+	}
+
+	w.copyFrom(unlockWlt)
+	return nil
+}
+
 // copyFrom copies the src wallet to w
 func (w *Wallet) copyFrom(src *Wallet) {
 	// Clear the original info first
@@ -569,6 +679,11 @@ func (w *Wallet) GuardUpdate(password []byte, fn func(w *Wallet) error) error {
 	}
 
 	cryptoType := w.cryptoType()
+	sp, ok := w.ScryptParams()
+	if !ok {
+		sp = DefaultScryptParams
+	}
+
 	wlt, err := w.Unlock(password)
 	if err != nil {
 		return err
@@ -580,7 +695,7 @@ func (w *Wallet) GuardUpdate(password []byte, fn func(w *Wallet) error) error {
 		return err
 	}
 
-	if err := wlt.Lock(password, cryptoType); err != nil {
+	if err := wlt.lock(password, cryptoType, sp); err != nil {
 		return err
 	}
 
@@ -710,10 +825,26 @@ func (w *Wallet) Validate() error {
 		return errors.New("wallet type invalid")
 	}
 
+	if s, ok := w.Meta[metaWatchOnly]; ok {
+		if _, err := strconv.ParseBool(s); err != nil {
+			return fmt.Errorf("invalid watch-only value: %v", err)
+		}
+	}
+
 	if _, ok := w.Meta[metaCoin]; !ok {
 		return errors.New("coin field not set")
 	}
 
+	if !w.IsEncrypted() {
+		m, err := w.metadata()
+		if err != nil {
+			return err
+		}
+		if err := m.validate(); err != nil {
+			return err
+		}
+	}
+
 	if encStr, ok := w.Meta[metaEncrypted]; ok {
 		// validate the encrypted value
 		isEncrypted, err := strconv.ParseBool(encStr)
@@ -723,6 +854,14 @@ func (w *Wallet) Validate() error {
 
 		// checks if the secrets field is empty
 		if isEncrypted {
+			if _, ok := w.Meta[metaMetadata]; ok {
+				return errors.New("wallet is encrypted, but metadata is not encrypted")
+			}
+
+			if w.IsWatchOnly() {
+				return errors.New("watch-only wallet can not be encrypted")
+			}
+
 			if _, ok := w.Meta[metaCryptoType]; !ok {
 				return errors.New("crypto type field not set")
 			}
@@ -730,6 +869,16 @@ func (w *Wallet) Validate() error {
 			if _, ok := w.Meta[metaSecrets]; !ok {
 				return errors.New("wallet is encrypted, but secrets field not set")
 			}
+
+			if _, ok := w.Meta[metaScryptN]; ok {
+				sp, ok := w.ScryptParams()
+				if !ok {
+					return errors.New("invalid scrypt parameters fields")
+				}
+				if err := sp.Validate(); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -809,6 +958,17 @@ func (w *Wallet) IsEncrypted() bool {
 	return b
 }
 
+// IsWatchOnly checks whether the wallet only has public keys, and can not generate addresses or sign transactions
+func (w *Wallet) IsWatchOnly() bool {
+	// Validate rejects an invalid value, a missing value is false
+	b, _ := strconv.ParseBool(w.Meta[metaWatchOnly])
+	return b
+}
+
+func (w *Wallet) setWatchOnly(watchOnly bool) {
+	w.Meta[metaWatchOnly] = strconv.FormatBool(watchOnly)
+}
+
 func (w *Wallet) setCryptoType(tp CryptoType) {
 	w.Meta[metaCryptoType] = string(tp)
 }
@@ -817,6 +977,45 @@ func (w *Wallet) cryptoType() CryptoType {
 	return CryptoType(w.Meta[metaCryptoType])
 }
 
+// ScryptParams returns the scrypt parameters recorded in the wallet.
+// Returns false if the wallet was encrypted without recording them.
+func (w *Wallet) ScryptParams() (ScryptParams, bool) {
+	var sp ScryptParams
+	for _, f := range []struct {
+		key string
+		v   *int
+	}{
+		{metaScryptN, &sp.N},
+		{metaScryptR, &sp.R},
+		{metaScryptP, &sp.P},
+	} {
+		s, ok := w.Meta[f.key]
+		if !ok {
+			return ScryptParams{}, false
+		}
+
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return ScryptParams{}, false
+		}
+		*f.v = v
+	}
+
+	return sp, true
+}
+
+func (w *Wallet) setScryptParams(sp ScryptParams) {
+	w.Meta[metaScryptN] = strconv.Itoa(sp.N)
+	w.Meta[metaScryptR] = strconv.Itoa(sp.R)
+	w.Meta[metaScryptP] = strconv.Itoa(sp.P)
+}
+
+func (w *Wallet) deleteScryptParams() {
+	delete(w.Meta, metaScryptN)
+	delete(w.Meta, metaScryptR)
+	delete(w.Meta, metaScryptP)
+}
+
 func (w *Wallet) secrets() string {
 	return w.Meta[metaSecrets]
 }
@@ -835,6 +1034,10 @@ func (w *Wallet) GenerateAddresses(num uint64) ([]cipher.Address, error) {
 		return nil, ErrWalletEncrypted
 	}
 
+	if w.IsWatchOnly() {
+		return nil, ErrWalletWatchOnly
+	}
+
 	var seckeys []cipher.SecKey
 	var seed []byte
 	if len(w.Entries) == 0 {
@@ -965,6 +1168,10 @@ func (w *Wallet) CreateAndSignTransaction(auxs coin.AddressUxOuts, headTime, coi
 		return nil, ErrWalletEncrypted
 	}
 
+	if w.IsWatchOnly() {
+		return nil, ErrWalletWatchOnly
+	}
+
 	entriesMap := make(map[cipher.Address]Entry)
 	for a := range auxs {
 		e, ok := w.GetEntry(a)
@@ -1050,6 +1257,10 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(params CreateTransactionParams
 		return nil, nil, ErrWalletEncrypted
 	}
 
+	if w.IsWatchOnly() {
+		return nil, nil, ErrWalletWatchOnly
+	}
+
 	entriesMap := make(map[cipher.Address]Entry)
 	for a := range auxs {
 		// Check that auxs does not contain addresses that are not known to this wallet
@@ -1060,6 +1271,60 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(params CreateTransactionParams
 		entriesMap[e.Address] = e
 	}
 
+	txn, inputs, err := createTransaction(params, auxs, headTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toSign := make([]cipher.SecKey, len(inputs))
+	for i, in := range inputs {
+		entry, ok := entriesMap[in.Address]
+		if !ok {
+			return nil, nil, fmt.Errorf("spend address %s not found in entriesMap", in.Address.String())
+		}
+		toSign[i] = entry.Secret
+	}
+
+	txn.SignInputs(toSign)
+	txn.UpdateHeader()
+
+	if err := verifyCreatedTransactionInvariants(params, txn, inputs); err != nil {
+		logger.Critical().Errorf("CreateAndSignTransactionAdvanced created transaction that violates invariants, aborting: %v", err)
+		return nil, nil, fmt.Errorf("Created transaction that violates invariants, this is a bug: %v", err)
+	}
+
+	return txn, inputs, nil
+}
+
+// EstimateTransaction creates an unsigned transaction based upon CreateTransactionParams,
+// choosing the inputs and hours the same way as CreateAndSignTransactionAdvanced.
+// The transaction has empty signatures, so that its size is the size of the signed transaction.
+// params.Wallet.ID is not required, and the wallet does not need to be decrypted.
+// NOTE: Caller must ensure that auxs correspond to params.Wallet.Addresses and params.Wallet.UxOuts options
+func EstimateTransaction(params CreateTransactionParams, auxs coin.AddressUxOuts, headTime uint64) (*coin.Transaction, []UxBalance, error) {
+	if err := params.validate(false); err != nil {
+		return nil, nil, err
+	}
+
+	txn, inputs, err := createTransaction(params, auxs, headTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	txn.UpdateHeader()
+
+	if err := verifyCreatedTransactionInvariants(params, txn, inputs); err != nil {
+		logger.Critical().Errorf("EstimateTransaction created transaction that violates invariants, aborting: %v", err)
+		return nil, nil, fmt.Errorf("Created transaction that violates invariants, this is a bug: %v", err)
+	}
+
+	return txn, inputs, nil
+}
+
+// createTransaction creates an unsigned transaction based upon CreateTransactionParams,
+// and returns the spent outputs in the order of the transaction's inputs
+func createTransaction(params CreateTransactionParams, auxs coin.AddressUxOuts, headTime uint64) (*coin.Transaction, []UxBalance, error) {
 	txn := &coin.Transaction{}
 
 	// Determine which unspents to spend
@@ -1105,8 +1370,7 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(params CreateTransactionParams
 	// calculate total coins and hours in spends
 	var totalInputCoins uint64
 	var totalInputHours uint64
-	toSign := make([]cipher.SecKey, len(spends))
-	for i, spend := range spends {
+	for _, spend := range spends {
 		totalInputCoins, err = coin.AddUint64(totalInputCoins, spend.Coins)
 		if err != nil {
 			return nil, nil, err
@@ -1117,12 +1381,6 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(params CreateTransactionParams
 			return nil, nil, err
 		}
 
-		entry, ok := entriesMap[spend.Address]
-		if !ok {
-			return nil, nil, fmt.Errorf("spend address %s not found in entriesMap", spend.Address.String())
-		}
-
-		toSign[i] = entry.Secret
 		txn.PushInput(spend.Hash)
 	}
 
@@ -1244,12 +1502,6 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(params CreateTransactionParams
 					return nil, nil, err
 				}
 
-				entry, ok := entriesMap[extra.Address]
-				if !ok {
-					return nil, nil, fmt.Errorf("extra spend address %s not found in entriesMap", extra.Address.String())
-				}
-
-				toSign = append(toSign, entry.Secret)
 				txn.PushInput(extra.Hash)
 			}
 		}
@@ -1263,7 +1515,7 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(params CreateTransactionParams
 			return nil, nil, errors.New("share factor is 1.0 but changeHours > 0 unexpectedly")
 		}
 		params.HoursSelection.ShareFactor = &oneDecimal
-		return w.CreateAndSignTransactionAdvanced(params, auxs, headTime)
+		return createTransaction(params, auxs, headTime)
 	}
 
 	if changeCoins > 0 {
@@ -1298,9 +1550,6 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(params CreateTransactionParams
 		txn.PushOutput(changeAddress, changeCoins, changeHours)
 	}
 
-	txn.SignInputs(toSign)
-	txn.UpdateHeader()
-
 	inputs := make([]UxBalance, len(txn.In))
 	for i, h := range txn.In {
 		uxBalance, ok := uxbMap[h]
@@ -1310,11 +1559,6 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(params CreateTransactionParams
 		inputs[i] = uxBalance
 	}
 
-	if err := verifyCreatedTransactionInvariants(params, txn, inputs); err != nil {
-		logger.Critical().Errorf("CreateAndSignTransactionAdvanced created transaction that violates invariants, aborting: %v", err)
-		return nil, nil, fmt.Errorf("Created transaction that violates invariants, this is a bug: %v", err)
-	}
-
 	return txn, inputs, nil
 }
 
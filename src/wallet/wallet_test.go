@@ -2,13 +2,17 @@ package wallet
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"math/rand"
+	"strconv"
 	"testing"
 	"time"
 
@@ -2463,3 +2467,302 @@ func TestDistributeCoinHoursProportional(t *testing.T) {
 		require.Equal(t, hours, totalHours)
 	}
 }
+
+func TestEstimateTransaction(t *testing.T) {
+	headTime := uint64(time.Now().UTC().Unix())
+
+	w := makeWallet(t, Options{
+		Seed: "seed",
+	}, 1)
+	entry := w.Entries[0]
+
+	var uxouts coin.UxArray
+	for i := 0; i < 5; i++ {
+		uxout := makeUxOut(t, entry.Secret, 2e6, uint64(100+i))
+		uxout.Head.Time = headTime
+		uxouts = append(uxouts, uxout)
+	}
+	auxs := coin.AddressUxOuts{
+		entry.Address: uxouts,
+	}
+
+	shareFactor := decimal.New(5, -1)
+	params := CreateTransactionParams{
+		HoursSelection: HoursSelection{
+			Type:        HoursSelectionTypeAuto,
+			Mode:        HoursSelectionModeShare,
+			ShareFactor: &shareFactor,
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Coins:   3e6,
+			},
+		},
+	}
+
+	// The wallet ID is not required to estimate
+	estimate, estimateInputs, err := EstimateTransaction(params, auxs, headTime)
+	require.NoError(t, err)
+	require.Len(t, estimate.Sigs, len(estimate.In))
+	for _, s := range estimate.Sigs {
+		require.Equal(t, cipher.Sig{}, s)
+	}
+
+	// The estimate chooses the same inputs and outputs as the signed transaction
+	params.Wallet.ID = w.Filename()
+	txn, inputs, err := w.CreateAndSignTransactionAdvanced(params, auxs, headTime)
+	require.NoError(t, err)
+
+	require.Equal(t, inputs, estimateInputs)
+	require.Equal(t, txn.In, estimate.In)
+	require.Equal(t, txn.Out, estimate.Out)
+	require.Equal(t, txn.InnerHash, estimate.InnerHash)
+	require.Equal(t, txn.Length, estimate.Length)
+	require.Equal(t, txn.Size(), estimate.Size())
+
+	// Invalid params are rejected
+	params.To = nil
+	_, _, err = EstimateTransaction(params, auxs, headTime)
+	require.Equal(t, NewError(errors.New("To is required")), err)
+}
+
+func TestWalletChangePassword(t *testing.T) {
+	fastScrypt := &ScryptParams{N: MinScryptN, R: 8, P: 1}
+
+	tt := []struct {
+		name             string
+		opts             Options
+		oldPassword      []byte
+		changeOpts       ChangePasswordOptions
+		expectPassword   []byte
+		expectCryptoType CryptoType
+		expectScrypt     *ScryptParams
+		expectErr        error
+	}{
+		{
+			name: "new password",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			oldPassword: []byte("pwd"),
+			changeOpts: ChangePasswordOptions{
+				NewPassword: []byte("newpwd"),
+			},
+			expectPassword:   []byte("newpwd"),
+			expectCryptoType: CryptoTypeSha256Xor,
+		},
+		{
+			name: "sha256-xor to scrypt-chacha20poly1305, keep password",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			oldPassword: []byte("pwd"),
+			changeOpts: ChangePasswordOptions{
+				CryptoType:   CryptoTypeScryptChacha20poly1305,
+				ScryptParams: fastScrypt,
+			},
+			expectPassword:   []byte("pwd"),
+			expectCryptoType: CryptoTypeScryptChacha20poly1305,
+			expectScrypt:     fastScrypt,
+		},
+		{
+			name: "scrypt-chacha20poly1305 to sha256-xor",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeScryptChacha20poly1305,
+			},
+			oldPassword: []byte("pwd"),
+			changeOpts: ChangePasswordOptions{
+				NewPassword: []byte("newpwd"),
+				CryptoType:  CryptoTypeSha256Xor,
+			},
+			expectPassword:   []byte("newpwd"),
+			expectCryptoType: CryptoTypeSha256Xor,
+		},
+		{
+			name: "invalid password",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			oldPassword: []byte("wrong"),
+			changeOpts: ChangePasswordOptions{
+				NewPassword: []byte("newpwd"),
+			},
+			expectErr: ErrInvalidPassword,
+		},
+		{
+			name: "invalid scrypt parameters",
+			opts: Options{
+				Seed:       "seed",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: CryptoTypeSha256Xor,
+			},
+			oldPassword: []byte("pwd"),
+			changeOpts: ChangePasswordOptions{
+				CryptoType:   CryptoTypeScryptChacha20poly1305,
+				ScryptParams: &ScryptParams{N: 1000, R: 8, P: 1},
+			},
+			expectErr: ErrInvalidScryptParams,
+		},
+		{
+			name: "wallet not encrypted",
+			opts: Options{
+				Seed: "seed",
+			},
+			oldPassword: []byte("pwd"),
+			changeOpts: ChangePasswordOptions{
+				NewPassword: []byte("newpwd"),
+			},
+			expectErr: ErrWalletNotEncrypted,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := NewWallet("t.wlt", tc.opts)
+			require.NoError(t, err)
+			origWlt := w.clone()
+
+			err = w.ChangePassword(tc.oldPassword, tc.changeOpts)
+			require.Equal(t, tc.expectErr, err)
+			if err != nil {
+				// The wallet is unchanged
+				require.Equal(t, origWlt, w)
+				return
+			}
+
+			require.True(t, w.IsEncrypted())
+			require.Equal(t, tc.expectCryptoType, w.cryptoType())
+			checkNoSensitiveData(t, w)
+			require.NoError(t, w.Validate())
+
+			sp, ok := w.ScryptParams()
+			if tc.expectScrypt != nil {
+				require.True(t, ok)
+				require.Equal(t, *tc.expectScrypt, sp)
+			} else if tc.expectCryptoType != CryptoTypeScryptChacha20poly1305 {
+				require.False(t, ok)
+			}
+
+			_, err = w.Unlock(tc.oldPassword)
+			if !bytes.Equal(tc.oldPassword, tc.expectPassword) {
+				require.Equal(t, ErrInvalidPassword, err)
+			}
+
+			wlt, err := w.Unlock(tc.expectPassword)
+			require.NoError(t, err)
+			require.Equal(t, tc.opts.Seed, wlt.seed())
+			require.Equal(t, origWlt.GetAddresses(), wlt.GetAddresses())
+
+			// Updates of the encrypted wallet keep the scrypt parameters
+			err = w.GuardUpdate(tc.expectPassword, func(w *Wallet) error {
+				_, err := w.GenerateAddresses(1)
+				return err
+			})
+			require.NoError(t, err)
+			sp2, ok2 := w.ScryptParams()
+			require.Equal(t, ok, ok2)
+			require.Equal(t, sp, sp2)
+		})
+	}
+}
+
+func TestScryptParamsValidate(t *testing.T) {
+	require.NoError(t, DefaultScryptParams.Validate())
+	require.NoError(t, ScryptParams{N: MinScryptN, R: 1, P: 1}.Validate())
+	require.NoError(t, ScryptParams{N: 1 << 17, R: 64, P: MaxScryptP}.Validate())
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1, R: 8, P: 1}.Validate())
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1000, R: 8, P: 1}.Validate())
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1024, R: 0, P: 1}.Validate())
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1 << 16, R: 0, P: 1}.Validate())
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1 << 16, R: 8, P: 0}.Validate())
+
+	// The security can't be lowered below MinScryptN
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: MinScryptN / 2, R: 8, P: 1}.Validate())
+
+	// Scrypt can't use more than MaxScryptMemory
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1 << 30, R: 8, P: 1}.Validate())
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1 << 21, R: 8, P: 1}.Validate())
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1 << 20, R: 9, P: 1}.Validate())
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1 << 62, R: 1 << 30, P: 1}.Validate())
+
+	// p is small
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1 << 16, R: 8, P: MaxScryptP + 1}.Validate())
+	require.Equal(t, ErrInvalidScryptParams, ScryptParams{N: 1024, R: 1 << 15, P: 1 << 15}.Validate())
+}
+
+func TestWalletUnlockScryptCost(t *testing.T) {
+	w, err := NewWallet("t.wlt", Options{
+		Seed:       "seed",
+		Encrypt:    true,
+		Password:   []byte("pwd"),
+		CryptoType: CryptoTypeScryptChacha20poly1305,
+	})
+	require.NoError(t, err)
+
+	err = w.ChangePassword([]byte("pwd"), ChangePasswordOptions{
+		ScryptParams: &ScryptParams{N: MinScryptN, R: 8, P: 1},
+	})
+	require.NoError(t, err)
+
+	_, err = w.Unlock([]byte("pwd"))
+	require.NoError(t, err)
+
+	// A wallet that records scrypt parameters that use too much memory is not decrypted
+	w.Meta[metaScryptN] = strconv.Itoa(1 << 30)
+	_, err = w.Unlock([]byte("pwd"))
+	require.Equal(t, ErrInvalidScryptParams, err)
+	err = w.GuardUpdate([]byte("pwd"), func(w *Wallet) error {
+		return nil
+	})
+	require.Equal(t, ErrInvalidScryptParams, err)
+
+	// A wallet encrypted with a lower N than MinScryptN can still be decrypted
+	w.Meta[metaScryptN] = strconv.Itoa(1 << 10)
+	_, err = w.Unlock([]byte("pwd"))
+	require.NotEqual(t, ErrInvalidScryptParams, err)
+
+	// Scrypt runs with the parameters in the header of the encrypted secrets,
+	// which are bounded even if the wallet records other parameters or none
+	w.Meta[metaScryptN] = strconv.Itoa(MinScryptN)
+	w.Meta[metaSecrets] = setScryptHeaderN(t, w.Meta[metaSecrets], 1<<30)
+	_, err = w.Unlock([]byte("pwd"))
+	require.Equal(t, ErrInvalidScryptParams, err)
+
+	delete(w.Meta, metaScryptN)
+	delete(w.Meta, metaScryptR)
+	delete(w.Meta, metaScryptP)
+	_, err = w.Unlock([]byte("pwd"))
+	require.Equal(t, ErrInvalidScryptParams, err)
+}
+
+// setScryptHeaderN replaces the scrypt N in the metadata header of data encrypted with scrypt-chacha20poly1305
+func setScryptHeaderN(t *testing.T, data string, n int) string {
+	b, err := base64.StdEncoding.DecodeString(data)
+	require.NoError(t, err)
+
+	ml := binary.LittleEndian.Uint16(b[:2])
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(b[2:2+ml], &m))
+	m["n"] = n
+
+	ms, err := json.Marshal(m)
+	require.NoError(t, err)
+
+	length := make([]byte, 2)
+	binary.LittleEndian.PutUint16(length, uint16(len(ms)))
+	return base64.StdEncoding.EncodeToString(append(append(length, ms...), b[2+ml:]...))
+}